@@ -59,8 +59,27 @@ func (r *RingBuffer[T]) GetRecent(n int) []T {
 	return result
 }
 
+// Cap 缓冲区容量
+func (r *RingBuffer[T]) Cap() int {
+	return r.size
+}
+
 func (r *RingBuffer[T]) Len() int {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 	return r.count
 }
+
+// UpdateRecent 从最新元素开始向前遍历，fn 返回 true 表示已处理并停止遍历
+// 返回是否有元素被处理
+func (r *RingBuffer[T]) UpdateRecent(fn func(item *T) bool) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := 1; i <= r.count; i++ {
+		idx := (r.head - i + r.size) % r.size
+		if fn(&r.data[idx]) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package buildinfo
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// 以下变量在编译时通过 -ldflags 注入，例如:
+//
+//	go build -ldflags "-X monitor-agent/buildinfo.Version=1.2.0 \
+//	  -X monitor-agent/buildinfo.Commit=$(git rev-parse --short HEAD) \
+//	  -X monitor-agent/buildinfo.BuildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)" ./cmd/web
+//
+// Windows PowerShell 下等价的写法:
+//
+//	$commit = git rev-parse --short HEAD
+//	$date = (Get-Date).ToUniversalTime().ToString("yyyy-MM-ddTHH:mm:ssZ")
+//	go build -ldflags "-X monitor-agent/buildinfo.Version=1.2.0 -X monitor-agent/buildinfo.Commit=$commit -X monitor-agent/buildinfo.BuildDate=$date" -o monitor-web.exe ./cmd/web
+//
+// 变量名或包路径变化时需同步修改 README「编译」一节的命令。
+// 版本信息会写入启动日志、CLI banner、值班报告头和 /api/version
+var (
+	Version   = "1.0.0"
+	Commit    = ""
+	BuildDate = ""
+)
+
+// Info 构建信息
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"build_date"`
+	GoVersion string `json:"go_version"`
+	Platform  string `json:"platform"`
+	Modified  bool   `json:"modified,omitempty"`
+}
+
+// Get 获取构建信息
+// 未通过 -ldflags 注入的字段回退到 runtime/debug.ReadBuildInfo 中的 VCS 信息
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildDate: BuildDate,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = s.Value
+				}
+			case "vcs.time":
+				if info.BuildDate == "" {
+					info.BuildDate = s.Value
+				}
+			case "vcs.modified":
+				info.Modified = s.Value == "true"
+			}
+		}
+	}
+
+	if len(info.Commit) > 12 {
+		info.Commit = info.Commit[:12]
+	}
+	if info.Commit == "" {
+		info.Commit = "unknown"
+	}
+	if info.BuildDate == "" {
+		info.BuildDate = "unknown"
+	}
+	return info
+}
+
+// Short 简短版本字符串
+func Short() string {
+	return fmt.Sprintf("Monitor Agent v%s", Version)
+}
+
+// Brief 版本和提交（如 "Monitor Agent v1.2.0 (3f2a9c1)"），用于 CLI banner 和报告头
+func Brief() string {
+	info := Get()
+	commit := info.Commit
+	if info.Modified {
+		commit += "-dirty"
+	}
+	return fmt.Sprintf("Monitor Agent v%s (%s)", info.Version, commit)
+}
+
+// Full 完整版本字符串
+func Full() string {
+	info := Get()
+	commit := info.Commit
+	if info.Modified {
+		commit += "-dirty"
+	}
+	return fmt.Sprintf("Monitor Agent v%s (commit %s, built %s, %s, %s)",
+		info.Version, commit, info.BuildDate, info.GoVersion, info.Platform)
+}
@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"monitor-agent/types"
+)
+
+// aliasCLI 两个进程、一个已添加目标（PID 4101）的命令行界面
+func aliasCLI(t *testing.T) *CLI {
+	t.Helper()
+	c, _ := newTestCLI(t,
+		types.ProcessInfo{PID: 4101, Name: "scada", Cmdline: "/opt/scada/bin/scada", CPUPct: 12.5, RSSBytes: 256 << 20},
+		types.ProcessInfo{PID: 4102, Name: "nginx", Cmdline: "nginx: master process", CPUPct: 1, RSSBytes: 8 << 20},
+	)
+	if err := c.monitor.AddTarget(types.MonitorTarget{PID: 4101, Name: "scada"}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(c.monitor.Stop)
+	return c
+}
+
+func sortedTargetPIDs(c *CLI) []int32 {
+	var pids []int32
+	for _, t := range c.monitor.GetTargets() {
+		pids = append(pids, t.PID)
+	}
+	sort.Slice(pids, func(i, j int) bool { return pids[i] < pids[j] })
+	return pids
+}
+
+func TestFlatAliasesMapToGroupCommands(t *testing.T) {
+	want := map[string][2]string{
+		"add": {"target", "add"}, "remove": {"target", "remove"}, "rm": {"target", "remove"},
+		"list": {"target", "list"}, "ls": {"target", "list"}, "info": {"target", "info"},
+		"start": {"target", "start"}, "stop": {"target", "stop"},
+		"top": {"system", "top"}, "ps": {"system", "ps"}, "watch": {"system", "watch"},
+		"events": {"system", "events"}, "status": {"system", "status"},
+	}
+	if !reflect.DeepEqual(flatAliases, want) {
+		t.Fatalf("flatAliases = %v, want %v", flatAliases, want)
+	}
+}
+
+// 快捷命令与对应的分组命令输出相同、产生相同的效果（watch 持续刷新，不在此比较）
+func TestFlatAliasParity(t *testing.T) {
+	tests := []struct {
+		alias, group string
+	}{
+		{"list", "target list"},
+		{"ls", "target list"},
+		{"ls --json", "target list --json"},
+		{"LS", "target list"},
+		{"info 4101", "target info 4101"},
+		{"info --json 4101", "target info 4101 --json"},
+		{"add 4102", "target add 4102"},
+		{"add 4102 --json", "target add 4102 --json"},
+		{"add 9999", "target add 9999"},
+		{"remove 4101", "target remove 4101"},
+		{"rm 4101", "target remove 4101"},
+		{"start", "target start"},
+		{"stop", "target stop"},
+		{"top once", "system top once"},
+		{"ps", "system ps"},
+		{"ps --json", "system ps --json"},
+		{"events", "system events"},
+		{"events --json", "system events --json"},
+	}
+	for _, tt := range tests {
+		aliasCLI, groupCLI := aliasCLI(t), aliasCLI(t)
+		aliasOut := captureStdout(t, func() { aliasCLI.handleCommand(tt.alias) })
+		groupOut := captureStdout(t, func() { groupCLI.handleCommand(tt.group) })
+		if groupOut == "" {
+			t.Errorf("%q printed nothing", tt.group)
+		}
+		if aliasOut != groupOut {
+			t.Errorf("%q output differs from %q:\n--- alias\n%s\n--- group\n%s", tt.alias, tt.group, aliasOut, groupOut)
+		}
+		if a, g := sortedTargetPIDs(aliasCLI), sortedTargetPIDs(groupCLI); !reflect.DeepEqual(a, g) {
+			t.Errorf("%q targets = %v, %q targets = %v", tt.alias, a, tt.group, g)
+		}
+		if a, g := aliasCLI.monitor.IsRunning(), groupCLI.monitor.IsRunning(); a != g {
+			t.Errorf("%q running = %v, %q running = %v", tt.alias, a, tt.group, g)
+		}
+		if aliasCLI.failed != groupCLI.failed {
+			t.Errorf("%q failed = %v, %q failed = %v", tt.alias, aliasCLI.failed, tt.group, groupCLI.failed)
+		}
+	}
+}
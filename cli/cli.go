@@ -4,9 +4,12 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"os/user"
 	"strings"
 
+	"monitor-agent/buildinfo"
 	"monitor-agent/config"
+	"monitor-agent/logger"
 	"monitor-agent/monitor"
 )
 
@@ -18,6 +21,10 @@ type CLI struct {
 	scanner    *bufio.Scanner
 	formatter  *Formatter
 	running    bool
+	jsonOutput bool   // 全局 JSON 输出模式
+	jsonCmd    bool   // 当前命令带 --json
+	failed     bool   // JSON 模式下有命令失败
+	user       string // 运行本程序的系统用户，记入审计日志
 
 	// 命令组
 	configCmd *ConfigCommand
@@ -27,6 +34,23 @@ type CLI struct {
 	logCmd    *LogCommand
 }
 
+// flatAliases 顶层快捷命令 -> 命令组和子命令（兼容早期不分组的命令写法）
+var flatAliases = map[string][2]string{
+	"add":    {"target", "add"},
+	"remove": {"target", "remove"},
+	"rm":     {"target", "remove"},
+	"list":   {"target", "list"},
+	"ls":     {"target", "list"},
+	"info":   {"target", "info"},
+	"start":  {"target", "start"},
+	"stop":   {"target", "stop"},
+	"top":    {"system", "top"},
+	"ps":     {"system", "ps"},
+	"watch":  {"system", "watch"},
+	"events": {"system", "events"},
+	"status": {"system", "status"},
+}
+
 // NewCLI 创建命令行界面
 func NewCLI(m *monitor.MultiMonitor, configFile string, cfg *config.Config) *CLI {
 	cli := &CLI{
@@ -36,6 +60,7 @@ func NewCLI(m *monitor.MultiMonitor, configFile string, cfg *config.Config) *CLI
 		scanner:    bufio.NewScanner(os.Stdin),
 		formatter:  NewFormatter(),
 		running:    true,
+		user:       currentUser(),
 	}
 
 	// 初始化命令组
@@ -50,11 +75,15 @@ func NewCLI(m *monitor.MultiMonitor, configFile string, cfg *config.Config) *CLI
 
 // Run 运行命令行交互
 func (c *CLI) Run() {
-	c.printBanner()
-	c.printHelp()
+	if !c.jsonOutput {
+		c.printBanner()
+		c.printHelp()
+	}
 
 	for c.running {
-		fmt.Print("\n> ")
+		if !c.jsonOutput {
+			fmt.Print("\n> ")
+		}
 		if !c.scanner.Scan() {
 			break
 		}
@@ -71,10 +100,19 @@ func (c *CLI) Run() {
 func (c *CLI) printBanner() {
 	fmt.Println(c.formatter.Header("╔═══════════════════════════════════════════════════════════╗"))
 	fmt.Println(c.formatter.Header("║    电厂核心软件监视保障系统 - 命令行管理界面              ║"))
-	fmt.Println(c.formatter.Header("║    Monitor Agent CLI v2.0                                 ║"))
+	fmt.Println(c.formatter.Header(bannerLine("    " + buildinfo.Brief())))
 	fmt.Println(c.formatter.Header("╚═══════════════════════════════════════════════════════════╝"))
 }
 
+// bannerLine 按 banner 边框宽度补齐一行（只用于 ASCII 内容），超长时不补齐
+func bannerLine(content string) string {
+	const width = 59
+	if pad := width - len(content); pad > 0 {
+		content += strings.Repeat(" ", pad)
+	}
+	return "║" + content + "║"
+}
+
 // ShowMainScreen 显示主界面（清屏后显示banner和帮助）
 func (c *CLI) ShowMainScreen() {
 	fmt.Print("\033[H\033[2J") // 清屏
@@ -96,16 +134,23 @@ func (c *CLI) printHelp() {
 	fmt.Println(c.formatter.Header("  目标管理 (target):"))
 	fmt.Println("    target list                     - 列出所有监控目标 (动态刷新)")
 	fmt.Println("    target list -1                  - 列出所有监控目标 (只显示一次)")
+	fmt.Println("    target list --by-group          - 按分组列出监控目标")
 	fmt.Println("    target add <pid|name> [alias]   - 添加监控目标 (自动保存)")
 	fmt.Println("    target remove <pid>             - 移除监控目标 (自动保存)")
 	fmt.Println("    target info <pid>               - 显示目标详情")
 	fmt.Println("    target update <pid> <key> <val> - 更新目标配置 (自动保存)")
 	fmt.Println("    target clear                    - 清除所有目标 (自动保存)")
+	fmt.Println("    target timeline <pid> [分钟]    - 显示目标时间线")
+	fmt.Println("    target start / stop             - 开始/停止监控")
+	fmt.Println("    target maint <pid|all> <时长> [原因] - 维护模式 (暂停告警)")
+	fmt.Println("    target export / import <文件>   - 导出/批量导入监控目标 (按进程名)")
 	fmt.Println()
 
 	fmt.Println(c.formatter.Header("  影响分析 (impact):"))
 	fmt.Println("    impact list [n]                 - 显示影响事件 (默认20)")
 	fmt.Println("    impact summary                  - 显示影响统计")
+	fmt.Println("    impact offenders [n]            - 最近7天影响源进程排行 (默认10)")
+	fmt.Println("    impact status                   - 显示影响分析器运行诊断")
 	fmt.Println("    impact config                   - 显示影响分析配置")
 	fmt.Println("    impact set <key> <value>        - 设置影响分析参数 (自动保存)")
 	fmt.Println("    impact clear                    - 清除所有影响事件")
@@ -130,16 +175,34 @@ func (c *CLI) printHelp() {
 	fmt.Println(c.formatter.Header("  通用命令:"))
 	fmt.Println("    help, ?                         - 显示帮助")
 	fmt.Println("    clear, cls                      - 清屏")
+	fmt.Println("    version [--full]                - 显示版本 (--full 含构建信息)")
 	fmt.Println("    exit, quit                      - 退出")
 	fmt.Println()
+	fmt.Println(c.formatter.Header("  快捷命令 (等同于对应的分组命令):"))
+	fmt.Println("    add/remove/list/info/start/stop - target add/remove/list/info/start/stop")
+	fmt.Println("    top/ps/watch/events/status      - system top/ps/watch/events/status")
+	fmt.Println()
+	fmt.Println(c.formatter.Info("提示: 以下命令加 --json 输出 JSON (字段与 Web API 一致): target list, system top/ps, impact list/summary/status, log tail/search, config show"))
 	fmt.Println(c.formatter.Info("提示: 配置修改会自动保存到 config.json，CLI 和 Web 数据实时同步"))
 }
 
 func (c *CLI) handleCommand(line string) {
-	parts := strings.Fields(line)
+	// --json 可出现在命令的任意位置
+	c.jsonCmd = false
+	parts := []string{}
+	for _, p := range strings.Fields(line) {
+		if p == "--json" {
+			c.jsonCmd = true
+			continue
+		}
+		parts = append(parts, p)
+	}
 	if len(parts) == 0 {
 		return
 	}
+	if alias, ok := flatAliases[strings.ToLower(parts[0])]; ok {
+		parts = append([]string{alias[0], alias[1]}, parts[1:]...)
+	}
 
 	cmdGroup := strings.ToLower(parts[0])
 	subCmd := ""
@@ -173,14 +236,64 @@ func (c *CLI) handleCommand(line string) {
 		}
 	case "clear", "cls":
 		fmt.Print("\033[H\033[2J")
+	case "version", "ver":
+		c.showVersion(subCmd == "--full" || subCmd == "-f")
 	case "exit", "quit", "q":
 		c.running = false
-		fmt.Println(c.formatter.Info("再见!"))
+		if !c.jsonOutput {
+			fmt.Println(c.formatter.Info("再见!"))
+		}
 
 	default:
-		fmt.Println(c.formatter.Error(fmt.Sprintf("未知命令: %s", cmdGroup)))
-		fmt.Println(c.formatter.Info("输入 'help' 查看可用命令"))
+		c.printError(fmt.Sprintf("未知命令: %s", cmdGroup))
+		if !c.jsonMode() {
+			fmt.Println(c.formatter.Info("输入 'help' 查看可用命令"))
+		}
+	}
+}
+
+// audit 记录一次 CLI 修改操作，err 非 nil 时记录失败原因
+func (c *CLI) audit(action string, detail interface{}, err error) {
+	entry := logger.AuditEntry{
+		Source: "cli",
+		User:   c.user,
+		Action: action,
+		Detail: detail,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	logger.Audit(entry)
+}
+
+// currentUser 当前系统用户名，获取失败时取环境变量
+func currentUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return os.Getenv("USERNAME")
+}
+
+// showVersion 显示版本信息
+func (c *CLI) showVersion(full bool) {
+	if !full {
+		fmt.Println(buildinfo.Short())
+		return
+	}
+	info := buildinfo.Get()
+	fmt.Println(c.formatter.Bold("版本信息:"))
+	fmt.Printf("  版本:       %s\n", info.Version)
+	commit := info.Commit
+	if info.Modified {
+		commit += " (dirty)"
 	}
+	fmt.Printf("  提交:       %s\n", commit)
+	fmt.Printf("  构建时间:   %s\n", info.BuildDate)
+	fmt.Printf("  Go版本:     %s\n", info.GoVersion)
+	fmt.Printf("  平台:       %s\n", info.Platform)
 }
 
 func (c *CLI) printCommandHelp(cmdGroup string) {
@@ -1,11 +1,23 @@
 package cli
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"path"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"monitor-agent/config"
+	"monitor-agent/format"
+	"monitor-agent/i18n"
+	"monitor-agent/influx"
+	"monitor-agent/logger"
+	"monitor-agent/profile"
+	"monitor-agent/timefmt"
 )
 
 // ConfigCommand 配置管理命令组
@@ -29,9 +41,17 @@ func (c *ConfigCommand) Handle(subCmd string, args []string) {
 		c.save()
 	case "reload":
 		c.reload()
+	case "apply-file":
+		c.applyFile()
+	case "export":
+		c.exportProfile(args)
+	case "import":
+		c.importProfile(args)
 	default:
-		fmt.Println(c.cli.formatter.Error(fmt.Sprintf("未知子命令: config %s", subCmd)))
-		c.PrintHelp()
+		c.cli.printError(fmt.Sprintf("未知子命令: config %s", subCmd))
+		if !c.cli.jsonMode() {
+			c.PrintHelp()
+		}
 	}
 }
 
@@ -43,18 +63,33 @@ func (c *ConfigCommand) PrintHelp() {
 	fmt.Println("  config set <key> <value>      - 设置配置项")
 	fmt.Println("  config save                   - 保存配置到文件")
 	fmt.Println("  config reload                 - 重新加载配置")
+	fmt.Println("  config apply-file             - 配置文件被手工修改后，按文件更新运行中的配置 (save 则反向以运行中的配置覆盖文件)")
+	fmt.Println("  config export <文件>          - 导出完整监控配置档案 (目标按进程名)")
+	fmt.Println("  config import <文件> [--dry-run] - 导入监控配置档案 (--dry-run 只显示将要进行的变更)")
 	fmt.Println()
 	fmt.Println(c.cli.formatter.Bold("可设置的配置项:"))
 	fmt.Println("  基础配置:")
 	fmt.Println("    interval <秒>               - 采样间隔")
+	fmt.Println("    cpu-style <irix|solaris>    - 进程CPU口径 (单核/整机)")
 	fmt.Println("    server.addr <地址>          - Web服务地址 (如 :8080)")
 	fmt.Println("    server.enabled <true|false> - Web服务开关")
+	fmt.Println("    server.auto_start <true|false> - 添加目标/启动时自动开始监控")
+	fmt.Println("    server.read_only <true|false>  - Web只读模式 (禁止修改操作)")
+	fmt.Println("    netmon.interfaces <网卡,...>   - 只统计这些网卡的流量，支持通配符 (- 清空，重启生效)")
+	fmt.Println("    netmon.exclude <网卡,...>      - 不统计这些网卡的流量，如 lo (- 清空，重启生效)")
+	fmt.Println("    time-zone <时区>               - 日志/报告/事件显示时区，如 Asia/Shanghai (- 使用本地时区)")
+	fmt.Println("    time-format <格式>             - 完整时间显示格式，Go 时间格式 (- 使用默认格式)")
+	fmt.Println("    language <zh|en>               - 事件消息和影响描述的语言")
 	fmt.Println()
 	fmt.Println("  系统级阈值:")
 	fmt.Println("    cpu-threshold <百分比>      - 系统CPU阈值")
 	fmt.Println("    memory-threshold <百分比>   - 系统内存阈值")
 	fmt.Println("    disk-threshold <MB/s>       - 系统磁盘IO阈值")
 	fmt.Println("    network-threshold <MB/s>    - 系统网络阈值")
+	fmt.Println("    core-threshold <百分比>     - 单核饱和阈值 (0=禁用)")
+	fmt.Println("    churn-threshold <个/分>     - 进程启停频率阈值 (0=禁用)")
+	fmt.Println("    steal-threshold <百分比>    - CPU 被宿主机抢占阈值 (0=禁用)")
+	fmt.Println("    run-wait-threshold <百分比> - 虚拟化争用的目标运行等待阈值 (0=禁用)")
 	fmt.Println()
 	fmt.Println("  进程级阈值:")
 	fmt.Println("    proc-cpu <百分比>           - 进程CPU阈值")
@@ -65,6 +100,16 @@ func (c *ConfigCommand) PrintHelp() {
 	fmt.Println("    proc-disk-write <MB/s>      - 进程磁盘写阈值")
 	fmt.Println("    proc-net-recv <MB/s>        - 进程网络收阈值")
 	fmt.Println("    proc-net-send <MB/s>        - 进程网络发阈值")
+	fmt.Println("    zombie-threshold <个>       - 僵尸子进程数阈值 (0=禁用)")
+	fmt.Println()
+	fmt.Println("  显示配置 (system top 高亮、常驻进程):")
+	fmt.Println("    top-warn <百分比>           - CPU黄色高亮阈值")
+	fmt.Println("    top-crit <百分比>           - CPU红色高亮阈值")
+	fmt.Println("    top-mem <MB>                - 内存高亮阈值 (0=不高亮)")
+	fmt.Println("    pinned <名称,...>           - system top 总是显示的进程 (none=清空)")
+	fmt.Println("    byte-units <binary|decimal> - 字节单位: binary=KiB/MiB (1024), decimal=KB/MB (1000), -=默认")
+	fmt.Println("    columns <列,...>            - system top/target list 显示的列，如 cpu,mem,disk_read,disk_write (all=全部)")
+	fmt.Println("    precision <0-3>             - system top/target list 中 CPU% 的小数位数 (-=默认 1 位)")
 	fmt.Println()
 	fmt.Println(c.cli.formatter.Info("示例: config set interval 3"))
 	fmt.Println(c.cli.formatter.Info("示例: config set proc-cpu 60"))
@@ -73,6 +118,10 @@ func (c *ConfigCommand) PrintHelp() {
 // show 显示当前配置
 func (c *ConfigCommand) show() {
 	cfg := c.cli.config
+	if c.cli.jsonMode() {
+		c.cli.printJSON(cfg)
+		return
+	}
 	f := c.cli.formatter
 
 	fmt.Println()
@@ -82,19 +131,39 @@ func (c *ConfigCommand) show() {
 	// 基础配置
 	fmt.Println(f.Bold("\n[基础配置]"))
 	fmt.Printf("  配置文件:       %s\n", c.cli.configFile)
+	c.printDrift(config.CheckDrift(c.cli.configFile, cfg))
 	fmt.Printf("  采样间隔:       %d 秒\n", cfg.Sampling.Interval)
+	fmt.Printf("  CPU口径:        %s\n", c.cli.monitor.GetCPUStyle())
+	fmt.Printf("  去除.exe后缀:   %s\n", map[bool]string{true: "是", false: "否"}[cfg.Sampling.StripExeSuffix])
+	fmt.Printf("  并发采集:       %d 协程 (0/1=顺序采集，重启生效)\n", cfg.Sampling.CollectWorkers)
+	if cfg.Sampling.SampleJitterMS > 0 {
+		fmt.Printf("  采样随机偏移:   %d 毫秒 (%s，重启生效)\n", cfg.Sampling.SampleJitterMS,
+			map[bool]string{true: "每轮", false: "仅首次"}[cfg.Sampling.SampleJitterEachTick])
+	}
 	fmt.Printf("  Web服务:        %s (地址: %s)\n", 
 		map[bool]string{true: f.StatusOK("启用"), false: f.StatusError("禁用")}[cfg.Server.Enabled],
 		cfg.Server.Addr)
+	fmt.Printf("  自动开始监控:   %s (当前: %s)\n", map[bool]string{true: "是", false: "否"}[cfg.Server.AutoStart],
+		map[bool]string{true: f.StatusOK("运行中"), false: f.StatusError("未运行")}[c.cli.monitor.IsRunning()])
+	fmt.Printf("  Web只读模式:    %s\n", map[bool]string{true: "是", false: "否"}[cfg.Server.ReadOnly])
+	fmt.Printf("  Web连接超时:    %s\n", formatServerTimeouts(cfg.Server.Timeouts))
+	fmt.Printf("  统计网卡:       %s\n", formatNetMonInterfaces(cfg.NetMon))
+	fmt.Printf("  时区:           %s\n", timefmt.ZoneName())
+	fmt.Printf("  时间格式:       %s\n", formatTimeFormat(cfg.Logging.TimeFormat))
+	fmt.Printf("  消息语言:       %s\n", i18n.Language())
 	fmt.Printf("  日志目录:       %s\n", cfg.Logging.Dir)
 	fmt.Printf("  控制台日志:     %s\n", map[bool]string{true: "是", false: "否"}[cfg.Logging.ConsoleOutput])
 	fmt.Printf("  文件日志:       %s\n", map[bool]string{true: "是", false: "否"}[cfg.Logging.FileOutput])
+	fmt.Printf("  日志保留:       %s\n", formatRetention(cfg.Logging))
+	fmt.Printf("  指标日志编码:   %s\n", formatMetricFormat(cfg.Logging.MetricFormat))
+	fmt.Printf("  转发 syslog:    %s\n", formatSyslog(cfg.Logging.Syslog))
 	
 	// 影响分析配置
 	fmt.Println(f.Bold("\n[影响分析]"))
 	fmt.Printf("  功能状态:       %s\n", 
 		map[bool]string{true: f.StatusOK("启用"), false: f.StatusError("禁用")}[cfg.Impact.Enabled])
 	fmt.Printf("  分析间隔:       %d 秒\n", cfg.Impact.AnalysisInterval)
+	fmt.Printf("  并发分析:       %d 协程 (0/1=顺序执行)\n", cfg.Impact.AnalysisWorkers)
 	fmt.Printf("  Top进程数:      %d\n", cfg.Impact.TopNProcesses)
 	
 	// 系统级阈值
@@ -103,25 +172,92 @@ func (c *ConfigCommand) show() {
 	fmt.Printf("  内存:           %.0f%%\n", cfg.Impact.MemoryThreshold)
 	fmt.Printf("  磁盘IO:         %.0f MB/s\n", cfg.Impact.DiskIOThreshold)
 	fmt.Printf("  网络:           %.0f MB/s\n", cfg.Impact.NetworkThreshold)
+	fmt.Printf("  单核饱和:       %.0f%% (0=禁用)\n", cfg.Impact.CPUCoreThreshold)
+	fmt.Printf("  进程启停:       %d 个/分 (0=禁用)\n", cfg.Impact.ChurnThreshold)
+	fmt.Printf("  宿主机抢占:     %.0f%% (0=禁用)\n", cfg.Impact.StealThreshold)
+	fmt.Printf("  虚拟化争用:     运行等待 %.0f%% (0=禁用)\n", cfg.Impact.VirtRunWaitThreshold)
+	fmt.Printf("  趋势预测:       %s\n", formatTrend(cfg.Impact))
 	
 	// 进程级阈值
 	fmt.Println(f.Bold("\n[进程级阈值] (0=禁用检测)"))
 	fmt.Printf("  CPU:            %.0f%%\n", cfg.Impact.ProcCPUThreshold)
 	fmt.Printf("  内存:           %.0f MB\n", cfg.Impact.ProcMemoryThreshold)
 	fmt.Printf("  内存增速:       %.0f MB/s\n", cfg.Impact.ProcMemGrowthThreshold)
-	fmt.Printf("  虚拟内存:       %.0f MB\n", cfg.Impact.ProcVMSThreshold)
+	fmt.Printf("  虚拟内存:       %.0f MB%s\n", cfg.Impact.ProcVMSThreshold, formatMappedVMS(cfg.Impact.ExcludeMappedVMS))
 	fmt.Printf("  线程数:         %d\n", cfg.Impact.ProcThreadsThreshold)
 	fmt.Printf("  句柄数:         %d\n", cfg.Impact.ProcFDsThreshold)
+	fmt.Printf("  句柄增速:       %.0f 个/分\n", cfg.Impact.ProcFDGrowthThreshold)
 	fmt.Printf("  打开文件数:     %d\n", cfg.Impact.ProcOpenFilesThreshold)
 	fmt.Printf("  磁盘读:         %.0f MB/s\n", cfg.Impact.ProcDiskReadThreshold)
 	fmt.Printf("  磁盘写:         %.0f MB/s\n", cfg.Impact.ProcDiskWriteThreshold)
 	fmt.Printf("  网络收:         %.0f MB/s\n", cfg.Impact.ProcNetRecvThreshold)
 	fmt.Printf("  网络发:         %.0f MB/s\n", cfg.Impact.ProcNetSendThreshold)
+	fmt.Printf("  僵尸子进程:     %d 个 (0=禁用)\n", cfg.Impact.ZombieThreshold)
 	
 	// 资源检测间隔
 	fmt.Println(f.Bold("\n[资源检测间隔]"))
 	fmt.Printf("  文件检测:       %d 秒\n", cfg.Impact.FileCheckInterval)
 	fmt.Printf("  端口检测:       %d 秒\n", cfg.Impact.PortCheckInterval)
+
+	// 显示配置
+	fmt.Println(f.Bold("\n[显示配置] (system top 高亮、常驻进程、进程显示名称)"))
+	fmt.Printf("  CPU黄色:        >%.0f%%\n", cfg.Display.TopHighlightWarn)
+	fmt.Printf("  CPU红色:        >%.0f%%\n", cfg.Display.TopHighlightCrit)
+	fmt.Printf("  内存高亮:       >%.0f MB (0=不高亮)\n", cfg.Display.TopHighlightMemMB)
+	if cfg.Display.ByteUnits != "" {
+		fmt.Printf("  字节单位:       %s\n", cfg.Display.ByteUnits)
+	}
+	if len(cfg.Display.TableColumns) > 0 {
+		fmt.Printf("  表格列:         %s\n", strings.Join(cfg.Display.TableColumns, ", "))
+	}
+	fmt.Printf("  CPU%%小数位:     %d\n", cfg.Display.CPUPrecision())
+	if len(cfg.Display.PinnedProcesses) > 0 {
+		fmt.Printf("  常驻进程:       %s\n", strings.Join(cfg.Display.PinnedProcesses, ", "))
+	}
+	if len(cfg.Display.NameAliases) > 0 {
+		names := make([]string, 0, len(cfg.Display.NameAliases))
+		for name := range cfg.Display.NameAliases {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		fmt.Println("  显示名称:")
+		for _, name := range names {
+			fmt.Printf("    %-20s → %s\n", name, cfg.Display.NameAliases[name])
+		}
+	}
+
+	// 值班报告
+	shifts := cfg.Report.Shifts
+	if len(shifts) == 0 {
+		shifts = config.DefaultShifts()
+	}
+	names := make([]string, len(shifts))
+	for i, s := range shifts {
+		names[i] = fmt.Sprintf("%s %02d:00", s.Name, s.StartHour)
+	}
+	fmt.Println(f.Bold("\n[值班报告]"))
+	fmt.Printf("  值次:           %s (配置文件 report.shifts)\n", strings.Join(names, ", "))
+
+	// 自动重启
+	fmt.Println(f.Bold("\n[自动重启]"))
+	fmt.Printf("  总开关:         %s (配置文件 watchdog.allow_restart，重启生效)\n",
+		map[bool]string{true: f.StatusOK("开启"), false: "关闭"}[cfg.Watchdog.AllowRestart])
+
+	// 指标推送
+	fmt.Println(f.Bold("\n[指标推送]"))
+	if in := cfg.Influx; in.Enabled {
+		interval := in.Interval
+		if interval == 0 {
+			interval = influx.DefaultInterval
+		}
+		endpoint := in.URL
+		if u, err := influx.ParseURL(in.URL); err == nil {
+			endpoint = u.Redacted()
+		}
+		fmt.Printf("  推送地址:       %s (每 %d 秒，配置文件 influx，重启生效)\n", endpoint, interval)
+	} else {
+		fmt.Println("  推送地址:       关闭 (配置文件 influx.enabled)")
+	}
 	
 	fmt.Println(f.Divider(60))
 	fmt.Println(f.Info("使用 'config set <key> <value>' 修改配置"))
@@ -148,16 +284,67 @@ func (c *ConfigCommand) set(args []string) {
 		var v int
 		if v, err = strconv.Atoi(value); err == nil && v > 0 {
 			cfg.Sampling.Interval = v
-			changed = true
+			err = c.cli.monitor.SetSampleInterval(v)
+			changed = err == nil
 		} else {
 			err = fmt.Errorf("间隔必须是正整数")
 		}
+	case "cpu-style":
+		if err = c.cli.monitor.SetCPUStyle(value); err == nil {
+			cfg.Sampling.CPUStyle = value
+			changed = true
+		}
 	case "server.addr":
 		cfg.Server.Addr = value
 		changed = true
 	case "server.enabled":
 		cfg.Server.Enabled = value == "true" || value == "1"
 		changed = true
+	case "server.auto_start":
+		cfg.Server.AutoStart = value == "true" || value == "1"
+		changed = true
+	case "server.read_only":
+		cfg.Server.ReadOnly = value == "true" || value == "1"
+		changed = true
+	case "netmon.interfaces", "netmon.exclude":
+		var patterns []string
+		if patterns, err = parseInterfacePatterns(value); err == nil {
+			if key == "netmon.interfaces" {
+				cfg.NetMon.Interfaces = patterns
+			} else {
+				cfg.NetMon.ExcludeInterfaces = patterns
+			}
+			changed = true
+			fmt.Println(f.Warning("网卡过滤需重启服务后生效"))
+		}
+	case "time-zone", "time-format":
+		zone, layout := cfg.Logging.TimeZone, cfg.Logging.TimeFormat
+		v := strings.Join(args[1:], " ")
+		if v == "-" {
+			v = ""
+		}
+		if key == "time-zone" {
+			zone = v
+		} else {
+			layout = v
+		}
+		if zone != "" {
+			if _, err = time.LoadLocation(zone); err != nil {
+				break
+			}
+		}
+		timefmt.Configure(zone, layout)
+		cfg.Logging.TimeZone, cfg.Logging.TimeFormat = zone, layout
+		value = v
+		changed = true
+	case "language":
+		var lang string
+		if lang, err = i18n.Normalize(value); err == nil {
+			i18n.SetLanguage(lang)
+			cfg.Language = lang
+			value = lang
+			changed = true
+		}
 
 	// 系统级阈值
 	case "cpu-threshold":
@@ -184,6 +371,30 @@ func (c *ConfigCommand) set(args []string) {
 			cfg.Impact.NetworkThreshold = v
 			changed = true
 		}
+	case "core-threshold":
+		var v float64
+		if v, err = strconv.ParseFloat(value, 64); err == nil && v >= 0 {
+			cfg.Impact.CPUCoreThreshold = v
+			changed = true
+		}
+	case "churn-threshold":
+		var v int
+		if v, err = strconv.Atoi(value); err == nil && v >= 0 {
+			cfg.Impact.ChurnThreshold = v
+			changed = true
+		}
+	case "steal-threshold":
+		var v float64
+		if v, err = strconv.ParseFloat(value, 64); err == nil && v >= 0 && v <= 100 {
+			cfg.Impact.StealThreshold = v
+			changed = true
+		}
+	case "run-wait-threshold":
+		var v float64
+		if v, err = strconv.ParseFloat(value, 64); err == nil && v >= 0 {
+			cfg.Impact.VirtRunWaitThreshold = v
+			changed = true
+		}
 
 	// 进程级阈值
 	case "proc-cpu":
@@ -234,6 +445,93 @@ func (c *ConfigCommand) set(args []string) {
 			cfg.Impact.ProcNetSendThreshold = v
 			changed = true
 		}
+	case "zombie-threshold":
+		var v int
+		if v, err = strconv.Atoi(value); err == nil && v >= 0 {
+			cfg.Impact.ZombieThreshold = v
+			changed = true
+		}
+
+	// 显示配置
+	case "top-warn":
+		var v float64
+		if v, err = strconv.ParseFloat(value, 64); err == nil {
+			if v < 0 || v > cfg.Display.TopHighlightCrit {
+				err = fmt.Errorf("需在 0 与红色阈值 %.0f 之间", cfg.Display.TopHighlightCrit)
+			} else {
+				cfg.Display.TopHighlightWarn = v
+				changed = true
+			}
+		}
+	case "top-crit":
+		var v float64
+		if v, err = strconv.ParseFloat(value, 64); err == nil {
+			if v < cfg.Display.TopHighlightWarn {
+				err = fmt.Errorf("不能小于黄色阈值 %.0f", cfg.Display.TopHighlightWarn)
+			} else {
+				cfg.Display.TopHighlightCrit = v
+				changed = true
+			}
+		}
+	case "top-mem":
+		var v float64
+		if v, err = strconv.ParseFloat(value, 64); err == nil && v >= 0 {
+			cfg.Display.TopHighlightMemMB = v
+			changed = true
+		}
+	case "pinned", "pinned-processes":
+		names := []string{}
+		if value != "none" {
+			for _, name := range strings.Split(value, ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					names = append(names, name)
+				}
+			}
+		}
+		cfg.Display.PinnedProcesses = names
+		changed = true
+	case "byte-units":
+		v := value
+		if v == "-" {
+			v = format.UnitsDefault
+		}
+		if err = format.SetByteUnits(v); err == nil {
+			cfg.Display.ByteUnits = v
+			changed = true
+		}
+	case "columns", "table-columns":
+		var names []string
+		if value != "all" {
+			for _, name := range strings.Split(value, ",") {
+				if name = strings.ToLower(strings.TrimSpace(name)); name == "" {
+					continue
+				}
+				if !config.IsTableColumn(name) {
+					err = fmt.Errorf("未知的列 %q，可用列: %s", name, strings.Join(config.TableColumnNames, ", "))
+					break
+				}
+				names = append(names, name)
+			}
+		}
+		if err == nil {
+			cfg.Display.TableColumns = names
+			changed = true
+		}
+	case "precision":
+		if value == "-" {
+			cfg.Display.Precision = nil
+			changed = true
+			break
+		}
+		var v int
+		if v, err = strconv.Atoi(value); err == nil {
+			if v < 0 || v > config.MaxPrecision {
+				err = fmt.Errorf("需在 0 与 %d 之间", config.MaxPrecision)
+			} else {
+				cfg.Display.Precision = &v
+				changed = true
+			}
+		}
 
 	default:
 		fmt.Println(f.Error(fmt.Sprintf("未知配置项: %s", key)))
@@ -253,7 +551,9 @@ func (c *ConfigCommand) set(args []string) {
 		}
 		
 		// 自动保存到文件
-		if err := config.SaveConfig(c.cli.configFile, c.cli.config); err != nil {
+		err := config.SaveConfig(c.cli.configFile, c.cli.config)
+		c.cli.audit("config.set", map[string]string{"key": key, "value": value}, err)
+		if err != nil {
 			fmt.Println(f.Warning(fmt.Sprintf("保存配置失败: %v", err)))
 		}
 		
@@ -263,7 +563,9 @@ func (c *ConfigCommand) set(args []string) {
 
 // save 保存配置
 func (c *ConfigCommand) save() {
-	if err := config.SaveConfig(c.cli.configFile, c.cli.config); err != nil {
+	err := config.SaveConfig(c.cli.configFile, c.cli.config)
+	c.cli.audit("config.save", map[string]string{"file": c.cli.configFile}, err)
+	if err != nil {
 		fmt.Println(c.cli.formatter.Error(fmt.Sprintf("保存失败: %v", err)))
 		return
 	}
@@ -273,17 +575,311 @@ func (c *ConfigCommand) save() {
 // reload 重新加载配置
 func (c *ConfigCommand) reload() {
 	cfg, err := config.LoadConfig(c.cli.configFile)
+	c.cli.audit("config.reload", map[string]string{"file": c.cli.configFile}, err)
 	if err != nil {
 		fmt.Println(c.cli.formatter.Error(fmt.Sprintf("加载失败: %v", err)))
 		return
 	}
-	
-	c.cli.config = cfg
-	
-	// 更新影响分析器配置
-	if analyzer := c.cli.monitor.GetImpactAnalyzer(); analyzer != nil {
-		analyzer.UpdateConfig(cfg.Impact)
+
+	// 原地更新，Web 服务与 CLI 共用同一份配置
+	for _, w := range profile.ApplyConfig(c.cli.config, cfg, c.cli.monitor) {
+		fmt.Println(c.cli.formatter.Warning(w))
 	}
-	
 	fmt.Println(c.cli.formatter.Success("配置已重新加载"))
 }
+
+// applyFile 配置文件在运行中被修改（配置漂移）时，列出差异并按文件更新运行中的配置
+func (c *ConfigCommand) applyFile() {
+	f := c.cli.formatter
+	drift := config.CheckDrift(c.cli.configFile, c.cli.config)
+	if !drift.Drift {
+		fmt.Println(f.Info("运行中的配置与配置文件一致，无需应用"))
+		return
+	}
+	if drift.Error != "" {
+		c.cli.audit("config.apply_file", map[string]string{"file": c.cli.configFile}, errors.New(drift.Error))
+		fmt.Println(f.Error(fmt.Sprintf("无法应用配置文件: %s", drift.Error)))
+		return
+	}
+
+	cfg, err := config.LoadConfig(c.cli.configFile)
+	c.cli.audit("config.apply_file", map[string]interface{}{"file": c.cli.configFile, "diff": drift.Diff}, err)
+	if err != nil {
+		fmt.Println(f.Error(fmt.Sprintf("加载失败: %v", err)))
+		return
+	}
+	for _, w := range profile.ApplyConfig(c.cli.config, cfg, c.cli.monitor) {
+		fmt.Println(f.Warning(w))
+	}
+	for _, d := range drift.Diff {
+		fmt.Printf("  %-36s %s → %s\n", d.Field, formatDriftValue(d.Active), formatDriftValue(d.File))
+	}
+	fmt.Println(f.Success(fmt.Sprintf("已按配置文件更新 %d 项配置", len(drift.Diff))))
+}
+
+// printDrift 配置文件在运行中被修改时显示与运行中配置的差异
+func (c *ConfigCommand) printDrift(drift config.Drift) {
+	if !drift.Drift {
+		return
+	}
+	f := c.cli.formatter
+	if drift.Error != "" {
+		fmt.Printf("  配置漂移:       %s\n", f.Warning(drift.Error))
+		return
+	}
+	fmt.Printf("  配置漂移:       %s\n", f.Warning(fmt.Sprintf("配置文件已被修改，%d 项与运行中的配置不同", len(drift.Diff))))
+	for _, d := range drift.Diff {
+		fmt.Printf("    %-34s 运行中 %s，文件 %s\n", d.Field, formatDriftValue(d.Active), formatDriftValue(d.File))
+	}
+	if drift.Truncated {
+		fmt.Println("    ...")
+	}
+	fmt.Println("  " + f.Info("'config apply-file' 按文件更新运行中的配置，'config save' 以运行中的配置覆盖文件"))
+}
+
+// formatDriftValue 格式化差异中的值，字段不存在时显示 -
+func formatDriftValue(v json.RawMessage) string {
+	if len(v) == 0 {
+		return "-"
+	}
+	return format.Truncate(string(v), 40)
+}
+
+// exportProfile 导出监控配置档案
+func (c *ConfigCommand) exportProfile(args []string) {
+	if len(args) == 0 {
+		fmt.Println(c.cli.formatter.Error("用法: config export <文件>"))
+		return
+	}
+
+	doc := profile.Export(c.cli.config, c.cli.monitor)
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err == nil {
+		err = os.WriteFile(args[0], data, 0644)
+	}
+	if err != nil {
+		fmt.Println(c.cli.formatter.Error(fmt.Sprintf("导出失败: %v", err)))
+		return
+	}
+	fmt.Println(c.cli.formatter.Success(fmt.Sprintf("已导出 %d 个监控目标及配置到 %s", len(doc.Targets), args[0])))
+}
+
+// importProfile 导入监控配置档案
+// 先计算并显示变更，--dry-run 时到此为止；否则逐项应用，失败时回滚已执行的目标变更
+func (c *ConfigCommand) importProfile(args []string) {
+	f := c.cli.formatter
+	dryRun := false
+	var file string
+	for _, arg := range args {
+		switch arg {
+		case "--dry-run", "-n":
+			dryRun = true
+		default:
+			file = arg
+		}
+	}
+	if file == "" {
+		fmt.Println(f.Error("用法: config import <文件> [--dry-run]"))
+		return
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		fmt.Println(f.Error(fmt.Sprintf("读取失败: %v", err)))
+		return
+	}
+	doc, err := profile.Parse(data, c.cli.config)
+	if err != nil {
+		fmt.Println(f.Error(fmt.Sprintf("导入失败: %v", err)))
+		return
+	}
+	plan, err := profile.NewPlan(doc, c.cli.config, c.cli.monitor)
+	if err != nil {
+		fmt.Println(f.Error(fmt.Sprintf("校验失败: %v", err)))
+		return
+	}
+
+	fmt.Println()
+	fmt.Println(f.Header(fmt.Sprintf("导入配置档案 - %s (来自 %s, v%s)", file, doc.Host, doc.AgentVersion)))
+	fmt.Println(f.Divider(70))
+	if len(plan.Changes) == 0 {
+		fmt.Println(f.Info("  与当前配置一致，无需变更"))
+	}
+	for _, ch := range plan.Changes {
+		target := ""
+		if ch.Target != "" {
+			target = ch.Target
+			if ch.PID != 0 {
+				target += fmt.Sprintf(" (PID %d)", ch.PID)
+			}
+			target += " "
+		}
+		fmt.Printf("  %s %s%s\n", c.formatChangeAction(ch.Action), target, ch.Detail)
+	}
+	for _, w := range plan.Warnings {
+		fmt.Println(f.Warning("  警告: " + w))
+	}
+	fmt.Println(f.Divider(70))
+
+	if dryRun || len(plan.Changes) == 0 {
+		if dryRun {
+			fmt.Println(f.Info("dry-run 模式，未做任何修改"))
+		}
+		return
+	}
+
+	if err := plan.Apply(c.cli.config, c.cli.monitor); err != nil {
+		c.cli.audit("config.import", plan.Changes, err)
+		fmt.Println(f.Error(fmt.Sprintf("导入失败，已回滚: %v", err)))
+		return
+	}
+	err = config.SaveConfig(c.cli.configFile, c.cli.config)
+	c.cli.audit("config.import", plan.Changes, err)
+	if err != nil {
+		fmt.Println(f.Warning(fmt.Sprintf("保存配置失败: %v", err)))
+	}
+	fmt.Println(f.Success("配置档案已导入 (已保存)"))
+}
+
+// formatChangeAction 格式化导入变更类型
+func (c *ConfigCommand) formatChangeAction(action string) string {
+	f := c.cli.formatter
+	switch action {
+	case "add":
+		return f.Success("[添加]")
+	case "update":
+		return f.Info("[更新]")
+	case "remove":
+		return f.Error("[移除]")
+	case "skip":
+		return f.Warning("[跳过]")
+	case "unresolved":
+		return f.Warning("[未找到]")
+	case "failed":
+		return f.Error("[失败]")
+	default:
+		return "[设置]"
+	}
+}
+
+// parseInterfacePatterns 解析逗号分隔的网卡名（支持通配符），"-" 表示清空
+func parseInterfacePatterns(value string) ([]string, error) {
+	if value == "-" || value == "" {
+		return nil, nil
+	}
+	var patterns []string
+	for _, p := range strings.Split(value, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("网卡通配符无效: %s", p)
+		}
+		patterns = append(patterns, p)
+	}
+	return patterns, nil
+}
+
+// formatNetMonInterfaces 格式化网卡过滤配置
+func formatNetMonInterfaces(cfg config.NetMonConfig) string {
+	if len(cfg.Interfaces) == 0 && len(cfg.ExcludeInterfaces) == 0 {
+		return "全部"
+	}
+	var parts []string
+	if len(cfg.Interfaces) > 0 {
+		parts = append(parts, strings.Join(cfg.Interfaces, ", "))
+	} else {
+		parts = append(parts, "全部")
+	}
+	if len(cfg.ExcludeInterfaces) > 0 {
+		parts = append(parts, "排除 "+strings.Join(cfg.ExcludeInterfaces, ", "))
+	}
+	return strings.Join(parts, "，")
+}
+
+// formatTimeFormat 时间格式的显示文本
+func formatTimeFormat(layout string) string {
+	if layout == "" {
+		return "默认"
+	}
+	return fmt.Sprintf("%s (示例: %s)", layout, timefmt.Format(time.Now(), layout))
+}
+
+// formatRetention 日志保留策略的显示文本
+func formatRetention(cfg config.LoggingConfig) string {
+	var parts []string
+	if cfg.MaxAgeDays > 0 {
+		parts = append(parts, fmt.Sprintf("%d 天", cfg.MaxAgeDays))
+	}
+	if cfg.MaxTotalMB > 0 {
+		parts = append(parts, fmt.Sprintf("总大小 %d MB", cfg.MaxTotalMB))
+	}
+	if len(parts) == 0 {
+		return "不清理"
+	}
+	return strings.Join(parts, "，")
+}
+
+// formatServerTimeouts Web 连接超时的显示文本
+func formatServerTimeouts(t config.ServerTimeouts) string {
+	format := func(n int) string {
+		if n <= 0 {
+			return "不限"
+		}
+		return fmt.Sprintf("%d秒", n)
+	}
+	keepalive := "默认"
+	if t.KeepAliveSeconds > 0 {
+		keepalive = fmt.Sprintf("%d秒", t.KeepAliveSeconds)
+	}
+	return fmt.Sprintf("读头 %s，读 %s，写 %s，空闲 %s，keepalive %s",
+		format(t.ReadHeaderSeconds), format(t.ReadSeconds), format(t.WriteSeconds),
+		format(t.IdleSeconds), keepalive)
+}
+
+// formatSyslog 日志转发到 syslog 的显示文本
+func formatSyslog(cfg config.SyslogConfig) string {
+	if !cfg.Enabled {
+		return "关闭"
+	}
+	facility := cfg.Facility
+	if facility == "" {
+		facility = "local0"
+	}
+	dest := fmt.Sprintf("%s %s", cfg.Network, cfg.Addr)
+	if strings.EqualFold(cfg.Network, "local") {
+		dest = "本机 syslog"
+	}
+	categories := cfg.Categories
+	if categories == nil {
+		categories = logger.DefaultSyslogCategories
+	}
+	var what []string
+	if len(categories) > 0 {
+		what = append(what, strings.Join(categories, "/"))
+	}
+	switch level := strings.ToUpper(cfg.MinLevel); level {
+	case "NONE":
+	case "":
+		what = append(what, "WARN 及以上")
+	default:
+		what = append(what, level+" 及以上")
+	}
+	if len(what) == 0 {
+		what = append(what, "无")
+	}
+	return fmt.Sprintf("%s，facility %s，转发 %s", dest, facility, strings.Join(what, "、"))
+}
+
+// formatMetricFormat 指标日志编码的显示文本
+func formatMetricFormat(format string) string {
+	f, err := logger.NormalizeMetricFormat(format)
+	switch {
+	case err != nil:
+		return fmt.Sprintf("%s (无效，使用 json)", format)
+	case f == logger.MetricFormatCompact:
+		return "compact (紧凑编码)"
+	}
+	return "json"
+}
@@ -2,11 +2,16 @@ package cli
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"monitor-agent/config"
+	"monitor-agent/format"
+	"monitor-agent/impact"
+	"monitor-agent/timefmt"
+	"monitor-agent/types"
 )
 
 // ImpactCommand 影响分析命令组
@@ -26,17 +31,25 @@ func (cmd *ImpactCommand) Handle(subCmd string, args []string) {
 		cmd.listImpacts(args)
 	case "summary", "sum":
 		cmd.showSummary()
+	case "offenders", "top":
+		cmd.showOffenders(args)
+	case "status", "diag":
+		cmd.showStatus()
 	case "config", "cfg":
 		cmd.showConfig()
 	case "set":
 		cmd.setConfig(args)
 	case "clear":
 		cmd.clearImpacts()
+	case "pause":
+		cmd.pause(args)
 	case "help", "h":
 		cmd.PrintHelp()
 	default:
-		fmt.Println(cmd.cli.formatter.Error(fmt.Sprintf("未知子命令: %s", subCmd)))
-		cmd.PrintHelp()
+		cmd.cli.printError(fmt.Sprintf("未知子命令: %s", subCmd))
+		if !cmd.cli.jsonMode() {
+			cmd.PrintHelp()
+		}
 	}
 }
 
@@ -44,15 +57,21 @@ func (cmd *ImpactCommand) Handle(subCmd string, args []string) {
 func (cmd *ImpactCommand) PrintHelp() {
 	fmt.Println(cmd.cli.formatter.Header("\n=== 影响分析命令 (impact) ==="))
 	fmt.Println()
-	fmt.Println("  list [n]              - 列出最近的影响事件 (默认20)")
-	fmt.Println("  summary               - 显示影响统计汇总")
+	fmt.Println("  list [n] [--grouped] [--min <级别>]")
+	fmt.Println("                        - 列出最近的影响事件 (默认20，--grouped 合并同一目标同类影响，")
+	fmt.Println("                          --min 只显示不低于该级别的事件: low/medium/high/critical)")
+	fmt.Println("  summary               - 显示影响统计汇总 (含健康评分)")
+	fmt.Println("  offenders [n]         - 最近7天影响目标最多的进程排行 (默认10)")
+	fmt.Println("  status                - 显示分析器运行诊断 (周期耗时、文件/端口检测缓存、最近错误)")
 	fmt.Println("  config                - 显示影响分析配置")
 	fmt.Println("  set <key> <value>     - 设置影响分析参数 (自动保存)")
 	fmt.Println("  clear                 - 清除所有影响事件记录")
+	fmt.Println("  pause <时长> [原因]   - 暂停风险告警 (全局维护模式，如 30m、2h)，pause end 提前恢复")
 	fmt.Println()
 	fmt.Println(cmd.cli.formatter.Info("系统级阈值: cpu, memory, disk_io, network"))
 	fmt.Println(cmd.cli.formatter.Info("进程级阈值: proc_cpu, proc_mem, proc_fds, proc_threads..."))
-	fmt.Println(cmd.cli.formatter.Info("其他: enabled, interval"))
+	fmt.Println(cmd.cli.formatter.Info("评分权重: weight_critical, weight_high, weight_medium, weight_low"))
+	fmt.Println(cmd.cli.formatter.Info("其他: enabled, interval, workers, skip_warn"))
 	fmt.Println()
 	fmt.Println(cmd.cli.formatter.Info("示例: impact set cpu 80"))
 	fmt.Println(cmd.cli.formatter.Info("示例: impact set proc_mem 500"))
@@ -60,13 +79,30 @@ func (cmd *ImpactCommand) PrintHelp() {
 
 func (cmd *ImpactCommand) listImpacts(args []string) {
 	count := 20
-	if len(args) > 0 {
-		if n, err := strconv.Atoi(args[0]); err == nil && n > 0 {
+	grouped := false
+	minSeverity := ""
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--grouped" || arg == "-g" {
+			grouped = true
+		} else if arg == "--min" {
+			if i+1 >= len(args) || !impact.IsSeverity(strings.ToLower(args[i+1])) {
+				cmd.cli.printError("--min 需要严重级别: low, medium, high, critical")
+				return
+			}
+			i++
+			minSeverity = strings.ToLower(args[i])
+		} else if n, err := strconv.Atoi(arg); err == nil && n > 0 {
 			count = n
 		}
 	}
 
-	impacts := cmd.cli.monitor.GetImpactEvents()
+	if cmd.cli.jsonMode() {
+		cmd.cli.printJSON(cmd.cli.monitor.GetRecentImpactsMin(count, grouped, minSeverity))
+		return
+	}
+
+	impacts := cmd.cli.monitor.GetRecentImpactsMin(0, grouped, minSeverity)
 	if len(impacts) == 0 {
 		fmt.Println(cmd.cli.formatter.Info("暂无影响事件"))
 		return
@@ -96,21 +132,43 @@ func (cmd *ImpactCommand) listImpacts(args []string) {
 	for i := len(impacts) - 1; i >= start; i-- {
 		imp := impacts[i]
 		
-		timeStr := imp.Timestamp.Format("01-02 15:04:05")
+		timeStr := timefmt.In(imp.Timestamp).Format("01-02 15:04:05")
 		typeStr := cmd.formatImpactType(imp.ImpactType)
-		procStr := cmd.cli.formatter.Truncate(imp.SourceName, 18)
+		procStr := format.Truncate(imp.SourceName, 18)
 		levelStr := cmd.formatImpactLevel(imp.Severity)
-		detailStr := cmd.cli.formatter.Truncate(imp.Description, 38)
+		detailStr := format.Truncate(imp.Description, 38)
 
 		fmt.Printf("%-20s%-10s%-20s%-10s%-40s\n",
 			timeStr, typeStr, procStr, levelStr, detailStr)
+		for _, c := range imp.Contributors {
+			fmt.Printf("%-30s%-20s%-10s%-40s\n", "", "└ "+format.Truncate(c.SourceName, 16),
+				cmd.formatImpactLevel(c.Severity), format.Truncate(c.Description, 38))
+		}
+		printImpactTarget(cmd.cli.formatter, imp)
+		printComments(cmd.cli.formatter, imp.Comments)
 	}
 
 	fmt.Println()
-	fmt.Printf(cmd.cli.formatter.Info("共 %d 条影响事件"), len(impacts))
+	if grouped {
+		fmt.Printf(cmd.cli.formatter.Info("共 %d 条影响事件 (已合并同一目标同类影响)"), len(impacts))
+	} else {
+		fmt.Printf(cmd.cli.formatter.Info("共 %d 条影响事件"), len(impacts))
+	}
 	fmt.Println()
 }
 
+// printImpactTarget 显示影响发生时目标的资源占用，用于判断影响是否确实波及目标（旧版本记录的事件没有这些指标，不显示）
+func printImpactTarget(f *Formatter, imp types.ImpactEvent) {
+	m := imp.Metrics
+	if m.TargetNumThreads == 0 {
+		return
+	}
+	fmt.Printf("    └ %s CPU %s  内存 %s  磁盘 %s  网络 %s  线程 %d  句柄 %d\n",
+		f.Color(ColorCyan, "目标 "+format.Truncate(imp.TargetName, 16)),
+		format.Percent(m.TargetCPU), format.Bytes(m.TargetMemory), format.BytesRate(m.TargetDiskIO),
+		format.BytesRate(m.TargetNetIO), m.TargetNumThreads, m.TargetNumFDs)
+}
+
 func (cmd *ImpactCommand) formatImpactType(t string) string {
 	switch strings.ToUpper(t) {
 	case "CPU":
@@ -142,11 +200,21 @@ func (cmd *ImpactCommand) formatImpactLevel(level string) string {
 }
 
 func (cmd *ImpactCommand) showSummary() {
+	if cmd.cli.jsonMode() {
+		cmd.cli.printJSON(cmd.cli.monitor.GetImpactSummary(false))
+		return
+	}
+
 	impacts := cmd.cli.monitor.GetImpactEvents()
 	
 	fmt.Println(cmd.cli.formatter.Header("\n=== 影响分析统计 ==="))
 	fmt.Println()
 
+	// 健康评分
+	score, grade := cmd.cli.monitor.GetHealthScore()
+	fmt.Printf("健康评分: %s\n", cmd.formatHealthGrade(score, grade))
+	fmt.Println()
+
 	if len(impacts) == 0 {
 		fmt.Println(cmd.cli.formatter.Info("暂无影响事件"))
 		return
@@ -223,6 +291,122 @@ func (cmd *ImpactCommand) showSummary() {
 	}
 }
 
+// showOffenders 显示最近 7 天影响目标最多的进程排行（按进程名累计，重启后保留）
+func (cmd *ImpactCommand) showOffenders(args []string) {
+	n := 10
+	if len(args) > 0 {
+		if v, err := strconv.Atoi(args[0]); err == nil && v > 0 {
+			n = v
+		}
+	}
+	f := cmd.cli.formatter
+
+	offenders := cmd.cli.monitor.GetImpactOffenders(n)
+	fmt.Println(f.Header(fmt.Sprintf("\n=== 影响源进程排行 (最近7天 Top %d) ===", n)))
+	fmt.Println()
+	if len(offenders) == 0 {
+		fmt.Println(f.Info("最近7天暂无其他进程影响保障对象"))
+		return
+	}
+
+	fmt.Println(f.Bold(fmt.Sprintf("%-6s%-24s%-8s%-8s%-8s%-8s%-8s%-16s%s",
+		"排名", "进程", "总计", "严重", "高", "中", "低", "主要类型", "最近")))
+	fmt.Println(strings.Repeat("-", 100))
+	for _, o := range offenders {
+		fmt.Printf("%-6d%-24s%-8d%-8d%-8d%-8d%-8d%-16s%s\n",
+			o.Rank, format.Truncate(o.Name, 22), o.Total,
+			o.BySeverity["critical"], o.BySeverity["high"], o.BySeverity["medium"], o.BySeverity["low"],
+			impact.ImpactTypeName(o.TopType), timefmt.In(o.LastSeen).Format("01-02 15:04"))
+	}
+	fmt.Println()
+	fmt.Println(f.Info("按进程名累计新产生的影响事件，同名进程重启后合并统计"))
+}
+
+// showStatus 显示影响分析器的运行诊断，用于排查文件/端口冲突检测是否在正常工作
+func (cmd *ImpactCommand) showStatus() {
+	d := cmd.cli.monitor.GetImpactDiagnostics()
+	if cmd.cli.jsonMode() {
+		cmd.cli.printJSON(d)
+		return
+	}
+	f := cmd.cli.formatter
+
+	fmt.Println(f.Header("\n=== 影响分析器状态 ==="))
+	fmt.Println()
+	state := f.StatusOK("运行中")
+	if !d.Enabled {
+		state = f.StatusWarn("未启用")
+	} else if !d.Running {
+		state = f.StatusError("已停止")
+	}
+	fmt.Printf("  状态:           %s\n", state)
+	fmt.Printf("  分析周期:       每 %d 秒，已完成 %d 个，并发 %d 协程\n", d.AnalysisInterval, d.Cycles, d.AnalysisWorkers)
+	fmt.Printf("  最近分析:       %s (耗时 %.1f ms，扫描 %d 个进程)\n", diagTime(d.LastRun), d.LastDurationMs, d.Processes)
+	skipped := fmt.Sprintf("跳过 %d 个周期 (当前连续 %d 个)，超时 %d 个周期", d.SkippedCycles, d.ConsecutiveSkips, d.SlowCycles)
+	if d.SkippedCycles > 0 || d.SlowCycles > 0 {
+		skipped = f.StatusWarn(skipped)
+	}
+	fmt.Printf("  负载保护:       %s\n", skipped)
+	fmt.Println()
+
+	fmt.Println(f.Bold("端口冲突检测:"))
+	fmt.Printf("  检测间隔:       %d 秒\n", d.PortCheckInterval)
+	fmt.Printf("  最近检测:       %s (扫描 %d 个连接)\n", diagTime(d.LastPortCheck), d.ConnectionsScanned)
+	fmt.Printf("  监听端口缓存:   %s 刷新\n", diagTime(d.PortsRefreshedAt))
+	fmt.Println()
+
+	fmt.Println(f.Bold("文件冲突检测:"))
+	fmt.Printf("  检测间隔:       %d 秒\n", d.FileCheckInterval)
+	fmt.Printf("  最近检测:       %s (索引 %d 个文件，%d 个进程无法读取)\n", diagTime(d.LastFileCheck), d.OpenFilesIndexed, d.OpenFilesUnreadable)
+	fmt.Printf("  打开文件缓存:   %s 刷新\n", diagTime(d.FilesRefreshedAt))
+	fmt.Println()
+
+	if len(d.Targets) > 0 {
+		fmt.Println(f.Bold(fmt.Sprintf("%-8s%-24s%-14s%-14s%-14s%s", "PID", "目标", "监听端口", "配置端口", "打开文件", "配置文件")))
+		fmt.Println(strings.Repeat("-", 84))
+		for _, t := range d.Targets {
+			fmt.Printf("%-8d%-24s%-14d%-14d%-14d%d\n", t.PID, format.Truncate(t.Name, 22), t.ListenPorts, t.WatchPorts, t.OpenFiles, t.WatchFiles)
+		}
+		fmt.Println()
+	}
+
+	if len(d.Errors) == 0 {
+		fmt.Println(f.Info("各项检测最近均无错误"))
+		return
+	}
+	fmt.Println(f.Bold("最近错误:"))
+	checks := make([]string, 0, len(d.Errors))
+	for check := range d.Errors {
+		checks = append(checks, check)
+	}
+	sort.Strings(checks)
+	for _, check := range checks {
+		e := d.Errors[check]
+		fmt.Printf("  %-10s %s  %s\n", check, timefmt.Format(e.Time, "01-02 15:04:05"), f.StatusError(e.Error))
+	}
+}
+
+// diagTime 格式化诊断中的时间，未发生时显示「尚未运行」
+func diagTime(t *time.Time) string {
+	if t == nil {
+		return "尚未运行"
+	}
+	return timefmt.Format(*t, "01-02 15:04:05")
+}
+
+// formatHealthGrade 按等级着色显示健康评分
+func (cmd *ImpactCommand) formatHealthGrade(score int, grade string) string {
+	text := fmt.Sprintf("%d (%s)", score, grade)
+	switch grade {
+	case "A", "B":
+		return cmd.cli.formatter.StatusOK(text)
+	case "C", "D":
+		return cmd.cli.formatter.StatusWarn(text)
+	default:
+		return cmd.cli.formatter.StatusError(text)
+	}
+}
+
 func (cmd *ImpactCommand) showConfig() {
 	cfg := cmd.cli.config.Impact
 
@@ -231,12 +415,70 @@ func (cmd *ImpactCommand) showConfig() {
 
 	fmt.Printf("  启用状态: %s\n", cmd.cli.formatter.FormatBool(cfg.Enabled))
 	fmt.Println()
+
+	// 阈值时段
+	active := ""
+	if analyzer := cmd.cli.monitor.GetImpactAnalyzer(); analyzer != nil {
+		active = analyzer.ActiveProfile()
+	}
+	fmt.Println(cmd.cli.formatter.Bold("阈值时段:"))
+	if active == "" {
+		fmt.Println("  当前生效:     基础配置")
+	} else {
+		fmt.Printf("  当前生效:     %s\n", cmd.cli.formatter.Warning(active))
+	}
+	for _, p := range cfg.Profiles {
+		mark := " "
+		if p.Name == active {
+			mark = "*"
+		}
+		fmt.Printf("  %s %-12s %s\n", mark, p.Name, p.Window)
+	}
+	if warnings, err := impact.ValidateProfiles(cfg.Profiles); err != nil {
+		fmt.Println("  " + cmd.cli.formatter.Error(err.Error()))
+	} else {
+		for _, w := range warnings {
+			fmt.Println("  " + cmd.cli.formatter.Warning(w))
+		}
+	}
+	if active != "" {
+		fmt.Println(cmd.cli.formatter.Info("  以下为基础配置，时段内部分阈值被覆盖"))
+	}
+	fmt.Println()
+
+	// 计划维护时段
+	if len(cfg.MaintenanceWindows) > 0 {
+		inWindow := ""
+		if analyzer := cmd.cli.monitor.GetImpactAnalyzer(); analyzer != nil {
+			inWindow = analyzer.ActiveMaintenanceWindow()
+		}
+		fmt.Println(cmd.cli.formatter.Bold("计划维护时段:"))
+		for _, w := range cfg.MaintenanceWindows {
+			mark := " "
+			if w.Name == inWindow {
+				mark = "*"
+			}
+			fmt.Printf("  %s %-12s %s\n", mark, w.Name, w.Window)
+		}
+		if err := impact.ValidateMaintenanceWindows(cfg.MaintenanceWindows); err != nil {
+			fmt.Println("  " + cmd.cli.formatter.Error(err.Error()))
+		}
+		if inWindow != "" {
+			fmt.Println(cmd.cli.formatter.Warning("  当前处于计划维护时段，风险告警已暂停"))
+		}
+		fmt.Println()
+	}
 	
 	fmt.Println(cmd.cli.formatter.Bold("系统级阈值:"))
 	fmt.Printf("  CPU阈值:      %.0f%%\n", cfg.CPUThreshold)
 	fmt.Printf("  内存阈值:     %.0f%%\n", cfg.MemoryThreshold)
 	fmt.Printf("  磁盘IO阈值:   %.0f MB/s\n", cfg.DiskIOThreshold)
 	fmt.Printf("  网络阈值:     %.0f MB/s\n", cfg.NetworkThreshold)
+	fmt.Printf("  单核饱和:     %.0f%% (0=禁用)\n", cfg.CPUCoreThreshold)
+	fmt.Printf("  进程启停:     %d 个/分 (0=禁用)\n", cfg.ChurnThreshold)
+	fmt.Printf("  宿主机抢占:   %.0f%% (0=禁用)\n", cfg.StealThreshold)
+	fmt.Printf("  虚拟化争用:   运行等待 %.0f%% (0=禁用)\n", cfg.VirtRunWaitThreshold)
+	fmt.Printf("  趋势预测:     %s\n", formatTrend(cfg))
 	fmt.Println()
 	
 	fmt.Println(cmd.cli.formatter.Bold("进程级阈值:"))
@@ -244,18 +486,46 @@ func (cmd *ImpactCommand) showConfig() {
 	fmt.Printf("  内存:         %.0f MB\n", cfg.ProcMemoryThreshold)
 	fmt.Printf("  内存增速:     %.0f MB/s\n", cfg.ProcMemGrowthThreshold)
 	fmt.Printf("  句柄数:       %d\n", cfg.ProcFDsThreshold)
+	fmt.Printf("  句柄增速:     %.0f 个/分\n", cfg.ProcFDGrowthThreshold)
 	fmt.Printf("  线程数:       %d\n", cfg.ProcThreadsThreshold)
 	fmt.Printf("  磁盘读:       %.0f MB/s\n", cfg.ProcDiskReadThreshold)
 	fmt.Printf("  磁盘写:       %.0f MB/s\n", cfg.ProcDiskWriteThreshold)
 	fmt.Printf("  网络收:       %.0f MB/s\n", cfg.ProcNetRecvThreshold)
 	fmt.Printf("  网络发:       %.0f MB/s\n", cfg.ProcNetSendThreshold)
+	fmt.Printf("  虚拟内存:     %.0f MB (0=禁用%s)\n", cfg.ProcVMSThreshold, formatMappedVMS(cfg.ExcludeMappedVMS))
+	fmt.Printf("  僵尸子进程:   %d 个 (0=禁用)\n", cfg.ZombieThreshold)
+	fmt.Printf("  目录增长:     %.0f MB/小时 (0=禁用)\n", cfg.DirGrowthThreshold)
+	fmt.Printf("  目录磁盘剩余: %.0f MB (0=禁用)\n", cfg.DirMinFreeMB)
 	fmt.Println()
 	
 	fmt.Println(cmd.cli.formatter.Bold("分析参数:"))
 	fmt.Printf("  分析周期:     %d秒\n", cfg.AnalysisInterval)
+	fmt.Printf("  并发分析:     %d 协程 (0/1=顺序执行)，连续跳过 %d 个周期后告警\n", cfg.AnalysisWorkers, cfg.SkipWarnCycles)
 	fmt.Printf("  最大记录:     %d\n", cfg.HistoryLen)
 	fmt.Printf("  端口检测间隔: %d秒\n", cfg.PortCheckInterval)
 	fmt.Printf("  文件检测间隔: %d秒\n", cfg.FileCheckInterval)
+	fmt.Printf("  忽略回环端口: %s\n", cmd.cli.formatter.FormatBool(cfg.IgnoreLoopbackPorts))
+	fmt.Printf("  排除本程序:   %s\n", cmd.cli.formatter.FormatBool(cfg.ExcludeSelf))
+	fmt.Printf("  持续时间要求: %d秒 (0=立即)\n", cfg.MinDurationSeconds)
+	if len(cfg.MinDurationOverrides) > 0 {
+		keys := make([]string, 0, len(cfg.MinDurationOverrides))
+		for t := range cfg.MinDurationOverrides {
+			keys = append(keys, t)
+		}
+		sort.Strings(keys)
+		for _, t := range keys {
+			fmt.Printf("    %-12s%d秒\n", t+":", cfg.MinDurationOverrides[t])
+		}
+	}
+	fmt.Printf("  解除清除期:   %d秒 (0=立即)\n", cfg.ClearDurationSeconds)
+	fmt.Printf("  日志冷却:     %d秒 (0=每次记录)\n", cfg.LogCooldownSeconds)
+	fmt.Println()
+
+	fmt.Println(cmd.cli.formatter.Bold("健康评分权重 (每个事件扣分):"))
+	fmt.Printf("  严重:         %.0f\n", cfg.ScoreWeightCritical)
+	fmt.Printf("  高级:         %.0f\n", cfg.ScoreWeightHigh)
+	fmt.Printf("  中级:         %.0f\n", cfg.ScoreWeightMedium)
+	fmt.Printf("  低级:         %.0f\n", cfg.ScoreWeightLow)
 }
 
 func (cmd *ImpactCommand) setConfig(args []string) {
@@ -263,16 +533,32 @@ func (cmd *ImpactCommand) setConfig(args []string) {
 		fmt.Println(cmd.cli.formatter.Error("用法: impact set <key> <value>"))
 		fmt.Println()
 		fmt.Println(cmd.cli.formatter.Info("系统级阈值:"))
-		fmt.Println("  cpu, memory, disk_io, network")
+		fmt.Println("  cpu, memory, disk_io, network, cpu_core, churn, steal, run_wait")
 		fmt.Println()
 		fmt.Println(cmd.cli.formatter.Info("进程级阈值:"))
 		fmt.Println("  proc_cpu, proc_mem, proc_mem_growth")
-		fmt.Println("  proc_fds, proc_threads")
+		fmt.Println("  proc_fds, proc_fd_growth, proc_threads")
 		fmt.Println("  proc_disk_read, proc_disk_write")
 		fmt.Println("  proc_net_recv, proc_net_send")
+		fmt.Println("  zombies")
+		fmt.Println("  dir_growth <MB/小时>, dir_min_free <MB>  (目标 watch_dirs 的增长速度和所在磁盘剩余空间)")
+		fmt.Println()
+		fmt.Println(cmd.cli.formatter.Info("健康评分权重:"))
+		fmt.Println("  weight_critical, weight_high, weight_medium, weight_low")
 		fmt.Println()
 		fmt.Println(cmd.cli.formatter.Info("其他:"))
-		fmt.Println("  enabled, interval")
+		fmt.Println("  enabled, interval, ignore_loopback, exclude_self")
+		fmt.Println("  exclude_mapped_vms           (虚拟内存检测不计文件映射，Linux)")
+		fmt.Println()
+		fmt.Println(cmd.cli.formatter.Info("持续时间 (秒，0=立即):"))
+		fmt.Println("  min_duration, clear_duration")
+		fmt.Println("  min_duration.<类型> <秒|->   (按影响类型覆盖，- 取消覆盖，如 min_duration.cpu 30)")
+		fmt.Println("  log_cooldown                 (同一影响在该时间内重复产生不再写日志，0=每次记录)")
+		fmt.Println()
+		fmt.Println(cmd.cli.formatter.Info("趋势预测:"))
+		fmt.Println("  trend_window <秒>            (斜率计算窗口，0=禁用)")
+		fmt.Println("  trend_horizon <分钟>         (预计在该时间内耗尽时告警)")
+		fmt.Println("  trend_limit <%>              (视为耗尽的使用率)")
 		return
 	}
 
@@ -309,6 +595,30 @@ func (cmd *ImpactCommand) setConfig(args []string) {
 			msg = fmt.Sprintf("系统网络阈值: %.0f MB/s", v)
 			updated = true
 		}
+	case "cpu_core", "core_threshold", "core":
+		if v, err := strconv.ParseFloat(value, 64); err == nil {
+			cfg.CPUCoreThreshold = v
+			msg = fmt.Sprintf("单核饱和阈值: %.0f%%", v)
+			updated = true
+		}
+	case "churn", "churn_threshold":
+		if v, err := strconv.Atoi(value); err == nil && v >= 0 {
+			cfg.ChurnThreshold = v
+			msg = fmt.Sprintf("进程启停频率阈值: %d 个/分", v)
+			updated = true
+		}
+	case "steal", "steal_threshold":
+		if v, err := strconv.ParseFloat(value, 64); err == nil && v >= 0 && v <= 100 {
+			cfg.StealThreshold = v
+			msg = fmt.Sprintf("宿主机抢占阈值: %.0f%%", v)
+			updated = true
+		}
+	case "run_wait", "virt_run_wait_threshold":
+		if v, err := strconv.ParseFloat(value, 64); err == nil && v >= 0 {
+			cfg.VirtRunWaitThreshold = v
+			msg = fmt.Sprintf("虚拟化争用运行等待阈值: %.0f%%", v)
+			updated = true
+		}
 
 	// 进程级阈值
 	case "proc_cpu":
@@ -335,6 +645,12 @@ func (cmd *ImpactCommand) setConfig(args []string) {
 			msg = fmt.Sprintf("进程句柄数阈值: %d", v)
 			updated = true
 		}
+	case "proc_fd_growth":
+		if v, err := strconv.ParseFloat(value, 64); err == nil {
+			cfg.ProcFDGrowthThreshold = v
+			msg = fmt.Sprintf("进程句柄数增速阈值: %.0f 个/分", v)
+			updated = true
+		}
 	case "proc_threads":
 		if v, err := strconv.Atoi(value); err == nil {
 			cfg.ProcThreadsThreshold = v
@@ -365,6 +681,50 @@ func (cmd *ImpactCommand) setConfig(args []string) {
 			msg = fmt.Sprintf("进程网络发阈值: %.0f MB/s", v)
 			updated = true
 		}
+	case "zombies", "zombie_threshold":
+		if v, err := strconv.Atoi(value); err == nil && v >= 0 {
+			cfg.ZombieThreshold = v
+			msg = fmt.Sprintf("僵尸子进程数阈值: %d 个", v)
+			updated = true
+		}
+	case "dir_growth", "dir_growth_threshold":
+		if v, err := strconv.ParseFloat(value, 64); err == nil && v >= 0 {
+			cfg.DirGrowthThreshold = v
+			msg = fmt.Sprintf("目录增长速度阈值: %.0f MB/小时", v)
+			updated = true
+		}
+	case "dir_min_free", "dir_min_free_mb":
+		if v, err := strconv.ParseFloat(value, 64); err == nil && v >= 0 {
+			cfg.DirMinFreeMB = v
+			msg = fmt.Sprintf("目录所在磁盘剩余空间下限: %.0f MB", v)
+			updated = true
+		}
+
+	// 健康评分权重
+	case "weight_critical":
+		if v, err := strconv.ParseFloat(value, 64); err == nil && v > 0 {
+			cfg.ScoreWeightCritical = v
+			msg = fmt.Sprintf("严重事件扣分: %.0f", v)
+			updated = true
+		}
+	case "weight_high":
+		if v, err := strconv.ParseFloat(value, 64); err == nil && v > 0 {
+			cfg.ScoreWeightHigh = v
+			msg = fmt.Sprintf("高级事件扣分: %.0f", v)
+			updated = true
+		}
+	case "weight_medium":
+		if v, err := strconv.ParseFloat(value, 64); err == nil && v > 0 {
+			cfg.ScoreWeightMedium = v
+			msg = fmt.Sprintf("中级事件扣分: %.0f", v)
+			updated = true
+		}
+	case "weight_low":
+		if v, err := strconv.ParseFloat(value, 64); err == nil && v > 0 {
+			cfg.ScoreWeightLow = v
+			msg = fmt.Sprintf("低级事件扣分: %.0f", v)
+			updated = true
+		}
 
 	// 其他配置
 	case "enabled":
@@ -377,16 +737,104 @@ func (cmd *ImpactCommand) setConfig(args []string) {
 			}
 			updated = true
 		}
+	case "ignore_loopback", "ignore_loopback_ports":
+		if v, err := strconv.ParseBool(value); err == nil {
+			cfg.IgnoreLoopbackPorts = v
+			msg = fmt.Sprintf("忽略回环端口: %v", v)
+			updated = true
+		}
+	case "exclude_self":
+		if v, err := strconv.ParseBool(value); err == nil {
+			cfg.ExcludeSelf = v
+			msg = fmt.Sprintf("排除本程序及其子进程: %v", v)
+			updated = true
+		}
+	case "exclude_mapped_vms":
+		if v, err := strconv.ParseBool(value); err == nil {
+			cfg.ExcludeMappedVMS = v
+			msg = fmt.Sprintf("虚拟内存检测不计文件映射: %v", v)
+			updated = true
+		}
 	case "interval", "analysis_interval":
 		if v, err := strconv.Atoi(value); err == nil && v > 0 {
 			cfg.AnalysisInterval = v
 			msg = fmt.Sprintf("分析间隔: %d秒", v)
 			updated = true
 		}
+	case "workers", "analysis_workers":
+		if v, err := strconv.Atoi(value); err == nil && v > 0 && v <= impact.MaxAnalysisWorkers {
+			cfg.AnalysisWorkers = v
+			msg = fmt.Sprintf("并发分析协程: %d (1=顺序执行)", v)
+			updated = true
+		}
+	case "skip_warn", "skip_warn_cycles":
+		if v, err := strconv.Atoi(value); err == nil && v > 0 {
+			cfg.SkipWarnCycles = v
+			msg = fmt.Sprintf("连续跳过 %d 个周期后告警", v)
+			updated = true
+		}
+	case "min_duration", "min_duration_seconds":
+		if v, err := strconv.Atoi(value); err == nil && v >= 0 {
+			cfg.MinDurationSeconds = v
+			msg = fmt.Sprintf("持续时间要求: %d秒", v)
+			updated = true
+		}
+	case "clear_duration", "clear_duration_seconds":
+		if v, err := strconv.Atoi(value); err == nil && v >= 0 {
+			cfg.ClearDurationSeconds = v
+			msg = fmt.Sprintf("解除前清除期: %d秒", v)
+			updated = true
+		}
+	case "log_cooldown", "log_cooldown_seconds":
+		if v, err := strconv.Atoi(value); err == nil && v >= 0 {
+			cfg.LogCooldownSeconds = v
+			msg = fmt.Sprintf("日志冷却: %d秒", v)
+			updated = true
+		}
+
+	// 趋势预测
+	case "trend_window", "trend_window_seconds":
+		if v, err := strconv.Atoi(value); err == nil && v >= 0 {
+			cfg.TrendWindowSeconds = v
+			msg = fmt.Sprintf("趋势窗口: %d秒", v)
+			updated = true
+		}
+	case "trend_horizon", "trend_horizon_minutes":
+		if v, err := strconv.Atoi(value); err == nil && v > 0 {
+			cfg.TrendHorizonMinutes = v
+			msg = fmt.Sprintf("趋势预警时长: %d分钟", v)
+			updated = true
+		}
+	case "trend_limit", "trend_limit_percent":
+		if v, err := strconv.ParseFloat(value, 64); err == nil && v > 0 && v <= 100 {
+			cfg.TrendLimitPercent = v
+			msg = fmt.Sprintf("趋势耗尽线: %.0f%%", v)
+			updated = true
+		}
 
 	default:
-		fmt.Println(cmd.cli.formatter.Error(fmt.Sprintf("未知配置项: %s", key)))
-		return
+		impactType := strings.TrimPrefix(key, "min_duration.")
+		if impactType == key {
+			fmt.Println(cmd.cli.formatter.Error(fmt.Sprintf("未知配置项: %s", key)))
+			return
+		}
+		if !impact.IsImpactType(impactType) {
+			fmt.Println(cmd.cli.formatter.Error(fmt.Sprintf("未知影响类型: %s (可选: %s)",
+				impactType, strings.Join(impact.ImpactTypes, ", "))))
+			return
+		}
+		if value == "-" {
+			delete(cfg.MinDurationOverrides, impactType)
+			msg = fmt.Sprintf("%s 持续时间: 使用全局设置", impactType)
+			updated = true
+		} else if v, err := strconv.Atoi(value); err == nil && v >= 0 {
+			if cfg.MinDurationOverrides == nil {
+				cfg.MinDurationOverrides = make(map[string]int)
+			}
+			cfg.MinDurationOverrides[impactType] = v
+			msg = fmt.Sprintf("%s 持续时间: %d秒", impactType, v)
+			updated = true
+		}
 	}
 
 	if !updated {
@@ -401,11 +849,13 @@ func (cmd *ImpactCommand) setConfig(args []string) {
 	}
 
 	// 保存到配置文件
+	var saveErr error
 	if cmd.cli.configFile != "" {
-		if err := config.SaveConfig(cmd.cli.configFile, cmd.cli.config); err != nil {
-			fmt.Println(cmd.cli.formatter.Warning(fmt.Sprintf("保存配置失败: %v", err)))
+		if saveErr = config.SaveConfig(cmd.cli.configFile, cmd.cli.config); saveErr != nil {
+			fmt.Println(cmd.cli.formatter.Warning(fmt.Sprintf("保存配置失败: %v", saveErr)))
 		}
 	}
+	cmd.cli.audit("impact.set", map[string]string{"key": key, "value": value}, saveErr)
 
 	fmt.Println(cmd.cli.formatter.Success(msg + " (已保存)"))
 }
@@ -416,9 +866,68 @@ func (cmd *ImpactCommand) clearImpacts() {
 		input := strings.ToLower(strings.TrimSpace(cmd.cli.scanner.Text()))
 		if input == "y" || input == "yes" {
 			cmd.cli.monitor.ClearImpactEvents()
+			cmd.cli.audit("impact.clear", nil, nil)
 			fmt.Println(cmd.cli.formatter.Success("所有影响事件已清除"))
 		} else {
 			fmt.Println(cmd.cli.formatter.Info("操作已取消"))
 		}
 	}
 }
+
+// pause 暂停风险告警：即全局维护模式，期间影响事件标记为已抑制，指标和分析照常进行
+// 用法: impact pause <时长> [原因] / impact pause end
+func (cmd *ImpactCommand) pause(args []string) {
+	if len(args) < 1 {
+		cmd.cli.printError("用法: impact pause <时长> [原因] | impact pause end")
+		return
+	}
+
+	if strings.ToLower(args[0]) == "end" {
+		err := cmd.cli.monitor.EndMaintenance(0)
+		cmd.cli.audit("impact.resume", nil, err)
+		if err != nil {
+			cmd.cli.printError("风险告警未暂停")
+			return
+		}
+		fmt.Println(cmd.cli.formatter.Success("已恢复风险告警"))
+		return
+	}
+
+	duration, err := time.ParseDuration(args[0])
+	if err != nil || duration <= 0 {
+		cmd.cli.printError("无效的时长，示例: 30m、2h")
+		return
+	}
+	reason := strings.Trim(strings.Join(args[1:], " "), "\"'")
+	w, err := cmd.cli.monitor.StartMaintenance(0, duration, reason)
+	cmd.cli.audit("impact.pause", map[string]interface{}{
+		"duration": args[0],
+		"reason":   reason,
+	}, err)
+	if err != nil {
+		cmd.cli.printError(fmt.Sprintf("暂停失败: %v", err))
+		return
+	}
+	fmt.Println(cmd.cli.formatter.Success(fmt.Sprintf("风险告警已暂停，至 %s 自动恢复", timefmt.Format(w.End, "2006-01-02 15:04:05"))))
+}
+
+// formatTrend 趋势预测配置的显示文本
+// formatMappedVMS 虚拟内存检测是否不计文件映射
+func formatMappedVMS(exclude bool) string {
+	if exclude {
+		return "，不计文件映射"
+	}
+	return ""
+}
+
+func formatTrend(cfg types.ImpactConfig) string {
+	if cfg.TrendWindowSeconds <= 0 {
+		return "禁用"
+	}
+	disks := "系统盘"
+	if len(cfg.TrendDiskPaths) > 0 {
+		disks = strings.Join(cfg.TrendDiskPaths, ", ")
+	}
+	return fmt.Sprintf("窗口 %d秒，%d分钟内达到 %.0f%% 时告警 (磁盘: %s)",
+		cfg.TrendWindowSeconds, cfg.TrendHorizonMinutes, cfg.TrendLimitPercent, disks)
+}
@@ -11,9 +11,49 @@ import (
 	"strings"
 	"time"
 
+	"monitor-agent/buildinfo"
+	"monitor-agent/config"
+	"monitor-agent/format"
 	"monitor-agent/logger"
+	"monitor-agent/logquery"
+	"monitor-agent/timefmt"
 )
 
+// reportShift 报告所在的值次
+type reportShift struct {
+	start time.Time // 本值次开始时间
+	label string    // 如 "白班 (08:00 - 20:00)"
+}
+
+// currentShift 确定 now 所在的值次，未配置值次时使用默认两班制
+// 早于当天第一个值次开始时间时，属于前一天的最后一个值次（如夜班跨零点）
+func currentShift(shifts []config.ShiftConfig, now time.Time) reportShift {
+	if len(shifts) == 0 {
+		shifts = config.DefaultShifts()
+	}
+	sorted := append([]config.ShiftConfig(nil), shifts...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].StartHour < sorted[j].StartHour
+	})
+
+	idx := len(sorted) - 1
+	for i, s := range sorted {
+		if s.StartHour <= now.Hour() {
+			idx = i
+		}
+	}
+	cur, next := sorted[idx], sorted[(idx+1)%len(sorted)]
+
+	day := now
+	if cur.StartHour > now.Hour() {
+		day = now.AddDate(0, 0, -1)
+	}
+	return reportShift{
+		start: time.Date(day.Year(), day.Month(), day.Day(), cur.StartHour, 0, 0, 0, day.Location()),
+		label: fmt.Sprintf("%s (%02d:00 - %02d:00)", cur.Name, cur.StartHour, next.StartHour),
+	}
+}
+
 // LogCommand 日志管理命令组
 type LogCommand struct {
 	cli *CLI
@@ -31,6 +71,8 @@ func (cmd *LogCommand) Handle(subCmd string, args []string) {
 		cmd.tailLogs(args)
 	case "filter", "f":
 		cmd.filterLogs(args)
+	case "search", "s":
+		cmd.searchLogs(args)
 	case "export", "exp":
 		cmd.exportLogs(args)
 	case "report", "rpt":
@@ -38,14 +80,16 @@ func (cmd *LogCommand) Handle(subCmd string, args []string) {
 	case "console", "con":
 		cmd.toggleConsole(args)
 	case "clear":
-		cmd.clearLogs()
+		cmd.clearLogs(args)
 	case "files":
 		cmd.listLogFiles()
 	case "help", "h":
 		cmd.PrintHelp()
 	default:
-		fmt.Println(cmd.cli.formatter.Error(fmt.Sprintf("未知子命令: %s", subCmd)))
-		cmd.PrintHelp()
+		cmd.cli.printError(fmt.Sprintf("未知子命令: %s", subCmd))
+		if !cmd.cli.jsonMode() {
+			cmd.PrintHelp()
+		}
 	}
 }
 
@@ -56,18 +100,24 @@ func (cmd *LogCommand) PrintHelp() {
 	fmt.Println("  console [on|off]      - 启停终端日志输出")
 	fmt.Println("  tail [n]              - 查看最近N条日志 (默认50)")
 	fmt.Println("  filter <type>         - 按类型过滤 (METRIC/EVENT/IMPACT)")
+	fmt.Println("  search <text> [--category TYPE] [--level LEVEL] [--pid PID] [--limit N]")
+	fmt.Println("                        - 检索日志目录中的全部历史日志 (从新到旧)")
 	fmt.Println("  export <file>         - 导出日志到文件")
-	fmt.Println("  report <file>         - 生成值班运行报告")
+	fmt.Println("  report <file> [--date YYYY-MM-DD]")
+	fmt.Println("                        - 生成值班运行报告 (指定日期时统计该日全天)")
 	fmt.Println("  files                 - 列出所有日志文件")
-	fmt.Println("  clear                 - 清理旧日志文件")
+	fmt.Println("  clear [days]          - 清理N天前的日志文件 (默认按配置保留天数)")
 	fmt.Println()
 	fmt.Println(cmd.cli.formatter.Info("示例:"))
 	fmt.Println("  log console off       - 关闭终端日志输出")
 	fmt.Println("  log console on        - 开启终端日志输出")
 	fmt.Println("  log tail 100          - 查看最近100条日志")
 	fmt.Println("  log filter IMPACT     - 仅显示影响分析日志")
+	fmt.Println("  log search 端口 --category IMPACT - 检索含\"端口\"的影响分析日志")
 	fmt.Println("  log export report.txt - 导出日志到文件")
 	fmt.Println("  log report 日报.txt   - 生成电厂值班运行报告")
+	fmt.Println("  log report 0512.txt --date 2024-05-12 - 生成指定日期的运行报告")
+	fmt.Println("  log clear 30          - 清理30天前的日志文件")
 }
 
 // LogEntry 日志条目结构
@@ -116,6 +166,13 @@ func (cmd *LogCommand) tailLogs(args []string) {
 	}
 
 	logs := cmd.readRecentLogs(count)
+	if cmd.cli.jsonMode() {
+		if logs == nil {
+			logs = []LogEntry{}
+		}
+		cmd.cli.printJSON(logs)
+		return
+	}
 	if len(logs) == 0 {
 		fmt.Println(cmd.cli.formatter.Info("暂无日志"))
 		return
@@ -172,6 +229,83 @@ func (cmd *LogCommand) filterLogs(args []string) {
 	}
 }
 
+// searchLogs 检索日志目录中的历史日志（与 /api/logs/search 共用 logquery）
+func (cmd *LogCommand) searchLogs(args []string) {
+	var query logquery.Query
+	var words []string
+	for i := 0; i < len(args); i++ {
+		flag := args[i]
+		switch flag {
+		case "--category", "--level", "--pid", "--limit":
+			if i+1 >= len(args) {
+				cmd.cli.printError(fmt.Sprintf("%s 需要参数值", flag))
+				return
+			}
+			i++
+			value := args[i]
+			switch flag {
+			case "--category":
+				query.Category = value
+			case "--level":
+				query.Level = value
+			case "--pid":
+				pid, err := strconv.ParseInt(value, 10, 32)
+				if err != nil || pid <= 0 {
+					cmd.cli.printError(fmt.Sprintf("无效的 PID: %s", value))
+					return
+				}
+				query.PID = int32(pid)
+			case "--limit":
+				n, err := strconv.Atoi(value)
+				if err != nil || n <= 0 {
+					cmd.cli.printError(fmt.Sprintf("无效的条数: %s", value))
+					return
+				}
+				query.Limit = n
+			}
+		default:
+			words = append(words, flag)
+		}
+	}
+	query.Text = strings.Join(words, " ")
+	if query.Text == "" && query.Category == "" && query.Level == "" && query.PID == 0 {
+		cmd.cli.printError("用法: log search <text> [--category TYPE] [--level LEVEL] [--pid PID] [--limit N]")
+		return
+	}
+
+	result, err := logquery.Collect(cmd.logDir(), query)
+	if err != nil {
+		cmd.cli.printError(fmt.Sprintf("检索日志失败: %v", err))
+		return
+	}
+	if cmd.cli.jsonMode() {
+		cmd.cli.printJSON(result)
+		return
+	}
+	if len(result.Entries) == 0 {
+		fmt.Println(cmd.cli.formatter.Info("未找到匹配的日志"))
+	} else {
+		fmt.Println(cmd.cli.formatter.Header(fmt.Sprintf("\n=== 检索结果 (共 %d 条，从新到旧) ===", len(result.Entries))))
+		fmt.Println()
+		for _, e := range result.Entries {
+			fmt.Printf("[%s] %s %s %s\n",
+				timefmt.Format(e.Timestamp, "2006-01-02 15:04:05"),
+				cmd.formatLevel(e.Level),
+				cmd.formatCategory(e.Category),
+				e.Message)
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf(cmd.cli.formatter.Info("扫描 %d 个文件，%s\n"), result.FilesScanned,
+		format.Bytes(uint64(result.BytesScanned)))
+	if result.Truncated {
+		fmt.Println(cmd.cli.formatter.Warning("已达到单次读取上限，更早的日志未检索，请缩小时间范围或增加过滤条件"))
+	} else if result.LimitReached {
+		fmt.Println(cmd.cli.formatter.Info("已达到条数上限，可用 --limit 显示更多"))
+	}
+}
+
 func (cmd *LogCommand) exportLogs(args []string) {
 	if len(args) == 0 {
 		fmt.Println(cmd.cli.formatter.Error("用法: log export <file>"))
@@ -204,14 +338,14 @@ func (cmd *LogCommand) exportLogs(args []string) {
 
 	// 写入表头
 	writer.WriteString("电厂监控系统日志导出\n")
-	writer.WriteString(fmt.Sprintf("导出时间: %s\n", time.Now().Format("2006-01-02 15:04:05")))
+	writer.WriteString(fmt.Sprintf("导出时间: %s\n", timefmt.Format(time.Now(), "2006-01-02 15:04:05")))
 	writer.WriteString(fmt.Sprintf("日志条数: %d\n", len(logs)))
 	writer.WriteString(strings.Repeat("=", 80) + "\n\n")
 
 	// 写入日志
 	for _, log := range logs {
 		line := fmt.Sprintf("[%s] [%s] [%s] %s\n",
-			log.Timestamp.Format("2006-01-02 15:04:05"),
+			timefmt.Format(log.Timestamp, "2006-01-02 15:04:05"),
 			log.Level,
 			log.Category,
 			log.Message)
@@ -249,7 +383,7 @@ func (cmd *LogCommand) listLogFiles() {
 	var totalSize int64
 
 	for _, file := range files {
-		if file.IsDir() || !strings.HasSuffix(file.Name(), ".jsonl") {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".jsonl") || file.Name() == logger.AuditFileName {
 			continue
 		}
 
@@ -277,18 +411,32 @@ func (cmd *LogCommand) listLogFiles() {
 	for _, f := range logFiles {
 		fmt.Printf("%-40s %12s %20s\n",
 			f.name,
-			cmd.cli.formatter.FormatBytes(uint64(f.size)),
-			f.modTime.Format("01-02 15:04:05"))
+			format.Bytes(uint64(f.size)),
+			timefmt.In(f.modTime).Format("01-02 15:04:05"))
 	}
 
 	fmt.Println()
 	fmt.Printf(cmd.cli.formatter.Info("共 %d 个文件，总大小: %s\n"),
 		len(logFiles),
-		cmd.cli.formatter.FormatBytes(uint64(totalSize)))
+		format.Bytes(uint64(totalSize)))
 }
 
-func (cmd *LogCommand) clearLogs() {
-	fmt.Print("确认清理7天前的日志文件? (y/n): ")
+// clearLogs 清理 N 天前的日志文件（默认使用配置的保留天数，未配置时为 7 天），当前日志文件不会删除
+func (cmd *LogCommand) clearLogs(args []string) {
+	days := cmd.cli.config.Logging.MaxAgeDays
+	if days <= 0 {
+		days = 7
+	}
+	if len(args) > 0 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n <= 0 {
+			fmt.Println(cmd.cli.formatter.Error("天数必须是正整数"))
+			return
+		}
+		days = n
+	}
+
+	fmt.Printf("确认清理%d天前的日志文件? (y/n): ", days)
 	if cmd.cli.scanner.Scan() {
 		input := strings.ToLower(strings.TrimSpace(cmd.cli.scanner.Text()))
 		if input != "y" && input != "yes" {
@@ -297,43 +445,33 @@ func (cmd *LogCommand) clearLogs() {
 		}
 	}
 
-	logDir := "logs"
-	files, err := os.ReadDir(logDir)
+	policy := logger.RetentionPolicy{MaxAge: time.Duration(days) * 24 * time.Hour}
+	result, err := logger.CleanupLogs(cmd.logDir(), policy, time.Now())
+	cmd.cli.audit("log.clear", map[string]interface{}{"days": days, "removed": result.Removed}, err)
 	if err != nil {
 		fmt.Println(cmd.cli.formatter.Error(fmt.Sprintf("读取日志目录失败: %v", err)))
 		return
 	}
 
-	cutoffTime := time.Now().AddDate(0, 0, -7)
-	removed := 0
-	var freedSize int64
-
-	for _, file := range files {
-		if file.IsDir() || !strings.HasSuffix(file.Name(), ".jsonl") {
-			continue
-		}
-
-		info, err := file.Info()
-		if err != nil {
-			continue
-		}
-
-		if info.ModTime().Before(cutoffTime) {
-			filePath := filepath.Join(logDir, file.Name())
-			if err := os.Remove(filePath); err == nil {
-				removed++
-				freedSize += info.Size()
-			}
-		}
-	}
-
-	if removed > 0 {
+	if len(result.Removed) > 0 {
 		fmt.Println(cmd.cli.formatter.Success(fmt.Sprintf("已清理 %d 个日志文件，释放 %s",
-			removed,
-			cmd.cli.formatter.FormatBytes(uint64(freedSize)))))
+			len(result.Removed),
+			format.Bytes(uint64(result.Freed)))))
 	} else {
 		fmt.Println(cmd.cli.formatter.Info("没有需要清理的日志文件"))
 	}
+	if len(result.Failed) > 0 {
+		fmt.Println(cmd.cli.formatter.Warning(fmt.Sprintf("%d 个文件正在使用，未能删除: %s",
+			len(result.Failed), strings.Join(result.Failed, ", "))))
+	}
+}
+
+// logDir 当前日志目录
+func (cmd *LogCommand) logDir() string {
+	if l := logger.Default(); l != nil {
+		return l.GetLogDir()
+	}
+	return "logs"
 }
 
 func (cmd *LogCommand) readRecentLogs(count int) []LogEntry {
@@ -348,7 +486,7 @@ func (cmd *LogCommand) readRecentLogs(count int) []LogEntry {
 	var latestTime time.Time
 
 	for _, file := range files {
-		if file.IsDir() || !strings.HasSuffix(file.Name(), ".jsonl") {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".jsonl") || file.Name() == logger.AuditFileName {
 			continue
 		}
 
@@ -380,7 +518,7 @@ func (cmd *LogCommand) readRecentLogs(count int) []LogEntry {
 
 	for scanner.Scan() {
 		var entry LogEntry
-		if err := json.Unmarshal(scanner.Bytes(), &entry); err == nil {
+		if err := logger.UnmarshalLine(scanner.Bytes(), &entry); err == nil {
 			logs = append(logs, entry)
 		}
 	}
@@ -393,8 +531,50 @@ func (cmd *LogCommand) readRecentLogs(count int) []LogEntry {
 	return logs
 }
 
+// readLogsBetween 读取时间范围内的日志（跨多个日志文件，按时间排序）
+// 修改时间早于 from 的文件不会包含范围内的记录，直接跳过
+func (cmd *LogCommand) readLogsBetween(from, to time.Time) []LogEntry {
+	logDir := "logs"
+	files, err := os.ReadDir(logDir)
+	if err != nil {
+		return nil
+	}
+
+	var logs []LogEntry
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".jsonl") || file.Name() == logger.AuditFileName {
+			continue
+		}
+		info, err := file.Info()
+		if err != nil || info.ModTime().Before(from) {
+			continue
+		}
+
+		f, err := os.Open(filepath.Join(logDir, file.Name()))
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			var entry LogEntry
+			if err := logger.UnmarshalLine(scanner.Bytes(), &entry); err != nil {
+				continue
+			}
+			if !entry.Timestamp.Before(from) && !entry.Timestamp.After(to) {
+				logs = append(logs, entry)
+			}
+		}
+		f.Close()
+	}
+
+	sort.SliceStable(logs, func(i, j int) bool {
+		return logs[i].Timestamp.Before(logs[j].Timestamp)
+	})
+	return logs
+}
+
 func (cmd *LogCommand) printLogEntry(log LogEntry) {
-	timeStr := log.Timestamp.Format("15:04:05")
+	timeStr := timefmt.In(log.Timestamp).Format("15:04:05")
 	levelStr := cmd.formatLevel(log.Level)
 	categoryStr := cmd.formatCategory(log.Category)
 
@@ -456,17 +636,51 @@ func (cmd *LogCommand) formatCategory(cat string) string {
 
 // generateReport 生成电厂风格的值班运行报告
 func (cmd *LogCommand) generateReport(args []string) {
-	if len(args) == 0 {
-		fmt.Println(cmd.cli.formatter.Error("用法: log report <file>"))
+	var outputFile, date string
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--date" {
+			if i+1 >= len(args) {
+				cmd.cli.printError("--date 需要参数值")
+				return
+			}
+			i++
+			date = args[i]
+			continue
+		}
+		outputFile = args[i]
+	}
+	if outputFile == "" {
+		fmt.Println(cmd.cli.formatter.Error("用法: log report <file> [--date YYYY-MM-DD]"))
 		fmt.Println(cmd.cli.formatter.Info("示例: log report 日报.txt"))
 		return
 	}
 
-	outputFile := args[0]
-	now := time.Now()
+	now := timefmt.Now() // 值次和报告日期按配置的时区判断
+	reportDate := now.Format("2006-01-02")
 
-	// 读取所有日志（最近24小时的）
-	allLogs := cmd.readRecentLogs(10000)
+	// 默认只统计本值次开始以来的日志；指定日期时统计该日全天，日志按日索引读取
+	shift := currentShift(cmd.cli.config.Report.Shifts, now)
+	periodStart, periodEnd := shift.start, now
+	var allLogs []LogEntry
+	historical := date != ""
+	if historical {
+		day, err := logquery.Day(cmd.logDir(), logquery.DayQuery{Date: date})
+		if err != nil {
+			cmd.cli.printError(fmt.Sprintf("读取 %s 的日志失败: %v", date, err))
+			return
+		}
+		reportDate = day.Date
+		periodStart, _ = time.ParseInLocation("2006-01-02", day.Date, timefmt.Location())
+		periodEnd = periodStart.AddDate(0, 0, 1)
+		shift.label = "全天"
+		for _, e := range day.Entries {
+			entry := LogEntry{Timestamp: e.Timestamp, Level: e.Level, Category: e.Category, Message: e.Message}
+			entry.Data, _ = e.Data.(map[string]interface{})
+			allLogs = append(allLogs, entry)
+		}
+	} else {
+		allLogs = cmd.readLogsBetween(periodStart, periodEnd)
+	}
 
 	// 分类统计
 	var eventLogs, impactLogs []LogEntry
@@ -500,15 +714,6 @@ func (cmd *LogCommand) generateReport(args []string) {
 	// 获取当前监控目标
 	targets := cmd.cli.monitor.GetTargets()
 
-	// 确定值次
-	hour := now.Hour()
-	var shift string
-	if hour >= 8 && hour < 20 {
-		shift = "白班 (08:00 - 20:00)"
-	} else {
-		shift = "夜班 (20:00 - 08:00)"
-	}
-
 	// 生成报告
 	file, err := os.Create(outputFile)
 	if err != nil {
@@ -525,24 +730,30 @@ func (cmd *LogCommand) generateReport(args []string) {
 	w.WriteString("              电厂核心软件运行日报\n")
 	w.WriteString("═══════════════════════════════════════════════════════════════\n")
 	w.WriteString(fmt.Sprintf("单位名称：XX发电厂\n"))
-	w.WriteString(fmt.Sprintf("报告日期：%s\n", now.Format("2006-01-02")))
-	w.WriteString(fmt.Sprintf("值    次：%s\n", shift))
-	w.WriteString(fmt.Sprintf("生成时间：%s\n", now.Format("2006-01-02 15:04:05")))
+	w.WriteString(fmt.Sprintf("报告日期：%s\n", reportDate))
+	w.WriteString(fmt.Sprintf("值    次：%s\n", shift.label))
+	w.WriteString(fmt.Sprintf("统计区间：%s 至 %s\n",
+		timefmt.Format(periodStart, "2006-01-02 15:04"), timefmt.Format(periodEnd, "2006-01-02 15:04")))
+	w.WriteString(fmt.Sprintf("生成时间：%s\n", timefmt.Format(now, "2006-01-02 15:04:05")))
+	w.WriteString(fmt.Sprintf("程序版本：%s\n", buildinfo.Brief()))
 	w.WriteString("───────────────────────────────────────────────────────────────\n\n")
 
 	// 一、保障软件运行情况
 	w.WriteString("一、保障软件运行情况\n")
-	if len(targets) == 0 {
+	if historical {
+		w.WriteString("  （历史日报只统计日志中的事件，不含软件运行数据）\n")
+	} else if len(targets) == 0 {
 		w.WriteString("  暂无保障对象\n")
 	} else {
-		w.WriteString(fmt.Sprintf("  %-6s %-20s %-8s %-10s %-10s %-10s\n",
-			"序号", "软件名称", "状态", "CPU均值", "内存均值", "运行时长"))
+		w.WriteString(fmt.Sprintf("  %-6s %-20s %-8s %-10s %-10s %-10s %-10s\n",
+			"序号", "软件名称", "状态", "CPU均值", "内存均值", "运行时长", "可用率"))
 		for i, t := range targets {
 			// 获取软件状态
 			status := "正常"
 			cpuAvg := "-"
 			memAvg := "-"
 			runtime := "-"
+			availability := "未知"
 
 			if metrics := cmd.cli.monitor.GetMetrics(t.PID, 100); len(metrics) > 0 {
 				// 计算平均值
@@ -551,8 +762,13 @@ func (cmd *LogCommand) generateReport(args []string) {
 					cpuSum += m.CPUPct
 					memSum += float64(m.RSSBytes)
 				}
-				cpuAvg = fmt.Sprintf("%.1f%%", cpuSum/float64(len(metrics)))
-				memAvg = cmd.cli.formatter.FormatBytes(uint64(memSum / float64(len(metrics))))
+				cpuAvg = format.Percent(cpuSum / float64(len(metrics)))
+				memAvg = format.Bytes(uint64(memSum / float64(len(metrics))))
+			}
+
+			// 本值次开始以来的可用率，代理未运行的时间不计入
+			if report, ok := cmd.cli.monitor.GetAvailability(t.PID, now.Sub(shift.start)); ok && report.UptimePct != nil {
+				availability = fmt.Sprintf("%.2f%%", *report.UptimePct)
 			}
 
 			displayName := t.Alias
@@ -563,8 +779,8 @@ func (cmd *LogCommand) generateReport(args []string) {
 				displayName = displayName[:18] + ".."
 			}
 
-			w.WriteString(fmt.Sprintf("  %-6d %-20s %-8s %-10s %-10s %-10s\n",
-				i+1, displayName, status, cpuAvg, memAvg, runtime))
+			w.WriteString(fmt.Sprintf("  %-6d %-20s %-8s %-10s %-10s %-10s %-10s\n",
+				i+1, displayName, status, cpuAvg, memAvg, runtime, availability))
 		}
 	}
 	w.WriteString("\n")
@@ -610,7 +826,7 @@ func (cmd *LogCommand) generateReport(args []string) {
 				}
 			}
 			w.WriteString(fmt.Sprintf("  [%s] [%s] %s\n",
-				log.Timestamp.Format("15:04:05"),
+				timefmt.In(log.Timestamp).Format("15:04:05"),
 				sev,
 				log.Message))
 			count++
@@ -4,10 +4,14 @@ import (
 	"fmt"
 	"os"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"monitor-agent/format"
+	"monitor-agent/impact"
+	"monitor-agent/timefmt"
 	"monitor-agent/types"
 
 	"github.com/shirou/gopsutil/v3/disk"
@@ -16,6 +20,9 @@ import (
 	"github.com/shirou/gopsutil/v3/process"
 )
 
+// naValue 读取失败（降级）的指标的显示值
+const naValue = "N/A"
+
 // SystemCommand 系统信息命令组
 type SystemCommand struct {
 	cli *CLI
@@ -39,11 +46,15 @@ func (cmd *SystemCommand) Handle(subCmd string, args []string) {
 		cmd.showEvents(args)
 	case "watch":
 		cmd.watchProcess(args)
+	case "whohas":
+		cmd.whoHas(args)
 	case "help", "h":
 		cmd.PrintHelp()
 	default:
-		fmt.Println(cmd.cli.formatter.Error(fmt.Sprintf("未知子命令: %s", subCmd)))
-		cmd.PrintHelp()
+		cmd.cli.printError(fmt.Sprintf("未知子命令: %s", subCmd))
+		if !cmd.cli.jsonMode() {
+			cmd.PrintHelp()
+		}
 	}
 }
 
@@ -53,15 +64,20 @@ func (cmd *SystemCommand) PrintHelp() {
 	fmt.Println()
 	fmt.Println("  status [-1]           - 显示系统状态 (默认动态刷新, -1 只显示一次)")
 	fmt.Println("  top [n] [-1]          - 显示Top N进程 (默认动态刷新, -1 只显示一次)")
+	fmt.Println("      [--warn N] [--crit N] [--mem MB] - 本次高亮阈值 (默认取 display 配置)")
 	fmt.Println("  ps [pattern]          - 列出进程 (可按名称过滤)")
 	fmt.Println("  events [n]            - 显示最近事件 (默认20)")
 	fmt.Println("  watch <pid>           - 实时监控指定进程")
+	fmt.Println("  whohas port <n>       - 列出占用端口的所有进程 (监听和连接)")
+	fmt.Println("  whohas file <路径>    - 列出打开该文件的所有进程")
 	fmt.Println()
 	fmt.Println(cmd.cli.formatter.Info("示例:"))
 	fmt.Println("  system top 20         - 动态刷新显示Top 20进程")
 	fmt.Println("  system top 10 -1      - 只显示一次Top 10进程")
+	fmt.Println("  system top --warn 40 --crit 70 - CPU超过40%黄色、超过70%红色")
 	fmt.Println("  system ps java        - 列出名称包含java的进程")
 	fmt.Println("  system watch 1234     - 实时监控PID为1234的进程")
+	fmt.Println("  system whohas port 3306 - 查看谁占用了 3306 端口")
 }
 
 func (cmd *SystemCommand) showStatus(args []string) {
@@ -105,7 +121,7 @@ func (cmd *SystemCommand) showStatus(args []string) {
 
 func (cmd *SystemCommand) renderStatusWatch() {
 	fmt.Print("\033[H\033[J")
-	now := time.Now().Format("15:04:05")
+	now := timefmt.Now().Format("15:04:05")
 	fmt.Printf("=== 系统状态 === [%s] 按 Enter 退出\n\n", now)
 	cmd.renderStatusContent()
 }
@@ -130,19 +146,34 @@ func (cmd *SystemCommand) renderStatusContent() {
 		fmt.Printf("  主机名:     %s\n", info.Hostname)
 		fmt.Printf("  操作系统:   %s %s\n", info.Platform, info.PlatformVersion)
 		fmt.Printf("  内核版本:   %s\n", info.KernelVersion)
-		uptime := time.Duration(info.Uptime) * time.Second
-		fmt.Printf("  运行时间:   %s\n", cmd.formatUptime(uptime))
+		fmt.Printf("  运行时间:   %s\n", format.Uptime(int64(info.Uptime)))
+		fmt.Println()
+	}
+
+	// 部分子系统读取失败时对应指标显示为 N/A，而不是看起来像真实读数的 0
+	if len(sysMetrics.Degraded) > 0 {
+		fmt.Println(cmd.cli.formatter.Warning(fmt.Sprintf("部分指标读取失败（显示为 %s）: %s",
+			naValue, strings.Join(sysMetrics.Degraded, ", "))))
 		fmt.Println()
 	}
 
 	// CPU信息
 	fmt.Println(cmd.cli.formatter.Bold("CPU:"))
 	fmt.Printf("  逻辑核心:   %d\n", runtime.NumCPU())
-	bar := cmd.cli.formatter.ProgressBar(sysMetrics.CPUPercent, 30)
-	fmt.Printf("  总使用率:   %s %s\n", bar, cmd.cli.formatter.FormatPercent(sysMetrics.CPUPercent))
-	fmt.Printf("  用户态:     %.1f%%    内核态: %.1f%%    IO等待: %.1f%%    空闲: %.1f%%\n",
-		sysMetrics.CPUUser, sysMetrics.CPUSystem, sysMetrics.CPUIowait, sysMetrics.CPUIdle)
-	if sysMetrics.LoadAvg1 > 0 || sysMetrics.LoadAvg5 > 0 || sysMetrics.LoadAvg15 > 0 {
+	if sysMetrics.IsDegraded(types.SubsystemCPU) {
+		fmt.Printf("  总使用率:   %s\n", naValue)
+	} else {
+		bar := cmd.cli.formatter.ProgressBar(sysMetrics.CPUPercent, 30)
+		fmt.Printf("  总使用率:   %s %s\n", bar, format.Percent(sysMetrics.CPUPercent))
+		fmt.Printf("  用户态:     %.1f%%    内核态: %.1f%%    IO等待: %.1f%%    空闲: %.1f%%\n",
+			sysMetrics.CPUUser, sysMetrics.CPUSystem, sysMetrics.CPUIowait, sysMetrics.CPUIdle)
+		if sysMetrics.CPUSteal > 0 {
+			fmt.Printf("  宿主机抢占: %.1f%%\n", sysMetrics.CPUSteal)
+		}
+	}
+	if sysMetrics.IsDegraded(types.SubsystemLoad) {
+		fmt.Printf("  系统负载:   %s\n", naValue)
+	} else if sysMetrics.LoadAvg1 > 0 || sysMetrics.LoadAvg5 > 0 || sysMetrics.LoadAvg15 > 0 {
 		fmt.Printf("  系统负载:   %.2f / %.2f / %.2f (1/5/15分钟)\n",
 			sysMetrics.LoadAvg1, sysMetrics.LoadAvg5, sysMetrics.LoadAvg15)
 	}
@@ -150,39 +181,57 @@ func (cmd *SystemCommand) renderStatusContent() {
 
 	// 内存信息
 	fmt.Println(cmd.cli.formatter.Bold("内存:"))
-	memBar := cmd.cli.formatter.ProgressBar(sysMetrics.MemoryPercent, 30)
-	fmt.Printf("  总量:       %s\n", FormatBytes(sysMetrics.MemoryTotal))
-	fmt.Printf("  已用:       %s\n", FormatBytes(sysMetrics.MemoryUsed))
-	fmt.Printf("  可用:       %s\n", FormatBytes(sysMetrics.MemoryAvailable))
-	fmt.Printf("  使用率:     %s %s\n", memBar, cmd.cli.formatter.FormatPercent(sysMetrics.MemoryPercent))
+	if sysMetrics.IsDegraded(types.SubsystemMemory) {
+		fmt.Printf("  使用率:     %s\n", naValue)
+	} else {
+		memBar := cmd.cli.formatter.ProgressBar(sysMetrics.MemoryPercent, 30)
+		fmt.Printf("  总量:       %s\n", format.Bytes(sysMetrics.MemoryTotal))
+		fmt.Printf("  已用:       %s\n", format.Bytes(sysMetrics.MemoryUsed))
+		fmt.Printf("  可用:       %s\n", format.Bytes(sysMetrics.MemoryAvailable))
+		fmt.Printf("  使用率:     %s %s\n", memBar, format.Percent(sysMetrics.MemoryPercent))
+	}
 	fmt.Println()
 
 	// Swap信息
-	if sysMetrics.SwapTotal > 0 {
+	if sysMetrics.IsDegraded(types.SubsystemSwap) {
+		fmt.Println(cmd.cli.formatter.Bold("Swap:"))
+		fmt.Printf("  使用率:     %s\n", naValue)
+		fmt.Println()
+	} else if sysMetrics.SwapTotal > 0 {
 		fmt.Println(cmd.cli.formatter.Bold("Swap:"))
 		swapBar := cmd.cli.formatter.ProgressBar(sysMetrics.SwapPercent, 30)
-		fmt.Printf("  总量:       %s\n", FormatBytes(sysMetrics.SwapTotal))
-		fmt.Printf("  已用:       %s\n", FormatBytes(sysMetrics.SwapUsed))
-		fmt.Printf("  使用率:     %s %s\n", swapBar, cmd.cli.formatter.FormatPercent(sysMetrics.SwapPercent))
+		fmt.Printf("  总量:       %s\n", format.Bytes(sysMetrics.SwapTotal))
+		fmt.Printf("  已用:       %s\n", format.Bytes(sysMetrics.SwapUsed))
+		fmt.Printf("  使用率:     %s %s\n", swapBar, format.Percent(sysMetrics.SwapPercent))
 		if sysMetrics.SwapInRate > 0 || sysMetrics.SwapOutRate > 0 {
-			fmt.Printf("  换入/换出:  %s/s / %s/s\n",
-				FormatBytes(uint64(sysMetrics.SwapInRate)), FormatBytes(uint64(sysMetrics.SwapOutRate)))
+			fmt.Printf("  换入/换出:  %s / %s\n",
+				format.BytesRate(sysMetrics.SwapInRate), format.BytesRate(sysMetrics.SwapOutRate))
 		}
 		fmt.Println()
 	}
 
 	// 网络流量
 	fmt.Println(cmd.cli.formatter.Bold("网络流量:"))
-	fmt.Printf("  接收速率:   %s/s\n", FormatBytes(uint64(sysMetrics.NetRecvRate)))
-	fmt.Printf("  发送速率:   %s/s\n", FormatBytes(uint64(sysMetrics.NetSendRate)))
-	fmt.Printf("  累计接收:   %s\n", FormatBytes(sysMetrics.NetBytesRecv))
-	fmt.Printf("  累计发送:   %s\n", FormatBytes(sysMetrics.NetBytesSent))
+	if sysMetrics.IsDegraded(types.SubsystemNetwork) {
+		fmt.Printf("  接收速率:   %s\n", naValue)
+		fmt.Printf("  发送速率:   %s\n", naValue)
+	} else {
+		fmt.Printf("  接收速率:   %s\n", format.BytesRate(sysMetrics.NetRecvRate))
+		fmt.Printf("  发送速率:   %s\n", format.BytesRate(sysMetrics.NetSendRate))
+		fmt.Printf("  累计接收:   %s\n", format.Bytes(sysMetrics.NetBytesRecv))
+		fmt.Printf("  累计发送:   %s\n", format.Bytes(sysMetrics.NetBytesSent))
+	}
 	fmt.Println()
 
 	// 磁盘IO
 	fmt.Println(cmd.cli.formatter.Bold("磁盘IO:"))
-	fmt.Printf("  读取速率:   %s/s    IOPS: %.0f\n", FormatBytes(uint64(sysMetrics.DiskReadRate)), sysMetrics.DiskReadOps)
-	fmt.Printf("  写入速率:   %s/s    IOPS: %.0f\n", FormatBytes(uint64(sysMetrics.DiskWriteRate)), sysMetrics.DiskWriteOps)
+	if sysMetrics.IsDegraded(types.SubsystemDisk) {
+		fmt.Printf("  读取速率:   %s\n", naValue)
+		fmt.Printf("  写入速率:   %s\n", naValue)
+	} else {
+		fmt.Printf("  读取速率:   %s    IOPS: %.0f\n", format.BytesRate(sysMetrics.DiskReadRate), sysMetrics.DiskReadOps)
+		fmt.Printf("  写入速率:   %s    IOPS: %.0f\n", format.BytesRate(sysMetrics.DiskWriteRate), sysMetrics.DiskWriteOps)
+	}
 	fmt.Println()
 
 	// 磁盘空间
@@ -194,9 +243,9 @@ func (cmd *SystemCommand) renderStatusContent() {
 				fmt.Printf("  %-10s %s %s / %s (%s)\n",
 					p.Mountpoint,
 					diskBar,
-					cmd.cli.formatter.FormatBytes(usage.Used),
-					cmd.cli.formatter.FormatBytes(usage.Total),
-					cmd.cli.formatter.FormatPercent(usage.UsedPercent))
+					format.Bytes(usage.Used),
+					format.Bytes(usage.Total),
+					format.Percent(usage.UsedPercent))
 			}
 		}
 	}
@@ -206,6 +255,11 @@ func (cmd *SystemCommand) renderStatusContent() {
 	fmt.Println(cmd.cli.formatter.Bold("进程统计:"))
 	fmt.Printf("  进程总数:   %d\n", sysMetrics.ProcessCount)
 	fmt.Printf("  线程总数:   %d\n", sysMetrics.ThreadCount)
+	churn := fmt.Sprintf("%d 个/分", sysMetrics.ProcessChurnRate)
+	if sysMetrics.ProcessChurnTop != "" {
+		churn += fmt.Sprintf(" (最频繁: %s)", sysMetrics.ProcessChurnTop)
+	}
+	fmt.Printf("  进程启停:   %s\n", churn)
 	fmt.Println()
 
 	// 监控状态
@@ -218,55 +272,93 @@ func (cmd *SystemCommand) renderStatusContent() {
 	fmt.Printf("  影响事件:   %d\n", len(impacts))
 }
 
-func (cmd *SystemCommand) formatUptime(d time.Duration) string {
-	days := int(d.Hours()) / 24
-	hours := int(d.Hours()) % 24
-	minutes := int(d.Minutes()) % 60
-
-	if days > 0 {
-		return fmt.Sprintf("%d天 %d小时 %d分钟", days, hours, minutes)
-	}
-	if hours > 0 {
-		return fmt.Sprintf("%d小时 %d分钟", hours, minutes)
-	}
-	return fmt.Sprintf("%d分钟", minutes)
+// topHighlight system top 高亮阈值
+type topHighlight struct {
+	warn     float64 // CPU% 黄色阈值
+	crit     float64 // CPU% 红色阈值
+	memBytes uint64  // 内存高亮阈值，0 表示不高亮
 }
 
 func (cmd *SystemCommand) showTopProcesses(args []string) {
 	count := 10
 	onceMode := false
 
+	// 默认使用配置中的高亮阈值，命令行参数仅对本次生效
+	display := cmd.cli.config.Display
+	hl := topHighlight{
+		warn:     display.TopHighlightWarn,
+		crit:     display.TopHighlightCrit,
+		memBytes: uint64(display.TopHighlightMemMB * 1024 * 1024),
+	}
+
 	// 解析参数
-	for _, arg := range args {
-		if arg == "-1" || arg == "once" || arg == "-once" {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch arg {
+		case "-1", "once", "-once":
 			onceMode = true
-		} else if n, err := strconv.Atoi(arg); err == nil && n > 0 {
-			count = n
+		case "--warn", "--crit", "--mem":
+			if i+1 >= len(args) {
+				cmd.cli.printError(fmt.Sprintf("%s 需要一个数值", arg))
+				return
+			}
+			i++
+			v, err := strconv.ParseFloat(args[i], 64)
+			if err != nil || v < 0 {
+				cmd.cli.printError(fmt.Sprintf("无效的 %s 值: %s", arg, args[i]))
+				return
+			}
+			switch arg {
+			case "--warn":
+				hl.warn = v
+			case "--crit":
+				hl.crit = v
+			case "--mem":
+				hl.memBytes = uint64(v * 1024 * 1024)
+			}
+		default:
+			if n, err := strconv.Atoi(arg); err == nil && n > 0 {
+				count = n
+			}
 		}
 	}
 
+	if hl.warn > hl.crit {
+		cmd.cli.printError(fmt.Sprintf("警告阈值 (%.0f) 不能大于严重阈值 (%.0f)", hl.warn, hl.crit))
+		return
+	}
+
+	if cmd.cli.jsonMode() {
+		procList, _ := cmd.getTopProcessList(count)
+		if procList == nil {
+			return
+		}
+		cmd.cli.printJSON(procList)
+		return
+	}
+
 	if onceMode {
-		cmd.showTopProcessesOnce(count)
+		cmd.showTopProcessesOnce(count, hl)
 		return
 	}
 
 	// 默认动态刷新
-	cmd.showTopProcessesWatch(count)
+	cmd.showTopProcessesWatch(count, hl)
 }
 
-func (cmd *SystemCommand) showTopProcessesOnce(count int) {
+func (cmd *SystemCommand) showTopProcessesOnce(count int, hl topHighlight) {
 	fmt.Println(cmd.cli.formatter.Header(fmt.Sprintf("\n=== Top %d 进程 (按CPU排序) ===", count)))
 	fmt.Println()
 
-	procList := cmd.getTopProcessList()
+	procList, pinned := cmd.getTopProcessList(count)
 	if procList == nil {
 		return
 	}
 
-	cmd.printProcessTable(procList, count)
+	cmd.printProcessTable(procList, pinned, hl)
 }
 
-func (cmd *SystemCommand) showTopProcessesWatch(count int) {
+func (cmd *SystemCommand) showTopProcessesWatch(count int, hl topHighlight) {
 	fmt.Println(cmd.cli.formatter.Info("动态监控模式，按 Enter 键退出..."))
 	fmt.Println()
 
@@ -283,7 +375,7 @@ func (cmd *SystemCommand) showTopProcessesWatch(count int) {
 	defer ticker.Stop()
 
 	// 先显示一次
-	cmd.renderTopProcesses(count)
+	cmd.renderTopProcesses(count, hl)
 
 	for {
 		select {
@@ -291,64 +383,101 @@ func (cmd *SystemCommand) showTopProcessesWatch(count int) {
 			cmd.cli.ShowMainScreen()
 			return
 		case <-ticker.C:
-			cmd.renderTopProcesses(count)
+			cmd.renderTopProcesses(count, hl)
 		}
 	}
 }
 
-func (cmd *SystemCommand) renderTopProcesses(count int) {
+func (cmd *SystemCommand) renderTopProcesses(count int, hl topHighlight) {
 	fmt.Print("\033[H\033[J")
-	now := time.Now().Format("15:04:05")
+	now := timefmt.Now().Format("15:04:05")
 	fmt.Printf("=== Top %d 进程 (按CPU排序) === [%s] 按 Enter 退出\n\n", count, now)
 
-	procList := cmd.getTopProcessList()
+	procList, pinned := cmd.getTopProcessList(count)
 	if procList == nil {
 		return
 	}
 
-	cmd.printProcessTable(procList, count)
+	cmd.printProcessTable(procList, pinned, hl)
+}
+
+// processTableColumns system top 表格的列，表头与 Web 页面保持一致
+var processTableColumns = []tableColumn{
+	{"pid", "PID", -7},
+	{"name", "名称", -18},
+	{"cpu", "CPU%", 7},
+	{"mem", "内存", 9},
+	{"mem_growth", "内存增速", 9},
+	{"disk_read", "磁盘读", 8},
+	{"disk_write", "磁盘写", 8},
+	{"net_recv", "网络收", 8},
+	{"net_send", "网络发", 8},
+	{"threads", "线程", 6},
+	{"user", "用户", 0},
 }
 
-func (cmd *SystemCommand) printProcessTable(procList []types.ProcessInfo, count int) {
-	// 表头：与 Web 页面保持一致
-	fmt.Printf("%-7s %-18s %7s %9s %9s %8s %8s %8s %8s %6s %s\n",
-		"PID", "名称", "CPU%", "内存", "内存增速", "磁盘读", "磁盘写", "网络收", "网络发", "线程", "用户")
+// printProcessTable 打印进程表，pinned 中的进程名称前加 ★；显示的列和 CPU% 精度按 display 配置
+func (cmd *SystemCommand) printProcessTable(procList []types.ProcessInfo, pinned map[int32]bool, hl topHighlight) {
+	cols := cmd.cli.selectColumns(processTableColumns)
+	precision := cmd.cli.config.Display.CPUPrecision()
+
+	headers := make([]string, len(cols))
+	for i, col := range cols {
+		headers[i] = padColumn(col.header, col.width)
+	}
+	fmt.Println(strings.Join(headers, " "))
 	fmt.Println(strings.Repeat("-", 120))
 
-	for i := 0; i < len(procList) && i < count; i++ {
+	cells := make([]string, len(cols))
+	for i := range procList {
 		p := procList[i]
-		name := cmd.cli.formatter.Truncate(p.Name, 16)
-		user := cmd.cli.formatter.Truncate(p.Username, 12)
-
-		// CPU 高亮
-		cpuStr := fmt.Sprintf("%7.1f", p.CPUPct)
-		if p.CPUPct > 50 {
-			cpuStr = cmd.cli.formatter.Error(cpuStr)
-		} else if p.CPUPct > 20 {
-			cpuStr = cmd.cli.formatter.Warning(cpuStr)
-		}
-
-		fmt.Printf("%-7d %-18s %s %9s %9s %8s %8s %8s %8s %6d %s\n",
-			p.PID,
-			name,
-			cpuStr,
-			FormatBytes(p.RSSBytes),
-			FormatMemGrowth(p.RSSGrowthRate),
-			FormatBytesRate(p.DiskReadRate),
-			FormatBytesRate(p.DiskWriteRate),
-			FormatBytesRate(p.NetRecvRate),
-			FormatBytesRate(p.NetSendRate),
-			p.NumThreads,
-			user,
-		)
+		name := format.Truncate(ProcessName(&p), 16)
+		if pinned[p.PID] {
+			name = "★" + format.Truncate(ProcessName(&p), 15)
+		}
+		row := map[string]string{
+			"pid":        fmt.Sprintf("%d", p.PID),
+			"name":       name,
+			"cpu":        fmt.Sprintf("%.*f", precision, p.CPUPct),
+			"mem":        format.Bytes(p.RSSBytes),
+			"mem_growth": format.MemGrowth(p.RSSGrowthRate),
+			"disk_read":  format.BytesRate(p.DiskReadRate),
+			"disk_write": format.BytesRate(p.DiskWriteRate),
+			"net_recv":   format.BytesRate(p.NetRecvRate),
+			"net_send":   format.BytesRate(p.NetSendRate),
+			"threads":    fmt.Sprintf("%d", p.NumThreads),
+			"user":       format.Truncate(p.Username, 12),
+		}
+
+		for i, col := range cols {
+			cell := padColumn(row[col.key], col.width)
+			switch col.key {
+			case "cpu":
+				// CPU 高亮
+				if p.CPUPct > hl.crit {
+					cell = cmd.cli.formatter.Error(cell)
+				} else if p.CPUPct > hl.warn {
+					cell = cmd.cli.formatter.Warning(cell)
+				}
+			case "mem":
+				// 内存高亮
+				if hl.memBytes > 0 && p.RSSBytes > hl.memBytes {
+					cell = cmd.cli.formatter.Warning(cell)
+				}
+			}
+			cells[i] = cell
+		}
+		fmt.Println(strings.Join(cells, " "))
 	}
 }
 
-func (cmd *SystemCommand) getTopProcessList() []types.ProcessInfo {
+// getTopProcessList 按 CPU 排序取前 count 个进程，display.pinned_processes 中的进程即使排在后面也附加在末尾；
+// 返回的 PID 集合为其中的常驻进程（含本来就在前 count 个中的）。获取失败时返回 nil
+func (cmd *SystemCommand) getTopProcessList(count int) ([]types.ProcessInfo, map[int32]bool) {
 	procs, err := cmd.cli.monitor.ListAllProcesses()
 	if err != nil {
-		fmt.Println(cmd.cli.formatter.Error(fmt.Sprintf("获取进程列表失败: %v", err)))
-		return nil
+		cmd.cli.printError(fmt.Sprintf("获取进程列表失败: %v", err))
+		return nil, nil
 	}
 
 	// 按CPU排序
@@ -360,7 +489,31 @@ func (cmd *SystemCommand) getTopProcessList() []types.ProcessInfo {
 		}
 	}
 
-	return procs
+	pinnedNames := cmd.cli.config.Display.PinnedProcesses
+	pinned := make(map[int32]bool)
+	result := make([]types.ProcessInfo, 0, count)
+	for i, p := range procs {
+		isPinned := isPinnedProcess(p.Name, pinnedNames)
+		if i >= count && !isPinned {
+			continue
+		}
+		if isPinned {
+			pinned[p.PID] = true
+		}
+		result = append(result, p)
+	}
+	return result, pinned
+}
+
+// isPinnedProcess 进程名是否在常驻列表中：不区分大小写，可省略 .exe 后缀
+func isPinnedProcess(name string, pinned []string) bool {
+	base := strings.TrimSuffix(strings.ToLower(name), ".exe")
+	for _, n := range pinned {
+		if strings.TrimSuffix(strings.ToLower(n), ".exe") == base {
+			return true
+		}
+	}
+	return false
 }
 
 func (cmd *SystemCommand) listProcesses(args []string) {
@@ -369,28 +522,41 @@ func (cmd *SystemCommand) listProcesses(args []string) {
 		pattern = strings.ToLower(args[0])
 	}
 
-	fmt.Println(cmd.cli.formatter.Header("\n=== 进程列表 ==="))
-	fmt.Println()
-
 	// 使用 monitor 的 ListAllProcesses，与 Web 数据源一致
 	procs, err := cmd.cli.monitor.ListAllProcesses()
 	if err != nil {
-		fmt.Println(cmd.cli.formatter.Error(fmt.Sprintf("获取进程列表失败: %v", err)))
+		cmd.cli.printError(fmt.Sprintf("获取进程列表失败: %v", err))
 		return
 	}
 
+	// JSON 输出全部匹配的进程，不受表格 100 条的限制
+	if cmd.cli.jsonMode() {
+		matched := make([]types.ProcessInfo, 0, len(procs))
+		for _, p := range procs {
+			if pattern == "" || processMatches(&p, pattern) {
+				matched = append(matched, p)
+			}
+		}
+		cmd.cli.printJSON(matched)
+		return
+	}
+
+	fmt.Println(cmd.cli.formatter.Header("\n=== 进程列表 ==="))
+	fmt.Println()
+
 	// 获取总内存用于计算百分比
 	var totalMem uint64
 	if memInfo, _ := mem.VirtualMemory(); memInfo != nil {
 		totalMem = memInfo.Total
 	}
 
-	fmt.Println(cmd.cli.formatter.Bold(fmt.Sprintf("%-8s %-30s %10s %10s %-20s", "PID", "名称", "CPU%", "内存%", "状态")))
-	fmt.Println(strings.Repeat("-", 85))
+	fmt.Println(cmd.cli.formatter.Bold(fmt.Sprintf("%-8s %-30s %10s %10s %-22s %-20s", "PID", "名称", "CPU%", "内存%", "端口", "状态")))
+	fmt.Println(strings.Repeat("-", 108))
 
 	count := 0
+	restricted := 0
 	for _, p := range procs {
-		if pattern != "" && !strings.Contains(strings.ToLower(p.Name), pattern) {
+		if pattern != "" && !processMatches(&p, pattern) {
 			continue
 		}
 
@@ -399,9 +565,16 @@ func (cmd *SystemCommand) listProcesses(args []string) {
 			memPct = float64(p.RSSBytes) / float64(totalMem) * 100
 		}
 
-		name := cmd.cli.formatter.Truncate(p.Name, 28)
+		name := format.Truncate(ProcessName(&p), 28)
+
+		status := p.Status
+		if p.Restricted {
+			status += " " + cmd.cli.formatter.Warning("(restricted)")
+			restricted++
+		}
 
-		fmt.Printf("%-8d %-30s %10.1f %10.1f %-20s\n", p.PID, name, p.CPUPct, memPct, p.Status)
+		fmt.Printf("%-8d %-30s %10.1f %10.1f %-22s %-20s\n", p.PID, name, p.CPUPct, memPct,
+			FormatPorts(p.ListenPorts, listPortsLimit), status)
 		count++
 
 		if count >= 100 {
@@ -416,6 +589,10 @@ func (cmd *SystemCommand) listProcesses(args []string) {
 	} else {
 		fmt.Printf(cmd.cli.formatter.Info("总进程数: %d\n"), len(procs))
 	}
+	if restricted > 0 {
+		fmt.Println(cmd.cli.formatter.Warning(fmt.Sprintf(
+			"%d 个进程标记为 (restricted)：权限不足，部分指标（磁盘IO/句柄数等）无法读取显示为 0，请以 root/管理员身份运行", restricted)))
+	}
 }
 
 func (cmd *SystemCommand) showEvents(args []string) {
@@ -445,17 +622,32 @@ func (cmd *SystemCommand) showEvents(args []string) {
 
 	for i := len(events) - 1; i >= start; i-- {
 		ev := events[i]
-		timeStr := ev.Timestamp.Format("01-02 15:04:05")
+		timeStr := timefmt.In(ev.Timestamp).Format("01-02 15:04:05")
 		typeStr := cmd.formatEventType(ev.Type)
-		desc := cmd.cli.formatter.Truncate(ev.Message, 38)
+		desc := format.Truncate(ev.Message, 38)
+		if ev.Count > 1 {
+			desc = format.Truncate(ev.Message, 32) + fmt.Sprintf(" ×%d", ev.Count)
+		}
 
 		fmt.Printf("%-20s %-10s %-10d %-40s\n", timeStr, typeStr, ev.PID, desc)
+		printComments(cmd.cli.formatter, ev.Comments)
 	}
 
 	fmt.Println()
 	fmt.Printf(cmd.cli.formatter.Info("共 %d 条事件\n"), len(events))
 }
 
+// printComments 在事件下方以缩进行显示值班备注：时间、备注人和内容
+func printComments(f *Formatter, comments []types.Comment) {
+	for _, c := range comments {
+		user := c.User
+		if user == "" {
+			user = "-"
+		}
+		fmt.Printf("    └ %s %s\n", f.Color(ColorCyan, fmt.Sprintf("备注 [%s %s]", timefmt.In(c.Time).Format("01-02 15:04"), user)), c.Text)
+	}
+}
+
 func (cmd *SystemCommand) formatEventType(t string) string {
 	switch strings.ToUpper(t) {
 	case "START":
@@ -532,7 +724,7 @@ func (cmd *SystemCommand) watchProcess(args []string) {
 			fmt.Print(clearLine)
 			fmt.Printf("CPU: %-6.1f%% | 内存: %-6.1f%% (%s) | 线程: %-4d | 连接: %-3d",
 				cpu, mem,
-				cmd.cli.formatter.FormatBytes(memInfo.RSS),
+				format.Bytes(memInfo.RSS),
 				threads, len(conns))
 		}
 	}
@@ -564,3 +756,134 @@ func GetHostname() string {
 	}
 	return name
 }
+
+// holder 占用端口或文件的进程（system whohas 的一行）
+type holder struct {
+	PID    int32  `json:"pid"`
+	Name   string `json:"name"`
+	Status string `json:"status"`           // 端口为连接状态（LISTEN、ESTABLISHED 等），文件为进程状态
+	Count  int    `json:"count,omitempty"`  // 同一进程相同状态的连接数
+	Target bool   `json:"target,omitempty"` // 是否为保障对象
+}
+
+// whoHas 反向排查冲突：列出当前占用端口或打开文件的所有进程（不排除任何进程，包括保障对象和本程序）
+func (cmd *SystemCommand) whoHas(args []string) {
+	if len(args) < 2 || (args[0] != "port" && args[0] != "file") {
+		cmd.cli.printError("用法: system whohas port <端口> | system whohas file <路径>")
+		return
+	}
+	var holders []holder
+	var subject string
+	switch args[0] {
+	case "port":
+		port, err := strconv.Atoi(args[1])
+		if err != nil || port <= 0 || port > 65535 {
+			cmd.cli.printError(fmt.Sprintf("无效的端口: %s", args[1]))
+			return
+		}
+		subject = fmt.Sprintf("端口 %d", port)
+		holders = portHolders(impact.NewPortChecker().CheckPort(port, 0))
+	case "file":
+		path := strings.Join(args[1:], " ")
+		subject = fmt.Sprintf("文件 %s", impact.NormalizePath(path))
+		if !cmd.cli.jsonMode() {
+			fmt.Println(cmd.cli.formatter.Info("正在扫描所有进程打开的文件..."))
+		}
+		checker := impact.NewFileChecker()
+		if err := checker.RefreshOpenFiles(nil); err != nil {
+			cmd.cli.printError(fmt.Sprintf("获取进程列表失败: %v", err))
+			return
+		}
+		holders = cmd.fileHolders(checker.CheckFile(path, 0))
+		if _, unreadable := checker.Stats(); unreadable > 0 && !cmd.cli.jsonMode() {
+			fmt.Println(cmd.cli.formatter.Warning(fmt.Sprintf("%d 个进程无法读取打开的文件（权限不足），结果可能不完整", unreadable)))
+		}
+	}
+
+	targets := make(map[int32]bool)
+	for _, t := range cmd.cli.monitor.GetTargets() {
+		targets[t.PID] = true
+	}
+	for i := range holders {
+		holders[i].Target = targets[holders[i].PID]
+	}
+
+	if cmd.cli.jsonMode() {
+		if holders == nil {
+			holders = []holder{}
+		}
+		cmd.cli.printJSON(holders)
+		return
+	}
+	f := cmd.cli.formatter
+	fmt.Println(f.Header(fmt.Sprintf("\n=== %s 的占用进程 ===", subject)))
+	if len(holders) == 0 {
+		fmt.Println(f.Info("没有进程占用" + subject))
+		return
+	}
+	fmt.Println(f.Bold(fmt.Sprintf("%-8s %-30s %-14s", "PID", "名称", "状态")))
+	fmt.Println(strings.Repeat("-", 60))
+	for _, h := range holders {
+		status := h.Status
+		if status == "" {
+			status = "-"
+		}
+		if h.Count > 1 {
+			status = fmt.Sprintf("%s ×%d", status, h.Count)
+		}
+		mark := ""
+		if h.Target {
+			mark = f.Warning("[保障对象]")
+		}
+		fmt.Printf("%-8d %-30s %-14s %s\n", h.PID, format.Truncate(h.Name, 28), status, mark)
+	}
+	fmt.Println()
+	fmt.Printf(f.Info("共 %d 个进程\n"), countPIDs(holders))
+}
+
+// portHolders 按进程和连接状态合并端口占用（同一进程的多个同状态连接合并计数），按 PID 排序
+func portHolders(conflicts []impact.PortConflict) []holder {
+	index := make(map[string]int)
+	var holders []holder
+	for _, c := range conflicts {
+		key := fmt.Sprintf("%d/%s", c.PID, c.Status)
+		if i, ok := index[key]; ok {
+			holders[i].Count++
+			continue
+		}
+		index[key] = len(holders)
+		holders = append(holders, holder{PID: c.PID, Name: c.Name, Status: c.Status, Count: 1})
+	}
+	sort.SliceStable(holders, func(i, j int) bool { return holders[i].PID < holders[j].PID })
+	return holders
+}
+
+// fileHolders 打开文件的进程（同一进程多次打开只列一次），状态取自进程列表
+func (cmd *SystemCommand) fileHolders(conflicts []impact.FileConflict) []holder {
+	status := make(map[int32]string)
+	if procs, err := cmd.cli.monitor.ListAllProcesses(); err == nil {
+		for _, p := range procs {
+			status[p.PID] = p.Status
+		}
+	}
+	seen := make(map[int32]bool)
+	var holders []holder
+	for _, c := range conflicts {
+		if seen[c.PID] {
+			continue
+		}
+		seen[c.PID] = true
+		holders = append(holders, holder{PID: c.PID, Name: c.Name, Status: status[c.PID]})
+	}
+	sort.Slice(holders, func(i, j int) bool { return holders[i].PID < holders[j].PID })
+	return holders
+}
+
+// countPIDs 不同进程的数量
+func countPIDs(holders []holder) int {
+	pids := make(map[int32]bool)
+	for _, h := range holders {
+		pids[h.PID] = true
+	}
+	return len(pids)
+}
@@ -1,14 +1,29 @@
 package cli
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"monitor-agent/format"
+	"monitor-agent/impact"
+	"monitor-agent/monitor"
+	"monitor-agent/profile"
+	"monitor-agent/timefmt"
 	"monitor-agent/types"
 )
 
+// listPortsLimit 列表中每个进程最多显示的监听端口数
+const listPortsLimit = 3
+
 // TargetCommand 目标管理命令组
 type TargetCommand struct {
 	cli *CLI
@@ -34,9 +49,29 @@ func (c *TargetCommand) Handle(subCmd string, args []string) {
 		c.update(args)
 	case "clear":
 		c.clear()
+	case "timeline":
+		c.timeline(args)
+	case "mem":
+		c.mem(args)
+	case "files":
+		c.files(args)
+	case "focus":
+		c.focusMode(args)
+	case "start":
+		c.start()
+	case "stop":
+		c.stop()
+	case "maint", "maintenance":
+		c.maintenance(args)
+	case "export":
+		c.exportTargets(args)
+	case "import":
+		c.importTargets(args)
 	default:
-		fmt.Println(c.cli.formatter.Error(fmt.Sprintf("未知子命令: target %s", subCmd)))
-		c.PrintHelp()
+		c.cli.printError(fmt.Sprintf("未知子命令: target %s", subCmd))
+		if !c.cli.jsonMode() {
+			c.PrintHelp()
+		}
 	}
 }
 
@@ -45,41 +80,85 @@ func (c *TargetCommand) PrintHelp() {
 	fmt.Println(c.cli.formatter.Header("\n目标管理命令 (target):"))
 	fmt.Println()
 	fmt.Println("  target list [-1]              - 列出监控目标 (默认动态刷新, -1 只显示一次)")
-	fmt.Println("  target add <pid|name> [alias] - 添加监控目标")
+	fmt.Println("  target list --by-group [-1]   - 按分组列出监控目标及分组状态、CPU 和内存之和")
+	fmt.Println("  target add <pid|name> [alias] [--force] - 添加监控目标 (--force 允许与已有目标同名同命令行)")
 	fmt.Println("  target remove <pid>           - 移除监控目标")
 	fmt.Println("  target info <pid>             - 显示目标详细信息")
 	fmt.Println("  target update <pid> <options> - 更新目标配置")
 	fmt.Println("  target clear                  - 清除所有监控目标")
+	fmt.Println("  target timeline <pid> [分钟]  - 显示目标时间线 (指标异常/事件/影响)")
+	fmt.Println("  target mem <pid>              - 显示目标内存构成 (排查内存增长类型)")
+	fmt.Println("  target files <pid>            - 显示目标打开的文件/套接字/管道及与上次查询的差异 (排查句柄泄漏)")
+	fmt.Println("  target focus <pid,...> [-interval 200ms] [-for 2m] - 聚焦采样：只对指定目标高频采样，到期恢复")
+	fmt.Println("  target focus [status|stop]    - 查看/提前结束聚焦采样")
+	fmt.Println("  target start                  - 开始监控 (auto_start 关闭时需手动执行)")
+	fmt.Println("  target stop                   - 停止监控")
+	fmt.Println("  target maint <pid|all> <时长> [原因] - 进入维护模式 (暂停告警，如 2h)")
+	fmt.Println("  target maint <pid|all> end    - 提前结束维护模式")
+	fmt.Println("  target export <文件>          - 导出监控目标列表 (JSON，按进程名)")
+	fmt.Println("  target import <文件>          - 按进程名批量添加目标 (已在监控的跳过)")
 	fmt.Println()
 	fmt.Println(c.cli.formatter.Bold("update 选项:"))
 	fmt.Println("  alias <名称>                  - 设置别名")
+	fmt.Println("  group <名称|->                - 设置所属分组，如 SCADA (- 清除)")
+	fmt.Println("  interval <秒|0>               - 该目标的采样间隔，覆盖全局 sampling.interval (0 恢复全局)")
 	fmt.Println("  add-port <端口>               - 添加监控端口")
 	fmt.Println("  add-file <路径>               - 添加监控文件")
+	fmt.Println("  add-dir <路径>                - 添加监控目录 (统计占用，检测增长过快和所在磁盘剩余空间不足)")
+	fmt.Println("  remove-dir <路径>             - 移除监控目录")
+	fmt.Println("  set-threshold <键> <值>       - 设置目标自定义阈值 (键同 impact set)")
+	fmt.Println("  clear-threshold <键|all>      - 清除目标自定义阈值，恢复使用全局配置")
+	fmt.Println("  expect-priority <值|none>     - 期望优先级，偏离时产生风险事件 (Linux 为 20-nice)")
+	fmt.Println("  notes <文本|->                - 处置说明，附加到该目标的影响事件建议中 (- 清空)")
+	fmt.Println("  runbook <http(s)链接|->       - 运行手册链接 (- 清空)")
+	fmt.Println("  restart-cmd <命令|->          - 退出后用于重启的命令 (需开启 watchdog.allow_restart，- 清空)")
+	fmt.Println("  restart-policy <never|on-failure|always> [次数] [间隔秒] - 自动重启策略")
 	fmt.Println()
 	fmt.Println(c.cli.formatter.Info("示例: target add 1234 数据库服务"))
 	fmt.Println(c.cli.formatter.Info("示例: target update 1234 add-port 3306"))
+	fmt.Println(c.cli.formatter.Info("示例: target update 1234 set-threshold proc_cpu 80"))
 }
 
 // list 列出监控目标
 func (c *TargetCommand) list(args []string) {
-	// 检查是否只显示一次
-	onceMode := false
+	// 检查是否只显示一次、是否按分组显示
+	onceMode, byGroup := false, false
 	for _, arg := range args {
-		if arg == "-1" || arg == "once" {
+		switch arg {
+		case "-1", "once":
 			onceMode = true
+		case "--by-group", "-g":
+			byGroup = true
 		}
 	}
 
+	if c.cli.jsonMode() {
+		if byGroup {
+			c.cli.printJSON(c.cli.monitor.GetTargetGroups())
+		} else {
+			c.cli.printJSON(c.cli.monitor.GetTargetList())
+		}
+		return
+	}
+
 	if onceMode {
-		c.listOnce()
+		if byGroup {
+			c.printGroups()
+		} else {
+			c.listOnce()
+		}
 		return
 	}
 
 	// 默认动态刷新
-	c.listWatch()
+	render := c.renderTargetList
+	if byGroup {
+		render = c.renderGroupList
+	}
+	c.listWatch(render)
 }
 
-func (c *TargetCommand) listWatch() {
+func (c *TargetCommand) listWatch(render func()) {
 	fmt.Println(c.cli.formatter.Info("动态监控模式，按 Enter 键退出..."))
 
 	stopChan := make(chan struct{})
@@ -91,7 +170,7 @@ func (c *TargetCommand) listWatch() {
 	ticker := time.NewTicker(2 * time.Second)
 	defer ticker.Stop()
 
-	c.renderTargetList()
+	render()
 
 	for {
 		select {
@@ -99,19 +178,82 @@ func (c *TargetCommand) listWatch() {
 			c.cli.ShowMainScreen()
 			return
 		case <-ticker.C:
-			c.renderTargetList()
+			render()
 		}
 	}
 }
 
+func (c *TargetCommand) renderGroupList() {
+	fmt.Print("\033[H\033[J")
+	fmt.Printf("监控目标分组 [%s] 按 Enter 退出\n", timefmt.Now().Format("15:04:05"))
+	c.printGroups()
+}
+
+// printGroups 按分组显示监控目标：每组一行汇总（分组状态、运行成员数、运行成员的 CPU% 和内存之和），其下列出成员
+func (c *TargetCommand) printGroups() {
+	f := c.cli.formatter
+	groups := c.cli.monitor.GetTargetGroups()
+	if len(groups) == 0 {
+		fmt.Println(f.Warning("当前没有监控目标"))
+		fmt.Println(f.Info("使用 'target add <pid|name>' 添加目标，'target update <pid> group <名称>' 设置分组"))
+		return
+	}
+
+	maint := c.maintenanceRemaining()
+	for _, g := range groups {
+		name := g.Name
+		if name == "" {
+			name = "(未分组)"
+		}
+		status := f.StatusOK("全部运行")
+		switch g.Status {
+		case types.GroupDegraded:
+			status = f.StatusWarn("部分停止")
+		case types.GroupDown:
+			status = f.StatusError("全部停止")
+		}
+		fmt.Println()
+		fmt.Printf("%s  %s  运行 %d/%d  CPU %s  内存 %s  活跃风险 %d\n",
+			f.Bold(name), status, g.Running, g.Total, c.cli.cpuPercent(g.CPUPct), format.Bytes(g.RSSBytes), g.ActiveImpacts)
+
+		table := NewTable("PID", "名称", "别名", "状态", "CPU%", "内存", "活跃风险", "维护")
+		table.PrintHeader()
+		for _, t := range g.Targets {
+			status, cpu, mem := f.StatusError("停止"), "-", "-"
+			if t.Latest != nil && t.Latest.Alive {
+				status = f.StatusOK("运行")
+				cpu = c.cli.cpuPercent(t.Latest.CPUPct)
+				mem = format.Bytes(t.Latest.RSSBytes)
+			}
+			alias := t.Alias
+			if alias == "" {
+				alias = "-"
+			}
+			table.AddRow(
+				fmt.Sprintf("%d", t.PID),
+				format.Truncate(t.Name, 15),
+				format.Truncate(alias, 10),
+				status, cpu, mem,
+				fmt.Sprintf("%d", t.ActiveImpacts),
+				maint(t.PID),
+			)
+		}
+		table.Flush()
+	}
+}
+
 func (c *TargetCommand) renderTargetList() {
 	fmt.Print("\033[H\033[J")
-	now := time.Now().Format("15:04:05")
+	now := timefmt.Now().Format("15:04:05")
 
 	targets := c.cli.monitor.GetTargets()
+	probes := c.cli.monitor.GetProbes()
 	if len(targets) == 0 {
 		fmt.Printf("监控目标列表 [%s] 按 Enter 退出\n\n", now)
-		fmt.Println(c.cli.formatter.Warning("当前没有监控目标"))
+		if len(probes) == 0 {
+			fmt.Println(c.cli.formatter.Warning("当前没有监控目标"))
+		}
+		c.printProbes(probes)
 		return
 	}
 
@@ -124,50 +266,20 @@ func (c *TargetCommand) renderTargetList() {
 	fmt.Printf("监控目标列表 (%d 个) [%s] 按 Enter 退出\n", len(targets), now)
 	fmt.Println(strings.Repeat("-", 120))
 
-	table := NewTable("PID", "名称", "别名", "状态", "CPU%", "内存", "内存增速", "磁盘读", "磁盘写", "网络收", "网络发")
-	table.PrintHeader()
-
-	for _, t := range targets {
-		p, exists := processMap[t.PID]
-		status := c.cli.formatter.StatusError("停止")
-		cpu, mem, memGrowth := "-", "-", "-"
-		diskRead, diskWrite, netRecv, netSend := "-", "-", "-", "-"
-
-		if exists {
-			status = c.cli.formatter.StatusOK("运行")
-			cpu = FormatPercent(p.CPUPct)
-			mem = FormatBytes(p.RSSBytes)
-			memGrowth = FormatMemGrowth(p.RSSGrowthRate)
-			diskRead = FormatBytesRate(p.DiskReadRate)
-			diskWrite = FormatBytesRate(p.DiskWriteRate)
-			netRecv = FormatBytesRate(p.NetRecvRate)
-			netSend = FormatBytesRate(p.NetSendRate)
-		}
-
-		alias := t.Alias
-		if alias == "" {
-			alias = "-"
-		}
-
-		table.AddRow(
-			fmt.Sprintf("%d", t.PID),
-			Truncate(t.Name, 15),
-			Truncate(alias, 10),
-			status,
-			cpu, mem, memGrowth,
-			diskRead, diskWrite, netRecv, netSend,
-		)
-	}
-
-	table.Flush()
+	c.printTargetTable(targets, processMap)
 	fmt.Println(strings.Repeat("-", 120))
+	c.printProbes(probes)
 }
 
 func (c *TargetCommand) listOnce() {
 	targets := c.cli.monitor.GetTargets()
+	probes := c.cli.monitor.GetProbes()
 	if len(targets) == 0 {
-		fmt.Println(c.cli.formatter.Warning("当前没有监控目标"))
-		fmt.Println(c.cli.formatter.Info("使用 'target add <pid|name>' 添加目标"))
+		if len(probes) == 0 {
+			fmt.Println(c.cli.formatter.Warning("当前没有监控目标"))
+			fmt.Println(c.cli.formatter.Info("使用 'target add <pid|name>' 添加目标"))
+		}
+		c.printProbes(probes)
 		return
 	}
 
@@ -188,55 +300,129 @@ func (c *TargetCommand) listOnce() {
 	fmt.Println(c.cli.formatter.Header(fmt.Sprintf("监控目标列表 (%d 个)", len(targets))))
 	fmt.Println(c.cli.formatter.Divider(120))
 
-	table := NewTable("PID", "名称", "别名", "状态", "CPU%", "内存", "内存增速", "磁盘读", "磁盘写", "网络收", "网络发")
+	c.printTargetTable(targets, processMap)
+	fmt.Println(c.cli.formatter.Divider(120))
+	c.printProbes(probes)
+}
+
+// targetTableColumns target list 表格的列
+var targetTableColumns = []tableColumn{
+	{key: "pid", header: "PID"},
+	{key: "name", header: "名称"},
+	{key: "alias", header: "别名"},
+	{key: "status", header: "状态"},
+	{key: "cpu", header: "CPU%"},
+	{key: "mem", header: "内存"},
+	{key: "mem_growth", header: "内存增速"},
+	{key: "disk_read", header: "磁盘读"},
+	{key: "disk_write", header: "磁盘写"},
+	{key: "net_recv", header: "网络收"},
+	{key: "net_send", header: "网络发"},
+	{key: "ports", header: "端口"},
+	{key: "maint", header: "维护"},
+}
+
+// printTargetTable 显示监控目标表格，processMap 中没有的目标显示为停止；显示的列和 CPU% 精度按 display 配置
+func (c *TargetCommand) printTargetTable(targets []types.MonitorTarget, processMap map[int32]*types.ProcessInfo) {
+	cols := c.cli.selectColumns(targetTableColumns)
+	table := NewTable(columnHeaders(cols)...)
 	table.PrintHeader()
 
+	maint := c.maintenanceRemaining()
+
 	for _, t := range targets {
 		p, exists := processMap[t.PID]
 
-		status := c.cli.formatter.StatusError("停止")
-		cpu := "-"
-		mem := "-"
-		memGrowth := "-"
-		diskRead := "-"
-		diskWrite := "-"
-		netRecv := "-"
-		netSend := "-"
-
-		if exists {
-			status = c.cli.formatter.StatusOK("运行")
-			cpu = FormatPercent(p.CPUPct)
-			mem = FormatBytes(p.RSSBytes)
-			memGrowth = FormatMemGrowth(p.RSSGrowthRate)
-			diskRead = FormatBytesRate(p.DiskReadRate)
-			diskWrite = FormatBytesRate(p.DiskWriteRate)
-			netRecv = FormatBytesRate(p.NetRecvRate)
-			netSend = FormatBytesRate(p.NetSendRate)
-		}
-
 		alias := t.Alias
 		if alias == "" {
 			alias = "-"
 		}
+		row := map[string]string{
+			"pid":    fmt.Sprintf("%d", t.PID),
+			"name":   format.Truncate(t.Name, 15),
+			"alias":  format.Truncate(alias, 10),
+			"status": c.cli.formatter.StatusError("停止"),
+			"ports":  "-",
+			"maint":  maint(t.PID),
+		}
+		if exists {
+			row["name"] = format.Truncate(ProcessName(p), 15)
+			row["status"] = c.cli.formatter.StatusOK("运行")
+			row["cpu"] = c.cli.cpuPercent(p.CPUPct)
+			row["mem"] = format.Bytes(p.RSSBytes)
+			row["mem_growth"] = format.MemGrowth(p.RSSGrowthRate)
+			row["disk_read"] = format.BytesRate(p.DiskReadRate)
+			row["disk_write"] = format.BytesRate(p.DiskWriteRate)
+			row["net_recv"] = format.BytesRate(p.NetRecvRate)
+			row["net_send"] = format.BytesRate(p.NetSendRate)
+			row["ports"] = FormatPorts(p.ListenPorts, listPortsLimit)
+		}
+		table.AddRow(columnValues(cols, row)...)
+	}
+
+	table.Flush()
+}
 
+// printProbes 显示远程探测目标：以连接耗时代替 CPU 等进程指标
+func (c *TargetCommand) printProbes(probes []types.ProbeStatus) {
+	if len(probes) == 0 {
+		return
+	}
+	f := c.cli.formatter
+	fmt.Println()
+	fmt.Println(f.Header(fmt.Sprintf("远程探测 (%d 个)", len(probes))))
+	fmt.Println(f.Divider(120))
+
+	table := NewTable("名称", "地址", "状态", "持续", "延迟", "连续失败", "最后探测")
+	table.PrintHeader()
+	for _, p := range probes {
+		status := f.StatusWarn("未知")
+		switch p.State {
+		case monitor.ProbeUp:
+			status = f.StatusOK("可达")
+		case monitor.ProbeDown:
+			status = f.StatusError("不可达")
+		}
+		since := "-"
+		if p.Since != nil {
+			since = format.Uptime(int64(time.Since(*p.Since).Seconds()))
+		}
+		rtt, last := "-", "-"
+		if p.Last != nil {
+			if p.Last.Up {
+				rtt = fmt.Sprintf("%.1fms", p.Last.RTTMs)
+			}
+			last = timefmt.Format(p.Last.Timestamp, "15:04:05")
+		}
 		table.AddRow(
-			fmt.Sprintf("%d", t.PID),
-			Truncate(t.Name, 15),
-			Truncate(alias, 10),
+			format.Truncate(p.Name, 15),
+			format.Truncate(net.JoinHostPort(p.Host, strconv.Itoa(p.Port)), 30),
 			status,
-			cpu, mem, memGrowth,
-			diskRead, diskWrite, netRecv, netSend,
+			since,
+			rtt,
+			fmt.Sprintf("%d/%d", p.Failures, p.FailThreshold),
+			last,
 		)
 	}
-
 	table.Flush()
-	fmt.Println(c.cli.formatter.Divider(120))
+	fmt.Println(f.Divider(120))
 }
 
 // add 添加监控目标
+// 已有同名同命令行的目标时拒绝添加，--force 用于有意监控多个相同实例
 func (c *TargetCommand) add(args []string) {
+	force := false
+	var rest []string
+	for _, arg := range args {
+		if arg == "--force" || arg == "-f" {
+			force = true
+			continue
+		}
+		rest = append(rest, arg)
+	}
+	args = rest
 	if len(args) == 0 {
-		fmt.Println(c.cli.formatter.Error("用法: target add <pid|name> [alias]"))
+		fmt.Println(c.cli.formatter.Error("用法: target add <pid|name> [alias] [--force]"))
 		return
 	}
 
@@ -285,7 +471,7 @@ func (c *TargetCommand) add(args []string) {
 		var matches []types.ProcessInfo
 		searchName := strings.ToLower(args[0])
 		for i := range processes {
-			if strings.Contains(strings.ToLower(processes[i].Name), searchName) {
+			if processMatches(&processes[i], searchName) {
 				matches = append(matches, processes[i])
 			}
 		}
@@ -298,7 +484,7 @@ func (c *TargetCommand) add(args []string) {
 		if len(matches) > 1 {
 			fmt.Println(c.cli.formatter.Warning(fmt.Sprintf("找到 %d 个匹配的进程:", len(matches))))
 			for _, p := range matches[:min(10, len(matches))] {
-				fmt.Printf("  PID %d: %s\n", p.PID, p.Name)
+				fmt.Printf("  PID %d: %s\n", p.PID, processLabel(&p))
 			}
 			if len(matches) > 10 {
 				fmt.Printf("  ... 还有 %d 个进程\n", len(matches)-10)
@@ -315,8 +501,19 @@ func (c *TargetCommand) add(args []string) {
 		}
 	}
 
-	if err := c.cli.monitor.AddTarget(target); err != nil {
+	var err error
+	if force {
+		err = c.cli.monitor.ForceAddTarget(target)
+	} else {
+		err = c.cli.monitor.AddTarget(target)
+	}
+	c.cli.audit("target.add", target, err)
+	if err != nil {
 		fmt.Println(c.cli.formatter.Error(fmt.Sprintf("添加失败: %v", err)))
+		var dup *monitor.DuplicateTargetError
+		if errors.As(err, &dup) {
+			fmt.Println(c.cli.formatter.Info(fmt.Sprintf("旧实例已不需要时先执行 'target remove %d'；确需同时监控多个相同实例时加 --force", dup.PID)))
+		}
 		return
 	}
 
@@ -327,6 +524,65 @@ func (c *TargetCommand) add(args []string) {
 	fmt.Println(c.cli.formatter.Success(fmt.Sprintf("已添加监控目标: %s [PID %d]", displayName, target.PID)))
 }
 
+// exportTargets 导出监控目标列表
+func (c *TargetCommand) exportTargets(args []string) {
+	if len(args) == 0 {
+		fmt.Println(c.cli.formatter.Error("用法: target export <文件>"))
+		return
+	}
+
+	list := profile.ExportTargets(c.cli.monitor)
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err == nil {
+		err = os.WriteFile(args[0], data, 0644)
+	}
+	if err != nil {
+		fmt.Println(c.cli.formatter.Error(fmt.Sprintf("导出失败: %v", err)))
+		return
+	}
+	fmt.Println(c.cli.formatter.Success(fmt.Sprintf("已导出 %d 个监控目标到 %s", len(list.Targets), args[0])))
+}
+
+// importTargets 按进程名批量添加监控目标，文件整体校验通过后才开始添加
+func (c *TargetCommand) importTargets(args []string) {
+	f := c.cli.formatter
+	if len(args) == 0 {
+		fmt.Println(f.Error("用法: target import <文件>"))
+		return
+	}
+
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		fmt.Println(f.Error(fmt.Sprintf("读取失败: %v", err)))
+		return
+	}
+	targets, err := profile.ParseTargets(data)
+	if err != nil {
+		fmt.Println(f.Error(fmt.Sprintf("导入失败: %v", err)))
+		return
+	}
+	result, err := profile.ImportTargets(targets, c.cli.monitor)
+	c.cli.audit("target.import", result, err)
+	if err != nil {
+		fmt.Println(f.Error(fmt.Sprintf("导入失败: %v", err)))
+		return
+	}
+
+	fmt.Println()
+	fmt.Println(f.Header(fmt.Sprintf("批量导入监控目标 - %s", args[0])))
+	fmt.Println(f.Divider(70))
+	for _, ch := range result.Changes {
+		target := ch.Target
+		if ch.PID != 0 {
+			target += fmt.Sprintf(" (PID %d)", ch.PID)
+		}
+		fmt.Printf("  %s %s %s\n", c.cli.configCmd.formatChangeAction(ch.Action), target, ch.Detail)
+	}
+	fmt.Println(f.Divider(70))
+	fmt.Printf("添加 %d, 跳过 %d, 未找到进程 %d, 失败 %d\n",
+		result.Added, result.Skipped, result.Unresolved, result.Failed)
+}
+
 // remove 移除监控目标
 func (c *TargetCommand) remove(args []string) {
 	if len(args) == 0 {
@@ -341,6 +597,7 @@ func (c *TargetCommand) remove(args []string) {
 	}
 
 	c.cli.monitor.RemoveTarget(int32(pid))
+	c.cli.audit("target.remove", map[string]int32{"pid": int32(pid)}, nil)
 	fmt.Println(c.cli.formatter.Success(fmt.Sprintf("已移除监控目标 PID %d", pid)))
 }
 
@@ -399,12 +656,20 @@ func (c *TargetCommand) info(args []string) {
 	if target.Alias != "" {
 		fmt.Printf("  别名:           %s\n", target.Alias)
 	}
+	if target.Group != "" {
+		fmt.Printf("  分组:           %s\n", target.Group)
+	}
 	if target.Cmdline != "" {
-		fmt.Printf("  命令行:         %s\n", Truncate(target.Cmdline, 50))
+		fmt.Printf("  命令行:         %s\n", format.Truncate(target.Cmdline, 50))
+	}
+	if target.SampleInterval > 0 {
+		fmt.Printf("  采样间隔:       %d 秒\n", target.SampleInterval)
+	} else {
+		fmt.Printf("  采样间隔:       %d 秒 (全局)\n", c.cli.config.Sampling.Interval)
 	}
 
 	// 监控配置
-	if len(target.WatchPorts) > 0 || len(target.WatchFiles) > 0 {
+	if len(target.WatchPorts) > 0 || len(target.WatchFiles) > 0 || len(target.WatchDirs) > 0 {
 		fmt.Println(f.Bold("\n[监控配置]"))
 		if len(target.WatchPorts) > 0 {
 			fmt.Printf("  监控端口:       %v\n", target.WatchPorts)
@@ -419,37 +684,165 @@ func (c *TargetCommand) info(args []string) {
 				fmt.Printf("                  - %s\n", file)
 			}
 		}
+		if len(target.WatchDirs) > 0 {
+			fmt.Printf("  监控目录:       %d 个\n", len(target.WatchDirs))
+		}
+	}
+
+	// 监控目录占用
+	if len(target.WatchDirs) > 0 {
+		c.printDirUsage(target)
+	}
+
+	// 自定义阈值
+	if target.Thresholds != nil {
+		fmt.Println(f.Bold("\n[自定义阈值]"))
+		for _, key := range targetThresholdKeys {
+			if v := formatTargetThreshold(target.Thresholds, key); v != "" {
+				fmt.Printf("  %-16s%s\n", key+":", v)
+			}
+		}
+	}
+
+	// 处置说明和运行手册
+	if target.Notes != "" || target.RunbookURL != "" {
+		fmt.Println(f.Bold("\n[处置说明]"))
+		for _, line := range strings.Split(target.Notes, "\n") {
+			if line != "" {
+				fmt.Printf("  %s\n", line)
+			}
+		}
+		if target.RunbookURL != "" {
+			fmt.Printf("  运行手册:       %s\n", target.RunbookURL)
+		}
+	}
+
+	// 自动重启
+	if target.RestartCommand != "" || target.RestartPolicy != nil {
+		fmt.Println(f.Bold("\n[自动重启]"))
+		if target.RestartCommand != "" {
+			fmt.Printf("  重启命令:       %s\n", target.RestartCommand)
+		}
+		if p := target.RestartPolicy; p != nil {
+			attempts, backoff := monitor.RestartLimits(p)
+			fmt.Printf("  重启策略:       %s (连续最多 %d 次，间隔 %v 起逐次翻倍)\n", p.Mode, attempts, backoff)
+		}
+		if !c.cli.config.Watchdog.AllowRestart {
+			fmt.Println("  " + f.Warning("watchdog.allow_restart 未开启，不会自动重启"))
+		}
+	}
+
+	// exec 为其他程序
+	if change := c.cli.monitor.GetExecChange(target.PID); change != nil {
+		fmt.Println(f.Bold("\n[程序切换]"))
+		fmt.Printf("  %s\n", f.StatusError("进程已 exec 为其他程序（PID 不变），当前监控的可能已不是原程序"))
+		fmt.Printf("  原程序:         %s\n", change.From)
+		fmt.Printf("  现程序:         %s\n", change.To)
+		fmt.Printf("  发现时间:       %s\n", timefmt.Format(change.At, "2006-01-02 15:04:05"))
+	}
+
+	// 可执行文件完整性
+	if binary, checkedAt := c.cli.monitor.GetBinaryInfo(target.PID); binary != nil {
+		fmt.Println(f.Bold("\n[程序文件]"))
+		fmt.Printf("  路径:           %s\n", binary.Path)
+		if binary.Cwd != "" {
+			fmt.Printf("  工作目录:       %s\n", binary.Cwd)
+		}
+		if binary.Missing {
+			fmt.Printf("  状态:           %s\n", f.StatusError("文件已删除"))
+		} else {
+			fmt.Printf("  大小:           %s\n", format.Bytes(uint64(binary.Size)))
+			fmt.Printf("  修改时间:       %s\n", timefmt.Format(binary.ModTime, "2006-01-02 15:04:05"))
+		}
+		if binary.SHA256 != "" {
+			fmt.Printf("  SHA256:         %s\n", binary.SHA256)
+		}
+		fmt.Printf("  上次校验:       %s\n", timefmt.Format(checkedAt, "2006-01-02 15:04:05"))
+	}
+
+	// 启动快照
+	if snap, ok := c.cli.monitor.GetLaunchSnapshot(target.PID); ok {
+		c.printSnapshot(snap)
 	}
 
 	// 实时状态
 	if proc != nil {
 		fmt.Println(f.Bold("\n[实时状态]"))
 		fmt.Printf("  状态:           %s\n", f.StatusOK("运行中"))
-		fmt.Printf("  CPU:            %s\n", FormatPercent(proc.CPUPct))
-		fmt.Printf("  内存:           %s\n", FormatBytes(proc.RSSBytes))
-		fmt.Printf("  内存增速:       %s\n", FormatMemGrowth(proc.RSSGrowthRate))
-		fmt.Printf("  虚拟内存:       %s\n", FormatBytes(proc.VMS))
+		if proc.Restricted {
+			fmt.Printf("  权限:           %s\n", f.Warning(fmt.Sprintf("(restricted) 无法读取 %s，对应指标显示为 0",
+				strings.Join(proc.RestrictedFields, ", "))))
+		}
+		fmt.Printf("  CPU:            %s\n", format.Percent(proc.CPUPct))
+		var runWait, blocked *float64
+		if latest := c.cli.monitor.GetMetrics(target.PID, 1); len(latest) > 0 {
+			runWait, blocked = latest[0].RunWaitPct, latest[0].BlockedPct
+		}
+		fmt.Printf("  运行等待:       %s\n", formatSchedWait(runWait))
+		fmt.Printf("  IO阻塞:         %s\n", formatSchedWait(blocked))
+		fmt.Printf("  内存:           %s\n", format.Bytes(proc.RSSBytes))
+		fmt.Printf("  内存增速:       %s\n", format.MemGrowth(proc.RSSGrowthRate))
+		fmt.Printf("  虚拟内存:       %s\n", format.Bytes(proc.VMS))
+		fmt.Printf("  优先级:         %s\n", c.formatPriority(proc, target.ExpectedPriority))
 		fmt.Printf("  线程数:         %d\n", proc.NumThreads)
+		fmt.Printf("  僵尸子进程:     %s\n", formatZombieCount(processes, proc.PID))
 		fmt.Printf("  句柄数:         %d\n", proc.NumFDs)
+		fmt.Printf("  句柄增速:       %+.1f/分钟\n", proc.FDGrowthRate)
 		fmt.Printf("  打开文件:       %d\n", proc.OpenFiles)
-		fmt.Printf("  磁盘读:         %s\n", FormatBytesRate(proc.DiskReadRate))
-		fmt.Printf("  磁盘写:         %s\n", FormatBytesRate(proc.DiskWriteRate))
-		fmt.Printf("  网络收:         %s\n", FormatBytesRate(proc.NetRecvRate))
-		fmt.Printf("  网络发:         %s\n", FormatBytesRate(proc.NetSendRate))
-		fmt.Printf("  运行时长:       %s\n", FormatUptime(proc.Uptime))
+		fmt.Printf("  磁盘读:         %s\n", format.BytesRate(proc.DiskReadRate))
+		fmt.Printf("  磁盘写:         %s\n", format.BytesRate(proc.DiskWriteRate))
+		fmt.Printf("  网络收:         %s\n", format.BytesRate(proc.NetRecvRate))
+		fmt.Printf("  网络发:         %s\n", format.BytesRate(proc.NetSendRate))
+		fmt.Printf("  监听端口:       %s\n", FormatPorts(proc.ListenPorts, 20))
+		fmt.Printf("  CPU亲和性:      %s\n", FormatCPUSet(proc.CPUAffinity, runtime.NumCPU()))
+		fmt.Printf("  运行时长:       %s\n", format.Uptime(proc.Uptime))
 	} else {
 		fmt.Println(f.Bold("\n[实时状态]"))
 		fmt.Printf("  状态:           %s\n", f.StatusError("已停止"))
+		if target.ExpectedPriority != nil {
+			fmt.Printf("  期望优先级:     %d\n", *target.ExpectedPriority)
+		}
 	}
 
 	fmt.Println(f.Divider(60))
 }
 
+// formatSchedWait 格式化调度等待占比，平台或内核不提供（Windows、未开启 schedstat/delayacct）时显示不可用
+func formatSchedWait(pct *float64) string {
+	if pct == nil {
+		return "不可用"
+	}
+	return format.Percent(*pct)
+}
+
+// formatZombieCount 格式化目标未回收的僵尸子进程数，Windows 没有僵尸进程显示不适用
+func formatZombieCount(processes []types.ProcessInfo, pid int32) string {
+	if runtime.GOOS == "windows" {
+		return "不适用"
+	}
+	return fmt.Sprintf("%d", impact.ZombieChildCounts(processes)[pid])
+}
+
+// formatPriority 格式化当前优先级，设置了期望值时一并显示，偏离时高亮
+func (c *TargetCommand) formatPriority(proc *types.ProcessInfo, expected *int32) string {
+	s := fmt.Sprintf("%d", proc.Priority)
+	if runtime.GOOS != "windows" {
+		s += fmt.Sprintf(" (nice %d)", proc.Nice)
+	}
+	if expected == nil {
+		return s
+	}
+	if proc.Priority != *expected {
+		return c.cli.formatter.Warning(fmt.Sprintf("%s，期望 %d (已偏离)", s, *expected))
+	}
+	return fmt.Sprintf("%s，期望 %d", s, *expected)
+}
+
 // update 更新目标配置
 func (c *TargetCommand) update(args []string) {
 	if len(args) < 3 {
 		fmt.Println(c.cli.formatter.Error("用法: target update <pid> <option> <value>"))
-		fmt.Println(c.cli.formatter.Info("选项: alias, add-port, add-file"))
+		fmt.Println(c.cli.formatter.Info("选项: alias, group, interval, add-port, add-file, add-dir, remove-dir, set-threshold, clear-threshold, expect-priority, notes, runbook, restart-cmd, restart-policy"))
 		return
 	}
 
@@ -480,6 +873,18 @@ func (c *TargetCommand) update(args []string) {
 	switch option {
 	case "alias":
 		target.Alias = value
+	case "group":
+		target.Group = value
+		if value == "-" {
+			target.Group = ""
+		}
+	case "interval":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 || n > monitor.MaxTargetInterval {
+			fmt.Println(c.cli.formatter.Error(fmt.Sprintf("无效的采样间隔 (0-%d 秒，0 恢复全局)", monitor.MaxTargetInterval)))
+			return
+		}
+		target.SampleInterval = n
 	case "add-port":
 		port, err := strconv.Atoi(value)
 		if err != nil || port < 1 || port > 65535 {
@@ -489,22 +894,223 @@ func (c *TargetCommand) update(args []string) {
 		target.WatchPorts = append(target.WatchPorts, port)
 	case "add-file":
 		target.WatchFiles = append(target.WatchFiles, value)
+	case "add-dir":
+		target.WatchDirs = append(target.WatchDirs, value)
+	case "remove-dir":
+		abs, _ := filepath.Abs(value)
+		kept := target.WatchDirs[:0]
+		for _, dir := range target.WatchDirs {
+			if dir != value && dir != abs {
+				kept = append(kept, dir)
+			}
+		}
+		if len(kept) == len(target.WatchDirs) {
+			fmt.Println(c.cli.formatter.Info("该目标未监控此目录"))
+			return
+		}
+		target.WatchDirs = kept
+	case "set-threshold":
+		if len(args) < 4 {
+			fmt.Println(c.cli.formatter.Error("用法: target update <pid> set-threshold <键> <值>"))
+			fmt.Println(c.cli.formatter.Info("键: " + strings.Join(targetThresholdKeys, ", ")))
+			return
+		}
+		if target.Thresholds == nil {
+			target.Thresholds = &types.ThresholdOverrides{}
+		}
+		if err := setTargetThreshold(target.Thresholds, strings.ToLower(value), args[3]); err != nil {
+			fmt.Println(c.cli.formatter.Error(err.Error()))
+			return
+		}
+	case "notes":
+		// 处置说明可包含空格，取剩余全部参数，"-" 清空
+		target.Notes = strings.Join(args[2:], " ")
+		if target.Notes == "-" {
+			target.Notes = ""
+		}
+	case "runbook":
+		target.RunbookURL = value
+		if value == "-" {
+			target.RunbookURL = ""
+		}
+	case "restart-cmd":
+		// 命令可包含空格，取剩余全部参数，"-" 清空
+		target.RestartCommand = strings.Join(args[2:], " ")
+		if target.RestartCommand == "-" {
+			target.RestartCommand = ""
+		}
+	case "restart-policy":
+		policy := &types.RestartPolicy{Mode: strings.ToLower(value)}
+		for i, dst := range []*int{&policy.MaxAttempts, &policy.BackoffSec} {
+			if len(args) <= 3+i {
+				break
+			}
+			n, err := strconv.Atoi(args[3+i])
+			if err != nil || n < 0 {
+				fmt.Println(c.cli.formatter.Error("用法: target update <pid> restart-policy <never|on-failure|always> [最多次数] [间隔秒]"))
+				return
+			}
+			*dst = n
+		}
+		target.RestartPolicy = policy
+		if policy.Mode == types.RestartNever {
+			target.RestartPolicy = nil
+		}
+	case "expect-priority":
+		if strings.ToLower(value) == "none" {
+			target.ExpectedPriority = nil
+			break
+		}
+		v, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			fmt.Println(c.cli.formatter.Error("无效的优先级 (Linux 为 20-nice，如 nice 0 对应 20；none 取消)"))
+			return
+		}
+		priority := int32(v)
+		target.ExpectedPriority = &priority
+	case "clear-threshold":
+		if strings.ToLower(value) == "all" {
+			target.Thresholds = nil
+			break
+		}
+		if target.Thresholds == nil {
+			fmt.Println(c.cli.formatter.Info("该目标未设置自定义阈值"))
+			return
+		}
+		if err := setTargetThreshold(target.Thresholds, strings.ToLower(value), ""); err != nil {
+			fmt.Println(c.cli.formatter.Error(err.Error()))
+			return
+		}
+		if *target.Thresholds == (types.ThresholdOverrides{}) {
+			target.Thresholds = nil
+		}
 	default:
 		fmt.Println(c.cli.formatter.Error(fmt.Sprintf("未知选项: %s", option)))
 		return
 	}
 
-	if err := c.cli.monitor.UpdateTarget(*target); err != nil {
+	warnings, err := monitor.NormalizeTargetWatches(target)
+	if err == nil {
+		err = c.cli.monitor.UpdateTarget(*target)
+	}
+	c.cli.audit("target.update", target, err)
+	if err != nil {
 		fmt.Println(c.cli.formatter.Error(fmt.Sprintf("更新失败: %v", err)))
 		return
 	}
+	for _, w := range warnings {
+		fmt.Println(c.cli.formatter.Warning(w))
+	}
 
 	fmt.Println(c.cli.formatter.Success(fmt.Sprintf("已更新目标 PID %d", pid)))
 }
 
+// targetThresholdKeys 可按目标覆盖的阈值键（与 impact set 一致）
+var targetThresholdKeys = []string{
+	"cpu", "memory", "disk_io", "network", "cpu_core",
+	"proc_cpu", "proc_mem", "proc_mem_growth", "proc_vms",
+	"proc_fds", "proc_fd_growth", "proc_threads", "proc_open_files",
+	"proc_disk_read", "proc_disk_write", "proc_net_recv", "proc_net_send",
+	"dir_growth", "dir_min_free",
+}
+
+// targetThresholdField 返回阈值键对应的覆盖字段，浮点与整数字段二者只返回其一
+func targetThresholdField(o *types.ThresholdOverrides, key string) (**float64, **int) {
+	switch key {
+	case "cpu":
+		return &o.CPUThreshold, nil
+	case "memory":
+		return &o.MemoryThreshold, nil
+	case "disk_io":
+		return &o.DiskIOThreshold, nil
+	case "network":
+		return &o.NetworkThreshold, nil
+	case "cpu_core":
+		return &o.CPUCoreThreshold, nil
+	case "proc_cpu":
+		return &o.ProcCPUThreshold, nil
+	case "proc_mem":
+		return &o.ProcMemoryThreshold, nil
+	case "proc_mem_growth":
+		return &o.ProcMemGrowthThreshold, nil
+	case "proc_vms":
+		return &o.ProcVMSThreshold, nil
+	case "proc_fds":
+		return nil, &o.ProcFDsThreshold
+	case "proc_fd_growth":
+		return &o.ProcFDGrowthThreshold, nil
+	case "proc_threads":
+		return nil, &o.ProcThreadsThreshold
+	case "proc_open_files":
+		return nil, &o.ProcOpenFilesThreshold
+	case "proc_disk_read":
+		return &o.ProcDiskReadThreshold, nil
+	case "proc_disk_write":
+		return &o.ProcDiskWriteThreshold, nil
+	case "proc_net_recv":
+		return &o.ProcNetRecvThreshold, nil
+	case "proc_net_send":
+		return &o.ProcNetSendThreshold, nil
+	case "dir_growth":
+		return &o.DirGrowthThreshold, nil
+	case "dir_min_free":
+		return &o.DirMinFreeMB, nil
+	}
+	return nil, nil
+}
+
+// setTargetThreshold 设置目标阈值，value 为空表示清除该项
+// 系统级阈值必须大于 0；进程级、目录阈值与 cpu_core 为 0 表示对该目标禁用此项检测
+func setTargetThreshold(o *types.ThresholdOverrides, key, value string) error {
+	fp, ip := targetThresholdField(o, key)
+	if fp == nil && ip == nil {
+		return fmt.Errorf("未知阈值键: %s", key)
+	}
+	if value == "" {
+		if fp != nil {
+			*fp = nil
+		} else {
+			*ip = nil
+		}
+		return nil
+	}
+
+	if fp != nil {
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil || v < 0 {
+			return fmt.Errorf("无效的阈值: %s", value)
+		}
+		if v == 0 && !strings.HasPrefix(key, "proc_") && !strings.HasPrefix(key, "dir_") && key != "cpu_core" {
+			return fmt.Errorf("系统级阈值 %s 必须大于 0", key)
+		}
+		*fp = &v
+		return nil
+	}
+	v, err := strconv.Atoi(value)
+	if err != nil || v < 0 {
+		return fmt.Errorf("无效的阈值: %s", value)
+	}
+	*ip = &v
+	return nil
+}
+
+// formatTargetThreshold 格式化已设置的目标阈值，未设置返回空字符串
+func formatTargetThreshold(o *types.ThresholdOverrides, key string) string {
+	fp, ip := targetThresholdField(o, key)
+	switch {
+	case fp != nil && *fp != nil:
+		return strconv.FormatFloat(**fp, 'f', -1, 64)
+	case ip != nil && *ip != nil:
+		return strconv.Itoa(**ip)
+	}
+	return ""
+}
+
 // clear 清除所有监控目标
 func (c *TargetCommand) clear() {
+	removed := len(c.cli.monitor.GetTargets())
 	c.cli.monitor.RemoveAllTargets()
+	c.cli.audit("target.remove_all", map[string]int{"removed": removed}, nil)
 	fmt.Println(c.cli.formatter.Success("已清除所有监控目标"))
 }
 
@@ -514,3 +1120,490 @@ func min(a, b int) int {
 	}
 	return b
 }
+
+// timeline 显示目标时间线
+func (c *TargetCommand) timeline(args []string) {
+	if len(args) == 0 {
+		fmt.Println(c.cli.formatter.Error("用法: target timeline <pid> [分钟]"))
+		return
+	}
+
+	pid, err := strconv.ParseInt(args[0], 10, 32)
+	if err != nil {
+		fmt.Println(c.cli.formatter.Error(fmt.Sprintf("无效的 PID: %s", args[0])))
+		return
+	}
+
+	var from time.Time
+	if len(args) > 1 {
+		if minutes, err := strconv.Atoi(args[1]); err == nil && minutes > 0 {
+			from = time.Now().Add(-time.Duration(minutes) * time.Minute)
+		}
+	}
+
+	f := c.cli.formatter
+	fmt.Println()
+	fmt.Println(f.Header(fmt.Sprintf("时间线 - PID %d", pid)))
+	fmt.Println(f.Divider(80))
+
+	total := 0
+	cursor := 0
+	for {
+		page := c.cli.monitor.GetTimeline(int32(pid), from, time.Time{}, cursor, 0)
+		total = page.Total
+		for _, item := range page.Items {
+			fmt.Printf("  %s  %s  %s\n",
+				timefmt.In(item.Timestamp).Format("01-02 15:04:05"),
+				c.formatTimelineKind(item.Kind),
+				format.Truncate(item.Summary, 56))
+		}
+		if page.NextCursor == 0 {
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	if total == 0 {
+		fmt.Println(f.Info("  暂无时间线记录"))
+	}
+	fmt.Println(f.Divider(80))
+	fmt.Printf(f.Info("共 %d 条记录\n"), total)
+}
+
+// snapshotEnvShown target info 中显示的环境变量数量
+const snapshotEnvShown = 10
+
+// printSnapshot 显示启动快照，并与同名目标上一个实例对比
+func (c *TargetCommand) printSnapshot(snap *types.LaunchSnapshot) {
+	f := c.cli.formatter
+	fmt.Println(f.Bold("\n[启动快照]"))
+	fmt.Printf("  记录时间:       %s\n", timefmt.Format(snap.CapturedAt, "2006-01-02 15:04:05"))
+	fmt.Printf("  命令行:         %s\n", snap.Cmdline)
+	fmt.Printf("  工作目录:       %s\n", snap.Cwd)
+	fmt.Printf("  父进程:         %d\n", snap.PPID)
+	if !snap.StartTime.IsZero() {
+		fmt.Printf("  启动时间:       %s\n", timefmt.Format(snap.StartTime, "2006-01-02 15:04:05"))
+	}
+	if snap.EnvError != "" {
+		fmt.Printf("  环境变量:       %s\n", f.Warning("读取失败: "+snap.EnvError))
+	} else {
+		fmt.Printf("  环境变量:       %d 个\n", len(snap.Env))
+		keys := make([]string, 0, len(snap.Env))
+		for k := range snap.Env {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for i, k := range keys {
+			if i >= snapshotEnvShown {
+				fmt.Printf("                  ... 还有 %d 个 (完整内容见 /api/monitor/target/snapshot)\n", len(keys)-snapshotEnvShown)
+				break
+			}
+			fmt.Printf("                  %s=%s\n", k, format.Truncate(snap.Env[k], 50))
+		}
+	}
+
+	if prev := snap.Previous; prev != nil {
+		fmt.Printf("  上一实例:       PID %d，启动于 %s\n", prev.PID, timefmt.Format(prev.StartTime, "2006-01-02 15:04:05"))
+		if changes := snapshotChanges(prev, snap); len(changes) > 0 {
+			fmt.Printf("  启动方式变化:   %s\n", f.Warning(strings.Join(changes, "；")))
+		} else {
+			fmt.Printf("  启动方式变化:   %s\n", f.StatusOK("无"))
+		}
+	}
+}
+
+// snapshotChanges 比较两个实例的启动快照，返回变化项描述
+func snapshotChanges(prev, cur *types.LaunchSnapshot) []string {
+	var changes []string
+	if prev.Cmdline != cur.Cmdline {
+		changes = append(changes, "命令行")
+	}
+	if prev.Cwd != cur.Cwd {
+		changes = append(changes, "工作目录")
+	}
+	if prev.EnvError != "" || cur.EnvError != "" {
+		return changes
+	}
+	var added, removed, modified int
+	for k, v := range cur.Env {
+		if old, ok := prev.Env[k]; !ok {
+			added++
+		} else if old != v {
+			modified++
+		}
+	}
+	for k := range prev.Env {
+		if _, ok := cur.Env[k]; !ok {
+			removed++
+		}
+	}
+	if added+removed+modified > 0 {
+		changes = append(changes, fmt.Sprintf("环境变量 (新增 %d，删除 %d，修改 %d)", added, removed, modified))
+	}
+	return changes
+}
+
+// mem 显示目标内存构成明细
+func (c *TargetCommand) mem(args []string) {
+	if len(args) == 0 {
+		c.cli.printError("用法: target mem <pid>")
+		return
+	}
+	pid, err := strconv.ParseInt(args[0], 10, 32)
+	if err != nil {
+		c.cli.printError(fmt.Sprintf("无效的 PID: %s", args[0]))
+		return
+	}
+	mb, err := c.cli.monitor.GetMemoryBreakdown(int32(pid))
+	if err != nil {
+		c.cli.printError(fmt.Sprintf("获取内存明细失败: %v", err))
+		return
+	}
+	if c.cli.jsonMode() {
+		c.cli.printJSON(mb)
+		return
+	}
+
+	f := c.cli.formatter
+	fmt.Println()
+	fmt.Println(f.Header(fmt.Sprintf("内存构成 - %s (PID %d)", mb.Name, mb.PID)))
+	fmt.Println(f.Divider(80))
+	fmt.Printf("  RSS:            %s\n", format.Bytes(mb.RSSBytes))
+	fmt.Printf("  VMS:            %s\n", format.Bytes(mb.VMSBytes))
+	if runtime.GOOS != "windows" {
+		fmt.Printf("  Swap:           %s\n", format.Bytes(mb.SwapBytes))
+		fmt.Printf("  共享:           %s\n", format.Bytes(mb.SharedBytes))
+	}
+
+	if !mb.Detailed {
+		fmt.Println()
+		fmt.Println(f.Warning(fmt.Sprintf("  无法读取内存明细: %s", mb.DetailError)))
+		fmt.Println(f.Info("  提示: 以 root/管理员身份运行可查看完整明细"))
+		return
+	}
+
+	if runtime.GOOS == "windows" {
+		fmt.Println(f.Bold("\n[内存计数器]"))
+		fmt.Printf("  工作集:         %s (峰值 %s)\n", format.Bytes(mb.WorkingSetBytes), format.Bytes(mb.PeakWorkingSetBytes))
+		fmt.Printf("  私有字节:       %s\n", format.Bytes(mb.PrivateBytes))
+		fmt.Printf("  页面文件:       %s (峰值 %s)\n", format.Bytes(mb.PagefileBytes), format.Bytes(mb.PeakPagefileBytes))
+		fmt.Printf("  分页池:         %s\n", format.Bytes(mb.PagedPoolBytes))
+		fmt.Printf("  非分页池:       %s\n", format.Bytes(mb.NonPagedPoolBytes))
+		return
+	}
+
+	fmt.Println(f.Bold("\n[驻留内存构成]"))
+	fmt.Printf("  匿名内存:       %s\n", format.Bytes(mb.AnonBytes))
+	fmt.Printf("  文件映射:       %s\n", format.Bytes(mb.FileBytes))
+	fmt.Printf("  共享内存:       %s\n", format.Bytes(mb.ShmemBytes))
+	fmt.Printf("  PSS:            %s\n", format.Bytes(mb.PSSBytes))
+
+	fmt.Println(f.Bold(fmt.Sprintf("\n[最大映射区 Top %d]", len(mb.TopMappings))))
+	fmt.Printf("  %-10s %-10s %-10s %-6s %-5s %s\n", "RSS", "大小", "Swap", "类型", "权限", "路径")
+	for _, m := range mb.TopMappings {
+		path := m.Path
+		if path == "" {
+			path = "[anon]"
+		}
+		fmt.Printf("  %-10s %-10s %-10s %-6s %-5s %s\n",
+			format.Bytes(m.RSSBytes), format.Bytes(m.SizeBytes), format.Bytes(m.SwapBytes),
+			m.Kind, m.Perms, format.Truncate(path, 40))
+	}
+	fmt.Println(f.Divider(80))
+}
+
+// files 显示目标打开的文件，按目录分组计数，并列出与上次查询相比新打开和已关闭的路径
+func (c *TargetCommand) files(args []string) {
+	if len(args) == 0 {
+		c.cli.printError("用法: target files <pid>")
+		return
+	}
+	pid, err := strconv.ParseInt(args[0], 10, 32)
+	if err != nil {
+		c.cli.printError(fmt.Sprintf("无效的 PID: %s", args[0]))
+		return
+	}
+	report, err := c.cli.monitor.GetOpenFiles(int32(pid))
+	if err != nil {
+		c.cli.printError(fmt.Sprintf("获取打开文件失败: %v", err))
+		return
+	}
+	if c.cli.jsonMode() {
+		c.cli.printJSON(report)
+		return
+	}
+
+	f := c.cli.formatter
+	fmt.Println()
+	fmt.Println(f.Header(fmt.Sprintf("打开文件 - %s (PID %d)", report.Name, report.PID)))
+	fmt.Println(f.Divider(80))
+	if !report.Complete {
+		fmt.Println(f.Warning(fmt.Sprintf("  无法读取打开文件: %s", report.Note)))
+		fmt.Println(f.Info("  提示: 以 root/管理员身份运行可查看完整列表"))
+		return
+	}
+	fmt.Printf("  句柄总数:       %d (文件 %d, 套接字 %d, 管道 %d, 设备 %d, 其他 %d)\n", report.Total,
+		report.ByType["file"], report.ByType["socket"], report.ByType["pipe"], report.ByType["device"], report.ByType["other"])
+
+	fmt.Println(f.Bold("\n[按目录分组]"))
+	fmt.Printf("  %-8s %-8s %s\n", "数量", "类型", "目录")
+	for i, d := range report.Dirs {
+		if i >= 20 {
+			fmt.Println(f.Info(fmt.Sprintf("  ... 共 %d 组，--json 查看全部", len(report.Dirs))))
+			break
+		}
+		fmt.Printf("  %-8d %-8s %s\n", d.Count, d.Type, format.Truncate(d.Dir, 60))
+	}
+
+	if report.Previous == nil {
+		fmt.Println()
+		fmt.Println(f.Info("  首次查询，再次执行可查看与本次相比新打开和已关闭的路径"))
+	} else {
+		fmt.Println(f.Bold(fmt.Sprintf("\n[与上次查询对比] (%s 前: 新打开 %d, 已关闭 %d)",
+			format.Uptime(int64(report.Timestamp.Sub(*report.Previous).Seconds())), len(report.Added), len(report.Removed))))
+		printEntries := func(prefix string, entries []types.OpenFileEntry) {
+			for i, e := range entries {
+				if i >= 20 {
+					fmt.Println(f.Info(fmt.Sprintf("  ... 共 %d 条，--json 查看全部", len(entries))))
+					break
+				}
+				fmt.Printf("  %s %-8s %s\n", prefix, e.Type, format.Truncate(e.Path, 64))
+			}
+		}
+		printEntries(f.Error("+"), report.Added)
+		printEntries(f.StatusOK("-"), report.Removed)
+		if report.DiffTruncated {
+			fmt.Println(f.Warning("  差异过多，已截断"))
+		}
+	}
+	if report.Truncated {
+		fmt.Println(f.Warning("  打开文件过多，列表已截断"))
+	}
+	fmt.Println(f.Divider(80))
+}
+
+// formatTimelineKind 格式化时间线条目类型
+func (c *TargetCommand) formatTimelineKind(kind string) string {
+	switch kind {
+	case "anomaly":
+		return c.cli.formatter.Warning("[指标异常]")
+	case "event":
+		return c.cli.formatter.Info("[事件]    ")
+	case "impact":
+		return c.cli.formatter.Error("[影响]    ")
+	default:
+		return kind
+	}
+}
+
+// start 开始监控
+func (c *TargetCommand) start() {
+	if c.cli.monitor.IsRunning() {
+		fmt.Println(c.cli.formatter.Info("监控已在运行中"))
+		return
+	}
+	err := c.cli.monitor.Start()
+	c.cli.audit("monitor.start", nil, err)
+	if err != nil {
+		fmt.Println(c.cli.formatter.Info("监控已在运行中"))
+		return
+	}
+	fmt.Println(c.cli.formatter.Success("已开始监控"))
+}
+
+// stop 停止监控
+func (c *TargetCommand) stop() {
+	if !c.cli.monitor.IsRunning() {
+		fmt.Println(c.cli.formatter.Info("监控未运行"))
+		return
+	}
+	c.cli.monitor.Stop()
+	c.cli.audit("monitor.stop", nil, nil)
+	fmt.Println(c.cli.formatter.Success("已停止监控"))
+}
+
+// maintenanceRemaining 返回按 PID 查询维护剩余时间的函数（全局维护对所有目标生效）
+func (c *TargetCommand) maintenanceRemaining() func(pid int32) string {
+	remaining := make(map[int32]time.Duration)
+	for _, w := range c.cli.monitor.GetMaintenance() {
+		remaining[w.PID] = time.Duration(w.Remaining) * time.Second
+	}
+	return func(pid int32) string {
+		d, ok := remaining[pid]
+		if g, gok := remaining[0]; gok && (!ok || g > d) {
+			d, ok = g, true
+		}
+		if !ok {
+			return "-"
+		}
+		return c.cli.formatter.Warning(d.Round(time.Minute).String())
+	}
+}
+
+// focusMode 聚焦采样
+// 用法: target focus <pid[,pid...]> [-interval 200ms] [-for 2m] / target focus [status|stop]
+func (c *TargetCommand) focusMode(args []string) {
+	f := c.cli.formatter
+	if len(args) == 0 || strings.ToLower(args[0]) == "status" {
+		status, ok := c.cli.monitor.GetFocus()
+		if !ok {
+			fmt.Println(f.Info("当前没有聚焦采样"))
+			return
+		}
+		fmt.Println(f.Info(fmt.Sprintf("聚焦采样中: PID %v，间隔 %s，剩余 %s", status.PIDs, status.Interval,
+			time.Until(status.Until).Round(time.Second))))
+		return
+	}
+	if strings.ToLower(args[0]) == "stop" {
+		if c.cli.monitor.StopFocus() {
+			fmt.Println(f.Success("已结束聚焦采样，恢复常规采样"))
+		} else {
+			fmt.Println(f.Info("当前没有聚焦采样"))
+		}
+		return
+	}
+
+	interval := 200 * time.Millisecond
+	duration := 2 * time.Minute
+	var pids []int32
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch arg {
+		case "-interval", "--interval", "-for", "--for":
+			if i+1 >= len(args) {
+				c.cli.printError(fmt.Sprintf("%s 需要参数值", arg))
+				return
+			}
+			i++
+			d, err := time.ParseDuration(args[i])
+			if err != nil || d <= 0 {
+				c.cli.printError(fmt.Sprintf("无效的时长: %s，示例: 200ms、2m", args[i]))
+				return
+			}
+			if strings.TrimLeft(arg, "-") == "interval" {
+				interval = d
+			} else {
+				duration = d
+			}
+		default:
+			for _, s := range strings.Split(arg, ",") {
+				if s == "" {
+					continue
+				}
+				pid, err := strconv.ParseInt(s, 10, 32)
+				if err != nil || pid <= 0 {
+					c.cli.printError(fmt.Sprintf("无效的 PID: %s", s))
+					return
+				}
+				pids = append(pids, int32(pid))
+			}
+		}
+	}
+	if len(pids) == 0 {
+		c.cli.printError("用法: target focus <pid[,pid...]> [-interval 200ms] [-for 2m]")
+		return
+	}
+
+	if err := c.cli.monitor.FocusMode(pids, interval, duration); err != nil {
+		c.cli.printError(fmt.Sprintf("开始聚焦采样失败: %v", err))
+		return
+	}
+	fmt.Println(f.Success(fmt.Sprintf("PID %v 开始聚焦采样：每 %s 采样一次，持续 %s 后恢复常规采样", pids, interval, duration)))
+	fmt.Println(f.Info("提示: 指标缓冲区按条数保留，聚焦期间覆盖的历史时长相应缩短"))
+}
+
+// maintenance 设置维护模式
+// 用法: target maint <pid|all> <时长> [原因] / target maint <pid|all> end
+func (c *TargetCommand) maintenance(args []string) {
+	if len(args) < 2 {
+		fmt.Println(c.cli.formatter.Error("用法: target maint <pid|all> <时长> [原因]"))
+		fmt.Println(c.cli.formatter.Info("示例: target maint 1234 2h \"打补丁\""))
+		fmt.Println(c.cli.formatter.Info("示例: target maint all end"))
+		return
+	}
+
+	var pid int32
+	if strings.ToLower(args[0]) != "all" {
+		v, err := strconv.ParseInt(args[0], 10, 32)
+		if err != nil || v <= 0 {
+			fmt.Println(c.cli.formatter.Error("无效的PID"))
+			return
+		}
+		pid = int32(v)
+	}
+
+	if strings.ToLower(args[1]) == "end" {
+		err := c.cli.monitor.EndMaintenance(pid)
+		c.cli.audit("target.maintenance_end", map[string]int32{"pid": pid}, err)
+		if err != nil {
+			fmt.Println(c.cli.formatter.Error(fmt.Sprintf("结束维护失败: %v", err)))
+			return
+		}
+		fmt.Println(c.cli.formatter.Success("已结束维护模式"))
+		return
+	}
+
+	duration, err := time.ParseDuration(args[1])
+	if err != nil || duration <= 0 {
+		fmt.Println(c.cli.formatter.Error("无效的时长，示例: 30m、2h"))
+		return
+	}
+	reason := strings.Trim(strings.Join(args[2:], " "), "\"'")
+
+	w, err := c.cli.monitor.StartMaintenance(pid, duration, reason)
+	c.cli.audit("target.maintenance_start", map[string]interface{}{
+		"pid":      pid,
+		"duration": args[1],
+		"reason":   reason,
+	}, err)
+	if err != nil {
+		fmt.Println(c.cli.formatter.Error(fmt.Sprintf("进入维护失败: %v", err)))
+		return
+	}
+	scope := fmt.Sprintf("PID %d", pid)
+	if pid == 0 {
+		scope = "全部目标"
+	}
+	fmt.Println(c.cli.formatter.Success(fmt.Sprintf("%s 进入维护模式，至 %s 自动结束", scope, timefmt.Format(w.End, "2006-01-02 15:04:05"))))
+}
+
+// printDirUsage 显示目标监控目录最近一次的统计结果（由影响分析器按文件检测间隔在后台统计）
+func (c *TargetCommand) printDirUsage(target *types.MonitorTarget) {
+	f := c.cli.formatter
+	fmt.Println(f.Bold("\n[目录占用]"))
+	analyzer := c.cli.monitor.GetImpactAnalyzer()
+	if analyzer == nil {
+		fmt.Println("  " + f.Warning("影响分析未启用，不统计目录占用"))
+		return
+	}
+	usage := make(map[string]types.DirUsage)
+	for _, u := range analyzer.GetDirUsage(target.PID) {
+		usage[u.Path] = u
+	}
+	for _, dir := range target.WatchDirs {
+		fmt.Printf("  %s\n", dir)
+		u, ok := usage[dir]
+		switch {
+		case !ok:
+			fmt.Println("    尚未统计")
+			continue
+		case u.Error != "":
+			fmt.Printf("    %s\n", f.StatusError(u.Error))
+			continue
+		}
+		size := format.Bytes(u.SizeBytes)
+		if u.Truncated {
+			size += " (条目过多，仅统计部分)"
+		}
+		fmt.Printf("    大小:         %s，%d 个文件\n", size, u.Files)
+		if u.TotalBytes > 0 {
+			fmt.Printf("    磁盘剩余:     %s / %s\n", format.Bytes(u.FreeBytes), format.Bytes(u.TotalBytes))
+		}
+		if u.GrowthMBPerHour != nil {
+			fmt.Printf("    增长速度:     %+.1f MB/小时\n", *u.GrowthMBPerHour)
+		} else {
+			fmt.Println("    增长速度:     统计不足 5 分钟")
+		}
+		fmt.Printf("    统计时间:     %s (耗时 %d ms)\n", timefmt.Format(u.ScannedAt, "2006-01-02 15:04:05"), u.ScanMillis)
+	}
+}
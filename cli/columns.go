@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"fmt"
+)
+
+// 表格列选择和 CPU% 精度：display.table_columns、display.precision 在每次渲染时读取，config set 修改后立即生效
+
+// tableColumn 表格的一列：列名（display.table_columns 中的名称）、表头和宽度。
+// 宽度只用于按固定宽度输出的表格，负数左对齐，0 不填充
+type tableColumn struct {
+	key    string
+	header string
+	width  int
+}
+
+// selectColumns 按 display.table_columns 选出要显示的列，顺序与 cols 相同
+func (c *CLI) selectColumns(cols []tableColumn) []tableColumn {
+	selected := make([]tableColumn, 0, len(cols))
+	for _, col := range cols {
+		if c.config.Display.ShowColumn(col.key) {
+			selected = append(selected, col)
+		}
+	}
+	return selected
+}
+
+// cpuPercent 按 display.precision 格式化 CPU%（如 "12.5%"）
+func (c *CLI) cpuPercent(pct float64) string {
+	return fmt.Sprintf("%.*f%%", c.config.Display.CPUPrecision(), pct)
+}
+
+// columnHeaders 各列的表头
+func columnHeaders(cols []tableColumn) []string {
+	headers := make([]string, len(cols))
+	for i, col := range cols {
+		headers[i] = col.header
+	}
+	return headers
+}
+
+// columnValues 按列顺序取出一行的值，row 中没有的列为 "-"
+func columnValues(cols []tableColumn, row map[string]string) []string {
+	values := make([]string, len(cols))
+	for i, col := range cols {
+		v, ok := row[col.key]
+		if !ok {
+			v = "-"
+		}
+		values[i] = v
+	}
+	return values
+}
+
+// padColumn 按列宽填充（负数左对齐，0 不填充）
+func padColumn(s string, width int) string {
+	switch {
+	case width < 0:
+		return fmt.Sprintf("%-*s", -width, s)
+	case width > 0:
+		return fmt.Sprintf("%*s", width, s)
+	}
+	return s
+}
@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"monitor-agent/config"
+	"monitor-agent/format"
+	"monitor-agent/monitor"
+	"monitor-agent/provider"
+	"monitor-agent/types"
+)
+
+// newTestCLI 以 provider.Fake 为数据来源的命令行界面，关闭颜色以便比较输出
+func newTestCLI(t *testing.T, procs ...types.ProcessInfo) (*CLI, *provider.Fake) {
+	t.Helper()
+	prov := provider.NewFake(nil)
+	prov.SetProcesses(procs)
+	cfg := config.DefaultConfig()
+	m, err := monitor.NewMultiMonitor(types.MultiMonitorConfig{SampleInterval: 1, LogDir: t.TempDir()}, prov)
+	if err != nil {
+		t.Fatalf("NewMultiMonitor: %v", err)
+	}
+	c := NewCLI(m, "", cfg)
+	c.formatter.colorEnabled = false
+	return c, prov
+}
+
+// captureStdout 执行 fn 并返回其标准输出
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	out := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		out <- string(data)
+	}()
+	defer func() {
+		os.Stdout = stdout
+	}()
+	fn()
+	w.Close()
+	return <-out
+}
+
+// rowOf 输出中包含 marker 的行
+func rowOf(t *testing.T, output, marker string) string {
+	t.Helper()
+	for _, line := range strings.Split(output, "\n") {
+		if strings.Contains(line, marker) {
+			return line
+		}
+	}
+	t.Fatalf("no line with %q in output:\n%s", marker, output)
+	return ""
+}
+
+// 目标列表和进程表的字节数、速率和内存增速与 format 包的格式一致（包括不足 1 B/s 的增速和单位口径）
+func TestTablesUseSharedFormatting(t *testing.T) {
+	procs := []types.ProcessInfo{
+		{PID: 4101, Name: "grow-slow", RSSBytes: 1536, RSSGrowthRate: 0.4, DiskReadRate: 2048},
+		{PID: 4102, Name: "shrink-slow", RSSBytes: 3 << 30, RSSGrowthRate: -0.3, NetSendRate: 1.5 * 1024 * 1024},
+		{PID: 4103, Name: "flat", RSSBytes: 512},
+		{PID: 4104, Name: "grow-fast", RSSBytes: 5 << 20, RSSGrowthRate: 4096},
+	}
+
+	for _, units := range []string{format.UnitsDefault, format.UnitsBinary, format.UnitsDecimal} {
+		if err := format.SetByteUnits(units); err != nil {
+			t.Fatal(err)
+		}
+		c, _ := newTestCLI(t, procs...)
+		for _, p := range procs {
+			if err := c.monitor.AddTarget(types.MonitorTarget{PID: p.PID, Name: p.Name}); err != nil {
+				t.Fatalf("AddTarget %d: %v", p.PID, err)
+			}
+		}
+		processMap := make(map[int32]*types.ProcessInfo)
+		for i := range procs {
+			processMap[procs[i].PID] = &procs[i]
+		}
+		targetOut := captureStdout(t, func() { c.targetCmd.printTargetTable(c.monitor.GetTargets(), processMap) })
+		processOut := captureStdout(t, func() { c.systemCmd.printProcessTable(procs, nil, topHighlight{warn: 100, crit: 100}) })
+
+		for _, p := range procs {
+			want := []string{
+				format.Bytes(p.RSSBytes),
+				format.MemGrowth(p.RSSGrowthRate),
+				format.BytesRate(p.DiskReadRate),
+				format.BytesRate(p.NetSendRate),
+			}
+			for name, out := range map[string]string{"target list": targetOut, "process table": processOut} {
+				row := rowOf(t, out, p.Name)
+				for _, cell := range want {
+					if !strings.Contains(row, cell) {
+						t.Errorf("units %q: %s row %q missing %q", units, name, row, cell)
+					}
+				}
+				if strings.Contains(row, "+0 B/s") || strings.Contains(row, "-0 B/s") {
+					t.Errorf("units %q: %s row %q shows a sub-1 B/s growth as zero", units, name, row)
+				}
+			}
+		}
+	}
+	format.SetByteUnits(format.UnitsDefault)
+}
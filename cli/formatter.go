@@ -3,8 +3,11 @@ package cli
 import (
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 	"text/tabwriter"
+
+	"monitor-agent/types"
 )
 
 // Color constants for terminal output
@@ -130,78 +133,87 @@ func (t *Table) Flush() {
 	t.writer.Flush()
 }
 
-// FormatBytes 格式化字节数
-func FormatBytes(bytes uint64) string {
-	if bytes < 1024 {
-		return fmt.Sprintf("%d B", bytes)
-	}
-	if bytes < 1024*1024 {
-		return fmt.Sprintf("%.1f KB", float64(bytes)/1024)
+// FormatPorts 格式化监听端口（去重排序，如 ":80,:443"），超过 limit 个时只显示前 limit 个并附 "+N"，无端口时为 "-"
+func FormatPorts(ports []int, limit int) string {
+	if len(ports) == 0 {
+		return "-"
 	}
-	if bytes < 1024*1024*1024 {
-		return fmt.Sprintf("%.1f MB", float64(bytes)/1024/1024)
+	seen := make(map[int]bool, len(ports))
+	uniq := make([]int, 0, len(ports))
+	for _, p := range ports {
+		if !seen[p] {
+			seen[p] = true
+			uniq = append(uniq, p)
+		}
 	}
-	return fmt.Sprintf("%.2f GB", float64(bytes)/1024/1024/1024)
-}
+	sort.Ints(uniq)
 
-// FormatBytesRate 格式化字节率
-func FormatBytesRate(bytesPerSec float64) string {
-	return FormatBytes(uint64(bytesPerSec)) + "/s"
-}
-
-// FormatPercent 格式化百分比
-func FormatPercent(pct float64) string {
-	return fmt.Sprintf("%.1f%%", pct)
+	shown := uniq
+	if limit > 0 && len(uniq) > limit {
+		shown = uniq[:limit]
+	}
+	parts := make([]string, len(shown))
+	for i, p := range shown {
+		parts[i] = fmt.Sprintf(":%d", p)
+	}
+	s := strings.Join(parts, ",")
+	if len(uniq) > len(shown) {
+		s += fmt.Sprintf(" +%d", len(uniq)-len(shown))
+	}
+	return s
 }
 
-// FormatMemGrowth 格式化内存增速
-func FormatMemGrowth(rate float64) string {
-	if rate > 0 {
-		return fmt.Sprintf("+%s/s", FormatBytes(uint64(rate)))
-	} else if rate < 0 {
-		return fmt.Sprintf("-%s/s", FormatBytes(uint64(-rate)))
+// FormatCPUSet 格式化 CPU 亲和性核心列表（连续核心合并为区间，如 "0-3,6 (5/8 核)"），为空时为 "-"
+func FormatCPUSet(cores []int, total int) string {
+	if len(cores) == 0 {
+		return "-"
 	}
-	return "0"
-}
+	sorted := append([]int(nil), cores...)
+	sort.Ints(sorted)
 
-// FormatUptime 格式化运行时间
-func FormatUptime(seconds int64) string {
-	if seconds < 60 {
-		return fmt.Sprintf("%d秒", seconds)
+	var parts []string
+	for i := 0; i < len(sorted); {
+		j := i
+		for j+1 < len(sorted) && sorted[j+1] == sorted[j]+1 {
+			j++
+		}
+		if j > i {
+			parts = append(parts, fmt.Sprintf("%d-%d", sorted[i], sorted[j]))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d", sorted[i]))
+		}
+		i = j + 1
 	}
-	if seconds < 3600 {
-		return fmt.Sprintf("%d分%d秒", seconds/60, seconds%60)
+	s := strings.Join(parts, ",")
+	if total > 0 && len(sorted) >= total {
+		return s + fmt.Sprintf(" (全部 %d 核)", total)
 	}
-	if seconds < 86400 {
-		return fmt.Sprintf("%d时%d分", seconds/3600, (seconds%3600)/60)
+	if total > 0 {
+		return s + fmt.Sprintf(" (%d/%d 核)", len(sorted), total)
 	}
-	return fmt.Sprintf("%d天%d时", seconds/86400, (seconds%86400)/3600)
+	return s
 }
 
-// Truncate 截断字符串
-func Truncate(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s
-	}
-	if maxLen <= 3 {
-		return s[:maxLen]
+// ProcessName 进程的显示名称：配置了 display.name_aliases 时使用显示名称，否则使用进程名
+func ProcessName(p *types.ProcessInfo) string {
+	if p.DisplayName != "" {
+		return p.DisplayName
 	}
-	return s[:maxLen-3] + "..."
+	return p.Name
 }
 
-// Truncate 截断字符串 (Formatter 方法)
-func (f *Formatter) Truncate(s string, maxLen int) string {
-	return Truncate(s, maxLen)
-}
-
-// FormatBytes 格式化字节 (Formatter 方法)
-func (f *Formatter) FormatBytes(bytes uint64) string {
-	return FormatBytes(bytes)
+// processLabel 显示名称与进程名，如 "IIS 应用程序池 (w3wp.exe)"，未配置显示名称时只有进程名
+func processLabel(p *types.ProcessInfo) string {
+	if p.DisplayName != "" {
+		return fmt.Sprintf("%s (%s)", p.DisplayName, p.Name)
+	}
+	return p.Name
 }
 
-// FormatPercent 格式化百分比 (Formatter 方法)
-func (f *Formatter) FormatPercent(pct float64) string {
-	return FormatPercent(pct)
+// processMatches 进程名或显示名称包含关键字（关键字需为小写）
+func processMatches(p *types.ProcessInfo, pattern string) bool {
+	return strings.Contains(strings.ToLower(p.Name), pattern) ||
+		strings.Contains(strings.ToLower(p.DisplayName), pattern)
 }
 
 // FormatBool 格式化布尔值
@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// JSON 输出模式：供自动化脚本调用，结果使用与 Web API 相同的结构体序列化，字段名一致。
+// 支持 target list、target mem、target files、system top、system ps、impact list、impact summary、log tail、config show，
+// 每条命令输出一行 JSON 到 stdout；错误以 {"error": "..."} 输出到 stderr。
+
+// SetJSONOutput 设置全局 JSON 输出模式（-json 启动参数），开启后不显示横幅、提示符和颜色
+func (c *CLI) SetJSONOutput(enabled bool) {
+	c.jsonOutput = enabled
+	c.formatter.colorEnabled = !enabled
+}
+
+// ExitCode JSON 模式下有命令失败时返回 1，否则返回 0
+func (c *CLI) ExitCode() int {
+	if c.failed {
+		return 1
+	}
+	return 0
+}
+
+// jsonMode 当前命令是否输出 JSON（全局 JSON 模式或命令带 --json）
+func (c *CLI) jsonMode() bool {
+	return c.jsonOutput || c.jsonCmd
+}
+
+// printJSON 将结果输出为单行 JSON
+func (c *CLI) printJSON(v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		c.printError(fmt.Sprintf("encode json: %v", err))
+		return
+	}
+	fmt.Println(string(data))
+}
+
+// printError 输出错误：JSON 模式下写到 stderr 并记录失败，否则红色显示
+func (c *CLI) printError(msg string) {
+	if !c.jsonMode() {
+		fmt.Println(c.formatter.Error(msg))
+		return
+	}
+	c.failed = true
+	data, _ := json.Marshal(map[string]string{"error": msg})
+	fmt.Fprintln(os.Stderr, string(data))
+}
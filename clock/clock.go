@@ -0,0 +1,135 @@
+// Package clock 可替换的时间来源：影响分析器和监控器通过它取当前时间、监控器的采样调度通过它等待，
+// 测试或回放时替换为 Fake，无需真实等待即可推进时间
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock 时间来源
+type Clock interface {
+	// Now 当前时间
+	Now() time.Time
+	// Since 自 t 起经过的时间
+	Since(t time.Time) time.Duration
+	// NewTimer 创建 d 之后触发一次的定时器，d<=0 时立即触发
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer 单次定时器，触发时向 C() 发送触发时刻
+type Timer interface {
+	C() <-chan time.Time
+	// Stop 停止定时器，已触发或已停止时返回 false
+	Stop() bool
+}
+
+// Real 系统时钟
+var Real Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                  { return time.Now() }
+func (realClock) Since(t time.Time) time.Duration { return time.Since(t) }
+func (realClock) NewTimer(d time.Duration) Timer  { return realTimer{time.NewTimer(d)} }
+
+type realTimer struct{ t *time.Timer }
+
+func (r realTimer) C() <-chan time.Time { return r.t.C }
+func (r realTimer) Stop() bool          { return r.t.Stop() }
+
+// Fake 手动推进的时钟，只在调用 Set/Advance 时变化，可并发使用。
+// 定时器在 Set/Advance 使时钟到达其触发时刻时触发
+type Fake struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+// fakeTimer Fake 的定时器
+type fakeTimer struct {
+	f  *Fake
+	at time.Time
+	c  chan time.Time
+}
+
+// NewFake 创建停在 t 的时钟
+func NewFake(t time.Time) *Fake {
+	return &Fake{now: t}
+}
+
+// Now 当前时间
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Since 自 t 起经过的时间
+func (f *Fake) Since(t time.Time) time.Duration {
+	return f.Now().Sub(t)
+}
+
+// Set 把时钟设置到 t，触发到期的定时器
+func (f *Fake) Set(t time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = t
+	f.fireLocked()
+}
+
+// Advance 把时钟向前推进 d，触发到期的定时器，返回推进后的时间
+func (f *Fake) Advance(d time.Duration) time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+	f.fireLocked()
+	return f.now
+}
+
+// NewTimer 创建在时钟推进 d 后触发的定时器
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t := &fakeTimer{f: f, at: f.now.Add(d), c: make(chan time.Time, 1)}
+	f.timers = append(f.timers, t)
+	f.fireLocked()
+	return t
+}
+
+// Timers 尚未触发也未停止的定时器数，测试用它确认等待方已开始等待再推进时钟
+func (f *Fake) Timers() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.timers)
+}
+
+// fireLocked 触发已到期的定时器（调用方需持有 mu）
+func (f *Fake) fireLocked() {
+	pending := f.timers[:0]
+	for _, t := range f.timers {
+		if t.at.After(f.now) {
+			pending = append(pending, t)
+			continue
+		}
+		t.c <- f.now // 缓冲为 1，每个定时器只触发一次
+	}
+	for i := len(pending); i < len(f.timers); i++ {
+		f.timers[i] = nil
+	}
+	f.timers = pending
+}
+
+func (t *fakeTimer) C() <-chan time.Time { return t.c }
+
+func (t *fakeTimer) Stop() bool {
+	t.f.mu.Lock()
+	defer t.f.mu.Unlock()
+	for i, p := range t.f.timers {
+		if p == t {
+			t.f.timers = append(t.f.timers[:i], t.f.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
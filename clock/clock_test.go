@@ -0,0 +1,77 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+var start = time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+// fired 定时器是否已触发（不阻塞）
+func fired(t Timer) bool {
+	select {
+	case <-t.C():
+		return true
+	default:
+		return false
+	}
+}
+
+func TestFakeTimerFiresOnAdvance(t *testing.T) {
+	f := NewFake(start)
+	timer := f.NewTimer(5 * time.Second)
+	if f.Timers() != 1 {
+		t.Fatalf("pending timers = %d, want 1", f.Timers())
+	}
+
+	f.Advance(4 * time.Second)
+	if fired(timer) {
+		t.Fatal("timer fired before its deadline")
+	}
+	f.Advance(time.Second)
+	select {
+	case at := <-timer.C():
+		if !at.Equal(start.Add(5 * time.Second)) {
+			t.Fatalf("fired at %v, want %v", at, start.Add(5*time.Second))
+		}
+	default:
+		t.Fatal("timer did not fire at its deadline")
+	}
+	if f.Timers() != 0 || timer.Stop() {
+		t.Fatal("fired timer is still pending")
+	}
+
+	// 只触发一次
+	f.Advance(time.Hour)
+	if fired(timer) {
+		t.Fatal("timer fired twice")
+	}
+}
+
+func TestFakeTimerStopAndImmediate(t *testing.T) {
+	f := NewFake(start)
+	stopped := f.NewTimer(time.Second)
+	if !stopped.Stop() {
+		t.Fatal("Stop of a pending timer returned false")
+	}
+	f.Set(start.Add(time.Minute))
+	if fired(stopped) {
+		t.Fatal("stopped timer fired")
+	}
+
+	if !fired(f.NewTimer(0)) || !fired(f.NewTimer(-time.Second)) {
+		t.Fatal("timer with non-positive duration did not fire immediately")
+	}
+	if f.Timers() != 0 {
+		t.Fatalf("pending timers = %d, want 0", f.Timers())
+	}
+}
+
+func TestRealTimer(t *testing.T) {
+	timer := Real.NewTimer(time.Millisecond)
+	select {
+	case <-timer.C():
+	case <-time.After(5 * time.Second):
+		t.Fatal("real timer did not fire")
+	}
+}
@@ -4,27 +4,32 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"os"
 
+	"monitor-agent/buildinfo"
 	"monitor-agent/cli"
 	"monitor-agent/config"
-	"monitor-agent/service"
+	"monitor-agent/monitoragent"
+	"monitor-agent/selftest"
 )
 
-var version = "1.0.0"
-
 func main() {
 	var (
 		addr        = flag.String("addr", "", "HTTP server address (overrides config)")
 		logDir      = flag.String("log-dir", "", "log directory (overrides config)")
 		configFile  = flag.String("config", "config.json", "config file path")
 		genConfig   = flag.Bool("gen-config", false, "generate example config file")
+		checkConfig = flag.Bool("check-config", false, "validate config file and exit (nonzero exit status on errors)")
+		runSelfTest = flag.Bool("selftest", false, "check privileges and readable metrics, print a PASS/WARN/FAIL report and exit (nonzero exit status on failures)")
 		showVersion = flag.Bool("version", false, "show version")
+		jsonOut     = flag.Bool("json", false, "CLI JSON output mode for automation: no banner, prompt or colors, supported commands print JSON")
+		lang        = flag.String("lang", "", "language of event messages and impact descriptions: zh or en (overrides config)")
 	)
 	flag.Parse()
 
 	// 显示版本
 	if *showVersion {
-		fmt.Printf("Monitor Agent v%s\n", version)
+		fmt.Println(buildinfo.Full())
 		return
 	}
 
@@ -37,6 +42,16 @@ func main() {
 		return
 	}
 
+	// 只校验配置，不启动服务
+	if *checkConfig {
+		os.Exit(runConfigCheck(*configFile, *addr, *logDir, *lang))
+	}
+
+	// 只自检，不启动服务
+	if *runSelfTest {
+		os.Exit(runSelfTestReport(*configFile, *logDir))
+	}
+
 	// 加载配置
 	cfg, err := config.LoadConfig(*configFile)
 	if err != nil {
@@ -50,39 +65,115 @@ func main() {
 	if *logDir != "" {
 		cfg.Logging.Dir = *logDir
 	}
-
-	// 转换为服务配置
-	serviceCfg := service.Config{
-		Addr:       cfg.Server.Addr,
-		LogDir:     cfg.Logging.Dir,
-		ConfigFile: *configFile,
+	if *lang != "" {
+		cfg.Language = *lang
 	}
 
 	// 启动 CLI + Web 模式
-	runCLIWithWeb(serviceCfg, cfg)
+	os.Exit(runCLIWithWeb(*configFile, cfg, *jsonOut))
 }
 
-func runCLIWithWeb(serviceCfg service.Config, cfg *config.Config) {
-	s, err := service.NewWithConfig(serviceCfg, cfg)
+// runCLIWithWeb 运行服务和 CLI，返回进程退出码
+func runCLIWithWeb(configFile string, cfg *config.Config, jsonOut bool) int {
+	agent, err := monitoragent.NewWithOptions(cfg, monitoragent.Options{
+		ConfigFile:    configFile,
+		CaptureStdLog: true,
+		NoConsoleLog:  jsonOut,
+	})
 	if err != nil {
 		log.Fatalf("Create service failed: %v", err)
 	}
 
-	if err := s.Start(); err != nil {
+	if err := agent.Start(); err != nil {
 		log.Fatalf("Start failed: %v", err)
 	}
 
-	// 显示启动信息
-	fmt.Println("Monitor Agent started")
-	fmt.Printf("Web interface: http://localhost%s\n", cfg.Server.Addr)
-	fmt.Printf("Monitoring %d targets\n", len(cfg.Targets))
-	fmt.Println("提示: 输入 'log console on' 可开启终端日志输出")
-	fmt.Println()
+	// 显示启动信息（JSON 模式下 stdout 只输出命令结果）
+	if !jsonOut {
+		fmt.Println("Monitor Agent started")
+		fmt.Printf("Web interface: http://localhost%s\n", cfg.Server.Addr)
+		fmt.Printf("Monitoring %d targets\n", len(cfg.Targets))
+		if report := agent.SelfTest(); report.Status != selftest.StatusPass {
+			fmt.Printf("Self-test: %s (run with -selftest for details)\n", report.Summary())
+		}
+		fmt.Println("提示: 输入 'log console on' 可开启终端日志输出")
+		fmt.Println()
+	}
 
 	// 启动 CLI（在前台运行）
-	cliInterface := cli.NewCLI(s.GetMonitor(), serviceCfg.ConfigFile, cfg)
+	cliInterface := cli.NewCLI(agent.Monitor(), configFile, cfg)
+	cliInterface.SetJSONOutput(jsonOut)
 	cliInterface.Run()
 
 	// CLI 退出后停止服务
-	s.Stop()
+	agent.Stop()
+	return cliInterface.ExitCode()
+}
+
+// runConfigCheck 校验配置文件（含命令行覆盖）并输出报告，返回进程退出码
+func runConfigCheck(configFile, addr, logDir, lang string) int {
+	fmt.Printf("Checking config: %s\n", configFile)
+	if _, err := os.Stat(configFile); err != nil {
+		fmt.Printf("  ERROR    %v\n", err)
+		fmt.Println("Config check failed")
+		return 1
+	}
+
+	config.WarnOnLoad = false
+	cfg, err := config.LoadConfig(configFile)
+	if err != nil {
+		fmt.Printf("  ERROR    %v\n", err)
+		fmt.Println("Config check failed")
+		return 1
+	}
+	if addr != "" {
+		cfg.Server.Addr = addr
+	}
+	if logDir != "" {
+		cfg.Logging.Dir = logDir
+	}
+	if lang != "" {
+		cfg.Language = lang
+	}
+
+	issues := cfg.Validate()
+	errors := 0
+	for _, issue := range issues {
+		level := "WARNING"
+		if !issue.Warning {
+			level = "ERROR"
+			errors++
+		}
+		fmt.Printf("  %-8s %s\n", level, issue)
+	}
+	warnings := len(issues) - errors
+
+	if errors > 0 {
+		fmt.Printf("Config check failed: %d error(s), %d warning(s)\n", errors, warnings)
+		return 1
+	}
+	fmt.Printf("Config OK: %d target(s), %d warning(s)\n", len(cfg.Targets), warnings)
+	return 0
+}
+
+// runSelfTestReport 按配置的日志目录（含命令行覆盖）执行自检并输出报告，返回进程退出码
+func runSelfTestReport(configFile, logDir string) int {
+	config.WarnOnLoad = false
+	cfg, err := config.LoadConfig(configFile)
+	if err != nil {
+		fmt.Printf("  WARNING  %v, using default config\n", err)
+		cfg = config.DefaultConfig()
+	}
+	if logDir != "" {
+		cfg.Logging.Dir = logDir
+	}
+
+	report := selftest.Run(cfg.Logging.Dir)
+	fmt.Printf("Self-test on %s (elevated: %v)\n", report.Platform, report.Elevated)
+	report.Print(os.Stdout)
+	fmt.Printf("Self-test %s: %s\n", report.Status, report.Summary())
+	if report.Status == selftest.StatusFail {
+		return 1
+	}
+	return 0
 }
@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 
+	"monitor-agent/logger"
 	"monitor-agent/types"
 )
 
@@ -13,14 +15,35 @@ type Config struct {
 	Server   ServerConfig          `json:"server"`
 	Logging  LoggingConfig         `json:"logging"`
 	Targets  []types.MonitorTarget `json:"targets"`
+	Probes   []types.ProbeConfig   `json:"probes"` // 远程探测目标（TCP 端口存活探测）
 	Sampling SamplingConfig        `json:"sampling"`
-	Impact   types.ImpactConfig    `json:"impact"` // 影响分析配置
+	Impact   types.ImpactConfig    `json:"impact"`   // 影响分析配置
+	Display  DisplayConfig         `json:"display"`  // 命令行显示配置
+	NetMon   NetMonConfig          `json:"netmon"`   // 网络监控配置
+	Report   ReportConfig          `json:"report"`   // 值班运行报告配置
+	Watchdog WatchdogConfig        `json:"watchdog"` // 自动重启配置
+	Influx   InfluxConfig          `json:"influx"`   // 指标推送配置
+	Language string                `json:"language"` // 事件消息和影响描述的语言：zh（默认）或 en
 }
 
 // ServerConfig HTTP 服务配置
 type ServerConfig struct {
-	Addr    string `json:"addr"`
-	Enabled bool   `json:"enabled"` // 是否启用 Web 服务
+	Addr      string `json:"addr"`
+	Enabled   bool   `json:"enabled"`    // 是否启用 Web 服务
+	AutoStart bool   `json:"auto_start"` // 启动服务或添加目标时是否自动开始监控，false 时需手动启动
+	ReadOnly  bool   `json:"read_only"`  // 只读模式：Web API 禁止增删目标、启停监控和修改配置
+
+	Timeouts ServerTimeouts `json:"timeouts"` // 连接超时（重启生效）
+}
+
+// ServerTimeouts HTTP 连接超时（秒），0 表示不限制（keepalive 为 0 时使用系统默认间隔）。
+// SSE、长轮询和日志检索等流式接口不受整体读写超时限制，改为每次写入前按 write_seconds 延长写期限
+type ServerTimeouts struct {
+	ReadHeaderSeconds int `json:"read_header_seconds"` // 读取请求头的超时
+	ReadSeconds       int `json:"read_seconds"`        // 读取整个请求（含请求体）的超时
+	WriteSeconds      int `json:"write_seconds"`       // 写响应的超时
+	IdleSeconds       int `json:"idle_seconds"`        // keep-alive 连接空闲多久后关闭
+	KeepAliveSeconds  int `json:"keepalive_seconds"`   // TCP keepalive 探测间隔，用于发现半关闭的死连接
 }
 
 // LoggingConfig 日志配置
@@ -30,21 +53,194 @@ type LoggingConfig struct {
 	ConsoleOutput   bool   `json:"console_output"`
 	FileOutput      bool   `json:"file_output"`
 	EventsToConsole bool   `json:"events_to_console"` // 是否将事件输出到控制台
+	TimeZone        string `json:"time_zone"`         // 日志、报告和事件显示使用的时区（IANA 名称，如 Asia/Shanghai），空表示本地时区
+	TimeFormat      string `json:"time_format"`       // 完整时间的显示格式（Go 时间格式），空表示各处默认格式
+	MetricFormat    string `json:"metric_format"`     // METRIC 日志编码：json（默认）或 compact（紧凑编码，体积约为 JSON 的 1/4）
+
+	// 日志保留策略，服务每小时清理一次，从最旧的日志文件删起
+	MaxAgeDays int `json:"max_age_days"` // 日志文件保留天数，0 表示不按时间清理
+	MaxTotalMB int `json:"max_total_mb"` // 日志目录中日志文件总大小上限（MB），0 表示不限制
+
+	// 日志转发到 syslog（重启生效）
+	Syslog SyslogConfig `json:"syslog"`
+}
+
+// SyslogConfig 日志转发到 syslog 的配置
+// 开启后选定类别（默认 EVENT、IMPACT）和达到最低级别（默认 WARN）的日志在写入本地日志文件的同时转发到中心 syslog，
+// syslog 不可达时后台重连，不影响监控
+type SyslogConfig struct {
+	Enabled  bool   `json:"enabled"`
+	Network  string `json:"network"`  // udp、tcp（RFC 5424 格式），或 local（本机 syslog，仅 Linux/Unix）
+	Addr     string `json:"addr"`     // udp/tcp 的接收地址 host:port，如 192.168.1.10:514
+	Facility string `json:"facility"` // user、daemon、local0～local7，空表示 local0
+
+	MinLevel   string   `json:"min_level"`  // 其他类别的日志达到该级别才转发：debug、info、warn、error，none 只转发 categories
+	Categories []string `json:"categories"` // 不论级别总是转发的日志类别，不填为 EVENT、IMPACT
+}
+
+// Options 转换为日志转发选项
+func (s SyslogConfig) Options() logger.SyslogOptions {
+	return logger.SyslogOptions{
+		Network:    s.Network,
+		Addr:       s.Addr,
+		Facility:   s.Facility,
+		MinLevel:   s.MinLevel,
+		Categories: s.Categories,
+	}
 }
 
 // SamplingConfig 采样配置
 type SamplingConfig struct {
-	Interval         int `json:"interval"`          // 采样间隔（秒）
-	MetricsBufferLen int `json:"metrics_buffer_len"` // 指标缓冲区大小
-	EventsBufferLen  int `json:"events_buffer_len"`  // 事件缓冲区大小
+	Interval         int    `json:"interval"`           // 采样间隔（秒）
+	MetricsBufferLen int    `json:"metrics_buffer_len"` // 指标缓冲区大小
+	EventsBufferLen  int    `json:"events_buffer_len"`  // 事件缓冲区大小
+	EventDedupWindow int    `json:"event_dedup_window"` // 相同事件去重窗口（秒），0 表示不去重
+	EventRateLimit   int    `json:"event_rate_limit"`   // 每分钟最多记录事件数，超出后抑制，0 表示不限制
+	StripExeSuffix   bool   `json:"strip_exe_suffix"`   // 去掉进程名的 .exe 后缀，使 Windows 与 Linux 名称一致
+	CPUStyle         string `json:"cpu_style"`          // 进程 CPU 口径：solaris（整机，最大100%）或 irix（单核100%，可超过100%）
+	CollectWorkers   int    `json:"collect_workers"`    // 并发采集进程信息的协程数，0 或 1 表示顺序采集（重启生效）
+
+	// 采样时刻的随机偏移，避免全厂多台主机在同一时刻采样、同时写共享存储和推送数据（重启生效）
+	SampleJitterMS       int  `json:"sample_jitter_ms"`        // 偏移上限（毫秒），0 表示不偏移，超过采样间隔时按采样间隔
+	SampleJitterEachTick bool `json:"sample_jitter_each_tick"` // 每轮采样都随机偏移，false 时只偏移首次采样（之后按固定间隔）
+
+	BinaryCheckInterval int  `json:"binary_check_interval"` // 目标可执行文件完整性校验间隔（秒）
+	BinaryHash          bool `json:"binary_hash"`           // 校验时计算 SHA256，大文件耗时较多
+
+	// 启动快照的环境变量过滤，变量名通配符不区分大小写
+	SnapshotEnvAllow []string `json:"snapshot_env_allow"` // 只记录这些变量，为空表示全部
+	SnapshotEnvDeny  []string `json:"snapshot_env_deny"`  // 记录变量名但隐藏值（如密码、令牌），优先于 allow
+}
+
+// DisplayConfig 显示配置（仅影响高亮颜色和显示名称，不影响检测）
+type DisplayConfig struct {
+	TopHighlightWarn  float64 `json:"top_highlight_warn"`   // system top 中 CPU% 超过该值显示黄色
+	TopHighlightCrit  float64 `json:"top_highlight_crit"`   // system top 中 CPU% 超过该值显示红色
+	TopHighlightMemMB float64 `json:"top_highlight_mem_mb"` // system top 中内存超过该值（MB）高亮，0 表示不高亮
+
+	// NameAliases 进程名到显示名称的映射（如 "w3wp.exe": "IIS 应用程序池"），用于列表和风险描述，
+	// 按名称匹配目标时仍使用原进程名
+	NameAliases map[string]string `json:"name_aliases"`
+
+	// PinnedProcesses 常驻进程名：system top 中总是显示这些进程（名称前加 ★），即使 CPU 排名在 Top N 之外。
+	// 不区分大小写，可省略 .exe 后缀；只影响显示，不会成为保障对象
+	PinnedProcesses []string `json:"pinned_processes"`
+
+	// ByteUnits 字节数的单位口径："binary"（1024 进制，KiB/MiB）或 "decimal"（1000 进制，KB/MB），
+	// 为空时保持旧版显示（1024 进制，标注 KB/MB）。影响 CLI、报告和风险描述，阈值中的 MB 仍按 1024 换算
+	ByteUnits string `json:"byte_units"`
+
+	// TableColumns system top 和 target list 表格显示的列（可用列名见 TableColumnNames，不区分大小写），
+	// 为空时显示全部列。列的顺序固定，PID 和名称总是显示，表格中没有的列忽略
+	TableColumns []string `json:"table_columns,omitempty"`
+
+	// Precision system top 和 target list 中 CPU% 的小数位数（0～MaxPrecision），不填时为 1 位
+	Precision *int `json:"precision,omitempty"`
+}
+
+// TableColumnNames display.table_columns 可用的列名
+var TableColumnNames = []string{
+	"pid", "name", "alias", "status", "cpu", "mem", "mem_growth",
+	"disk_read", "disk_write", "net_recv", "net_send", "threads", "user", "ports", "maint",
+}
+
+// MaxPrecision display.precision 的最大值
+const MaxPrecision = 3
+
+// ShowColumn 表格是否显示某列：未配置 table_columns 时显示全部列，pid 和 name 总是显示
+func (d *DisplayConfig) ShowColumn(name string) bool {
+	if len(d.TableColumns) == 0 || name == "pid" || name == "name" {
+		return true
+	}
+	for _, c := range d.TableColumns {
+		if strings.EqualFold(c, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// CPUPrecision 表格中 CPU% 的小数位数，未配置或超出范围时为 1
+func (d *DisplayConfig) CPUPrecision() int {
+	if d.Precision == nil || *d.Precision < 0 || *d.Precision > MaxPrecision {
+		return 1
+	}
+	return *d.Precision
+}
+
+// IsTableColumn 是否为可用的列名（不区分大小写）
+func IsTableColumn(name string) bool {
+	for _, c := range TableColumnNames {
+		if strings.EqualFold(c, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// NetMonConfig 网络监控配置（重启生效）
+// 网卡名支持通配符，如 "eth*"；均为空时统计所有网卡（包括回环）
+type NetMonConfig struct {
+	Interfaces        []string `json:"interfaces"`         // 只统计这些网卡
+	ExcludeInterfaces []string `json:"exclude_interfaces"` // 排除这些网卡，优先于 interfaces
+}
+
+// WatchdogConfig 自动重启配置（重启生效）
+// 开启后，目标按 restart_policy 退出时由代理执行其 restart_command；命令只能在配置文件或命令行中设置，Web API 不能修改
+type WatchdogConfig struct {
+	AllowRestart bool `json:"allow_restart"` // 总开关，默认关闭：关闭时所有目标的重启策略都不生效
+}
+
+// InfluxConfig 指标推送配置（重启生效）
+// 开启后按 InfluxDB 行协议定期推送系统和各保障对象的指标，推送失败不影响监控，失败的批次稍后重试
+type InfluxConfig struct {
+	Enabled     bool              `json:"enabled"`
+	URL         string            `json:"url"`         // udp://host:8089，或 HTTP 写入地址如 http://host:8086/write?db=plant
+	Token       string            `json:"token"`       // HTTP 推送的认证令牌（Authorization: Token），可为空
+	Interval    int               `json:"interval"`    // 推送间隔（秒），0 表示 10 秒
+	Measurement string            `json:"measurement"` // measurement 名称，空表示 plant_monitor
+	Tags        map[string]string `json:"tags"`        // 附加到每个数据点的静态标签，如 {"site": "一号机组"}
+	Backlog     int               `json:"backlog"`     // 推送失败时最多保留的批次数，0 表示 30
+}
+
+// ReportConfig 值班运行报告配置
+type ReportConfig struct {
+	Shifts []ShiftConfig `json:"shifts"` // 值次划分，为空时使用 DefaultShifts
+}
+
+// ShiftConfig 值次，从 StartHour 整点开始，到下一个值次开始时结束
+type ShiftConfig struct {
+	Name      string `json:"name"`
+	StartHour int    `json:"start_hour"` // 0-23
+}
+
+// DefaultShifts 默认两班制：白班 08:00、夜班 20:00
+func DefaultShifts() []ShiftConfig {
+	return []ShiftConfig{
+		{Name: "白班", StartHour: 8},
+		{Name: "夜班", StartHour: 20},
+	}
+}
+
+// DefaultSnapshotEnvDeny 默认隐藏值的环境变量，避免在快照和日志中保存密钥
+func DefaultSnapshotEnvDeny() []string {
+	return []string{"*PASSWORD*", "*PASSWD*", "*SECRET*", "*TOKEN*", "*KEY*", "*CREDENTIAL*", "*AUTH*"}
 }
 
 // DefaultConfig 返回默认配置
 func DefaultConfig() *Config {
 	return &Config{
 		Server: ServerConfig{
-			Addr:    ":8080",
-			Enabled: true,
+			Addr:      ":8080",
+			Enabled:   true,
+			AutoStart: true,
+			Timeouts: ServerTimeouts{
+				ReadHeaderSeconds: 10,
+				ReadSeconds:       30,
+				WriteSeconds:      60,
+				IdleSeconds:       120,
+				KeepAliveSeconds:  30,
+			},
 		},
 		Logging: LoggingConfig{
 			Dir:             "./logs",
@@ -52,39 +248,85 @@ func DefaultConfig() *Config {
 			ConsoleOutput:   true,
 			FileOutput:      true,
 			EventsToConsole: true,
+			MaxAgeDays:      30,
+			MaxTotalMB:      1024,
+			Syslog:          SyslogConfig{Network: "udp", Facility: "local0", MinLevel: "warn"},
 		},
 		Targets: []types.MonitorTarget{},
+		Probes:  []types.ProbeConfig{},
 		Sampling: SamplingConfig{
 			Interval:         1,
 			MetricsBufferLen: 300,
 			EventsBufferLen:  100,
+			EventDedupWindow: 60,
+			EventRateLimit:   120,
+			CPUStyle:         "solaris",
+			CollectWorkers:   1,
+
+			BinaryCheckInterval: 600,
+			SnapshotEnvAllow:    []string{},
+			SnapshotEnvDeny:     DefaultSnapshotEnvDeny(),
 		},
 		Impact: types.ImpactConfig{
 			Enabled:          true,
 			AnalysisInterval: 5,
 			TopNProcesses:    10,
 			HistoryLen:       100,
+			AnalysisWorkers:  1,
+			SkipWarnCycles:   3,
 			// 系统级别阈值
 			CPUThreshold:     80,
 			MemoryThreshold:  85,
 			DiskIOThreshold:  100,
 			NetworkThreshold: 100,
+			CPUCoreThreshold: 90,
+			ChurnThreshold:   120,
+			StealThreshold:   10,
+			// 虚拟化资源争用：目标可运行等待 CPU 的时间占比阈值
+			VirtRunWaitThreshold: 20,
 			// 进程级别阈值
 			ProcCPUThreshold:       50,
 			ProcMemoryThreshold:    1000,
 			ProcMemGrowthThreshold: 10,
 			ProcVMSThreshold:       0,
 			ProcFDsThreshold:       1000,
+			ProcFDGrowthThreshold:  100,
 			ProcThreadsThreshold:   500,
 			ProcOpenFilesThreshold: 500,
 			ProcDiskReadThreshold:  50,
 			ProcDiskWriteThreshold: 50,
 			ProcNetRecvThreshold:   50,
 			ProcNetSendThreshold:   50,
+			ZombieThreshold:        5,
+			// 趋势预测
+			TrendWindowSeconds:  600,
+			TrendHorizonMinutes: 30,
+			TrendLimitPercent:   95,
+			// 目标目录占用
+			DirGrowthThreshold: 1024,
+			DirMinFreeMB:       1024,
 			// 资源冲突检测间隔
 			FileCheckInterval: 30,
 			PortCheckInterval: 30,
+			// 排除本程序自身
+			ExcludeSelf: true,
+			// 健康评分权重
+			ScoreWeightCritical: 40,
+			ScoreWeightHigh:     15,
+			ScoreWeightMedium:   5,
+			ScoreWeightLow:      1,
+		},
+		Display: DisplayConfig{
+			TopHighlightWarn:  20,
+			TopHighlightCrit:  50,
+			TopHighlightMemMB: 1000,
+			NameAliases:       map[string]string{},
+			PinnedProcesses:   []string{},
 		},
+		Report: ReportConfig{
+			Shifts: DefaultShifts(),
+		},
+		Language: "zh",
 	}
 }
 
@@ -104,6 +346,12 @@ func LoadConfig(path string) (*Config, error) {
 	if err := json.Unmarshal(data, cfg); err != nil {
 		return nil, fmt.Errorf("parse config file: %w", err)
 	}
+	recordFileHash(path, data)
+
+	// 有问题的值只给出警告，是否拒绝启动由调用方决定（如 -check-config）
+	if WarnOnLoad {
+		warnIssues(path, cfg.Validate())
+	}
 
 	return cfg, nil
 }
@@ -118,6 +366,7 @@ func SaveConfig(path string, cfg *Config) error {
 	if err := os.WriteFile(path, data, 0644); err != nil {
 		return fmt.Errorf("write config file: %w", err)
 	}
+	recordFileHash(path, data)
 
 	return nil
 }
@@ -0,0 +1,178 @@
+package config
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 配置漂移：impact set、Web 页面等修改配置后会立即保存，但值班人员有时也会在服务运行时手工编辑
+// config.json，运行中的配置与文件从此不一致而无人察觉（直到重启后行为突变）。
+// LoadConfig/SaveConfig 记录读写内容的哈希，CheckDrift 重新计算文件的哈希并与之比较，
+// 不一致时按字段列出运行中的配置与文件的差异。只是格式不同（字段值都相同）时以新内容为准，不算漂移。
+
+// maxDriftFields 差异最多列出的字段数
+const maxDriftFields = 50
+
+// driftRedacted 差异中不显示值的字段（可能含凭据）
+var driftRedacted = map[string]bool{
+	"influx.url":   true,
+	"influx.token": true,
+}
+
+// FieldDiff 运行中的配置与文件中某个字段的差异，值为 JSON，字段在一方不存在时为空
+type FieldDiff struct {
+	Field  string          `json:"field"` // 点分隔的字段路径，如 impact.cpu_threshold
+	Active json.RawMessage `json:"active,omitempty"`
+	File   json.RawMessage `json:"file,omitempty"`
+}
+
+// Drift 配置漂移检查结果
+type Drift struct {
+	Drift     bool        `json:"config_drift"`
+	File      string      `json:"file"`
+	CheckedAt time.Time   `json:"checked_at"`
+	Diff      []FieldDiff `json:"diff,omitempty"`
+	Truncated bool        `json:"truncated,omitempty"` // 差异超过 maxDriftFields，只列出了一部分
+	Error     string      `json:"error,omitempty"`     // 文件被删除或无法解析
+}
+
+var (
+	fileHashMu sync.Mutex
+	fileHashes = make(map[string][sha256.Size]byte) // 配置文件（绝对路径）-> 最近一次读取或保存的内容哈希
+)
+
+func hashKey(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// recordFileHash 记录本进程最近一次读取或保存的配置文件内容
+func recordFileHash(path string, data []byte) {
+	fileHashMu.Lock()
+	defer fileHashMu.Unlock()
+	fileHashes[hashKey(path)] = sha256.Sum256(data)
+}
+
+// knownFileHash 返回最近一次读取或保存的内容哈希
+func knownFileHash(path string) ([sha256.Size]byte, bool) {
+	fileHashMu.Lock()
+	defer fileHashMu.Unlock()
+	h, ok := fileHashes[hashKey(path)]
+	return h, ok
+}
+
+// CheckDrift 检查配置文件是否在本进程读取或保存之后被修改，修改后与运行中的配置 active 有差异时报告漂移。
+// path 为空（不保存配置）时不检查；启动时文件不存在、之后被创建同样视为修改
+func CheckDrift(path string, active *Config) Drift {
+	d := Drift{File: path, CheckedAt: time.Now()}
+	if path == "" {
+		return d
+	}
+	known, loaded := knownFileHash(path)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) && !loaded {
+			return d
+		}
+		d.Drift = true
+		d.Error = err.Error()
+		return d
+	}
+	if loaded && sha256.Sum256(data) == known {
+		return d
+	}
+
+	fileCfg := DefaultConfig()
+	if err := json.Unmarshal(data, fileCfg); err != nil {
+		d.Drift = true
+		d.Error = fmt.Sprintf("parse config file: %v", err)
+		return d
+	}
+	d.Diff, d.Truncated = diffConfigs(active, fileCfg)
+	if len(d.Diff) == 0 {
+		recordFileHash(path, data) // 只是格式变化，以新内容为准
+		return d
+	}
+	d.Drift = true
+	return d
+}
+
+// diffConfigs 按字段比较两份配置：对象逐级展开为点分隔的路径，数组和其他值整体比较
+func diffConfigs(active, file *Config) ([]FieldDiff, bool) {
+	a, b := flattenConfig(active), flattenConfig(file)
+	var fields []string
+	for k, v := range a {
+		if !bytes.Equal(v, b[k]) {
+			fields = append(fields, k)
+		}
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			fields = append(fields, k)
+		}
+	}
+	sort.Strings(fields)
+
+	truncated := len(fields) > maxDriftFields
+	if truncated {
+		fields = fields[:maxDriftFields]
+	}
+	diffs := make([]FieldDiff, 0, len(fields))
+	for _, k := range fields {
+		diff := FieldDiff{Field: k, Active: a[k], File: b[k]}
+		if driftRedacted[k] {
+			diff.Active, diff.File = redactedJSON(diff.Active), redactedJSON(diff.File)
+		}
+		diffs = append(diffs, diff)
+	}
+	return diffs, truncated
+}
+
+// redactedJSON 隐藏差异中的值，字段不存在时保持为空
+func redactedJSON(v json.RawMessage) json.RawMessage {
+	if v == nil {
+		return nil
+	}
+	return json.RawMessage(`"[redacted]"`)
+}
+
+// flattenConfig 把配置展开为 字段路径 -> JSON 值
+func flattenConfig(cfg *Config) map[string]json.RawMessage {
+	out := make(map[string]json.RawMessage)
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return out
+	}
+	flattenJSON("", data, out)
+	return out
+}
+
+// flattenJSON 展开 JSON 对象，null 和空数组视为不存在（未设置的切片和映射在两边的编码可能不同）
+func flattenJSON(prefix string, data json.RawMessage, out map[string]json.RawMessage) {
+	if string(data) == "null" || string(data) == "[]" {
+		return
+	}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(data, &obj); err != nil {
+		out[prefix] = data
+		return
+	}
+	for k, v := range obj {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		flattenJSON(key, v, out)
+	}
+}
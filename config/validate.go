@@ -0,0 +1,487 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"monitor-agent/format"
+	"monitor-agent/i18n"
+	"monitor-agent/impact"
+	"monitor-agent/influx"
+	"monitor-agent/logger"
+	"monitor-agent/monitor"
+	"monitor-agent/provider"
+	"monitor-agent/types"
+)
+
+// WarnOnLoad LoadConfig 加载后是否校验配置并通过标准 log 输出发现的问题（不导致加载失败）
+var WarnOnLoad = true
+
+// Issue 配置校验发现的问题
+type Issue struct {
+	Field   string `json:"field"`             // 配置项，如 sampling.interval
+	Message string `json:"message"`           // 问题描述
+	Warning bool   `json:"warning,omitempty"` // 可疑但不影响启动的值
+}
+
+func (i Issue) String() string {
+	return i.Field + ": " + i.Message
+}
+
+// HasErrors 是否存在错误（警告不计）
+func HasErrors(issues []Issue) bool {
+	for _, i := range issues {
+		if !i.Warning {
+			return true
+		}
+	}
+	return false
+}
+
+// validator 收集校验问题
+type validator struct {
+	issues []Issue
+}
+
+func (v *validator) errorf(field, format string, args ...interface{}) {
+	v.issues = append(v.issues, Issue{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) warnf(field, format string, args ...interface{}) {
+	v.issues = append(v.issues, Issue{Field: field, Message: fmt.Sprintf(format, args...), Warning: true})
+}
+
+// Validate 校验配置，返回发现的错误和警告（无问题时为空）
+// 错误表示启动后会运行异常的值，警告表示可疑但可以运行的值
+func (c *Config) Validate() []Issue {
+	v := &validator{}
+	c.validateServer(v)
+	c.validateLogging(v)
+	c.validateSampling(v)
+	c.validateImpact(v)
+	c.validateDisplay(v)
+	c.validateTargets(v)
+	c.validateProbes(v)
+	c.validateReport(v)
+	c.validateInflux(v)
+
+	for _, p := range append(append([]string{}, c.NetMon.Interfaces...), c.NetMon.ExcludeInterfaces...) {
+		if _, err := path.Match(p, ""); err != nil {
+			v.errorf("netmon", "invalid interface pattern %q", p)
+		}
+	}
+	if _, err := i18n.Normalize(c.Language); err != nil {
+		v.errorf("language", "%v", err)
+	}
+	return v.issues
+}
+
+func (c *Config) validateServer(v *validator) {
+	if !c.Server.Enabled {
+		return
+	}
+	host, port, err := net.SplitHostPort(c.Server.Addr)
+	if err != nil {
+		v.errorf("server.addr", "invalid address %q, expected host:port or :port", c.Server.Addr)
+		return
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 0 || n > 65535 {
+		v.errorf("server.addr", "invalid port %q", port)
+	}
+	if host != "" && host != "localhost" && net.ParseIP(host) == nil {
+		v.warnf("server.addr", "host %q is not an IP address, it must resolve on this machine", host)
+	}
+
+	t := c.Server.Timeouts
+	timeouts := []struct {
+		field string
+		value int
+	}{
+		{"server.timeouts.read_header_seconds", t.ReadHeaderSeconds},
+		{"server.timeouts.read_seconds", t.ReadSeconds},
+		{"server.timeouts.write_seconds", t.WriteSeconds},
+		{"server.timeouts.idle_seconds", t.IdleSeconds},
+		{"server.timeouts.keepalive_seconds", t.KeepAliveSeconds},
+	}
+	for _, item := range timeouts {
+		if item.value < 0 {
+			v.errorf(item.field, "must not be negative")
+		}
+	}
+	if t.ReadHeaderSeconds > 0 && t.ReadSeconds > 0 && t.ReadHeaderSeconds > t.ReadSeconds {
+		v.warnf("server.timeouts.read_header_seconds", "larger than read_seconds (%d), read_seconds applies first", t.ReadSeconds)
+	}
+}
+
+func (c *Config) validateLogging(v *validator) {
+	l := c.Logging
+	switch strings.ToLower(l.Level) {
+	case "", "debug", "info", "warn", "error":
+	default:
+		v.errorf("logging.level", "unknown level %q", l.Level)
+	}
+	if l.TimeZone != "" {
+		if _, err := time.LoadLocation(l.TimeZone); err != nil {
+			v.errorf("logging.time_zone", "%v", err)
+		}
+	}
+	if _, err := logger.NormalizeMetricFormat(l.MetricFormat); err != nil {
+		v.errorf("logging.metric_format", "%v", err)
+	}
+	if l.MaxAgeDays < 0 {
+		v.errorf("logging.max_age_days", "must not be negative")
+	}
+	if l.MaxTotalMB < 0 {
+		v.errorf("logging.max_total_mb", "must not be negative")
+	}
+	if s := l.Syslog; s.Enabled {
+		if err := logger.ValidateSyslog(s.Options()); err != nil {
+			v.errorf("logging.syslog", "%v", err)
+		}
+	}
+
+	if l.Dir == "" {
+		return
+	}
+	fi, err := os.Stat(l.Dir)
+	switch {
+	case os.IsNotExist(err):
+		v.warnf("logging.dir", "%s does not exist, it will be created on start", l.Dir)
+	case err != nil:
+		v.errorf("logging.dir", "%v", err)
+	case !fi.IsDir():
+		v.errorf("logging.dir", "%s is not a directory", l.Dir)
+	default:
+		f, err := os.CreateTemp(l.Dir, ".check-*")
+		if err != nil {
+			v.errorf("logging.dir", "%s is not writable: %v", l.Dir, err)
+			return
+		}
+		f.Close()
+		os.Remove(f.Name())
+	}
+}
+
+func (c *Config) validateSampling(v *validator) {
+	s := c.Sampling
+	if s.Interval < 1 {
+		v.errorf("sampling.interval", "must be at least 1")
+	} else if s.Interval > 60 {
+		v.warnf("sampling.interval", "%d seconds is long, short process hangs may be missed", s.Interval)
+	}
+	if s.MetricsBufferLen <= 0 {
+		v.errorf("sampling.metrics_buffer_len", "must be positive")
+	}
+	if s.EventsBufferLen <= 0 {
+		v.errorf("sampling.events_buffer_len", "must be positive")
+	}
+	if s.EventDedupWindow < 0 {
+		v.errorf("sampling.event_dedup_window", "must not be negative")
+	}
+	if s.EventRateLimit < 0 {
+		v.errorf("sampling.event_rate_limit", "must not be negative")
+	}
+	if s.CPUStyle != "" && s.CPUStyle != provider.CPUStyleIrix && s.CPUStyle != provider.CPUStyleSolaris {
+		v.errorf("sampling.cpu_style", "unknown style %q (irix or solaris)", s.CPUStyle)
+	}
+	if s.CollectWorkers < 0 || s.CollectWorkers > provider.MaxCollectWorkers {
+		v.errorf("sampling.collect_workers", "must be between 0 and %d, got %d", provider.MaxCollectWorkers, s.CollectWorkers)
+	}
+	if s.SampleJitterMS < 0 {
+		v.errorf("sampling.sample_jitter_ms", "must not be negative")
+	} else if s.Interval >= 1 && s.SampleJitterMS > s.Interval*1000 {
+		v.warnf("sampling.sample_jitter_ms", "%dms exceeds the sample interval, capped to %ds", s.SampleJitterMS, s.Interval)
+	}
+	if s.BinaryCheckInterval < 0 {
+		v.errorf("sampling.binary_check_interval", "must not be negative")
+	}
+	for _, p := range append(append([]string{}, s.SnapshotEnvAllow...), s.SnapshotEnvDeny...) {
+		if _, err := path.Match(p, ""); err != nil {
+			v.errorf("sampling.snapshot_env", "invalid pattern %q", p)
+		}
+	}
+}
+
+func (c *Config) validateImpact(v *validator) {
+	imp := c.Impact
+	percents := []struct {
+		field string
+		value float64
+	}{
+		{"impact.cpu_threshold", imp.CPUThreshold},
+		{"impact.memory_threshold", imp.MemoryThreshold},
+	}
+	for _, p := range percents {
+		if p.value <= 0 || p.value > 100 {
+			v.errorf(p.field, "must be between 0 and 100, got %g", p.value)
+		}
+	}
+	if imp.CPUCoreThreshold < 0 || imp.CPUCoreThreshold > 100 {
+		v.errorf("impact.cpu_core_threshold", "must be between 0 and 100, got %g", imp.CPUCoreThreshold)
+	}
+	if imp.StealThreshold < 0 || imp.StealThreshold > 100 {
+		v.errorf("impact.steal_threshold", "must be between 0 and 100, got %g", imp.StealThreshold)
+	}
+	if imp.DiskIOThreshold <= 0 {
+		v.errorf("impact.disk_io_threshold", "must be positive")
+	}
+	if imp.NetworkThreshold <= 0 {
+		v.errorf("impact.network_threshold", "must be positive")
+	}
+
+	if imp.AnalysisInterval <= 0 {
+		v.errorf("impact.analysis_interval", "must be positive")
+	} else if c.Sampling.Interval > 0 && imp.AnalysisInterval < c.Sampling.Interval {
+		v.warnf("impact.analysis_interval", "%d is shorter than sampling.interval %d", imp.AnalysisInterval, c.Sampling.Interval)
+	}
+	if imp.AnalysisWorkers < 0 || imp.AnalysisWorkers > impact.MaxAnalysisWorkers {
+		v.errorf("impact.analysis_workers", "must be between 0 and %d, got %d", impact.MaxAnalysisWorkers, imp.AnalysisWorkers)
+	}
+	if imp.SkipWarnCycles < 0 {
+		v.errorf("impact.skip_warn_cycles", "must not be negative")
+	}
+	if imp.TopNProcesses <= 0 {
+		v.errorf("impact.top_n_processes", "must be positive")
+	}
+	if imp.HistoryLen <= 0 {
+		v.errorf("impact.history_len", "must be positive")
+	}
+
+	procs := []struct {
+		field string
+		value float64
+	}{
+		{"impact.proc_cpu_threshold", imp.ProcCPUThreshold},
+		{"impact.proc_memory_threshold", imp.ProcMemoryThreshold},
+		{"impact.proc_mem_growth_threshold", imp.ProcMemGrowthThreshold},
+		{"impact.proc_vms_threshold", imp.ProcVMSThreshold},
+		{"impact.proc_fds_threshold", float64(imp.ProcFDsThreshold)},
+		{"impact.proc_fd_growth_threshold", imp.ProcFDGrowthThreshold},
+		{"impact.proc_threads_threshold", float64(imp.ProcThreadsThreshold)},
+		{"impact.proc_open_files_threshold", float64(imp.ProcOpenFilesThreshold)},
+		{"impact.proc_disk_read_threshold", imp.ProcDiskReadThreshold},
+		{"impact.proc_disk_write_threshold", imp.ProcDiskWriteThreshold},
+		{"impact.proc_net_recv_threshold", imp.ProcNetRecvThreshold},
+		{"impact.proc_net_send_threshold", imp.ProcNetSendThreshold},
+		{"impact.churn_threshold", float64(imp.ChurnThreshold)},
+		{"impact.virt_run_wait_threshold", imp.VirtRunWaitThreshold},
+		{"impact.zombie_threshold", float64(imp.ZombieThreshold)},
+		{"impact.dir_growth_threshold", imp.DirGrowthThreshold},
+		{"impact.dir_min_free_mb", imp.DirMinFreeMB},
+	}
+	for _, p := range procs {
+		if p.value < 0 {
+			v.errorf(p.field, "must not be negative")
+		}
+	}
+
+	if imp.MinDurationSeconds < 0 {
+		v.errorf("impact.min_duration_seconds", "must not be negative")
+	}
+	if imp.ClearDurationSeconds < 0 {
+		v.errorf("impact.clear_duration_seconds", "must not be negative")
+	}
+	if imp.LogCooldownSeconds < 0 {
+		v.errorf("impact.log_cooldown_seconds", "must not be negative")
+	}
+	if imp.TrendWindowSeconds < 0 {
+		v.errorf("impact.trend_window_seconds", "must not be negative")
+	}
+	if imp.TrendHorizonMinutes < 0 {
+		v.errorf("impact.trend_horizon_minutes", "must not be negative")
+	}
+	if imp.TrendLimitPercent < 0 || imp.TrendLimitPercent > 100 {
+		v.errorf("impact.trend_limit_percent", "must be between 0 and 100")
+	}
+	for i, p := range imp.TrendDiskPaths {
+		if strings.TrimSpace(p) == "" {
+			v.errorf(fmt.Sprintf("impact.trend_disk_paths[%d]", i), "must not be empty")
+		}
+	}
+	for t, secs := range imp.MinDurationOverrides {
+		if secs < 0 {
+			v.errorf("impact.min_duration_overrides."+t, "must not be negative")
+		}
+	}
+	if err := impact.ValidateMaintenanceWindows(imp.MaintenanceWindows); err != nil {
+		v.errorf("impact.maintenance_windows", "%v", err)
+	}
+
+	// 健康评分权重应随严重级别递减
+	weights := []float64{imp.ScoreWeightCritical, imp.ScoreWeightHigh, imp.ScoreWeightMedium, imp.ScoreWeightLow}
+	for _, w := range weights {
+		if w <= 0 {
+			v.errorf("impact.score_weight_*", "score weights must be positive")
+			return
+		}
+	}
+	if !(weights[0] >= weights[1] && weights[1] >= weights[2] && weights[2] >= weights[3]) {
+		v.warnf("impact.score_weight_*", "weights should satisfy critical >= high >= medium >= low, got %g/%g/%g/%g",
+			weights[0], weights[1], weights[2], weights[3])
+	}
+}
+
+func (c *Config) validateDisplay(v *validator) {
+	d := c.Display
+	if d.TopHighlightWarn < 0 || d.TopHighlightCrit < 0 || d.TopHighlightMemMB < 0 {
+		v.errorf("display", "highlight thresholds must not be negative")
+	}
+	if d.TopHighlightWarn > d.TopHighlightCrit {
+		v.errorf("display.top_highlight_warn", "%g must not exceed top_highlight_crit %g", d.TopHighlightWarn, d.TopHighlightCrit)
+	}
+	for name, alias := range d.NameAliases {
+		if name == "" || strings.TrimSpace(alias) == "" {
+			v.errorf("display.name_aliases", "process name and display name must not be empty (%q: %q)", name, alias)
+		}
+	}
+	for i, name := range d.PinnedProcesses {
+		if strings.TrimSpace(name) == "" {
+			v.errorf(fmt.Sprintf("display.pinned_processes[%d]", i), "process name must not be empty")
+		}
+	}
+	switch d.ByteUnits {
+	case format.UnitsDefault, format.UnitsBinary, format.UnitsDecimal:
+	default:
+		v.errorf("display.byte_units", "must be binary or decimal, got %q", d.ByteUnits)
+	}
+	for i, name := range d.TableColumns {
+		if !IsTableColumn(name) {
+			v.warnf(fmt.Sprintf("display.table_columns[%d]", i), "unknown column %q ignored, expected one of %s",
+				name, strings.Join(TableColumnNames, ", "))
+		}
+	}
+	if d.Precision != nil && (*d.Precision < 0 || *d.Precision > MaxPrecision) {
+		v.errorf("display.precision", "must be between 0 and %d, got %d", MaxPrecision, *d.Precision)
+	}
+}
+
+// validateTargets 名称必填、端口有效；同名目标需用不同别名区分
+func (c *Config) validateTargets(v *validator) {
+	seen := make(map[string]int)
+	for i, t := range c.Targets {
+		field := fmt.Sprintf("targets[%d]", i)
+		if t.Name == "" {
+			v.errorf(field, "name is required")
+			continue
+		}
+		key := t.Name + "\x00" + t.Alias
+		if j, dup := seen[key]; dup {
+			v.errorf(field, "duplicate of targets[%d] (%s); give each instance a distinct alias", j, t.Name)
+		} else {
+			seen[key] = i
+		}
+		for _, port := range t.WatchPorts {
+			if port < 1 || port > 65535 {
+				v.errorf(field, "%s: invalid watch port %d", t.Name, port)
+			}
+		}
+		for _, dir := range t.WatchDirs {
+			if strings.TrimSpace(dir) == "" {
+				v.errorf(field, "%s: watch dir must not be empty", t.Name)
+			} else if fi, err := os.Stat(dir); err != nil || !fi.IsDir() {
+				v.warnf(field, "%s: watch dir %s is not an existing directory", t.Name, dir)
+			}
+		}
+		if err := monitor.ValidateTargetInterval(t); err != nil {
+			v.errorf(field, "%s: %v", t.Name, err)
+		}
+		// 校验副本，不修改配置本身
+		restart := t
+		if t.RestartPolicy != nil {
+			policy := *t.RestartPolicy
+			restart.RestartPolicy = &policy
+		}
+		if err := monitor.NormalizeTargetRestart(&restart); err != nil {
+			v.errorf(field, "%s: %v", t.Name, err)
+		} else if p := restart.RestartPolicy; p != nil && p.Mode != types.RestartNever && !c.Watchdog.AllowRestart {
+			v.warnf(field, "%s: restart_policy %s has no effect while watchdog.allow_restart is false", t.Name, p.Mode)
+		}
+	}
+}
+
+// validateProbes 名称必填且不重复，地址和端口有效，超时不超过探测间隔（0 表示使用默认值）
+func (c *Config) validateProbes(v *validator) {
+	seen := make(map[string]int)
+	for i, p := range c.Probes {
+		field := fmt.Sprintf("probes[%d]", i)
+		if p.Name == "" {
+			v.errorf(field, "name is required")
+			continue
+		}
+		if j, dup := seen[p.Name]; dup {
+			v.errorf(field, "duplicate of probes[%d] (%s)", j, p.Name)
+		} else {
+			seen[p.Name] = i
+		}
+		if p.Host == "" {
+			v.errorf(field, "%s: host is required", p.Name)
+		}
+		if p.Port < 1 || p.Port > 65535 {
+			v.errorf(field, "%s: invalid port %d", p.Name, p.Port)
+		}
+		if p.Interval < 0 || p.TimeoutMs < 0 || p.FailThreshold < 0 {
+			v.errorf(field, "%s: interval, timeout_ms and fail_threshold must not be negative", p.Name)
+		} else if p.Interval > 0 && p.TimeoutMs > p.Interval*1000 {
+			v.errorf(field, "%s: timeout_ms must not exceed the interval", p.Name)
+		}
+	}
+}
+
+// validateReport 值次名称必填，开始时间为 0-23 点且不重复
+func (c *Config) validateReport(v *validator) {
+	hours := make(map[int]string)
+	for i, s := range c.Report.Shifts {
+		field := fmt.Sprintf("report.shifts[%d]", i)
+		if s.Name == "" {
+			v.errorf(field, "name is required")
+		}
+		if s.StartHour < 0 || s.StartHour > 23 {
+			v.errorf(field, "start_hour must be between 0 and 23, got %d", s.StartHour)
+			continue
+		}
+		if other, dup := hours[s.StartHour]; dup {
+			v.errorf(field, "%s starts at the same hour as %s", s.Name, other)
+		}
+		hours[s.StartHour] = s.Name
+	}
+}
+
+// validateInflux 开启指标推送时校验推送地址，推送间隔短于采样间隔时每次推送的数据相同
+func (c *Config) validateInflux(v *validator) {
+	in := c.Influx
+	if !in.Enabled {
+		return
+	}
+	if _, err := influx.ParseURL(in.URL); err != nil {
+		v.errorf("influx.url", "%v", err)
+	}
+	if in.Interval < 0 || in.Backlog < 0 {
+		v.errorf("influx", "interval and backlog must not be negative")
+	} else if in.Interval > 0 && in.Interval < c.Sampling.Interval {
+		v.warnf("influx.interval", "%ds is shorter than the sampling interval (%ds), points are pushed once per sample",
+			in.Interval, c.Sampling.Interval)
+	}
+	for k := range in.Tags {
+		switch k {
+		case "", "kind", "pid", "name", "alias":
+			v.errorf("influx.tags", "tag key %q is empty or reserved", k)
+		}
+	}
+}
+
+// warnIssues 通过标准 log 输出校验问题
+func warnIssues(path string, issues []Issue) {
+	for _, i := range issues {
+		level := "error"
+		if i.Warning {
+			level = "warning"
+		}
+		log.Printf("config %s: %s: %s", path, level, i)
+	}
+}
@@ -0,0 +1,144 @@
+// Package format 字节数、速率、百分比、运行时间等数值的统一显示格式，CLI 表格和风险描述共用
+package format
+
+import (
+	"fmt"
+	"math"
+	"sync"
+)
+
+// 字节数的单位口径（display.byte_units）
+const (
+	UnitsDefault = ""        // 1024 进制，标注 KB/MB（兼容旧版显示）
+	UnitsBinary  = "binary"  // 1024 进制，标注 KiB/MiB，与 IEC 标准一致
+	UnitsDecimal = "decimal" // 1000 进制，标注 KB/MB，与磁盘厂商和部分网络设备一致
+)
+
+// byteScale 一种单位口径：进制和 B 以上依次放大的单位名
+type byteScale struct {
+	base  float64
+	units []string
+}
+
+var byteScales = map[string]byteScale{
+	UnitsDefault: {1024, []string{"B", "KB", "MB", "GB", "TB", "PB", "EB"}},
+	UnitsBinary:  {1024, []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}},
+	UnitsDecimal: {1000, []string{"B", "KB", "MB", "GB", "TB", "PB", "EB"}},
+}
+
+var (
+	unitsMu sync.RWMutex
+	units   = UnitsDefault
+)
+
+// SetByteUnits 设置字节数的单位口径（""、"binary" 或 "decimal"），CLI、报告和风险描述随之改变，
+// 阈值配置中的 MB 仍按 1024 进制换算
+func SetByteUnits(mode string) error {
+	if _, ok := byteScales[mode]; !ok {
+		return fmt.Errorf("unknown byte units %q (binary or decimal)", mode)
+	}
+	unitsMu.Lock()
+	units = mode
+	unitsMu.Unlock()
+	return nil
+}
+
+// ByteUnits 当前的单位口径
+func ByteUnits() string {
+	unitsMu.RLock()
+	defer unitsMu.RUnlock()
+	return units
+}
+
+// Bytes 格式化字节数：小于 1 KB 显示整数字节，KB/MB 保留 1 位小数，GB 及以上保留 2 位小数；
+// 进制和单位名按 SetByteUnits 设置的口径
+func Bytes(bytes uint64) string {
+	scale := byteScales[ByteUnits()]
+	value := float64(bytes)
+	if value < scale.base {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	unit := 0
+	for value >= scale.base && unit < len(scale.units)-1 {
+		value /= scale.base
+		unit++
+	}
+	if unit >= 3 {
+		return fmt.Sprintf("%.2f %s", value, scale.units[unit])
+	}
+	return fmt.Sprintf("%.1f %s", value, scale.units[unit])
+}
+
+// signedBytes 格式化可正可负的字节数，负数带 "-" 号；NaN 视为 0，超出范围的值按最大值显示
+func signedBytes(value float64) string {
+	if math.IsNaN(value) {
+		return Bytes(0)
+	}
+	sign := ""
+	if value < 0 {
+		sign = "-"
+		value = -value
+	}
+	if value >= math.MaxUint64 {
+		return sign + Bytes(math.MaxUint64)
+	}
+	return sign + Bytes(uint64(value))
+}
+
+// BytesRate 格式化字节速率（如 "1.5 MB/s"），负速率显示为 "-1.5 MB/s"
+func BytesRate(bytesPerSec float64) string {
+	return signedBytes(bytesPerSec) + "/s"
+}
+
+// MemGrowth 格式化内存增速，增长带 "+" 号、下降带 "-" 号，无变化为 "0"；
+// 不足 1 B/s 的变化显示为 "+<1 B/s"，避免与无变化混淆
+func MemGrowth(rate float64) string {
+	switch {
+	case rate >= 1:
+		return "+" + BytesRate(rate)
+	case rate > 0:
+		return "+<1 B/s"
+	case rate <= -1:
+		return BytesRate(rate)
+	case rate < 0:
+		return "-<1 B/s"
+	}
+	return "0"
+}
+
+// Percent 格式化百分比，保留 1 位小数
+func Percent(pct float64) string {
+	return fmt.Sprintf("%.1f%%", pct)
+}
+
+// Uptime 格式化运行时间（如 "3时25分"），负数按 0 处理
+func Uptime(seconds int64) string {
+	if seconds < 0 {
+		seconds = 0
+	}
+	if seconds < 60 {
+		return fmt.Sprintf("%d秒", seconds)
+	}
+	if seconds < 3600 {
+		return fmt.Sprintf("%d分%d秒", seconds/60, seconds%60)
+	}
+	if seconds < 86400 {
+		return fmt.Sprintf("%d时%d分", seconds/3600, (seconds%3600)/60)
+	}
+	return fmt.Sprintf("%d天%d时", seconds/86400, (seconds%86400)/3600)
+}
+
+// Truncate 按字符（而非字节）截断字符串，超长时以 "..." 结尾；maxLen 不超过 3 时直接截断，maxLen<=0 返回空串
+func Truncate(s string, maxLen int) string {
+	if maxLen <= 0 {
+		return ""
+	}
+	runes := []rune(s)
+	if len(runes) <= maxLen {
+		return s
+	}
+	if maxLen <= 3 {
+		return string(runes[:maxLen])
+	}
+	return string(runes[:maxLen-3]) + "..."
+}
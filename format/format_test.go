@@ -0,0 +1,175 @@
+package format
+
+import (
+	"math"
+	"testing"
+)
+
+// withUnits 在 mode 口径下执行 fn，结束后恢复默认口径
+func withUnits(t *testing.T, mode string, fn func()) {
+	t.Helper()
+	if err := SetByteUnits(mode); err != nil {
+		t.Fatalf("SetByteUnits(%q): %v", mode, err)
+	}
+	defer SetByteUnits(UnitsDefault)
+	fn()
+}
+
+func TestBytes(t *testing.T) {
+	tests := []struct {
+		units string
+		in    uint64
+		want  string
+	}{
+		{UnitsDefault, 0, "0 B"},
+		{UnitsDefault, 1023, "1023 B"},
+		{UnitsDefault, 1024, "1.0 KB"},
+		{UnitsDefault, 1536, "1.5 KB"},
+		{UnitsDefault, 1 << 20, "1.0 MB"},
+		{UnitsDefault, 1 << 30, "1.00 GB"},
+		{UnitsDefault, 5 << 40, "5.00 TB"},
+		{UnitsDefault, math.MaxUint64, "16.00 EB"},
+		{UnitsBinary, 1023, "1023 B"},
+		{UnitsBinary, 1024, "1.0 KiB"},
+		{UnitsBinary, 3 << 30, "3.00 GiB"},
+		{UnitsBinary, math.MaxUint64, "16.00 EiB"},
+		{UnitsDecimal, 999, "999 B"},
+		{UnitsDecimal, 1000, "1.0 KB"},
+		{UnitsDecimal, 1500000, "1.5 MB"},
+		{UnitsDecimal, 2e9, "2.00 GB"},
+		{UnitsDecimal, math.MaxUint64, "18.45 EB"},
+	}
+	for _, tt := range tests {
+		withUnits(t, tt.units, func() {
+			if got := Bytes(tt.in); got != tt.want {
+				t.Errorf("units %q: Bytes(%d) = %q, want %q", tt.units, tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetByteUnitsRejectsUnknown(t *testing.T) {
+	if err := SetByteUnits("si"); err == nil {
+		t.Fatal("SetByteUnits(\"si\") succeeded, want error")
+	}
+	if got := ByteUnits(); got != UnitsDefault {
+		t.Fatalf("units after rejected mode = %q, want default", got)
+	}
+}
+
+func TestBytesRate(t *testing.T) {
+	tests := []struct {
+		in   float64
+		want string
+	}{
+		{0, "0 B/s"},
+		{0.5, "0 B/s"},
+		{512, "512 B/s"},
+		{1536, "1.5 KB/s"},
+		{-1536, "-1.5 KB/s"},
+		{-512, "-512 B/s"},
+		{3 << 30, "3.00 GB/s"},
+		{1e30, "16.00 EB/s"},
+		{math.Inf(1), "16.00 EB/s"},
+		{math.Inf(-1), "-16.00 EB/s"},
+		{math.NaN(), "0 B/s"},
+	}
+	for _, tt := range tests {
+		if got := BytesRate(tt.in); got != tt.want {
+			t.Errorf("BytesRate(%v) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMemGrowth(t *testing.T) {
+	tests := []struct {
+		in   float64
+		want string
+	}{
+		{0, "0"},
+		{math.NaN(), "0"},
+		{0.5, "+<1 B/s"},
+		{-0.5, "-<1 B/s"},
+		{1, "+1 B/s"},
+		{-1, "-1 B/s"},
+		{2048, "+2.0 KB/s"},
+		{-2048, "-2.0 KB/s"},
+		{math.Inf(1), "+16.00 EB/s"},
+		{math.Inf(-1), "-16.00 EB/s"},
+	}
+	for _, tt := range tests {
+		if got := MemGrowth(tt.in); got != tt.want {
+			t.Errorf("MemGrowth(%v) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestPercent(t *testing.T) {
+	tests := []struct {
+		in   float64
+		want string
+	}{
+		{0, "0.0%"},
+		{12.34, "12.3%"},
+		{99.96, "100.0%"},
+		{-5, "-5.0%"},
+		{1e6, "1000000.0%"},
+		{math.NaN(), "NaN%"},
+	}
+	for _, tt := range tests {
+		if got := Percent(tt.in); got != tt.want {
+			t.Errorf("Percent(%v) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestUptime(t *testing.T) {
+	tests := []struct {
+		in   int64
+		want string
+	}{
+		{math.MinInt64, "0秒"},
+		{-5, "0秒"},
+		{0, "0秒"},
+		{59, "59秒"},
+		{60, "1分0秒"},
+		{3599, "59分59秒"},
+		{3600, "1时0分"},
+		{86399, "23时59分"},
+		{86400, "1天0时"},
+		{90061, "1天1时"},
+		{45*86400 + 2*3600 + 59*60, "45天2时"}, // 主机运行时间（system status）
+		{math.MaxInt64, "106751991167300天15时"},
+	}
+	for _, tt := range tests {
+		if got := Uptime(tt.in); got != tt.want {
+			t.Errorf("Uptime(%d) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		in     string
+		maxLen int
+		want   string
+	}{
+		{"", 5, ""},
+		{"hello", 10, "hello"},
+		{"hello", 5, "hello"},
+		{"hello world", 8, "hello..."},
+		{"hello", 3, "hel"},
+		{"hello", 1, "h"},
+		{"hello", 0, ""},
+		{"hello", -1, ""},
+		{"发电机组状态监控", 8, "发电机组状态监控"},
+		{"发电机组状态监控", 5, "发电..."},
+		{"发电机组", 2, "发电"},
+		{"scada-主控", 7, "scad..."},
+	}
+	for _, tt := range tests {
+		if got := Truncate(tt.in, tt.maxLen); got != tt.want {
+			t.Errorf("Truncate(%q, %d) = %q, want %q", tt.in, tt.maxLen, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+// Package i18n 事件消息、影响描述和处置建议等生成文字的多语言消息表
+// 消息按键查找模板并以 fmt 格式代入参数；JSON 字段名和事件类型不受语言影响
+package i18n
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// 支持的语言
+const (
+	Chinese = "zh"
+	English = "en"
+)
+
+var (
+	mu   sync.RWMutex
+	lang = Chinese
+)
+
+// catalogs 语言 -> 消息键 -> 模板
+var catalogs = map[string]map[string]string{
+	Chinese: zhMessages,
+	English: enMessages,
+}
+
+// Normalize 规范化语言名（不区分大小写，zh-CN、en_US 等取主语言），空表示默认中文
+// 不支持的语言返回错误
+func Normalize(language string) (string, error) {
+	l := strings.ToLower(strings.TrimSpace(language))
+	if i := strings.IndexAny(l, "-_"); i >= 0 {
+		l = l[:i]
+	}
+	if l == "" {
+		return Chinese, nil
+	}
+	if _, ok := catalogs[l]; !ok {
+		return "", fmt.Errorf("unsupported language %q (supported: zh, en)", language)
+	}
+	return l, nil
+}
+
+// SetLanguage 设置输出语言，不支持的语言回退为中文并返回错误
+func SetLanguage(language string) error {
+	l, err := Normalize(language)
+	if err != nil {
+		l = Chinese
+	}
+	mu.Lock()
+	lang = l
+	mu.Unlock()
+	return err
+}
+
+// Language 当前输出语言
+func Language() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return lang
+}
+
+// T 按当前语言取消息模板并代入参数，当前语言缺少该消息时使用中文，仍找不到时返回键名
+func T(key string, args ...interface{}) string {
+	tmpl, ok := catalogs[Language()][key]
+	if !ok {
+		if tmpl, ok = zhMessages[key]; !ok {
+			return key
+		}
+	}
+	if len(args) == 0 {
+		return tmpl
+	}
+	return fmt.Sprintf(tmpl, args...)
+}
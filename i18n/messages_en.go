@@ -0,0 +1,142 @@
+package i18n
+
+// enMessages 英文消息
+var enMessages = map[string]string{
+	// 影响描述和处置建议
+	"impact.cpu.proc":                   "Process %s (PID %d) CPU usage %.1f%% exceeds threshold %.0f%%",
+	"impact.cpu.system":                 "System CPU %.1f%% exceeds threshold, process %s (PID %d) uses %.1f%%",
+	"impact.cpu.suggestion.critical":    "CPU is severely short, process %s uses %.1f%%; check the process immediately or add system resources",
+	"impact.cpu.suggestion.high":        "Process %s uses a lot of CPU (%.1f%%) and may slow down the monitored target; check whether it is behaving normally",
+	"impact.cpu.suggestion":             "Keep an eye on process %s, CPU usage %.1f%%",
+	"impact.cpu_core.desc":              "Process %s (PID %d) uses about %.0f%% of a core, cores %s are saturated (%d/%d of the target's usable cores saturated)",
+	"impact.cpu_core.suggestion":        "Process %s shares cores %s with the monitored target; adjust the CPU affinity of both to isolate them on different cores",
+	"impact.memory.proc":                "Process %s (PID %d) memory usage %s exceeds threshold %.0f MB",
+	"impact.memory.system":              "System memory %.1f%% exceeds threshold, process %s (PID %d) uses %s",
+	"impact.memory.suggestion.growth":   "Process %s memory keeps growing (%s), possibly a memory leak; investigate",
+	"impact.memory.suggestion.critical": "Memory is nearly exhausted, process %s uses %s; risk of OOM, act immediately",
+	"impact.memory.suggestion.high":     "Memory pressure is high, process %s uses %s; check whether memory can be freed",
+	"impact.memory.suggestion":          "Keep an eye on the memory usage of process %s (%s)",
+	"impact.mem_growth.desc":            "Process %s (PID %d) memory growth %s exceeds threshold %.0f MB/s",
+	"impact.mem_growth.suggestion":      "Process %s memory keeps growing, possibly a memory leak; investigate",
+	"impact.disk_io.read":               "Process %s (PID %d) disk read %s exceeds threshold %.0f MB/s",
+	"impact.disk_io.write":              "Process %s (PID %d) disk write %s exceeds threshold %.0f MB/s",
+	"impact.disk_io.system":             "System disk IO %s exceeds threshold, process %s (PID %d) IO rate %s",
+	"impact.disk_io.suggestion":         "Process %s has high disk IO that may delay the monitored target's IO; check its IO activity",
+	"impact.network.recv":               "Process %s (PID %d) network receive %s exceeds threshold %.0f MB/s",
+	"impact.network.send":               "Process %s (PID %d) network send %s exceeds threshold %.0f MB/s",
+	"impact.network.system":             "System network traffic %s exceeds threshold, process %s (PID %d) traffic %s",
+	"impact.network.suggestion":         "Process %s has high network traffic that may affect the monitored target's communication",
+	"impact.port.desc":                  "Port %d %s, process %s (PID %d)",
+	"impact.port.listen":                "is being listened on by another process",
+	"impact.port.connect":               "has an external connection",
+	"impact.port.suggestion.listen":     "Port %d is being listened on by %s (PID %d), a port conflict; check the configuration or stop the conflicting process",
+	"impact.port.suggestion.connect":    "Process %s (PID %d) is connecting to the monitored target's port %d",
+	"impact.file.desc":                  "File %s is also opened by process %s (PID %d)",
+	"impact.file.suggestion":            "File %s is opened by multiple processes, which may affect the monitored target's exclusive access to it",
+	"impact.fds.desc":                   "Process %s (PID %d) handle count %d exceeds threshold %d",
+	"impact.fds.suggestion":             "Process %s has too many handles, possibly a resource leak; investigate",
+	"impact.fd_growth.desc":             "Process %s (PID %d) handle count grows by %.0f per minute (now %d), exceeding threshold %.0f/min",
+	"impact.fd_growth.suggestion":       "Process %s keeps opening handles, possibly a handle leak; investigate or schedule a restart before it hits the system limit",
+	"impact.threads.desc":               "Process %s (PID %d) thread count %d exceeds threshold %d",
+	"impact.threads.suggestion":         "Process %s has too many threads, which may affect system performance; investigate",
+	"impact.open_files.desc":            "Process %s (PID %d) open files %d exceeds threshold %d",
+	"impact.open_files.suggestion":      "Process %s has too many open files, which may affect system performance",
+	"impact.vms.desc":                   "Process %s (PID %d) virtual memory %s exceeds threshold %.0f MB",
+	"impact.vms.desc_anon":              "Process %s (PID %d) anonymous virtual memory %s (excluding file mappings, total %s) exceeds threshold %.0f MB",
+	"impact.vms.suggestion":             "Process %s virtual memory usage is too high",
+	"impact.priority.desc":              "Target priority %d deviates from expected %d (nice %d)",
+	"impact.priority.suggestion":        "The target's priority was changed (e.g. reniced by a script or given another priority class), which may cause scheduling jitter; restore the expected value and find the source of the change",
+	"impact.churn.multiple":             "multiple processes",
+	"impact.churn.top":                  ", %s started %d times",
+	"impact.churn.desc":                 "Process churn: %d processes started/exited in the last minute (threshold %d)%s",
+	"impact.churn.suggestion":           "A service may be crashing and restarted repeatedly, or a script is spawning processes frequently; check the process logs and supervisor/scheduled task configuration",
+	"impact.steal.source":               "hypervisor",
+	"impact.steal.desc":                 "CPU stolen by the hypervisor: %.1f%% (threshold %.0f%%)",
+	"impact.steal.suggestion":           "The VM host is overcommitted, so targets wait for CPU even when runnable; ask the virtualization team to migrate the VM or reserve CPU for it",
+	"impact.virt_contention.desc":       "Virtualization contention: hypervisor steal %.1f%%, target runnable but waiting for CPU %.1f%% (threshold %.0f%%), own CPU only %.1f%%",
+	"impact.virt_contention.suggestion": "The target has work to do but cannot get a CPU; the slowdown is not caused by its own load. Ask the virtualization team to migrate the VM, reserve CPU for it or lower the host overcommit ratio",
+	"impact.zombies.desc":               "Target has %d unreaped zombie child processes (threshold %d)",
+	"impact.zombies.suggestion":         "Child processes of the target exit without being reaped (missing wait/SIGCHLD handling) and will eventually exhaust process IDs; contact the vendor and restart the target at a convenient time if needed",
+	"impact.trend.desc":                 "At the current rate, %s will be exhausted in about %d minutes (now %.1f%%, +%.2f%% per minute, limit %.0f%%)",
+	"impact.trend.suggestion":           "%s usage keeps rising; look for the source of growth (memory leak, growing log or data files) and clean up or add capacity in advance",
+	"trend.metric.memory":               "memory",
+	"trend.metric.swap":                 "swap",
+	"trend.metric.disk":                 "disk %s",
+	"impact.dir_growth.desc":            "Directory %s grew %.0f MB/hour recently (threshold %.0f MB/hour), now %s, %s free on its disk",
+	"impact.dir_growth.suggestion":      "The target's data or log directory %s is growing fast; check log levels, retention of old data and unexpected writes, and clean up or move it to a larger disk if needed",
+	"impact.dir_full.desc":              "Disk holding directory %s has %s free, below the minimum of %.0f MB (directory is now %s)",
+	"impact.dir_full.suggestion":        "The disk holding %s is almost full and the target may exit when writes fail; clean up or add capacity now",
+	"impact.notes":                      "Handling notes: %s",
+	"impact.runbook":                    "See runbook: %s",
+	"impact.separator":                  "; ",
+	"impact.group.sources":              "%d processes",
+	"impact.group.desc":                 "Sources: %s (%d processes in total)",
+
+	// 影响事件日志
+	"impact.event.raised":   "[%s impact] %s → %s: %s",
+	"impact.event.resolved": "[Impact resolved] %[3]s impact of %[1]s on %[2]s has cleared",
+	"impact.event.id":       " (ID: %s)",
+	"severity.critical":     "critical",
+	"severity.high":         "high",
+	"severity.medium":       "medium",
+	"severity.low":          "low",
+
+	// 影响类型名称
+	"impact_type.cpu":             "CPU contention",
+	"impact_type.cpu_core":        "CPU core contention",
+	"impact_type.memory":          "Memory pressure",
+	"impact_type.mem_growth":      "Memory growth",
+	"impact_type.disk_io":         "Disk IO",
+	"impact_type.network":         "Network bandwidth",
+	"impact_type.file":            "File contention",
+	"impact_type.port":            "Port conflict",
+	"impact_type.fds":             "Handle count",
+	"impact_type.fd_growth":       "Handle growth",
+	"impact_type.threads":         "Thread count",
+	"impact_type.open_files":      "Open files",
+	"impact_type.vms":             "Virtual memory",
+	"impact_type.priority":        "Priority deviation",
+	"impact_type.churn":           "Process churn",
+	"impact_type.zombies":         "Zombie child processes",
+	"impact_type.trend":           "Trend forecast",
+	"impact_type.steal":           "CPU steal",
+	"impact_type.virt_contention": "Virtualization contention",
+	"impact_type.dir_growth":      "Directory growth",
+	"impact_type.dir_full":        "Directory disk full",
+
+	// 监控事件
+	"event.priority_changed":   "Process priority changed: %s → %s",
+	"event.exit":               "Process exited",
+	"event.new_process":        "New process started",
+	"event.process_gone":       "Process gone",
+	"event.storm":              "Event storm: more than %d events per minute, further events suppressed",
+	"event.binary_changed":     "Executable changed: %s",
+	"event.reexec":             "Process exec'd into another program (same PID): %s → %s",
+	"event.restart_attempt":    "Running restart command (attempt %d/%d)",
+	"event.restart_success":    "Restarted, PID %d → %d",
+	"event.restart_failed":     "Restart failed (attempt %d/%d): %s",
+	"event.restart_exhausted":  "Restarted %d times in a row, giving up; manual action required",
+	"target.duplicate":         "A target with the same name is already monitored, PID %d",
+	"event.probe_down":         "Probe unreachable: %s, %d consecutive failures (%s)",
+	"event.probe_up":           "Probe recovered: %s, connect time %.1fms",
+	"binary.path":              "path %s → %s",
+	"binary.cwd":               "working directory %s → %s",
+	"binary.deleted":           "file deleted",
+	"binary.restored":          "file restored",
+	"binary.size":              "size %d → %d bytes",
+	"binary.mtime":             "modified %s → %s",
+	"binary.sha256":            "SHA256 %s → %s",
+	"maintenance.all_targets":  "all targets",
+	"maintenance.start":        "Entered maintenance mode for %s, reason: %s",
+	"maintenance.end":          "Maintenance mode ended (%s), reason: %s",
+	"maintenance.manual":       "ended manually",
+	"maintenance.expired":      "expired",
+	"maintenance.no_reason":    "not given",
+	"maintenance.window_start": "Entered scheduled maintenance window %s (%s), impact alerts paused, reason: %s",
+	"maintenance.window_end":   "Scheduled maintenance window %s ended, impact alerts resumed",
+	"config.drift.name":        "config file",
+	"config.drift.detected":    "Config file %s was modified while running and differs from the running config: %s (config apply-file applies the file, config save overwrites it with the running config)",
+	"config.drift.resolved":    "Config file %s matches the running config again",
+	"timeline.cpu":             "CPU %.1f%% deviates from the window mean %.1f%%",
+	"timeline.memory":          "Memory %s deviates from the window mean %s",
+}
@@ -0,0 +1,142 @@
+package i18n
+
+// zhMessages 中文消息（默认语言，其他语言缺少的消息也回退到这里）
+var zhMessages = map[string]string{
+	// 影响描述和处置建议
+	"impact.cpu.proc":                   "进程 %s (PID %d) CPU 占用 %.1f%% 超过阈值 %.0f%%",
+	"impact.cpu.system":                 "系统 CPU %.1f%% 超过阈值，进程 %s (PID %d) 占用 %.1f%%",
+	"impact.cpu.suggestion.critical":    "CPU 资源严重不足，进程 %s 占用 %.1f%%，建议立即检查该进程或增加系统资源",
+	"impact.cpu.suggestion.high":        "进程 %s 占用大量 CPU (%.1f%%)，可能影响监控目标性能，建议检查该进程是否正常",
+	"impact.cpu.suggestion":             "建议关注进程 %s，CPU 占用 %.1f%%",
+	"impact.cpu_core.desc":              "进程 %s (PID %d) 占用约 %.0f%% 单核，核心 %s 已饱和（目标可用核心 %d/%d 饱和）",
+	"impact.cpu_core.suggestion":        "进程 %s 与监控目标共享核心 %s，建议调整两者的 CPU 亲和性将其隔离到不同核心",
+	"impact.memory.proc":                "进程 %s (PID %d) 内存占用 %s 超过阈值 %.0f MB",
+	"impact.memory.system":              "系统内存 %.1f%% 超过阈值，进程 %s (PID %d) 占用 %s",
+	"impact.memory.suggestion.growth":   "进程 %s 内存持续增长 (%s)，可能存在内存泄漏，建议检查",
+	"impact.memory.suggestion.critical": "内存即将耗尽，进程 %s 占用 %s，存在 OOM 风险，建议立即处理",
+	"impact.memory.suggestion.high":     "内存压力较大，进程 %s 占用 %s，建议检查是否可以释放",
+	"impact.memory.suggestion":          "建议关注进程 %s 的内存使用 (%s)",
+	"impact.mem_growth.desc":            "进程 %s (PID %d) 内存增速 %s 超过阈值 %.0f MB/s",
+	"impact.mem_growth.suggestion":      "进程 %s 内存持续增长，可能存在内存泄漏，建议检查",
+	"impact.disk_io.read":               "进程 %s (PID %d) 磁盘读 %s 超过阈值 %.0f MB/s",
+	"impact.disk_io.write":              "进程 %s (PID %d) 磁盘写 %s 超过阈值 %.0f MB/s",
+	"impact.disk_io.system":             "系统磁盘 IO %s 超过阈值，进程 %s (PID %d) IO 速率 %s",
+	"impact.disk_io.suggestion":         "进程 %s 磁盘 IO 较高，可能导致监控目标 IO 延迟，建议检查该进程的 IO 操作",
+	"impact.network.recv":               "进程 %s (PID %d) 网络收 %s 超过阈值 %.0f MB/s",
+	"impact.network.send":               "进程 %s (PID %d) 网络发 %s 超过阈值 %.0f MB/s",
+	"impact.network.system":             "系统网络流量 %s 超过阈值，进程 %s (PID %d) 流量 %s",
+	"impact.network.suggestion":         "进程 %s 网络流量较高，可能影响监控目标的网络通信",
+	"impact.port.desc":                  "端口 %d %s，进程 %s (PID %d)",
+	"impact.port.listen":                "被其他进程监听",
+	"impact.port.connect":               "有外部连接",
+	"impact.port.suggestion.listen":     "端口 %d 被 %s (PID %d) 监听，存在端口冲突，建议检查配置或终止冲突进程",
+	"impact.port.suggestion.connect":    "进程 %s (PID %d) 正在连接监控目标的端口 %d",
+	"impact.file.desc":                  "文件 %s 被进程 %s (PID %d) 同时打开",
+	"impact.file.suggestion":            "文件 %s 被多个进程打开，可能影响监控目标对该文件的独占访问",
+	"impact.fds.desc":                   "进程 %s (PID %d) 句柄数 %d 超过阈值 %d",
+	"impact.fds.suggestion":             "进程 %s 句柄数过高，可能存在资源泄漏，建议检查",
+	"impact.fd_growth.desc":             "进程 %s (PID %d) 句柄数每分钟增加 %.0f 个（当前 %d）超过阈值 %.0f 个/分钟",
+	"impact.fd_growth.suggestion":       "进程 %s 句柄数持续增长，可能存在句柄泄漏，建议在达到系统上限前检查或安排重启",
+	"impact.threads.desc":               "进程 %s (PID %d) 线程数 %d 超过阈值 %d",
+	"impact.threads.suggestion":         "进程 %s 线程数过多，可能影响系统性能，建议检查",
+	"impact.open_files.desc":            "进程 %s (PID %d) 打开文件数 %d 超过阈值 %d",
+	"impact.open_files.suggestion":      "进程 %s 打开文件数过多，可能影响系统性能",
+	"impact.vms.desc":                   "进程 %s (PID %d) 虚拟内存 %s 超过阈值 %.0f MB",
+	"impact.vms.desc_anon":              "进程 %s (PID %d) 匿名虚拟内存 %s（不含文件映射，总虚拟内存 %s）超过阈值 %.0f MB",
+	"impact.vms.suggestion":             "进程 %s 虚拟内存占用过高",
+	"impact.priority.desc":              "目标优先级 %d 偏离期望值 %d (nice %d)",
+	"impact.priority.suggestion":        "目标进程优先级被修改（如被脚本 renice 或调整优先级类），可能导致调度抖动，建议恢复为期望值并排查修改来源",
+	"impact.churn.multiple":             "多个进程",
+	"impact.churn.top":                  "，其中 %s 新建 %d 次",
+	"impact.churn.desc":                 "进程频繁启停: 最近一分钟新建/退出 %d 个进程 (阈值 %d)%s",
+	"impact.churn.suggestion":           "可能有服务崩溃后被反复拉起或脚本频繁创建进程，建议检查该进程的日志和守护/计划任务配置",
+	"impact.steal.source":               "宿主机",
+	"impact.steal.desc":                 "CPU 被宿主机抢占 %.1f%% (阈值 %.0f%%)",
+	"impact.steal.suggestion":           "虚拟机所在宿主机 CPU 超分，目标可运行时得不到 CPU 而变慢，建议联系虚拟化平台管理员迁移虚拟机或为其预留 CPU",
+	"impact.virt_contention.desc":       "虚拟化资源争用: 宿主机抢占 %.1f%%，目标可运行等待 CPU %.1f%% (阈值 %.0f%%)，自身 CPU 仅 %.1f%%",
+	"impact.virt_contention.suggestion": "目标有工作要做却拿不到 CPU，变慢不是目标自身负载造成的，建议联系虚拟化平台管理员迁移虚拟机、为其预留 CPU 或降低宿主机超分比",
+	"impact.zombies.desc":               "目标有 %d 个僵尸子进程未回收 (阈值 %d)",
+	"impact.zombies.suggestion":         "目标进程创建的子进程退出后未被回收（缺少 wait/SIGCHLD 处理），持续积累会耗尽进程号，建议联系厂家排查，必要时择机重启目标",
+	"impact.trend.desc":                 "按当前趋势，%s将在约 %d 分钟内耗尽（当前 %.1f%%，每分钟 +%.2f%%，耗尽线 %.0f%%）",
+	"impact.trend.suggestion":           "%s使用率持续上升，建议排查增长来源（如内存泄漏、日志或数据文件增长），必要时提前清理或扩容",
+	"trend.metric.memory":               "内存",
+	"trend.metric.swap":                 "Swap",
+	"trend.metric.disk":                 "磁盘(%s)",
+	"impact.dir_growth.desc":            "目录 %s 最近增长 %.0f MB/小时 (阈值 %.0f MB/小时)，当前 %s，所在磁盘剩余 %s",
+	"impact.dir_growth.suggestion":      "目标的数据或日志目录 %s 增长过快，建议检查日志级别、过期数据清理和异常写入，必要时清理或迁移到更大的磁盘",
+	"impact.dir_full.desc":              "目录 %s 所在磁盘剩余 %s，低于下限 %.0f MB（目录当前 %s）",
+	"impact.dir_full.suggestion":        "目录 %s 所在磁盘即将写满，目标写入失败可能直接退出，建议立即清理或扩容",
+	"impact.notes":                      "处置说明: %s",
+	"impact.runbook":                    "参见运行手册: %s",
+	"impact.separator":                  "；",
+	"impact.group.sources":              "%d 个进程",
+	"impact.group.desc":                 "影响源: %s（共 %d 个进程）",
+
+	// 影响事件日志
+	"impact.event.raised":   "[影响%s] %s → %s: %s",
+	"impact.event.resolved": "[影响解除] %s 对 %s 的 %s 影响已解除",
+	"impact.event.id":       "（ID: %s）",
+	"severity.critical":     "严重",
+	"severity.high":         "高级",
+	"severity.medium":       "中级",
+	"severity.low":          "低级",
+
+	// 影响类型名称
+	"impact_type.cpu":             "CPU竞争",
+	"impact_type.cpu_core":        "CPU核心争用",
+	"impact_type.memory":          "内存压力",
+	"impact_type.mem_growth":      "内存增速",
+	"impact_type.disk_io":         "磁盘IO",
+	"impact_type.network":         "网络带宽",
+	"impact_type.file":            "文件占用",
+	"impact_type.port":            "端口占用",
+	"impact_type.fds":             "句柄数",
+	"impact_type.fd_growth":       "句柄增速",
+	"impact_type.threads":         "线程数",
+	"impact_type.open_files":      "打开文件数",
+	"impact_type.vms":             "虚拟内存",
+	"impact_type.priority":        "优先级偏离",
+	"impact_type.churn":           "进程频繁启停",
+	"impact_type.zombies":         "僵尸子进程",
+	"impact_type.trend":           "趋势预测",
+	"impact_type.steal":           "CPU抢占",
+	"impact_type.virt_contention": "虚拟化资源争用",
+	"impact_type.dir_growth":      "目录增长",
+	"impact_type.dir_full":        "目录空间不足",
+
+	// 监控事件
+	"event.priority_changed":   "进程优先级变化: %s → %s",
+	"event.exit":               "进程已退出",
+	"event.new_process":        "新进程启动",
+	"event.process_gone":       "进程消失",
+	"event.storm":              "事件风暴：每分钟事件超过 %d 条，后续事件已抑制",
+	"event.binary_changed":     "可执行文件变化: %s",
+	"event.reexec":             "进程已 exec 为其他程序（PID 不变）: %s → %s",
+	"event.restart_attempt":    "执行重启命令（第 %d/%d 次）",
+	"event.restart_success":    "重启成功，PID %d → %d",
+	"event.restart_failed":     "重启失败（第 %d/%d 次）: %s",
+	"event.restart_exhausted":  "已连续重启 %d 次，不再自动重启，请人工处理",
+	"target.duplicate":         "已存在同名监控目标, PID %d",
+	"event.probe_down":         "远程探测不可达: %s，连续失败 %d 次（%s）",
+	"event.probe_up":           "远程探测恢复: %s，连接耗时 %.1fms",
+	"binary.path":              "路径 %s → %s",
+	"binary.cwd":               "工作目录 %s → %s",
+	"binary.deleted":           "文件已删除",
+	"binary.restored":          "文件已恢复",
+	"binary.size":              "大小 %d → %d 字节",
+	"binary.mtime":             "修改时间 %s → %s",
+	"binary.sha256":            "SHA256 %s → %s",
+	"maintenance.all_targets":  "全部目标",
+	"maintenance.start":        "进入维护模式，时长 %s，原因: %s",
+	"maintenance.end":          "维护模式结束（%s），原因: %s",
+	"maintenance.manual":       "手动结束",
+	"maintenance.expired":      "到期",
+	"maintenance.no_reason":    "未填写",
+	"maintenance.window_start": "进入计划维护时段 %s（%s），风险告警暂停，原因: %s",
+	"maintenance.window_end":   "计划维护时段 %s 结束，风险告警恢复",
+	"config.drift.name":        "配置文件",
+	"config.drift.detected":    "配置文件 %s 在运行中被修改，与运行中的配置不一致: %s（config apply-file 按文件应用，config save 以运行中的配置覆盖文件）",
+	"config.drift.resolved":    "配置文件 %s 与运行中的配置已一致",
+	"timeline.cpu":             "CPU %.1f%% 偏离窗口均值 %.1f%%",
+	"timeline.memory":          "内存 %s 偏离窗口均值 %s",
+}
@@ -1,13 +1,25 @@
 package impact
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"runtime"
 	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/shirou/gopsutil/v3/net"
+
+	"monitor-agent/buffer"
+	"monitor-agent/clock"
+	"monitor-agent/format"
+	"monitor-agent/i18n"
 	"monitor-agent/logger"
 	"monitor-agent/provider"
+	"monitor-agent/pubsub"
 	"monitor-agent/types"
 )
 
@@ -22,37 +34,113 @@ type impactKey struct {
 // EventCallback 事件回调函数类型
 type EventCallback func(eventType string, pid int32, name string, message string)
 
+// ErrAlreadyRunning 影响分析已在运行时 Start 返回的错误
+var ErrAlreadyRunning = errors.New("impact analysis is already running")
+
 // ImpactAnalyzer 影响分析器
 type ImpactAnalyzer struct {
 	mu           sync.RWMutex
 	provider     provider.ProcProvider
-	config       types.ImpactConfig
+	config       types.ImpactConfig // 基础配置
 	targets      func() []types.MonitorTarget // 获取监控目标的函数
 	getProcesses func() ([]types.ProcessInfo, error)
 	running      bool
 	stopCh       chan struct{}
+	clock        clock.Clock // 时间来源，默认系统时钟
 
 	// 动态事件存储（活跃的冲突）
 	activeImpacts map[impactKey]*types.ImpactEvent
 
+	// 持续时间判定（见 sustain.go）
+	pending    map[impactKey]time.Time    // 候选影响首次突破时间（尚未达到持续时间要求）
+	lastBreach map[impactKey]time.Time    // 候选及正式影响最近一次突破时间
+	lastLogged map[impactKey]loggedImpact // 影响最近一次写入日志（见 cooldown.go）
+	cycleStart time.Time                  // 当前分析周期开始时间
+	passTypes  map[string]bool            // 当前周期已评估的影响类型
+	// cycleConfig 当前周期的配置快照（beginCycle 时记录），持续时间、清除期和日志冷却按它判定，
+	// 与本周期各项检测使用的阈值一致
+	cycleConfig types.ImpactConfig
+
+	// 启停：lifecycleMu 串行化 Start/Stop，Stop 等待 runWG 跟踪的分析和目录扫描协程退出后返回
+	lifecycleMu sync.Mutex
+	runWG       sync.WaitGroup
+
+	// 当前周期的 PID 映射，各项分析开始前写入，用于补充影响事件中目标的指标
+	cycleProcs map[int32]*types.ProcessInfo
+
 	// 事件回调（用于记录到事件日志）
 	eventCallback EventCallback
 
+	// 维护模式判断（目标处于维护时不告警）
+	inMaintenance func(targetPID int32) bool
+
+	// 进程启停频率来源（由监控器的进程变化追踪提供）
+	churnSource func() types.ProcessChurn
+
+	// 目标指标历史来源（由监控器的指标缓冲提供，见 identity.go）
+	metricsSource func(pid int32, n int) []types.ProcessMetrics
+
+	// 最近解除的影响事件（供按 ID 查询）
+	resolved []types.ImpactEvent
+
+	// 系统使用率趋势采样（见 trend.go）
+	trendSamples *buffer.RingBuffer[trendSample]
+
+	// 突破的影响交给 emit：实时分析为 recordImpact，模拟时只计数（见 simulate.go）
+	emit func(event types.ImpactEvent, detail string)
+
+	// 最近分析周期的输入，供阈值模拟回放
+	replay *buffer.RingBuffer[cycleInput]
+
 	// 文件和端口检测器
 	fileChecker *FileChecker
 	portChecker *PortChecker
 
+	// 目标监控目录的占用统计（见 dirusage.go），由后台协程按文件检测间隔更新
+	dirUsage *DirUsageTracker
+
 	// 上次检测时间
 	lastFileCheck time.Time
 	lastPortCheck time.Time
 
-	// 缓存监控目标的监听端口 (PID -> []port)
-	targetPorts     map[int32][]int
+	// 缓存监控目标的监听地址 (PID -> []listener)
+	targetPorts     map[int32][]ConnectionInfo
 	targetPortsTime time.Time
 
 	// 缓存监控目标打开的文件 (PID -> []filePath)
 	targetFiles     map[int32][]string
 	targetFilesTime time.Time
+
+	// 目标的处置说明和运行手册（PID -> 目标配置），每个分析周期刷新
+	targetNotes map[int32]types.MonitorTarget
+
+	// 本周期各指标的 Top N 进程（见 getTopByField），并发分析时由 topMu 保护
+	topMu    sync.Mutex
+	topCache map[topKey][]types.ProcessInfo
+
+	// 周期负载控制（见 cycle.go）：busy 表示有分析周期正在运行，其余计数由 mu 保护
+	busy             atomic.Bool
+	skippedCycles    int64
+	consecutiveSkips int
+	slowCycles       int64
+
+	// 运行诊断（见 diagnostics.go）：scratch 只在分析协程中使用，每个周期结束时发布到 diag
+	scratch diagScratch
+	diag    types.ImpactDiagnostics
+
+	// 新影响事件、影响解除的实时推送
+	impactStream   *pubsub.Broker[types.ImpactEvent]
+	resolvedStream *pubsub.Broker[types.ImpactEvent]
+
+	// 影响源进程排行（按进程名累计，持久化到状态文件）
+	offenders *OffenderTracker
+
+	// 当前生效的阈值（基础配置叠加时段覆盖），每个分析周期刷新
+	effective         types.ImpactConfig
+	activeProfileName string
+
+	// 当前所在的计划维护时段（见 maintenance.go），每个分析周期刷新
+	maintWindow *types.MaintenanceSchedule
 }
 
 // NewImpactAnalyzer 创建影响分析器
@@ -78,6 +166,9 @@ func NewImpactAnalyzer(
 	if cfg.PortCheckInterval <= 0 {
 		cfg.PortCheckInterval = 30
 	}
+	if cfg.SkipWarnCycles <= 0 {
+		cfg.SkipWarnCycles = 3
+	}
 	
 	// 系统级别阈值默认值（这些也必须有值）
 	if cfg.CPUThreshold <= 0 {
@@ -92,6 +183,20 @@ func NewImpactAnalyzer(
 	if cfg.NetworkThreshold <= 0 {
 		cfg.NetworkThreshold = 100
 	}
+
+	// 健康评分权重默认值
+	if cfg.ScoreWeightCritical <= 0 {
+		cfg.ScoreWeightCritical = 40
+	}
+	if cfg.ScoreWeightHigh <= 0 {
+		cfg.ScoreWeightHigh = 15
+	}
+	if cfg.ScoreWeightMedium <= 0 {
+		cfg.ScoreWeightMedium = 5
+	}
+	if cfg.ScoreWeightLow <= 0 {
+		cfg.ScoreWeightLow = 1
+	}
 	
 	// 进程级别阈值：不再覆盖！
 	// 这些值应该从配置文件加载，0表示禁用检测
@@ -113,45 +218,99 @@ func NewImpactAnalyzer(
 		cfg.ProcNetSendThreshold = cfg.ProcessNetworkThreshold
 	}
 
-	return &ImpactAnalyzer{
-		provider:      prov,
-		config:        cfg,
-		targets:       getTargets,
-		getProcesses:  getProcesses,
-		stopCh:        make(chan struct{}),
-		activeImpacts: make(map[impactKey]*types.ImpactEvent),
-		fileChecker:   NewFileChecker(),
-		portChecker:   NewPortChecker(),
-		targetPorts:   make(map[int32][]int),
-		targetFiles:   make(map[int32][]string),
+	a := &ImpactAnalyzer{
+		provider:       prov,
+		config:         cfg,
+		targets:        getTargets,
+		getProcesses:   getProcesses,
+		clock:          clock.Real,
+		activeImpacts:  make(map[impactKey]*types.ImpactEvent),
+		pending:        make(map[impactKey]time.Time),
+		lastBreach:     make(map[impactKey]time.Time),
+		lastLogged:     make(map[impactKey]loggedImpact),
+		passTypes:      make(map[string]bool),
+		targetNotes:    make(map[int32]types.MonitorTarget),
+		fileChecker:    NewFileChecker(),
+		portChecker:    NewPortChecker(),
+		dirUsage:       NewDirUsageTracker(),
+		targetPorts:    make(map[int32][]ConnectionInfo),
+		targetFiles:    make(map[int32][]string),
+		impactStream:   pubsub.NewBroker[types.ImpactEvent](),
+		resolvedStream: pubsub.NewBroker[types.ImpactEvent](),
+		trendSamples:   buffer.NewRingBuffer[trendSample](trendSampleCap),
+		replay:         buffer.NewRingBuffer[cycleInput](replayCap),
+	}
+	a.emit = a.recordImpact
+	if warnings, err := ValidateProfiles(cfg.Profiles); err != nil {
+		logger.Warnf("IMPACT", "Invalid threshold profiles: %v", err)
+	} else {
+		for _, w := range warnings {
+			logger.Warnf("IMPACT", "Threshold profiles: %s", w)
+		}
+	}
+	if err := ValidateMaintenanceWindows(cfg.MaintenanceWindows); err != nil {
+		logger.Warnf("IMPACT", "Invalid maintenance windows: %v", err)
 	}
+	a.refreshActiveProfileLocked(a.clock.Now())
+	a.cycleConfig = a.effective
+	return a
 }
 
-// Start 启动影响分析
-func (a *ImpactAnalyzer) Start() {
+// Start 启动影响分析，已在运行时返回 ErrAlreadyRunning，配置中关闭影响分析时不启动
+func (a *ImpactAnalyzer) Start() error {
+	a.lifecycleMu.Lock()
+	defer a.lifecycleMu.Unlock()
+
 	a.mu.Lock()
-	if a.running || !a.config.Enabled {
+	if a.running {
 		a.mu.Unlock()
-		return
+		return ErrAlreadyRunning
+	}
+	if !a.config.Enabled {
+		a.mu.Unlock()
+		return nil
 	}
 	a.running = true
+	a.stopCh = make(chan struct{}) // 每次运行使用新的停止通道
+	stopCh := a.stopCh
+	interval := time.Duration(a.config.AnalysisInterval) * time.Second
+	a.runWG.Add(2)
 	a.mu.Unlock()
 
-	go a.loop()
-	logger.Infof("IMPACT", "ImpactAnalyzer started (interval=%ds)", a.config.AnalysisInterval)
+	go func() {
+		defer a.runWG.Done()
+		a.loop(interval, stopCh)
+	}()
+	go func() {
+		defer a.runWG.Done()
+		a.dirLoop(stopCh)
+	}()
+	logger.Infof("IMPACT", "ImpactAnalyzer started (interval=%ds)", int(interval/time.Second))
+	return nil
 }
 
-// Stop 停止影响分析
+// Stop 停止影响分析，等待分析和目录扫描协程退出后返回；未运行时直接返回
 func (a *ImpactAnalyzer) Stop() {
-	a.mu.Lock()
-	defer a.mu.Unlock()
+	a.lifecycleMu.Lock()
+	defer a.lifecycleMu.Unlock()
 
+	a.mu.Lock()
 	if !a.running {
+		a.mu.Unlock()
 		return
 	}
 	a.running = false
 	close(a.stopCh)
-	a.stopCh = make(chan struct{})
+	a.mu.Unlock()
+
+	// 分析过程需要 mu，等待时不能持有 mu
+	a.runWG.Wait()
+
+	if a.offenders != nil {
+		if err := a.offenders.Save(); err != nil {
+			logger.Warnf("IMPACT", "Save offender state failed: %v", err)
+		}
+	}
 	logger.Info("IMPACT", "ImpactAnalyzer stopped")
 }
 
@@ -166,47 +325,106 @@ func (a *ImpactAnalyzer) IsRunning() bool {
 func (a *ImpactAnalyzer) UpdateConfig(cfg types.ImpactConfig) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
+
+	// 阈值时段配置（窗口格式错误的时段不会生效）
+	if _, err := ValidateProfiles(cfg.Profiles); err != nil {
+		logger.Warnf("IMPACT", "Invalid threshold profiles: %v", err)
+	}
+	if err := ValidateMaintenanceWindows(cfg.MaintenanceWindows); err != nil {
+		logger.Warnf("IMPACT", "Invalid maintenance windows: %v", err)
+	}
+	mergeConfig(&a.config, cfg)
+	a.refreshActiveProfileLocked(a.clock.Now())
 	
+	logger.Infof("IMPACT", "Config updated: SysCPU=%.0f%%, SysMem=%.0f%%, ProcCPU=%.0f%%, ProcMem=%.0fMB",
+		a.config.CPUThreshold, a.config.MemoryThreshold, a.config.ProcCPUThreshold, a.config.ProcMemoryThreshold)
+}
+
+// mergeConfig 将新配置合并到 dst：必须有值的字段只在新值大于 0 时更新，其余字段（0 表示禁用）直接覆盖
+func mergeConfig(dst *types.ImpactConfig, cfg types.ImpactConfig) {
 	// 更新阈值配置
 	if cfg.CPUThreshold > 0 {
-		a.config.CPUThreshold = cfg.CPUThreshold
+		dst.CPUThreshold = cfg.CPUThreshold
 	}
 	if cfg.MemoryThreshold > 0 {
-		a.config.MemoryThreshold = cfg.MemoryThreshold
+		dst.MemoryThreshold = cfg.MemoryThreshold
 	}
 	if cfg.DiskIOThreshold > 0 {
-		a.config.DiskIOThreshold = cfg.DiskIOThreshold
+		dst.DiskIOThreshold = cfg.DiskIOThreshold
 	}
 	if cfg.NetworkThreshold > 0 {
-		a.config.NetworkThreshold = cfg.NetworkThreshold
+		dst.NetworkThreshold = cfg.NetworkThreshold
 	}
 	if cfg.TopNProcesses > 0 {
-		a.config.TopNProcesses = cfg.TopNProcesses
+		dst.TopNProcesses = cfg.TopNProcesses
 	}
 	if cfg.AnalysisInterval > 0 {
-		a.config.AnalysisInterval = cfg.AnalysisInterval
+		dst.AnalysisInterval = cfg.AnalysisInterval
+	}
+	if cfg.AnalysisWorkers > 0 {
+		dst.AnalysisWorkers = cfg.AnalysisWorkers
+	}
+	if cfg.SkipWarnCycles > 0 {
+		dst.SkipWarnCycles = cfg.SkipWarnCycles
 	}
 	if cfg.FileCheckInterval > 0 {
-		a.config.FileCheckInterval = cfg.FileCheckInterval
+		dst.FileCheckInterval = cfg.FileCheckInterval
 	}
 	if cfg.PortCheckInterval > 0 {
-		a.config.PortCheckInterval = cfg.PortCheckInterval
+		dst.PortCheckInterval = cfg.PortCheckInterval
+	}
+	if cfg.ScoreWeightCritical > 0 {
+		dst.ScoreWeightCritical = cfg.ScoreWeightCritical
+	}
+	if cfg.ScoreWeightHigh > 0 {
+		dst.ScoreWeightHigh = cfg.ScoreWeightHigh
+	}
+	if cfg.ScoreWeightMedium > 0 {
+		dst.ScoreWeightMedium = cfg.ScoreWeightMedium
+	}
+	if cfg.ScoreWeightLow > 0 {
+		dst.ScoreWeightLow = cfg.ScoreWeightLow
 	}
 	// 进程级别阈值（支持设为0以禁用检测）
-	a.config.ProcCPUThreshold = cfg.ProcCPUThreshold
-	a.config.ProcMemoryThreshold = cfg.ProcMemoryThreshold
-	a.config.ProcMemGrowthThreshold = cfg.ProcMemGrowthThreshold
-	a.config.ProcVMSThreshold = cfg.ProcVMSThreshold
-	a.config.ProcFDsThreshold = cfg.ProcFDsThreshold
-	a.config.ProcThreadsThreshold = cfg.ProcThreadsThreshold
-	a.config.ProcOpenFilesThreshold = cfg.ProcOpenFilesThreshold
-	a.config.ProcDiskReadThreshold = cfg.ProcDiskReadThreshold
-	a.config.ProcDiskWriteThreshold = cfg.ProcDiskWriteThreshold
-	a.config.ProcNetRecvThreshold = cfg.ProcNetRecvThreshold
-	a.config.ProcNetSendThreshold = cfg.ProcNetSendThreshold
-	
-	logger.Infof("IMPACT", "Config updated: SysCPU=%.0f%%, SysMem=%.0f%%, ProcCPU=%.0f%%, ProcMem=%.0fMB",
-		a.config.CPUThreshold, a.config.MemoryThreshold, a.config.ProcCPUThreshold, a.config.ProcMemoryThreshold)
+	dst.ProcCPUThreshold = cfg.ProcCPUThreshold
+	dst.ProcMemoryThreshold = cfg.ProcMemoryThreshold
+	dst.ProcMemGrowthThreshold = cfg.ProcMemGrowthThreshold
+	dst.ProcVMSThreshold = cfg.ProcVMSThreshold
+	dst.ProcFDsThreshold = cfg.ProcFDsThreshold
+	dst.ProcFDGrowthThreshold = cfg.ProcFDGrowthThreshold
+	dst.ProcThreadsThreshold = cfg.ProcThreadsThreshold
+	dst.ProcOpenFilesThreshold = cfg.ProcOpenFilesThreshold
+	dst.ProcDiskReadThreshold = cfg.ProcDiskReadThreshold
+	dst.ProcDiskWriteThreshold = cfg.ProcDiskWriteThreshold
+	dst.ProcNetRecvThreshold = cfg.ProcNetRecvThreshold
+	dst.ProcNetSendThreshold = cfg.ProcNetSendThreshold
+	// 单核饱和阈值（0 表示禁用核心争用检测）
+	dst.CPUCoreThreshold = cfg.CPUCoreThreshold
+	// 进程启停频率阈值（0 表示禁用）
+	dst.ChurnThreshold = cfg.ChurnThreshold
+	dst.StealThreshold = cfg.StealThreshold
+	dst.VirtRunWaitThreshold = cfg.VirtRunWaitThreshold
+	// 僵尸子进程数阈值（0 表示禁用）
+	dst.ZombieThreshold = cfg.ZombieThreshold
+	// 趋势预测（窗口为 0 表示禁用）
+	dst.TrendWindowSeconds = cfg.TrendWindowSeconds
+	dst.TrendHorizonMinutes = cfg.TrendHorizonMinutes
+	dst.TrendLimitPercent = cfg.TrendLimitPercent
+	dst.TrendDiskPaths = cfg.TrendDiskPaths
+	// 目标目录占用（0 表示禁用）
+	dst.DirGrowthThreshold = cfg.DirGrowthThreshold
+	dst.DirMinFreeMB = cfg.DirMinFreeMB
+	// 持续时间要求（0 表示立即产生/解除）
+	dst.MinDurationSeconds = cfg.MinDurationSeconds
+	dst.MinDurationOverrides = cfg.MinDurationOverrides
+	dst.ClearDurationSeconds = cfg.ClearDurationSeconds
+	// 日志冷却（0 表示每次产生都记录）
+	dst.LogCooldownSeconds = cfg.LogCooldownSeconds
+	dst.IgnoreLoopbackPorts = cfg.IgnoreLoopbackPorts
+	dst.ExcludeSelf = cfg.ExcludeSelf
+	dst.ExcludeMappedVMS = cfg.ExcludeMappedVMS
+	dst.Profiles = cfg.Profiles
+	dst.MaintenanceWindows = cfg.MaintenanceWindows
 }
 
 // GetConfig 获取当前配置
@@ -223,20 +441,83 @@ func (a *ImpactAnalyzer) SetEventCallback(cb EventCallback) {
 	a.eventCallback = cb
 }
 
+// SetClock 替换时间来源（测试或回放时使用手动推进的时钟），需在 Start 之前调用
+func (a *ImpactAnalyzer) SetClock(c clock.Clock) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.clock = c
+}
+
+// SetConnectionSource 替换端口冲突检测读取的连接表（测试时使用 provider.Fake.Connections），需在 Start 之前调用
+func (a *ImpactAnalyzer) SetConnectionSource(fn func() ([]net.ConnectionStat, error)) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.portChecker.connections = fn
+}
+
+// SetMaintenanceChecker 设置维护模式判断函数
+func (a *ImpactAnalyzer) SetMaintenanceChecker(fn func(targetPID int32) bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.inMaintenance = fn
+}
+
+// SetChurnSource 设置进程启停频率来源
+func (a *ImpactAnalyzer) SetChurnSource(fn func() types.ProcessChurn) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.churnSource = fn
+}
+
+// SuppressTarget 将目标现有的影响事件标记为已抑制，targetPID 为 0 表示所有目标
+func (a *ImpactAnalyzer) SuppressTarget(targetPID int32) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for key, imp := range a.activeImpacts {
+		if targetPID == 0 || key.TargetPID == targetPID {
+			imp.Suppressed = true
+		}
+	}
+}
+
+// SubscribeImpacts 订阅新影响事件的实时推送
+func (a *ImpactAnalyzer) SubscribeImpacts(bufSize int) (<-chan types.ImpactEvent, func()) {
+	return a.impactStream.Subscribe(bufSize)
+}
+
+// SubscribeResolvedImpacts 订阅影响解除的实时推送，推送的是解除前的影响事件（ID 与新影响推送时一致）
+func (a *ImpactAnalyzer) SubscribeResolvedImpacts(bufSize int) (<-chan types.ImpactEvent, func()) {
+	return a.resolvedStream.Subscribe(bufSize)
+}
+
 // GetRecentImpacts 获取活跃的影响事件
-func (a *ImpactAnalyzer) GetRecentImpacts(n int) []types.ImpactEvent {
-	a.mu.RLock()
-	defer a.mu.RUnlock()
+// grouped 为 true 时同一目标、同一类型的多个影响源合并为一条，n 按合并后的条数计算
+func (a *ImpactAnalyzer) GetRecentImpacts(n int, grouped bool) []types.ImpactEvent {
+	return a.GetRecentImpactsMin(n, grouped, "")
+}
 
+// GetRecentImpactsMin 获取严重级别不低于 minSeverity 的活跃影响事件（为空表示全部）
+// 先按级别过滤再合并，合并条目只包含满足级别的影响源
+func (a *ImpactAnalyzer) GetRecentImpactsMin(n int, grouped bool, minSeverity string) []types.ImpactEvent {
+	a.mu.RLock()
 	result := make([]types.ImpactEvent, 0, len(a.activeImpacts))
 	for _, imp := range a.activeImpacts {
-		result = append(result, *imp)
+		if !AtLeastSeverity(imp.Severity, minSeverity) {
+			continue
+		}
+		evt := *imp
+		evt.History = nil // 指标历史仅在按 ID 查询时返回
+		result = append(result, evt)
 	}
+	a.mu.RUnlock()
 
 	// 按时间排序（最新的在后）
 	sort.Slice(result, func(i, j int) bool {
 		return result[i].Timestamp.Before(result[j].Timestamp)
 	})
+	if grouped {
+		result = groupImpacts(result)
+	}
 
 	if n > 0 && len(result) > n {
 		result = result[len(result)-n:]
@@ -244,27 +525,44 @@ func (a *ImpactAnalyzer) GetRecentImpacts(n int) []types.ImpactEvent {
 	return result
 }
 
-// GetImpactSummary 获取影响统计摘要
-func (a *ImpactAnalyzer) GetImpactSummary() map[string]interface{} {
+// ActiveImpactCountByTarget 按目标 PID 统计活跃影响事件数
+func (a *ImpactAnalyzer) ActiveImpactCountByTarget() map[int32]int {
 	a.mu.RLock()
 	defer a.mu.RUnlock()
 
+	result := make(map[int32]int)
+	for key, imp := range a.activeImpacts {
+		if !imp.Suppressed {
+			result[key.TargetPID]++
+		}
+	}
+	return result
+}
+
+// GetImpactSummary 获取影响统计摘要
+// grouped 为 true 时按合并后的条目统计（合并条目取最高严重级别），健康评分始终按单个影响事件计算
+func (a *ImpactAnalyzer) GetImpactSummary(grouped bool) map[string]interface{} {
+	impacts := a.GetRecentImpacts(0, grouped)
+	score := a.HealthScore()
+
 	// 按类型统计
 	byType := make(map[string]int)
 	bySeverity := make(map[string]int)
 	byTarget := make(map[string]int)
 
-	for _, imp := range a.activeImpacts {
+	for _, imp := range impacts {
 		byType[imp.ImpactType]++
 		bySeverity[imp.Severity]++
 		byTarget[imp.TargetName]++
 	}
 
 	return map[string]interface{}{
-		"total":       len(a.activeImpacts),
+		"total":       len(impacts),
 		"by_type":     byType,
 		"by_severity": bySeverity,
 		"by_target":   byTarget,
+		"score":       score,
+		"grade":       HealthGrade(score),
 	}
 }
 
@@ -273,11 +571,7 @@ func (a *ImpactAnalyzer) RemoveTargetEvents(targetPID int32) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
-	for key := range a.activeImpacts {
-		if key.TargetPID == targetPID {
-			delete(a.activeImpacts, key)
-		}
-	}
+	a.forgetLocked(func(key impactKey) bool { return key.TargetPID == targetPID })
 	logger.Infof("IMPACT", "Removed impact events for target PID %d", targetPID)
 }
 
@@ -286,6 +580,10 @@ func (a *ImpactAnalyzer) ClearAllEvents() {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 	a.activeImpacts = make(map[impactKey]*types.ImpactEvent)
+	a.pending = make(map[impactKey]time.Time)
+	a.lastBreach = make(map[impactKey]time.Time)
+	a.lastLogged = make(map[impactKey]loggedImpact)
+	a.resolved = nil
 }
 
 // ClearImpacts 清除所有影响事件（CLI使用，与ClearAllEvents相同）
@@ -293,49 +591,165 @@ func (a *ImpactAnalyzer) ClearImpacts() {
 	a.ClearAllEvents()
 }
 
-func (a *ImpactAnalyzer) loop() {
-	ticker := time.NewTicker(time.Duration(a.config.AnalysisInterval) * time.Second)
+func (a *ImpactAnalyzer) loop(interval time.Duration, stopCh chan struct{}) {
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	for {
 		select {
-		case <-a.stopCh:
+		case <-stopCh:
 			return
 		case <-ticker.C:
-			a.analyze()
+			a.tick()
 		}
 	}
 }
 
 func (a *ImpactAnalyzer) analyze() {
+	now := a.clock.Now()
+	defer a.finishCycle(now)
+
+	// 按当前时间选择生效的阈值时段。本周期的各项分析都使用此时的快照，
+	// 周期中途 UpdateConfig 重新计算 a.effective 不影响正在进行的分析
+	a.mu.Lock()
+	prevProfile := a.activeProfileName
+	a.refreshActiveProfileLocked(now)
+	if a.activeProfileName != prevProfile {
+		logger.Infof("IMPACT", "Threshold profile switched: %q -> %q", prevProfile, a.activeProfileName)
+	}
+	effective := a.effective
+	prevWindow, window := a.refreshMaintenanceWindowLocked(now)
+	a.mu.Unlock()
+	a.reportMaintenanceWindow(prevWindow, window)
+
 	targets := a.targets()
 	if len(targets) == 0 {
 		// 没有监控目标，清除所有事件
-		a.mu.Lock()
-		a.activeImpacts = make(map[impactKey]*types.ImpactEvent)
-		a.mu.Unlock()
+		a.ClearAllEvents()
 		return
 	}
+	a.beginCycle(now, effective)
+	a.refreshTargetNotes(targets)
+
+	in, ok := a.collect(effective, targets, now)
+	if !ok {
+		return
+	}
+	procMap, targetPIDSet, excludePIDSet := a.evaluate(effective, in)
+	a.replay.Push(in.compact())
+
+	// 低频检测：文件和端口冲突（依赖实时连接和文件状态，不参与模拟回放）
+	if now.Sub(a.lastPortCheck) >= time.Duration(effective.PortCheckInterval)*time.Second {
+		a.analyzePortConflict(effective, targets, procMap, excludePIDSet)
+		a.lastPortCheck = now
+	}
+	if now.Sub(a.lastFileCheck) >= time.Duration(effective.FileCheckInterval)*time.Second {
+		a.analyzeFileConflict(targets, procMap, excludePIDSet)
+		a.analyzeDirUsage(effective, in.sys, targets, procMap)
+		a.lastFileCheck = now
+	}
+
+	// 解除本周期不再突破的影响
+	a.settleImpacts(effective, a.clock.Now())
+
+	// 清理已不存在的目标的事件
+	a.cleanupOrphanedEvents(targetPIDSet)
+}
+
+// collect 采集本周期的分析输入：系统指标、进程列表、进程启停频率和趋势预测的磁盘使用率，effective 为本周期的配置快照
+func (a *ImpactAnalyzer) collect(effective types.ImpactConfig, targets []types.MonitorTarget, now time.Time) (cycleInput, bool) {
+	in := cycleInput{at: now, targets: targets}
 
 	// 获取系统指标
 	sysMetrics, err := a.provider.GetSystemMetrics()
+	a.setCheckError(checkSystem, err)
 	if err != nil {
 		logger.Warnf("IMPACT", "Get system metrics failed: %v", err)
-		return
+		return in, false
 	}
+	in.sys = sysMetrics
 
 	// 获取所有进程
-	processes, err := a.getProcesses()
+	in.procs, err = a.getProcesses()
+	a.setCheckError(checkProcesses, err)
 	if err != nil {
 		logger.Warnf("IMPACT", "List processes failed: %v", err)
+		return in, false
+	}
+	a.scratch.processes = len(in.procs)
+	a.fillAnonVMS(effective, in.procs, targets)
+	in.procs = a.appendCachedTargets(effective, in.procs, targets, now)
+
+	// 启停频率来自进程列表采样，需在获取进程列表之后读取
+	a.mu.RLock()
+	source := a.churnSource
+	a.mu.RUnlock()
+	if source != nil {
+		churn := source()
+		in.churn = &churn
+	}
+	if effective.TrendWindowSeconds > 0 {
+		in.disk = sampleDisks(trendDiskPaths(effective.TrendDiskPaths))
+	}
+	in.runWait = a.targetRunWait(targets)
+	return in, true
+}
+
+// fillAnonVMS 启用 exclude_mapped_vms 时，为完整 VMS 达到虚拟内存阈值的进程读取匿名虚拟内存。
+// 匿名部分不超过完整 VMS，未达到阈值的进程无需读取；读取失败时保持为 0，按完整 VMS 比较
+func (a *ImpactAnalyzer) fillAnonVMS(effective types.ImpactConfig, procs []types.ProcessInfo, targets []types.MonitorTarget) {
+	minThreshold := 0.0
+	for _, t := range targets {
+		cfg := targetConfig(effective, t)
+		if cfg.ExcludeMappedVMS && cfg.ProcVMSThreshold > 0 && (minThreshold == 0 || cfg.ProcVMSThreshold < minThreshold) {
+			minThreshold = cfg.ProcVMSThreshold
+		}
+	}
+	if minThreshold == 0 {
 		return
 	}
+	limit := minThreshold * 1024 * 1024 // MB -> B
+	for i := range procs {
+		if float64(procs[i].VMS) < limit {
+			continue
+		}
+		if anon, err := a.provider.GetAnonVMS(procs[i].PID); err == nil {
+			procs[i].AnonVMS = anon
+		}
+	}
+}
+
+// targetRunWait 读取各目标最近一次采样的可运行等待 CPU 占比（来自监控器的指标历史，不持有 mu 调用）
+func (a *ImpactAnalyzer) targetRunWait(targets []types.MonitorTarget) map[int32]float64 {
+	a.mu.RLock()
+	source := a.metricsSource
+	a.mu.RUnlock()
+	if source == nil {
+		return nil
+	}
+	runWait := make(map[int32]float64)
+	for _, t := range targets {
+		if latest := source(t.PID, 1); len(latest) > 0 && latest[0].RunWaitPct != nil {
+			runWait[t.PID] = *latest[0].RunWaitPct
+		}
+	}
+	return runWait
+}
+
+// evaluate 按本周期的配置快照 effective 评估一个周期的输入，突破的影响交给 a.emit（实时分析为 recordImpact，模拟时只计数）。
+// 返回 PID 映射、目标 PID 集合和不作为影响来源的 PID 集合
+func (a *ImpactAnalyzer) evaluate(effective types.ImpactConfig, in cycleInput) (map[int32]*types.ProcessInfo, map[int32]bool, map[int32]bool) {
+	sysMetrics, processes, targets := in.sys, in.procs, in.targets
+	a.topMu.Lock()
+	a.topCache = make(map[topKey][]types.ProcessInfo)
+	a.topMu.Unlock()
 
 	// 创建 PID -> ProcessInfo 映射
 	procMap := make(map[int32]*types.ProcessInfo)
 	for i := range processes {
 		procMap[processes[i].PID] = &processes[i]
 	}
+	a.cycleProcs = procMap
 
 	// 创建目标 PID 集合
 	targetPIDSet := make(map[int32]bool)
@@ -343,68 +757,83 @@ func (a *ImpactAnalyzer) analyze() {
 		targetPIDSet[t.PID] = true
 	}
 
-	// 分析各类影响（瞬时指标，每次先清除旧的同类型事件）
-	a.analyzeCPU(sysMetrics, processes, targets, procMap, targetPIDSet)
-	a.analyzeMemory(sysMetrics, processes, targets, procMap, targetPIDSet)
-	a.analyzeDiskIO(sysMetrics, processes, targets, procMap, targetPIDSet)
-	a.analyzeNetwork(sysMetrics, processes, targets, procMap, targetPIDSet)
-	a.analyzeOtherMetrics(sysMetrics, processes, targets, procMap, targetPIDSet)
-
-	// 低频检测：文件和端口冲突（动态维护）
-	now := time.Now()
-	if now.Sub(a.lastPortCheck) >= time.Duration(a.config.PortCheckInterval)*time.Second {
-		a.analyzePortConflict(targets, procMap, targetPIDSet)
-		a.lastPortCheck = now
-	}
-	if now.Sub(a.lastFileCheck) >= time.Duration(a.config.FileCheckInterval)*time.Second {
-		a.analyzeFileConflict(targets, procMap, targetPIDSet)
-		a.lastFileCheck = now
+	// 排除的影响来源：目标自身，启用 ExcludeSelf 时还有本程序及其子进程
+	excludePIDSet := targetPIDSet
+	if effective.ExcludeSelf {
+		excludePIDSet = make(map[int32]bool, len(targetPIDSet)+1)
+		for pid := range targetPIDSet {
+			excludePIDSet[pid] = true
+		}
+		for pid := range selfPIDs(processes, targetPIDSet) {
+			excludePIDSet[pid] = true
+		}
 	}
 
-	// 清理已不存在的目标的事件
-	a.cleanupOrphanedEvents(targetPIDSet)
+	// 分析各类影响（瞬时指标，本周期不再突破的影响在 settleImpacts 中解除）。
+	// 各项分析只读输入，结果经 emit 在锁内合并，可按 analysis_workers 并发执行
+	a.runPasses(effective.AnalysisWorkers, []func(){
+		func() { a.analyzeCPU(effective, sysMetrics, processes, targets, procMap, excludePIDSet) },
+		func() { a.analyzeCPUCore(effective, sysMetrics, processes, targets, procMap, excludePIDSet) },
+		func() { a.analyzeMemory(effective, sysMetrics, processes, targets, procMap, excludePIDSet) },
+		func() { a.analyzeDiskIO(effective, sysMetrics, processes, targets, procMap, excludePIDSet) },
+		func() { a.analyzeNetwork(effective, sysMetrics, processes, targets, procMap, excludePIDSet) },
+		func() { a.analyzeOtherMetrics(effective, sysMetrics, processes, targets, procMap, excludePIDSet) },
+		func() { a.analyzePriority(sysMetrics, targets, procMap) },
+		func() { a.analyzeChurn(effective, sysMetrics, in.churn, targets, procMap) },
+		func() { a.analyzeSteal(effective, sysMetrics, targets, procMap) },
+		func() { a.analyzeVirtContention(effective, sysMetrics, in.runWait, targets, procMap) },
+		func() { a.analyzeZombies(effective, sysMetrics, processes, targets, procMap) },
+		func() { a.analyzeTrend(effective, sysMetrics, in.disk, targets, procMap) },
+	})
+	return procMap, targetPIDSet, excludePIDSet
 }
 
-// cleanupOrphanedEvents 清理已不存在的目标的事件
-func (a *ImpactAnalyzer) cleanupOrphanedEvents(targetPIDSet map[int32]bool) {
-	a.mu.Lock()
-	defer a.mu.Unlock()
-
-	for key := range a.activeImpacts {
-		if !targetPIDSet[key.TargetPID] {
-			delete(a.activeImpacts, key)
+// selfPID 本程序的 PID
+var selfPID = int32(os.Getpid())
+
+// selfPIDs 本程序及其子进程（按进程列表的父子关系逐级查找）。
+// 监控目标及其子进程不计入，即使目标是由本程序启动的
+func selfPIDs(procs []types.ProcessInfo, targetPIDSet map[int32]bool) map[int32]bool {
+	children := make(map[int32][]int32)
+	for i := range procs {
+		children[procs[i].PPID] = append(children[procs[i].PPID], procs[i].PID)
+	}
+	pids := map[int32]bool{selfPID: true}
+	queue := []int32{selfPID}
+	for len(queue) > 0 {
+		pid := queue[0]
+		queue = queue[1:]
+		for _, child := range children[pid] {
+			if pids[child] || targetPIDSet[child] {
+				continue
+			}
+			pids[child] = true
+			queue = append(queue, child)
 		}
 	}
+	return pids
 }
 
-// clearEventsByType 清除特定类型的所有事件
-func (a *ImpactAnalyzer) clearEventsByType(impactType string) {
+// cleanupOrphanedEvents 清理已不存在的目标的事件
+func (a *ImpactAnalyzer) cleanupOrphanedEvents(targetPIDSet map[int32]bool) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
-
-	for key := range a.activeImpacts {
-		if key.ImpactType == impactType {
-			delete(a.activeImpacts, key)
-		}
-	}
+	a.forgetLocked(func(key impactKey) bool { return !targetPIDSet[key.TargetPID] })
 }
 
 // analyzeCPU 分析 CPU 竞争
 func (a *ImpactAnalyzer) analyzeCPU(
+	effective types.ImpactConfig,
 	sys *types.SystemMetrics,
 	procs []types.ProcessInfo,
 	targets []types.MonitorTarget,
 	procMap map[int32]*types.ProcessInfo,
 	targetPIDSet map[int32]bool,
 ) {
-	// 先清除旧的 CPU 事件
-	a.clearEventsByType("cpu")
-
-	// 检查是否触发系统级别阈值
-	systemTriggered := sys.CPUPercent >= a.config.CPUThreshold
+	a.beginPass("cpu")
 
 	// 获取 Top N CPU 消耗进程
-	topCPU := a.getTopByField(procs, "cpu", a.config.TopNProcesses)
+	topCPU := a.getTopByField(procs, "cpu", effective.TopNProcesses)
 
 	// 找出非目标的 CPU 消耗者
 	for _, target := range targets {
@@ -412,23 +841,30 @@ func (a *ImpactAnalyzer) analyzeCPU(
 		if targetProc == nil {
 			continue
 		}
+		cfg := targetConfig(effective, target)
 
-		for _, proc := range topCPU {
+		// 检查是否触发系统级别阈值
+		systemTriggered := sys.CPUPercent >= cfg.CPUThreshold
+
+		overProc := func(p *types.ProcessInfo) bool {
+			return cfg.ProcCPUThreshold > 0 && p.CPUPct >= cfg.ProcCPUThreshold
+		}
+		for _, proc := range impactCandidates(procs, topCPU, systemTriggered, overProc) {
 			// 跳过目标自身
 			if targetPIDSet[proc.PID] {
 				continue
 			}
 
 			// 检查是否触发进程级别阈值
-			processTriggered := a.config.ProcCPUThreshold > 0 && proc.CPUPct >= a.config.ProcCPUThreshold
+			processTriggered := cfg.ProcCPUThreshold > 0 && proc.CPUPct >= cfg.ProcCPUThreshold
 
 			// 如果系统级别和进程级别都未触发，跳过
 			if !systemTriggered && !processTriggered {
 				continue
 			}
 
-			// 如果是系统级别触发，还需要进程 CPU > 10%
-			if systemTriggered && !processTriggered && proc.CPUPct < 10 {
+			// 如果是系统级别触发，还需要进程占整机 CPU > 10%
+			if systemTriggered && !processTriggered && a.machineCPUPct(proc.CPUPct, len(sys.CPUPerCore)) < 10 {
 				continue
 			}
 
@@ -437,16 +873,16 @@ func (a *ImpactAnalyzer) analyzeCPU(
 			var description string
 			if processTriggered {
 				// 进程级别触发
-				severity = a.getProcessSeverity(proc.CPUPct, a.config.ProcCPUThreshold)
-				description = fmt.Sprintf("进程 %s (PID %d) CPU 占用 %.1f%% 超过阈值 %.0f%%", proc.Name, proc.PID, proc.CPUPct, a.config.ProcCPUThreshold)
+				severity = a.getProcessSeverity(proc.CPUPct, cfg.ProcCPUThreshold)
+				description = i18n.T("impact.cpu.proc", procDisplayName(&proc), proc.PID, proc.CPUPct, cfg.ProcCPUThreshold)
 			} else {
 				// 系统级别触发
 				severity = a.getSeverity(sys.CPUPercent, 80, 90, 95)
-				description = fmt.Sprintf("系统 CPU %.1f%% 超过阈值，进程 %s (PID %d) 占用 %.1f%%", sys.CPUPercent, proc.Name, proc.PID, proc.CPUPct)
+				description = i18n.T("impact.cpu.system", sys.CPUPercent, procDisplayName(&proc), proc.PID, proc.CPUPct)
 			}
 
 			event := types.ImpactEvent{
-				Timestamp:   time.Now(),
+				Timestamp:   a.cycleStart,
 				TargetPID:   target.PID,
 				TargetName:  a.getTargetDisplayName(target),
 				ImpactType:  "cpu",
@@ -462,45 +898,224 @@ func (a *ImpactAnalyzer) analyzeCPU(
 					SourceCPU:    proc.CPUPct,
 					SourceMemory: proc.RSSBytes,
 				},
-				Suggestion: a.getCPUSuggestion(severity, proc.Name, proc.CPUPct),
+				Suggestion: a.getCPUSuggestion(severity, procDisplayName(&proc), proc.CPUPct),
+			}
+			a.emit(event, "")
+		}
+	}
+}
+
+// analyzeCPUCore 分析 CPU 核心争用
+// 系统总 CPU 未超阈值时，某个非目标进程仍可能占满目标亲和性范围内的核心。
+// 任一进程亲和性无法读取时跳过该进程，由 analyzeCPU 的总量检测兜底。
+func (a *ImpactAnalyzer) analyzeCPUCore(
+	effective types.ImpactConfig,
+	sys *types.SystemMetrics,
+	procs []types.ProcessInfo,
+	targets []types.MonitorTarget,
+	procMap map[int32]*types.ProcessInfo,
+	targetPIDSet map[int32]bool,
+) {
+	a.beginPass("cpu_core")
+
+	numCores := len(sys.CPUPerCore)
+	if numCores == 0 {
+		return
+	}
+
+	// 目标可自定义核心阈值，候选进程按所有目标中最低的阈值预筛选
+	minThreshold := 0.0
+	for _, target := range targets {
+		if t := targetConfig(effective, target).CPUCoreThreshold; t > 0 && (minThreshold == 0 || t < minThreshold) {
+			minThreshold = t
+		}
+	}
+	if minThreshold <= 0 {
+		return
+	}
+	anySaturated := false
+	for _, pct := range sys.CPUPerCore {
+		if pct >= minThreshold {
+			anySaturated = true
+			break
+		}
+	}
+	if !anySaturated {
+		return
+	}
+
+	// 找出占满至少一个核心的非目标进程（进程 CPU% 统一换算为单核占比）。
+	// 核心阈值是逐个进程的比较，对全部进程评估，只为超过阈值的进程读取亲和性
+	type coreHog struct {
+		proc     types.ProcessInfo
+		corePct  float64
+		affinity []int
+	}
+	var hogs []coreHog
+	for _, proc := range procs {
+		if targetPIDSet[proc.PID] {
+			continue
+		}
+		corePct := a.coreCPUPct(proc.CPUPct, numCores)
+		if corePct < minThreshold {
+			continue
+		}
+		affinity, err := a.provider.GetCPUAffinity(proc.PID)
+		if err != nil {
+			continue
+		}
+		hogs = append(hogs, coreHog{proc: proc, corePct: corePct, affinity: affinity})
+	}
+	if len(hogs) == 0 {
+		return
+	}
+
+	for _, target := range targets {
+		targetProc := procMap[target.PID]
+		if targetProc == nil {
+			continue
+		}
+		threshold := targetConfig(effective, target).CPUCoreThreshold
+		if threshold <= 0 {
+			continue
+		}
+		targetAffinity, err := a.provider.GetCPUAffinity(target.PID)
+		if err != nil {
+			continue
+		}
+
+		// 按目标阈值判断饱和核心
+		saturated := make(map[int]bool)
+		for i, pct := range sys.CPUPerCore {
+			if pct >= threshold {
+				saturated[i] = true
+			}
+		}
+
+		// 目标可用核心中已饱和的核心
+		targetCores := make(map[int]bool, len(targetAffinity))
+		hotCount := 0
+		for _, c := range targetAffinity {
+			targetCores[c] = true
+			if saturated[c] {
+				hotCount++
+			}
+		}
+		if hotCount == 0 {
+			continue
+		}
+
+		for _, hog := range hogs {
+			if hog.corePct < threshold {
+				continue
+			}
+			var shared []string
+			maxPct := 0.0
+			for _, c := range hog.affinity {
+				if !targetCores[c] || !saturated[c] {
+					continue
+				}
+				shared = append(shared, fmt.Sprintf("%d", c))
+				if c < numCores && sys.CPUPerCore[c] > maxPct {
+					maxPct = sys.CPUPerCore[c]
+				}
+			}
+			if len(shared) == 0 {
+				continue
+			}
+
+			// 目标可用核心全部饱和为严重，核心已满载为高
+			severity := "medium"
+			if hotCount == len(targetAffinity) {
+				severity = "critical"
+			} else if maxPct >= 99 {
+				severity = "high"
+			}
+
+			event := types.ImpactEvent{
+				Timestamp:  a.cycleStart,
+				TargetPID:  target.PID,
+				TargetName: a.getTargetDisplayName(target),
+				ImpactType: "cpu_core",
+				Severity:   severity,
+				SourcePID:  hog.proc.PID,
+				SourceName: hog.proc.Name,
+				Description: i18n.T("impact.cpu_core.desc",
+					procDisplayName(&hog.proc), hog.proc.PID, hog.corePct, strings.Join(shared, ","), hotCount, len(targetAffinity)),
+				Metrics: types.ImpactMetrics{
+					SystemCPU:    sys.CPUPercent,
+					SystemMemory: sys.MemoryPercent,
+					TargetCPU:    targetProc.CPUPct,
+					TargetMemory: targetProc.RSSBytes,
+					SourceCPU:    hog.proc.CPUPct,
+					SourceMemory: hog.proc.RSSBytes,
+				},
+				Suggestion: i18n.T("impact.cpu_core.suggestion", procDisplayName(&hog.proc), strings.Join(shared, ",")),
 			}
-			a.recordImpact(event, "")
+			a.emit(event, "")
 		}
 	}
 }
 
+// coreCPUPct 将进程 CPU% 换算为单核口径（irix，单核满载 100%）
+// 进程级阈值与 provider 当前口径一致，无需换算；仅在需要固定口径比较时使用
+func (a *ImpactAnalyzer) coreCPUPct(procPct float64, numCores int) float64 {
+	if a.provider.GetCPUStyle() == provider.CPUStyleIrix {
+		return procPct
+	}
+	if numCores <= 0 {
+		numCores = runtime.NumCPU()
+	}
+	return procPct * float64(numCores)
+}
+
+// machineCPUPct 将进程 CPU% 换算为整机口径（solaris，最大 100%）
+func (a *ImpactAnalyzer) machineCPUPct(procPct float64, numCores int) float64 {
+	if a.provider.GetCPUStyle() == provider.CPUStyleSolaris {
+		return procPct
+	}
+	if numCores <= 0 {
+		numCores = runtime.NumCPU()
+	}
+	return procPct / float64(numCores)
+}
+
 // analyzeMemory 分析内存压力
 func (a *ImpactAnalyzer) analyzeMemory(
+	effective types.ImpactConfig,
 	sys *types.SystemMetrics,
 	procs []types.ProcessInfo,
 	targets []types.MonitorTarget,
 	procMap map[int32]*types.ProcessInfo,
 	targetPIDSet map[int32]bool,
 ) {
-	// 先清除旧的 memory 事件
-	a.clearEventsByType("memory")
-
-	// 检查是否触发系统级别阈值
-	systemTriggered := sys.MemoryPercent >= a.config.MemoryThreshold
-	// 进程内存阈值转换为字节
-	procMemThreshold := a.config.ProcMemoryThreshold * 1024 * 1024
+	a.beginPass("memory")
 
 	// 获取 Top N 内存消耗进程
-	topMem := a.getTopByField(procs, "memory", a.config.TopNProcesses)
+	topMem := a.getTopByField(procs, "memory", effective.TopNProcesses)
 
 	for _, target := range targets {
 		targetProc := procMap[target.PID]
 		if targetProc == nil {
 			continue
 		}
+		cfg := targetConfig(effective, target)
+
+		// 检查是否触发系统级别阈值（内存读取失败时不按系统内存判断）
+		systemTriggered := !sys.IsDegraded(types.SubsystemMemory) && sys.MemoryPercent >= cfg.MemoryThreshold
+		// 进程内存阈值转换为字节
+		procMemThreshold := cfg.ProcMemoryThreshold * 1024 * 1024
 
-		for _, proc := range topMem {
+		overProc := func(p *types.ProcessInfo) bool {
+			return cfg.ProcMemoryThreshold > 0 && float64(p.RSSBytes) >= procMemThreshold
+		}
+		for _, proc := range impactCandidates(procs, topMem, systemTriggered, overProc) {
 			if targetPIDSet[proc.PID] {
 				continue
 			}
 
 			// 检查是否触发进程级别阈值
-			processTriggered := a.config.ProcMemoryThreshold > 0 && float64(proc.RSSBytes) >= procMemThreshold
+			processTriggered := cfg.ProcMemoryThreshold > 0 && float64(proc.RSSBytes) >= procMemThreshold
 
 			// 如果系统级别和进程级别都未触发，跳过
 			if !systemTriggered && !processTriggered {
@@ -518,15 +1133,15 @@ func (a *ImpactAnalyzer) analyzeMemory(
 			if processTriggered {
 				// 进程级别触发
 				severity = a.getProcessSeverity(float64(proc.RSSBytes), procMemThreshold)
-				description = fmt.Sprintf("进程 %s (PID %d) 内存占用 %s 超过阈值 %.0f MB", proc.Name, proc.PID, formatBytes(proc.RSSBytes), a.config.ProcMemoryThreshold)
+				description = i18n.T("impact.memory.proc", procDisplayName(&proc), proc.PID, format.Bytes(proc.RSSBytes), cfg.ProcMemoryThreshold)
 			} else {
 				// 系统级别触发
 				severity = a.getSeverity(sys.MemoryPercent, 85, 92, 98)
-				description = fmt.Sprintf("系统内存 %.1f%% 超过阈值，进程 %s (PID %d) 占用 %s", sys.MemoryPercent, proc.Name, proc.PID, formatBytes(proc.RSSBytes))
+				description = i18n.T("impact.memory.system", sys.MemoryPercent, procDisplayName(&proc), proc.PID, format.Bytes(proc.RSSBytes))
 			}
 
 			event := types.ImpactEvent{
-				Timestamp:   time.Now(),
+				Timestamp:   a.cycleStart,
 				TargetPID:   target.PID,
 				TargetName:  a.getTargetDisplayName(target),
 				ImpactType:  "memory",
@@ -542,50 +1157,55 @@ func (a *ImpactAnalyzer) analyzeMemory(
 					SourceCPU:    proc.CPUPct,
 					SourceMemory: proc.RSSBytes,
 				},
-				Suggestion: a.getMemorySuggestion(severity, proc.Name, proc.RSSBytes, proc.RSSGrowthRate),
+				Suggestion: a.getMemorySuggestion(severity, procDisplayName(&proc), proc.RSSBytes, proc.RSSGrowthRate),
 			}
-			a.recordImpact(event, "")
+			a.emit(event, "")
 		}
 	}
 }
 
 // analyzeDiskIO 分析磁盘 IO 竞争
 func (a *ImpactAnalyzer) analyzeDiskIO(
+	effective types.ImpactConfig,
 	sys *types.SystemMetrics,
 	procs []types.ProcessInfo,
 	targets []types.MonitorTarget,
 	procMap map[int32]*types.ProcessInfo,
 	targetPIDSet map[int32]bool,
 ) {
-	// 先清除旧的 disk_io 事件
-	a.clearEventsByType("disk_io")
+	a.beginPass("disk_io")
 
-	// 系统阈值转换为 B/s
-	systemThreshold := a.config.DiskIOThreshold * 1024 * 1024
 	totalIO := sys.DiskReadRate + sys.DiskWriteRate
-	systemTriggered := totalIO >= systemThreshold
-
-	// 进程阈值转换为 B/s
-	procDiskReadThreshold := a.config.ProcDiskReadThreshold * 1024 * 1024
-	procDiskWriteThreshold := a.config.ProcDiskWriteThreshold * 1024 * 1024
 
 	// 获取 Top N 磁盘 IO 进程
-	topIO := a.getTopByField(procs, "disk_io", a.config.TopNProcesses)
+	topIO := a.getTopByField(procs, "disk_io", effective.TopNProcesses)
 
 	for _, target := range targets {
 		targetProc := procMap[target.PID]
 		if targetProc == nil {
 			continue
 		}
+		cfg := targetConfig(effective, target)
+
+		// 系统阈值转换为 B/s
+		systemTriggered := totalIO >= cfg.DiskIOThreshold*1024*1024
 
-		for _, proc := range topIO {
+		// 进程阈值转换为 B/s
+		procDiskReadThreshold := cfg.ProcDiskReadThreshold * 1024 * 1024
+		procDiskWriteThreshold := cfg.ProcDiskWriteThreshold * 1024 * 1024
+
+		overProc := func(p *types.ProcessInfo) bool {
+			return (cfg.ProcDiskReadThreshold > 0 && p.DiskReadRate >= procDiskReadThreshold) ||
+				(cfg.ProcDiskWriteThreshold > 0 && p.DiskWriteRate >= procDiskWriteThreshold)
+		}
+		for _, proc := range impactCandidates(procs, topIO, systemTriggered, overProc) {
 			if targetPIDSet[proc.PID] {
 				continue
 			}
 
 			// 检查是否触发进程级别阈值（读或写）
-			readTriggered := a.config.ProcDiskReadThreshold > 0 && proc.DiskReadRate >= procDiskReadThreshold
-			writeTriggered := a.config.ProcDiskWriteThreshold > 0 && proc.DiskWriteRate >= procDiskWriteThreshold
+			readTriggered := cfg.ProcDiskReadThreshold > 0 && proc.DiskReadRate >= procDiskReadThreshold
+			writeTriggered := cfg.ProcDiskWriteThreshold > 0 && proc.DiskWriteRate >= procDiskWriteThreshold
 			processTriggered := readTriggered || writeTriggered
 
 			procIO := proc.DiskReadRate + proc.DiskWriteRate
@@ -607,19 +1227,19 @@ func (a *ImpactAnalyzer) analyzeDiskIO(
 				// 进程级别触发
 				if readTriggered {
 					severity = a.getProcessSeverity(proc.DiskReadRate, procDiskReadThreshold)
-					description = fmt.Sprintf("进程 %s (PID %d) 磁盘读 %.1f MB/s 超过阈值 %.0f MB/s", proc.Name, proc.PID, proc.DiskReadRate/1024/1024, a.config.ProcDiskReadThreshold)
+					description = i18n.T("impact.disk_io.read", procDisplayName(&proc), proc.PID, format.BytesRate(proc.DiskReadRate), cfg.ProcDiskReadThreshold)
 				} else {
 					severity = a.getProcessSeverity(proc.DiskWriteRate, procDiskWriteThreshold)
-					description = fmt.Sprintf("进程 %s (PID %d) 磁盘写 %.1f MB/s 超过阈值 %.0f MB/s", proc.Name, proc.PID, proc.DiskWriteRate/1024/1024, a.config.ProcDiskWriteThreshold)
+					description = i18n.T("impact.disk_io.write", procDisplayName(&proc), proc.PID, format.BytesRate(proc.DiskWriteRate), cfg.ProcDiskWriteThreshold)
 				}
 			} else {
 				// 系统级别触发
 				severity = a.getSeverity(totalIO/1024/1024, 100, 200, 500)
-				description = fmt.Sprintf("系统磁盘 IO %.1f MB/s 超过阈值，进程 %s (PID %d) IO 速率 %.1f MB/s", totalIO/1024/1024, proc.Name, proc.PID, procIO/1024/1024)
+				description = i18n.T("impact.disk_io.system", format.BytesRate(totalIO), procDisplayName(&proc), proc.PID, format.BytesRate(procIO))
 			}
 
 			event := types.ImpactEvent{
-				Timestamp:   time.Now(),
+				Timestamp:   a.cycleStart,
 				TargetPID:   target.PID,
 				TargetName:  a.getTargetDisplayName(target),
 				ImpactType:  "disk_io",
@@ -636,50 +1256,55 @@ func (a *ImpactAnalyzer) analyzeDiskIO(
 					SourceMemory: proc.RSSBytes,
 					SourceDiskIO: procIO,
 				},
-				Suggestion: fmt.Sprintf("进程 %s 磁盘 IO 较高，可能导致监控目标 IO 延迟，建议检查该进程的 IO 操作", proc.Name),
+				Suggestion: i18n.T("impact.disk_io.suggestion", procDisplayName(&proc)),
 			}
-			a.recordImpact(event, "")
+			a.emit(event, "")
 		}
 	}
 }
 
 // analyzeNetwork 分析网络带宽竞争
 func (a *ImpactAnalyzer) analyzeNetwork(
+	effective types.ImpactConfig,
 	sys *types.SystemMetrics,
 	procs []types.ProcessInfo,
 	targets []types.MonitorTarget,
 	procMap map[int32]*types.ProcessInfo,
 	targetPIDSet map[int32]bool,
 ) {
-	// 先清除旧的 network 事件
-	a.clearEventsByType("network")
+	a.beginPass("network")
 
-	// 系统阈值转换为 B/s
-	systemThreshold := a.config.NetworkThreshold * 1024 * 1024
 	totalNet := sys.NetRecvRate + sys.NetSendRate
-	systemTriggered := totalNet >= systemThreshold
-
-	// 进程阈值转换为 B/s
-	procNetRecvThreshold := a.config.ProcNetRecvThreshold * 1024 * 1024
-	procNetSendThreshold := a.config.ProcNetSendThreshold * 1024 * 1024
 
 	// 获取 Top N 网络流量进程
-	topNet := a.getTopByField(procs, "network", a.config.TopNProcesses)
+	topNet := a.getTopByField(procs, "network", effective.TopNProcesses)
 
 	for _, target := range targets {
 		targetProc := procMap[target.PID]
 		if targetProc == nil {
 			continue
 		}
+		cfg := targetConfig(effective, target)
+
+		// 系统阈值转换为 B/s
+		systemTriggered := totalNet >= cfg.NetworkThreshold*1024*1024
 
-		for _, proc := range topNet {
+		// 进程阈值转换为 B/s
+		procNetRecvThreshold := cfg.ProcNetRecvThreshold * 1024 * 1024
+		procNetSendThreshold := cfg.ProcNetSendThreshold * 1024 * 1024
+
+		overProc := func(p *types.ProcessInfo) bool {
+			return (cfg.ProcNetRecvThreshold > 0 && p.NetRecvRate >= procNetRecvThreshold) ||
+				(cfg.ProcNetSendThreshold > 0 && p.NetSendRate >= procNetSendThreshold)
+		}
+		for _, proc := range impactCandidates(procs, topNet, systemTriggered, overProc) {
 			if targetPIDSet[proc.PID] {
 				continue
 			}
 
 			// 检查是否触发进程级别阈值（收或发）
-			recvTriggered := a.config.ProcNetRecvThreshold > 0 && proc.NetRecvRate >= procNetRecvThreshold
-			sendTriggered := a.config.ProcNetSendThreshold > 0 && proc.NetSendRate >= procNetSendThreshold
+			recvTriggered := cfg.ProcNetRecvThreshold > 0 && proc.NetRecvRate >= procNetRecvThreshold
+			sendTriggered := cfg.ProcNetSendThreshold > 0 && proc.NetSendRate >= procNetSendThreshold
 			processTriggered := recvTriggered || sendTriggered
 
 			procNet := proc.NetRecvRate + proc.NetSendRate
@@ -701,19 +1326,19 @@ func (a *ImpactAnalyzer) analyzeNetwork(
 				// 进程级别触发
 				if recvTriggered {
 					severity = a.getProcessSeverity(proc.NetRecvRate, procNetRecvThreshold)
-					description = fmt.Sprintf("进程 %s (PID %d) 网络收 %.1f MB/s 超过阈值 %.0f MB/s", proc.Name, proc.PID, proc.NetRecvRate/1024/1024, a.config.ProcNetRecvThreshold)
+					description = i18n.T("impact.network.recv", procDisplayName(&proc), proc.PID, format.BytesRate(proc.NetRecvRate), cfg.ProcNetRecvThreshold)
 				} else {
 					severity = a.getProcessSeverity(proc.NetSendRate, procNetSendThreshold)
-					description = fmt.Sprintf("进程 %s (PID %d) 网络发 %.1f MB/s 超过阈值 %.0f MB/s", proc.Name, proc.PID, proc.NetSendRate/1024/1024, a.config.ProcNetSendThreshold)
+					description = i18n.T("impact.network.send", procDisplayName(&proc), proc.PID, format.BytesRate(proc.NetSendRate), cfg.ProcNetSendThreshold)
 				}
 			} else {
 				// 系统级别触发
 				severity = "medium"
-				description = fmt.Sprintf("系统网络流量 %.1f MB/s 超过阈值，进程 %s (PID %d) 流量 %.1f MB/s", totalNet/1024/1024, proc.Name, proc.PID, procNet/1024/1024)
+				description = i18n.T("impact.network.system", format.BytesRate(totalNet), procDisplayName(&proc), proc.PID, format.BytesRate(procNet))
 			}
 
 			event := types.ImpactEvent{
-				Timestamp:   time.Now(),
+				Timestamp:   a.cycleStart,
 				TargetPID:   target.PID,
 				TargetName:  a.getTargetDisplayName(target),
 				ImpactType:  "network",
@@ -730,31 +1355,31 @@ func (a *ImpactAnalyzer) analyzeNetwork(
 					SourceMemory: proc.RSSBytes,
 					SourceNetIO:  procNet,
 				},
-				Suggestion: fmt.Sprintf("进程 %s 网络流量较高，可能影响监控目标的网络通信", proc.Name),
+				Suggestion: i18n.T("impact.network.suggestion", procDisplayName(&proc)),
 			}
-			a.recordImpact(event, "")
+			a.emit(event, "")
 		}
 	}
 }
 
 // analyzePortConflict 分析端口占用冲突
 // 自动获取监控目标的监听端口，检测其他进程是否尝试连接监控目标的端口
-func (a *ImpactAnalyzer) analyzePortConflict(targets []types.MonitorTarget, procMap map[int32]*types.ProcessInfo, targetPIDSet map[int32]bool) {
+func (a *ImpactAnalyzer) analyzePortConflict(effective types.ImpactConfig, targets []types.MonitorTarget, procMap map[int32]*types.ProcessInfo, targetPIDSet map[int32]bool) {
 	// 每 60 秒更新一次监控目标的监听端口缓存
-	now := time.Now()
+	now := a.clock.Now()
 	if now.Sub(a.targetPortsTime) > 60*time.Second {
-		a.refreshTargetPorts(targets)
+		a.refreshTargetPorts(targets, effective.IgnoreLoopbackPorts)
 		a.targetPortsTime = now
 	}
 
 	// 获取所有网络连接（一次性调用，减少开销）
 	allConns, err := a.portChecker.getAllConnections()
+	a.setCheckError(checkPort, err)
 	if err != nil {
 		return
 	}
-
-	// 收集本次检测到的所有端口冲突 (使用 string key)
-	currentConflicts := make(map[string]bool)
+	a.scratch.connections = len(allConns)
+	a.beginPass("port")
 
 	for _, target := range targets {
 		// 合并配置的 WatchPorts 和 自动发现的监听端口
@@ -765,70 +1390,55 @@ func (a *ImpactAnalyzer) analyzePortConflict(targets []types.MonitorTarget, proc
 
 		// 检查是否有其他进程连接或监听监控目标的端口
 		for _, port := range watchPorts {
-			conflicts := a.findPortConflicts(allConns, port, target.PID, targetPIDSet)
+			conflicts := a.findPortConflicts(allConns, port, a.getTargetListeners(target.PID, port), target.PID, targetPIDSet, effective.IgnoreLoopbackPorts)
 			for _, conflict := range conflicts {
-				conflictKey := fmt.Sprintf("%d-%d-%d", target.PID, conflict.PID, port)
-				currentConflicts[conflictKey] = true
-
 				event := types.ImpactEvent{
-					Timestamp:   time.Now(),
+					Timestamp:   a.cycleStart,
 					TargetPID:   target.PID,
 					TargetName:  a.getTargetDisplayName(target),
 					ImpactType:  "port",
 					Severity:    a.getPortConflictSeverity(conflict.Status),
 					SourcePID:   conflict.PID,
 					SourceName:  conflict.Name,
-					Description: fmt.Sprintf("端口 %d %s，进程 %s (PID %d)", port, a.getPortStatusDesc(conflict.Status), conflict.Name, conflict.PID),
+					Description: i18n.T("impact.port.desc", port, a.getPortStatusDesc(conflict.Status), conflict.Name, conflict.PID),
 					Metrics: types.ImpactMetrics{
 						ConflictPort: port,
 					},
 					Suggestion: a.getPortConflictSuggestion(port, conflict),
 				}
-				a.recordImpact(event, fmt.Sprintf("port:%d", port))
+				a.emit(event, fmt.Sprintf("port:%d", port))
 			}
 		}
 	}
+}
 
-	// 删除不再存在的端口冲突事件
-	a.mu.Lock()
-	var toRemove []impactKey
-	for key := range a.activeImpacts {
-		if key.ImpactType != "port" {
-			continue
-		}
-		// 从 detail 中解析端口号
-		var port int
-		fmt.Sscanf(key.Detail, "port:%d", &port)
-		conflictKey := fmt.Sprintf("%d-%d-%d", key.TargetPID, key.SourcePID, port)
-		if !currentConflicts[conflictKey] {
-			toRemove = append(toRemove, key)
+// refreshTargetPorts 刷新监控目标的监听端口缓存，ignoreLoopback 时不记录仅监听回环地址的端口
+func (a *ImpactAnalyzer) refreshTargetPorts(targets []types.MonitorTarget, ignoreLoopback bool) {
+	a.targetPorts = make(map[int32][]ConnectionInfo)
+	for _, target := range targets {
+		var listeners []ConnectionInfo
+		for _, l := range a.portChecker.GetListeners(target.PID) {
+			// 仅监听回环地址的端口不对外服务，可配置忽略
+			if ignoreLoopback && isLoopbackIP(l.LocalIP) {
+				continue
+			}
+			listeners = append(listeners, l)
 		}
-	}
-	// 先收集要删除的事件
-	removedEvents := make([]*types.ImpactEvent, 0, len(toRemove))
-	for _, key := range toRemove {
-		if evt := a.activeImpacts[key]; evt != nil {
-			removedEvents = append(removedEvents, evt)
+		if len(listeners) > 0 {
+			a.targetPorts[target.PID] = listeners
 		}
-		delete(a.activeImpacts, key)
-	}
-	a.mu.Unlock()
-
-	// 记录移除事件
-	for _, evt := range removedEvents {
-		a.recordImpactRemoved(evt)
 	}
 }
 
-// refreshTargetPorts 刷新监控目标的监听端口缓存
-func (a *ImpactAnalyzer) refreshTargetPorts(targets []types.MonitorTarget) {
-	a.targetPorts = make(map[int32][]int)
-	for _, target := range targets {
-		ports := a.portChecker.GetListeningPorts(target.PID)
-		if len(ports) > 0 {
-			a.targetPorts[target.PID] = ports
+// getTargetListeners 获取目标在指定端口上的监听地址（配置端口未监听时返回空）
+func (a *ImpactAnalyzer) getTargetListeners(pid int32, port int) []ConnectionInfo {
+	var result []ConnectionInfo
+	for _, l := range a.targetPorts[pid] {
+		if l.LocalPort == port {
+			result = append(result, l)
 		}
 	}
+	return result
 }
 
 // getWatchPortsForTarget 获取目标需要监控的端口（配置 + 自动发现）
@@ -841,10 +1451,8 @@ func (a *ImpactAnalyzer) getWatchPortsForTarget(target types.MonitorTarget) []in
 	}
 
 	// 自动发现的监听端口
-	if discovered, ok := a.targetPorts[target.PID]; ok {
-		for _, p := range discovered {
-			portSet[p] = true
-		}
+	for _, l := range a.targetPorts[target.PID] {
+		portSet[l.LocalPort] = true
 	}
 
 	var ports []int
@@ -855,7 +1463,8 @@ func (a *ImpactAnalyzer) getWatchPortsForTarget(target types.MonitorTarget) []in
 }
 
 // findPortConflicts 查找端口冲突
-func (a *ImpactAnalyzer) findPortConflicts(conns []ConnectionInfo, port int, excludePID int32, targetPIDs map[int32]bool) []PortConflict {
+// listeners 为目标在该端口上的监听地址；为空（如仅配置了端口）时按通配处理，不比较地址。ignoreLoopback 时忽略仅监听回环地址的进程
+func (a *ImpactAnalyzer) findPortConflicts(conns []ConnectionInfo, port int, listeners []ConnectionInfo, excludePID int32, targetPIDs map[int32]bool, ignoreLoopback bool) []PortConflict {
 	var conflicts []PortConflict
 	seen := make(map[int32]bool) // 避免同一进程重复报告
 
@@ -865,6 +1474,16 @@ func (a *ImpactAnalyzer) findPortConflicts(conns []ConnectionInfo, port int, exc
 			continue
 		}
 
+		// 忽略仅监听回环地址的进程
+		if ignoreLoopback && conn.Status == "LISTEN" && isLoopbackIP(conn.LocalIP) {
+			continue
+		}
+
+		// 地址族和地址（考虑通配地址）与目标监听地址重叠才算冲突
+		if !a.overlapsListeners(conn, port, listeners) {
+			continue
+		}
+
 		// 排除监控目标自身
 		if conn.PID == excludePID || conn.PID == 0 {
 			continue
@@ -897,6 +1516,23 @@ func (a *ImpactAnalyzer) findPortConflicts(conns []ConnectionInfo, port int, exc
 	return conflicts
 }
 
+// overlapsListeners 判断连接在该端口上使用的地址是否与目标监听地址重叠
+func (a *ImpactAnalyzer) overlapsListeners(conn ConnectionInfo, port int, listeners []ConnectionInfo) bool {
+	if len(listeners) == 0 {
+		return true
+	}
+	ip := conn.LocalIP
+	if conn.LocalPort != port {
+		ip = conn.RemoteIP
+	}
+	for _, l := range listeners {
+		if addrOverlap(l.Family, l.LocalIP, conn.Family, ip) {
+			return true
+		}
+	}
+	return false
+}
+
 // getPortConflictSeverity 根据冲突类型返回严重程度
 func (a *ImpactAnalyzer) getPortConflictSeverity(status string) string {
 	if status == "LISTEN" {
@@ -908,23 +1544,24 @@ func (a *ImpactAnalyzer) getPortConflictSeverity(status string) string {
 // getPortStatusDesc 获取端口状态描述
 func (a *ImpactAnalyzer) getPortStatusDesc(status string) string {
 	if status == "LISTEN" {
-		return "被其他进程监听"
+		return i18n.T("impact.port.listen")
 	}
-	return "有外部连接"
+	return i18n.T("impact.port.connect")
 }
 
 // getPortConflictSuggestion 获取端口冲突建议
 func (a *ImpactAnalyzer) getPortConflictSuggestion(port int, conflict PortConflict) string {
 	if conflict.Status == "LISTEN" {
-		return fmt.Sprintf("端口 %d 被 %s (PID %d) 监听，存在端口冲突，建议检查配置或终止冲突进程", port, conflict.Name, conflict.PID)
+		return i18n.T("impact.port.suggestion.listen", port, conflict.Name, conflict.PID)
 	}
-	return fmt.Sprintf("进程 %s (PID %d) 正在连接监控目标的端口 %d", conflict.Name, conflict.PID, port)
+	return i18n.T("impact.port.suggestion.connect", conflict.Name, conflict.PID, port)
 }
 
 // analyzeFileConflict 分析文件占用冲突
 // 自动发现监控目标打开的文件，检测其他进程是否也打开了同样的文件
 func (a *ImpactAnalyzer) analyzeFileConflict(targets []types.MonitorTarget, procMap map[int32]*types.ProcessInfo, targetPIDSet map[int32]bool) {
-	now := time.Now()
+	a.beginPass("file")
+	now := a.clock.Now()
 
 	// 每 60 秒更新一次监控目标的打开文件缓存
 	if now.Sub(a.targetFilesTime) > 60*time.Second {
@@ -933,10 +1570,7 @@ func (a *ImpactAnalyzer) analyzeFileConflict(targets []types.MonitorTarget, proc
 	}
 
 	// 刷新所有进程的打开文件缓存
-	a.fileChecker.RefreshOpenFiles(targetPIDSet)
-
-	// 收集本次检测到的所有文件冲突
-	currentConflicts := make(map[string]bool)
+	a.setCheckError(checkFile, a.fileChecker.RefreshOpenFiles(targetPIDSet))
 
 	// 检测每个监控目标的文件冲突
 	for _, target := range targets {
@@ -949,58 +1583,23 @@ func (a *ImpactAnalyzer) analyzeFileConflict(targets []types.MonitorTarget, proc
 		// 查找冲突
 		conflicts := a.fileChecker.FindConflicts(target.PID, watchFiles, targetPIDSet)
 		for _, conflict := range conflicts {
-			conflictKey := fmt.Sprintf("%d-%d-%s", target.PID, conflict.PID, conflict.Path)
-			currentConflicts[conflictKey] = true
-
 			event := types.ImpactEvent{
-				Timestamp:   time.Now(),
+				Timestamp:   a.cycleStart,
 				TargetPID:   target.PID,
 				TargetName:  a.getTargetDisplayName(target),
 				ImpactType:  "file",
 				Severity:    "high",
 				SourcePID:   conflict.PID,
 				SourceName:  conflict.Name,
-				Description: fmt.Sprintf("文件 %s 被进程 %s (PID %d) 同时打开", conflict.Path, conflict.Name, conflict.PID),
+				Description: i18n.T("impact.file.desc", conflict.Path, conflict.Name, conflict.PID),
 				Metrics: types.ImpactMetrics{
 					ConflictFile: conflict.Path,
 				},
-				Suggestion: fmt.Sprintf("文件 %s 被多个进程打开，可能影响监控目标对该文件的独占访问", conflict.Path),
+				Suggestion: i18n.T("impact.file.suggestion", conflict.Path),
 			}
-			a.recordImpact(event, "file:"+conflict.Path)
-		}
-	}
-
-	// 删除不再存在的文件冲突事件
-	a.mu.Lock()
-	var toRemove []impactKey
-	for key := range a.activeImpacts {
-		if key.ImpactType != "file" {
-			continue
-		}
-		// 从 detail 中提取文件路径
-		filePath := ""
-		if len(key.Detail) > 5 && key.Detail[:5] == "file:" {
-			filePath = key.Detail[5:]
-		}
-		conflictKey := fmt.Sprintf("%d-%d-%s", key.TargetPID, key.SourcePID, filePath)
-		if !currentConflicts[conflictKey] {
-			toRemove = append(toRemove, key)
+			a.emit(event, "file:"+conflict.Path)
 		}
 	}
-	// 先收集要删除的事件
-	removedEvents := make([]*types.ImpactEvent, 0, len(toRemove))
-	for _, key := range toRemove {
-		if evt := a.activeImpacts[key]; evt != nil {
-			removedEvents = append(removedEvents, evt)
-		}
-		delete(a.activeImpacts, key)
-	}
-	a.mu.Unlock()
-
-	// 记录移除事件
-	for _, evt := range removedEvents {
-		a.recordImpactRemoved(evt)
-	}
 }
 
 // refreshTargetFiles 刷新监控目标的打开文件缓存
@@ -1039,33 +1638,112 @@ func (a *ImpactAnalyzer) getWatchFilesForTarget(target types.MonitorTarget) []st
 
 // 辅助函数
 
-func (a *ImpactAnalyzer) recordImpact(event types.ImpactEvent, detail string) {
-	key := impactKey{
+// newImpactKey 影响事件的唯一标识，detail 为文件路径、端口等区分同一来源多个影响的明细
+func newImpactKey(event types.ImpactEvent, detail string) impactKey {
+	return impactKey{
 		TargetPID:  event.TargetPID,
 		ImpactType: event.ImpactType,
 		SourcePID:  event.SourcePID,
 		Detail:     detail,
 	}
+}
+
+func (a *ImpactAnalyzer) recordImpact(event types.ImpactEvent, detail string) {
+	key := newImpactKey(event, detail)
+	fillTargetMetrics(&event.Metrics, a.cycleProcs[event.TargetPID])
 
 	a.mu.Lock()
-	_, exists := a.activeImpacts[key]
-	a.activeImpacts[key] = &event
+	// 维护中的目标或计划维护时段内：保留事件供查看，但标记为已抑制，不告警
+	if a.maintWindow != nil || (a.inMaintenance != nil && a.inMaintenance(event.TargetPID)) {
+		event.Suppressed = true
+	}
+	a.attachTargetNotesLocked(&event)
+	a.lastBreach[key] = event.Timestamp
+	prev, exists := a.activeImpacts[key]
+	if !exists && !a.sustainedLocked(key, event.Timestamp) {
+		// 突破持续时间未达到要求，暂不产生影响事件
+		a.mu.Unlock()
+		return
+	}
+	isNew := !exists || prev.Suppressed // 维护结束后仍存在的影响视为新事件
+	assignIdentityLocked(&event, key, prev, isNew)
+	stored := &event
+	a.activeImpacts[key] = stored
+	logIt := isNew && !event.Suppressed && a.shouldLogLocked(key, &event)
 	callback := a.eventCallback
+	offenders := a.offenders
 	a.mu.Unlock()
 
-	if !exists {
+	if isNew {
+		a.captureHistory(key, stored)
+	}
+	if isNew && !event.Suppressed {
+		a.impactStream.Publish(event)
+		if offenders != nil {
+			offenders.Record(event)
+		}
+	}
+	if logIt {
 		logger.Impact(event.ImpactType, event.Severity, event.TargetName, event.SourceName, event.Description)
 
 		// 记录到事件日志
 		if callback != nil {
 			eventType := "impact_" + event.ImpactType
-			message := fmt.Sprintf("[影响%s] %s → %s: %s",
-				a.getSeverityName(event.Severity), event.SourceName, event.TargetName, event.Description)
+			message := i18n.T("impact.event.raised",
+				a.getSeverityName(event.Severity), event.SourceName, event.TargetName, event.Description) +
+				i18n.T("impact.event.id", event.ID)
 			callback(eventType, event.SourcePID, event.SourceName, message)
 		}
 	}
 }
 
+// fillTargetMetrics 记录目标进程当时的指标（各项分析只填写了与本类影响相关的字段），目标不在进程列表中时保持不变
+func fillTargetMetrics(m *types.ImpactMetrics, target *types.ProcessInfo) {
+	if target == nil {
+		return
+	}
+	m.TargetCPU = target.CPUPct
+	m.TargetMemory = target.RSSBytes
+	m.TargetDiskIO = target.DiskReadRate + target.DiskWriteRate
+	m.TargetNetIO = target.NetRecvRate + target.NetSendRate
+	m.TargetNumThreads = target.NumThreads
+	m.TargetNumFDs = target.NumFDs
+}
+
+// refreshTargetNotes 缓存设置了处置说明或运行手册的目标
+func (a *ImpactAnalyzer) refreshTargetNotes(targets []types.MonitorTarget) {
+	notes := make(map[int32]types.MonitorTarget)
+	for _, t := range targets {
+		if t.Notes != "" || t.RunbookURL != "" {
+			notes[t.PID] = t
+		}
+	}
+	a.mu.Lock()
+	a.targetNotes = notes
+	a.mu.Unlock()
+}
+
+// attachTargetNotesLocked 将目标的处置说明和运行手册附加到影响事件（调用方需持有 mu）
+func (a *ImpactAnalyzer) attachTargetNotesLocked(event *types.ImpactEvent) {
+	t, ok := a.targetNotes[event.TargetPID]
+	if !ok {
+		return
+	}
+	event.TargetNotes = t.Notes
+	event.RunbookURL = t.RunbookURL
+	parts := []string{}
+	if event.Suggestion != "" {
+		parts = append(parts, event.Suggestion)
+	}
+	if t.Notes != "" {
+		parts = append(parts, i18n.T("impact.notes", strings.ReplaceAll(t.Notes, "\n", " ")))
+	}
+	if t.RunbookURL != "" {
+		parts = append(parts, i18n.T("impact.runbook", t.RunbookURL))
+	}
+	event.Suggestion = strings.Join(parts, i18n.T("impact.separator"))
+}
+
 // recordImpactRemoved 记录影响事件移除
 func (a *ImpactAnalyzer) recordImpactRemoved(event *types.ImpactEvent) {
 	a.mu.RLock()
@@ -1074,52 +1752,32 @@ func (a *ImpactAnalyzer) recordImpactRemoved(event *types.ImpactEvent) {
 
 	if callback != nil {
 		eventType := "impact_resolved"
-		message := fmt.Sprintf("[影响解除] %s 对 %s 的 %s 影响已解除",
-			event.SourceName, event.TargetName, a.getImpactTypeName(event.ImpactType))
+		message := i18n.T("impact.event.resolved",
+			event.SourceName, event.TargetName, a.getImpactTypeName(event.ImpactType)) +
+			i18n.T("impact.event.id", event.ID)
 		callback(eventType, event.SourcePID, event.SourceName, message)
 	}
 }
 
 func (a *ImpactAnalyzer) getSeverityName(severity string) string {
 	switch severity {
-	case "critical":
-		return "严重"
-	case "high":
-		return "高级"
-	case "medium":
-		return "中级"
+	case "critical", "high", "medium":
+		return i18n.T("severity." + severity)
 	default:
-		return "低级"
+		return i18n.T("severity.low")
 	}
 }
 
 func (a *ImpactAnalyzer) getImpactTypeName(impactType string) string {
-	switch impactType {
-	case "cpu":
-		return "CPU竞争"
-	case "memory":
-		return "内存压力"
-	case "mem_growth":
-		return "内存增速"
-	case "disk_io":
-		return "磁盘IO"
-	case "network":
-		return "网络带宽"
-	case "file":
-		return "文件占用"
-	case "port":
-		return "端口占用"
-	case "fds":
-		return "句柄数"
-	case "threads":
-		return "线程数"
-	case "open_files":
-		return "打开文件数"
-	case "vms":
-		return "虚拟内存"
-	default:
+	return ImpactTypeName(impactType)
+}
+
+// ImpactTypeName 影响类型的显示名称（按当前语言），未知类型返回原值
+func ImpactTypeName(impactType string) string {
+	if !IsImpactType(impactType) {
 		return impactType
 	}
+	return i18n.T("impact_type." + impactType)
 }
 
 func (a *ImpactAnalyzer) getTargetDisplayName(target types.MonitorTarget) string {
@@ -1157,90 +1815,109 @@ func (a *ImpactAnalyzer) getProcessSeverity(value, threshold float64) string {
 func (a *ImpactAnalyzer) getCPUSuggestion(severity, procName string, cpuPct float64) string {
 	switch severity {
 	case "critical":
-		return fmt.Sprintf("CPU 资源严重不足，进程 %s 占用 %.1f%%，建议立即检查该进程或增加系统资源", procName, cpuPct)
+		return i18n.T("impact.cpu.suggestion.critical", procName, cpuPct)
 	case "high":
-		return fmt.Sprintf("进程 %s 占用大量 CPU (%.1f%%)，可能影响监控目标性能，建议检查该进程是否正常", procName, cpuPct)
+		return i18n.T("impact.cpu.suggestion.high", procName, cpuPct)
 	default:
-		return fmt.Sprintf("建议关注进程 %s，CPU 占用 %.1f%%", procName, cpuPct)
+		return i18n.T("impact.cpu.suggestion", procName, cpuPct)
 	}
 }
 
 func (a *ImpactAnalyzer) getMemorySuggestion(severity, procName string, rss uint64, growthRate float64) string {
 	if growthRate > 1024*1024 { // > 1MB/s 增长
-		return fmt.Sprintf("进程 %s 内存持续增长 (+%.1f MB/s)，可能存在内存泄漏，建议检查", procName, growthRate/1024/1024)
+		return i18n.T("impact.memory.suggestion.growth", procName, format.MemGrowth(growthRate))
 	}
 	switch severity {
 	case "critical":
-		return fmt.Sprintf("内存即将耗尽，进程 %s 占用 %s，存在 OOM 风险，建议立即处理", procName, formatBytes(rss))
+		return i18n.T("impact.memory.suggestion.critical", procName, format.Bytes(rss))
 	case "high":
-		return fmt.Sprintf("内存压力较大，进程 %s 占用 %s，建议检查是否可以释放", procName, formatBytes(rss))
+		return i18n.T("impact.memory.suggestion.high", procName, format.Bytes(rss))
 	default:
-		return fmt.Sprintf("建议关注进程 %s 的内存使用 (%s)", procName, formatBytes(rss))
+		return i18n.T("impact.memory.suggestion", procName, format.Bytes(rss))
 	}
 }
 
+// topFields getTopByField 支持的排序指标
+var topFields = map[string]func(p *types.ProcessInfo) float64{
+	"cpu":     func(p *types.ProcessInfo) float64 { return p.CPUPct },
+	"memory":  func(p *types.ProcessInfo) float64 { return float64(p.RSSBytes) },
+	"disk_io": func(p *types.ProcessInfo) float64 { return p.DiskReadRate + p.DiskWriteRate },
+	"network": func(p *types.ProcessInfo) float64 { return p.NetRecvRate + p.NetSendRate },
+}
+
+// topKey getTopByField 缓存的键
+type topKey struct {
+	field string
+	n     int
+}
+
+// getTopByField 按指标取前 n 个进程（降序，相同时保持进程列表顺序），返回的切片不可修改。
+// 同一周期内 procs 不变，结果按指标和 n 缓存，evaluate 开始时清空
 func (a *ImpactAnalyzer) getTopByField(procs []types.ProcessInfo, field string, n int) []types.ProcessInfo {
-	sorted := make([]types.ProcessInfo, len(procs))
-	copy(sorted, procs)
-
-	switch field {
-	case "cpu":
-		sort.Slice(sorted, func(i, j int) bool { return sorted[i].CPUPct > sorted[j].CPUPct })
-	case "memory":
-		sort.Slice(sorted, func(i, j int) bool { return sorted[i].RSSBytes > sorted[j].RSSBytes })
-	case "disk_io":
-		sort.Slice(sorted, func(i, j int) bool {
-			return (sorted[i].DiskReadRate + sorted[i].DiskWriteRate) > (sorted[j].DiskReadRate + sorted[j].DiskWriteRate)
-		})
-	case "network":
-		sort.Slice(sorted, func(i, j int) bool {
-			return (sorted[i].NetRecvRate + sorted[i].NetSendRate) > (sorted[j].NetRecvRate + sorted[j].NetSendRate)
-		})
-	}
+	key := topKey{field, n}
+	a.topMu.Lock()
+	top, ok := a.topCache[key]
+	a.topMu.Unlock()
+	if ok {
+		return top
+	}
+	value, ok := topFields[field]
+	if !ok {
+		return nil
+	}
+	top = topProcessesBy(procs, n, value)
+	a.topMu.Lock()
+	if a.topCache != nil {
+		a.topCache[key] = top
+	}
+	a.topMu.Unlock()
+	return top
+}
 
-	if len(sorted) > n {
-		return sorted[:n]
+// vmsForCheck 虚拟内存检测比较的值：启用 exclude_mapped_vms 且读取到匿名虚拟内存时不计文件映射，
+// 第二个返回值表示使用的是匿名虚拟内存
+func vmsForCheck(p *types.ProcessInfo, excludeMapped bool) (uint64, bool) {
+	if excludeMapped && p.AnonVMS > 0 {
+		return p.AnonVMS, true
 	}
-	return sorted
+	return p.VMS, false
 }
 
-func formatBytes(bytes uint64) string {
-	const unit = 1024
-	if bytes < unit {
-		return fmt.Sprintf("%d B", bytes)
+// procDisplayName 风险描述和处置建议中使用的进程名称：配置了显示名称时使用显示名称
+func procDisplayName(p *types.ProcessInfo) string {
+	if p.DisplayName != "" {
+		return p.DisplayName
 	}
-	div, exp := uint64(unit), 0
-	for n := bytes / unit; n >= unit; n /= unit {
-		div *= unit
-		exp++
-	}
-	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+	return p.Name
 }
 
 // analyzeOtherMetrics 分析其他进程指标（内存增速、句柄数、线程数、打开文件数、虚拟内存）
 func (a *ImpactAnalyzer) analyzeOtherMetrics(
+	effective types.ImpactConfig,
 	sys *types.SystemMetrics,
 	procs []types.ProcessInfo,
 	targets []types.MonitorTarget,
 	procMap map[int32]*types.ProcessInfo,
 	targetPIDSet map[int32]bool,
 ) {
-	// 清除旧的其他类型事件
-	a.clearEventsByType("mem_growth")
-	a.clearEventsByType("fds")
-	a.clearEventsByType("threads")
-	a.clearEventsByType("open_files")
-	a.clearEventsByType("vms")
-
-	// 阈值转换
-	memGrowthThreshold := a.config.ProcMemGrowthThreshold * 1024 * 1024 // MB/s -> B/s
-	vmsThreshold := a.config.ProcVMSThreshold * 1024 * 1024             // MB -> B
+	// 本周期评估的其他类型
+	a.beginPass("mem_growth")
+	a.beginPass("fds")
+	a.beginPass("fd_growth")
+	a.beginPass("threads")
+	a.beginPass("open_files")
+	a.beginPass("vms")
 
 	for _, target := range targets {
 		targetProc := procMap[target.PID]
 		if targetProc == nil {
 			continue
 		}
+		cfg := targetConfig(effective, target)
+
+		// 阈值转换
+		memGrowthThreshold := cfg.ProcMemGrowthThreshold * 1024 * 1024 // MB/s -> B/s
+		vmsThreshold := cfg.ProcVMSThreshold * 1024 * 1024             // MB -> B
 
 		for _, proc := range procs {
 			// 跳过目标自身
@@ -1249,111 +1926,382 @@ func (a *ImpactAnalyzer) analyzeOtherMetrics(
 			}
 
 			// 检查内存增速
-			if a.config.ProcMemGrowthThreshold > 0 && proc.RSSGrowthRate >= memGrowthThreshold {
+			if cfg.ProcMemGrowthThreshold > 0 && proc.RSSGrowthRate >= memGrowthThreshold {
 				severity := a.getProcessSeverity(proc.RSSGrowthRate, memGrowthThreshold)
 				event := types.ImpactEvent{
-					Timestamp:   time.Now(),
+					Timestamp:   a.cycleStart,
 					TargetPID:   target.PID,
 					TargetName:  a.getTargetDisplayName(target),
 					ImpactType:  "mem_growth",
 					Severity:    severity,
 					SourcePID:   proc.PID,
 					SourceName:  proc.Name,
-					Description: fmt.Sprintf("进程 %s (PID %d) 内存增速 %.1f MB/s 超过阈值 %.0f MB/s", proc.Name, proc.PID, proc.RSSGrowthRate/1024/1024, a.config.ProcMemGrowthThreshold),
+					Description: i18n.T("impact.mem_growth.desc", procDisplayName(&proc), proc.PID, format.MemGrowth(proc.RSSGrowthRate), cfg.ProcMemGrowthThreshold),
 					Metrics: types.ImpactMetrics{
 						SystemCPU:    sys.CPUPercent,
 						SystemMemory: sys.MemoryPercent,
 						SourceMemory: proc.RSSBytes,
 					},
-					Suggestion: fmt.Sprintf("进程 %s 内存持续增长，可能存在内存泄漏，建议检查", proc.Name),
+					Suggestion: i18n.T("impact.mem_growth.suggestion", procDisplayName(&proc)),
 				}
-				a.recordImpact(event, "")
+				a.emit(event, "")
 			}
 
 			// 检查句柄数
-			if a.config.ProcFDsThreshold > 0 && proc.NumFDs >= int32(a.config.ProcFDsThreshold) {
-				severity := a.getProcessSeverity(float64(proc.NumFDs), float64(a.config.ProcFDsThreshold))
+			if cfg.ProcFDsThreshold > 0 && proc.NumFDs >= int32(cfg.ProcFDsThreshold) {
+				severity := a.getProcessSeverity(float64(proc.NumFDs), float64(cfg.ProcFDsThreshold))
 				event := types.ImpactEvent{
-					Timestamp:   time.Now(),
+					Timestamp:   a.cycleStart,
 					TargetPID:   target.PID,
 					TargetName:  a.getTargetDisplayName(target),
 					ImpactType:  "fds",
 					Severity:    severity,
 					SourcePID:   proc.PID,
 					SourceName:  proc.Name,
-					Description: fmt.Sprintf("进程 %s (PID %d) 句柄数 %d 超过阈值 %d", proc.Name, proc.PID, proc.NumFDs, a.config.ProcFDsThreshold),
+					Description: i18n.T("impact.fds.desc", procDisplayName(&proc), proc.PID, proc.NumFDs, cfg.ProcFDsThreshold),
 					Metrics: types.ImpactMetrics{
 						SystemCPU:    sys.CPUPercent,
 						SystemMemory: sys.MemoryPercent,
 					},
-					Suggestion: fmt.Sprintf("进程 %s 句柄数过高，可能存在资源泄漏，建议检查", proc.Name),
+					Suggestion: i18n.T("impact.fds.suggestion", procDisplayName(&proc)),
 				}
-				a.recordImpact(event, "")
+				a.emit(event, "")
+			}
+
+			// 检查句柄数增速（句柄泄漏的早期迹象，绝对数量尚未超过阈值）
+			if cfg.ProcFDGrowthThreshold > 0 && proc.FDGrowthRate >= cfg.ProcFDGrowthThreshold {
+				severity := a.getProcessSeverity(proc.FDGrowthRate, cfg.ProcFDGrowthThreshold)
+				event := types.ImpactEvent{
+					Timestamp:   a.cycleStart,
+					TargetPID:   target.PID,
+					TargetName:  a.getTargetDisplayName(target),
+					ImpactType:  "fd_growth",
+					Severity:    severity,
+					SourcePID:   proc.PID,
+					SourceName:  proc.Name,
+					Description: i18n.T("impact.fd_growth.desc", procDisplayName(&proc), proc.PID, proc.FDGrowthRate, proc.NumFDs, cfg.ProcFDGrowthThreshold),
+					Metrics: types.ImpactMetrics{
+						SystemCPU:    sys.CPUPercent,
+						SystemMemory: sys.MemoryPercent,
+					},
+					Suggestion: i18n.T("impact.fd_growth.suggestion", procDisplayName(&proc)),
+				}
+				a.emit(event, "")
 			}
 
 			// 检查线程数
-			if a.config.ProcThreadsThreshold > 0 && proc.NumThreads >= int32(a.config.ProcThreadsThreshold) {
-				severity := a.getProcessSeverity(float64(proc.NumThreads), float64(a.config.ProcThreadsThreshold))
+			if cfg.ProcThreadsThreshold > 0 && proc.NumThreads >= int32(cfg.ProcThreadsThreshold) {
+				severity := a.getProcessSeverity(float64(proc.NumThreads), float64(cfg.ProcThreadsThreshold))
 				event := types.ImpactEvent{
-					Timestamp:   time.Now(),
+					Timestamp:   a.cycleStart,
 					TargetPID:   target.PID,
 					TargetName:  a.getTargetDisplayName(target),
 					ImpactType:  "threads",
 					Severity:    severity,
 					SourcePID:   proc.PID,
 					SourceName:  proc.Name,
-					Description: fmt.Sprintf("进程 %s (PID %d) 线程数 %d 超过阈值 %d", proc.Name, proc.PID, proc.NumThreads, a.config.ProcThreadsThreshold),
+					Description: i18n.T("impact.threads.desc", procDisplayName(&proc), proc.PID, proc.NumThreads, cfg.ProcThreadsThreshold),
 					Metrics: types.ImpactMetrics{
 						SystemCPU:    sys.CPUPercent,
 						SystemMemory: sys.MemoryPercent,
 					},
-					Suggestion: fmt.Sprintf("进程 %s 线程数过多，可能影响系统性能，建议检查", proc.Name),
+					Suggestion: i18n.T("impact.threads.suggestion", procDisplayName(&proc)),
 				}
-				a.recordImpact(event, "")
+				a.emit(event, "")
 			}
 
 			// 检查打开文件数
-			if a.config.ProcOpenFilesThreshold > 0 && proc.OpenFiles >= a.config.ProcOpenFilesThreshold {
-				severity := a.getProcessSeverity(float64(proc.OpenFiles), float64(a.config.ProcOpenFilesThreshold))
+			if cfg.ProcOpenFilesThreshold > 0 && proc.OpenFiles >= cfg.ProcOpenFilesThreshold {
+				severity := a.getProcessSeverity(float64(proc.OpenFiles), float64(cfg.ProcOpenFilesThreshold))
 				event := types.ImpactEvent{
-					Timestamp:   time.Now(),
+					Timestamp:   a.cycleStart,
 					TargetPID:   target.PID,
 					TargetName:  a.getTargetDisplayName(target),
 					ImpactType:  "open_files",
 					Severity:    severity,
 					SourcePID:   proc.PID,
 					SourceName:  proc.Name,
-					Description: fmt.Sprintf("进程 %s (PID %d) 打开文件数 %d 超过阈值 %d", proc.Name, proc.PID, proc.OpenFiles, a.config.ProcOpenFilesThreshold),
+					Description: i18n.T("impact.open_files.desc", procDisplayName(&proc), proc.PID, proc.OpenFiles, cfg.ProcOpenFilesThreshold),
 					Metrics: types.ImpactMetrics{
 						SystemCPU:    sys.CPUPercent,
 						SystemMemory: sys.MemoryPercent,
 					},
-					Suggestion: fmt.Sprintf("进程 %s 打开文件数过多，可能影响系统性能", proc.Name),
+					Suggestion: i18n.T("impact.open_files.suggestion", procDisplayName(&proc)),
 				}
-				a.recordImpact(event, "")
+				a.emit(event, "")
 			}
 
 			// 检查虚拟内存
-			if a.config.ProcVMSThreshold > 0 && float64(proc.VMS) >= vmsThreshold {
-				severity := a.getProcessSeverity(float64(proc.VMS), vmsThreshold)
+			vms, anon := vmsForCheck(&proc, cfg.ExcludeMappedVMS)
+			if cfg.ProcVMSThreshold > 0 && float64(vms) >= vmsThreshold {
+				severity := a.getProcessSeverity(float64(vms), vmsThreshold)
+				description := i18n.T("impact.vms.desc", procDisplayName(&proc), proc.PID, format.Bytes(vms), cfg.ProcVMSThreshold)
+				if anon {
+					description = i18n.T("impact.vms.desc_anon", procDisplayName(&proc), proc.PID, format.Bytes(vms), format.Bytes(proc.VMS), cfg.ProcVMSThreshold)
+				}
 				event := types.ImpactEvent{
-					Timestamp:   time.Now(),
+					Timestamp:   a.cycleStart,
 					TargetPID:   target.PID,
 					TargetName:  a.getTargetDisplayName(target),
 					ImpactType:  "vms",
 					Severity:    severity,
 					SourcePID:   proc.PID,
 					SourceName:  proc.Name,
-					Description: fmt.Sprintf("进程 %s (PID %d) 虚拟内存 %s 超过阈值 %.0f MB", proc.Name, proc.PID, formatBytes(proc.VMS), a.config.ProcVMSThreshold),
+					Description: description,
 					Metrics: types.ImpactMetrics{
 						SystemCPU:    sys.CPUPercent,
 						SystemMemory: sys.MemoryPercent,
-						SourceMemory: proc.VMS,
+						SourceMemory: vms,
 					},
-					Suggestion: fmt.Sprintf("进程 %s 虚拟内存占用过高", proc.Name),
+					Suggestion: i18n.T("impact.vms.suggestion", procDisplayName(&proc)),
 				}
-				a.recordImpact(event, "")
+				a.emit(event, "")
 			}
 		}
 	}
 }
+
+// analyzePriority 检查目标优先级是否偏离配置的期望值（expected_priority）
+// 偏离期间持续保留影响事件，恢复后自动解除
+func (a *ImpactAnalyzer) analyzePriority(
+	sys *types.SystemMetrics,
+	targets []types.MonitorTarget,
+	procMap map[int32]*types.ProcessInfo,
+) {
+	a.beginPass("priority")
+
+	for _, target := range targets {
+		targetProc := procMap[target.PID]
+		if targetProc == nil || target.ExpectedPriority == nil || targetProc.Priority == *target.ExpectedPriority {
+			continue
+		}
+
+		// Linux 优先级为 20-nice，数值越小越容易被抢占
+		severity := "medium"
+		if targetProc.Priority < *target.ExpectedPriority {
+			severity = "high"
+		}
+		event := types.ImpactEvent{
+			Timestamp:   a.cycleStart,
+			TargetPID:   target.PID,
+			TargetName:  a.getTargetDisplayName(target),
+			ImpactType:  "priority",
+			Severity:    severity,
+			SourcePID:   target.PID,
+			SourceName:  targetProc.Name,
+			Description: i18n.T("impact.priority.desc", targetProc.Priority, *target.ExpectedPriority, targetProc.Nice),
+			Metrics: types.ImpactMetrics{
+				SystemCPU:    sys.CPUPercent,
+				SystemMemory: sys.MemoryPercent,
+				TargetCPU:    targetProc.CPUPct,
+				TargetMemory: targetProc.RSSBytes,
+			},
+			Suggestion: i18n.T("impact.priority.suggestion"),
+		}
+		a.emit(event, "")
+	}
+}
+
+// analyzeChurn 分析进程频繁启停（如服务崩溃后被反复拉起），影响所有监控目标
+// churn 为本周期采集的启停频率，未设置来源时为 nil
+func (a *ImpactAnalyzer) analyzeChurn(
+	effective types.ImpactConfig,
+	sys *types.SystemMetrics,
+	churn *types.ProcessChurn,
+	targets []types.MonitorTarget,
+	procMap map[int32]*types.ProcessInfo,
+) {
+	a.beginPass("churn")
+
+	threshold := effective.ChurnThreshold
+	if threshold <= 0 || churn == nil || churn.PerMinute < threshold {
+		return
+	}
+
+	severity := "medium"
+	if churn.PerMinute >= threshold*2 {
+		severity = "high"
+	}
+	sourceName := churn.TopName
+	detail := ""
+	if sourceName == "" {
+		sourceName = i18n.T("impact.churn.multiple")
+	} else {
+		detail = i18n.T("impact.churn.top", churn.TopName, churn.TopCount)
+	}
+
+	for _, target := range targets {
+		targetProc := procMap[target.PID]
+		if targetProc == nil {
+			continue
+		}
+		event := types.ImpactEvent{
+			Timestamp:   a.cycleStart,
+			TargetPID:   target.PID,
+			TargetName:  a.getTargetDisplayName(target),
+			ImpactType:  "churn",
+			Severity:    severity,
+			SourceName:  sourceName,
+			Description: i18n.T("impact.churn.desc", churn.PerMinute, threshold, detail),
+			Metrics: types.ImpactMetrics{
+				SystemCPU:    sys.CPUPercent,
+				SystemMemory: sys.MemoryPercent,
+				TargetCPU:    targetProc.CPUPct,
+				TargetMemory: targetProc.RSSBytes,
+			},
+			Suggestion: i18n.T("impact.churn.suggestion"),
+		}
+		a.emit(event, "")
+	}
+}
+
+// analyzeSteal 分析 CPU 被宿主机抢占（虚拟机所在宿主机超分），影响所有监控目标
+// 抢占不是由本机进程造成的，作为提示性影响：默认 low，达到阈值 2 倍为 medium
+func (a *ImpactAnalyzer) analyzeSteal(
+	effective types.ImpactConfig,
+	sys *types.SystemMetrics,
+	targets []types.MonitorTarget,
+	procMap map[int32]*types.ProcessInfo,
+) {
+	a.beginPass("steal")
+
+	threshold := effective.StealThreshold
+	if threshold <= 0 || sys.CPUSteal < threshold {
+		return
+	}
+	severity := "low"
+	if sys.CPUSteal >= threshold*2 {
+		severity = "medium"
+	}
+
+	for _, target := range targets {
+		targetProc := procMap[target.PID]
+		if targetProc == nil {
+			continue
+		}
+		event := types.ImpactEvent{
+			Timestamp:   a.cycleStart,
+			TargetPID:   target.PID,
+			TargetName:  a.getTargetDisplayName(target),
+			ImpactType:  "steal",
+			Severity:    severity,
+			SourceName:  i18n.T("impact.steal.source"),
+			Description: i18n.T("impact.steal.desc", sys.CPUSteal, threshold),
+			Metrics: types.ImpactMetrics{
+				SystemCPU:    sys.CPUPercent,
+				SystemMemory: sys.MemoryPercent,
+				TargetCPU:    targetProc.CPUPct,
+				TargetMemory: targetProc.RSSBytes,
+			},
+			Suggestion: i18n.T("impact.steal.suggestion"),
+		}
+		a.emit(event, "")
+	}
+}
+
+// analyzeVirtContention 分析虚拟化资源争用：宿主机抢占达到 steal_threshold，同时目标可运行等待 CPU 的
+// 时间占比达到 virt_run_wait_threshold，说明目标确实因拿不到 CPU 而变慢（而不只是宿主机繁忙）。
+// 默认 high，等待占比达到阈值 2 倍为 critical；平台不提供调度等待（Windows、内核未开启 schedstat）时不检测
+func (a *ImpactAnalyzer) analyzeVirtContention(
+	effective types.ImpactConfig,
+	sys *types.SystemMetrics,
+	runWait map[int32]float64,
+	targets []types.MonitorTarget,
+	procMap map[int32]*types.ProcessInfo,
+) {
+	a.beginPass("virt_contention")
+
+	stealThreshold := effective.StealThreshold
+	threshold := effective.VirtRunWaitThreshold
+	if stealThreshold <= 0 || threshold <= 0 || sys.CPUSteal < stealThreshold {
+		return
+	}
+
+	for _, target := range targets {
+		targetProc := procMap[target.PID]
+		wait, ok := runWait[target.PID]
+		if targetProc == nil || !ok || wait < threshold {
+			continue
+		}
+		severity := "high"
+		if wait >= threshold*2 {
+			severity = "critical"
+		}
+		event := types.ImpactEvent{
+			Timestamp:   a.cycleStart,
+			TargetPID:   target.PID,
+			TargetName:  a.getTargetDisplayName(target),
+			ImpactType:  "virt_contention",
+			Severity:    severity,
+			SourceName:  i18n.T("impact.steal.source"),
+			Description: i18n.T("impact.virt_contention.desc", sys.CPUSteal, wait, threshold, targetProc.CPUPct),
+			Metrics: types.ImpactMetrics{
+				SystemCPU:    sys.CPUPercent,
+				SystemMemory: sys.MemoryPercent,
+				TargetCPU:    targetProc.CPUPct,
+				TargetMemory: targetProc.RSSBytes,
+			},
+			Suggestion: i18n.T("impact.virt_contention.suggestion"),
+		}
+		a.emit(event, "")
+	}
+}
+
+// ZombieChildCounts 按父进程统计僵尸（已退出未回收）子进程数
+// 只有类 Unix 系统有僵尸进程；Windows 进程状态为空，统计结果为空
+func ZombieChildCounts(procs []types.ProcessInfo) map[int32]int {
+	counts := make(map[int32]int)
+	for _, p := range procs {
+		if p.Status == "zombie" && p.PPID > 0 {
+			counts[p.PPID]++
+		}
+	}
+	return counts
+}
+
+// analyzeZombies 分析目标未回收的僵尸子进程（子进程退出后父进程未 wait，长期积累会耗尽进程表）
+func (a *ImpactAnalyzer) analyzeZombies(
+	effective types.ImpactConfig,
+	sys *types.SystemMetrics,
+	procs []types.ProcessInfo,
+	targets []types.MonitorTarget,
+	procMap map[int32]*types.ProcessInfo,
+) {
+	a.beginPass("zombies")
+
+	counts := ZombieChildCounts(procs)
+	for _, target := range targets {
+		targetProc := procMap[target.PID]
+		if targetProc == nil {
+			continue
+		}
+		cfg := targetConfig(effective, target)
+		count := counts[target.PID]
+		if cfg.ZombieThreshold <= 0 || count < cfg.ZombieThreshold {
+			continue
+		}
+
+		severity := "medium"
+		if count >= cfg.ZombieThreshold*2 {
+			severity = "high"
+		}
+		event := types.ImpactEvent{
+			Timestamp:   a.cycleStart,
+			TargetPID:   target.PID,
+			TargetName:  a.getTargetDisplayName(target),
+			ImpactType:  "zombies",
+			Severity:    severity,
+			SourcePID:   target.PID,
+			SourceName:  targetProc.Name,
+			Description: i18n.T("impact.zombies.desc", count, cfg.ZombieThreshold),
+			Metrics: types.ImpactMetrics{
+				SystemCPU:    sys.CPUPercent,
+				SystemMemory: sys.MemoryPercent,
+				TargetCPU:    targetProc.CPUPct,
+				TargetMemory: targetProc.RSSBytes,
+			},
+			Suggestion: i18n.T("impact.zombies.suggestion"),
+		}
+		a.emit(event, "")
+	}
+}
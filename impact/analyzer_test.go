@@ -0,0 +1,298 @@
+package impact
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/net"
+
+	"monitor-agent/clock"
+	"monitor-agent/provider"
+	"monitor-agent/types"
+)
+
+// 测试用的 PID 取较大的值，避免与运行测试的主机上的真实进程重合（端口检测按 PID 读取进程名）
+const (
+	testTargetPID   int32 = 900001
+	testTarget2PID  int32 = 900002
+	testSourcePID   int32 = 900010
+	testIntruderPID int32 = 900011
+)
+
+// testStart 测试时钟的起点
+var testStart = time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+// testHarness 用 provider.Fake 和 clock.Fake 驱动分析器：step 推进时钟并手动执行一个分析周期，
+// 不启动分析协程，也不读取真实的进程表和连接表
+type testHarness struct {
+	t     *testing.T
+	a     *ImpactAnalyzer
+	prov  *provider.Fake
+	clock *clock.Fake
+
+	mu      sync.Mutex
+	targets []types.MonitorTarget
+	events  map[string]int // 事件回调收到的各类事件次数
+}
+
+// testConfig 测试用的基础配置：系统级阈值不触发，进程级阈值全部关闭，文件检测间隔足够长（只在第一个周期执行）
+func testConfig() types.ImpactConfig {
+	return types.ImpactConfig{
+		Enabled:           true,
+		AnalysisInterval:  5,
+		TopNProcesses:     10,
+		CPUThreshold:      80,
+		MemoryThreshold:   85,
+		DiskIOThreshold:   100,
+		NetworkThreshold:  100,
+		FileCheckInterval: 3600,
+		PortCheckInterval: 3600,
+	}
+}
+
+// newHarness 按 cfg 创建分析器，系统指标为空闲状态，targets 为监控目标
+func newHarness(t *testing.T, cfg types.ImpactConfig, targets ...types.MonitorTarget) *testHarness {
+	t.Helper()
+	h := &testHarness{
+		t:       t,
+		clock:   clock.NewFake(testStart),
+		targets: targets,
+		events:  make(map[string]int),
+	}
+	h.prov = provider.NewFake(h.clock)
+	h.prov.SetSystemMetrics(types.SystemMetrics{
+		CPUPercent:    20,
+		CPUPerCore:    []float64{20, 20, 20, 20},
+		MemoryPercent: 40,
+	})
+	h.a = NewImpactAnalyzer(cfg, h.prov, h.getTargets, h.prov.ListAllProcesses)
+	h.a.SetClock(h.clock)
+	h.a.SetConnectionSource(h.prov.Connections)
+	h.a.SetEventCallback(func(eventType string, pid int32, name, message string) {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.events[eventType]++
+	})
+	return h
+}
+
+func (h *testHarness) getTargets() []types.MonitorTarget {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]types.MonitorTarget(nil), h.targets...)
+}
+
+// setTargets 替换监控目标
+func (h *testHarness) setTargets(targets ...types.MonitorTarget) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.targets = targets
+}
+
+// eventCount 事件回调收到 eventType 的次数
+func (h *testHarness) eventCount(eventType string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.events[eventType]
+}
+
+// step 时钟推进一个分析间隔后执行一个分析周期
+func (h *testHarness) step() {
+	h.clock.Advance(5 * time.Second)
+	h.a.analyze()
+}
+
+// active 当前活跃的影响事件
+func (h *testHarness) active() []types.ImpactEvent {
+	return h.a.GetRecentImpacts(0, false)
+}
+
+// activeOf 当前活跃的某类影响事件
+func (h *testHarness) activeOf(impactType string) []types.ImpactEvent {
+	var result []types.ImpactEvent
+	for _, e := range h.active() {
+		if e.ImpactType == impactType {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+// expectActive 断言某类活跃影响事件的数量
+func (h *testHarness) expectActive(impactType string, want int) []types.ImpactEvent {
+	h.t.Helper()
+	got := h.activeOf(impactType)
+	if len(got) != want {
+		h.t.Fatalf("active %s impacts = %d, want %d: %+v", impactType, len(got), want, got)
+	}
+	return got
+}
+
+func target(pid int32, name string) types.MonitorTarget {
+	return types.MonitorTarget{PID: pid, Name: name}
+}
+
+func proc(pid int32, name string, cpuPct float64) types.ProcessInfo {
+	return types.ProcessInfo{PID: pid, Name: name, CPUPct: cpuPct, RSSBytes: 64 << 20, Status: "S"}
+}
+
+func TestCPUImpactRaisedAndCleared(t *testing.T) {
+	cfg := testConfig()
+	cfg.ProcCPUThreshold = 50
+	h := newHarness(t, cfg, target(testTargetPID, "scada"))
+	h.prov.SetProcesses([]types.ProcessInfo{
+		proc(testTargetPID, "scada", 10),
+		proc(testSourcePID, "compiler", 90),
+	})
+
+	h.step()
+	events := h.expectActive("cpu", 1)
+	if e := events[0]; e.TargetPID != testTargetPID || e.SourcePID != testSourcePID {
+		t.Fatalf("impact target/source = %d/%d, want %d/%d", e.TargetPID, e.SourcePID, testTargetPID, testSourcePID)
+	}
+	if n := h.eventCount("impact_cpu"); n != 1 {
+		t.Fatalf("impact_cpu callbacks = %d, want 1", n)
+	}
+
+	// 持续突破：同一影响保持活跃，不重复产生
+	h.step()
+	h.expectActive("cpu", 1)
+	if n := h.eventCount("impact_cpu"); n != 1 {
+		t.Fatalf("impact_cpu callbacks after repeat = %d, want 1", n)
+	}
+
+	// 负载下降后解除
+	h.prov.UpdateProcess(testSourcePID, func(p *types.ProcessInfo) { p.CPUPct = 5 })
+	h.step()
+	h.expectActive("cpu", 0)
+	if n := h.eventCount("impact_resolved"); n != 1 {
+		t.Fatalf("impact_resolved callbacks = %d, want 1", n)
+	}
+}
+
+func TestPortConflictResolvedCallbackOnce(t *testing.T) {
+	cfg := testConfig()
+	cfg.PortCheckInterval = 1
+	scada := target(testTargetPID, "scada")
+	scada.WatchPorts = []int{8080}
+	h := newHarness(t, cfg, scada)
+	h.prov.SetProcesses([]types.ProcessInfo{
+		proc(testTargetPID, "scada", 10),
+		proc(testIntruderPID, "rogue", 1),
+	})
+	listen := func(pid int32) net.ConnectionStat {
+		return net.ConnectionStat{
+			Family: 2, // AF_INET
+			Laddr:  net.Addr{IP: "0.0.0.0", Port: 8080},
+			Status: "LISTEN",
+			Pid:    pid,
+		}
+	}
+	h.prov.SetConnections([]net.ConnectionStat{listen(testTargetPID), listen(testIntruderPID)})
+
+	h.step()
+	events := h.expectActive("port", 1)
+	if events[0].SourcePID != testIntruderPID || events[0].Metrics.ConflictPort != 8080 {
+		t.Fatalf("port impact = %+v, want source %d on 8080", events[0], testIntruderPID)
+	}
+	if n := h.eventCount("impact_port"); n != 1 {
+		t.Fatalf("impact_port callbacks = %d, want 1", n)
+	}
+
+	// 冲突进程释放端口：解除回调只触发一次，之后的周期不再重复
+	h.prov.SetConnections([]net.ConnectionStat{listen(testTargetPID)})
+	for i := 0; i < 3; i++ {
+		h.step()
+	}
+	h.expectActive("port", 0)
+	if n := h.eventCount("impact_resolved"); n != 1 {
+		t.Fatalf("impact_resolved callbacks = %d, want 1", n)
+	}
+}
+
+func TestOrphanedEventsCleanedUp(t *testing.T) {
+	cfg := testConfig()
+	cfg.ProcCPUThreshold = 50
+	h := newHarness(t, cfg, target(testTargetPID, "scada"), target(testTarget2PID, "historian"))
+	h.prov.SetProcesses([]types.ProcessInfo{
+		proc(testTargetPID, "scada", 10),
+		proc(testTarget2PID, "historian", 10),
+		proc(testSourcePID, "compiler", 90),
+	})
+
+	h.step()
+	h.expectActive("cpu", 2)
+
+	// 移除一个目标：它的影响事件被清理，另一个目标的影响不受影响
+	h.setTargets(target(testTarget2PID, "historian"))
+	h.step()
+	events := h.expectActive("cpu", 1)
+	if events[0].TargetPID != testTarget2PID {
+		t.Fatalf("remaining impact target = %d, want %d", events[0].TargetPID, testTarget2PID)
+	}
+	if got := h.a.ActiveImpactCountByTarget()[testTargetPID]; got != 0 {
+		t.Fatalf("removed target still has %d impacts", got)
+	}
+
+	// 没有监控目标时清除所有事件
+	h.setTargets()
+	h.step()
+	if got := h.active(); len(got) != 0 {
+		t.Fatalf("impacts without targets = %+v, want none", got)
+	}
+}
+
+func TestUpdateConfigDuringRun(t *testing.T) {
+	cfg := testConfig()
+	cfg.ProcCPUThreshold = 50
+	h := newHarness(t, cfg, target(testTargetPID, "scada"))
+	h.prov.SetProcesses([]types.ProcessInfo{
+		proc(testTargetPID, "scada", 10),
+		proc(testSourcePID, "compiler", 60),
+	})
+
+	h.step()
+	h.expectActive("cpu", 1)
+
+	// 调高进程级阈值后，下一个周期按新阈值评估，影响解除
+	raised := cfg
+	raised.ProcCPUThreshold = 70
+	h.a.UpdateConfig(raised)
+	h.step()
+	h.expectActive("cpu", 0)
+
+	// 调回原阈值后重新产生
+	h.a.UpdateConfig(cfg)
+	h.step()
+	h.expectActive("cpu", 1)
+
+	// 分析周期运行期间反复修改配置（配合 -race 检查配置读取没有数据竞争）
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			next := cfg
+			if i%2 == 1 {
+				next = raised
+			}
+			h.a.UpdateConfig(next)
+		}
+	}()
+	for i := 0; i < 200; i++ {
+		h.step()
+	}
+	close(stop)
+	<-done
+
+	// 停止修改后的周期按最后的配置评估
+	h.a.UpdateConfig(cfg)
+	h.step()
+	h.expectActive("cpu", 1)
+}
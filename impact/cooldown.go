@@ -0,0 +1,42 @@
+package impact
+
+import (
+	"time"
+
+	"monitor-agent/types"
+)
+
+// 日志冷却：持续存在但反复解除/产生的影响（如在阈值附近波动的指标）每次产生都会写一条日志。
+// log_cooldown_seconds 大于 0 时，同一（目标、影响源、类型）在冷却期内再次产生不写日志，
+// 其解除也不写日志；影响事件本身照常产生、推送和计入排行，界面上持续可见。
+
+// loggedImpact 影响最近一次写入日志的时间和事件 ID
+type loggedImpact struct {
+	at time.Time
+	id string
+}
+
+// shouldLogLocked 判断新产生的影响是否写入日志，冷却期按本周期的配置快照，写入时记录时间和 ID（调用方需持有 mu）
+func (a *ImpactAnalyzer) shouldLogLocked(key impactKey, event *types.ImpactEvent) bool {
+	cooldown := time.Duration(a.cycleConfig.LogCooldownSeconds) * time.Second
+	if last, ok := a.lastLogged[key]; ok && cooldown > 0 && event.Timestamp.Sub(last.at) < cooldown {
+		return false
+	}
+	a.lastLogged[key] = loggedImpact{at: event.Timestamp, id: event.ID}
+	return true
+}
+
+// loggedLocked 影响产生时是否写入了日志，未记录产生的影响解除时也不记录（调用方需持有 mu）
+func (a *ImpactAnalyzer) loggedLocked(key impactKey, event *types.ImpactEvent) bool {
+	return a.lastLogged[key].id == event.ID
+}
+
+// pruneLoggedLocked 清除已不活跃且超过冷却期的日志记录（调用方需持有 mu）
+func (a *ImpactAnalyzer) pruneLoggedLocked(effective types.ImpactConfig, now time.Time) {
+	cooldown := time.Duration(effective.LogCooldownSeconds) * time.Second
+	for key, last := range a.lastLogged {
+		if _, active := a.activeImpacts[key]; !active && now.Sub(last.at) >= cooldown {
+			delete(a.lastLogged, key)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package impact
+
+import (
+	"sync"
+	"time"
+
+	"monitor-agent/logger"
+)
+
+// MaxAnalysisWorkers analysis_workers 的上限
+const MaxAnalysisWorkers = 8
+
+// 周期负载控制：负载较高时一个分析周期（系统指标 + 完整进程列表 + 各项分析）可能超过分析间隔。
+// 定时器只负责触发，分析在单独的协程中运行；上一周期未结束时跳过本次触发，
+// 避免分析器首尾相接地运行而加重它正在度量的负载。
+
+// tick 定时触发一个分析周期，上一周期仍在运行时跳过（只在分析循环中调用，分析协程计入 runWG，Stop 等待其结束）
+func (a *ImpactAnalyzer) tick() {
+	if !a.busy.CompareAndSwap(false, true) {
+		a.recordSkip()
+		return
+	}
+	a.runWG.Add(1)
+	go func() {
+		defer a.runWG.Done()
+		defer a.busy.Store(false)
+		a.analyze()
+	}()
+}
+
+// recordSkip 记录一次跳过，连续跳过的周期数刚超过 skip_warn_cycles 时记录 WARN（持续跳过期间不重复）
+func (a *ImpactAnalyzer) recordSkip() {
+	a.mu.Lock()
+	a.skippedCycles++
+	a.consecutiveSkips++
+	skips, limit, interval := a.consecutiveSkips, a.config.SkipWarnCycles, a.config.AnalysisInterval
+	a.mu.Unlock()
+
+	if skips == limit+1 {
+		logger.Warnf("IMPACT", "Analysis cycle still running, skipped %d consecutive cycles (interval=%ds)",
+			skips, interval)
+	}
+}
+
+// recordCycleTime 分析周期结束时记录耗时：超过分析间隔时记录 WARN，并清零连续跳过计数
+func (a *ImpactAnalyzer) recordCycleTime(duration time.Duration) {
+	a.mu.Lock()
+	interval := time.Duration(a.config.AnalysisInterval) * time.Second
+	slow := duration > interval
+	if slow {
+		a.slowCycles++
+	}
+	skips, limit := a.consecutiveSkips, a.config.SkipWarnCycles
+	a.consecutiveSkips = 0
+	a.mu.Unlock()
+
+	if slow {
+		logger.Warnf("IMPACT", "Analysis cycle took %v, longer than interval %v", duration.Round(time.Millisecond), interval)
+	}
+	if skips > limit {
+		logger.Infof("IMPACT", "Analysis caught up after %d skipped cycles", skips)
+	}
+}
+
+// runPasses 执行本周期的各项分析：workers（本周期快照中的 analysis_workers）大于 1 时由相应数量的协程并发执行，否则按顺序执行
+func (a *ImpactAnalyzer) runPasses(workers int, passes []func()) {
+	if workers > MaxAnalysisWorkers {
+		workers = MaxAnalysisWorkers
+	}
+	if workers > len(passes) {
+		workers = len(passes)
+	}
+	if workers <= 1 {
+		for _, pass := range passes {
+			pass()
+		}
+		return
+	}
+
+	queue := make(chan func(), len(passes))
+	for _, pass := range passes {
+		queue <- pass
+	}
+	close(queue)
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for pass := range queue {
+				pass()
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,89 @@
+package impact
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"monitor-agent/types"
+)
+
+func TestRunPassesRunsEachPassOnce(t *testing.T) {
+	a := &ImpactAnalyzer{}
+	for _, workers := range []int{0, 1, 3, MaxAnalysisWorkers + 4} {
+		counts := make([]int32, 12)
+		passes := make([]func(), len(counts))
+		for i := range passes {
+			i := i
+			passes[i] = func() { atomic.AddInt32(&counts[i], 1) }
+		}
+		a.runPasses(workers, passes)
+		for i, n := range counts {
+			if n != 1 {
+				t.Fatalf("workers=%d: pass %d ran %d times, want 1", workers, i, n)
+			}
+		}
+	}
+}
+
+// 并发执行各项分析的同时修改配置：需配合 -race 运行，各项分析只使用周期开始时的配置快照
+func TestParallelPassesWithConcurrentUpdateConfig(t *testing.T) {
+	cfg := testConfig()
+	cfg.AnalysisWorkers = 4
+	cfg.ProcCPUThreshold = 50
+	cfg.ProcMemoryThreshold = 500 // MB
+	cfg.TrendWindowSeconds = 60
+	h := newHarness(t, cfg, target(testTargetPID, "scada"), target(testTarget2PID, "historian"))
+	hog := proc(testSourcePID, "compiler", 90)
+	hog.RSSBytes = 800 << 20
+	h.prov.SetProcesses([]types.ProcessInfo{
+		proc(testTargetPID, "scada", 10),
+		proc(testTarget2PID, "historian", 10),
+		hog,
+	})
+
+	h.step()
+	h.expectActive("cpu", 2)
+	h.expectActive("memory", 2)
+
+	relaxed := cfg
+	relaxed.ProcCPUThreshold = 95
+	relaxed.ProcMemoryThreshold = 1000
+	relaxed.AnalysisWorkers = 2
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			if i%2 == 0 {
+				h.a.UpdateConfig(relaxed)
+			} else {
+				h.a.UpdateConfig(cfg)
+			}
+			h.a.GetEffectiveConfig()
+			h.a.Diagnostics()
+		}
+	}()
+	for i := 0; i < 200; i++ {
+		h.step()
+	}
+	close(stop)
+	wg.Wait()
+
+	// 停止修改后按最后的配置评估：并发执行与顺序执行的结果一致
+	h.a.UpdateConfig(relaxed)
+	h.step()
+	h.expectActive("cpu", 0)
+	h.expectActive("memory", 0)
+	h.a.UpdateConfig(cfg)
+	h.step()
+	h.expectActive("cpu", 2)
+	h.expectActive("memory", 2)
+}
@@ -0,0 +1,106 @@
+package impact
+
+import (
+	"time"
+
+	"monitor-agent/types"
+)
+
+// 运行诊断：分析协程在周期内把检测结果记在 scratch 中，周期结束时连同文件/端口缓存状态
+// 一起复制到 diag（持有 mu），Diagnostics 只读取这份快照，不与分析协程竞争检测器的状态
+
+// 各项检测的名称（ImpactDiagnostics.Errors 的键）
+const (
+	checkSystem    = "system"
+	checkProcesses = "processes"
+	checkPort      = "port"
+	checkFile      = "file"
+)
+
+// diagScratch 分析协程记录的诊断数据，只在分析协程中使用
+type diagScratch struct {
+	processes   int
+	connections int
+	errors      map[string]types.ImpactCheckError
+}
+
+// setCheckError 记录一项检测的结果：失败时保存错误，成功时清除之前的错误
+func (a *ImpactAnalyzer) setCheckError(check string, err error) {
+	if err == nil {
+		delete(a.scratch.errors, check)
+		return
+	}
+	if a.scratch.errors == nil {
+		a.scratch.errors = make(map[string]types.ImpactCheckError)
+	}
+	a.scratch.errors[check] = types.ImpactCheckError{Error: err.Error(), Time: a.clock.Now()}
+}
+
+// finishCycle 分析周期结束时发布诊断快照，start 为周期开始时间
+func (a *ImpactAnalyzer) finishCycle(start time.Time) {
+	duration := a.clock.Since(start)
+	a.recordCycleTime(duration)
+	d := types.ImpactDiagnostics{
+		LastRun:            timePtr(start),
+		LastDurationMs:     float64(duration.Microseconds()) / 1000,
+		Processes:          a.scratch.processes,
+		LastPortCheck:      timePtr(a.lastPortCheck),
+		PortsRefreshedAt:   timePtr(a.targetPortsTime),
+		ConnectionsScanned: a.scratch.connections,
+		LastFileCheck:      timePtr(a.lastFileCheck),
+		FilesRefreshedAt:   timePtr(a.targetFilesTime),
+		Targets:            []types.ImpactTargetDiagnostics{},
+		Errors:             make(map[string]types.ImpactCheckError, len(a.scratch.errors)),
+	}
+	d.OpenFilesIndexed, d.OpenFilesUnreadable = a.fileChecker.Stats()
+	for check, e := range a.scratch.errors {
+		d.Errors[check] = e
+	}
+	for _, t := range a.targets() {
+		d.Targets = append(d.Targets, types.ImpactTargetDiagnostics{
+			PID:         t.PID,
+			Name:        a.getTargetDisplayName(t),
+			ListenPorts: len(a.targetPorts[t.PID]),
+			WatchPorts:  len(t.WatchPorts),
+			OpenFiles:   len(a.targetFiles[t.PID]),
+			WatchFiles:  len(t.WatchFiles),
+		})
+	}
+
+	a.mu.Lock()
+	d.Cycles = a.diag.Cycles + 1
+	a.diag = d
+	a.mu.Unlock()
+}
+
+// Diagnostics 返回最近一个分析周期的诊断快照和当前的检测间隔
+func (a *ImpactAnalyzer) Diagnostics() types.ImpactDiagnostics {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	d := a.diag
+	d.Enabled = a.config.Enabled
+	d.Running = a.running
+	d.AnalysisInterval = a.config.AnalysisInterval
+	d.AnalysisWorkers = a.effective.AnalysisWorkers
+	d.SkippedCycles = a.skippedCycles
+	d.ConsecutiveSkips = a.consecutiveSkips
+	d.SlowCycles = a.slowCycles
+	d.PortCheckInterval = a.effective.PortCheckInterval
+	d.FileCheckInterval = a.effective.FileCheckInterval
+	if d.Targets == nil {
+		d.Targets = []types.ImpactTargetDiagnostics{}
+	}
+	d.Errors = make(map[string]types.ImpactCheckError, len(a.diag.Errors))
+	for check, e := range a.diag.Errors {
+		d.Errors[check] = e
+	}
+	return d
+}
+
+// timePtr 零值时间返回 nil（对应 JSON 中省略）
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,258 @@
+package impact
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/disk"
+
+	"monitor-agent/format"
+	"monitor-agent/i18n"
+	"monitor-agent/types"
+)
+
+// 目标目录占用：保障对象往往是因为自己的数据、日志目录所在磁盘写满而退出，而不是系统盘。
+// 后台协程按 file_check_interval 统计各目标 WatchDirs 的大小和所在文件系统的剩余空间，
+// 分析周期按最新的统计结果产生 dir_growth（增长过快）和 dir_full（剩余空间不足）影响。
+// 遍历随分析器停止而中止，并且每遍历一批条目暂停一次，避免很大的归档目录树占满一个核心。
+
+const (
+	dirScanBatch      = 1000                  // 每遍历该数量的条目暂停一次
+	dirScanPause      = 20 * time.Millisecond // 每批之间的暂停，遍历速度上限约 5 万条目/秒
+	dirScanMaxEntries = 2000000               // 单个目录最多遍历的条目数，超出时停止并标记 truncated
+	dirGrowthWindow   = time.Hour             // 增长速度按该窗口内最早的统计计算
+	dirGrowthMinSpan  = 5 * time.Minute       // 计算增长速度至少需要的统计跨度
+)
+
+// errDirScanLimit 遍历的条目数达到上限
+var errDirScanLimit = errors.New("directory entry limit reached")
+
+// dirSample 一次目录大小统计
+type dirSample struct {
+	at   time.Time
+	size uint64
+}
+
+// DirUsageTracker 统计目标监控目录的占用，保存每个目标最近一次的结果
+type DirUsageTracker struct {
+	mu      sync.RWMutex
+	results map[int32][]types.DirUsage // 目标 PID -> 各目录的统计结果
+	samples map[string][]dirSample     // 目录 -> 最近一小时的大小统计，用于计算增长速度
+}
+
+// NewDirUsageTracker 创建目录占用统计
+func NewDirUsageTracker() *DirUsageTracker {
+	return &DirUsageTracker{
+		results: make(map[int32][]types.DirUsage),
+		samples: make(map[string][]dirSample),
+	}
+}
+
+// Get 返回目标各目录最近一次的统计结果，尚未统计时返回 nil
+func (t *DirUsageTracker) Get(pid int32) []types.DirUsage {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return append([]types.DirUsage(nil), t.results[pid]...)
+}
+
+// scan 统计所有目标的监控目录（多个目标监控同一目录时只遍历一次），ctx 取消时放弃本轮结果
+func (t *DirUsageTracker) scan(ctx context.Context, targets []types.MonitorTarget, now func() time.Time) {
+	usage := make(map[string]types.DirUsage)
+	results := make(map[int32][]types.DirUsage)
+	for _, target := range targets {
+		for _, dir := range target.WatchDirs {
+			u, ok := usage[dir]
+			if !ok {
+				u = t.scanOne(ctx, dir, now())
+				if ctx.Err() != nil {
+					return
+				}
+				usage[dir] = u
+			}
+			results[target.PID] = append(results[target.PID], u)
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.results = results
+	for dir := range t.samples {
+		if _, ok := usage[dir]; !ok {
+			delete(t.samples, dir)
+		}
+	}
+}
+
+// scanOne 统计一个目录，并按最近一小时的统计计算增长速度
+func (t *DirUsageTracker) scanOne(ctx context.Context, dir string, at time.Time) types.DirUsage {
+	u := types.DirUsage{Path: dir, ScannedAt: at}
+	start := time.Now()
+	size, files, truncated, err := walkDirSize(ctx, dir)
+	u.ScanMillis = time.Since(start).Milliseconds()
+	if err != nil {
+		u.Error = err.Error()
+		return u
+	}
+	u.SizeBytes, u.Files, u.Truncated = size, files, truncated
+	if du, err := disk.Usage(dir); err == nil {
+		u.FreeBytes, u.TotalBytes = du.Free, du.Total
+	}
+	if truncated {
+		return u // 只统计了一部分，不参与增长速度计算
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	samples := append(t.samples[dir], dirSample{at: at, size: size})
+	for len(samples) > 1 && at.Sub(samples[0].at) > dirGrowthWindow {
+		samples = samples[1:]
+	}
+	t.samples[dir] = samples
+	if span := at.Sub(samples[0].at); span >= dirGrowthMinSpan {
+		growth := (float64(size) - float64(samples[0].size)) / 1024 / 1024 / span.Hours()
+		u.GrowthMBPerHour = &growth
+	}
+	return u
+}
+
+// walkDirSize 遍历目录，返回普通文件的大小之和和文件数。
+// 子目录、文件读取失败（如权限不足）时跳过，目录本身无法读取时返回错误；
+// 每遍历 dirScanBatch 个条目暂停 dirScanPause，超过 dirScanMaxEntries 时停止并返回 truncated
+func walkDirSize(ctx context.Context, dir string) (size uint64, files int, truncated bool, err error) {
+	entries := 0
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir {
+				return err
+			}
+			if d != nil && d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		entries++
+		if entries > dirScanMaxEntries {
+			truncated = true
+			return errDirScanLimit
+		}
+		if entries%dirScanBatch == 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(dirScanPause):
+			}
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil // 遍历期间被删除
+		}
+		size += uint64(info.Size())
+		files++
+		return nil
+	})
+	if errors.Is(err, errDirScanLimit) {
+		err = nil
+	}
+	return size, files, truncated, err
+}
+
+// dirLoop 后台统计目标目录占用，每轮结束后等待 file_check_interval；stopCh 关闭时中止正在进行的遍历并退出
+func (a *ImpactAnalyzer) dirLoop(stopCh chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-stopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	for {
+		a.dirUsage.scan(ctx, a.targets(), a.clock.Now)
+
+		a.mu.RLock()
+		interval := time.Duration(a.config.FileCheckInterval) * time.Second
+		a.mu.RUnlock()
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// GetDirUsage 获取目标各监控目录最近一次的统计结果
+func (a *ImpactAnalyzer) GetDirUsage(pid int32) []types.DirUsage {
+	return a.dirUsage.Get(pid)
+}
+
+// analyzeDirUsage 按最近一次的统计结果检测目标目录增长过快和所在磁盘剩余空间不足
+func (a *ImpactAnalyzer) analyzeDirUsage(effective types.ImpactConfig, sys *types.SystemMetrics, targets []types.MonitorTarget, procMap map[int32]*types.ProcessInfo) {
+	a.beginPass("dir_growth")
+	a.beginPass("dir_full")
+
+	for _, target := range targets {
+		targetProc := procMap[target.PID]
+		if targetProc == nil || len(target.WatchDirs) == 0 {
+			continue
+		}
+		cfg := targetConfig(effective, target)
+		for _, u := range a.dirUsage.Get(target.PID) {
+			if u.Error != "" {
+				continue
+			}
+			base := types.ImpactEvent{
+				Timestamp:  a.cycleStart,
+				TargetPID:  target.PID,
+				TargetName: a.getTargetDisplayName(target),
+				SourcePID:  target.PID,
+				SourceName: targetProc.Name,
+				Metrics: types.ImpactMetrics{
+					SystemCPU:    sys.CPUPercent,
+					SystemMemory: sys.MemoryPercent,
+					TargetCPU:    targetProc.CPUPct,
+					TargetMemory: targetProc.RSSBytes,
+					ConflictFile: u.Path,
+				},
+			}
+			freeMB := float64(u.FreeBytes) / 1024 / 1024
+
+			if cfg.DirMinFreeMB > 0 && u.TotalBytes > 0 && freeMB < cfg.DirMinFreeMB {
+				event := base
+				event.ImpactType = "dir_full"
+				event.Severity = "high"
+				if freeMB < cfg.DirMinFreeMB/2 {
+					event.Severity = "critical"
+				}
+				event.Description = i18n.T("impact.dir_full.desc", u.Path, format.Bytes(u.FreeBytes), cfg.DirMinFreeMB, format.Bytes(u.SizeBytes))
+				event.Suggestion = i18n.T("impact.dir_full.suggestion", u.Path)
+				a.emit(event, "dir:"+u.Path)
+			}
+
+			if g := u.GrowthMBPerHour; cfg.DirGrowthThreshold > 0 && g != nil && *g > cfg.DirGrowthThreshold {
+				event := base
+				event.ImpactType = "dir_growth"
+				event.Severity = "medium"
+				switch {
+				case u.TotalBytes > 0 && freeMB < *g:
+					event.Severity = "critical" // 按当前速度一小时内写满
+				case *g > cfg.DirGrowthThreshold*2:
+					event.Severity = "high"
+				}
+				event.Description = i18n.T("impact.dir_growth.desc", u.Path, *g, cfg.DirGrowthThreshold, format.Bytes(u.SizeBytes), format.Bytes(u.FreeBytes))
+				event.Suggestion = i18n.T("impact.dir_growth.suggestion", u.Path)
+				a.emit(event, "dir:"+u.Path)
+			}
+		}
+	}
+}
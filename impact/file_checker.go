@@ -28,6 +28,7 @@ type FileChecker struct {
 	// 缓存：文件路径 -> 打开该文件的进程列表
 	fileToProcs     map[string][]OpenFileInfo
 	lastRefreshTime int64 // Unix timestamp
+	unreadable      int   // 上次刷新时无法读取打开文件的进程数
 }
 
 // NewFileChecker 创建文件检测器
@@ -37,19 +38,20 @@ func NewFileChecker() *FileChecker {
 	}
 }
 
-// RefreshOpenFiles 刷新所有进程的打开文件缓存
+// RefreshOpenFiles 刷新所有进程的打开文件缓存，无法列出进程时返回错误（单个进程读取失败只计数）
 // 这个操作较重，应该低频调用（如每 30-60 秒）
-func (c *FileChecker) RefreshOpenFiles(excludePIDs map[int32]bool) {
+func (c *FileChecker) RefreshOpenFiles(excludePIDs map[int32]bool) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	// 重建缓存
 	c.fileToProcs = make(map[string][]OpenFileInfo)
+	c.unreadable = 0
 
 	// 获取所有进程
 	procs, err := process.Processes()
 	if err != nil {
-		return
+		return err
 	}
 
 	for _, proc := range procs {
@@ -62,6 +64,7 @@ func (c *FileChecker) RefreshOpenFiles(excludePIDs map[int32]bool) {
 		// 获取进程打开的文件
 		files, err := proc.OpenFiles()
 		if err != nil {
+			c.unreadable++
 			continue
 		}
 
@@ -73,7 +76,7 @@ func (c *FileChecker) RefreshOpenFiles(excludePIDs map[int32]bool) {
 
 		for _, f := range files {
 			// 规范化路径
-			filePath := normalizePath(f.Path)
+			filePath := NormalizePath(f.Path)
 			if filePath == "" {
 				continue
 			}
@@ -91,6 +94,14 @@ func (c *FileChecker) RefreshOpenFiles(excludePIDs map[int32]bool) {
 			c.fileToProcs[filePath] = append(c.fileToProcs[filePath], info)
 		}
 	}
+	return nil
+}
+
+// Stats 返回上次刷新索引的文件路径数和无法读取打开文件的进程数
+func (c *FileChecker) Stats() (indexed, unreadable int) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.fileToProcs), c.unreadable
 }
 
 // GetFilesOpenedByPID 获取指定进程打开的所有文件
@@ -107,7 +118,7 @@ func (c *FileChecker) GetFilesOpenedByPID(pid int32) []string {
 
 	var result []string
 	for _, f := range files {
-		filePath := normalizePath(f.Path)
+		filePath := NormalizePath(f.Path)
 		if filePath == "" || shouldSkipFile(filePath) {
 			continue
 		}
@@ -167,7 +178,7 @@ func (c *FileChecker) CheckFile(filePath string, excludePID int32) []FileConflic
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	filePath = normalizePath(filePath)
+	filePath = NormalizePath(filePath)
 	procs, ok := c.fileToProcs[filePath]
 	if !ok {
 		return nil
@@ -196,8 +207,8 @@ func (c *FileChecker) CheckFiles(files []string, excludePID int32) map[string][]
 	return result
 }
 
-// normalizePath 规范化文件路径
-func normalizePath(path string) string {
+// NormalizePath 规范化文件路径
+func NormalizePath(path string) string {
 	if path == "" {
 		return ""
 	}
@@ -217,13 +228,8 @@ func shouldSkipFile(path string) bool {
 		return true
 	}
 
-	// Linux: 跳过特殊文件系统
-	if strings.HasPrefix(path, "/proc/") ||
-		strings.HasPrefix(path, "/sys/") ||
-		strings.HasPrefix(path, "/dev/") ||
-		strings.Contains(path, "socket:") ||
-		strings.Contains(path, "pipe:") ||
-		strings.Contains(path, "anon_inode:") {
+	// 跳过套接字、管道、设备和特殊文件系统（见 classifyOpenFile），以及 /dev/shm 共享内存
+	if classifyOpenFile(path) != OpenFileTypeFile || strings.HasPrefix(path, "/dev/") {
 		return true
 	}
 
@@ -0,0 +1,115 @@
+package impact
+
+import (
+	"sort"
+	"strings"
+
+	"monitor-agent/i18n"
+	"monitor-agent/types"
+)
+
+// groupTopContributors 分组条目中列出的影响源数量
+const groupTopContributors = 5
+
+// severityRank 严重级别排序值，越严重越大
+func severityRank(severity string) int {
+	switch severity {
+	case "critical":
+		return 3
+	case "high":
+		return 2
+	case "medium":
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Severities 严重级别，按从低到高排列
+var Severities = []string{"low", "medium", "high", "critical"}
+
+// IsSeverity 判断是否为已知严重级别
+func IsSeverity(severity string) bool {
+	for _, s := range Severities {
+		if s == severity {
+			return true
+		}
+	}
+	return false
+}
+
+// AtLeastSeverity 严重级别是否不低于 min，min 为空表示不限制
+func AtLeastSeverity(severity, min string) bool {
+	return min == "" || severityRank(severity) >= severityRank(min)
+}
+
+// groupImpacts 将同一目标、同一影响类型的多个影响事件合并为一条
+// 合并条目取最高严重级别和最新时间，建议和指标取最严重的影响源，Contributors 列出影响最大的几个来源；
+// 只有一个影响源的事件原样保留。输入需按时间排序，输出按合并后的时间排序
+func groupImpacts(events []types.ImpactEvent) []types.ImpactEvent {
+	type groupKey struct {
+		TargetPID  int32
+		ImpactType string
+	}
+	groups := make(map[groupKey][]types.ImpactEvent)
+	var order []groupKey
+	for _, e := range events {
+		key := groupKey{e.TargetPID, e.ImpactType}
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], e)
+	}
+
+	result := make([]types.ImpactEvent, 0, len(order))
+	for _, key := range order {
+		members := groups[key]
+		if len(members) == 1 {
+			result = append(result, members[0])
+			continue
+		}
+		result = append(result, mergeImpactGroup(members))
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Timestamp.Before(result[j].Timestamp)
+	})
+	return result
+}
+
+// mergeImpactGroup 合并同组影响事件，影响源按严重级别从高到低、持续时间从长到短排列
+func mergeImpactGroup(members []types.ImpactEvent) types.ImpactEvent {
+	sort.SliceStable(members, func(i, j int) bool {
+		ri, rj := severityRank(members[i].Severity), severityRank(members[j].Severity)
+		if ri != rj {
+			return ri > rj
+		}
+		return members[i].Timestamp.Before(members[j].Timestamp)
+	})
+
+	merged := members[0]
+	merged.SourcePID = 0
+	merged.SourceName = i18n.T("impact.group.sources", len(members))
+	merged.Count = len(members)
+	merged.Contributors = make([]types.ImpactContributor, 0, groupTopContributors)
+
+	names := make([]string, 0, groupTopContributors)
+	for i, m := range members {
+		if m.Timestamp.After(merged.Timestamp) {
+			merged.Timestamp = m.Timestamp
+		}
+		merged.Suppressed = merged.Suppressed && m.Suppressed
+		if i < groupTopContributors {
+			merged.Contributors = append(merged.Contributors, types.ImpactContributor{
+				ID:          m.ID,
+				SourcePID:   m.SourcePID,
+				SourceName:  m.SourceName,
+				Severity:    m.Severity,
+				Description: m.Description,
+			})
+			names = append(names, m.SourceName)
+		}
+	}
+	merged.Description = i18n.T("impact.group.desc", strings.Join(names, ", "), len(members))
+	return merged
+}
@@ -0,0 +1,51 @@
+package impact
+
+// HealthScore 根据活跃影响事件计算健康评分（0-100）
+// 每个活跃事件按严重级别扣除配置中的权重分值，严重事件扣分最重；维护中被抑制的事件不扣分
+func (a *ImpactAnalyzer) HealthScore() int {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.healthScoreLocked()
+}
+
+// healthScoreLocked 计算健康评分（调用方需持有读锁）
+func (a *ImpactAnalyzer) healthScoreLocked() int {
+	penalty := 0.0
+	for _, imp := range a.activeImpacts {
+		if imp.Suppressed {
+			continue
+		}
+		switch imp.Severity {
+		case "critical":
+			penalty += a.config.ScoreWeightCritical
+		case "high":
+			penalty += a.config.ScoreWeightHigh
+		case "medium":
+			penalty += a.config.ScoreWeightMedium
+		default:
+			penalty += a.config.ScoreWeightLow
+		}
+	}
+
+	score := 100 - int(penalty+0.5)
+	if score < 0 {
+		score = 0
+	}
+	return score
+}
+
+// HealthGrade 将健康评分映射为等级（A-F）
+func HealthGrade(score int) string {
+	switch {
+	case score >= 90:
+		return "A"
+	case score >= 80:
+		return "B"
+	case score >= 70:
+		return "C"
+	case score >= 60:
+		return "D"
+	default:
+		return "F"
+	}
+}
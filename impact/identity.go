@@ -0,0 +1,93 @@
+package impact
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"monitor-agent/types"
+)
+
+// 影响事件标识：事件首次成为正式影响时按 目标PID+类型+来源PID+详情+首次发现时间 生成 ID，
+// 同一活跃影响的后续检测沿用 ID、首次发现时间和检测时的指标历史，便于 Web 界面深链接
+// 以及将事件日志中的 EVENT 行与影响记录关联。已解除的影响保留最近若干条，解除后仍可按 ID 查询。
+
+const (
+	// impactHistorySamples 检测时保存的目标指标历史采样数
+	impactHistorySamples = 30
+	// resolvedImpactsKeep 保留可按 ID 查询的已解除影响数
+	resolvedImpactsKeep = 200
+)
+
+// impactID 生成影响事件 ID
+func impactID(key impactKey, firstSeen time.Time) string {
+	sum := sha1.Sum([]byte(fmt.Sprintf("%d|%s|%d|%s|%d",
+		key.TargetPID, key.ImpactType, key.SourcePID, key.Detail, firstSeen.UnixNano())))
+	return hex.EncodeToString(sum[:8])
+}
+
+// SetMetricsSource 设置目标指标历史来源（返回目标最近 n 个采样），用于在影响产生时保存检测时的指标
+func (a *ImpactAnalyzer) SetMetricsSource(fn func(pid int32, n int) []types.ProcessMetrics) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.metricsSource = fn
+}
+
+// assignIdentityLocked 为影响事件分配 ID 和首次发现时间，同一活跃影响沿用之前的值（调用方需持有 mu）
+func assignIdentityLocked(event *types.ImpactEvent, key impactKey, prev *types.ImpactEvent, isNew bool) {
+	if !isNew {
+		event.ID = prev.ID
+		event.FirstSeen = prev.FirstSeen
+		event.History = prev.History
+		return
+	}
+	event.FirstSeen = event.Timestamp
+	event.ID = impactID(key, event.FirstSeen)
+}
+
+// captureHistory 保存影响产生时目标的指标历史（不持有 mu 调用，指标来源会获取监控器的锁）
+func (a *ImpactAnalyzer) captureHistory(key impactKey, stored *types.ImpactEvent) {
+	a.mu.RLock()
+	source := a.metricsSource
+	a.mu.RUnlock()
+	if source == nil {
+		return
+	}
+	history := source(key.TargetPID, impactHistorySamples)
+	if len(history) == 0 {
+		return
+	}
+
+	a.mu.Lock()
+	if a.activeImpacts[key] == stored {
+		stored.History = history
+	}
+	a.mu.Unlock()
+}
+
+// rememberResolvedLocked 保留已解除的影响，供按 ID 查询（调用方需持有 mu）
+func (a *ImpactAnalyzer) rememberResolvedLocked(event *types.ImpactEvent) {
+	a.resolved = append(a.resolved, *event)
+	if len(a.resolved) > resolvedImpactsKeep {
+		a.resolved = a.resolved[len(a.resolved)-resolvedImpactsKeep:]
+	}
+}
+
+// GetImpact 按 ID 获取影响事件（含检测时的指标历史），先查活跃影响，再查最近解除的影响
+func (a *ImpactAnalyzer) GetImpact(id string) (types.ImpactEvent, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	for _, imp := range a.activeImpacts {
+		if imp.ID == id {
+			return *imp, true
+		}
+	}
+	for i := len(a.resolved) - 1; i >= 0; i-- {
+		if a.resolved[i].ID == id {
+			return a.resolved[i], true
+		}
+	}
+	return types.ImpactEvent{}, false
+}
@@ -0,0 +1,73 @@
+package impact
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"monitor-agent/types"
+)
+
+// 并发反复启停影响分析器：需配合 -race 运行，结束后不遗留协程、不死锁
+func TestAnalyzerConcurrentStartStop(t *testing.T) {
+	base := runtime.NumGoroutine()
+
+	cfg := testConfig()
+	cfg.AnalysisInterval = 1
+	h := newHarness(t, cfg, target(testTargetPID, "scada"))
+	h.prov.SetProcesses([]types.ProcessInfo{proc(testTargetPID, "scada", 10)})
+	a := h.a
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		var wg sync.WaitGroup
+		for w := 0; w < 8; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				for i := 0; i < 50; i++ {
+					if (i+w)%2 == 0 {
+						if err := a.Start(); err != nil && err != ErrAlreadyRunning {
+							t.Errorf("Start: %v", err)
+						}
+					} else {
+						a.Stop()
+					}
+					a.IsRunning()
+				}
+			}(w)
+		}
+		wg.Wait()
+		a.Stop()
+	}()
+	select {
+	case <-done:
+	case <-time.After(30 * time.Second):
+		buf := make([]byte, 1<<20)
+		t.Fatalf("start/stop hung\n%s", buf[:runtime.Stack(buf, true)])
+	}
+
+	if a.IsRunning() {
+		t.Fatal("running after Stop")
+	}
+	if err := a.Start(); err != nil {
+		t.Fatalf("Start after Stop: %v", err)
+	}
+	if err := a.Start(); err != ErrAlreadyRunning {
+		t.Fatalf("second Start = %v, want ErrAlreadyRunning", err)
+	}
+	// 运行超过一个间隔，Stop 需等待进行中的周期结束
+	time.Sleep(1200 * time.Millisecond)
+	a.Stop()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > base {
+		if time.Now().After(deadline) {
+			buf := make([]byte, 1<<20)
+			t.Fatalf("goroutines = %d, want <= %d\n%s", runtime.NumGoroutine(), base, buf[:runtime.Stack(buf, true)])
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
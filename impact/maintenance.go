@@ -0,0 +1,97 @@
+package impact
+
+import (
+	"fmt"
+	"time"
+
+	"monitor-agent/i18n"
+	"monitor-agent/logger"
+	"monitor-agent/types"
+)
+
+// 计划维护时段：备份、批处理等计划作业期间资源尖峰是预期的。时段内分析照常运行、指标照常采集，
+// 但影响事件标记为已抑制（与维护模式相同），时段开始和结束各记录一条运行事件和日志
+
+// ValidateMaintenanceWindows 校验计划维护时段：名称必填且不重复，窗口格式与阈值时段相同
+func ValidateMaintenanceWindows(windows []types.MaintenanceSchedule) error {
+	names := make(map[string]bool)
+	for i, w := range windows {
+		if w.Name == "" {
+			return fmt.Errorf("maintenance window #%d: name is required", i+1)
+		}
+		if names[w.Name] {
+			return fmt.Errorf("maintenance window %q: duplicate name", w.Name)
+		}
+		names[w.Name] = true
+		if _, err := parseProfileWindow(w.Window); err != nil {
+			return fmt.Errorf("maintenance window %q: %w", w.Name, err)
+		}
+	}
+	return nil
+}
+
+// activeMaintenanceWindow 返回 t 时刻所在的计划维护时段（多个匹配时取第一个），无匹配返回 nil
+// 窗口格式错误的时段被忽略
+func activeMaintenanceWindow(windows []types.MaintenanceSchedule, t time.Time) *types.MaintenanceSchedule {
+	for i := range windows {
+		w, err := parseProfileWindow(windows[i].Window)
+		if err == nil && w.contains(t) {
+			active := windows[i]
+			return &active
+		}
+	}
+	return nil
+}
+
+// refreshMaintenanceWindowLocked 按当前时间更新所在的计划维护时段，返回更新前后的时段，调用方需持有写锁
+func (a *ImpactAnalyzer) refreshMaintenanceWindowLocked(now time.Time) (prev, cur *types.MaintenanceSchedule) {
+	prev = a.maintWindow
+	a.maintWindow = activeMaintenanceWindow(a.config.MaintenanceWindows, now)
+	return prev, a.maintWindow
+}
+
+// reportMaintenanceWindow 计划维护时段变化时记录日志和运行事件，进入时段时抑制已有的影响事件
+func (a *ImpactAnalyzer) reportMaintenanceWindow(prev, cur *types.MaintenanceSchedule) {
+	if windowName(prev) == windowName(cur) {
+		return
+	}
+	a.mu.RLock()
+	callback := a.eventCallback
+	a.mu.RUnlock()
+	all := i18n.T("maintenance.all_targets")
+
+	if prev != nil {
+		logger.Infof("IMPACT", "Scheduled maintenance window %q ended, impact alerts resumed", prev.Name)
+		if callback != nil {
+			callback("maintenance_end", 0, all, i18n.T("maintenance.window_end", prev.Name))
+		}
+	}
+	if cur != nil {
+		a.SuppressTarget(0)
+		logger.Infof("IMPACT", "Scheduled maintenance window %q (%s) started, impacts are suppressed", cur.Name, cur.Window)
+		if callback != nil {
+			callback("maintenance_start", 0, all, i18n.T("maintenance.window_start", cur.Name, cur.Window, reasonOrDefault(cur.Reason)))
+		}
+	}
+}
+
+// ActiveMaintenanceWindow 返回当前所在的计划维护时段名称，空字符串表示不在时段内
+func (a *ImpactAnalyzer) ActiveMaintenanceWindow() string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return windowName(a.maintWindow)
+}
+
+func windowName(w *types.MaintenanceSchedule) string {
+	if w == nil {
+		return ""
+	}
+	return w.Name
+}
+
+func reasonOrDefault(reason string) string {
+	if reason == "" {
+		return i18n.T("maintenance.no_reason")
+	}
+	return reason
+}
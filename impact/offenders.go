@@ -0,0 +1,226 @@
+package impact
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"monitor-agent/logger"
+	"monitor-agent/types"
+)
+
+const (
+	offendersFile         = "offenders.json"
+	offendersWindow       = 7 * 24 * time.Hour // 排行统计窗口（滚动 7 天，按小时粒度）
+	offendersSaveInterval = time.Minute
+)
+
+// offenderBucket 每小时的影响事件计数
+type offenderBucket struct {
+	BySeverity map[string]int `json:"by_severity"`
+	ByType     map[string]int `json:"by_type"`
+}
+
+type offenderRecord struct {
+	Hours    map[int64]*offenderBucket `json:"hours"` // Unix 小时序号 -> 计数
+	LastSeen time.Time                 `json:"last_seen"`
+}
+
+// OffenderTracker 按影响源进程名累计影响事件，并持久化到日志目录下的状态文件
+// 只统计其他进程对目标造成的影响，目标自身的指标（如优先级偏离）和系统级影响不计入
+type OffenderTracker struct {
+	mu        sync.Mutex
+	path      string
+	offenders map[string]*offenderRecord
+	lastSave  time.Time
+	dirty     bool
+}
+
+// NewOffenderTracker 创建影响源排行追踪器，并加载已有状态文件
+func NewOffenderTracker(logDir string) *OffenderTracker {
+	t := &OffenderTracker{
+		path:      filepath.Join(logDir, offendersFile),
+		offenders: make(map[string]*offenderRecord),
+		lastSave:  time.Now(),
+	}
+	if err := t.load(); err != nil {
+		logger.Warnf("IMPACT", "Load offender state failed: %v", err)
+	}
+	return t
+}
+
+func (t *OffenderTracker) load() error {
+	data, err := os.ReadFile(t.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	offenders := make(map[string]*offenderRecord)
+	if err := json.Unmarshal(data, &offenders); err != nil {
+		return err
+	}
+	for _, rec := range offenders {
+		if rec.Hours == nil {
+			rec.Hours = make(map[int64]*offenderBucket)
+		}
+	}
+	t.offenders = offenders
+	return nil
+}
+
+// Record 记录一次新产生的影响事件
+func (t *OffenderTracker) Record(event types.ImpactEvent) {
+	if event.SourceName == "" || event.SourcePID == 0 || event.SourcePID == event.TargetPID {
+		return
+	}
+
+	t.mu.Lock()
+	rec, ok := t.offenders[event.SourceName]
+	if !ok {
+		rec = &offenderRecord{Hours: make(map[int64]*offenderBucket)}
+		t.offenders[event.SourceName] = rec
+	}
+	hour := event.Timestamp.Unix() / 3600
+	b, ok := rec.Hours[hour]
+	if !ok {
+		b = &offenderBucket{BySeverity: make(map[string]int), ByType: make(map[string]int)}
+		rec.Hours[hour] = b
+	}
+	b.BySeverity[event.Severity]++
+	b.ByType[event.ImpactType]++
+	if event.Timestamp.After(rec.LastSeen) {
+		rec.LastSeen = event.Timestamp
+	}
+	t.dirty = true
+	due := time.Since(t.lastSave) >= offendersSaveInterval
+	t.mu.Unlock()
+
+	if due {
+		if err := t.Save(); err != nil {
+			logger.Warnf("IMPACT", "Save offender state failed: %v", err)
+		}
+	}
+}
+
+// Top 返回最近 7 天影响事件最多的 n 个进程（n<=0 表示全部）
+// 按事件总数排序，相同时严重级别高的次数多者在前
+func (t *OffenderTracker) Top(n int, now time.Time) []types.ImpactOffender {
+	fromHour := now.Add(-offendersWindow).Unix() / 3600
+
+	t.mu.Lock()
+	result := make([]types.ImpactOffender, 0, len(t.offenders))
+	for name, rec := range t.offenders {
+		o := types.ImpactOffender{
+			Name:       name,
+			BySeverity: make(map[string]int),
+			ByType:     make(map[string]int),
+			LastSeen:   rec.LastSeen,
+		}
+		for hour, b := range rec.Hours {
+			if hour < fromHour {
+				continue
+			}
+			for sev, c := range b.BySeverity {
+				o.BySeverity[sev] += c
+				o.Total += c
+			}
+			for typ, c := range b.ByType {
+				o.ByType[typ] += c
+			}
+		}
+		if o.Total == 0 {
+			continue
+		}
+		for typ, c := range o.ByType {
+			if c > o.ByType[o.TopType] || (c == o.ByType[o.TopType] && typ < o.TopType) {
+				o.TopType = typ
+			}
+		}
+		result = append(result, o)
+	}
+	t.mu.Unlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Total != b.Total {
+			return a.Total > b.Total
+		}
+		for _, sev := range []string{"critical", "high", "medium", "low"} {
+			if a.BySeverity[sev] != b.BySeverity[sev] {
+				return a.BySeverity[sev] > b.BySeverity[sev]
+			}
+		}
+		return a.Name < b.Name
+	})
+	if n > 0 && len(result) > n {
+		result = result[:n]
+	}
+	for i := range result {
+		result[i].Rank = i + 1
+	}
+	return result
+}
+
+// Save 清理过期数据并写入状态文件
+func (t *OffenderTracker) Save() error {
+	t.mu.Lock()
+	if !t.dirty {
+		t.mu.Unlock()
+		return nil
+	}
+	t.pruneLocked(time.Now())
+	data, err := json.Marshal(t.offenders)
+	t.dirty = false
+	t.lastSave = time.Now()
+	t.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(t.path), 0755); err != nil {
+		return err
+	}
+	tmp := t.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, t.path)
+}
+
+func (t *OffenderTracker) pruneLocked(now time.Time) {
+	cutoffHour := now.Add(-offendersWindow).Unix() / 3600
+	for name, rec := range t.offenders {
+		for hour := range rec.Hours {
+			if hour < cutoffHour {
+				delete(rec.Hours, hour)
+			}
+		}
+		if len(rec.Hours) == 0 {
+			delete(t.offenders, name)
+		}
+	}
+}
+
+// SetOffenderTracker 设置影响源排行追踪器（为 nil 时不统计）
+func (a *ImpactAnalyzer) SetOffenderTracker(t *OffenderTracker) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.offenders = t
+}
+
+// GetOffenders 获取最近 7 天影响目标最多的 n 个进程
+func (a *ImpactAnalyzer) GetOffenders(n int) []types.ImpactOffender {
+	a.mu.RLock()
+	t := a.offenders
+	a.mu.RUnlock()
+	if t == nil {
+		return []types.ImpactOffender{}
+	}
+	return t.Top(n, time.Now())
+}
@@ -0,0 +1,221 @@
+package impact
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+
+	"monitor-agent/provider"
+	"monitor-agent/types"
+)
+
+// 打开文件的类型
+const (
+	OpenFileTypeFile   = "file"
+	OpenFileTypeSocket = "socket"
+	OpenFileTypePipe   = "pipe"
+	OpenFileTypeDevice = "device"
+	OpenFileTypeOther  = "other" // anon_inode（eventfd、epoll 等）、/proc、/sys
+)
+
+const (
+	openFilesMaxEntries = 500 // 报告中最多列出的路径数
+	openFilesMaxDirs    = 100 // 报告中最多列出的目录数
+	openFilesMaxDiff    = 200 // 差异中最多列出的新增/关闭路径数
+)
+
+// classifyOpenFile 按路径判断打开文件的类型，支持原始路径和 NormalizePath 规范化后的路径
+// Linux 的套接字、管道形如 socket:[12345]、pipe:[12345]；Windows 的套接字为 \Device\Afd，命名管道为 \Device\NamedPipe
+func classifyOpenFile(p string) string {
+	switch {
+	case strings.Contains(p, "socket:"):
+		return OpenFileTypeSocket
+	case strings.Contains(p, "pipe:"):
+		return OpenFileTypePipe
+	case strings.Contains(p, "anon_inode:"),
+		strings.HasPrefix(p, "/proc/"), strings.HasPrefix(p, "/sys/"):
+		return OpenFileTypeOther
+	case strings.HasPrefix(p, "/dev/shm/"):
+		return OpenFileTypeFile // POSIX 共享内存
+	case strings.HasPrefix(p, "/dev/"):
+		return OpenFileTypeDevice
+	}
+
+	// Windows：规范化后盘符会加在 \Device 前（如 C:/Device/Afd）
+	lower := strings.ToLower(strings.ReplaceAll(p, `\`, "/"))
+	if len(lower) >= 2 && lower[1] == ':' {
+		lower = lower[2:]
+	}
+	switch {
+	case strings.HasPrefix(lower, "/device/afd"):
+		return OpenFileTypeSocket
+	case strings.HasPrefix(lower, "/device/namedpipe"), strings.HasPrefix(lower, "//./pipe/"):
+		return OpenFileTypePipe
+	case strings.HasPrefix(lower, "/device/"):
+		return OpenFileTypeDevice
+	}
+	return OpenFileTypeFile
+}
+
+// openFilesGroup 打开文件的分组：普通文件和设备按所在目录，套接字、管道按类型，其他按路径本身
+func openFilesGroup(p, kind string) string {
+	switch kind {
+	case OpenFileTypeFile, OpenFileTypeDevice:
+		return path.Dir(p)
+	case OpenFileTypeSocket, OpenFileTypePipe:
+		return kind
+	}
+	return p
+}
+
+// OpenFilesTracker 列出目标打开的文件，并按目标缓存上次查询结果用于计算差异
+type OpenFilesTracker struct {
+	mu   sync.Mutex
+	last map[int32]openFilesSnapshot
+}
+
+// openFilesSnapshot 一次查询的全部路径
+type openFilesSnapshot struct {
+	at      time.Time
+	key     provider.ProcessKey // 进程实例，PID 被复用或目标重启后不再对比
+	entries map[string]types.OpenFileEntry
+}
+
+// NewOpenFilesTracker 创建打开文件追踪器
+func NewOpenFilesTracker() *OpenFilesTracker {
+	return &OpenFilesTracker{last: make(map[int32]openFilesSnapshot)}
+}
+
+// Report 列出进程当前打开的文件，并与该进程上次查询的结果对比
+// 权限不足时返回 Complete 为 false 的报告（不更新对比基准），进程不存在等其他错误返回 error
+func (t *OpenFilesTracker) Report(pid int32, name string) (*types.OpenFilesReport, error) {
+	proc, err := process.NewProcess(pid)
+	if err != nil {
+		return nil, fmt.Errorf("process %d not found: %w", pid, err)
+	}
+	report := &types.OpenFilesReport{
+		PID:       pid,
+		Name:      name,
+		Timestamp: time.Now(),
+		ByType:    make(map[string]int),
+		Dirs:      []types.OpenFilesDir{},
+		Files:     []types.OpenFileEntry{},
+		Complete:  true,
+	}
+	files, err := proc.OpenFiles()
+	if err != nil {
+		if errors.Is(err, os.ErrPermission) {
+			report.Complete = false
+			report.Note = err.Error()
+			return report, nil
+		}
+		return nil, fmt.Errorf("list open files of pid %d: %w", pid, err)
+	}
+	key, _ := provider.GetProcessCreateTimeKeyed(pid)
+
+	// 按路径去重计数，按分组计数
+	entries := make(map[string]types.OpenFileEntry)
+	groups := make(map[string]*types.OpenFilesDir)
+	for _, f := range files {
+		if f.Path == "" {
+			continue
+		}
+		kind := classifyOpenFile(f.Path)
+		p := f.Path
+		if kind == OpenFileTypeFile || kind == OpenFileTypeDevice {
+			p = NormalizePath(p)
+		}
+		e := entries[p]
+		e.Path, e.Type = p, kind
+		e.Count++
+		entries[p] = e
+
+		report.Total++
+		report.ByType[kind]++
+		group := openFilesGroup(p, kind)
+		if g := groups[group]; g != nil {
+			g.Count++
+		} else {
+			groups[group] = &types.OpenFilesDir{Dir: group, Type: kind, Count: 1}
+		}
+	}
+
+	for _, e := range entries {
+		report.Files = append(report.Files, e)
+	}
+	sort.Slice(report.Files, func(i, j int) bool {
+		a, b := report.Files[i], report.Files[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Path < b.Path
+	})
+	if len(report.Files) > openFilesMaxEntries {
+		report.Files = report.Files[:openFilesMaxEntries]
+		report.Truncated = true
+	}
+	for _, g := range groups {
+		report.Dirs = append(report.Dirs, *g)
+	}
+	sort.Slice(report.Dirs, func(i, j int) bool {
+		a, b := report.Dirs[i], report.Dirs[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Dir < b.Dir
+	})
+	if len(report.Dirs) > openFilesMaxDirs {
+		report.Dirs = report.Dirs[:openFilesMaxDirs]
+		report.Truncated = true
+	}
+
+	t.mu.Lock()
+	prev, ok := t.last[pid]
+	t.last[pid] = openFilesSnapshot{at: report.Timestamp, key: key, entries: entries}
+	t.mu.Unlock()
+	if ok && prev.key == key {
+		at := prev.at
+		report.Previous = &at
+		report.Added, report.Removed = diffOpenFiles(prev.entries, entries)
+		if len(report.Added) > openFilesMaxDiff {
+			report.Added = report.Added[:openFilesMaxDiff]
+			report.DiffTruncated = true
+		}
+		if len(report.Removed) > openFilesMaxDiff {
+			report.Removed = report.Removed[:openFilesMaxDiff]
+			report.DiffTruncated = true
+		}
+	}
+	return report, nil
+}
+
+// Forget 清除目标的对比基准（目标移除时调用）
+func (t *OpenFilesTracker) Forget(pid int32) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.last, pid)
+}
+
+// diffOpenFiles 对比两次查询，返回新打开和已关闭的路径（按路径排序）
+func diffOpenFiles(prev, cur map[string]types.OpenFileEntry) (added, removed []types.OpenFileEntry) {
+	for p, e := range cur {
+		if _, ok := prev[p]; !ok {
+			added = append(added, e)
+		}
+	}
+	for p, e := range prev {
+		if _, ok := cur[p]; !ok {
+			removed = append(removed, e)
+		}
+	}
+	sort.Slice(added, func(i, j int) bool { return added[i].Path < added[j].Path })
+	sort.Slice(removed, func(i, j int) bool { return removed[i].Path < removed[j].Path })
+	return added, removed
+}
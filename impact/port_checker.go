@@ -1,6 +1,9 @@
 package impact
 
 import (
+	stdnet "net"
+	"syscall"
+
 	"github.com/shirou/gopsutil/v3/net"
 	"github.com/shirou/gopsutil/v3/process"
 )
@@ -17,27 +20,77 @@ type PortConflict struct {
 type ConnectionInfo struct {
 	PID         int32
 	ProcessName string
+	Family      string // "ipv4" 或 "ipv6"
+	LocalIP     string
 	LocalPort   int
+	RemoteIP    string
 	RemotePort  int
 	Status      string
 }
 
+// connFamily 获取连接的地址族
+func connFamily(conn net.ConnectionStat) string {
+	if conn.Family == syscall.AF_INET6 {
+		return "ipv6"
+	}
+	if conn.Family == syscall.AF_INET {
+		return "ipv4"
+	}
+	// 未知地址族时根据 IP 判断
+	if ip := stdnet.ParseIP(conn.Laddr.IP); ip != nil && ip.To4() == nil {
+		return "ipv6"
+	}
+	return "ipv4"
+}
+
+// isWildcardIP 是否为通配地址（监听所有地址）
+func isWildcardIP(ip string) bool {
+	switch ip {
+	case "", "*", "0.0.0.0", "::":
+		return true
+	}
+	return false
+}
+
+// isLoopbackIP 是否为回环地址
+func isLoopbackIP(ip string) bool {
+	parsed := stdnet.ParseIP(ip)
+	return parsed != nil && parsed.IsLoopback()
+}
+
+// addrOverlap 判断两个地址是否重叠：地址族相同，且任一方为通配地址或地址相同
+func addrOverlap(familyA, ipA, familyB, ipB string) bool {
+	if familyA != familyB {
+		return false
+	}
+	return isWildcardIP(ipA) || isWildcardIP(ipB) || ipA == ipB
+}
+
 // PortChecker 端口占用检测器
 type PortChecker struct {
 	// 进程名缓存，避免频繁查询
 	procNameCache map[int32]string
+
+	// 连接表来源，默认读取系统全部连接
+	connections func() ([]net.ConnectionStat, error)
 }
 
 // NewPortChecker 创建端口检测器
 func NewPortChecker() *PortChecker {
 	return &PortChecker{
 		procNameCache: make(map[int32]string),
+		connections:   systemConnections,
 	}
 }
 
+// systemConnections 读取系统全部网络连接
+func systemConnections() ([]net.ConnectionStat, error) {
+	return net.Connections("all")
+}
+
 // getAllConnections 获取所有网络连接（一次性调用，减少开销）
 func (c *PortChecker) getAllConnections() ([]ConnectionInfo, error) {
-	conns, err := net.Connections("all")
+	conns, err := c.connections()
 	if err != nil {
 		return nil, err
 	}
@@ -54,7 +107,10 @@ func (c *PortChecker) getAllConnections() ([]ConnectionInfo, error) {
 		result = append(result, ConnectionInfo{
 			PID:         conn.Pid,
 			ProcessName: procName,
+			Family:      connFamily(conn),
+			LocalIP:     conn.Laddr.IP,
 			LocalPort:   int(conn.Laddr.Port),
+			RemoteIP:    conn.Raddr.IP,
 			RemotePort:  int(conn.Raddr.Port),
 			Status:      conn.Status,
 		})
@@ -90,7 +146,7 @@ func (c *PortChecker) CheckPort(port int, excludePID int32) []PortConflict {
 	var conflicts []PortConflict
 
 	// 获取所有网络连接
-	conns, err := net.Connections("all")
+	conns, err := c.connections()
 	if err != nil {
 		return conflicts
 	}
@@ -135,7 +191,7 @@ func (c *PortChecker) CheckPorts(ports []int, excludePID int32) map[int][]PortCo
 	result := make(map[int][]PortConflict)
 
 	// 获取所有网络连接（只调用一次）
-	conns, err := net.Connections("all")
+	conns, err := c.connections()
 	if err != nil {
 		return result
 	}
@@ -186,17 +242,32 @@ func (c *PortChecker) CheckPorts(ports []int, excludePID int32) map[int][]PortCo
 // GetListeningPorts 获取指定进程监听的所有端口
 func (c *PortChecker) GetListeningPorts(pid int32) []int {
 	var ports []int
+	for _, l := range c.GetListeners(pid) {
+		ports = append(ports, l.LocalPort)
+	}
+	return ports
+}
 
-	conns, err := net.Connections("all")
+// GetListeners 获取指定进程的所有监听地址（含地址族和本地 IP）
+func (c *PortChecker) GetListeners(pid int32) []ConnectionInfo {
+	var listeners []ConnectionInfo
+
+	conns, err := c.connections()
 	if err != nil {
-		return ports
+		return listeners
 	}
 
 	for _, conn := range conns {
 		if conn.Pid == pid && conn.Status == "LISTEN" {
-			ports = append(ports, int(conn.Laddr.Port))
+			listeners = append(listeners, ConnectionInfo{
+				PID:       conn.Pid,
+				Family:    connFamily(conn),
+				LocalIP:   conn.Laddr.IP,
+				LocalPort: int(conn.Laddr.Port),
+				Status:    conn.Status,
+			})
 		}
 	}
 
-	return ports
+	return listeners
 }
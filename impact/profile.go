@@ -0,0 +1,242 @@
+package impact
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"monitor-agent/types"
+)
+
+// profileWindow 解析后的时段窗口
+type profileWindow struct {
+	start, end int     // 当天的分钟数，end 可为 1440（24:00）
+	days       [7]bool // 按开始当天的星期匹配，0=周日
+}
+
+// parseProfileWindow 解析 "HH:MM-HH:MM [星期]" 格式的时段窗口
+func parseProfileWindow(s string) (profileWindow, error) {
+	var w profileWindow
+	fields := strings.Fields(s)
+	if len(fields) == 0 || len(fields) > 2 {
+		return w, fmt.Errorf("window %q: expected \"HH:MM-HH:MM [days]\"", s)
+	}
+
+	parts := strings.Split(fields[0], "-")
+	if len(parts) != 2 {
+		return w, fmt.Errorf("window %q: expected time range HH:MM-HH:MM", s)
+	}
+	var err error
+	if w.start, err = parseClock(parts[0]); err != nil {
+		return w, fmt.Errorf("window %q: %w", s, err)
+	}
+	if w.end, err = parseClock(parts[1]); err != nil {
+		return w, fmt.Errorf("window %q: %w", s, err)
+	}
+	if w.start == w.end || w.start == 24*60 {
+		return w, fmt.Errorf("window %q: empty time range", s)
+	}
+
+	dayExpr := "*"
+	if len(fields) == 2 {
+		dayExpr = fields[1]
+	}
+	if w.days, err = parseCronDays(dayExpr); err != nil {
+		return w, fmt.Errorf("window %q: %w", s, err)
+	}
+	return w, nil
+}
+
+// parseClock 解析 HH:MM，允许 24:00
+func parseClock(s string) (int, error) {
+	t := strings.Split(s, ":")
+	if len(t) != 2 {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	h, err1 := strconv.Atoi(t[0])
+	m, err2 := strconv.Atoi(t[1])
+	if err1 != nil || err2 != nil || h < 0 || h > 24 || m < 0 || m > 59 || (h == 24 && m != 0) {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	return h*60 + m, nil
+}
+
+// parseCronDays 解析 cron 风格的星期字段（* / 1-5 / 0,6 / 7 表示周日）
+func parseCronDays(expr string) ([7]bool, error) {
+	var days [7]bool
+	for _, item := range strings.Split(expr, ",") {
+		if item == "*" {
+			for i := range days {
+				days[i] = true
+			}
+			continue
+		}
+		lo, hi := item, item
+		if i := strings.Index(item, "-"); i >= 0 {
+			lo, hi = item[:i], item[i+1:]
+		}
+		from, err1 := strconv.Atoi(lo)
+		to, err2 := strconv.Atoi(hi)
+		if err1 != nil || err2 != nil || from < 0 || to > 7 || from > to {
+			return days, fmt.Errorf("invalid day-of-week %q", item)
+		}
+		for d := from; d <= to; d++ {
+			days[d%7] = true
+		}
+	}
+	return days, nil
+}
+
+// contains 判断时刻是否在窗口内
+func (w profileWindow) contains(t time.Time) bool {
+	minute := t.Hour()*60 + t.Minute()
+	today := int(t.Weekday())
+	if w.start < w.end {
+		return w.days[today] && minute >= w.start && minute < w.end
+	}
+	// 跨零点：开始当天的后半段，或前一天开始的前半段
+	yesterday := (today + 6) % 7
+	return (w.days[today] && minute >= w.start) || (w.days[yesterday] && minute < w.end)
+}
+
+// activeProfile 返回 t 时刻生效的时段配置（多个匹配时取最后一个），无匹配返回 nil
+// 窗口格式错误的时段被忽略
+func activeProfile(profiles []types.ThresholdProfile, t time.Time) *types.ThresholdProfile {
+	var active *types.ThresholdProfile
+	for i := range profiles {
+		w, err := parseProfileWindow(profiles[i].Window)
+		if err != nil {
+			continue
+		}
+		if w.contains(t) {
+			active = &profiles[i]
+		}
+	}
+	return active
+}
+
+// ValidateProfiles 校验时段配置
+// 窗口格式错误或名称为空/重复返回错误；时段窗口重叠不算错误（以后者为准），以警告返回
+func ValidateProfiles(profiles []types.ThresholdProfile) (warnings []string, err error) {
+	windows := make([]profileWindow, len(profiles))
+	names := make(map[string]bool)
+	for i, p := range profiles {
+		if p.Name == "" {
+			return nil, fmt.Errorf("profile #%d: name is required", i+1)
+		}
+		if names[p.Name] {
+			return nil, fmt.Errorf("profile %q: duplicate name", p.Name)
+		}
+		names[p.Name] = true
+		if windows[i], err = parseProfileWindow(p.Window); err != nil {
+			return nil, fmt.Errorf("profile %q: %w", p.Name, err)
+		}
+	}
+
+	// 按分钟遍历一周检查窗口重叠
+	base := time.Date(2024, 1, 7, 0, 0, 0, 0, time.Local) // 周日
+	for i := 0; i < len(windows); i++ {
+		for j := i + 1; j < len(windows); j++ {
+			for m := 0; m < 7*24*60; m++ {
+				t := base.Add(time.Duration(m) * time.Minute)
+				if windows[i].contains(t) && windows[j].contains(t) {
+					warnings = append(warnings, fmt.Sprintf("profiles %q and %q overlap (e.g. %s %s), %q wins",
+						profiles[i].Name, profiles[j].Name, t.Weekday(), t.Format("15:04"), profiles[j].Name))
+					break
+				}
+			}
+		}
+	}
+	return warnings, nil
+}
+
+// applyProfile 返回叠加时段覆盖值后的配置
+func applyProfile(base types.ImpactConfig, p *types.ThresholdProfile) types.ImpactConfig {
+	if p == nil {
+		return base
+	}
+	return applyOverrides(base, p.Overrides)
+}
+
+// applyOverrides 返回叠加覆盖值后的配置，未设置的字段沿用 base
+func applyOverrides(base types.ImpactConfig, o types.ThresholdOverrides) types.ImpactConfig {
+	cfg := base
+	setFloat := func(dst *float64, v *float64) {
+		if v != nil {
+			*dst = *v
+		}
+	}
+	setInt := func(dst *int, v *int) {
+		if v != nil {
+			*dst = *v
+		}
+	}
+	setFloat(&cfg.CPUThreshold, o.CPUThreshold)
+	setFloat(&cfg.MemoryThreshold, o.MemoryThreshold)
+	setFloat(&cfg.DiskIOThreshold, o.DiskIOThreshold)
+	setFloat(&cfg.NetworkThreshold, o.NetworkThreshold)
+	setFloat(&cfg.CPUCoreThreshold, o.CPUCoreThreshold)
+	setFloat(&cfg.ProcCPUThreshold, o.ProcCPUThreshold)
+	setFloat(&cfg.ProcMemoryThreshold, o.ProcMemoryThreshold)
+	setFloat(&cfg.ProcMemGrowthThreshold, o.ProcMemGrowthThreshold)
+	setFloat(&cfg.ProcVMSThreshold, o.ProcVMSThreshold)
+	setInt(&cfg.ProcFDsThreshold, o.ProcFDsThreshold)
+	setFloat(&cfg.ProcFDGrowthThreshold, o.ProcFDGrowthThreshold)
+	setInt(&cfg.ProcThreadsThreshold, o.ProcThreadsThreshold)
+	setInt(&cfg.ProcOpenFilesThreshold, o.ProcOpenFilesThreshold)
+	setFloat(&cfg.ProcDiskReadThreshold, o.ProcDiskReadThreshold)
+	setFloat(&cfg.ProcDiskWriteThreshold, o.ProcDiskWriteThreshold)
+	setFloat(&cfg.ProcNetRecvThreshold, o.ProcNetRecvThreshold)
+	setFloat(&cfg.ProcNetSendThreshold, o.ProcNetSendThreshold)
+	setFloat(&cfg.DirGrowthThreshold, o.DirGrowthThreshold)
+	setFloat(&cfg.DirMinFreeMB, o.DirMinFreeMB)
+
+	// 系统级阈值必须大于 0，覆盖值无效时沿用基础配置
+	if cfg.CPUThreshold <= 0 {
+		cfg.CPUThreshold = base.CPUThreshold
+	}
+	if cfg.MemoryThreshold <= 0 {
+		cfg.MemoryThreshold = base.MemoryThreshold
+	}
+	if cfg.DiskIOThreshold <= 0 {
+		cfg.DiskIOThreshold = base.DiskIOThreshold
+	}
+	if cfg.NetworkThreshold <= 0 {
+		cfg.NetworkThreshold = base.NetworkThreshold
+	}
+	return cfg
+}
+
+// refreshActiveProfileLocked 按当前时间重新计算生效的阈值，调用方需持有写锁
+func (a *ImpactAnalyzer) refreshActiveProfileLocked(now time.Time) {
+	p := activeProfile(a.config.Profiles, now)
+	name := ""
+	if p != nil {
+		name = p.Name
+	}
+	a.effective = applyProfile(a.config, p)
+	a.activeProfileName = name
+}
+
+// ActiveProfile 返回当前生效的时段配置名称，空字符串表示使用基础配置
+func (a *ImpactAnalyzer) ActiveProfile() string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.activeProfileName
+}
+
+// GetEffectiveConfig 获取当前实际生效的配置（基础配置叠加时段覆盖）
+func (a *ImpactAnalyzer) GetEffectiveConfig() types.ImpactConfig {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.effective
+}
+
+// targetConfig 返回目标实际使用的配置（本周期生效配置的快照 effective 叠加目标自定义阈值）
+func targetConfig(effective types.ImpactConfig, target types.MonitorTarget) types.ImpactConfig {
+	if target.Thresholds == nil {
+		return effective
+	}
+	return applyOverrides(effective, *target.Thresholds)
+}
@@ -0,0 +1,179 @@
+package impact
+
+import (
+	"time"
+
+	"monitor-agent/buffer"
+	"monitor-agent/types"
+)
+
+// 阈值模拟：每个分析周期的输入（系统指标、进程列表、启停频率、磁盘使用率）精简后保存在回放缓冲中，
+// 模拟时用独立的影子分析器按候选阈值重新评估这些周期。影子分析器的 emit 只做持续时间判定和计数，
+// 不产生事件、日志、推送，也不影响实时分析的状态。
+// 文件和端口冲突依赖检测时的实时连接和文件状态，不参与回放。
+
+const (
+	replayCap  = 720 // 回放缓冲保留的分析周期数（5 秒周期约 1 小时）
+	replayTopN = 20  // 回放缓冲按各项指标保留的前 N 个进程
+
+	simulationExamples = 20 // 模拟结果中保留的事件示例数
+)
+
+// simulationExcluded 不参与回放的影响类型
+var simulationExcluded = []string{"port", "file"}
+
+// cycleInput 一个分析周期的输入：实时分析时现场采集，模拟时取自回放缓冲
+type cycleInput struct {
+	at      time.Time
+	sys     *types.SystemMetrics
+	procs   []types.ProcessInfo
+	targets []types.MonitorTarget
+	churn   *types.ProcessChurn // 未设置启停频率来源时为 nil
+	disk    map[string]float64  // 趋势预测的磁盘使用率，未启用趋势预测时为 nil
+	runWait map[int32]float64   // 目标最近一次采样的可运行等待 CPU 占比，平台不提供时不含该目标
+}
+
+// replayRankings 回放缓冲保留进程时参考的各项指标（影响分析中按进程比较的指标）
+var replayRankings = []func(p *types.ProcessInfo) float64{
+	func(p *types.ProcessInfo) float64 { return p.CPUPct },
+	func(p *types.ProcessInfo) float64 { return float64(p.RSSBytes) },
+	func(p *types.ProcessInfo) float64 { return p.DiskReadRate + p.DiskWriteRate },
+	func(p *types.ProcessInfo) float64 { return p.NetRecvRate + p.NetSendRate },
+	func(p *types.ProcessInfo) float64 { return p.RSSGrowthRate },
+	func(p *types.ProcessInfo) float64 { return float64(p.NumFDs) },
+	func(p *types.ProcessInfo) float64 { return p.FDGrowthRate },
+	func(p *types.ProcessInfo) float64 { return float64(p.NumThreads) },
+	func(p *types.ProcessInfo) float64 { return float64(p.VMS) },
+	func(p *types.ProcessInfo) float64 { return float64(p.OpenFiles) },
+}
+
+// compact 精简后放入回放缓冲：只保留目标、本程序及其子进程、僵尸进程和各项指标前 replayTopN 的进程，
+// 并去掉分析不使用的字段
+func (in cycleInput) compact() cycleInput {
+	keep := make(map[int32]bool)
+	for _, t := range in.targets {
+		keep[t.PID] = true
+	}
+	// 保留父子关系，回放时 ExcludeSelf 才能找到本程序的子进程
+	for pid := range selfPIDs(in.procs, keep) {
+		keep[pid] = true
+	}
+	for i := range in.procs {
+		if in.procs[i].Status == "zombie" {
+			keep[in.procs[i].PID] = true
+		}
+	}
+	for _, value := range replayRankings {
+		for _, i := range topNIndices(len(in.procs), replayTopN, func(i int) float64 { return value(&in.procs[i]) }) {
+			keep[in.procs[i].PID] = true
+		}
+	}
+
+	out := in
+	out.procs = make([]types.ProcessInfo, 0, len(keep))
+	for _, p := range in.procs {
+		if !keep[p.PID] {
+			continue
+		}
+		p.Username, p.Cmdline, p.Description = "", "", ""
+		p.ListenPorts, p.CPUAffinity, p.RestrictedFields = nil, nil, nil
+		out.procs = append(out.procs, p)
+	}
+	sys := *in.sys
+	out.sys = &sys
+	return out
+}
+
+// Simulate 按候选阈值回放最近 window 内的分析周期（window<=0 表示回放缓冲中的全部周期），
+// 统计会产生的影响事件，并用当前阈值回放同一区间作为对照。candidate 按 UpdateConfig 的规则合并到当前配置
+func (a *ImpactAnalyzer) Simulate(candidate types.ImpactConfig, window time.Duration) types.ImpactSimulation {
+	now := a.clock.Now()
+	var samples []cycleInput
+	for _, in := range a.replay.GetAll() {
+		if window <= 0 || now.Sub(in.at) <= window {
+			samples = append(samples, in)
+		}
+	}
+
+	current := a.GetConfig()
+	merged := current
+	mergeConfig(&merged, candidate)
+
+	result := types.ImpactSimulation{
+		Cycles:    len(samples),
+		Candidate: a.replayWith(merged, samples),
+		Current:   a.replayWith(current, samples),
+		Excluded:  simulationExcluded,
+	}
+	if len(samples) > 0 {
+		result.From = samples[0].at
+		result.To = samples[len(samples)-1].at
+	}
+	return result
+}
+
+// replayWith 用影子分析器按 cfg 依次评估各周期，统计新产生的影响事件
+func (a *ImpactAnalyzer) replayWith(cfg types.ImpactConfig, samples []cycleInput) types.ImpactSimulationCounts {
+	counts := types.ImpactSimulationCounts{
+		BySeverity: make(map[string]int),
+		ByType:     make(map[string]map[string]int),
+		Examples:   []types.ImpactEvent{},
+	}
+	// 影子分析器的 emit 直接累加计数，各项分析需顺序执行
+	cfg.AnalysisWorkers = 1
+	shadow := &ImpactAnalyzer{
+		provider:      a.provider,
+		config:        cfg,
+		clock:         a.clock,
+		activeImpacts: make(map[impactKey]*types.ImpactEvent),
+		pending:       make(map[impactKey]time.Time),
+		lastBreach:    make(map[impactKey]time.Time),
+		lastLogged:    make(map[impactKey]loggedImpact),
+		passTypes:     make(map[string]bool),
+		targetNotes:   make(map[int32]types.MonitorTarget),
+		trendSamples:  buffer.NewRingBuffer[trendSample](trendSampleCap),
+	}
+	shadow.emit = func(event types.ImpactEvent, detail string) {
+		if !shadow.simulateImpact(event, detail) {
+			return
+		}
+		counts.Total++
+		counts.BySeverity[event.Severity]++
+		if counts.ByType[event.ImpactType] == nil {
+			counts.ByType[event.ImpactType] = make(map[string]int)
+		}
+		counts.ByType[event.ImpactType][event.Severity]++
+		if len(counts.Examples) < simulationExamples {
+			counts.Examples = append(counts.Examples, event)
+		}
+	}
+
+	for _, in := range samples {
+		shadow.mu.Lock()
+		shadow.refreshActiveProfileLocked(in.at)
+		effective := shadow.effective
+		shadow.mu.Unlock()
+		shadow.beginCycle(in.at, effective)
+		shadow.evaluate(effective, in)
+		shadow.settleImpacts(effective, in.at)
+	}
+	return counts
+}
+
+// simulateImpact 模拟时记录一次突破：只做持续时间判定，返回是否产生新的影响事件
+func (a *ImpactAnalyzer) simulateImpact(event types.ImpactEvent, detail string) bool {
+	key := newImpactKey(event, detail)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.lastBreach[key] = event.Timestamp
+	if _, exists := a.activeImpacts[key]; exists {
+		return false
+	}
+	if !a.sustainedLocked(key, event.Timestamp) {
+		return false
+	}
+	event.FirstSeen = event.Timestamp
+	a.activeImpacts[key] = &event
+	return true
+}
@@ -0,0 +1,75 @@
+package impact
+
+import (
+	"time"
+
+	"monitor-agent/types"
+)
+
+// appendCachedTargets 进程列表中缺少的目标（列表读取与目标重启等竞争时会漏掉）用监控器最近一次采样补上，
+// 避免该目标本周期的影响分析被整个跳过。采样需存活且足够新：不早于两个采样间隔（未记录时按两个分析周期）。
+// 补上的条目只有采样中的 CPU、内存和优先级，其余指标为 0（不计入进程数）
+func (a *ImpactAnalyzer) appendCachedTargets(effective types.ImpactConfig, procs []types.ProcessInfo, targets []types.MonitorTarget, now time.Time) []types.ProcessInfo {
+	a.mu.RLock()
+	source := a.metricsSource
+	a.mu.RUnlock()
+	if source == nil {
+		return procs
+	}
+	listed := make(map[int32]bool, len(procs))
+	for i := range procs {
+		listed[procs[i].PID] = true
+	}
+	analysisInterval := time.Duration(effective.AnalysisInterval) * time.Second
+	for _, t := range targets {
+		if listed[t.PID] {
+			continue
+		}
+		latest := source(t.PID, 1)
+		if len(latest) == 0 || !latest[0].Alive {
+			continue
+		}
+		m := latest[0]
+		maxAge := 2 * analysisInterval
+		if interval := time.Duration(m.IntervalMS) * time.Millisecond; 2*interval > maxAge {
+			maxAge = 2 * interval
+		}
+		if now.Sub(m.Timestamp) > maxAge {
+			continue
+		}
+		name := m.Name
+		if name == "" {
+			name = t.Name
+		}
+		procs = append(procs, types.ProcessInfo{
+			PID:      t.PID,
+			Name:     name,
+			CPUPct:   m.CPUPct,
+			RSSBytes: m.RSSBytes,
+			Priority: m.Priority,
+			Nice:     m.Nice,
+		})
+		listed[t.PID] = true
+	}
+	return procs
+}
+
+// impactCandidates 一类资源竞争的影响来源候选。进程级阈值只是逐个进程的数值比较，对全部进程评估，
+// 排在 Top N 之外但超过进程级阈值的进程同样报告；系统级触发时只归因到 top（按该指标排序的前 N 个进程），
+// 避免系统繁忙时为每个进程都产生事件。先列出 top 中的候选，再按进程列表顺序补充 Top N 之外的候选
+func impactCandidates(procs, top []types.ProcessInfo, systemTriggered bool, overProc func(p *types.ProcessInfo) bool) []types.ProcessInfo {
+	out := make([]types.ProcessInfo, 0, len(top))
+	inTop := make(map[int32]bool, len(top))
+	for i := range top {
+		inTop[top[i].PID] = true
+		if systemTriggered || overProc(&top[i]) {
+			out = append(out, top[i])
+		}
+	}
+	for i := range procs {
+		if !inTop[procs[i].PID] && overProc(&procs[i]) {
+			out = append(out, procs[i])
+		}
+	}
+	return out
+}
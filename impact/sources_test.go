@@ -0,0 +1,121 @@
+package impact
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"monitor-agent/types"
+)
+
+func TestImpactCandidates(t *testing.T) {
+	procs := cpuProcs(90, 10, 70, 5, 65, 1)
+	top := topProcessesBy(procs, 2, procCPU) // PID 1、3
+	over60 := func(p *types.ProcessInfo) bool { return p.CPUPct >= 60 }
+	never := func(p *types.ProcessInfo) bool { return false }
+
+	tests := []struct {
+		name   string
+		system bool
+		over   func(p *types.ProcessInfo) bool
+		want   []int32
+	}{
+		// 进程级阈值对全部进程评估：Top N 之外的 PID 5 同样报告，排在 top 之后
+		{"process threshold beyond top", false, over60, []int32{1, 3, 5}},
+		// 系统级触发只归因到 Top N
+		{"system triggered", true, never, []int32{1, 3}},
+		{"system and process", true, over60, []int32{1, 3, 5}},
+		{"nothing triggered", false, never, []int32{}},
+	}
+	for _, tt := range tests {
+		got := pids(impactCandidates(procs, top, tt.system, tt.over))
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: candidates = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// sourcePIDs 当前活跃的某类影响的来源 PID（升序）
+func (h *testHarness) sourcePIDs(impactType string) []int32 {
+	var result []int32
+	for _, e := range h.activeOf(impactType) {
+		result = append(result, e.SourcePID)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+	return result
+}
+
+func TestCPUCandidatesBeyondTopN(t *testing.T) {
+	cfg := testConfig()
+	cfg.TopNProcesses = 2
+	cfg.ProcCPUThreshold = 50
+	h := newHarness(t, cfg, target(testTargetPID, "scada"))
+	h.prov.SetProcesses([]types.ProcessInfo{
+		proc(testTargetPID, "scada", 10),
+		proc(testSourcePID, "compiler", 90),
+		proc(testIntruderPID, "backup", 80),
+		proc(900012, "indexer", 60), // 排在 Top 2 之外，但超过进程级阈值
+		proc(900013, "idle", 5),
+	})
+
+	h.step()
+	if got, want := h.sourcePIDs("cpu"), []int32{testSourcePID, testIntruderPID, 900012}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("cpu impact sources = %v, want %v", got, want)
+	}
+}
+
+func TestCPUSystemTriggeredOnlyTopN(t *testing.T) {
+	cfg := testConfig()
+	cfg.TopNProcesses = 2
+	h := newHarness(t, cfg, target(testTargetPID, "scada"))
+	h.prov.SetSystemMetrics(types.SystemMetrics{
+		CPUPercent:    95,
+		CPUPerCore:    []float64{95, 95, 95, 95},
+		MemoryPercent: 40,
+	})
+	// 进程级阈值关闭：三个进程占整机 CPU 都超过 10%，系统级触发只归因到前两个
+	h.prov.SetProcesses([]types.ProcessInfo{
+		proc(testTargetPID, "scada", 10),
+		proc(testSourcePID, "compiler", 120),
+		proc(testIntruderPID, "backup", 100),
+		proc(900012, "indexer", 80),
+	})
+
+	h.step()
+	if got, want := h.sourcePIDs("cpu"), []int32{testSourcePID, testIntruderPID}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("cpu impact sources = %v, want %v", got, want)
+	}
+}
+
+func TestAppendCachedTargets(t *testing.T) {
+	now := testStart
+	cfg := testConfig() // 分析间隔 5 秒
+	samples := map[int32]types.ProcessMetrics{
+		1: {Timestamp: now.Add(-3 * time.Second), Name: "fresh", Alive: true, CPUPct: 12, RSSBytes: 1 << 20},
+		2: {Timestamp: now.Add(-time.Minute), Name: "stale", Alive: true},
+		3: {Timestamp: now.Add(-time.Second), Name: "dead"},
+		// 采样间隔 60 秒：两个采样间隔内的采样仍有效
+		4: {Timestamp: now.Add(-90 * time.Second), Alive: true, IntervalMS: 60000},
+	}
+	a := NewImpactAnalyzer(cfg, nil, nil, nil)
+	a.SetMetricsSource(func(pid int32, n int) []types.ProcessMetrics {
+		if m, ok := samples[pid]; ok {
+			return []types.ProcessMetrics{m}
+		}
+		return nil
+	})
+
+	listed := []types.ProcessInfo{{PID: 1, Name: "listed", CPUPct: 50}}
+	targets := []types.MonitorTarget{target(1, "a"), target(2, "b"), target(3, "c"), target(4, "slow"), target(5, "none")}
+
+	got := a.appendCachedTargets(cfg, listed, targets, now)
+	if len(got) != 2 || got[0].Name != "listed" || got[1].PID != 4 || got[1].Name != "slow" {
+		t.Fatalf("appended = %+v, want listed PID 1 and cached PID 4 (target name)", got)
+	}
+
+	got = a.appendCachedTargets(cfg, nil, targets[:1], now)
+	if len(got) != 1 || got[0].Name != "fresh" || got[0].CPUPct != 12 || got[0].RSSBytes != 1<<20 {
+		t.Fatalf("cached target = %+v, want fresh sample", got)
+	}
+}
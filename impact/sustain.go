@@ -0,0 +1,158 @@
+package impact
+
+import (
+	"fmt"
+	"time"
+
+	"monitor-agent/types"
+)
+
+// 持续时间要求：阈值突破需持续 min_duration_seconds 才成为正式影响事件，
+// 正式影响需连续 clear_duration_seconds 未再突破才解除，避免瞬时尖峰反复产生/解除事件。
+//
+// 每个分析周期开始时调用 beginCycle，各检测在本周期实际执行时调用 beginPass 声明
+// 参与评估的影响类型，周期结束时 settleImpacts 对这些类型中本周期未再突破的影响
+// 进行解除、丢弃未持续满足的候选。未执行的检测（如低频的文件/端口检测）保持原状。
+
+// ImpactTypes 所有影响类型，min_duration_overrides 的键必须是其中之一
+var ImpactTypes = []string{
+	"cpu", "cpu_core", "memory", "mem_growth", "disk_io", "network", "port", "file",
+	"fds", "fd_growth", "threads", "open_files", "vms", "priority", "churn", "zombies",
+	"trend", "steal", "virt_contention", "dir_growth", "dir_full",
+}
+
+// IsImpactType 是否为已知影响类型
+func IsImpactType(impactType string) bool {
+	for _, t := range ImpactTypes {
+		if t == impactType {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateDurations 校验持续时间配置：不能为负，按类型覆盖的键必须是已知影响类型
+func ValidateDurations(cfg types.ImpactConfig) error {
+	if cfg.MinDurationSeconds < 0 || cfg.ClearDurationSeconds < 0 {
+		return fmt.Errorf("min_duration_seconds and clear_duration_seconds must not be negative")
+	}
+	if cfg.LogCooldownSeconds < 0 {
+		return fmt.Errorf("log_cooldown_seconds must not be negative")
+	}
+	for t, v := range cfg.MinDurationOverrides {
+		if !IsImpactType(t) {
+			return fmt.Errorf("min_duration_overrides: unknown impact type %q", t)
+		}
+		if v < 0 {
+			return fmt.Errorf("min_duration_overrides.%s must not be negative", t)
+		}
+	}
+	return nil
+}
+
+// beginCycle 开始新的分析周期，effective 为本周期的配置快照
+func (a *ImpactAnalyzer) beginCycle(now time.Time, effective types.ImpactConfig) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.cycleStart = now
+	a.cycleConfig = effective
+	a.passTypes = make(map[string]bool)
+}
+
+// beginPass 声明本周期评估该影响类型
+func (a *ImpactAnalyzer) beginPass(impactType string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.passTypes[impactType] = true
+}
+
+// minDuration 影响类型要求的持续时间（按类型覆盖优先），effective 为本周期的配置快照
+func minDuration(effective types.ImpactConfig, impactType string) time.Duration {
+	secs := effective.MinDurationSeconds
+	if v, ok := effective.MinDurationOverrides[impactType]; ok {
+		secs = v
+	}
+	return time.Duration(secs) * time.Second
+}
+
+// sustainedLocked 记录一次突破，返回候选是否已持续足够长时间（调用方需持有 mu）
+func (a *ImpactAnalyzer) sustainedLocked(key impactKey, at time.Time) bool {
+	minDur := minDuration(a.cycleConfig, key.ImpactType)
+	if minDur <= 0 {
+		delete(a.pending, key)
+		return true
+	}
+	firstSeen, ok := a.pending[key]
+	if !ok {
+		a.pending[key] = at
+		return false
+	}
+	if at.Sub(firstSeen) < minDur {
+		return false
+	}
+	delete(a.pending, key)
+	return true
+}
+
+// settleImpacts 解除本周期已评估但未再突破、且超过清除期的影响，丢弃中断的候选，effective 为本周期的配置快照
+func (a *ImpactAnalyzer) settleImpacts(effective types.ImpactConfig, now time.Time) {
+	a.mu.Lock()
+	clearDur := time.Duration(effective.ClearDurationSeconds) * time.Second
+	var removed []*types.ImpactEvent
+	var resolved []types.ImpactEvent
+	for key, evt := range a.activeImpacts {
+		last := a.lastBreach[key]
+		if !a.passTypes[key.ImpactType] || !last.Before(a.cycleStart) || now.Sub(last) < clearDur {
+			continue
+		}
+		if !evt.Suppressed && a.loggedLocked(key, evt) {
+			removed = append(removed, evt)
+		}
+		// 推送与新影响推送对应：未抑制的影响都推送解除（模拟用的影子分析器没有推送）
+		if !evt.Suppressed && a.resolvedStream != nil {
+			resolved = append(resolved, *evt)
+		}
+		a.rememberResolvedLocked(evt)
+		delete(a.activeImpacts, key)
+		delete(a.lastBreach, key)
+	}
+	for key := range a.pending {
+		if a.passTypes[key.ImpactType] && a.lastBreach[key].Before(a.cycleStart) {
+			delete(a.pending, key)
+			delete(a.lastBreach, key)
+		}
+	}
+	a.pruneLoggedLocked(effective, now)
+	a.mu.Unlock()
+
+	for _, evt := range removed {
+		a.recordImpactRemoved(evt)
+	}
+	for _, evt := range resolved {
+		a.resolvedStream.Publish(evt)
+	}
+}
+
+// forgetLocked 清除满足条件的影响、候选和突破记录（调用方需持有 mu）
+func (a *ImpactAnalyzer) forgetLocked(match func(key impactKey) bool) {
+	for key := range a.activeImpacts {
+		if match(key) {
+			delete(a.activeImpacts, key)
+		}
+	}
+	for key := range a.pending {
+		if match(key) {
+			delete(a.pending, key)
+		}
+	}
+	for key := range a.lastBreach {
+		if match(key) {
+			delete(a.lastBreach, key)
+		}
+	}
+	for key := range a.lastLogged {
+		if match(key) {
+			delete(a.lastLogged, key)
+		}
+	}
+}
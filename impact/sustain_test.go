@@ -0,0 +1,154 @@
+package impact
+
+import (
+	"testing"
+
+	"monitor-agent/types"
+)
+
+// sustainConfig 突破需持续 15 秒（3 个分析间隔）才成为影响，解除需连续 20 秒未再突破
+func sustainConfig() types.ImpactConfig {
+	cfg := testConfig()
+	cfg.ProcCPUThreshold = 50
+	cfg.MinDurationSeconds = 15
+	cfg.ClearDurationSeconds = 20
+	return cfg
+}
+
+func sustainHarness(t *testing.T, cfg types.ImpactConfig) *testHarness {
+	h := newHarness(t, cfg, target(testTargetPID, "scada"))
+	h.prov.SetProcesses([]types.ProcessInfo{
+		proc(testTargetPID, "scada", 10),
+		proc(testSourcePID, "compiler", 5),
+	})
+	return h
+}
+
+func (h *testHarness) setSourceCPU(pct float64) {
+	h.prov.UpdateProcess(testSourcePID, func(p *types.ProcessInfo) { p.CPUPct = pct })
+}
+
+func TestFlappingImpactNeverReported(t *testing.T) {
+	h := sustainHarness(t, sustainConfig())
+
+	// 每隔一个周期突破一次：候选持续时间被中断，始终不满足 min_duration_seconds
+	for i := 0; i < 20; i++ {
+		if i%2 == 0 {
+			h.setSourceCPU(90)
+		} else {
+			h.setSourceCPU(5)
+		}
+		h.step()
+		h.expectActive("cpu", 0)
+	}
+	// 连续两个周期突破（10 秒）仍不足 15 秒
+	for i := 0; i < 3; i++ {
+		h.setSourceCPU(90)
+		h.step()
+		h.step()
+		h.setSourceCPU(5)
+		h.step()
+		h.expectActive("cpu", 0)
+	}
+	if n := h.eventCount("impact_cpu"); n != 0 {
+		t.Fatalf("impact_cpu callbacks = %d, want 0", n)
+	}
+}
+
+func TestSustainedImpactReportedOnceAndClearedAfterCooldown(t *testing.T) {
+	h := sustainHarness(t, sustainConfig())
+
+	// 首次突破记为候选，持续满 15 秒后的周期才成为正式影响
+	h.setSourceCPU(90)
+	for i := 0; i < 3; i++ {
+		h.step()
+		h.expectActive("cpu", 0)
+	}
+	h.step()
+	h.expectActive("cpu", 1)
+
+	// 持续突破期间只报告一次
+	for i := 0; i < 10; i++ {
+		h.step()
+		h.expectActive("cpu", 1)
+	}
+	if n := h.eventCount("impact_cpu"); n != 1 {
+		t.Fatalf("impact_cpu callbacks = %d, want 1", n)
+	}
+
+	// 负载下降后，清除期内保持活跃；清除期内的一次突破重新计时，不重复报告
+	h.setSourceCPU(5)
+	h.step()
+	h.step()
+	h.expectActive("cpu", 1)
+	h.setSourceCPU(90)
+	h.step()
+	h.setSourceCPU(5)
+	for i := 0; i < 3; i++ {
+		h.step()
+		h.expectActive("cpu", 1)
+	}
+	if n := h.eventCount("impact_resolved"); n != 0 {
+		t.Fatalf("impact_resolved callbacks within cooldown = %d, want 0", n)
+	}
+
+	// 连续 20 秒未突破后解除
+	h.step()
+	h.expectActive("cpu", 0)
+	for i := 0; i < 3; i++ {
+		h.step()
+	}
+	if n := h.eventCount("impact_cpu"); n != 1 {
+		t.Fatalf("impact_cpu callbacks = %d, want 1", n)
+	}
+	if n := h.eventCount("impact_resolved"); n != 1 {
+		t.Fatalf("impact_resolved callbacks = %d, want 1", n)
+	}
+}
+
+func TestMinDurationOverrideReportsImmediately(t *testing.T) {
+	cfg := sustainConfig()
+	cfg.MinDurationOverrides = map[string]int{"cpu": 0}
+	h := sustainHarness(t, cfg)
+
+	h.setSourceCPU(90)
+	h.step()
+	h.expectActive("cpu", 1)
+}
+
+// 周期中途修改配置（进程列表读取期间调用 UpdateConfig）：本周期的持续时间和清除期仍按周期开始时的快照判定，
+// 与本周期各项检测的阈值一致，新配置从下一个周期生效
+func TestSustainUsesCycleSnapshot(t *testing.T) {
+	slow := sustainConfig()
+	fast := sustainConfig()
+	fast.MinDurationSeconds = 0
+	fast.ClearDurationSeconds = 0
+	h := sustainHarness(t, slow)
+
+	var next *types.ImpactConfig
+	list := h.a.getProcesses
+	h.a.getProcesses = func() ([]types.ProcessInfo, error) {
+		if next != nil {
+			h.a.UpdateConfig(*next)
+			next = nil
+		}
+		return list()
+	}
+
+	// 本周期按 15 秒的持续时间要求只记为候选，下一个周期按新配置立即成为影响
+	h.setSourceCPU(90)
+	next = &fast
+	h.step()
+	h.expectActive("cpu", 0)
+	h.step()
+	h.expectActive("cpu", 1)
+
+	// 本周期按清除期 0 立即解除，周期中途改回的 20 秒清除期不影响本周期
+	h.setSourceCPU(5)
+	next = &slow
+	h.step()
+	h.expectActive("cpu", 0)
+	if n := h.eventCount("impact_resolved"); n != 1 {
+		t.Fatalf("impact_resolved callbacks = %d, want 1", n)
+	}
+}
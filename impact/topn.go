@@ -0,0 +1,74 @@
+package impact
+
+import (
+	"container/heap"
+
+	"monitor-agent/types"
+)
+
+// Top N 选择：影响分析每个周期要从全部进程中取 CPU、内存、磁盘、网络各前 N 个进程，
+// 回放缓冲还要按十几项指标各取前 N 个。N 通常远小于进程数，用大小为 N 的最小堆
+// 一次遍历选出，代价 O(len·log N)，不必完整排序。
+
+// rankedIndex 参与 Top N 选择的元素：原下标和比较值
+type rankedIndex struct {
+	idx   int
+	value float64
+}
+
+// minRankHeap 以「最差」元素为堆顶的堆：值更小的更差，值相同时原下标更大的更差（保证结果稳定）
+type minRankHeap []rankedIndex
+
+func (h minRankHeap) Len() int { return len(h) }
+func (h minRankHeap) Less(i, j int) bool {
+	if h[i].value != h[j].value {
+		return h[i].value < h[j].value
+	}
+	return h[i].idx > h[j].idx
+}
+func (h minRankHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *minRankHeap) Push(x interface{}) { *h = append(*h, x.(rankedIndex)) }
+func (h *minRankHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// topNIndices 返回 value 最大的 n 个元素的下标，按值降序排列，值相同时保持原顺序；n<=0 时返回空
+func topNIndices(count, n int, value func(i int) float64) []int {
+	if n <= 0 || count == 0 {
+		return nil
+	}
+	if n > count {
+		n = count
+	}
+	h := make(minRankHeap, 0, n)
+	for i := 0; i < count; i++ {
+		item := rankedIndex{idx: i, value: value(i)}
+		if len(h) < n {
+			heap.Push(&h, item)
+			continue
+		}
+		// 只有比堆顶（当前第 n 名）更好的元素才能进入
+		if item.value > h[0].value {
+			h[0] = item
+			heap.Fix(&h, 0)
+		}
+	}
+	result := make([]int, len(h))
+	for i := len(h) - 1; i >= 0; i-- {
+		result[i] = heap.Pop(&h).(rankedIndex).idx
+	}
+	return result
+}
+
+// topProcessesBy 返回 value 最大的 n 个进程的副本，按值降序排列，值相同时保持原顺序
+func topProcessesBy(procs []types.ProcessInfo, n int, value func(p *types.ProcessInfo) float64) []types.ProcessInfo {
+	idx := topNIndices(len(procs), n, func(i int) float64 { return value(&procs[i]) })
+	result := make([]types.ProcessInfo, len(idx))
+	for i, j := range idx {
+		result[i] = procs[j]
+	}
+	return result
+}
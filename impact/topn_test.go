@@ -0,0 +1,117 @@
+package impact
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+
+	"monitor-agent/types"
+)
+
+func procCPU(p *types.ProcessInfo) float64 { return p.CPUPct }
+
+// sortedTopN 参照实现：稳定排序后取前 n 个
+func sortedTopN(procs []types.ProcessInfo, n int, value func(p *types.ProcessInfo) float64) []types.ProcessInfo {
+	sorted := append([]types.ProcessInfo(nil), procs...)
+	sort.SliceStable(sorted, func(i, j int) bool { return value(&sorted[i]) > value(&sorted[j]) })
+	if n < 0 {
+		n = 0
+	}
+	if n > len(sorted) {
+		n = len(sorted)
+	}
+	return sorted[:n]
+}
+
+func pids(procs []types.ProcessInfo) []int32 {
+	result := make([]int32, len(procs))
+	for i, p := range procs {
+		result[i] = p.PID
+	}
+	return result
+}
+
+// cpuProcs 按 CPU 使用率依次生成进程，PID 从 1 开始
+func cpuProcs(cpu ...float64) []types.ProcessInfo {
+	procs := make([]types.ProcessInfo, len(cpu))
+	for i, c := range cpu {
+		procs[i] = types.ProcessInfo{PID: int32(i + 1), CPUPct: c}
+	}
+	return procs
+}
+
+func TestTopProcessesByMatchesStableSort(t *testing.T) {
+	tests := []struct {
+		name string
+		cpu  []float64
+		n    int
+		want []int32
+	}{
+		{"empty", nil, 3, []int32{}},
+		{"n zero", []float64{1, 2}, 0, []int32{}},
+		{"n negative", []float64{1, 2}, -1, []int32{}},
+		{"n over count", []float64{1, 3, 2}, 10, []int32{2, 3, 1}},
+		{"all equal keeps order", []float64{5, 5, 5, 5}, 3, []int32{1, 2, 3}},
+		{"ties at cutoff", []float64{1, 9, 4, 4, 4, 0}, 3, []int32{2, 3, 4}},
+		{"ties after larger", []float64{4, 4, 9, 4, 9}, 4, []int32{3, 5, 1, 2}},
+		{"late better replaces tie", []float64{2, 2, 2, 3}, 2, []int32{4, 1}},
+	}
+	for _, tt := range tests {
+		procs := cpuProcs(tt.cpu...)
+		got := pids(topProcessesBy(procs, tt.n, procCPU))
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: topProcessesBy = %v, want %v", tt.name, got, tt.want)
+		}
+		if ref := pids(sortedTopN(procs, tt.n, procCPU)); !reflect.DeepEqual(got, ref) {
+			t.Errorf("%s: topProcessesBy = %v, stable sort = %v", tt.name, got, ref)
+		}
+	}
+
+	// 随机数据：取值范围小，大量并列
+	rng := rand.New(rand.NewSource(1))
+	for round := 0; round < 200; round++ {
+		cpu := make([]float64, rng.Intn(60))
+		for i := range cpu {
+			cpu[i] = float64(rng.Intn(8))
+		}
+		procs := cpuProcs(cpu...)
+		n := rng.Intn(len(cpu) + 3)
+		got := pids(topProcessesBy(procs, n, procCPU))
+		if ref := pids(sortedTopN(procs, n, procCPU)); !reflect.DeepEqual(got, ref) {
+			t.Fatalf("cpu %v n=%d: topProcessesBy = %v, stable sort = %v", cpu, n, got, ref)
+		}
+	}
+}
+
+// benchProcs 模拟约 2000 个进程的主机，多数进程 CPU 为 0
+func benchProcs() []types.ProcessInfo {
+	rng := rand.New(rand.NewSource(1))
+	procs := make([]types.ProcessInfo, 2000)
+	for i := range procs {
+		procs[i] = types.ProcessInfo{PID: int32(i + 1), Name: "proc", RSSBytes: uint64(rng.Intn(1 << 30))}
+		if rng.Intn(4) == 0 {
+			procs[i].CPUPct = rng.Float64() * 100
+		}
+	}
+	return procs
+}
+
+func BenchmarkTopProcessesBy(b *testing.B) {
+	procs := benchProcs()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		topProcessesBy(procs, 10, procCPU)
+	}
+}
+
+// BenchmarkTopProcessesBySort 对照：完整稳定排序后取前 N 个
+func BenchmarkTopProcessesBySort(b *testing.B) {
+	procs := benchProcs()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sortedTopN(procs, 10, procCPU)
+	}
+}
@@ -0,0 +1,218 @@
+package impact
+
+import (
+	"math"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/disk"
+
+	"monitor-agent/i18n"
+	"monitor-agent/types"
+)
+
+// 趋势预测：系统内存、Swap 使用率和磁盘填充率通常缓慢上升，到达阈值时往往已来不及处置。
+// 每个分析周期记录一次采样，按 trend_window_seconds 窗口内的采样做最小二乘线性拟合，
+// 预计在 trend_horizon_minutes 内到达 trend_limit_percent 时产生 trend 影响；
+// 增长停止（斜率不再为正或拟合度不足）后按常规解除流程解除。
+
+const (
+	trendSampleCap      = 720 // 最多保留的采样数（5 秒周期约 1 小时）
+	trendMinSamples     = 5   // 拟合所需的最少采样数
+	trendMinR2          = 0.5 // 拟合优度下限，低于该值视为波动而非趋势
+	trendDefaultHorizon = 30
+	trendDefaultLimit   = 95
+)
+
+// trendSample 一次趋势采样（使用率均为百分比）
+type trendSample struct {
+	at     time.Time
+	memory float64            // 内存读取失败时为 -1
+	swap   float64            // 无 Swap 或读取失败时为 -1
+	disk   map[string]float64 // 挂载点 -> 使用率，获取失败的挂载点不记录
+}
+
+// trendFit 线性拟合结果
+type trendFit struct {
+	current   float64 // 拟合线在最后一次采样时的值
+	perMinute float64 // 每分钟增长（百分点）
+}
+
+// trendDiskPaths 检测填满趋势的磁盘挂载点，未配置时使用系统盘
+func trendDiskPaths(paths []string) []string {
+	if len(paths) > 0 {
+		return paths
+	}
+	if runtime.GOOS == "windows" {
+		drive := os.Getenv("SystemDrive")
+		if drive == "" {
+			drive = "C:"
+		}
+		return []string{drive + `\`}
+	}
+	return []string{"/"}
+}
+
+// sampleDisks 读取各挂载点的使用率，获取失败的挂载点不记录
+func sampleDisks(paths []string) map[string]float64 {
+	usage := make(map[string]float64)
+	for _, p := range paths {
+		if u, err := disk.Usage(p); err == nil && u.Total > 0 {
+			usage[p] = u.UsedPercent
+		}
+	}
+	return usage
+}
+
+// sampleTrend 记录本周期的趋势采样，diskUsage 为采集阶段读取的磁盘使用率
+func (a *ImpactAnalyzer) sampleTrend(sys *types.SystemMetrics, diskUsage map[string]float64, now time.Time) {
+	s := trendSample{at: now, memory: -1, swap: -1, disk: diskUsage}
+	if !sys.IsDegraded(types.SubsystemMemory) {
+		s.memory = sys.MemoryPercent
+	}
+	if sys.SwapTotal > 0 && !sys.IsDegraded(types.SubsystemSwap) {
+		s.swap = sys.SwapPercent
+	}
+	a.trendSamples.Push(s)
+}
+
+// fitTrend 对窗口内的采样做最小二乘线性拟合，采样不足、跨度不足或拟合度过低时返回 false
+func fitTrend(samples []trendSample, window time.Duration, value func(trendSample) (float64, bool)) (trendFit, bool) {
+	if len(samples) == 0 {
+		return trendFit{}, false
+	}
+	last := samples[len(samples)-1].at
+	var xs, ys []float64
+	for _, s := range samples {
+		if last.Sub(s.at) > window {
+			continue
+		}
+		v, ok := value(s)
+		if !ok {
+			continue
+		}
+		xs = append(xs, s.at.Sub(last).Seconds())
+		ys = append(ys, v)
+	}
+	n := float64(len(xs))
+	if len(xs) < trendMinSamples || -xs[0] < window.Seconds()/2 {
+		return trendFit{}, false
+	}
+
+	var sumX, sumY float64
+	for i := range xs {
+		sumX += xs[i]
+		sumY += ys[i]
+	}
+	meanX, meanY := sumX/n, sumY/n
+	var sxx, sxy, syy float64
+	for i := range xs {
+		dx, dy := xs[i]-meanX, ys[i]-meanY
+		sxx += dx * dx
+		sxy += dx * dy
+		syy += dy * dy
+	}
+	if sxx == 0 || syy == 0 {
+		return trendFit{}, false
+	}
+	slope := sxy / sxx
+	if r2 := sxy * sxy / (sxx * syy); r2 < trendMinR2 {
+		return trendFit{}, false
+	}
+	// 最后一次采样 x=0，截距即为拟合的当前值
+	return trendFit{current: meanY - slope*meanX, perMinute: slope * 60}, true
+}
+
+// analyzeTrend 按系统内存、Swap 和磁盘使用率的增长趋势预测耗尽时间，影响所有监控目标
+func (a *ImpactAnalyzer) analyzeTrend(
+	effective types.ImpactConfig,
+	sys *types.SystemMetrics,
+	diskUsage map[string]float64,
+	targets []types.MonitorTarget,
+	procMap map[int32]*types.ProcessInfo,
+) {
+	a.beginPass("trend")
+
+	cfg := effective
+	if cfg.TrendWindowSeconds <= 0 {
+		return
+	}
+	horizon := cfg.TrendHorizonMinutes
+	if horizon <= 0 {
+		horizon = trendDefaultHorizon
+	}
+	limit := cfg.TrendLimitPercent
+	if limit <= 0 || limit > 100 {
+		limit = trendDefaultLimit
+	}
+	paths := trendDiskPaths(cfg.TrendDiskPaths)
+
+	a.sampleTrend(sys, diskUsage, a.cycleStart)
+	samples := a.trendSamples.GetAll()
+	window := time.Duration(cfg.TrendWindowSeconds) * time.Second
+
+	type metric struct {
+		key   string // 影响明细键
+		name  string
+		value func(trendSample) (float64, bool)
+	}
+	metrics := []metric{
+		{"memory", i18n.T("trend.metric.memory"), func(s trendSample) (float64, bool) { return s.memory, s.memory >= 0 }},
+		{"swap", i18n.T("trend.metric.swap"), func(s trendSample) (float64, bool) { return s.swap, s.swap >= 0 }},
+	}
+	for _, p := range paths {
+		p := p
+		metrics = append(metrics, metric{"disk:" + p, i18n.T("trend.metric.disk", p), func(s trendSample) (float64, bool) {
+			v, ok := s.disk[p]
+			return v, ok
+		}})
+	}
+
+	for _, m := range metrics {
+		fit, ok := fitTrend(samples, window, m.value)
+		if !ok || fit.perMinute <= 0 {
+			continue
+		}
+		minutes := (limit - fit.current) / fit.perMinute
+		if minutes >= float64(horizon) {
+			continue
+		}
+		severity := "medium"
+		switch {
+		case minutes < float64(horizon)/4:
+			severity = "critical"
+		case minutes < float64(horizon)/2:
+			severity = "high"
+		}
+		eta := int(math.Ceil(minutes))
+		if eta < 1 {
+			eta = 1
+		}
+		desc := i18n.T("impact.trend.desc", m.name, eta, fit.current, fit.perMinute, limit)
+
+		for _, target := range targets {
+			targetProc := procMap[target.PID]
+			if targetProc == nil {
+				continue
+			}
+			event := types.ImpactEvent{
+				Timestamp:   a.cycleStart,
+				TargetPID:   target.PID,
+				TargetName:  a.getTargetDisplayName(target),
+				ImpactType:  "trend",
+				Severity:    severity,
+				SourceName:  m.name,
+				Description: desc,
+				Metrics: types.ImpactMetrics{
+					SystemCPU:    sys.CPUPercent,
+					SystemMemory: sys.MemoryPercent,
+					TargetCPU:    targetProc.CPUPct,
+					TargetMemory: targetProc.RSSBytes,
+				},
+				Suggestion: i18n.T("impact.trend.suggestion", m.name),
+			}
+			a.emit(event, m.key)
+		}
+	}
+}
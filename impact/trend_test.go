@@ -0,0 +1,142 @@
+package impact
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"monitor-agent/types"
+)
+
+// samplesOf 按 5 秒间隔生成内存使用率采样
+func samplesOf(values []float64) []trendSample {
+	samples := make([]trendSample, len(values))
+	for i, v := range values {
+		samples[i] = trendSample{at: testStart.Add(time.Duration(i) * 5 * time.Second), memory: v, swap: -1}
+	}
+	return samples
+}
+
+func memoryValue(s trendSample) (float64, bool) { return s.memory, s.memory >= 0 }
+
+func TestFitTrendDetectsRamp(t *testing.T) {
+	// 每 5 秒增长 0.5 个百分点，即每分钟 6 个百分点
+	values := make([]float64, 13)
+	for i := range values {
+		values[i] = 50 + 0.5*float64(i)
+	}
+	fit, ok := fitTrend(samplesOf(values), time.Minute, memoryValue)
+	if !ok {
+		t.Fatal("ramp not detected")
+	}
+	if math.Abs(fit.perMinute-6) > 1e-9 || math.Abs(fit.current-56) > 1e-9 {
+		t.Fatalf("fit = %+v, want current 56, 6/min", fit)
+	}
+
+	// 叠加小幅波动后仍识别为上升趋势
+	rng := rand.New(rand.NewSource(1))
+	for i := range values {
+		values[i] += rng.Float64() - 0.5
+	}
+	fit, ok = fitTrend(samplesOf(values), time.Minute, memoryValue)
+	if !ok || fit.perMinute < 5 || fit.perMinute > 7 {
+		t.Fatalf("noisy ramp fit = %+v, %v; want about 6/min", fit, ok)
+	}
+}
+
+func TestFitTrendRejectsNoise(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	noise := make([]float64, 120)
+	for i := range noise {
+		noise[i] = 60 + 20*(rng.Float64()-0.5)
+	}
+	// 窗口填满后逐个周期检查：随机波动不构成趋势
+	for end := 13; end <= len(noise); end++ {
+		if fit, ok := fitTrend(samplesOf(noise[:end]), time.Minute, memoryValue); ok {
+			t.Fatalf("noise up to sample %d fitted as trend %+v", end, fit)
+		}
+	}
+
+	alternating := make([]float64, 13)
+	for i := range alternating {
+		alternating[i] = 60 + 10*float64(1-2*(i%2))
+	}
+	if fit, ok := fitTrend(samplesOf(alternating), time.Minute, memoryValue); ok {
+		t.Fatalf("alternating values fitted as trend %+v", fit)
+	}
+}
+
+func TestFitTrendRequiresEnoughSamples(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+	}{
+		{"empty", nil},
+		{"too few samples", []float64{50, 51, 52, 53}},
+		{"span under half window", []float64{50, 51, 52, 53, 54, 55}}, // 25 秒 < 30 秒
+		{"flat", []float64{50, 50, 50, 50, 50, 50, 50, 50}},
+		{"missing values", []float64{-1, -1, -1, -1, -1, -1, -1, -1}},
+	}
+	for _, tt := range tests {
+		if fit, ok := fitTrend(samplesOf(tt.values), time.Minute, memoryValue); ok {
+			t.Errorf("%s: fitted %+v, want no trend", tt.name, fit)
+		}
+	}
+}
+
+// trendHarness 趋势检测窗口 60 秒，磁盘挂载点不存在（只检测内存）
+func trendHarness(t *testing.T) *testHarness {
+	cfg := testConfig()
+	cfg.TrendWindowSeconds = 60
+	cfg.TrendHorizonMinutes = 30
+	cfg.TrendLimitPercent = 95
+	cfg.TrendDiskPaths = []string{"/nonexistent/trend-test"}
+	h := newHarness(t, cfg, target(testTargetPID, "scada"))
+	h.prov.SetProcesses([]types.ProcessInfo{proc(testTargetPID, "scada", 10)})
+	return h
+}
+
+func (h *testHarness) setMemory(pct float64) {
+	h.prov.SetSystemMetrics(types.SystemMetrics{
+		CPUPercent:    20,
+		CPUPerCore:    []float64{20, 20, 20, 20},
+		MemoryPercent: pct,
+	})
+}
+
+func TestAnalyzeTrendRaisesOnRamp(t *testing.T) {
+	h := trendHarness(t)
+
+	// 每周期增长 1 个百分点（12/分钟），约 3 分钟内到达 95%；采样跨度达到半个窗口前不判断
+	for i := 0; i < 6; i++ {
+		h.setMemory(50 + float64(i))
+		h.step()
+		h.expectActive("trend", 0)
+	}
+	h.setMemory(56)
+	h.step()
+	events := h.expectActive("trend", 1)
+	if events[0].Severity != "critical" {
+		t.Fatalf("trend severity = %q, want critical", events[0].Severity)
+	}
+
+	// 增长停止后按常规流程解除
+	for i := 0; i < 20; i++ {
+		h.step()
+	}
+	h.expectActive("trend", 0)
+}
+
+func TestAnalyzeTrendIgnoresNoise(t *testing.T) {
+	h := trendHarness(t)
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 120; i++ {
+		h.setMemory(60 + 20*(rng.Float64()-0.5))
+		h.step()
+		h.expectActive("trend", 0)
+	}
+	if n := h.eventCount("impact_trend"); n != 0 {
+		t.Fatalf("impact_trend callbacks = %d, want 0", n)
+	}
+}
@@ -0,0 +1,349 @@
+// Package influx 把系统和各保障对象的指标按 InfluxDB 行协议推送到历史数据库（UDP 或 HTTP 写入接口）。
+//
+// 由监控的采样循环驱动：每次采样后 Observe 记录各目标的最新指标，达到推送间隔时唤醒发送协程，
+// 发送协程生成一批数据点（同时读取系统指标）并推送。推送失败不影响监控，失败的批次保留在有限的积压队列中，
+// 下次推送时按顺序重试，超出上限时丢弃最旧的批次。
+package influx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"monitor-agent/logger"
+	"monitor-agent/types"
+)
+
+const (
+	DefaultMeasurement = "plant_monitor"
+	DefaultInterval    = 10 // 默认推送间隔（秒）
+	DefaultBacklog     = 30 // 默认最多积压的批次数
+
+	sendTimeout = 5 * time.Second
+	udpPayload  = 1400 // UDP 单个数据包的最大字节数，避免 IP 分片
+)
+
+// Options 推送选项
+type Options struct {
+	URL         string            // udp://host:port 或 HTTP 写入地址，如 http://host:8086/write?db=plant
+	Token       string            // HTTP 推送时的 Authorization: Token 认证，可为空
+	Interval    int               // 推送间隔（秒），0 表示默认值
+	Measurement string            // measurement 名称，空表示 plant_monitor
+	Tags        map[string]string // 附加到每个数据点的静态标签（如 site、unit）
+	Backlog     int               // 推送失败时最多保留的批次数，0 表示默认值
+}
+
+// ParseURL 校验推送地址：udp://host:port、http:// 或 https://
+func ParseURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return nil, fmt.Errorf("invalid influx url %q: %w", raw, err)
+	}
+	switch u.Scheme {
+	case "udp":
+		if u.Hostname() == "" || u.Port() == "" {
+			return nil, fmt.Errorf("invalid influx url %q: udp requires host:port", raw)
+		}
+	case "http", "https":
+		if u.Host == "" {
+			return nil, fmt.Errorf("invalid influx url %q: host is required", raw)
+		}
+	default:
+		return nil, fmt.Errorf("invalid influx url %q: scheme must be udp, http or https", raw)
+	}
+	return u, nil
+}
+
+// Exporter 指标推送器
+type Exporter struct {
+	opts     Options
+	endpoint *url.URL
+	host     string
+	system   func() (*types.SystemMetrics, error)
+	client   *http.Client
+
+	mu       sync.Mutex
+	lastPush time.Time
+	pending  []types.TargetStatus // 待生成数据点的目标指标
+	pendAt   time.Time
+	backlog  [][]byte // 待发送的批次，最旧的在前
+	dropped  int      // 因积压超出上限丢弃的批次数
+	failing  bool     // 上次推送是否失败，用于只在开始失败和恢复时记录日志
+	kick     chan struct{}
+}
+
+// New 创建推送器，system 用于读取系统指标（为 nil 时只推送目标指标）
+func New(opts Options, system func() (*types.SystemMetrics, error)) (*Exporter, error) {
+	endpoint, err := ParseURL(opts.URL)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Interval < 0 || opts.Backlog < 0 {
+		return nil, fmt.Errorf("influx interval and backlog must not be negative")
+	}
+	if opts.Interval == 0 {
+		opts.Interval = DefaultInterval
+	}
+	if opts.Backlog == 0 {
+		opts.Backlog = DefaultBacklog
+	}
+	if opts.Measurement == "" {
+		opts.Measurement = DefaultMeasurement
+	}
+	host, _ := os.Hostname()
+	return &Exporter{
+		opts:     opts,
+		endpoint: endpoint,
+		host:     host,
+		system:   system,
+		client:   &http.Client{Timeout: sendTimeout},
+		kick:     make(chan struct{}, 1),
+	}, nil
+}
+
+// Observe 记录一次采样的目标指标，达到推送间隔时唤醒发送协程（不阻塞采样循环）
+func (e *Exporter) Observe(at time.Time, targets []types.TargetStatus) {
+	interval := time.Duration(e.opts.Interval) * time.Second
+	e.mu.Lock()
+	if !e.lastPush.IsZero() && at.Sub(e.lastPush) < interval {
+		e.mu.Unlock()
+		return
+	}
+	e.lastPush = at
+	e.pending, e.pendAt = targets, at
+	e.mu.Unlock()
+
+	select {
+	case e.kick <- struct{}{}:
+	default:
+	}
+}
+
+// Run 发送协程，ctx 结束时退出
+func (e *Exporter) Run(ctx context.Context) {
+	logger.Infof("INFLUX", "Exporting metrics to %s every %ds (measurement %s)",
+		e.endpoint.Redacted(), e.opts.Interval, e.opts.Measurement)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-e.kick:
+			e.flush(ctx)
+		}
+	}
+}
+
+// flush 生成本次的数据批次并按顺序发送积压的批次，遇到失败时停止，留待下次重试
+func (e *Exporter) flush(ctx context.Context) {
+	e.mu.Lock()
+	targets, at := e.pending, e.pendAt
+	e.pending = nil
+	e.mu.Unlock()
+
+	if batch := e.batch(at, targets); len(batch) > 0 {
+		e.mu.Lock()
+		e.backlog = append(e.backlog, batch)
+		if over := len(e.backlog) - e.opts.Backlog; over > 0 {
+			e.backlog = e.backlog[over:]
+			e.dropped += over
+		}
+		e.mu.Unlock()
+	}
+
+	for {
+		e.mu.Lock()
+		if len(e.backlog) == 0 {
+			e.mu.Unlock()
+			break
+		}
+		batch := e.backlog[0]
+		e.mu.Unlock()
+
+		err := e.send(ctx, batch)
+		var rejected *rejectedError
+		if errors.As(err, &rejected) {
+			// 数据被拒绝（如数据库不存在、认证失败），重试也不会成功，丢弃该批次避免阻塞后续数据
+			logger.Warnf("INFLUX", "Push to %s rejected, batch dropped: %v", e.endpoint.Redacted(), err)
+			err = nil
+		}
+		if err != nil {
+			e.mu.Lock()
+			first := !e.failing
+			e.failing = true
+			e.mu.Unlock()
+			if first {
+				logger.Warnf("INFLUX", "Push to %s failed, keeping up to %d batches for retry: %v",
+					e.endpoint.Redacted(), e.opts.Backlog, err)
+			}
+			return
+		}
+
+		e.mu.Lock()
+		e.backlog = e.backlog[1:]
+		recovered := e.failing && len(e.backlog) == 0
+		dropped := e.dropped
+		if recovered {
+			e.failing, e.dropped = false, 0
+		}
+		e.mu.Unlock()
+		if recovered {
+			logger.Infof("INFLUX", "Push to %s recovered (%d batches dropped while failing)", e.endpoint.Redacted(), dropped)
+		}
+	}
+}
+
+// batch 把系统指标和目标指标编码为一批行协议数据
+func (e *Exporter) batch(at time.Time, targets []types.TargetStatus) []byte {
+	if at.IsZero() {
+		return nil
+	}
+	var buf []byte
+	if e.system != nil {
+		if sys, err := e.system(); err == nil {
+			buf = appendLine(buf, e.opts.Measurement, e.systemPoint(at, sys))
+		}
+	}
+	for _, t := range targets {
+		if t.Latest == nil {
+			continue
+		}
+		buf = appendLine(buf, e.opts.Measurement, e.targetPoint(at, t))
+	}
+	return buf
+}
+
+// tags 基础标签：主机名、静态标签（可覆盖主机名）和数据类型
+func (e *Exporter) tags(kind string) map[string]string {
+	tags := map[string]string{"host": e.host}
+	for k, v := range e.opts.Tags {
+		tags[k] = v
+	}
+	tags["kind"] = kind
+	return tags
+}
+
+// systemPoint 系统指标数据点，读取失败（降级）的子系统不写入对应字段，避免在历史曲线中留下假的 0
+func (e *Exporter) systemPoint(at time.Time, sys *types.SystemMetrics) point {
+	p := point{tags: e.tags("system"), at: at}
+	if !sys.IsDegraded(types.SubsystemCPU) {
+		p.float("cpu", sys.CPUPercent)
+		p.float("cpu_iowait", sys.CPUIowait)
+		p.float("cpu_steal", sys.CPUSteal)
+	}
+	if !sys.IsDegraded(types.SubsystemMemory) {
+		p.int("mem_used", int64(sys.MemoryUsed))
+		p.float("mem_pct", sys.MemoryPercent)
+	}
+	if !sys.IsDegraded(types.SubsystemSwap) {
+		p.float("swap_pct", sys.SwapPercent)
+	}
+	if !sys.IsDegraded(types.SubsystemLoad) {
+		p.float("load1", sys.LoadAvg1)
+		p.float("load5", sys.LoadAvg5)
+		p.float("load15", sys.LoadAvg15)
+	}
+	if !sys.IsDegraded(types.SubsystemNetwork) {
+		p.float("net_recv_rate", sys.NetRecvRate)
+		p.float("net_send_rate", sys.NetSendRate)
+	}
+	if !sys.IsDegraded(types.SubsystemDisk) {
+		p.float("disk_read_rate", sys.DiskReadRate)
+		p.float("disk_write_rate", sys.DiskWriteRate)
+	}
+	p.int("processes", int64(sys.ProcessCount))
+	p.int("threads", int64(sys.ThreadCount))
+	return p
+}
+
+func (e *Exporter) targetPoint(at time.Time, t types.TargetStatus) point {
+	tags := e.tags("target")
+	tags["pid"] = strconv.Itoa(int(t.PID))
+	tags["name"] = t.Name
+	tags["alias"] = t.Alias
+	p := point{tags: tags, at: at}
+	p.bool("alive", t.Latest.Alive)
+	if t.Latest.Alive {
+		p.float("cpu", t.Latest.CPUPct)
+		p.int("rss", int64(t.Latest.RSSBytes))
+		p.int("priority", int64(t.Latest.Priority))
+		if t.Latest.RunWaitPct != nil {
+			p.float("run_wait_pct", *t.Latest.RunWaitPct)
+		}
+		if t.Latest.BlockedPct != nil {
+			p.float("blocked_pct", *t.Latest.BlockedPct)
+		}
+	}
+	return p
+}
+
+// rejectedError 服务端拒绝了数据（4xx），重试不会成功
+type rejectedError struct{ err error }
+
+func (e *rejectedError) Error() string { return e.err.Error() }
+
+// send 发送一个批次
+func (e *Exporter) send(ctx context.Context, batch []byte) error {
+	if e.endpoint.Scheme == "udp" {
+		return e.sendUDP(batch)
+	}
+	ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint.String(), bytes.NewReader(batch))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if e.opts.Token != "" {
+		req.Header.Set("Authorization", "Token "+e.opts.Token)
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+		err := fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+		if resp.StatusCode/100 == 4 && resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusRequestTimeout {
+			return &rejectedError{err}
+		}
+		return err
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// sendUDP 按行切分为不超过 udpPayload 的数据包发送（单行超长时单独成包）
+func (e *Exporter) sendUDP(batch []byte) error {
+	conn, err := net.DialTimeout("udp", e.endpoint.Host, sendTimeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	conn.SetWriteDeadline(time.Now().Add(sendTimeout))
+	for len(batch) > 0 {
+		n := len(batch)
+		if n > udpPayload {
+			if i := bytes.LastIndexByte(batch[:udpPayload], '\n'); i >= 0 {
+				n = i + 1
+			} else if i := bytes.IndexByte(batch, '\n'); i >= 0 {
+				n = i + 1
+			}
+		}
+		if _, err := conn.Write(batch[:n]); err != nil {
+			return err
+		}
+		batch = batch[n:]
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package influx
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 行协议（line protocol）编码：measurement,tag=v field=v timestamp
+// 标签值为空的标签省略；字段只使用数值和布尔值，不需要引号
+
+var (
+	measurementEscaper = strings.NewReplacer(",", `\,`, " ", `\ `)
+	tagEscaper         = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `, "\n", `\ `)
+)
+
+// field 一个字段，按添加顺序输出
+type field struct {
+	key   string
+	value string
+}
+
+// point 一个数据点
+type point struct {
+	tags   map[string]string
+	fields []field
+	at     time.Time
+}
+
+func (p *point) float(key string, v float64) {
+	p.fields = append(p.fields, field{key, strconv.FormatFloat(v, 'f', -1, 64)})
+}
+
+func (p *point) int(key string, v int64) {
+	p.fields = append(p.fields, field{key, strconv.FormatInt(v, 10) + "i"})
+}
+
+func (p *point) bool(key string, v bool) {
+	p.fields = append(p.fields, field{key, strconv.FormatBool(v)})
+}
+
+// appendLine 把数据点编码为一行（含换行符）追加到 buf，没有字段时不输出
+func appendLine(buf []byte, measurement string, p point) []byte {
+	if len(p.fields) == 0 {
+		return buf
+	}
+	buf = append(buf, measurementEscaper.Replace(measurement)...)
+
+	keys := make([]string, 0, len(p.tags))
+	for k, v := range p.tags {
+		if k != "" && v != "" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys) // InfluxDB 建议按键排序，写入更快
+	for _, k := range keys {
+		buf = append(buf, ',')
+		buf = append(buf, tagEscaper.Replace(k)...)
+		buf = append(buf, '=')
+		buf = append(buf, tagEscaper.Replace(p.tags[k])...)
+	}
+
+	for i, f := range p.fields {
+		if i == 0 {
+			buf = append(buf, ' ')
+		} else {
+			buf = append(buf, ',')
+		}
+		buf = append(buf, tagEscaper.Replace(f.key)...)
+		buf = append(buf, '=')
+		buf = append(buf, f.value...)
+	}
+	buf = append(buf, ' ')
+	buf = strconv.AppendInt(buf, p.at.UnixNano(), 10)
+	return append(buf, '\n')
+}
@@ -0,0 +1,98 @@
+package logger
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// 审计日志：记录所有修改状态的操作（谁、从哪里、在何时做了什么），写入日志目录下单独的
+// audit.jsonl。与运行日志分开，不受 file_output 开关影响，保留清理、按日索引和日志搜索都不处理该文件
+
+const (
+	// AuditFileName 审计日志文件名
+	AuditFileName = "audit.jsonl"
+	// AuditCategory 审计日志类别
+	AuditCategory = "AUDIT"
+)
+
+// AuditEntry 一条审计记录
+type AuditEntry struct {
+	Timestamp time.Time   `json:"timestamp"`
+	Category  string      `json:"category"`            // 固定为 AUDIT
+	Source    string      `json:"source"`              // web 或 cli
+	User      string      `json:"user,omitempty"`      // Web 登录用户或 CLI 所在的系统用户
+	RemoteIP  string      `json:"remote_ip,omitempty"` // Web 请求的来源 IP
+	Action    string      `json:"action"`              // 操作，如 target.add、config.impact
+	Detail    interface{} `json:"detail,omitempty"`    // 操作对象和内容
+	Error     string      `json:"error,omitempty"`     // 操作失败时的原因
+}
+
+// Audit 写入一条审计记录，写入失败时记一条错误日志
+func (l *Logger) Audit(entry AuditEntry) {
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+	entry.Category = AuditCategory
+	data, err := json.Marshal(entry)
+	if err != nil {
+		l.Errorf("SERVICE", "Marshal audit entry failed: %v", err)
+		return
+	}
+
+	l.auditMu.Lock()
+	if l.auditFile == nil {
+		l.auditFile, err = os.OpenFile(filepath.Join(l.logDir, AuditFileName), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	}
+	if err == nil {
+		_, err = l.auditFile.Write(append(data, '\n'))
+	}
+	l.auditMu.Unlock()
+
+	if err != nil {
+		l.Errorf("SERVICE", "Write audit log failed: %v", err)
+	}
+}
+
+// closeAudit 关闭审计日志文件
+func (l *Logger) closeAudit() {
+	l.auditMu.Lock()
+	defer l.auditMu.Unlock()
+	if l.auditFile != nil {
+		l.auditFile.Close()
+		l.auditFile = nil
+	}
+}
+
+// ReadAudit 读取日志目录中最近 n 条审计记录（n<=0 表示全部），按时间从早到晚排列。没有审计日志时返回空
+func ReadAudit(logDir string, n int) ([]AuditEntry, error) {
+	entries := []AuditEntry{}
+	f, err := os.Open(filepath.Join(logDir, AuditFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return entries, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), maxIndexLine)
+	for scanner.Scan() {
+		var e AuditEntry
+		if json.Unmarshal(scanner.Bytes(), &e) != nil {
+			continue
+		}
+		entries = append(entries, e)
+		if n > 0 && len(entries) > 2*n {
+			// 只保留最近的记录，避免审计日志较大时占用过多内存
+			entries = append(entries[:0], entries[len(entries)-n:]...)
+		}
+	}
+	if n > 0 && len(entries) > n {
+		entries = entries[len(entries)-n:]
+	}
+	return entries, scanner.Err()
+}
@@ -0,0 +1,311 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"monitor-agent/timefmt"
+)
+
+// 按日索引：写入 EVENT、IMPACT 日志时，在日志目录的 index 子目录中按日期（配置的时区）
+// 追加一条索引记录，记录日志所在文件和字节偏移。按日浏览历史时只读取索引指向的行，
+// 不必重新扫描整个日志目录
+
+const (
+	// IndexDirName 索引子目录名
+	IndexDirName = "index"
+	// DayLayout 索引日期格式
+	DayLayout = "2006-01-02"
+
+	dayIndexExt     = ".idx"
+	indexedListName = "files.txt" // 已建立索引的日志文件名，每行一个
+	maxIndexLine    = 1 << 20     // 单行日志最大长度
+)
+
+// IndexRecord 一条按日索引记录
+type IndexRecord struct {
+	Time     int64  `json:"t"` // 日志时间（Unix 毫秒）
+	File     string `json:"file"`
+	Offset   int64  `json:"offset"` // 日志行在文件中的字节偏移（压缩文件为解压后的偏移）
+	Category string `json:"category"`
+	Severity string `json:"severity,omitempty"` // 影响日志的严重级别
+}
+
+// indexMu 串行化补建索引，避免并发补建重复写入记录
+var indexMu sync.Mutex
+
+// IsIndexedCategory 该类别的日志是否建立按日索引
+func IsIndexedCategory(category string) bool {
+	return category == "EVENT" || category == "IMPACT"
+}
+
+// dayIndexWriter 当天索引文件的追加写入器，跨天时切换文件
+type dayIndexWriter struct {
+	dir string // 索引目录
+	day string
+	f   *os.File
+}
+
+// append 追加一条索引记录，写入失败时忽略（索引缺失只影响历史浏览）
+func (w *dayIndexWriter) append(ts time.Time, rec IndexRecord) {
+	day := timefmt.In(ts).Format(DayLayout)
+	if w.f == nil || w.day != day {
+		w.close()
+		os.MkdirAll(w.dir, 0755)
+		f, err := os.OpenFile(filepath.Join(w.dir, day+dayIndexExt), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return
+		}
+		w.f, w.day = f, day
+	}
+	rec.Time = ts.UnixMilli()
+	if data, err := json.Marshal(rec); err == nil {
+		w.f.Write(append(data, '\n'))
+	}
+}
+
+func (w *dayIndexWriter) close() {
+	if w.f != nil {
+		w.f.Close()
+		w.f = nil
+	}
+}
+
+// severityOf 取日志 data.severity
+func severityOf(data interface{}) string {
+	if m, ok := data.(map[string]interface{}); ok {
+		if s, ok := m["severity"].(string); ok {
+			return s
+		}
+	}
+	return ""
+}
+
+// markIndexed 记录日志文件已建立索引（由日志器边写边索引或已补建）
+func markIndexed(logDir, name string) error {
+	dir := filepath.Join(logDir, IndexDirName)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(dir, indexedListName), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.WriteString(name + "\n")
+	return err
+}
+
+// indexedFiles 已建立索引的日志文件名
+func indexedFiles(logDir string) map[string]bool {
+	names := make(map[string]bool)
+	data, err := os.ReadFile(filepath.Join(logDir, IndexDirName, indexedListName))
+	if err != nil {
+		return names
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			names[line] = true
+		}
+	}
+	return names
+}
+
+// BuildIndex 为尚未建立索引的日志文件（升级前写入的日志、其他实例写入的日志）补建按日索引，
+// 返回补建的文件数。逐个文件读取，每个文件的记录写完后才标记为已索引
+func BuildIndex(logDir string) (int, error) {
+	indexMu.Lock()
+	defer indexMu.Unlock()
+
+	entries, err := os.ReadDir(logDir)
+	if err != nil {
+		return 0, err
+	}
+	done := indexedFiles(logDir)
+	built := 0
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !IsLogFile(name) || done[name] {
+			continue
+		}
+		byDay, err := scanForIndex(filepath.Join(logDir, name))
+		if err != nil {
+			continue // 文件已被清理或无法读取，下次重试
+		}
+		if err := appendDayRecords(logDir, byDay); err != nil {
+			return built, err
+		}
+		if err := markIndexed(logDir, name); err != nil {
+			return built, err
+		}
+		built++
+	}
+	return built, nil
+}
+
+// scanForIndex 扫描日志文件，按日期返回 EVENT、IMPACT 日志的索引记录
+func scanForIndex(path string) (map[string][]IndexRecord, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	name := filepath.Base(path)
+	byDay := make(map[string][]IndexRecord)
+	var offset int64
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxIndexLine)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		lineOffset := offset
+		offset += int64(len(line)) + 1
+		// 指标日志占绝大部分，先按字节过滤再解析
+		if !bytes.Contains(line, []byte(`"category":"EVENT"`)) && !bytes.Contains(line, []byte(`"category":"IMPACT"`)) {
+			continue
+		}
+		var e struct {
+			Timestamp time.Time   `json:"timestamp"`
+			Category  string      `json:"category"`
+			Data      interface{} `json:"data"`
+		}
+		if err := json.Unmarshal(line, &e); err != nil || !IsIndexedCategory(e.Category) {
+			continue
+		}
+		day := timefmt.In(e.Timestamp).Format(DayLayout)
+		byDay[day] = append(byDay[day], IndexRecord{
+			Time:     e.Timestamp.UnixMilli(),
+			File:     name,
+			Offset:   lineOffset,
+			Category: e.Category,
+			Severity: severityOf(e.Data),
+		})
+	}
+	return byDay, scanner.Err()
+}
+
+// appendDayRecords 将记录追加到各日期的索引文件
+func appendDayRecords(logDir string, byDay map[string][]IndexRecord) error {
+	dir := filepath.Join(logDir, IndexDirName)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for day, records := range byDay {
+		var buf bytes.Buffer
+		for _, rec := range records {
+			data, _ := json.Marshal(rec)
+			buf.Write(data)
+			buf.WriteByte('\n')
+		}
+		f, err := os.OpenFile(filepath.Join(dir, day+dayIndexExt), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return err
+		}
+		_, err = f.Write(buf.Bytes())
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IndexedDays 有索引的日期，从新到旧排列
+func IndexedDays(logDir string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(logDir, IndexDirName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var days []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, dayIndexExt) {
+			continue
+		}
+		day := strings.TrimSuffix(name, dayIndexExt)
+		if _, err := time.Parse(DayLayout, day); err == nil {
+			days = append(days, day)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(days)))
+	return days, nil
+}
+
+// ReadDayIndex 读取某天的索引记录，按时间排序。没有索引时返回空
+func ReadDayIndex(logDir, day string) ([]IndexRecord, error) {
+	f, err := os.Open(filepath.Join(logDir, IndexDirName, day+dayIndexExt))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []IndexRecord
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var rec IndexRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err == nil && rec.File != "" {
+			records = append(records, rec)
+		}
+	}
+	// 补建的记录追加在日志器实时写入的记录之后，按时间重新排序
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Time < records[j].Time
+	})
+	return records, scanner.Err()
+}
+
+// pruneIndex 删除引用的日志文件均已不存在的按日索引
+func pruneIndex(logDir string) {
+	days, err := IndexedDays(logDir)
+	if err != nil {
+		return
+	}
+	exists := make(map[string]bool)
+	for _, day := range days {
+		records, err := ReadDayIndex(logDir, day)
+		if err != nil {
+			continue
+		}
+		live := false
+		for _, rec := range records {
+			ok, seen := exists[rec.File]
+			if !seen {
+				_, err := os.Stat(filepath.Join(logDir, rec.File))
+				ok = err == nil
+				exists[rec.File] = ok
+			}
+			if ok {
+				live = true
+				break
+			}
+		}
+		if !live {
+			os.Remove(filepath.Join(logDir, IndexDirName, day+dayIndexExt))
+		}
+	}
+}
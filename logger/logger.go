@@ -8,13 +8,15 @@ import (
 	"path/filepath"
 	"sync"
 	"time"
+
+	"monitor-agent/timefmt"
 )
 
 // LogEntry 统一日志条目
 type LogEntry struct {
 	Timestamp time.Time   `json:"timestamp"`
 	Level     string      `json:"level"`    // INFO, WARN, ERROR, DEBUG
-	Category  string      `json:"category"` // SERVICE, EVENT, IMPACT, METRIC
+	Category  string      `json:"category"` // SERVICE, EVENT, IMPACT, METRIC（AUDIT 写入单独的审计日志，见 audit.go）
 	Message   string      `json:"message"`
 	Data      interface{} `json:"data,omitempty"` // 可选的附加数据
 }
@@ -26,10 +28,17 @@ type Logger struct {
 	logDir        string
 	consoleOutput bool
 	fileOutput    bool
+	offset        int64          // 当前日志文件已写入的字节数，用于按日索引
+	index         dayIndexWriter // 按日索引
+	metricFormat  string         // 指标日志编码（见 metricfmt.go），空表示 JSON
+
+	auditMu   sync.Mutex
+	auditFile *os.File // 审计日志，首次写入时打开（见 audit.go）
 }
 
 var (
-	defaultLogger *Logger
+	defaultLogger *Logger // 本包创建的 JSONL 日志器，使用调用方的日志实现时为 nil
+	sink          Sink    // 全局日志函数的输出目标
 	once          sync.Once
 )
 
@@ -42,7 +51,7 @@ func Init(logDir string, fileOutput, consoleOutput bool) error {
 			initErr = err
 			return
 		}
-		defaultLogger = logger
+		SetSink(logger)
 	})
 	return initErr
 }
@@ -59,6 +68,7 @@ func NewLogger(logDir string, fileOutput, consoleOutput bool) (*Logger, error) {
 		logDir:        logDir,
 		fileOutput:    fileOutput,
 		consoleOutput: consoleOutput,
+		index:         dayIndexWriter{dir: filepath.Join(logDir, IndexDirName)},
 	}
 
 	if fileOutput {
@@ -78,9 +88,30 @@ func (l *Logger) openLogFile() error {
 		return fmt.Errorf("open log file: %w", err)
 	}
 	l.logFile = f
+	l.offset = 0
+	if info, err := f.Stat(); err == nil {
+		l.offset = info.Size()
+	}
+	// 本日志器写入的文件边写边索引，不需要补建
+	markIndexed(l.logDir, filepath.Base(logPath))
 	return nil
 }
 
+// writeLine 写入一行日志，EVENT、IMPACT 日志同时记入按日索引。调用方需持有 l.mu
+func (l *Logger) writeLine(ts time.Time, category string, data interface{}, line []byte) {
+	offset := l.offset
+	n, err := l.logFile.Write(append(line, '\n'))
+	l.offset += int64(n)
+	if err == nil && IsIndexedCategory(category) {
+		l.index.append(ts, IndexRecord{
+			File:     filepath.Base(l.logFile.Name()),
+			Offset:   offset,
+			Category: category,
+			Severity: severityOf(data),
+		})
+	}
+}
+
 // Close 关闭日志器
 func (l *Logger) Close() {
 	l.mu.Lock()
@@ -89,6 +120,8 @@ func (l *Logger) Close() {
 		l.logFile.Close()
 		l.logFile = nil
 	}
+	l.index.close()
+	l.closeAudit()
 }
 
 // Reopen 重新打开日志文件（用于日志轮转或重启后）
@@ -118,14 +151,14 @@ func (l *Logger) Log(level, category, message string, data interface{}) {
 	if l.fileOutput && l.logFile != nil {
 		jsonData, err := json.Marshal(entry)
 		if err == nil {
-			l.logFile.Write(append(jsonData, '\n'))
+			l.writeLine(entry.Timestamp, category, data, jsonData)
 		}
 	}
 
 	// 输出到控制台
 	if l.consoleOutput {
 		fmt.Printf("%s [%s] [%s] %s\n",
-			entry.Timestamp.Format("2006/01/02 15:04:05"),
+			timefmt.Format(entry.Timestamp, "2006/01/02 15:04:05"),
 			level, category, message)
 	}
 }
@@ -146,12 +179,19 @@ func (l *Logger) LogData(category string, data interface{}) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
-	if l.fileOutput && l.logFile != nil {
-		jsonData, err := json.Marshal(entry)
-		if err == nil {
-			l.logFile.Write(append(jsonData, '\n'))
+	if !l.fileOutput || l.logFile == nil {
+		return
+	}
+	if category == "METRIC" && l.metricFormat == MetricFormatCompact {
+		if line, ok := encodeCompactMetric(data); ok {
+			l.writeLine(entry.Timestamp, category, data, line)
+			return
 		}
 	}
+	jsonData, err := json.Marshal(entry)
+	if err == nil {
+		l.writeLine(entry.Timestamp, category, data, jsonData)
+	}
 }
 
 // Info 输出 INFO 级别日志
@@ -186,21 +226,17 @@ func (l *Logger) Errorf(category, format string, args ...interface{}) {
 
 // Event 输出事件日志
 func (l *Logger) Event(eventType string, pid int32, name, message string) {
-	l.Log("INFO", "EVENT", fmt.Sprintf("%s: %s (pid=%d, name=%s)", eventType, message, pid, name), map[string]interface{}{
-		"event_type": eventType,
-		"pid":        pid,
-		"name":       name,
-	})
+	l.EventWithData(eventType, pid, name, message, nil)
+}
+
+// EventWithData 输出带附加数据的事件日志，附加数据记录在 data.detail 中
+func (l *Logger) EventWithData(eventType string, pid int32, name, message string, detail interface{}) {
+	logEvent(l, eventType, pid, name, message, detail)
 }
 
 // Impact 输出影响分析日志
 func (l *Logger) Impact(impactType, severity, target, source, detail string) {
-	l.Log("INFO", "IMPACT", fmt.Sprintf("[%s] [%s] 目标: %s, 来源: %s - %s", impactType, severity, target, source, detail), map[string]interface{}{
-		"impact_type": impactType,
-		"severity":    severity,
-		"target":      target,
-		"source":      source,
-	})
+	logImpact(l, impactType, severity, target, source, detail)
 }
 
 // Metric 输出指标数据
@@ -247,84 +283,101 @@ func (w *logWriter) Write(p []byte) (n int, err error) {
 	return len(p), nil
 }
 
-// 全局函数，使用默认日志器
+// 全局函数，输出到 SetSink 设置的日志实现（默认为 Init 创建的日志器）
 
-// Default 获取默认日志器
+// Default 获取默认日志器，使用调用方的日志实现时返回 nil
 func Default() *Logger {
 	return defaultLogger
 }
 
 // Info 全局 Info
 func Info(category, message string) {
-	if defaultLogger != nil {
-		defaultLogger.Info(category, message)
-	}
+	logGlobal("INFO", category, message)
 }
 
 // Infof 全局 Infof
 func Infof(category, format string, args ...interface{}) {
-	if defaultLogger != nil {
-		defaultLogger.Infof(category, format, args...)
+	if sink != nil {
+		logGlobal("INFO", category, fmt.Sprintf(format, args...))
 	}
 }
 
 // Warn 全局 Warn
 func Warn(category, message string) {
-	if defaultLogger != nil {
-		defaultLogger.Warn(category, message)
-	}
+	logGlobal("WARN", category, message)
 }
 
 // Warnf 全局 Warnf
 func Warnf(category, format string, args ...interface{}) {
-	if defaultLogger != nil {
-		defaultLogger.Warnf(category, format, args...)
+	if sink != nil {
+		logGlobal("WARN", category, fmt.Sprintf(format, args...))
 	}
 }
 
 // Error 全局 Error
 func Error(category, message string) {
-	if defaultLogger != nil {
-		defaultLogger.Error(category, message)
-	}
+	logGlobal("ERROR", category, message)
 }
 
 // Errorf 全局 Errorf
 func Errorf(category, format string, args ...interface{}) {
-	if defaultLogger != nil {
-		defaultLogger.Errorf(category, format, args...)
+	if sink != nil {
+		logGlobal("ERROR", category, fmt.Sprintf(format, args...))
+	}
+}
+
+// logGlobal 输出到全局日志实现，开启 syslog 转发时按级别和类别同时转发（在日志器的锁外进行）
+func logGlobal(level, category, message string) {
+	if sink == nil {
+		return
 	}
+	sink.Log(level, category, message, nil)
+	forwardSyslog(level, category, levelSyslogSeverity(level), message)
 }
 
 // Event 全局 Event
 func Event(eventType string, pid int32, name, message string) {
-	if defaultLogger != nil {
-		defaultLogger.Event(eventType, pid, name, message)
+	EventWithData(eventType, pid, name, message, nil)
+}
+
+// EventWithData 全局 EventWithData
+func EventWithData(eventType string, pid int32, name, message string, detail interface{}) {
+	if sink != nil {
+		logEvent(sink, eventType, pid, name, message, detail)
 	}
 }
 
 // Impact 全局 Impact
 func Impact(impactType, severity, target, source, detail string) {
-	if defaultLogger != nil {
-		defaultLogger.Impact(impactType, severity, target, source, detail)
+	if sink != nil {
+		logImpact(sink, impactType, severity, target, source, detail)
 	}
 }
 
-// Metric 全局 Metric
+// Metric 全局 Metric，日志实现不支持 LogData 时不记录（每次采样都会调用）
 func Metric(data interface{}) {
-	if defaultLogger != nil {
-		defaultLogger.Metric(data)
+	if ds, ok := sink.(DataSink); ok {
+		ds.LogData("METRIC", data)
+	}
+}
+
+// Audit 全局 Audit，日志实现不支持审计日志时作为 AUDIT 类别的普通日志输出
+func Audit(entry AuditEntry) {
+	if as, ok := sink.(AuditSink); ok {
+		as.Audit(entry)
+	} else if sink != nil {
+		sink.Log("INFO", AuditCategory, entry.Action, entry)
 	}
 }
 
-// Close 关闭默认日志器
+// Close 关闭默认日志器（调用方的日志实现由调用方关闭）
 func Close() {
 	if defaultLogger != nil {
 		defaultLogger.Close()
 	}
 }
 
-// SetConsoleOutput 全局设置终端输出
+// SetConsoleOutput 全局设置终端输出（只对默认日志器有效）
 func SetConsoleOutput(enabled bool) {
 	if defaultLogger != nil {
 		defaultLogger.SetConsoleOutput(enabled)
@@ -0,0 +1,149 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"monitor-agent/types"
+)
+
+// 指标日志的紧凑编码：METRIC 是日志量最大的类别（每个目标每次采样一行），JSON 中重复的字段名和
+// 两个完整时间戳占了大部分体积。紧凑编码每行为 "M1" 开头、制表符分隔的固定字段：
+//
+//	M1 <毫秒时间戳> <pid> <cpu_pct> <rss_bytes> <priority> <nice> <alive 0/1> <name>
+//
+// 进程名放在最后（其中的制表符和换行替换为空格）。事件、影响等其他日志仍为 JSON，便于 grep。
+// 读取日志时用 UnmarshalLine 代替 json.Unmarshal，两种格式都还原为相同结构。
+
+const (
+	MetricFormatJSON    = "json"    // 默认：与其他日志相同的 JSON
+	MetricFormatCompact = "compact" // 紧凑编码
+
+	compactMetricPrefix = "M1\t"
+	compactMetricFields = 9
+)
+
+// NormalizeMetricFormat 校验指标日志编码，空字符串视为 json
+func NormalizeMetricFormat(format string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "", MetricFormatJSON:
+		return MetricFormatJSON, nil
+	case MetricFormatCompact:
+		return MetricFormatCompact, nil
+	}
+	return "", fmt.Errorf("unknown metric format %q (json or compact)", format)
+}
+
+// SetMetricFormat 设置指标日志编码，只影响之后写入的日志
+func (l *Logger) SetMetricFormat(format string) error {
+	f, err := NormalizeMetricFormat(format)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.metricFormat = f
+	return nil
+}
+
+// SetMetricFormat 设置默认日志器的指标日志编码
+func SetMetricFormat(format string) error {
+	if defaultLogger == nil {
+		_, err := NormalizeMetricFormat(format)
+		return err
+	}
+	return defaultLogger.SetMetricFormat(format)
+}
+
+// encodeCompactMetric 把进程指标编码为紧凑格式的一行（不含换行），其他类型的数据返回 false
+func encodeCompactMetric(data interface{}) ([]byte, bool) {
+	var m types.ProcessMetrics
+	switch v := data.(type) {
+	case types.ProcessMetrics:
+		m = v
+	case *types.ProcessMetrics:
+		if v == nil {
+			return nil, false
+		}
+		m = *v
+	default:
+		return nil, false
+	}
+	alive := "0"
+	if m.Alive {
+		alive = "1"
+	}
+	name := strings.NewReplacer("\t", " ", "\n", " ", "\r", " ").Replace(m.Name)
+	line := make([]byte, 0, 64+len(name))
+	line = append(line, compactMetricPrefix...)
+	line = strconv.AppendInt(line, m.Timestamp.UnixMilli(), 10)
+	line = append(line, '\t')
+	line = strconv.AppendInt(line, int64(m.PID), 10)
+	line = append(line, '\t')
+	line = strconv.AppendFloat(line, m.CPUPct, 'f', -1, 64)
+	line = append(line, '\t')
+	line = strconv.AppendUint(line, m.RSSBytes, 10)
+	line = append(line, '\t')
+	line = strconv.AppendInt(line, int64(m.Priority), 10)
+	line = append(line, '\t')
+	line = strconv.AppendInt(line, int64(m.Nice), 10)
+	line = append(line, '\t')
+	line = append(line, alive...)
+	line = append(line, '\t')
+	line = append(line, name...)
+	return line, true
+}
+
+// decodeCompactMetric 解析紧凑格式的指标行
+func decodeCompactMetric(line []byte) (types.ProcessMetrics, error) {
+	var m types.ProcessMetrics
+	fields := strings.SplitN(string(line), "\t", compactMetricFields)
+	if len(fields) != compactMetricFields {
+		return m, fmt.Errorf("compact metric: expected %d fields, got %d", compactMetricFields, len(fields))
+	}
+	ms, err1 := strconv.ParseInt(fields[1], 10, 64)
+	pid, err2 := strconv.ParseInt(fields[2], 10, 32)
+	cpu, err3 := strconv.ParseFloat(fields[3], 64)
+	rss, err4 := strconv.ParseUint(fields[4], 10, 64)
+	prio, err5 := strconv.ParseInt(fields[5], 10, 32)
+	nice, err6 := strconv.ParseInt(fields[6], 10, 32)
+	for _, err := range []error{err1, err2, err3, err4, err5, err6} {
+		if err != nil {
+			return m, fmt.Errorf("compact metric: %w", err)
+		}
+	}
+	m.Timestamp = time.UnixMilli(ms)
+	m.PID = int32(pid)
+	m.CPUPct = cpu
+	m.RSSBytes = rss
+	m.Priority = int32(prio)
+	m.Nice = int32(nice)
+	m.Alive = fields[7] == "1"
+	m.Name = fields[8]
+	return m, nil
+}
+
+// UnmarshalLine 解析日志文件中的一行到 v：JSON 行直接解析，紧凑格式的指标行先还原为与
+// JSON 格式相同的 {"timestamp","category":"METRIC","data":{...}} 结构
+func UnmarshalLine(line []byte, v interface{}) error {
+	if !bytes.HasPrefix(line, []byte(compactMetricPrefix)) {
+		return json.Unmarshal(line, v)
+	}
+	m, err := decodeCompactMetric(line)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(struct {
+		Timestamp time.Time            `json:"timestamp"`
+		Category  string               `json:"category"`
+		Data      types.ProcessMetrics `json:"data"`
+	}{m.Timestamp, "METRIC", m})
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
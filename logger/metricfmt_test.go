@@ -0,0 +1,142 @@
+package logger
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"monitor-agent/types"
+)
+
+// sampleMetric README 中紧凑编码示例对应的采样
+func sampleMetric() types.ProcessMetrics {
+	return types.ProcessMetrics{
+		Timestamp:  time.UnixMilli(1792297173725),
+		PID:        12345,
+		Name:       "edpf_hmi",
+		CPUPct:     12.34,
+		RSSBytes:   523456789,
+		Priority:   20,
+		Alive:      true,
+		IntervalMS: 1000,
+	}
+}
+
+// writeMetricLines 用 format 编码写入 metrics，返回日志文件中的各行
+func writeMetricLines(t *testing.T, format string, metrics ...types.ProcessMetrics) []string {
+	t.Helper()
+	l, err := NewLogger(t.TempDir(), true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.SetMetricFormat(format); err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range metrics {
+		l.Metric(m)
+	}
+	path := l.logFile.Name()
+	l.Close()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if len(lines) != len(metrics) {
+		t.Fatalf("%s: %d lines in %s, want %d", format, len(lines), filepath.Base(path), len(metrics))
+	}
+	return lines
+}
+
+func TestCompactMetricSize(t *testing.T) {
+	m := sampleMetric()
+	jsonLine := writeMetricLines(t, MetricFormatJSON, m)[0]
+	compactLine := writeMetricLines(t, MetricFormatCompact, m)[0]
+
+	if want := "M1\t1792297173725\t12345\t12.34\t523456789\t20\t0\t1\tedpf_hmi"; compactLine != want {
+		t.Fatalf("compact line = %q, want %q", compactLine, want)
+	}
+	// README：JSON 一行约 250 字节，紧凑编码约 55 字节，体积减少约 78%
+	if n := len(compactLine); n > 60 {
+		t.Errorf("compact line is %d bytes, want <= 60", n)
+	}
+	if n := len(jsonLine); n < 200 {
+		t.Errorf("json line is %d bytes, want >= 200: %s", n, jsonLine)
+	}
+	if saved := 1 - float64(len(compactLine))/float64(len(jsonLine)); saved < 0.7 {
+		t.Errorf("compact encoding saves %.0f%% (%d vs %d bytes), want >= 70%%", saved*100, len(compactLine), len(jsonLine))
+	}
+}
+
+// 两种编码的日志行经 UnmarshalLine 还原为相同的结构（时间戳精度为毫秒）
+func TestUnmarshalLineReadsBothFormats(t *testing.T) {
+	metrics := []types.ProcessMetrics{
+		sampleMetric(),
+		{Timestamp: time.UnixMilli(1792297174725), PID: 1, Name: "dead\tproc\nname", CPUPct: 0, Priority: -5, Nice: 19},
+		{Timestamp: time.UnixMilli(1792297175725), PID: 2147483647, Name: "数据采集", CPUPct: 1234.5, RSSBytes: 1<<64 - 1, Alive: true},
+	}
+	jsonLines := writeMetricLines(t, MetricFormatJSON, metrics...)
+	compactLines := writeMetricLines(t, MetricFormatCompact, metrics...)
+
+	type entry struct {
+		Category string               `json:"category"`
+		Data     types.ProcessMetrics `json:"data"`
+	}
+	for i, m := range metrics {
+		var fromJSON, fromCompact entry
+		if err := UnmarshalLine([]byte(jsonLines[i]), &fromJSON); err != nil {
+			t.Fatalf("json line %d: %v", i, err)
+		}
+		if err := UnmarshalLine([]byte(compactLines[i]), &fromCompact); err != nil {
+			t.Fatalf("compact line %d: %v", i, err)
+		}
+		if fromCompact.Category != "METRIC" || fromJSON.Category != "METRIC" {
+			t.Fatalf("line %d categories = %q/%q, want METRIC", i, fromJSON.Category, fromCompact.Category)
+		}
+		// 紧凑编码不记录采样间隔，进程名中的制表符和换行替换为空格
+		want := fromJSON.Data
+		want.IntervalMS = 0
+		want.Name = strings.NewReplacer("\t", " ", "\n", " ").Replace(m.Name)
+		got := fromCompact.Data
+		if !got.Timestamp.Equal(want.Timestamp) {
+			t.Fatalf("line %d timestamp = %v, want %v", i, got.Timestamp, want.Timestamp)
+		}
+		got.Timestamp, want.Timestamp = time.Time{}, time.Time{}
+		if got != want {
+			t.Fatalf("line %d compact = %+v, want %+v", i, got, want)
+		}
+	}
+}
+
+func TestUnmarshalLineRejectsMalformedCompact(t *testing.T) {
+	for _, line := range []string{
+		"M1\t1792297173725\t12345",
+		"M1\tnow\t12345\t1\t1\t20\t0\t1\tname",
+		"M1\t1792297173725\t12345\tbusy\t1\t20\t0\t1\tname",
+	} {
+		var v map[string]interface{}
+		if err := UnmarshalLine([]byte(line), &v); err == nil {
+			t.Errorf("UnmarshalLine(%q) succeeded, want error", line)
+		}
+	}
+}
+
+func TestNormalizeMetricFormat(t *testing.T) {
+	for in, want := range map[string]string{"": "json", "json": "json", " Compact ": "compact"} {
+		if got, err := NormalizeMetricFormat(in); err != nil || got != want {
+			t.Errorf("NormalizeMetricFormat(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := NormalizeMetricFormat("binary"); err == nil {
+		t.Error("NormalizeMetricFormat(\"binary\") succeeded, want error")
+	}
+}
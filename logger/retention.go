@@ -0,0 +1,120 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RetentionPolicy 日志保留策略，两项均为 0 时不清理
+type RetentionPolicy struct {
+	MaxAge        time.Duration // 修改时间早于该时长的日志文件删除，0 表示不按时间清理
+	MaxTotalBytes int64         // 日志文件总大小上限，超出时从最旧的文件删起，0 表示不限制
+}
+
+// CleanupResult 一次清理的结果
+type CleanupResult struct {
+	Removed []string // 已删除的文件名
+	Freed   int64    // 释放的字节数
+	Failed  []string // 删除失败的文件名（如 Windows 下被其他进程占用），下次清理时重试
+	Total   int64    // 清理后剩余日志文件总大小
+}
+
+// IsLogFile 判断文件名是否为日志文件：.jsonl 及轮转、压缩后的文件（如 .jsonl.1、.jsonl.gz）。
+// 审计日志不算在内，不会被保留策略清理
+func IsLogFile(name string) bool {
+	if name == AuditFileName {
+		return false
+	}
+	return strings.HasSuffix(name, ".jsonl") || strings.Contains(name, ".jsonl.")
+}
+
+// CleanupLogs 按保留策略清理日志目录，从最旧的文件删起。
+// 当前正在写入的日志文件不会删除；删除失败的文件记入 Failed 后继续处理其余文件
+func CleanupLogs(dir string, policy RetentionPolicy, now time.Time) (CleanupResult, error) {
+	var result CleanupResult
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return result, err
+	}
+
+	type logFile struct {
+		name    string
+		size    int64
+		modTime time.Time
+	}
+	var files []logFile
+	for _, e := range entries {
+		if e.IsDir() || !IsLogFile(e.Name()) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue // 文件在列目录后被删除
+		}
+		files = append(files, logFile{name: e.Name(), size: info.Size(), modTime: info.ModTime()})
+		result.Total += info.Size()
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.Before(files[j].modTime)
+	})
+
+	current := currentLogFile()
+	for _, f := range files {
+		expired := policy.MaxAge > 0 && now.Sub(f.modTime) > policy.MaxAge
+		oversize := policy.MaxTotalBytes > 0 && result.Total > policy.MaxTotalBytes
+		if !expired && !oversize {
+			break
+		}
+		path := filepath.Join(dir, f.name)
+		if current != "" && sameFile(path, current) {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			if !os.IsNotExist(err) {
+				result.Failed = append(result.Failed, f.name)
+			}
+			continue
+		}
+		result.Removed = append(result.Removed, f.name)
+		result.Freed += f.size
+		result.Total -= f.size
+	}
+	if len(result.Removed) > 0 {
+		pruneIndex(dir)
+	}
+	return result, nil
+}
+
+// currentLogFile 默认日志器正在写入的文件路径，未写文件时返回空字符串
+func currentLogFile() string {
+	if defaultLogger == nil {
+		return ""
+	}
+	return defaultLogger.CurrentFile()
+}
+
+// CurrentFile 正在写入的日志文件路径，未写文件时返回空字符串
+func (l *Logger) CurrentFile() string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if l.logFile == nil {
+		return ""
+	}
+	return l.logFile.Name()
+}
+
+// sameFile 判断两个路径是否指向同一文件
+func sameFile(a, b string) bool {
+	fa, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	fb, err := os.Stat(b)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(fa, fb)
+}
@@ -0,0 +1,59 @@
+package logger
+
+import "fmt"
+
+// Sink 最小日志接口，全局日志函数都通过它输出。默认实现是写 JSONL 文件的 *Logger，
+// 嵌入到其他程序时可用 SetSink 换成宿主的日志实现
+type Sink interface {
+	Log(level, category, message string, data interface{})
+}
+
+// DataSink 可选接口：记录纯数据日志（每次采样的指标），未实现时不记录指标
+type DataSink interface {
+	LogData(category string, data interface{})
+}
+
+// AuditSink 可选接口：记录审计日志，未实现时审计记录作为 AUDIT 类别的普通日志输出
+type AuditSink interface {
+	Audit(entry AuditEntry)
+}
+
+var (
+	_ Sink      = (*Logger)(nil)
+	_ DataSink  = (*Logger)(nil)
+	_ AuditSink = (*Logger)(nil)
+)
+
+// SetSink 替换全局日志实现，应在创建监控器之前调用。s 为 *Logger 时同时作为默认日志器；
+// 否则 Default 返回 nil，依赖日志目录的功能（日志检索、终端输出开关）按未初始化处理
+func SetSink(s Sink) {
+	sink = s
+	defaultLogger, _ = s.(*Logger)
+}
+
+// logEvent 输出事件日志，附加数据记录在 data.detail 中；开启 syslog 转发时同时转发（见 syslog.go）
+func logEvent(s Sink, eventType string, pid int32, name, message string, detail interface{}) {
+	data := map[string]interface{}{
+		"event_type": eventType,
+		"pid":        pid,
+		"name":       name,
+	}
+	if detail != nil {
+		data["detail"] = detail
+	}
+	text := fmt.Sprintf("%s: %s (pid=%d, name=%s)", eventType, message, pid, name)
+	s.Log("INFO", "EVENT", text, data)
+	forwardSyslog("INFO", "EVENT", eventSyslogSeverity(eventType), text)
+}
+
+// logImpact 输出影响分析日志
+func logImpact(s Sink, impactType, severity, target, source, detail string) {
+	text := fmt.Sprintf("[%s] [%s] 目标: %s, 来源: %s - %s", impactType, severity, target, source, detail)
+	s.Log("INFO", "IMPACT", text, map[string]interface{}{
+		"impact_type": impactType,
+		"severity":    severity,
+		"target":      target,
+		"source":      source,
+	})
+	forwardSyslog("INFO", "IMPACT", impactSyslogSeverity(severity), text)
+}
@@ -0,0 +1,355 @@
+package logger
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// 日志转发到 syslog：电厂的基础设施通常把各系统的日志汇总到中心 syslog（rsyslog/SIEM），
+// 开启后选定类别的日志（默认 EVENT、IMPACT）和达到最低级别的日志（默认 WARN 及以上）在写入本地 JSONL 文件的同时转发一份，
+// 日志类别作为 MSGID。SYSLOG 类别（转发器自身的连接日志）不转发，避免 syslog 不可达时自我放大。
+// udp/tcp 按 RFC 5424 格式发送（tcp 按 RFC 6587 加长度前缀），local 写入本机 syslog（仅 Unix）。
+// 转发在单独的协程中进行，不阻塞调用方：队列满时丢弃新消息并计数，连接失败后按 1 秒起逐次翻倍（最长 1 分钟）的间隔重连。
+
+const (
+	// DefaultSyslogTag syslog 消息的 APP-NAME
+	DefaultSyslogTag = "monitor-agent"
+
+	syslogQueueLen     = 1024
+	syslogMinBackoff   = time.Second
+	syslogMaxBackoff   = time.Minute
+	syslogDialTimeout  = 5 * time.Second
+	syslogWriteTimeout = 5 * time.Second
+)
+
+// syslog 严重级别（RFC 5424）
+const (
+	sevCrit    = 2
+	sevErr     = 3
+	sevWarning = 4
+	sevNotice  = 5
+	sevInfo    = 6
+	sevDebug   = 7
+)
+
+// syslogLevels 日志级别的先后顺序（用于 MinLevel 比较），none 表示只按类别转发
+var syslogLevels = map[string]int{"debug": 0, "info": 1, "warn": 2, "error": 3, "none": 4}
+
+// DefaultSyslogCategories 未指定类别时总是转发的日志类别
+var DefaultSyslogCategories = []string{"EVENT", "IMPACT"}
+
+// syslogFacilities 可用的 facility 名称
+var syslogFacilities = map[string]int{
+	"user": 1, "daemon": 3,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19,
+	"local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+// SyslogOptions 转发选项
+type SyslogOptions struct {
+	Network  string // udp、tcp，或 local（本机 syslog，仅 Unix）
+	Addr     string // udp/tcp 的接收地址 host:port
+	Facility string // user、daemon、local0～local7，空表示 local0
+	Tag      string // APP-NAME，空表示 monitor-agent
+
+	// MinLevel 其他类别的日志达到该级别才转发：debug、info、warn、error，none 表示只转发 Categories，空表示 warn
+	MinLevel string
+	// Categories 不论级别总是转发的日志类别（不区分大小写），nil 表示 EVENT、IMPACT
+	Categories []string
+}
+
+// ParseSyslogFacility 解析 facility 名称（不区分大小写），空表示 local0
+func ParseSyslogFacility(name string) (int, error) {
+	if name == "" {
+		return syslogFacilities["local0"], nil
+	}
+	f, ok := syslogFacilities[strings.ToLower(name)]
+	if !ok {
+		return 0, fmt.Errorf("unknown syslog facility %q, expected user, daemon or local0-local7", name)
+	}
+	return f, nil
+}
+
+// ValidateSyslog 校验转发选项
+func ValidateSyslog(opts SyslogOptions) error {
+	switch strings.ToLower(opts.Network) {
+	case "udp", "tcp":
+		if _, _, err := net.SplitHostPort(opts.Addr); err != nil {
+			return fmt.Errorf("invalid syslog addr %q, expected host:port", opts.Addr)
+		}
+	case "local":
+		if !localSyslogSupported {
+			return fmt.Errorf("local syslog is not supported on this platform, use udp or tcp")
+		}
+	default:
+		return fmt.Errorf("unknown syslog network %q, expected udp, tcp or local", opts.Network)
+	}
+	if _, ok := syslogLevels[strings.ToLower(opts.MinLevel)]; opts.MinLevel != "" && !ok {
+		return fmt.Errorf("unknown syslog min level %q, expected debug, info, warn, error or none", opts.MinLevel)
+	}
+	_, err := ParseSyslogFacility(opts.Facility)
+	return err
+}
+
+// syslogMessage 一条待转发的日志
+type syslogMessage struct {
+	ts       time.Time
+	severity int
+	msgID    string // 日志类别 EVENT/IMPACT
+	text     string
+}
+
+// syslogConn 一个 syslog 连接
+type syslogConn interface {
+	write(m syslogMessage) error
+	Close() error
+}
+
+// SyslogForwarder 把选定的日志转发到 syslog
+type SyslogForwarder struct {
+	opts     SyslogOptions
+	facility int
+	host     string
+	queue    chan syslogMessage
+	dropped  atomic.Int64
+	stop     chan struct{}
+	done     chan struct{}
+
+	minLevel   int
+	categories map[string]bool
+}
+
+// NewSyslogForwarder 校验选项并启动转发协程（首次连接在协程中进行，连接失败不返回错误）
+func NewSyslogForwarder(opts SyslogOptions) (*SyslogForwarder, error) {
+	if err := ValidateSyslog(opts); err != nil {
+		return nil, err
+	}
+	opts.Network = strings.ToLower(opts.Network)
+	if opts.Tag == "" {
+		opts.Tag = DefaultSyslogTag
+	}
+	facility, _ := ParseSyslogFacility(opts.Facility)
+	host, _ := os.Hostname()
+	f := &SyslogForwarder{
+		opts:     opts,
+		facility: facility,
+		host:     host,
+		queue:    make(chan syslogMessage, syslogQueueLen),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	f.minLevel = syslogLevels["warn"]
+	if opts.MinLevel != "" {
+		f.minLevel = syslogLevels[strings.ToLower(opts.MinLevel)]
+	}
+	categories := opts.Categories
+	if categories == nil {
+		categories = DefaultSyslogCategories
+	}
+	f.categories = make(map[string]bool, len(categories))
+	for _, c := range categories {
+		f.categories[strings.ToUpper(c)] = true
+	}
+	go f.run()
+	return f, nil
+}
+
+// Dropped 因队列满（syslog 长时间不可达）丢弃的消息数
+func (f *SyslogForwarder) Dropped() int64 {
+	return f.dropped.Load()
+}
+
+// Close 停止转发协程并关闭连接，队列中未发送的消息丢弃
+func (f *SyslogForwarder) Close() {
+	close(f.stop)
+	<-f.done
+}
+
+// wants 是否转发该级别、类别的日志
+func (f *SyslogForwarder) wants(level, category string) bool {
+	if category == "SYSLOG" {
+		return false
+	}
+	if f.categories[category] {
+		return true
+	}
+	rank, ok := syslogLevels[strings.ToLower(level)]
+	return ok && rank >= f.minLevel
+}
+
+// send 排入一条消息，队列满时丢弃
+func (f *SyslogForwarder) send(m syslogMessage) {
+	select {
+	case f.queue <- m:
+	default:
+		f.dropped.Add(1)
+	}
+}
+
+// run 转发协程：按顺序发送，发送失败时断开重连后重发同一条消息
+func (f *SyslogForwarder) run() {
+	defer close(f.done)
+	var conn syslogConn
+	defer func() {
+		if conn != nil {
+			conn.Close()
+		}
+	}()
+	backoff := syslogMinBackoff
+	failing := false
+
+	for {
+		var m syslogMessage
+		select {
+		case <-f.stop:
+			return
+		case m = <-f.queue:
+		}
+
+		for {
+			if conn == nil {
+				c, err := f.dial()
+				if err != nil {
+					if !failing {
+						Warnf("SYSLOG", "Connect to syslog %s %s failed, retrying: %v", f.opts.Network, f.opts.Addr, err)
+						failing = true
+					}
+					select {
+					case <-f.stop:
+						return
+					case <-time.After(backoff):
+					}
+					if backoff *= 2; backoff > syslogMaxBackoff {
+						backoff = syslogMaxBackoff
+					}
+					continue
+				}
+				conn = c
+				backoff = syslogMinBackoff
+				if failing {
+					Infof("SYSLOG", "Reconnected to syslog %s %s (%d messages dropped so far)", f.opts.Network, f.opts.Addr, f.Dropped())
+					failing = false
+				}
+			}
+			if err := conn.write(m); err != nil {
+				Warnf("SYSLOG", "Write to syslog %s %s failed, reconnecting: %v", f.opts.Network, f.opts.Addr, err)
+				conn.Close()
+				conn = nil
+				failing = true
+				continue
+			}
+			break
+		}
+	}
+}
+
+// dial 按网络类型建立连接
+func (f *SyslogForwarder) dial() (syslogConn, error) {
+	if f.opts.Network == "local" {
+		return dialLocalSyslog(f.facility, f.opts.Tag)
+	}
+	c, err := net.DialTimeout(f.opts.Network, f.opts.Addr, syslogDialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	return &rfc5424Conn{
+		conn:     c,
+		framed:   f.opts.Network == "tcp",
+		facility: f.facility,
+		host:     f.host,
+		tag:      f.opts.Tag,
+		pid:      os.Getpid(),
+	}, nil
+}
+
+// rfc5424Conn 按 RFC 5424 格式发送的 udp/tcp 连接
+type rfc5424Conn struct {
+	conn     net.Conn
+	framed   bool // tcp：按 RFC 6587 在消息前加 "长度 "
+	facility int
+	host     string
+	tag      string
+	pid      int
+}
+
+func (c *rfc5424Conn) write(m syslogMessage) error {
+	host := c.host
+	if host == "" {
+		host = "-"
+	}
+	// <PRI>VERSION TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG
+	msg := fmt.Sprintf("<%d>1 %s %s %s %d %s - %s", c.facility*8+m.severity,
+		m.ts.Format("2006-01-02T15:04:05.000000Z07:00"), host, c.tag, c.pid, m.msgID, m.text)
+	if c.framed {
+		msg = fmt.Sprintf("%d %s", len(msg), msg)
+	}
+	c.conn.SetWriteDeadline(time.Now().Add(syslogWriteTimeout))
+	_, err := c.conn.Write([]byte(msg))
+	return err
+}
+
+func (c *rfc5424Conn) Close() error {
+	return c.conn.Close()
+}
+
+var syslogForwarder atomic.Pointer[SyslogForwarder]
+
+// SetSyslogForwarder 设置全局转发器（nil 表示不转发），返回之前的转发器，由调用方关闭
+func SetSyslogForwarder(f *SyslogForwarder) *SyslogForwarder {
+	return syslogForwarder.Swap(f)
+}
+
+// forwardSyslog 按转发器的级别和类别设置转发一条日志，未设置转发器时什么也不做。
+// 只排入队列，不阻塞调用方，也不在日志器的锁内调用
+func forwardSyslog(level, category string, severity int, text string) {
+	if f := syslogForwarder.Load(); f != nil && f.wants(level, category) {
+		f.send(syslogMessage{ts: time.Now(), severity: severity, msgID: category, text: text})
+	}
+}
+
+// levelSyslogSeverity 日志级别对应的 syslog 严重级别
+func levelSyslogSeverity(level string) int {
+	switch level {
+	case "ERROR":
+		return sevErr
+	case "WARN":
+		return sevWarning
+	case "DEBUG":
+		return sevDebug
+	}
+	return sevInfo
+}
+
+// eventSyslogSeverity 事件类型对应的 syslog 严重级别：目标退出、探测失败、重启失败等为 err，
+// 需要关注的变化和风险为 warning，其余（新进程、恢复、维护等）为 notice
+func eventSyslogSeverity(eventType string) int {
+	switch eventType {
+	case "exit", "gone", "restart_failed", "probe_down", "binary_changed":
+		return sevErr
+	case "reexec", "priority_changed", "event_storm", "config_drift", "restart_attempt":
+		return sevWarning
+	case "impact_resolved":
+		return sevNotice
+	}
+	if strings.HasPrefix(eventType, "impact_") {
+		return sevWarning
+	}
+	return sevNotice
+}
+
+// impactSyslogSeverity 风险严重级别对应的 syslog 严重级别
+func impactSyslogSeverity(severity string) int {
+	switch severity {
+	case "critical":
+		return sevCrit
+	case "high":
+		return sevErr
+	case "medium":
+		return sevWarning
+	}
+	return sevNotice
+}
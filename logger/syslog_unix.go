@@ -0,0 +1,41 @@
+//go:build !windows
+
+package logger
+
+import "log/syslog"
+
+// localSyslogSupported 是否支持写入本机 syslog
+const localSyslogSupported = true
+
+// localSyslog 本机 syslog（/dev/log 等），严重级别由写入方法决定
+type localSyslog struct {
+	w *syslog.Writer
+}
+
+func dialLocalSyslog(facility int, tag string) (syslogConn, error) {
+	w, err := syslog.New(syslog.Priority(facility<<3)|syslog.LOG_NOTICE, tag)
+	if err != nil {
+		return nil, err
+	}
+	return localSyslog{w: w}, nil
+}
+
+func (l localSyslog) write(m syslogMessage) error {
+	switch m.severity {
+	case sevCrit:
+		return l.w.Crit(m.text)
+	case sevErr:
+		return l.w.Err(m.text)
+	case sevWarning:
+		return l.w.Warning(m.text)
+	case sevInfo:
+		return l.w.Info(m.text)
+	case sevDebug:
+		return l.w.Debug(m.text)
+	}
+	return l.w.Notice(m.text)
+}
+
+func (l localSyslog) Close() error {
+	return l.w.Close()
+}
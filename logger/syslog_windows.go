@@ -0,0 +1,12 @@
+//go:build windows
+
+package logger
+
+import "errors"
+
+// localSyslogSupported Windows 没有本机 syslog，只能使用 udp/tcp
+const localSyslogSupported = false
+
+func dialLocalSyslog(facility int, tag string) (syslogConn, error) {
+	return nil, errors.New("local syslog is not supported on Windows")
+}
@@ -0,0 +1,226 @@
+package logquery
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"monitor-agent/logger"
+)
+
+// DaySummary 某天的事件和影响日志统计
+type DaySummary struct {
+	Date       string         `json:"date"`
+	Events     int            `json:"events"`
+	Impacts    int            `json:"impacts"`
+	BySeverity map[string]int `json:"impacts_by_severity"`
+}
+
+// DayQuery 按日浏览条件
+type DayQuery struct {
+	Date     string // 日期，格式 2006-01-02（配置的时区）
+	Category string // EVENT 或 IMPACT（不区分大小写），空表示两者
+	Offset   int    // 跳过的条数
+	Limit    int    // 最多返回条数，<=0 表示不限制
+}
+
+// DayResult 某天的日志，按时间从早到晚排列
+type DayResult struct {
+	DaySummary
+	Total   int     `json:"total"` // 符合类别条件的总条数，用于分页
+	Offset  int     `json:"offset"`
+	Entries []Entry `json:"entries"`
+	Missing int     `json:"missing"` // 索引指向的日志文件已被清理或无法读取的条数
+}
+
+// ParseDay 校验日期参数
+func ParseDay(date string) (string, error) {
+	t, err := time.Parse(logger.DayLayout, date)
+	if err != nil {
+		return "", fmt.Errorf("date must be YYYY-MM-DD")
+	}
+	return t.Format(logger.DayLayout), nil
+}
+
+// Days 列出有事件或影响日志的日期及各日统计，从新到旧排列。
+// 先为尚未索引的日志文件补建索引；日志文件已被清理的记录不计入
+func Days(dir string) ([]DaySummary, error) {
+	if _, err := logger.BuildIndex(dir); err != nil {
+		return nil, err
+	}
+	days, err := logger.IndexedDays(dir)
+	if err != nil {
+		return nil, err
+	}
+	exists := newFileChecker(dir)
+	summaries := []DaySummary{}
+	for _, day := range days {
+		records, err := logger.ReadDayIndex(dir, day)
+		if err != nil {
+			continue
+		}
+		s := summarize(day, records, exists)
+		if s.Events+s.Impacts > 0 {
+			summaries = append(summaries, s)
+		}
+	}
+	return summaries, nil
+}
+
+// Day 读取某天的事件和影响日志（分页），只读取索引指向的行
+func Day(dir string, q DayQuery) (DayResult, error) {
+	result := DayResult{Entries: []Entry{}, Offset: q.Offset}
+	date, err := ParseDay(q.Date)
+	if err != nil {
+		return result, err
+	}
+	if _, err := logger.BuildIndex(dir); err != nil {
+		return result, err
+	}
+	records, err := logger.ReadDayIndex(dir, date)
+	if err != nil {
+		return result, err
+	}
+	exists := newFileChecker(dir)
+	result.DaySummary = summarize(date, records, exists)
+
+	var selected []logger.IndexRecord
+	for _, rec := range records {
+		if q.Category != "" && !strings.EqualFold(rec.Category, q.Category) {
+			continue
+		}
+		if !exists(rec.File) {
+			result.Missing++
+			continue
+		}
+		selected = append(selected, rec)
+	}
+	result.Total = len(selected)
+	if q.Offset >= len(selected) {
+		return result, nil
+	}
+	selected = selected[q.Offset:]
+	if q.Limit > 0 && len(selected) > q.Limit {
+		selected = selected[:q.Limit]
+	}
+
+	// 按文件分组读取，再按索引顺序输出
+	byFile := make(map[string][]int64)
+	for _, rec := range selected {
+		byFile[rec.File] = append(byFile[rec.File], rec.Offset)
+	}
+	lines := make(map[string]map[int64]Entry)
+	for name, offsets := range byFile {
+		entries, err := readAtOffsets(filepath.Join(dir, name), offsets)
+		if err != nil {
+			continue
+		}
+		lines[name] = entries
+	}
+	for _, rec := range selected {
+		e, ok := lines[rec.File][rec.Offset]
+		if !ok {
+			result.Missing++
+			continue
+		}
+		e.File = rec.File
+		result.Entries = append(result.Entries, e)
+	}
+	return result, nil
+}
+
+// summarize 统计日志文件仍存在的索引记录
+func summarize(day string, records []logger.IndexRecord, exists func(string) bool) DaySummary {
+	s := DaySummary{Date: day, BySeverity: map[string]int{}}
+	for _, rec := range records {
+		if !exists(rec.File) {
+			continue
+		}
+		switch rec.Category {
+		case "EVENT":
+			s.Events++
+		case "IMPACT":
+			s.Impacts++
+			if rec.Severity != "" {
+				s.BySeverity[strings.ToLower(rec.Severity)]++
+			}
+		}
+	}
+	return s
+}
+
+// newFileChecker 返回带缓存的日志文件存在性检查
+func newFileChecker(dir string) func(string) bool {
+	cache := make(map[string]bool)
+	return func(name string) bool {
+		ok, seen := cache[name]
+		if !seen {
+			_, err := os.Stat(filepath.Join(dir, name))
+			ok = err == nil
+			cache[name] = ok
+		}
+		return ok
+	}
+}
+
+// readAtOffsets 读取文件中指定偏移处的日志行。普通文件直接定位，压缩文件顺序解压跳过
+func readAtOffsets(path string, offsets []int64) (map[int64]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	sorted := append([]int64(nil), offsets...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	entries := make(map[int64]Entry, len(sorted))
+
+	if !strings.HasSuffix(path, ".gz") {
+		for _, off := range sorted {
+			r := bufio.NewReader(io.NewSectionReader(f, off, maxLineSize))
+			line, err := r.ReadBytes('\n')
+			if err != nil && err != io.EOF {
+				continue
+			}
+			var e Entry
+			if json.Unmarshal(line, &e) == nil {
+				entries[off] = e
+			}
+		}
+		return entries, nil
+	}
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+	r := bufio.NewReaderSize(gz, 64*1024)
+	var pos int64
+	for _, off := range sorted {
+		if off < pos {
+			continue // 重复偏移
+		}
+		if _, err := r.Discard(int(off - pos)); err != nil {
+			break
+		}
+		pos = off
+		line, err := r.ReadBytes('\n')
+		pos += int64(len(line))
+		var e Entry
+		if json.Unmarshal(line, &e) == nil {
+			entries[off] = e
+		}
+		if err != nil {
+			break
+		}
+	}
+	return entries, nil
+}
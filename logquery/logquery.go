@@ -0,0 +1,190 @@
+// Package logquery 在日志目录的 JSONL 文件中检索历史日志，供 Web API 和 CLI 共用
+package logquery
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"monitor-agent/logger"
+)
+
+const (
+	// DefaultLimit 未指定条数时最多返回的日志条数
+	DefaultLimit = 100
+	// DefaultMaxBytes 未指定上限时单次检索最多读取的字节数
+	DefaultMaxBytes = 64 << 20
+
+	maxLineSize = 1 << 20 // 单行日志最大长度
+)
+
+// Query 检索条件，零值字段表示不限制
+type Query struct {
+	Category string    // 日志类别（不区分大小写），如 EVENT、IMPACT
+	Level    string    // 日志级别（不区分大小写），如 WARN
+	PID      int32     // data.pid 等于该值（事件、指标日志）
+	Text     string    // 子串（不区分大小写），匹配消息和展开后的 data 各字段值
+	From     time.Time // 起始时间（含）
+	To       time.Time // 结束时间（含）
+	Limit    int       // 最多返回条数，<=0 使用 DefaultLimit
+	MaxBytes int64     // 最多读取字节数，<=0 使用 DefaultMaxBytes
+}
+
+// Entry 一条日志记录
+type Entry struct {
+	Timestamp time.Time   `json:"timestamp"`
+	Level     string      `json:"level,omitempty"`
+	Category  string      `json:"category"`
+	Message   string      `json:"message,omitempty"`
+	Data      interface{} `json:"data,omitempty"`
+	File      string      `json:"file"` // 所在日志文件名
+}
+
+// Stats 检索过程统计
+type Stats struct {
+	FilesScanned int   `json:"files_scanned"`
+	BytesScanned int64 `json:"bytes_scanned"`
+	LimitReached bool  `json:"limit_reached"` // 已达到条数上限，可能还有更早的匹配日志
+	Truncated    bool  `json:"truncated"`     // 达到读取字节上限，更早的日志未检索
+}
+
+// Result 检索结果，按时间从新到旧排列
+type Result struct {
+	Entries []Entry `json:"entries"`
+	Stats
+}
+
+// Search 从最新的日志文件开始检索，按时间从新到旧依次回调匹配的日志，
+// 达到条数或字节上限、或 fn 返回 false 时停止
+func Search(dir string, q Query, fn func(Entry) bool) (Stats, error) {
+	var stats Stats
+	files, err := listFiles(dir, q.From)
+	if err != nil {
+		return stats, err
+	}
+	limit := q.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	maxBytes := q.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBytes
+	}
+	m := newMatcher(q)
+
+	emitted := 0
+	for _, name := range files {
+		if stats.BytesScanned >= maxBytes {
+			stats.Truncated = true
+			break
+		}
+		// 文件内按时间顺序记录，只保留最新的 limit-emitted 条匹配，再倒序输出
+		matches, truncated, err := scanFile(filepath.Join(dir, name), m, limit-emitted, maxBytes, &stats.BytesScanned)
+		if err != nil {
+			continue // 文件已被清理或无法读取（如被占用）
+		}
+		stats.FilesScanned++
+		for i := len(matches) - 1; i >= 0; i-- {
+			matches[i].File = name
+			if !fn(matches[i]) {
+				return stats, nil
+			}
+			emitted++
+		}
+		if truncated {
+			stats.Truncated = true
+			break
+		}
+		if emitted >= limit {
+			stats.LimitReached = true
+			break
+		}
+	}
+	return stats, nil
+}
+
+// Collect 检索并返回全部匹配结果
+func Collect(dir string, q Query) (Result, error) {
+	result := Result{Entries: []Entry{}}
+	stats, err := Search(dir, q, func(e Entry) bool {
+		result.Entries = append(result.Entries, e)
+		return true
+	})
+	result.Stats = stats
+	return result, err
+}
+
+// listFiles 列出日志文件，按修改时间从新到旧排列，跳过最后修改早于 from 的文件
+func listFiles(dir string, from time.Time) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read log dir: %w", err)
+	}
+	type file struct {
+		name    string
+		modTime time.Time
+	}
+	var files []file
+	for _, e := range entries {
+		if e.IsDir() || !logger.IsLogFile(e.Name()) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil || (!from.IsZero() && info.ModTime().Before(from)) {
+			continue
+		}
+		files = append(files, file{name: e.Name(), modTime: info.ModTime()})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.After(files[j].modTime)
+	})
+	names := make([]string, len(files))
+	for i, f := range files {
+		names[i] = f.name
+	}
+	return names, nil
+}
+
+// scanFile 扫描单个文件，返回最新的 keep 条匹配（按时间顺序）；读取量累计到 scanned，超过 maxBytes 时停止并返回 truncated
+func scanFile(path string, m *matcher, keep int, maxBytes int64, scanned *int64) (matches []Entry, truncated bool, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, false, err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, false, err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		*scanned += int64(len(line)) + 1
+		if *scanned > maxBytes {
+			return matches, true, nil
+		}
+		var e Entry
+		if err := logger.UnmarshalLine(line, &e); err != nil || !m.match(&e) {
+			continue
+		}
+		matches = append(matches, e)
+		if len(matches) > keep {
+			matches = matches[1:]
+		}
+	}
+	return matches, false, nil
+}
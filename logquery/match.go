@@ -0,0 +1,83 @@
+package logquery
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// matcher 按检索条件过滤日志
+type matcher struct {
+	q    Query
+	text string // 小写的检索文本
+}
+
+func newMatcher(q Query) *matcher {
+	return &matcher{q: q, text: strings.ToLower(q.Text)}
+}
+
+func (m *matcher) match(e *Entry) bool {
+	q := m.q
+	if q.Category != "" && !strings.EqualFold(e.Category, q.Category) {
+		return false
+	}
+	if q.Level != "" && !strings.EqualFold(e.Level, q.Level) {
+		return false
+	}
+	if !q.From.IsZero() && e.Timestamp.Before(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && e.Timestamp.After(q.To) {
+		return false
+	}
+	if q.PID != 0 && dataPID(e.Data) != q.PID {
+		return false
+	}
+	if m.text == "" {
+		return true
+	}
+	if strings.Contains(strings.ToLower(e.Message), m.text) {
+		return true
+	}
+	return containsValue(e.Data, m.text)
+}
+
+// dataPID 取 data.pid，没有时返回 0
+func dataPID(data interface{}) int32 {
+	obj, ok := data.(map[string]interface{})
+	if !ok {
+		return 0
+	}
+	if pid, ok := obj["pid"].(float64); ok {
+		return int32(pid)
+	}
+	return 0
+}
+
+// containsValue 展开 data 中的各字段值（含嵌套对象和数组），判断是否包含小写文本 text
+func containsValue(v interface{}, text string) bool {
+	switch val := v.(type) {
+	case nil:
+		return false
+	case map[string]interface{}:
+		for _, item := range val {
+			if containsValue(item, text) {
+				return true
+			}
+		}
+		return false
+	case []interface{}:
+		for _, item := range val {
+			if containsValue(item, text) {
+				return true
+			}
+		}
+		return false
+	case string:
+		return strings.Contains(strings.ToLower(val), text)
+	case float64:
+		return strings.Contains(strconv.FormatFloat(val, 'f', -1, 64), text)
+	default:
+		return strings.Contains(strings.ToLower(fmt.Sprint(val)), text)
+	}
+}
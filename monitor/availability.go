@@ -0,0 +1,322 @@
+package monitor
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"monitor-agent/logger"
+	"monitor-agent/types"
+)
+
+const (
+	availabilityFile         = "availability.json"
+	availabilityRetention    = 45 * 24 * time.Hour // 保留约一个半月，满足月度可用率统计
+	availabilitySaveInterval = time.Minute
+	availabilityMaxOutages   = 500 // 每个目标最多保留的停运区间数
+
+	// 相邻两次采样间隔超过 max(availabilityGapFactor×采样间隔, availabilityMinGap)
+	// 视为代理未运行（或监控已停止），该段时间计为 unknown
+	availabilityGapFactor = 3
+	availabilityMinGap    = 5 * time.Second
+)
+
+// availabilityBucket 每小时的存活/停运秒数
+type availabilityBucket struct {
+	Up   float64 `json:"up"`
+	Down float64 `json:"down"`
+}
+
+// outageRecord 停运记录，End 为零值表示仍在停运
+type outageRecord struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+type targetAvailability struct {
+	Name       string                        `json:"name"`
+	Hours      map[int64]*availabilityBucket `json:"hours"` // Unix 小时序号 -> 计数
+	Outages    []outageRecord                `json:"outages"`
+	LastSample time.Time                     `json:"last_sample"`
+}
+
+// AvailabilityTracker 按目标累计可用时间，并持久化到日志目录下的状态文件
+// 代理重启后从状态文件继续累计；代理停止期间不计入停运时间
+type AvailabilityTracker struct {
+	mu       sync.Mutex
+	path     string
+	targets  map[int32]*targetAvailability
+	lastSave time.Time
+	dirty    bool
+}
+
+// NewAvailabilityTracker 创建可用率追踪器，并加载已有状态文件
+func NewAvailabilityTracker(logDir string) *AvailabilityTracker {
+	t := &AvailabilityTracker{
+		path:     filepath.Join(logDir, availabilityFile),
+		targets:  make(map[int32]*targetAvailability),
+		lastSave: time.Now(),
+	}
+	if err := t.load(); err != nil {
+		logger.Warnf("MONITOR", "Load availability state failed: %v", err)
+	}
+	return t
+}
+
+func (t *AvailabilityTracker) load() error {
+	data, err := os.ReadFile(t.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	targets := make(map[int32]*targetAvailability)
+	if err := json.Unmarshal(data, &targets); err != nil {
+		return err
+	}
+	for _, ta := range targets {
+		if ta.Hours == nil {
+			ta.Hours = make(map[int64]*availabilityBucket)
+		}
+	}
+	t.targets = targets
+	return nil
+}
+
+// Record 记录一次采样结果
+// 两次采样之间的时间按本次观测到的状态计入；间隔过大时计为 unknown，
+// 未结束的停运区间在上次采样时刻截止（代理停止期间的状态未知）
+func (t *AvailabilityTracker) Record(pid int32, name string, alive bool, now time.Time, interval time.Duration) {
+	maxGap := interval * availabilityGapFactor
+	if maxGap < availabilityMinGap {
+		maxGap = availabilityMinGap
+	}
+
+	t.mu.Lock()
+	ta, ok := t.targets[pid]
+	if !ok {
+		ta = &targetAvailability{Hours: make(map[int64]*availabilityBucket)}
+		t.targets[pid] = ta
+	}
+	ta.Name = name
+
+	continuous := false
+	if !ta.LastSample.IsZero() {
+		elapsed := now.Sub(ta.LastSample)
+		continuous = elapsed > 0 && elapsed <= maxGap
+		if continuous {
+			hour := now.Unix() / 3600
+			b, ok := ta.Hours[hour]
+			if !ok {
+				b = &availabilityBucket{}
+				ta.Hours[hour] = b
+			}
+			if alive {
+				b.Up += elapsed.Seconds()
+			} else {
+				b.Down += elapsed.Seconds()
+			}
+		}
+	}
+
+	open := ta.openOutage()
+	if open != nil && !continuous {
+		open.End = ta.LastSample
+		open = nil
+	}
+	switch {
+	case !alive && open == nil:
+		ta.Outages = append(ta.Outages, outageRecord{Start: now})
+	case alive && open != nil:
+		open.End = now
+	}
+
+	ta.LastSample = now
+	t.dirty = true
+	due := now.Sub(t.lastSave) >= availabilitySaveInterval
+	t.mu.Unlock()
+
+	if due {
+		if err := t.Save(); err != nil {
+			logger.Warnf("MONITOR", "Save availability state failed: %v", err)
+		}
+	}
+}
+
+// openOutage 返回未结束的停运记录
+func (ta *targetAvailability) openOutage() *outageRecord {
+	if n := len(ta.Outages); n > 0 && ta.Outages[n-1].End.IsZero() {
+		return &ta.Outages[n-1]
+	}
+	return nil
+}
+
+// Has 是否有该目标的可用率记录
+func (t *AvailabilityTracker) Has(pid int32) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, ok := t.targets[pid]
+	return ok
+}
+
+// Report 统计 [from, to] 内的可用率（按小时粒度累计）
+func (t *AvailabilityTracker) Report(pid int32, from, to time.Time) types.AvailabilityReport {
+	report := types.AvailabilityReport{
+		PID:     pid,
+		From:    from,
+		To:      to,
+		Outages: []types.OutageInterval{},
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	ta, ok := t.targets[pid]
+	if ok {
+		report.Name = ta.Name
+		fromHour, toHour := from.Unix()/3600, to.Unix()/3600
+		for hour, b := range ta.Hours {
+			if hour >= fromHour && hour <= toHour {
+				report.UpSeconds += b.Up
+				report.DownSeconds += b.Down
+			}
+		}
+
+		for _, o := range ta.Outages {
+			ongoing := o.End.IsZero()
+			end := o.End
+			if ongoing {
+				end = to
+			}
+			if !o.Start.Before(to) || !end.After(from) {
+				continue
+			}
+			start := o.Start
+			if start.Before(from) {
+				start = from
+			}
+			if end.After(to) {
+				end = to
+			}
+			if !end.After(start) {
+				continue // 仅观测到一次停运即进入未知区间
+			}
+			report.Outages = append(report.Outages, types.OutageInterval{
+				Start:    start,
+				End:      end,
+				Ongoing:  ongoing,
+				Duration: end.Sub(start).Seconds(),
+			})
+		}
+	}
+
+	known := report.UpSeconds + report.DownSeconds
+	if known > 0 {
+		pct := report.UpSeconds / known * 100
+		report.UptimePct = &pct
+	}
+	if unknown := to.Sub(from).Seconds() - known; unknown > 0 {
+		report.UnknownSeconds = unknown
+	}
+	return report
+}
+
+// Save 清理过期数据并写入状态文件
+func (t *AvailabilityTracker) Save() error {
+	t.mu.Lock()
+	if !t.dirty {
+		t.mu.Unlock()
+		return nil
+	}
+	t.pruneLocked(time.Now())
+	data, err := json.Marshal(t.targets)
+	t.dirty = false
+	t.lastSave = time.Now()
+	t.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(t.path), 0755); err != nil {
+		return err
+	}
+	tmp := t.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, t.path)
+}
+
+func (t *AvailabilityTracker) pruneLocked(now time.Time) {
+	cutoff := now.Add(-availabilityRetention)
+	cutoffHour := cutoff.Unix() / 3600
+
+	for pid, ta := range t.targets {
+		for hour := range ta.Hours {
+			if hour < cutoffHour {
+				delete(ta.Hours, hour)
+			}
+		}
+
+		kept := ta.Outages[:0]
+		for _, o := range ta.Outages {
+			if o.End.IsZero() || o.End.After(cutoff) {
+				kept = append(kept, o)
+			}
+		}
+		if len(kept) > availabilityMaxOutages {
+			kept = kept[len(kept)-availabilityMaxOutages:]
+		}
+		ta.Outages = kept
+
+		if len(ta.Hours) == 0 && len(ta.Outages) == 0 && ta.LastSample.Before(cutoff) {
+			delete(t.targets, pid)
+		}
+	}
+}
+
+// GetAvailability 获取目标最近 window 时间内的可用率
+func (m *MultiMonitor) GetAvailability(pid int32, window time.Duration) (types.AvailabilityReport, bool) {
+	m.mu.RLock()
+	state, monitored := m.targets[pid]
+	name := ""
+	if monitored {
+		name = state.target.Name
+	}
+	m.mu.RUnlock()
+
+	if !monitored && !m.availability.Has(pid) {
+		return types.AvailabilityReport{}, false
+	}
+
+	to := time.Now()
+	report := m.availability.Report(pid, to.Add(-window), to)
+	if report.Name == "" {
+		report.Name = name
+	}
+	return report, true
+}
+
+// GetAllAvailability 获取所有当前监控目标的可用率（按 PID 排序）
+func (m *MultiMonitor) GetAllAvailability(window time.Duration) []types.AvailabilityReport {
+	m.mu.RLock()
+	pids := make([]int32, 0, len(m.targets))
+	for pid := range m.targets {
+		pids = append(pids, pid)
+	}
+	m.mu.RUnlock()
+	sort.Slice(pids, func(i, j int) bool { return pids[i] < pids[j] })
+
+	reports := make([]types.AvailabilityReport, 0, len(pids))
+	for _, pid := range pids {
+		if report, ok := m.GetAvailability(pid, window); ok {
+			reports = append(reports, report)
+		}
+	}
+	return reports
+}
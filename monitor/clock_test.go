@@ -0,0 +1,64 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+
+	"monitor-agent/clock"
+	"monitor-agent/provider"
+	"monitor-agent/types"
+)
+
+// 采样调度按监控器的时钟等待：时钟不动时不采样，推进一个采样间隔采样一次
+func TestSamplingFollowsInjectedClock(t *testing.T) {
+	c := clock.NewFake(time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC))
+	prov := provider.NewFake(c)
+	prov.SetProcesses([]types.ProcessInfo{{PID: 900001, Name: "scada", Status: "S"}})
+	m, err := NewMultiMonitor(types.MultiMonitorConfig{SampleInterval: 1, LogDir: t.TempDir()}, prov)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.SetClock(c)
+	if err := m.AddTarget(types.MonitorTarget{PID: 900001, Name: "scada"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	// waitIdle 等待采样循环开始等待下一次采样
+	waitIdle := func() {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for c.Timers() == 0 {
+			if time.Now().After(deadline) {
+				t.Fatal("sampling loop is not waiting on the injected clock")
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+	samples := func() int { return len(m.GetMetrics(900001, 0)) }
+
+	waitIdle()
+	base := samples()
+	time.Sleep(1500 * time.Millisecond) // 真实时间经过一个以上的采样间隔
+	if n := samples(); n != base {
+		t.Fatalf("samples grew from %d to %d without advancing the clock", base, n)
+	}
+
+	for i := 1; i <= 3; i++ {
+		c.Advance(time.Second)
+		deadline := time.Now().Add(5 * time.Second)
+		for samples() < base+i {
+			if time.Now().After(deadline) {
+				t.Fatalf("advance %d: samples = %d, want %d", i, samples(), base+i)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		waitIdle()
+	}
+	if last := m.GetMetrics(900001, 1); len(last) != 1 || !last[0].Timestamp.Equal(c.Now()) {
+		t.Fatalf("latest sample = %+v, want timestamp %v", last, c.Now())
+	}
+}
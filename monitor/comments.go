@@ -0,0 +1,238 @@
+package monitor
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"monitor-agent/logger"
+	"monitor-agent/types"
+)
+
+// 值班备注：交接班时值班人员直接在事件或影响事件上记录处理情况（如"已确认，厂家工单 #4411"），
+// 下一班在同一界面看到。备注按 事件类型+ID 保存，追加写入日志目录下的 comments.jsonl，重启后恢复；
+// 查询事件和影响事件时附带在 comments 字段中。
+
+const (
+	commentsFile = "comments.jsonl"
+
+	// MaxCommentLen 单条备注的最大长度（字符数）
+	MaxCommentLen = 500
+	// MaxCommentsPerItem 每个事件最多保存的备注数
+	MaxCommentsPerItem = 20
+	// commentRetention 备注的保留时间，加载时丢弃更早的备注并重写文件
+	commentRetention = 30 * 24 * time.Hour
+)
+
+// 备注所属的事件类型
+const (
+	CommentEvent  = "event"
+	CommentImpact = "impact"
+)
+
+// commentRecord comments.jsonl 中的一行
+type commentRecord struct {
+	Kind string `json:"kind"` // event 或 impact
+	ID   string `json:"id"`
+	types.Comment
+}
+
+// eventID 生成事件 ID（按序号、发生时间、类型和对象，重启后序号重置也不会重复）
+func eventID(evt types.Event) string {
+	sum := sha1.Sum([]byte(fmt.Sprintf("%d|%d|%s|%d|%s",
+		evt.Seq, evt.Timestamp.UnixNano(), evt.Type, evt.PID, evt.Name)))
+	return hex.EncodeToString(sum[:8])
+}
+
+func commentKey(kind, id string) string {
+	return kind + ":" + id
+}
+
+func (m *MultiMonitor) commentsPath() string {
+	return filepath.Join(m.config.LogDir, commentsFile)
+}
+
+// loadComments 从 comments.jsonl 恢复备注，丢弃超过保留时间的备注（有丢弃时重写文件）
+func (m *MultiMonitor) loadComments() {
+	f, err := os.Open(m.commentsPath())
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			logger.Warnf("MONITOR", "Load comments failed: %v", err)
+		}
+		return
+	}
+	defer f.Close()
+
+	cutoff := m.clock.Now().Add(-commentRetention)
+	var kept []commentRecord
+	dropped := 0
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var rec commentRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil || rec.ID == "" {
+			dropped++
+			continue
+		}
+		if rec.Time.Before(cutoff) {
+			dropped++
+			continue
+		}
+		kept = append(kept, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		logger.Warnf("MONITOR", "Read comments failed: %v", err)
+		return
+	}
+
+	m.commentMu.Lock()
+	defer m.commentMu.Unlock()
+	for _, rec := range kept {
+		key := commentKey(rec.Kind, rec.ID)
+		m.comments[key] = append(m.comments[key], rec.Comment)
+	}
+	if dropped > 0 {
+		m.rewriteCommentsLocked(kept)
+	}
+}
+
+// rewriteCommentsLocked 用保留的备注重写 comments.jsonl（调用方需持有 commentMu）
+func (m *MultiMonitor) rewriteCommentsLocked(records []commentRecord) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, rec := range records {
+		enc.Encode(rec)
+	}
+	tmp := m.commentsPath() + ".tmp"
+	err := os.WriteFile(tmp, buf.Bytes(), 0644)
+	if err == nil {
+		err = os.Rename(tmp, m.commentsPath())
+	}
+	if err != nil {
+		logger.Warnf("MONITOR", "Compact comments failed: %v", err)
+	}
+}
+
+// appendCommentLocked 追加一条备注到 comments.jsonl（调用方需持有 commentMu）
+func (m *MultiMonitor) appendCommentLocked(rec commentRecord) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(m.config.LogDir, 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(m.commentsPath(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// normalizeComment 去除首尾空白，换行和控制字符替换为空格（备注在 CLI 中显示为一行）
+func normalizeComment(text string) (string, error) {
+	text = strings.ToValidUTF8(text, "")
+	text = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return ' '
+		}
+		return r
+	}, text)
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", fmt.Errorf("comment is empty")
+	}
+	if n := utf8.RuneCountInString(text); n > MaxCommentLen {
+		return "", fmt.Errorf("comment too long: %d characters (max %d)", n, MaxCommentLen)
+	}
+	return text, nil
+}
+
+// addComment 为事件添加备注并写入文件，写入失败时不保存
+func (m *MultiMonitor) addComment(kind, id, user, text string) (types.Comment, error) {
+	text, err := normalizeComment(text)
+	if err != nil {
+		return types.Comment{}, err
+	}
+	c := types.Comment{Time: m.clock.Now(), User: user, Text: text}
+
+	m.commentMu.Lock()
+	defer m.commentMu.Unlock()
+	key := commentKey(kind, id)
+	if len(m.comments[key]) >= MaxCommentsPerItem {
+		return types.Comment{}, fmt.Errorf("too many comments on %s %s (max %d)", kind, id, MaxCommentsPerItem)
+	}
+	if err := m.appendCommentLocked(commentRecord{Kind: kind, ID: id, Comment: c}); err != nil {
+		return types.Comment{}, fmt.Errorf("save comment: %w", err)
+	}
+	m.comments[key] = append(m.comments[key], c)
+	return c, nil
+}
+
+// GetEvent 按 ID 获取最近事件中的事件（附带备注）
+func (m *MultiMonitor) GetEvent(id string) (types.Event, bool) {
+	for _, e := range m.eventsBuffer.GetAll() {
+		if e.ID == id {
+			e.Comments = m.commentsFor(CommentEvent, id)
+			return e, true
+		}
+	}
+	return types.Event{}, false
+}
+
+// AddEventComment 为事件添加备注，事件须在最近事件中
+func (m *MultiMonitor) AddEventComment(id, user, text string) (types.Comment, error) {
+	if _, ok := m.GetEvent(id); !ok {
+		return types.Comment{}, fmt.Errorf("event %q not found", id)
+	}
+	return m.addComment(CommentEvent, id, user, text)
+}
+
+// AddImpactComment 为影响事件添加备注，影响须为活跃或最近解除的影响
+func (m *MultiMonitor) AddImpactComment(id, user, text string) (types.Comment, error) {
+	if _, ok := m.GetImpact(id); !ok {
+		return types.Comment{}, fmt.Errorf("impact %q not found", id)
+	}
+	return m.addComment(CommentImpact, id, user, text)
+}
+
+// commentsFor 返回事件的备注副本
+func (m *MultiMonitor) commentsFor(kind, id string) []types.Comment {
+	m.commentMu.Lock()
+	defer m.commentMu.Unlock()
+	list := m.comments[commentKey(kind, id)]
+	if len(list) == 0 {
+		return nil
+	}
+	return append([]types.Comment(nil), list...)
+}
+
+// withEventComments 为事件列表附带备注
+func (m *MultiMonitor) withEventComments(events []types.Event) []types.Event {
+	for i := range events {
+		events[i].Comments = m.commentsFor(CommentEvent, events[i].ID)
+	}
+	return events
+}
+
+// withImpactComments 为影响事件列表附带备注
+func (m *MultiMonitor) withImpactComments(impacts []types.ImpactEvent) []types.ImpactEvent {
+	for i := range impacts {
+		impacts[i].Comments = m.commentsFor(CommentImpact, impacts[i].ID)
+	}
+	return impacts
+}
@@ -0,0 +1,42 @@
+package monitor
+
+import (
+	"sort"
+
+	"monitor-agent/i18n"
+	"monitor-agent/types"
+)
+
+// DuplicateTargetError 添加的目标与已有目标是同一个逻辑目标（进程名和命令行相同），
+// 常见于目标重启后按新 PID 再次添加而旧目标仍在；确需监控多个相同实例时使用 ForceAddTarget
+type DuplicateTargetError struct {
+	PID  int32 // 已有目标的 PID
+	Name string
+}
+
+func (e *DuplicateTargetError) Error() string {
+	return i18n.T("target.duplicate", e.PID)
+}
+
+// sameLogicalTarget 两个目标是否为同一个逻辑目标：进程名相同，且命令行相同（任一方未知时只比较进程名）
+func sameLogicalTarget(a, b types.MonitorTarget) bool {
+	if a.Name != b.Name {
+		return false
+	}
+	return a.Cmdline == "" || b.Cmdline == "" || a.Cmdline == b.Cmdline
+}
+
+// findDuplicateLocked 查找与 target 为同一个逻辑目标的已有目标，有多个时返回 PID 最小的（调用方需持有 mu）
+func (m *MultiMonitor) findDuplicateLocked(target types.MonitorTarget) *DuplicateTargetError {
+	var pids []int32
+	for pid, state := range m.targets {
+		if sameLogicalTarget(state.target, target) {
+			pids = append(pids, pid)
+		}
+	}
+	if len(pids) == 0 {
+		return nil
+	}
+	sort.Slice(pids, func(i, j int) bool { return pids[i] < pids[j] })
+	return &DuplicateTargetError{PID: pids[0], Name: target.Name}
+}
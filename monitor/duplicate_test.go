@@ -0,0 +1,121 @@
+package monitor
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"monitor-agent/provider"
+	"monitor-agent/types"
+)
+
+func newDuplicateMonitor(t *testing.T, procs ...types.ProcessInfo) (*MultiMonitor, *provider.Fake) {
+	t.Helper()
+	prov := provider.NewFake(nil)
+	prov.SetProcesses(procs)
+	m, err := NewMultiMonitor(types.MultiMonitorConfig{SampleInterval: 1, LogDir: t.TempDir()}, prov)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m, prov
+}
+
+func targetPIDs(m *MultiMonitor) map[int32]bool {
+	pids := make(map[int32]bool)
+	for _, t := range m.GetTargets() {
+		pids[t.PID] = true
+	}
+	return pids
+}
+
+// expectDuplicate 断言 err 为指向 existing 的 *DuplicateTargetError
+func expectDuplicate(t *testing.T, err error, existing int32) {
+	t.Helper()
+	var dup *DuplicateTargetError
+	if !errors.As(err, &dup) {
+		t.Fatalf("err = %v, want *DuplicateTargetError", err)
+	}
+	if dup.PID != existing {
+		t.Fatalf("duplicate of PID %d, want %d", dup.PID, existing)
+	}
+}
+
+func TestAddTargetRejectsDuplicateUnlessForced(t *testing.T) {
+	m, _ := newDuplicateMonitor(t,
+		types.ProcessInfo{PID: 100, Name: "nginx", Cmdline: "nginx: worker process"},
+		types.ProcessInfo{PID: 101, Name: "nginx", Cmdline: "nginx: worker process"},
+		types.ProcessInfo{PID: 102, Name: "nginx", Cmdline: "nginx: master process"},
+		types.ProcessInfo{PID: 103, Name: "nginx", Cmdline: "nginx: worker process"},
+	)
+	if err := m.AddTarget(types.MonitorTarget{PID: 100, Name: "nginx"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 同名同命令行：拒绝，错误信息指出已有目标的 PID
+	err := m.AddTarget(types.MonitorTarget{PID: 101, Name: "nginx"})
+	expectDuplicate(t, err, 100)
+	if !strings.Contains(err.Error(), "PID 100") {
+		t.Fatalf("error %q does not name the existing PID", err)
+	}
+
+	// 同名但命令行不同：不同的逻辑目标
+	if err := m.AddTarget(types.MonitorTarget{PID: 102, Name: "nginx"}); err != nil {
+		t.Fatalf("different cmdline rejected: %v", err)
+	}
+
+	// 有意监控多个相同实例
+	if err := m.ForceAddTarget(types.MonitorTarget{PID: 101, Name: "nginx"}); err != nil {
+		t.Fatalf("ForceAddTarget: %v", err)
+	}
+	// 已有多个相同目标时报告 PID 最小的
+	expectDuplicate(t, m.AddTarget(types.MonitorTarget{PID: 103, Name: "nginx"}), 100)
+
+	// 同一 PID 即使强制添加也拒绝
+	if err := m.ForceAddTarget(types.MonitorTarget{PID: 100, Name: "nginx"}); err == nil {
+		t.Fatal("ForceAddTarget of an already monitored PID succeeded")
+	}
+	if got := len(m.GetTargets()); got != 3 {
+		t.Fatalf("targets = %d, want 3", got)
+	}
+}
+
+// 目标重启后出现新 PID：旧目标仍在时按新 PID 再次添加被拒绝；
+// 改为监控新 PID（看门狗重启后的 rebindTarget）后只剩一个目标，再次添加同样被拒绝
+func TestRestartedTargetReattach(t *testing.T) {
+	m, prov := newDuplicateMonitor(t, types.ProcessInfo{PID: 200, Name: "scada", Cmdline: "/opt/scada/bin/scada -c main.conf"})
+	if err := m.AddTarget(types.MonitorTarget{PID: 200, Name: "scada", WatchPorts: []int{8080}}); err != nil {
+		t.Fatal(err)
+	}
+
+	prov.RemoveProcess(200)
+	prov.AddProcess(types.ProcessInfo{PID: 300, Name: "scada", Cmdline: "/opt/scada/bin/scada -c main.conf"})
+	expectDuplicate(t, m.AddTarget(types.MonitorTarget{PID: 300, Name: "scada"}), 200)
+
+	if err := m.rebindTarget(200, 300); err != nil {
+		t.Fatalf("rebindTarget: %v", err)
+	}
+	if pids := targetPIDs(m); len(pids) != 1 || !pids[300] {
+		t.Fatalf("targets after reattach = %v, want only PID 300", pids)
+	}
+	got, _ := m.GetTarget(300)
+	if len(got.WatchPorts) != 1 || got.WatchPorts[0] != 8080 {
+		t.Fatalf("reattached target lost its config: %+v", got)
+	}
+
+	if err := m.AddTarget(types.MonitorTarget{PID: 300, Name: "scada"}); err == nil {
+		t.Fatal("adding the reattached PID again succeeded")
+	}
+	prov.AddProcess(types.ProcessInfo{PID: 301, Name: "scada", Cmdline: "/opt/scada/bin/scada -c main.conf"})
+	expectDuplicate(t, m.AddTarget(types.MonitorTarget{PID: 301, Name: "scada"}), 300)
+
+	// 新 PID 已被另一个（强制添加的）目标占用时不合并
+	if err := m.ForceAddTarget(types.MonitorTarget{PID: 301, Name: "scada"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.rebindTarget(300, 301); err == nil {
+		t.Fatal("rebindTarget onto a monitored PID succeeded")
+	}
+	if pids := targetPIDs(m); len(pids) != 2 || !pids[300] || !pids[301] {
+		t.Fatalf("targets = %v, want PIDs 300 and 301", pids)
+	}
+}
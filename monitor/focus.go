@@ -0,0 +1,145 @@
+package monitor
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"monitor-agent/logger"
+)
+
+// 聚焦采样：排查故障时以更高频率（如 200ms）只采样指定目标，不做全系统和全部进程的枚举，到期后自动恢复。
+// 聚焦期间常规采样循环跳过这些目标，每个目标只有一路采样写入指标缓冲区、日志和可用率统计，不会重复计数。
+
+const (
+	// FocusMinInterval 聚焦采样的最小间隔（CPU% 按两次采样的 CPU 时间差计算，间隔过短误差过大）
+	FocusMinInterval = 100 * time.Millisecond
+	// FocusMaxDuration 聚焦采样的最长持续时间
+	FocusMaxDuration = 30 * time.Minute
+)
+
+// focusSession 一次聚焦采样
+type focusSession struct {
+	pids     map[int32]bool
+	interval time.Duration
+	until    time.Time
+	stop     chan struct{}
+}
+
+// FocusStatus 当前聚焦采样状态
+type FocusStatus struct {
+	PIDs     []int32
+	Interval time.Duration
+	Until    time.Time
+}
+
+// FocusMode 在 duration 内以 interval 间隔只采样指定目标，到期后恢复常规采样。
+// 同一时间只有一个聚焦采样，再次调用会替换正在进行的聚焦；监控未运行时返回错误
+func (m *MultiMonitor) FocusMode(pids []int32, interval time.Duration, duration time.Duration) error {
+	if len(pids) == 0 {
+		return fmt.Errorf("no target to focus")
+	}
+	if interval < FocusMinInterval {
+		return fmt.Errorf("focus interval must be at least %s, got %s", FocusMinInterval, interval)
+	}
+	if duration <= 0 || duration > FocusMaxDuration {
+		return fmt.Errorf("focus duration must be between 0 and %s, got %s", FocusMaxDuration, duration)
+	}
+
+	m.mu.Lock()
+	if !m.running {
+		m.mu.Unlock()
+		return fmt.Errorf("monitoring is not running")
+	}
+	sess := &focusSession{
+		pids:     make(map[int32]bool, len(pids)),
+		interval: interval,
+		until:    time.Now().Add(duration),
+		stop:     make(chan struct{}),
+	}
+	for _, pid := range pids {
+		if _, ok := m.targets[pid]; !ok {
+			m.mu.Unlock()
+			return fmt.Errorf("target PID %d not found", pid)
+		}
+		sess.pids[pid] = true
+	}
+	if m.focus != nil {
+		close(m.focus.stop)
+	}
+	m.focus = sess
+	stopCh := m.stopCh
+	m.runWG.Add(1)
+	m.mu.Unlock()
+
+	go func() {
+		defer m.runWG.Done()
+		m.focusLoop(sess, stopCh)
+	}()
+	logger.Infof("MONITOR", "Focus mode started: PIDs=%v interval=%s duration=%s", pids, interval, duration)
+	return nil
+}
+
+// StopFocus 提前结束聚焦采样，没有进行中的聚焦时返回 false
+func (m *MultiMonitor) StopFocus() bool {
+	m.mu.Lock()
+	sess := m.focus
+	m.focus = nil
+	m.mu.Unlock()
+	if sess == nil {
+		return false
+	}
+	close(sess.stop)
+	logger.Info("MONITOR", "Focus mode stopped")
+	return true
+}
+
+// GetFocus 当前聚焦采样状态，没有进行中的聚焦时返回 false
+func (m *MultiMonitor) GetFocus() (FocusStatus, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.focus == nil {
+		return FocusStatus{}, false
+	}
+	status := FocusStatus{Interval: m.focus.interval, Until: m.focus.until}
+	for pid := range m.focus.pids {
+		status.PIDs = append(status.PIDs, pid)
+	}
+	sort.Slice(status.PIDs, func(i, j int) bool { return status.PIDs[i] < status.PIDs[j] })
+	return status, true
+}
+
+// focusedLocked 目标是否正在聚焦采样（调用方需持有 mu）
+func (m *MultiMonitor) focusedLocked(pid int32) bool {
+	return m.focus != nil && m.focus.pids[pid]
+}
+
+// focusLoop 聚焦采样循环，到期、被替换或监控停止时退出
+func (m *MultiMonitor) focusLoop(sess *focusSession, stopCh chan struct{}) {
+	ticker := time.NewTicker(sess.interval)
+	defer ticker.Stop()
+	timer := time.NewTimer(time.Until(sess.until))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-sess.stop:
+			return
+		case <-stopCh:
+		case <-timer.C:
+			logger.Info("MONITOR", "Focus mode ended")
+		case <-ticker.C:
+			for pid := range sess.pids {
+				m.collectOne(pid, sess.interval)
+			}
+			continue
+		}
+		// 到期或监控停止：恢复常规采样
+		m.mu.Lock()
+		if m.focus == sess {
+			m.focus = nil
+		}
+		m.mu.Unlock()
+		return
+	}
+}
@@ -0,0 +1,56 @@
+package monitor
+
+import (
+	"sort"
+
+	"monitor-agent/types"
+)
+
+// GetTargetGroups 按分组汇总监控目标的状态和指标（运维人员关心的是"SCADA 系统"而不是单个 PID）
+func (m *MultiMonitor) GetTargetGroups() []types.TargetGroup {
+	statuses, _ := m.GetTargetStatuses()
+	return GroupTargets(statuses)
+}
+
+// GroupTargets 按 Group 汇总目标状态：最新一次采样存活的成员计为运行，CPU% 和内存只累加运行中的成员。
+// 分组按名称排序，未分组的目标在最后
+func GroupTargets(statuses []types.TargetStatus) []types.TargetGroup {
+	index := make(map[string]int)
+	var groups []types.TargetGroup
+	for _, s := range statuses {
+		i, ok := index[s.Group]
+		if !ok {
+			i = len(groups)
+			index[s.Group] = i
+			groups = append(groups, types.TargetGroup{Name: s.Group})
+		}
+		g := &groups[i]
+		g.Total++
+		g.ActiveImpacts += s.ActiveImpacts
+		if s.Latest != nil && s.Latest.Alive {
+			g.Running++
+			g.CPUPct += s.Latest.CPUPct
+			g.RSSBytes += s.Latest.RSSBytes
+		}
+		g.Targets = append(g.Targets, s)
+	}
+
+	for i := range groups {
+		g := &groups[i]
+		switch g.Running {
+		case g.Total:
+			g.Status = types.GroupUp
+		case 0:
+			g.Status = types.GroupDown
+		default:
+			g.Status = types.GroupDegraded
+		}
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		if (groups[i].Name == "") != (groups[j].Name == "") {
+			return groups[j].Name == ""
+		}
+		return groups[i].Name < groups[j].Name
+	})
+	return groups
+}
@@ -0,0 +1,181 @@
+package monitor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"monitor-agent/i18n"
+	"monitor-agent/timefmt"
+	"monitor-agent/types"
+)
+
+// Linux 下可执行文件被替换或删除后，/proc/<pid>/exe 指向的路径会带此后缀
+const deletedExeSuffix = " (deleted)"
+
+// binaryLoop 按较慢的周期校验目标可执行文件是否被替换
+func (m *MultiMonitor) binaryLoop(interval time.Duration, stopCh chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			m.verifyBinaries()
+		}
+	}
+}
+
+// verifyBinaries 对所有存活目标执行一次完整性校验
+func (m *MultiMonitor) verifyBinaries() {
+	m.mu.RLock()
+	pids := make([]int32, 0, len(m.targets))
+	for pid := range m.targets {
+		pids = append(pids, pid)
+	}
+	m.mu.RUnlock()
+
+	for _, pid := range pids {
+		m.verifyBinary(pid)
+	}
+}
+
+// verifyBinary 与基线比较，不一致时记录 binary_changed 事件并以当前状态作为新基线
+// 文件暂时无法读取（如 Windows 下被安装程序锁定）时跳过本轮，不视为变化
+func (m *MultiMonitor) verifyBinary(pid int32) {
+	if !m.provider.IsAlive(pid) {
+		return
+	}
+	cur, err := m.readBinaryInfo(pid)
+	if err != nil {
+		return
+	}
+
+	now := m.clock.Now()
+	m.mu.Lock()
+	state, exists := m.targets[pid]
+	if !exists {
+		m.mu.Unlock()
+		return
+	}
+	prev := state.binary
+	state.binaryCheckedAt = now
+	var diffs []string
+	if prev != nil {
+		diffs = binaryDiff(prev, cur)
+	}
+	if prev == nil || len(diffs) > 0 {
+		state.binary = cur
+	}
+	name := state.target.Name
+	m.mu.Unlock()
+
+	if len(diffs) == 0 {
+		return
+	}
+	m.addEvent(types.Event{
+		Timestamp: now,
+		Type:      "binary_changed",
+		PID:       pid,
+		Name:      name,
+		Message:   i18n.T("event.binary_changed", strings.Join(diffs, "; ")),
+	})
+}
+
+// readBinaryInfo 读取进程可执行文件的路径、大小、修改时间和（可选）SHA256
+func (m *MultiMonitor) readBinaryInfo(pid int32) (*types.BinaryInfo, error) {
+	exe, cwd, err := m.provider.GetExecPaths(pid)
+	if err != nil {
+		return nil, err
+	}
+	if exe == "" {
+		return nil, fmt.Errorf("executable path unavailable")
+	}
+
+	info := &types.BinaryInfo{Path: exe, Cwd: cwd}
+	path := strings.TrimSuffix(exe, deletedExeSuffix)
+	fi, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			info.Missing = true
+			return info, nil
+		}
+		return nil, err
+	}
+	info.Size = fi.Size()
+	info.ModTime = fi.ModTime()
+
+	if m.config.BinaryHash {
+		sum, err := hashFile(path)
+		if err != nil {
+			return nil, err
+		}
+		info.SHA256 = sum
+	}
+	return info, nil
+}
+
+// hashFile 计算文件 SHA256
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// binaryDiff 返回两次校验之间的差异描述
+func binaryDiff(prev, cur *types.BinaryInfo) []string {
+	var diffs []string
+	if prev.Path != cur.Path {
+		diffs = append(diffs, i18n.T("binary.path", prev.Path, cur.Path))
+	}
+	if prev.Cwd != "" && cur.Cwd != "" && prev.Cwd != cur.Cwd {
+		diffs = append(diffs, i18n.T("binary.cwd", prev.Cwd, cur.Cwd))
+	}
+	if prev.Missing != cur.Missing {
+		if cur.Missing {
+			diffs = append(diffs, i18n.T("binary.deleted"))
+		} else {
+			diffs = append(diffs, i18n.T("binary.restored"))
+		}
+	}
+	if prev.Missing || cur.Missing {
+		return diffs
+	}
+	if prev.Size != cur.Size {
+		diffs = append(diffs, i18n.T("binary.size", prev.Size, cur.Size))
+	}
+	if !prev.ModTime.Equal(cur.ModTime) {
+		diffs = append(diffs, i18n.T("binary.mtime",
+			timefmt.Format(prev.ModTime, "2006-01-02 15:04:05"), timefmt.Format(cur.ModTime, "2006-01-02 15:04:05")))
+	}
+	if prev.SHA256 != "" && cur.SHA256 != "" && prev.SHA256 != cur.SHA256 {
+		diffs = append(diffs, i18n.T("binary.sha256", prev.SHA256[:12], cur.SHA256[:12]))
+	}
+	return diffs
+}
+
+// GetBinaryInfo 获取目标可执行文件基线和上次校验时间（未校验时为零值）
+func (m *MultiMonitor) GetBinaryInfo(pid int32) (*types.BinaryInfo, time.Time) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	state, ok := m.targets[pid]
+	if !ok || state.binary == nil {
+		return nil, time.Time{}
+	}
+	info := *state.binary
+	return &info, state.binaryCheckedAt
+}
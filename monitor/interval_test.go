@@ -0,0 +1,50 @@
+package monitor
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"monitor-agent/provider"
+	"monitor-agent/types"
+)
+
+// 未运行时并发修改采样间隔：调用都能返回（采样循环不在接收），通道中保留的间隔与最终配置一致
+func TestSetSampleIntervalConcurrent(t *testing.T) {
+	m, err := NewMultiMonitor(types.MultiMonitorConfig{LogDir: t.TempDir()}, provider.NewFake(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runWithTimeout(t, 10*time.Second, func() {
+		var wg sync.WaitGroup
+		for w := 1; w <= 8; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				for i := 0; i < 100; i++ {
+					if err := m.SetSampleInterval(w); err != nil {
+						t.Errorf("SetSampleInterval(%d): %v", w, err)
+					}
+				}
+			}(w)
+		}
+		wg.Wait()
+	})
+
+	m.mu.RLock()
+	want := time.Duration(m.config.SampleInterval) * time.Second
+	m.mu.RUnlock()
+	select {
+	case got := <-m.intervalCh:
+		if got != want {
+			t.Fatalf("pending interval = %v, want %v (config)", got, want)
+		}
+	default:
+		t.Fatal("no pending interval")
+	}
+
+	if err := m.SetSampleInterval(0); err == nil {
+		t.Fatal("SetSampleInterval(0) succeeded, want error")
+	}
+}
@@ -0,0 +1,63 @@
+package monitor
+
+import (
+	"math/rand"
+	"os"
+	"time"
+
+	"monitor-agent/clock"
+	"monitor-agent/logger"
+)
+
+// sampleJitter 采样时刻的随机偏移：全厂多台主机按相同间隔、在整秒边界同时采样时，
+// 会同时访问共享的监控后端和 NAS 上的日志目录。偏移首次（或每轮）采样，把负载分散到整个采样间隔内
+type sampleJitter struct {
+	max      time.Duration
+	eachTick bool
+	rnd      *rand.Rand // 只在采样循环中使用
+}
+
+// newSampleJitter 创建偏移上限为 maxMS 毫秒的随机偏移，maxMS 为 0 时返回 nil（不偏移）。
+// 各主机的随机序列必须不同，因此用当前时间和 PID 作为种子，而不是全局的固定种子
+func newSampleJitter(maxMS int, eachTick bool) *sampleJitter {
+	if maxMS <= 0 {
+		return nil
+	}
+	logger.Infof("MONITOR", "Sample jitter enabled: up to %dms (each tick: %v)", maxMS, eachTick)
+	return &sampleJitter{
+		max:      time.Duration(maxMS) * time.Millisecond,
+		eachTick: eachTick,
+		rnd:      rand.New(rand.NewSource(time.Now().UnixNano() ^ int64(os.Getpid())<<32)),
+	}
+}
+
+// delay 返回 [0, 上限) 内的随机延迟，上限不超过采样间隔
+func (j *sampleJitter) delay(interval time.Duration) time.Duration {
+	if j == nil {
+		return 0
+	}
+	max := j.max
+	if max > interval {
+		max = interval
+	}
+	if max <= 0 {
+		return 0
+	}
+	return time.Duration(j.rnd.Int63n(int64(max)))
+}
+
+// wait 按 clk 等待一次随机延迟，等待期间监控被停止时返回 false
+func (j *sampleJitter) wait(clk clock.Clock, interval time.Duration, stopCh chan struct{}) bool {
+	d := j.delay(interval)
+	if d <= 0 {
+		return true
+	}
+	timer := clk.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C():
+		return true
+	case <-stopCh:
+		return false
+	}
+}
@@ -0,0 +1,94 @@
+package monitor
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"monitor-agent/impact"
+	"monitor-agent/provider"
+	"monitor-agent/types"
+)
+
+// waitGoroutines 等待协程数回落到 base 以内（已退出的协程需要一点时间被调度器回收），超时失败并输出协程栈
+func waitGoroutines(t *testing.T, base int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > base {
+		if time.Now().After(deadline) {
+			buf := make([]byte, 1<<20)
+			t.Fatalf("goroutines = %d, want <= %d\n%s", runtime.NumGoroutine(), base, buf[:runtime.Stack(buf, true)])
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// runWithTimeout 在限定时间内执行 fn，超时视为死锁
+func runWithTimeout(t *testing.T, d time.Duration, fn func()) {
+	t.Helper()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn()
+	}()
+	select {
+	case <-done:
+	case <-time.After(d):
+		buf := make([]byte, 1<<20)
+		t.Fatalf("timed out after %v\n%s", d, buf[:runtime.Stack(buf, true)])
+	}
+}
+
+// 并发反复启停监控器（含影响分析器）：需配合 -race 运行，结束后不遗留协程、不死锁
+func TestMultiMonitorConcurrentStartStop(t *testing.T) {
+	base := runtime.NumGoroutine()
+
+	prov := provider.NewFake(nil)
+	prov.SetProcesses([]types.ProcessInfo{{PID: 900001, Name: "scada", Status: "S"}})
+	m, err := NewMultiMonitor(types.MultiMonitorConfig{SampleInterval: 1, LogDir: t.TempDir()}, prov)
+	if err != nil {
+		t.Fatal(err)
+	}
+	analyzer := impact.NewImpactAnalyzer(types.ImpactConfig{Enabled: true, AnalysisInterval: 1}, prov, m.GetTargets, m.ListAllProcesses)
+	m.SetImpactAnalyzer(analyzer)
+	if err := m.AddTarget(types.MonitorTarget{PID: 900001, Name: "scada"}); err != nil {
+		t.Fatal(err)
+	}
+
+	runWithTimeout(t, 30*time.Second, func() {
+		var wg sync.WaitGroup
+		for w := 0; w < 8; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				for i := 0; i < 50; i++ {
+					if (i+w)%2 == 0 {
+						if err := m.Start(); err != nil && err != ErrAlreadyRunning {
+							t.Errorf("Start: %v", err)
+						}
+					} else {
+						m.Stop()
+					}
+					m.IsRunning()
+				}
+			}(w)
+		}
+		wg.Wait()
+		m.Stop()
+	})
+
+	if m.IsRunning() || analyzer.IsRunning() {
+		t.Fatalf("running after Stop: monitor=%v analyzer=%v", m.IsRunning(), analyzer.IsRunning())
+	}
+	if err := m.Start(); err != nil {
+		t.Fatalf("Start after Stop: %v", err)
+	}
+	if err := m.Start(); err != ErrAlreadyRunning {
+		t.Fatalf("second Start = %v, want ErrAlreadyRunning", err)
+	}
+	// 运行超过一个间隔，Stop 需等待进行中的周期结束
+	time.Sleep(1200 * time.Millisecond)
+	m.Stop()
+	waitGoroutines(t, base)
+}
@@ -0,0 +1,207 @@
+package monitor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"monitor-agent/i18n"
+	"monitor-agent/logger"
+	"monitor-agent/types"
+)
+
+const maintenanceFile = "maintenance.json"
+
+// loadMaintenance 从状态文件恢复维护窗口，重启期间已到期的窗口记录退出事件后丢弃
+func (m *MultiMonitor) loadMaintenance() {
+	data, err := os.ReadFile(m.maintenancePath())
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			logger.Warnf("MONITOR", "Load maintenance state failed: %v", err)
+		}
+		return
+	}
+	var windows []types.MaintenanceWindow
+	if err := json.Unmarshal(data, &windows); err != nil {
+		logger.Warnf("MONITOR", "Parse maintenance state failed: %v", err)
+		return
+	}
+
+	m.maintMu.Lock()
+	for _, w := range windows {
+		m.maintenance[w.PID] = w
+	}
+	m.maintMu.Unlock()
+
+	m.expireMaintenance(m.clock.Now())
+}
+
+func (m *MultiMonitor) maintenancePath() string {
+	return filepath.Join(m.config.LogDir, maintenanceFile)
+}
+
+// saveMaintenanceLocked 写入维护状态文件（调用方需持有 maintMu）
+func (m *MultiMonitor) saveMaintenanceLocked() {
+	windows := make([]types.MaintenanceWindow, 0, len(m.maintenance))
+	for _, w := range m.maintenance {
+		windows = append(windows, w)
+	}
+	data, err := json.Marshal(windows)
+	if err == nil {
+		if err = os.MkdirAll(m.config.LogDir, 0755); err == nil {
+			err = os.WriteFile(m.maintenancePath(), data, 0644)
+		}
+	}
+	if err != nil {
+		logger.Warnf("MONITOR", "Save maintenance state failed: %v", err)
+	}
+}
+
+// StartMaintenance 进入维护模式，pid 为 0 表示全局维护
+// 维护期间影响分析不对目标告警，已有的影响事件标记为已抑制，指标照常采集
+func (m *MultiMonitor) StartMaintenance(pid int32, duration time.Duration, reason string) (types.MaintenanceWindow, error) {
+	if duration <= 0 {
+		return types.MaintenanceWindow{}, fmt.Errorf("duration must be positive")
+	}
+
+	name := i18n.T("maintenance.all_targets")
+	if pid != 0 {
+		m.mu.RLock()
+		state, ok := m.targets[pid]
+		if ok {
+			name = state.target.Name
+		}
+		m.mu.RUnlock()
+		if !ok {
+			return types.MaintenanceWindow{}, fmt.Errorf("target PID %d not found", pid)
+		}
+	}
+
+	now := m.clock.Now()
+	w := types.MaintenanceWindow{
+		PID:    pid,
+		Reason: reason,
+		Start:  now,
+		End:    now.Add(duration),
+	}
+
+	m.maintMu.Lock()
+	m.maintenance[pid] = w
+	m.saveMaintenanceLocked()
+	m.maintMu.Unlock()
+
+	if analyzer := m.GetImpactAnalyzer(); analyzer != nil {
+		analyzer.SuppressTarget(pid)
+	}
+
+	m.addEvent(types.Event{
+		Timestamp: now,
+		Type:      "maintenance_start",
+		PID:       pid,
+		Name:      name,
+		Message:   i18n.T("maintenance.start", duration, reasonOrDefault(reason)),
+	})
+	logger.Infof("MONITOR", "Maintenance started: PID=%d, until=%s", pid, w.End.Format(time.RFC3339))
+
+	w.Remaining = duration.Seconds()
+	return w, nil
+}
+
+// EndMaintenance 手动结束维护模式
+func (m *MultiMonitor) EndMaintenance(pid int32) error {
+	m.maintMu.Lock()
+	w, ok := m.maintenance[pid]
+	if ok {
+		delete(m.maintenance, pid)
+		m.saveMaintenanceLocked()
+	}
+	m.maintMu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("PID %d is not in maintenance", pid)
+	}
+	m.addMaintenanceEndEvent(w, i18n.T("maintenance.manual"))
+	return nil
+}
+
+// expireMaintenance 结束已到期的维护窗口
+func (m *MultiMonitor) expireMaintenance(now time.Time) {
+	var expired []types.MaintenanceWindow
+
+	m.maintMu.Lock()
+	for pid, w := range m.maintenance {
+		if !now.Before(w.End) {
+			expired = append(expired, w)
+			delete(m.maintenance, pid)
+		}
+	}
+	if len(expired) > 0 {
+		m.saveMaintenanceLocked()
+	}
+	m.maintMu.Unlock()
+
+	for _, w := range expired {
+		m.addMaintenanceEndEvent(w, i18n.T("maintenance.expired"))
+	}
+}
+
+func (m *MultiMonitor) addMaintenanceEndEvent(w types.MaintenanceWindow, how string) {
+	name := i18n.T("maintenance.all_targets")
+	if w.PID != 0 {
+		m.mu.RLock()
+		if state, ok := m.targets[w.PID]; ok {
+			name = state.target.Name
+		}
+		m.mu.RUnlock()
+	}
+	m.addEvent(types.Event{
+		Timestamp: m.clock.Now(),
+		Type:      "maintenance_end",
+		PID:       w.PID,
+		Name:      name,
+		Message:   i18n.T("maintenance.end", how, reasonOrDefault(w.Reason)),
+	})
+	logger.Infof("MONITOR", "Maintenance ended (%s): PID=%d", how, w.PID)
+}
+
+// InMaintenance 目标是否处于维护中（包括全局维护）
+func (m *MultiMonitor) InMaintenance(pid int32) bool {
+	now := m.clock.Now()
+	m.maintMu.Lock()
+	defer m.maintMu.Unlock()
+	if w, ok := m.maintenance[0]; ok && now.Before(w.End) {
+		return true
+	}
+	if w, ok := m.maintenance[pid]; ok && now.Before(w.End) {
+		return true
+	}
+	return false
+}
+
+// GetMaintenance 获取当前有效的维护窗口（按 PID 排序，含剩余时间）
+func (m *MultiMonitor) GetMaintenance() []types.MaintenanceWindow {
+	now := m.clock.Now()
+	m.maintMu.Lock()
+	defer m.maintMu.Unlock()
+
+	result := make([]types.MaintenanceWindow, 0, len(m.maintenance))
+	for _, w := range m.maintenance {
+		if now.Before(w.End) {
+			w.Remaining = w.End.Sub(now).Seconds()
+			result = append(result, w)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].PID < result[j].PID })
+	return result
+}
+
+func reasonOrDefault(reason string) string {
+	if reason == "" {
+		return i18n.T("maintenance.no_reason")
+	}
+	return reason
+}
@@ -1,21 +1,33 @@
 package monitor
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"runtime"
 	"sort"
 	"sync"
 	"time"
 
 	"monitor-agent/buffer"
+	"monitor-agent/clock"
+	"monitor-agent/i18n"
 	"monitor-agent/impact"
 	"monitor-agent/logger"
 	"monitor-agent/provider"
+	"monitor-agent/pubsub"
 	"monitor-agent/types"
 )
 
 // TargetChangeCallback 目标变化回调函数类型
 type TargetChangeCallback func(targets []types.MonitorTarget)
 
+// CollectCallback 每轮采样完成后的回调，targets 为各目标及其最新指标（用于推送到外部系统，不应阻塞）
+type CollectCallback func(at time.Time, targets []types.TargetStatus)
+
+// ErrAlreadyRunning 监控已在运行时 Start 返回的错误
+var ErrAlreadyRunning = errors.New("monitoring is already running")
+
 // MultiMonitor 多进程监控器
 type MultiMonitor struct {
 	mu             sync.RWMutex
@@ -26,6 +38,14 @@ type MultiMonitor struct {
 	config         types.MultiMonitorConfig
 	running        bool
 	stopCh         chan struct{}
+	intervalCh     chan time.Duration // 通知采样循环全局采样间隔变化
+	scheduleCh     chan struct{}      // 通知采样循环目标或其采样间隔有变化（见 schedule.go）
+	clock          clock.Clock        // 时间来源，默认系统时钟
+
+	// 启停：lifecycleMu 串行化 Start/Stop；runWG 跟踪本次运行的采样、校验、聚焦和探测协程，
+	// Stop 等待它们退出后才返回，下次 Start 不会与上次运行的协程同时采样
+	lifecycleMu sync.Mutex
+	runWG       sync.WaitGroup
 
 	// 进程变化追踪
 	processTracker *ProcessTracker
@@ -35,12 +55,69 @@ type MultiMonitor struct {
 
 	// 目标变化回调（用于持久化配置）
 	targetChangeCallback TargetChangeCallback
+
+	// 采样完成回调（用于指标推送），受 mu 保护
+	collectCallback CollectCallback
+
+	// 事件去重与限流状态（每分钟窗口），eventMu 同时串行化 addEvent
+	eventMu          sync.Mutex
+	eventWindowStart time.Time
+	eventWindowCount int
+	eventSeq         int64
+	eventNotify      chan struct{} // 有新事件时关闭并重建，用于唤醒长轮询
+
+	// 实时推送（新事件和进程变化）
+	stream *pubsub.Broker[types.StreamMessage]
+
+	// 可用率统计
+	availability *AvailabilityTracker
+
+	// 维护窗口（PID -> 窗口，0 表示全局），持久化到日志目录
+	maintMu     sync.Mutex
+	maintenance map[int32]types.MaintenanceWindow
+
+	// 事件和影响事件的值班备注（见 comments.go），持久化到日志目录
+	commentMu sync.Mutex
+	comments  map[string][]types.Comment
+
+	// 各目标最近一次的启动快照（按名称和别名，移除目标后保留，用于与重新添加的实例对比）
+	lastSnapshots map[string]*types.LaunchSnapshot
+
+	// 目标打开文件查询（按目标保留上次结果用于对比）
+	openFiles *impact.OpenFilesTracker
+
+	// 进行中的聚焦采样（见 focus.go），受 mu 保护
+	focus *focusSession
+
+	// 各目标的自动重启状态（按名称和别名，见 restart.go），受 mu 保护
+	restarts map[string]*restartState
+
+	// 远程探测目标（见 probe.go）
+	probeMu     sync.Mutex
+	probes      map[string]*probeState
+	probeStopCh chan struct{} // 监控运行中时为本次运行的停止通道
 }
 
 type targetState struct {
 	target       types.MonitorTarget
 	lastMetric   *types.ProcessMetrics
 	exitReported bool // 是否已报告退出事件
+
+	// 上次采样的优先级，用于检测优先级变化（如被其他脚本 renice）
+	prioritySeen bool
+	lastPriority int32
+	lastNice     int32
+
+	// 可执行文件基线和上次完整性校验时间
+	binary          *types.BinaryInfo
+	binaryCheckedAt time.Time
+
+	// 期望的可执行文件路径（纳入保障时记录）和最近一次 exec 为其他程序的记录（见 reexec.go）
+	expectedExe string
+	reexec      *types.ExecChange
+
+	// 添加目标时记录的启动快照
+	snapshot *types.LaunchSnapshot
 }
 
 func NewMultiMonitor(cfg types.MultiMonitorConfig, prov provider.ProcProvider) (*MultiMonitor, error) {
@@ -56,6 +133,9 @@ func NewMultiMonitor(cfg types.MultiMonitorConfig, prov provider.ProcProvider) (
 	if cfg.LogDir == "" {
 		cfg.LogDir = "logs"
 	}
+	if cfg.BinaryCheckInterval <= 0 {
+		cfg.BinaryCheckInterval = 600 // 10分钟
+	}
 
 	m := &MultiMonitor{
 		provider:       prov,
@@ -63,18 +143,51 @@ func NewMultiMonitor(cfg types.MultiMonitorConfig, prov provider.ProcProvider) (
 		metricsBuffers: make(map[int32]*buffer.RingBuffer[types.ProcessMetrics]),
 		eventsBuffer:   buffer.NewRingBuffer[types.Event](cfg.EventsBufferLen),
 		config:         cfg,
-		stopCh:         make(chan struct{}),
+		intervalCh:     make(chan time.Duration, 1),
+		scheduleCh:     make(chan struct{}, 1),
+		clock:          clock.Real,
 		processTracker: NewProcessTracker(200), // 保留最近 200 条进程变化
+		eventNotify:    make(chan struct{}),
+		stream:         pubsub.NewBroker[types.StreamMessage](),
+		availability:   NewAvailabilityTracker(cfg.LogDir),
+		maintenance:    make(map[int32]types.MaintenanceWindow),
+		lastSnapshots:  make(map[string]*types.LaunchSnapshot),
+		comments:       make(map[string][]types.Comment),
+		openFiles:      impact.NewOpenFilesTracker(),
+		probes:         make(map[string]*probeState),
+		restarts:       make(map[string]*restartState),
 	}
+	m.loadMaintenance()
+	m.loadComments()
 
 	return m, nil
 }
 
+// SetClock 替换时间来源（测试或回放时使用手动推进的时钟），同时用于采样调度、进程追踪和已设置的影响分析器，
+// 需在 Start 之前调用。探测和聚焦采样的等待仍按真实时间进行
+func (m *MultiMonitor) SetClock(c clock.Clock) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.clock = c
+	m.processTracker.mu.Lock()
+	m.processTracker.clock = c
+	m.processTracker.mu.Unlock()
+	if m.impactAnalyzer != nil {
+		m.impactAnalyzer.SetClock(c)
+	}
+}
+
 // SetImpactAnalyzer 设置影响分析器
 func (m *MultiMonitor) SetImpactAnalyzer(analyzer *impact.ImpactAnalyzer) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.impactAnalyzer = analyzer
+	if analyzer != nil {
+		analyzer.SetMaintenanceChecker(m.InMaintenance)
+		analyzer.SetChurnSource(m.GetProcessChurn)
+		analyzer.SetMetricsSource(m.GetMetrics)
+		analyzer.SetClock(m.clock)
+	}
 }
 
 // GetImpactAnalyzer 获取影响分析器
@@ -91,6 +204,13 @@ func (m *MultiMonitor) SetTargetChangeCallback(cb TargetChangeCallback) {
 	m.targetChangeCallback = cb
 }
 
+// SetCollectCallback 设置每轮采样完成后的回调
+func (m *MultiMonitor) SetCollectCallback(cb CollectCallback) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.collectCallback = cb
+}
+
 // notifyTargetChange 通知目标变化
 func (m *MultiMonitor) notifyTargetChange() {
 	if m.targetChangeCallback == nil {
@@ -104,14 +224,53 @@ func (m *MultiMonitor) notifyTargetChange() {
 	go m.targetChangeCallback(targets)
 }
 
-// AddTarget 添加监控目标
+// AddTarget 添加监控目标，已有进程名和命令行都相同的目标时返回 *DuplicateTargetError
 func (m *MultiMonitor) AddTarget(target types.MonitorTarget) error {
+	return m.addTarget(target, false)
+}
+
+// ForceAddTarget 添加监控目标，允许与已有目标同名同命令行（有意监控多个相同的实例，如多个 nginx worker）
+func (m *MultiMonitor) ForceAddTarget(target types.MonitorTarget) error {
+	return m.addTarget(target, true)
+}
+
+func (m *MultiMonitor) addTarget(target types.MonitorTarget, force bool) error {
+	if err := NormalizeTargetNotes(&target); err != nil {
+		return err
+	}
+	if err := m.normalizeWatches(&target); err != nil {
+		return err
+	}
+	if err := NormalizeTargetRestart(&target); err != nil {
+		return err
+	}
+	if err := ValidateTargetInterval(target); err != nil {
+		return err
+	}
+
+	// 记录可执行文件基线（可能需要计算哈希，不持锁），失败时在首次校验时补记
+	binary, _ := m.readBinaryInfo(target.PID)
+	var binaryCheckedAt time.Time
+	if binary != nil {
+		binaryCheckedAt = m.clock.Now()
+	}
+	snapshot := m.captureSnapshot(target.PID)
+	if target.Cmdline == "" && snapshot != nil {
+		target.Cmdline = snapshot.Cmdline
+	}
+
 	m.mu.Lock()
 
 	if _, exists := m.targets[target.PID]; exists {
 		m.mu.Unlock()
 		return fmt.Errorf("target PID %d already monitored", target.PID)
 	}
+	if !force {
+		if dup := m.findDuplicateLocked(target); dup != nil {
+			m.mu.Unlock()
+			return dup
+		}
+	}
 
 	// 验证进程存在
 	if !m.provider.IsAlive(target.PID) {
@@ -122,16 +281,29 @@ func (m *MultiMonitor) AddTarget(target types.MonitorTarget) error {
 	// 立即获取一次指标
 	var initialMetric *types.ProcessMetrics
 	if met, err := m.provider.GetMetrics(target.PID); err == nil {
-		met.Timestamp = time.Now()
+		met.Timestamp = m.clock.Now()
 		met.Alive = true
 		initialMetric = met
 	}
 
-	state := &targetState{target: target, lastMetric: initialMetric}
+	state := &targetState{
+		target:          target,
+		lastMetric:      initialMetric,
+		binary:          binary,
+		binaryCheckedAt: binaryCheckedAt,
+	}
+	if binary != nil {
+		state.expectedExe = normalizeExe(binary.Path)
+	}
 	m.targets[target.PID] = state
+	m.forgetRestartLocked(target)
+	if snapshot != nil {
+		m.recordSnapshotLocked(state, snapshot)
+	}
 
-	buf := buffer.NewRingBuffer[types.ProcessMetrics](m.config.MetricsBufferLen)
+	buf := m.newMetricsBufferLocked(target)
 	if initialMetric != nil {
+		initialMetric.IntervalMS = m.targetIntervalLocked(target).Milliseconds()
 		buf.Push(*initialMetric)
 	}
 	m.metricsBuffers[target.PID] = buf
@@ -139,14 +311,35 @@ func (m *MultiMonitor) AddTarget(target types.MonitorTarget) error {
 	logger.Infof("MONITOR", "Added monitor target: PID=%d Name=%s", target.PID, target.Name)
 	m.notifyTargetChange()
 	m.mu.Unlock()
+	m.wakeScheduler()
+
+	if snapshot != nil {
+		logSnapshot(snapshot)
+	}
+	return nil
+}
+
+// normalizeWatches 规范化目标的监控端口和文件，保存的是规范化后的值；警告只记录日志
+func (m *MultiMonitor) normalizeWatches(target *types.MonitorTarget) error {
+	warnings, err := NormalizeTargetWatches(target)
+	if err != nil {
+		return err
+	}
+	for _, w := range warnings {
+		logger.Warnf("MONITOR", "Target PID=%d: %s", target.PID, w)
+	}
 	return nil
 }
 
 // RemoveTarget 移除监控目标
 func (m *MultiMonitor) RemoveTarget(pid int32) {
 	m.mu.Lock()
+	if state, ok := m.targets[pid]; ok {
+		m.forgetRestartLocked(state.target)
+	}
 	delete(m.targets, pid)
 	delete(m.metricsBuffers, pid)
+	m.openFiles.Forget(pid)
 
 	// 清理该目标的影响事件
 	if m.impactAnalyzer != nil {
@@ -162,7 +355,9 @@ func (m *MultiMonitor) RemoveTarget(pid int32) {
 func (m *MultiMonitor) RemoveAllTargets() {
 	m.mu.Lock()
 	m.targets = make(map[int32]*targetState)
+	m.restarts = make(map[string]*restartState)
 	m.metricsBuffers = make(map[int32]*buffer.RingBuffer[types.ProcessMetrics])
+	m.openFiles = impact.NewOpenFilesTracker()
 
 	// 清理所有影响事件
 	if m.impactAnalyzer != nil {
@@ -176,6 +371,19 @@ func (m *MultiMonitor) RemoveAllTargets() {
 
 // UpdateTarget 更新监控目标配置
 func (m *MultiMonitor) UpdateTarget(target types.MonitorTarget) error {
+	if err := NormalizeTargetNotes(&target); err != nil {
+		return err
+	}
+	if err := m.normalizeWatches(&target); err != nil {
+		return err
+	}
+	if err := NormalizeTargetRestart(&target); err != nil {
+		return err
+	}
+	if err := ValidateTargetInterval(target); err != nil {
+		return err
+	}
+
 	m.mu.Lock()
 
 	state, exists := m.targets[target.PID]
@@ -184,13 +392,34 @@ func (m *MultiMonitor) UpdateTarget(target types.MonitorTarget) error {
 		return fmt.Errorf("target PID %d not found", target.PID)
 	}
 
+	if restartChanged(state.target, target) {
+		m.resetRestartLocked(state.target)
+	}
+	intervalChanged := state.target.SampleInterval != target.SampleInterval
 	state.target = target
+	if intervalChanged {
+		m.resizeMetricsBufferLocked(target.PID)
+	}
 	logger.Infof("MONITOR", "Updated monitor target: PID=%d Name=%s", target.PID, target.Name)
 	m.notifyTargetChange()
 	m.mu.Unlock()
+	if intervalChanged {
+		m.wakeScheduler()
+	}
 	return nil
 }
 
+// GetTarget 获取指定 PID 的监控目标
+func (m *MultiMonitor) GetTarget(pid int32) (types.MonitorTarget, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	state, ok := m.targets[pid]
+	if !ok {
+		return types.MonitorTarget{}, false
+	}
+	return state.target, true
+}
+
 // GetTargets 获取所有监控目标（按 PID 排序）
 func (m *MultiMonitor) GetTargets() []types.MonitorTarget {
 	m.mu.RLock()
@@ -211,71 +440,220 @@ func (m *MultiMonitor) GetTargets() []types.MonitorTarget {
 	return result
 }
 
-// Start 启动监控
-func (m *MultiMonitor) Start() {
+// GetTargetStatuses 一次性获取所有监控目标、最新指标、活跃影响数和运行状态
+func (m *MultiMonitor) GetTargetStatuses() ([]types.TargetStatus, bool) {
+	m.mu.RLock()
+	pids := make([]int32, 0, len(m.targets))
+	for pid := range m.targets {
+		pids = append(pids, pid)
+	}
+	sort.Slice(pids, func(i, j int) bool { return pids[i] < pids[j] })
+
+	result := make([]types.TargetStatus, 0, len(pids))
+	for _, pid := range pids {
+		state := m.targets[pid]
+		status := types.TargetStatus{MonitorTarget: state.target}
+		if state.lastMetric != nil {
+			latest := *state.lastMetric
+			status.Latest = &latest
+		}
+		if state.binary != nil {
+			binary := *state.binary
+			checkedAt := state.binaryCheckedAt
+			status.Binary = &binary
+			status.BinaryCheckedAt = &checkedAt
+		}
+		if state.reexec != nil {
+			reexec := *state.reexec
+			status.Reexec = &reexec
+		}
+		result = append(result, status)
+	}
+	running := m.running
+	analyzer := m.impactAnalyzer
+	m.mu.RUnlock()
+
+	if analyzer != nil {
+		counts := analyzer.ActiveImpactCountByTarget()
+		for i := range result {
+			result[i].ActiveImpacts = counts[result[i].PID]
+			if len(result[i].WatchDirs) > 0 {
+				result[i].Dirs = analyzer.GetDirUsage(result[i].PID)
+			}
+		}
+	}
+	return result, running
+}
+
+// Start 启动监控，已在运行时返回 ErrAlreadyRunning
+func (m *MultiMonitor) Start() error {
+	m.lifecycleMu.Lock()
+	defer m.lifecycleMu.Unlock()
+
 	m.mu.Lock()
 	if m.running {
 		m.mu.Unlock()
-		return
+		return ErrAlreadyRunning
 	}
 	m.running = true
+	m.stopCh = make(chan struct{}) // 每次运行使用新的停止通道
+	interval := time.Duration(m.config.SampleInterval) * time.Second
+	binaryInterval := time.Duration(m.config.BinaryCheckInterval) * time.Second
+	jitter := newSampleJitter(m.config.SampleJitterMS, m.config.SampleJitterEachTick)
+	stopCh := m.stopCh
+	clk := m.clock
+	m.runWG.Add(2)
 	m.mu.Unlock()
 
-	go m.loop()
+	go func() {
+		defer m.runWG.Done()
+		m.loop(clk, interval, jitter, stopCh)
+	}()
+	go func() {
+		defer m.runWG.Done()
+		m.binaryLoop(binaryInterval, stopCh)
+	}()
+	m.startProbes(stopCh)
 	logger.Info("MONITOR", "MultiMonitor started")
 
-	// 启动影响分析器
+	// 启动影响分析器（已单独启动时沿用）
 	if m.impactAnalyzer != nil {
 		m.impactAnalyzer.Start()
 	}
+	return nil
 }
 
-// Stop 停止监控
+// Stop 停止监控，等待本次运行的协程退出后返回；未运行时直接返回
 func (m *MultiMonitor) Stop() {
+	m.lifecycleMu.Lock()
+	defer m.lifecycleMu.Unlock()
+
 	// 停止影响分析器
 	if m.impactAnalyzer != nil {
 		m.impactAnalyzer.Stop()
 	}
 
 	m.mu.Lock()
-	defer m.mu.Unlock()
 	if !m.running {
+		m.mu.Unlock()
 		return
 	}
 	m.running = false
 	close(m.stopCh)
-	m.stopCh = make(chan struct{}) // 重新创建 channel 以便下次启动
+	m.mu.Unlock()
+	m.stopProbes()
+
+	// 协程可能正在采样（需要 mu），等待时不能持有 mu
+	m.runWG.Wait()
+
+	if err := m.availability.Save(); err != nil {
+		logger.Warnf("MONITOR", "Save availability state failed: %v", err)
+	}
 	logger.Info("MONITOR", "MultiMonitor stopped")
 }
 
-func (m *MultiMonitor) loop() {
-	ticker := time.NewTicker(time.Duration(m.config.SampleInterval) * time.Second)
-	defer ticker.Stop()
+// loop 采样循环：按各目标的采样间隔调度（见 schedule.go），interval 为全局采样间隔。
+// 调度的当前时刻和等待都取自 clk（监控器的时间来源），替换为 clock.Fake 时推进时钟即触发采样
+func (m *MultiMonitor) loop(clk clock.Clock, interval time.Duration, jitter *sampleJitter, stopCh chan struct{}) {
+	// 首次采样前随机偏移，使同时启动的多台主机错开采样时刻
+	if !jitter.wait(clk, interval, stopCh) {
+		return
+	}
+	sched := newSampleSchedule(clk.Now())
 
 	for {
+		now := clk.Now()
+		m.syncSchedule(sched, interval, now)
+		timer := clk.NewTimer(sched.next().Sub(now))
 		select {
-		case <-m.stopCh:
+		case <-stopCh:
+			timer.Stop()
 			return
-		case <-ticker.C:
-			m.collectAll()
+		case d := <-m.intervalCh:
+			// 全局间隔变化：以当前时刻为基准重新排列所有目标
+			timer.Stop()
+			interval = d
+			sched = newSampleSchedule(clk.Now())
+		case <-m.scheduleCh:
+			timer.Stop()
+		case <-timer.C():
+			if jitter != nil && jitter.eachTick && !jitter.wait(clk, interval, stopCh) {
+				return
+			}
+			now := clk.Now()
+			due := sched.popDue(now)
+			m.expireMaintenance(now)
+			m.collectDue(due)
 		}
 	}
 }
 
-func (m *MultiMonitor) collectAll() {
+// SetSampleInterval 运行时修改采样间隔（秒），最小 1 秒，立即对采样循环生效
+func (m *MultiMonitor) SetSampleInterval(seconds int) error {
+	if seconds < 1 {
+		return fmt.Errorf("sample interval must be at least 1s, got %d", seconds)
+	}
+
 	m.mu.Lock()
-	pids := make([]int32, 0, len(m.targets))
-	for pid := range m.targets {
-		pids = append(pids, pid)
+	m.config.SampleInterval = seconds
+	// 设置了采样间隔的目标与全局间隔的比例变化，按新比例调整缓冲区
+	for pid, state := range m.targets {
+		if state.target.SampleInterval > 0 {
+			m.resizeMetricsBufferLocked(pid)
+		}
+	}
+	// 只保留最新的间隔，未运行时下次 Start 会读取新配置。
+	// 清空和发送都在 mu 内进行：并发调用时不会在清空后都去发送而阻塞，通道中的间隔也与配置一致
+	select {
+	case <-m.intervalCh:
+	default:
 	}
+	m.intervalCh <- time.Duration(seconds) * time.Second
 	m.mu.Unlock()
 
-	for _, pid := range pids {
-		m.collectOne(pid)
+	logger.Infof("MONITOR", "Sample interval changed to %ds", seconds)
+	return nil
+}
+
+// collectDue 采样本轮到期的目标，due 中的全局节拍不采样，只触发采样完成回调
+func (m *MultiMonitor) collectDue(due []dueTarget) {
+	m.mu.Lock()
+	targets := make([]dueTarget, 0, len(due))
+	for _, d := range due {
+		// 聚焦采样中的目标由聚焦循环采样
+		if d.pid == baseTickPID || m.focusedLocked(d.pid) {
+			continue
+		}
+		targets = append(targets, d)
+	}
+	m.mu.Unlock()
+
+	for _, d := range targets {
+		m.collectOne(d.pid, d.interval)
+	}
+
+	m.mu.RLock()
+	cb := m.collectCallback
+	var statuses []types.TargetStatus
+	if cb != nil {
+		statuses = make([]types.TargetStatus, 0, len(m.targets))
+		for _, state := range m.targets {
+			status := types.TargetStatus{MonitorTarget: state.target}
+			if state.lastMetric != nil {
+				latest := *state.lastMetric
+				status.Latest = &latest
+			}
+			statuses = append(statuses, status)
+		}
+	}
+	m.mu.RUnlock()
+	if cb != nil {
+		cb(m.clock.Now(), statuses)
 	}
 }
 
-func (m *MultiMonitor) collectOne(pid int32) {
+// collectOne 采样一个目标，interval 为本路采样的间隔（用于可用率统计）
+func (m *MultiMonitor) collectOne(pid int32, interval time.Duration) {
 	m.mu.Lock()
 	state, exists := m.targets[pid]
 	if !exists {
@@ -288,21 +666,45 @@ func (m *MultiMonitor) collectOne(pid int32) {
 
 	alive := m.provider.IsAlive(pid)
 	metric := types.ProcessMetrics{
-		Timestamp: time.Now(),
+		Timestamp: m.clock.Now(),
 		PID:       pid,
 		Alive:     alive,
 	}
+	m.availability.Record(pid, target.Name, alive, metric.Timestamp, interval)
 
+	var priorityChanged bool
+	var oldPriority, oldNice int32
 	if alive {
-		if met, err := m.provider.GetMetrics(pid); err == nil {
+		met, err := m.provider.GetMetrics(pid)
+		if err == nil {
 			metric = *met
-			metric.Timestamp = time.Now()
+			metric.Timestamp = m.clock.Now()
 			metric.Alive = true
 		}
 		// 进程恢复运行，重置退出标记
 		m.mu.Lock()
 		state.exitReported = false
+		if err == nil {
+			oldPriority, oldNice = state.lastPriority, state.lastNice
+			priorityChanged = state.prioritySeen && (oldPriority != metric.Priority || oldNice != metric.Nice)
+			state.prioritySeen = true
+			state.lastPriority, state.lastNice = metric.Priority, metric.Nice
+		}
 		m.mu.Unlock()
+		m.checkReexec(pid)
+	}
+
+	metric.IntervalMS = interval.Milliseconds()
+
+	if priorityChanged {
+		m.addEvent(types.Event{
+			Timestamp: metric.Timestamp,
+			Type:      "priority_changed",
+			PID:       pid,
+			Name:      target.Name,
+			Message: i18n.T("event.priority_changed",
+				formatPriority(oldPriority, oldNice), formatPriority(metric.Priority, metric.Nice)),
+		})
 	}
 
 	buf.Push(metric)
@@ -321,25 +723,190 @@ func (m *MultiMonitor) collectOne(pid int32) {
 		m.mu.Unlock()
 
 		evt := types.Event{
-			Timestamp: time.Now(),
+			Timestamp: m.clock.Now(),
 			Type:      "exit",
 			PID:       pid,
 			Name:      target.Name,
-			Message:   "进程已退出",
+			Message:   i18n.T("event.exit"),
 		}
 		m.addEvent(evt)
 	}
+	if !alive {
+		m.maybeRestart(pid, target)
+	}
+}
+
+// formatPriority 格式化优先级，Linux 附带 nice 值
+func formatPriority(priority, nice int32) string {
+	if runtime.GOOS == "windows" {
+		return fmt.Sprintf("%d", priority)
+	}
+	return fmt.Sprintf("%d (nice %d)", priority, nice)
 }
 
+// addEvent 记录事件
+// 去重窗口内相同 (type, pid, name, message) 的事件合并为一条并累加次数；
+// 每分钟新增事件超过上限时，后续事件合并到一条 "event_storm" 事件中，不再单独记录
 func (m *MultiMonitor) addEvent(evt types.Event) {
+	if evt.Count == 0 {
+		evt.Count = 1
+	}
+	evt.LastSeen = evt.Timestamp
+
+	m.eventMu.Lock()
+	defer m.eventMu.Unlock()
+	defer m.notifyEventLocked()
+
+	if m.mergeDuplicateEvent(evt) {
+		return
+	}
+
+	if !m.allowEvent(evt.Timestamp) {
+		m.addStormEvent(evt.Timestamp)
+		return
+	}
+
+	evt.Seq = m.nextEventSeqLocked()
+	evt.ID = eventID(evt)
+	m.eventsBuffer.Push(evt)
+	logger.Event(evt.Type, evt.PID, evt.Name, evt.Message)
+	m.stream.Publish(types.StreamMessage{Type: "event", Data: evt})
+}
+
+// Subscribe 订阅新事件和进程变化的实时推送
+// 返回的通道关闭表示订阅结束（消费过慢会被移除），使用完毕需调用取消函数
+func (m *MultiMonitor) Subscribe(bufSize int) (<-chan types.StreamMessage, func()) {
+	return m.stream.Subscribe(bufSize)
+}
+
+// nextEventSeqLocked 分配下一个事件序号（调用方需持有 eventMu）
+func (m *MultiMonitor) nextEventSeqLocked() int64 {
+	m.eventSeq++
+	return m.eventSeq
+}
+
+// notifyEventLocked 唤醒等待新事件的长轮询（调用方需持有 eventMu）
+func (m *MultiMonitor) notifyEventLocked() {
+	close(m.eventNotify)
+	m.eventNotify = make(chan struct{})
+}
+
+// WaitEventsSince 等待序号大于 since 的事件，最多等待 timeout
+// 返回匹配的事件（按序号升序）和当前最新序号；超时或 ctx 取消时返回空列表
+func (m *MultiMonitor) WaitEventsSince(ctx context.Context, since int64, timeout time.Duration) ([]types.Event, int64) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		m.eventMu.Lock()
+		// 序号超前（如服务重启后序号重置）时从头返回
+		if since > m.eventSeq {
+			since = 0
+		}
+		events := m.eventsSinceLocked(since)
+		latest := m.eventSeq
+		notify := m.eventNotify
+		m.eventMu.Unlock()
+
+		if len(events) > 0 {
+			return m.withEventComments(events), latest
+		}
+
+		select {
+		case <-notify:
+		case <-timer.C:
+			return []types.Event{}, latest
+		case <-ctx.Done():
+			return []types.Event{}, latest
+		}
+	}
+}
+
+// eventsSinceLocked 获取序号大于 since 的事件（调用方需持有 eventMu）
+func (m *MultiMonitor) eventsSinceLocked(since int64) []types.Event {
+	var result []types.Event
+	for _, e := range m.eventsBuffer.GetAll() {
+		if e.Seq > since {
+			result = append(result, e)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Seq < result[j].Seq
+	})
+	return result
+}
+
+// mergeDuplicateEvent 将事件合并到去重窗口内的相同事件，返回是否已合并
+func (m *MultiMonitor) mergeDuplicateEvent(evt types.Event) bool {
+	window := time.Duration(m.config.EventDedupWindow) * time.Second
+	if window <= 0 {
+		return false
+	}
+	return m.eventsBuffer.UpdateRecent(func(e *types.Event) bool {
+		if e.Type != evt.Type || e.PID != evt.PID || e.Name != evt.Name || e.Message != evt.Message {
+			return false
+		}
+		if evt.Timestamp.Sub(e.LastSeen) > window {
+			return false
+		}
+		e.Count += evt.Count
+		e.LastSeen = evt.Timestamp
+		e.Seq = m.nextEventSeqLocked()
+		return true
+	})
+}
+
+// allowEvent 检查当前分钟窗口内是否还允许新增事件（调用方需持有 eventMu）
+func (m *MultiMonitor) allowEvent(now time.Time) bool {
+	if m.config.EventRateLimit <= 0 {
+		return true
+	}
+
+	if now.Sub(m.eventWindowStart) >= time.Minute {
+		m.eventWindowStart = now
+		m.eventWindowCount = 0
+	}
+	if m.eventWindowCount >= m.config.EventRateLimit {
+		return false
+	}
+	m.eventWindowCount++
+	return true
+}
+
+// addStormEvent 记录被抑制的事件，同一窗口内只保留一条事件风暴记录（调用方需持有 eventMu）
+func (m *MultiMonitor) addStormEvent(now time.Time) {
+	merged := m.eventsBuffer.UpdateRecent(func(e *types.Event) bool {
+		if e.Type != "event_storm" || e.Timestamp.Before(m.eventWindowStart) {
+			return false
+		}
+		e.Count++
+		e.LastSeen = now
+		e.Seq = m.nextEventSeqLocked()
+		return true
+	})
+	if merged {
+		return
+	}
+
+	evt := types.Event{
+		Seq:       m.nextEventSeqLocked(),
+		Timestamp: now,
+		Type:      "event_storm",
+		Name:      "monitor",
+		Message:   i18n.T("event.storm", m.config.EventRateLimit),
+		Count:     1,
+		LastSeen:  now,
+	}
+	evt.ID = eventID(evt)
 	m.eventsBuffer.Push(evt)
 	logger.Event(evt.Type, evt.PID, evt.Name, evt.Message)
+	m.stream.Publish(types.StreamMessage{Type: "event", Data: evt})
 }
 
 // AddImpactEvent 添加影响事件到事件日志
 func (m *MultiMonitor) AddImpactEvent(eventType string, pid int32, name string, message string) {
 	evt := types.Event{
-		Timestamp: time.Now(),
+		Timestamp: m.clock.Now(),
 		Type:      eventType,
 		PID:       pid,
 		Name:      name,
@@ -348,7 +915,7 @@ func (m *MultiMonitor) AddImpactEvent(eventType string, pid int32, name string,
 	m.addEvent(evt)
 }
 
-// GetMetrics 获取指定进程的最近指标
+// GetMetrics 获取指定进程的最近 n 条指标，n <= 0 时返回缓冲区中的全部指标
 func (m *MultiMonitor) GetMetrics(pid int32, n int) []types.ProcessMetrics {
 	m.mu.RLock()
 	buf, exists := m.metricsBuffers[pid]
@@ -356,6 +923,9 @@ func (m *MultiMonitor) GetMetrics(pid int32, n int) []types.ProcessMetrics {
 	if !exists {
 		return nil
 	}
+	if n <= 0 {
+		return buf.GetAll()
+	}
 	return buf.GetRecent(n)
 }
 
@@ -374,7 +944,7 @@ func (m *MultiMonitor) GetAllLatestMetrics() map[int32]*types.ProcessMetrics {
 
 // GetRecentEvents 获取最近事件
 func (m *MultiMonitor) GetRecentEvents(n int) []types.Event {
-	return m.eventsBuffer.GetRecent(n)
+	return m.withEventComments(m.eventsBuffer.GetRecent(n))
 }
 
 // IsRunning 检查是否运行中
@@ -396,11 +966,13 @@ func (m *MultiMonitor) ListAllProcesses() ([]types.ProcessInfo, error) {
 
 	// 将进程变化转换为事件
 	for _, change := range changes {
+		m.stream.Publish(types.StreamMessage{Type: "process_change", Data: change})
+
 		eventType := "new_process"
-		message := "新进程启动"
+		message := i18n.T("event.new_process")
 		if change.Type == "gone" {
 			eventType = "process_gone"
-			message = "进程消失"
+			message = i18n.T("event.process_gone")
 		}
 		evt := types.Event{
 			Timestamp: change.Timestamp,
@@ -420,30 +992,167 @@ func (m *MultiMonitor) GetProcessChanges(n int) []types.ProcessChange {
 	return m.processTracker.GetRecentChanges(n)
 }
 
-// GetSystemMetrics 获取系统指标
+// GetCPUStyle 获取当前进程 CPU 口径
+func (m *MultiMonitor) GetCPUStyle() string {
+	return m.provider.GetCPUStyle()
+}
+
+// SetCPUStyle 修改进程 CPU 口径（重置 CPU 采样）
+func (m *MultiMonitor) SetCPUStyle(style string) error {
+	if err := m.provider.SetCPUStyle(style); err != nil {
+		return err
+	}
+	logger.Infof("MONITOR", "CPU style changed to %s", style)
+	return nil
+}
+
+// SetNameAliases 替换进程名到显示名称的映射，之后采集的进程列表立即使用
+func (m *MultiMonitor) SetNameAliases(aliases map[string]string) {
+	m.provider.SetNameAliases(aliases)
+}
+
+// GetMemoryBreakdown 获取监控目标的内存构成明细，用于排查内存增长类风险
+func (m *MultiMonitor) GetMemoryBreakdown(pid int32) (*types.MemoryBreakdown, error) {
+	m.mu.RLock()
+	state, ok := m.targets[pid]
+	name := ""
+	if ok {
+		name = state.target.Name
+	}
+	m.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("target PID %d not found", pid)
+	}
+
+	mb, err := m.provider.GetMemoryBreakdown(pid)
+	if err != nil {
+		return nil, err
+	}
+	mb.Name = name
+	return mb, nil
+}
+
+// GetOpenFiles 获取监控目标当前打开的文件、套接字和管道，以及与上次查询的差异，用于排查句柄泄漏
+func (m *MultiMonitor) GetOpenFiles(pid int32) (*types.OpenFilesReport, error) {
+	m.mu.RLock()
+	state, ok := m.targets[pid]
+	name := ""
+	if ok {
+		name = state.target.Name
+	}
+	tracker := m.openFiles
+	m.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("target PID %d not found", pid)
+	}
+	return tracker.Report(pid, name)
+}
+
+// GetProcessHistory 按需采样任意进程（不要求是监控目标）最近 seconds 秒的指标，不写入监控缓冲区
+func (m *MultiMonitor) GetProcessHistory(ctx context.Context, pid int32, seconds int) ([]types.ProcessMetrics, error) {
+	return m.provider.GetProcessHistory(ctx, pid, seconds)
+}
+
+// GetSystemMetrics 获取系统指标（附带进程启停频率）
 func (m *MultiMonitor) GetSystemMetrics() (*types.SystemMetrics, error) {
-	return m.provider.GetSystemMetrics()
+	metrics, err := m.provider.GetSystemMetrics()
+	if err != nil {
+		return nil, err
+	}
+	churn := m.GetProcessChurn()
+	metrics.ProcessChurnRate = churn.PerMinute
+	metrics.ProcessChurnTop = churn.TopName
+	return metrics, nil
+}
+
+// GetProcessChurn 获取最近一分钟的进程启停统计
+func (m *MultiMonitor) GetProcessChurn() types.ProcessChurn {
+	return m.processTracker.Churn(m.clock.Now())
 }
 
-// GetRecentImpacts 获取最近的影响事件
-func (m *MultiMonitor) GetRecentImpacts(n int) []types.ImpactEvent {
+// GetRecentImpacts 获取最近的影响事件，grouped 为 true 时合并同一目标、同一类型的多个影响源
+func (m *MultiMonitor) GetRecentImpacts(n int, grouped bool) []types.ImpactEvent {
 	if m.impactAnalyzer == nil {
 		return []types.ImpactEvent{}
 	}
-	return m.impactAnalyzer.GetRecentImpacts(n)
+	return m.withImpactComments(m.impactAnalyzer.GetRecentImpacts(n, grouped))
+}
+
+// GetRecentImpactsMin 获取严重级别不低于 minSeverity 的影响事件（为空表示全部）
+func (m *MultiMonitor) GetRecentImpactsMin(n int, grouped bool, minSeverity string) []types.ImpactEvent {
+	if m.impactAnalyzer == nil {
+		return []types.ImpactEvent{}
+	}
+	return m.withImpactComments(m.impactAnalyzer.GetRecentImpactsMin(n, grouped, minSeverity))
+}
+
+// GetImpact 按 ID 获取影响事件（含检测时的指标历史）
+func (m *MultiMonitor) GetImpact(id string) (types.ImpactEvent, bool) {
+	if m.impactAnalyzer == nil {
+		return types.ImpactEvent{}, false
+	}
+	imp, ok := m.impactAnalyzer.GetImpact(id)
+	if ok {
+		imp.Comments = m.commentsFor(CommentImpact, imp.ID)
+	}
+	return imp, ok
+}
+
+// GetImpactOffenders 获取最近 7 天影响目标最多的 n 个进程
+func (m *MultiMonitor) GetImpactOffenders(n int) []types.ImpactOffender {
+	if m.impactAnalyzer == nil {
+		return []types.ImpactOffender{}
+	}
+	return m.impactAnalyzer.GetOffenders(n)
+}
+
+// GetImpactDiagnostics 获取影响分析器的运行诊断（未启用影响分析时返回空诊断）
+func (m *MultiMonitor) GetImpactDiagnostics() types.ImpactDiagnostics {
+	if m.impactAnalyzer == nil {
+		return types.ImpactDiagnostics{
+			Targets: []types.ImpactTargetDiagnostics{},
+			Errors:  map[string]types.ImpactCheckError{},
+		}
+	}
+	return m.impactAnalyzer.Diagnostics()
+}
+
+// SubscribeImpacts 订阅新影响事件的实时推送（未启用影响分析时返回 nil 通道）
+func (m *MultiMonitor) SubscribeImpacts(bufSize int) (<-chan types.ImpactEvent, func()) {
+	if m.impactAnalyzer == nil {
+		return nil, func() {}
+	}
+	return m.impactAnalyzer.SubscribeImpacts(bufSize)
+}
+
+// SubscribeResolvedImpacts 订阅影响解除的实时推送（未启用影响分析时返回 nil 通道）
+func (m *MultiMonitor) SubscribeResolvedImpacts(bufSize int) (<-chan types.ImpactEvent, func()) {
+	if m.impactAnalyzer == nil {
+		return nil, func() {}
+	}
+	return m.impactAnalyzer.SubscribeResolvedImpacts(bufSize)
+}
+
+// GetImpactSummary 获取影响统计摘要，grouped 为 true 时按合并后的条目统计
+func (m *MultiMonitor) GetImpactSummary(grouped bool) map[string]interface{} {
+	if m.impactAnalyzer == nil {
+		return map[string]interface{}{"total": 0, "score": 100, "grade": impact.HealthGrade(100)}
+	}
+	return m.impactAnalyzer.GetImpactSummary(grouped)
 }
 
-// GetImpactSummary 获取影响统计摘要
-func (m *MultiMonitor) GetImpactSummary() map[string]interface{} {
+// GetHealthScore 获取健康评分和等级
+func (m *MultiMonitor) GetHealthScore() (int, string) {
 	if m.impactAnalyzer == nil {
-		return map[string]interface{}{"total": 0}
+		return 100, impact.HealthGrade(100)
 	}
-	return m.impactAnalyzer.GetImpactSummary()
+	score := m.impactAnalyzer.HealthScore()
+	return score, impact.HealthGrade(score)
 }
 
 // GetEvents 获取所有事件 (CLI使用)
 func (m *MultiMonitor) GetEvents() []types.Event {
-	return m.eventsBuffer.GetRecent(10000) // 返回所有事件
+	return m.withEventComments(m.eventsBuffer.GetRecent(10000)) // 返回所有事件
 }
 
 // GetImpactEvents 获取所有影响事件 (CLI使用)
@@ -451,7 +1160,7 @@ func (m *MultiMonitor) GetImpactEvents() []types.ImpactEvent {
 	if m.impactAnalyzer == nil {
 		return []types.ImpactEvent{}
 	}
-	return m.impactAnalyzer.GetRecentImpacts(10000) // 返回所有影响事件
+	return m.withImpactComments(m.impactAnalyzer.GetRecentImpacts(10000, false)) // 返回所有影响事件
 }
 
 // ClearImpactEvents 清除所有影响事件 (CLI使用)
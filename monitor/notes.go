@@ -0,0 +1,56 @@
+package monitor
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"monitor-agent/types"
+)
+
+const (
+	// MaxTargetNotesLen 目标处置说明的最大长度（字符数）
+	MaxTargetNotesLen = 1000
+	// MaxRunbookURLLen 运行手册链接的最大长度
+	MaxRunbookURLLen = 2048
+)
+
+// NormalizeTargetNotes 规范化并校验目标的处置说明和运行手册链接
+// 处置说明去除首尾空白和控制字符（保留换行和制表符），超出长度限制时返回错误；
+// 运行手册链接只允许 http/https 绝对地址，避免在 Web 页面中生成可执行脚本的链接
+func NormalizeTargetNotes(t *types.MonitorTarget) error {
+	notes := strings.ToValidUTF8(t.Notes, "")
+	notes = strings.ReplaceAll(notes, "\r\n", "\n")
+	notes = strings.Map(func(r rune) rune {
+		if r != '\n' && r != '\t' && unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, notes)
+	notes = strings.TrimSpace(notes)
+	if n := utf8.RuneCountInString(notes); n > MaxTargetNotesLen {
+		return fmt.Errorf("notes too long: %d characters (max %d)", n, MaxTargetNotesLen)
+	}
+	t.Notes = notes
+
+	link := strings.TrimSpace(t.RunbookURL)
+	if link != "" {
+		if len(link) > MaxRunbookURLLen {
+			return fmt.Errorf("runbook_url too long (max %d bytes)", MaxRunbookURLLen)
+		}
+		if strings.IndexFunc(link, func(r rune) bool { return unicode.IsSpace(r) || unicode.IsControl(r) }) >= 0 {
+			return fmt.Errorf("runbook_url must not contain whitespace")
+		}
+		u, err := url.Parse(link)
+		if err != nil {
+			return fmt.Errorf("invalid runbook_url: %v", err)
+		}
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("runbook_url must be an http or https URL")
+		}
+	}
+	t.RunbookURL = link
+	return nil
+}
@@ -0,0 +1,264 @@
+package monitor
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"time"
+
+	"monitor-agent/buffer"
+	"monitor-agent/i18n"
+	"monitor-agent/logger"
+	"monitor-agent/types"
+)
+
+// 远程探测：无法安装本程序的设备（如嵌入式控制器）只能从外部判断存活。每个探测目标一个探测协程，
+// 按间隔 TCP 连接指定端口并记录连接耗时，连续失败达到阈值判定为不可达、恢复连通时判定为恢复，
+// 两者都产生事件。探测目标不是进程，不参与影响分析，随监控启停。
+
+const (
+	defaultProbeInterval      = 10   // 默认探测间隔（秒）
+	defaultProbeTimeoutMs     = 2000 // 默认连接超时（毫秒）
+	defaultProbeFailThreshold = 3    // 默认连续失败判定次数
+	probeHistoryLen           = 360  // 每个探测目标保留的结果数（默认间隔约 1 小时）
+)
+
+// 探测目标状态
+const (
+	ProbeUnknown = "unknown"
+	ProbeUp      = "up"
+	ProbeDown    = "down"
+)
+
+// probeState 一个探测目标的运行状态，除 results 外受 probeMu 保护
+type probeState struct {
+	cfg      types.ProbeConfig
+	results  *buffer.RingBuffer[types.ProbeResult]
+	state    string
+	since    time.Time
+	failures int
+	last     *types.ProbeResult
+	stop     chan struct{} // 探测协程运行中时非 nil
+}
+
+// ValidateProbe 补齐探测目标的默认值并校验
+func ValidateProbe(cfg *types.ProbeConfig) error {
+	if cfg.Name == "" {
+		return fmt.Errorf("probe name is required")
+	}
+	if cfg.Host == "" {
+		return fmt.Errorf("probe %s: host is required", cfg.Name)
+	}
+	if cfg.Port < 1 || cfg.Port > 65535 {
+		return fmt.Errorf("probe %s: invalid port %d", cfg.Name, cfg.Port)
+	}
+	if cfg.Interval < 0 || cfg.TimeoutMs < 0 || cfg.FailThreshold < 0 {
+		return fmt.Errorf("probe %s: interval, timeout_ms and fail_threshold must not be negative", cfg.Name)
+	}
+	if cfg.Interval == 0 {
+		cfg.Interval = defaultProbeInterval
+	}
+	if cfg.TimeoutMs == 0 {
+		cfg.TimeoutMs = defaultProbeTimeoutMs
+	}
+	if cfg.FailThreshold == 0 {
+		cfg.FailThreshold = defaultProbeFailThreshold
+	}
+	if time.Duration(cfg.TimeoutMs)*time.Millisecond > time.Duration(cfg.Interval)*time.Second {
+		return fmt.Errorf("probe %s: timeout_ms must not exceed the interval", cfg.Name)
+	}
+	return nil
+}
+
+// SetProbes 替换全部探测目标（名称不能重复）。名称和地址均未变化的目标保留状态和历史结果，
+// 其余重新开始；监控运行中时立即按新配置探测
+func (m *MultiMonitor) SetProbes(cfgs []types.ProbeConfig) error {
+	probes := make(map[string]*probeState, len(cfgs))
+	for _, cfg := range cfgs {
+		if err := ValidateProbe(&cfg); err != nil {
+			return err
+		}
+		if _, dup := probes[cfg.Name]; dup {
+			return fmt.Errorf("duplicate probe name %q", cfg.Name)
+		}
+		probes[cfg.Name] = &probeState{
+			cfg:     cfg,
+			results: buffer.NewRingBuffer[types.ProbeResult](probeHistoryLen),
+			state:   ProbeUnknown,
+			since:   time.Now(),
+		}
+	}
+
+	m.probeMu.Lock()
+	defer m.probeMu.Unlock()
+	for name, old := range m.probes {
+		if old.stop != nil {
+			close(old.stop)
+			old.stop = nil
+		}
+		if p, ok := probes[name]; ok && p.cfg.Host == old.cfg.Host && p.cfg.Port == old.cfg.Port {
+			p.results, p.state, p.since, p.failures, p.last = old.results, old.state, old.since, old.failures, old.last
+		}
+	}
+	m.probes = probes
+	if m.probeStopCh != nil {
+		m.startProbesLocked()
+	}
+	logger.Infof("MONITOR", "Probes configured: %d", len(probes))
+	return nil
+}
+
+// startProbes 监控启动时开始探测，stopCh 为本次运行的停止通道
+func (m *MultiMonitor) startProbes(stopCh chan struct{}) {
+	m.probeMu.Lock()
+	defer m.probeMu.Unlock()
+	m.probeStopCh = stopCh
+	m.startProbesLocked()
+}
+
+// stopProbes 监控停止时停止全部探测协程
+func (m *MultiMonitor) stopProbes() {
+	m.probeMu.Lock()
+	defer m.probeMu.Unlock()
+	m.probeStopCh = nil
+	for _, p := range m.probes {
+		if p.stop != nil {
+			close(p.stop)
+			p.stop = nil
+		}
+	}
+}
+
+// startProbesLocked 为尚未运行的探测目标启动探测协程（调用方需持有 probeMu，监控运行中）
+func (m *MultiMonitor) startProbesLocked() {
+	for _, p := range m.probes {
+		if p.stop == nil {
+			p.stop = make(chan struct{})
+			m.runWG.Add(1)
+			go func(p *probeState, stop, stopCh chan struct{}) {
+				defer m.runWG.Done()
+				m.probeLoop(p, stop, stopCh)
+			}(p, p.stop, m.probeStopCh)
+		}
+	}
+}
+
+// probeLoop 探测循环，探测目标被替换或监控停止时退出
+func (m *MultiMonitor) probeLoop(p *probeState, stop, stopCh chan struct{}) {
+	interval := time.Duration(p.cfg.Interval) * time.Second
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		m.probeOnce(p)
+		select {
+		case <-stop:
+			return
+		case <-stopCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// probeOnce 探测一次并更新状态，状态变化时产生事件
+func (m *MultiMonitor) probeOnce(p *probeState) {
+	addr := net.JoinHostPort(p.cfg.Host, strconv.Itoa(p.cfg.Port))
+	start := time.Now()
+	conn, err := net.DialTimeout("tcp", addr, time.Duration(p.cfg.TimeoutMs)*time.Millisecond)
+	result := types.ProbeResult{Timestamp: start, Up: err == nil}
+	if err == nil {
+		result.RTTMs = float64(time.Since(start).Microseconds()) / 1000
+		conn.Close()
+	} else {
+		result.Error = err.Error()
+	}
+	p.results.Push(result)
+
+	var evt *types.Event
+	m.probeMu.Lock()
+	p.last = &result
+	if result.Up {
+		p.failures = 0
+		if p.state != ProbeUp {
+			if p.state == ProbeDown {
+				evt = &types.Event{
+					Type:    "probe_up",
+					Message: i18n.T("event.probe_up", addr, result.RTTMs),
+				}
+			}
+			p.state, p.since = ProbeUp, result.Timestamp
+		}
+	} else {
+		p.failures++
+		if p.failures >= p.cfg.FailThreshold && p.state != ProbeDown {
+			evt = &types.Event{
+				Type:    "probe_down",
+				Message: i18n.T("event.probe_down", addr, p.failures, result.Error),
+			}
+			p.state, p.since = ProbeDown, result.Timestamp
+		}
+	}
+	m.probeMu.Unlock()
+
+	if evt != nil {
+		evt.Timestamp = result.Timestamp
+		evt.Name = p.cfg.Name
+		m.addEvent(*evt)
+	}
+}
+
+// GetProbes 所有探测目标及其状态，按名称排序
+func (m *MultiMonitor) GetProbes() []types.ProbeStatus {
+	m.probeMu.Lock()
+	defer m.probeMu.Unlock()
+	result := make([]types.ProbeStatus, 0, len(m.probes))
+	for _, p := range m.probes {
+		status := types.ProbeStatus{
+			Kind:        "probe",
+			ProbeConfig: p.cfg,
+			State:       p.state,
+			Failures:    p.failures,
+		}
+		if p.state != ProbeUnknown {
+			since := p.since
+			status.Since = &since
+		}
+		if p.last != nil {
+			last := *p.last
+			status.Last = &last
+		}
+		result = append(result, status)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// GetProbeResults 探测目标最近 n 次探测结果（n<=0 表示全部），按时间从早到晚排列；目标不存在时返回 false
+func (m *MultiMonitor) GetProbeResults(name string, n int) ([]types.ProbeResult, bool) {
+	m.probeMu.Lock()
+	p, ok := m.probes[name]
+	m.probeMu.Unlock()
+	if !ok {
+		return nil, false
+	}
+	if n <= 0 {
+		return p.results.GetAll(), true
+	}
+	return p.results.GetRecent(n), true
+}
+
+// GetTargetList 进程目标和远程探测目标的列表，以 kind 区分（"process" 或 "probe"），进程目标在前
+func (m *MultiMonitor) GetTargetList() []interface{} {
+	targets := m.GetTargets()
+	probes := m.GetProbes()
+	list := make([]interface{}, 0, len(targets)+len(probes))
+	for _, t := range targets {
+		list = append(list, types.ProcessTargetEntry{Kind: "process", MonitorTarget: t})
+	}
+	for _, p := range probes {
+		list = append(list, p)
+	}
+	return list
+}
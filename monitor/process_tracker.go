@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"monitor-agent/buffer"
+	"monitor-agent/clock"
 	"monitor-agent/types"
 )
 
@@ -20,8 +21,17 @@ type ProcessTracker struct {
 
 	// 首次运行标记
 	firstRun bool
+
+	// 最近一个统计窗口内的进程变化，用于计算启停频率
+	recent []types.ProcessChange
+
+	// 时间来源，默认系统时钟
+	clock clock.Clock
 }
 
+// churnWindow 进程启停频率统计窗口
+const churnWindow = time.Minute
+
 // NewProcessTracker 创建进程追踪器
 func NewProcessTracker(bufferSize int) *ProcessTracker {
 	if bufferSize <= 0 {
@@ -31,6 +41,7 @@ func NewProcessTracker(bufferSize int) *ProcessTracker {
 		lastSnapshot: make(map[int32]*types.ProcessInfo),
 		changes:      buffer.NewRingBuffer[types.ProcessChange](bufferSize),
 		firstRun:     true,
+		clock:        clock.Real,
 	}
 }
 
@@ -39,7 +50,7 @@ func (t *ProcessTracker) Update(processes []types.ProcessInfo) []types.ProcessCh
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
-	now := time.Now()
+	now := t.clock.Now()
 	currentPids := make(map[int32]bool)
 	var changes []types.ProcessChange
 
@@ -83,9 +94,43 @@ func (t *ProcessTracker) Update(processes []types.ProcessInfo) []types.ProcessCh
 	}
 
 	t.firstRun = false
+	t.recent = append(t.recent, changes...)
+	t.pruneRecentLocked(now)
 	return changes
 }
 
+// pruneRecentLocked 丢弃统计窗口之外的进程变化（调用方需持有 mu）
+func (t *ProcessTracker) pruneRecentLocked(now time.Time) {
+	i := 0
+	for i < len(t.recent) && now.Sub(t.recent[i].Timestamp) > churnWindow {
+		i++
+	}
+	if i > 0 {
+		t.recent = append(t.recent[:0], t.recent[i:]...)
+	}
+}
+
+// Churn 统计最近一分钟的进程启停次数，并找出新建最频繁的进程名
+func (t *ProcessTracker) Churn(now time.Time) types.ProcessChurn {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.pruneRecentLocked(now)
+
+	churn := types.ProcessChurn{PerMinute: len(t.recent)}
+	counts := make(map[string]int)
+	for _, c := range t.recent {
+		if c.Type != "new" {
+			continue
+		}
+		counts[c.Name]++
+		n := counts[c.Name]
+		if n > churn.TopCount || (n == churn.TopCount && c.Name < churn.TopName) {
+			churn.TopName, churn.TopCount = c.Name, n
+		}
+	}
+	return churn
+}
+
 // GetRecentChanges 获取最近的进程变化
 func (t *ProcessTracker) GetRecentChanges(n int) []types.ProcessChange {
 	return t.changes.GetRecent(n)
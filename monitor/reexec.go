@@ -0,0 +1,82 @@
+package monitor
+
+import (
+	"runtime"
+	"strings"
+
+	"monitor-agent/i18n"
+	"monitor-agent/types"
+)
+
+// exec 检测：Linux 下进程可以 exec 为另一个程序而保持 PID 不变，此时按 PID 监控的目标实际已换成了别的程序。
+// 纳入保障时记录可执行文件路径，每次采样与当前路径比较，不一致时产生 reexec 事件。
+// 程序文件被原地替换（路径带 " (deleted)" 后缀）不属于 exec，由完整性校验（binary_changed）负责。
+// Windows 没有 exec，不检测。
+
+// normalizeExe 去掉程序文件被替换后的 " (deleted)" 后缀
+func normalizeExe(exe string) string {
+	return strings.TrimSuffix(exe, deletedExeSuffix)
+}
+
+// checkReexec 比较目标当前的可执行文件路径与纳入保障时记录的路径，不一致时记录 reexec 事件，
+// 以新路径作为期望值并重建程序文件基线。读取失败（如权限不足、进程刚退出）时跳过
+func (m *MultiMonitor) checkReexec(pid int32) {
+	if runtime.GOOS == "windows" {
+		return
+	}
+	exe, _, err := m.provider.GetExecPaths(pid)
+	if err != nil || exe == "" {
+		return
+	}
+	exe = normalizeExe(exe)
+
+	m.mu.Lock()
+	state, exists := m.targets[pid]
+	if !exists {
+		m.mu.Unlock()
+		return
+	}
+	expected := state.expectedExe
+	if expected == "" {
+		// 纳入保障时未能读取路径，以首次读到的路径作为期望值
+		state.expectedExe = exe
+	}
+	if expected == "" || expected == exe {
+		m.mu.Unlock()
+		return
+	}
+	change := &types.ExecChange{From: expected, To: exe, At: m.clock.Now()}
+	state.expectedExe = exe
+	state.reexec = change
+	name := state.target.Name
+	m.mu.Unlock()
+
+	// 原基线属于 exec 之前的程序，以新程序重建（可能需要计算哈希，不持锁）
+	binary, _ := m.readBinaryInfo(pid)
+	m.mu.Lock()
+	if state, exists := m.targets[pid]; exists {
+		state.binary = binary
+		state.binaryCheckedAt = change.At
+	}
+	m.mu.Unlock()
+
+	m.addEvent(types.Event{
+		Timestamp: change.At,
+		Type:      "reexec",
+		PID:       pid,
+		Name:      name,
+		Message:   i18n.T("event.reexec", change.From, change.To),
+	})
+}
+
+// GetExecChange 获取目标最近一次 exec 为其他程序的记录（未发生时返回 nil）
+func (m *MultiMonitor) GetExecChange(pid int32) *types.ExecChange {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	state, ok := m.targets[pid]
+	if !ok || state.reexec == nil {
+		return nil
+	}
+	change := *state.reexec
+	return &change
+}
@@ -0,0 +1,343 @@
+package monitor
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"monitor-agent/i18n"
+	"monitor-agent/logger"
+	"monitor-agent/provider"
+	"monitor-agent/types"
+)
+
+// 自动重启：目标退出后按 restart_policy 执行 restart_command，再按进程名找到新实例并改为监控新 PID。
+// 命令以代理的身份和工作目录执行，只有配置文件中 watchdog.allow_restart 开启时才会执行。
+const (
+	defaultRestartAttempts = 3
+	defaultRestartBackoff  = 10 * time.Second
+	maxRestartBackoff      = 10 * time.Minute
+
+	// restartResolveTimeout 执行命令后等待新进程出现的时间
+	restartResolveTimeout = 15 * time.Second
+	// restartStableAfter 重启后持续运行超过该时间再退出时，重新计算尝试次数
+	restartStableAfter = 5 * time.Minute
+	// restartStartSlack 进程启动时间由开机时间（秒级）换算，判断是否在重启后启动时允许的误差
+	restartStartSlack = 5 * time.Second
+)
+
+// errRestartStopped 等待新进程期间监控已停止，放弃本次重启的后续处理
+var errRestartStopped = errors.New("monitor stopped")
+
+// restartState 一个目标的重启状态（按名称和别名记录，PID 变化后保留）
+type restartState struct {
+	attempts    int       // 连续尝试次数
+	running     bool      // 重启命令执行中
+	nextAt      time.Time // 下次尝试的最早时间
+	succeededAt time.Time // 最近一次重启成功的时间
+	exhausted   bool      // 已达到最多尝试次数，不再重启
+	reported    bool      // 已记录放弃重启的事件
+}
+
+// NormalizeTargetRestart 校验目标的自动重启配置，策略模式为空时视为 never
+func NormalizeTargetRestart(t *types.MonitorTarget) error {
+	t.RestartCommand = strings.TrimSpace(t.RestartCommand)
+	p := t.RestartPolicy
+	if p == nil {
+		return nil
+	}
+	p.Mode = strings.ToLower(strings.TrimSpace(p.Mode))
+	switch p.Mode {
+	case "", types.RestartNever:
+		p.Mode = types.RestartNever
+	case types.RestartOnFailure, types.RestartAlways:
+		if t.RestartCommand == "" {
+			return fmt.Errorf("restart_policy %s requires restart_command", p.Mode)
+		}
+	default:
+		return fmt.Errorf("unknown restart_policy mode %q (never, on-failure or always)", p.Mode)
+	}
+	if p.MaxAttempts < 0 || p.BackoffSec < 0 {
+		return fmt.Errorf("restart_policy max_attempts and backoff_sec must not be negative")
+	}
+	return nil
+}
+
+// forgetRestartLocked 重新纳入保障或解除保障时清除目标的重启状态（调用方需持有 mu）
+func (m *MultiMonitor) forgetRestartLocked(target types.MonitorTarget) {
+	delete(m.restarts, snapshotKey(target))
+}
+
+// resetRestartLocked 修改重启配置后重新计算尝试次数，进行中的重启不受影响（调用方需持有 mu）
+func (m *MultiMonitor) resetRestartLocked(target types.MonitorTarget) {
+	if rs, ok := m.restarts[snapshotKey(target)]; ok {
+		rs.attempts, rs.exhausted, rs.reported, rs.nextAt = 0, false, false, time.Time{}
+	}
+}
+
+// restartChanged 两个目标配置的重启命令或重启策略是否不同
+func restartChanged(a, b types.MonitorTarget) bool {
+	if a.RestartCommand != b.RestartCommand || (a.RestartPolicy == nil) != (b.RestartPolicy == nil) {
+		return true
+	}
+	return a.RestartPolicy != nil && *a.RestartPolicy != *b.RestartPolicy
+}
+
+// RestartLimits 重启策略生效的最多尝试次数和初始等待（未设置时为默认值）
+func RestartLimits(p *types.RestartPolicy) (int, time.Duration) {
+	attempts, backoff := p.MaxAttempts, time.Duration(p.BackoffSec)*time.Second
+	if attempts <= 0 {
+		attempts = defaultRestartAttempts
+	}
+	if backoff <= 0 {
+		backoff = defaultRestartBackoff
+	}
+	return attempts, backoff
+}
+
+// maybeRestart 目标不存活时按重启策略决定是否执行重启命令（命令在后台执行，不阻塞采样）
+func (m *MultiMonitor) maybeRestart(pid int32, target types.MonitorTarget) {
+	p := target.RestartPolicy
+	if !m.config.AllowRestart || p == nil || target.RestartCommand == "" {
+		return
+	}
+	if p.Mode != types.RestartAlways && (p.Mode != types.RestartOnFailure || m.InMaintenance(pid)) {
+		return
+	}
+	maxAttempts, backoff := RestartLimits(p)
+	now := m.clock.Now()
+
+	m.mu.Lock()
+	if !m.running {
+		// 未运行时（或正在停止）不启动新的重启
+		m.mu.Unlock()
+		return
+	}
+	stopCh := m.stopCh
+	key := snapshotKey(target)
+	rs, ok := m.restarts[key]
+	if !ok {
+		rs = &restartState{}
+		m.restarts[key] = rs
+	}
+	if !rs.succeededAt.IsZero() && now.Sub(rs.succeededAt) > restartStableAfter {
+		// 上次重启后已稳定运行，本次退出重新计数
+		rs.attempts, rs.exhausted, rs.reported, rs.succeededAt = 0, false, false, time.Time{}
+	}
+	if rs.exhausted && !rs.reported && !rs.running {
+		rs.reported = true
+		m.mu.Unlock()
+		m.addEvent(types.Event{
+			Timestamp: now,
+			Type:      "restart_failed",
+			PID:       pid,
+			Name:      target.Name,
+			Message:   i18n.T("event.restart_exhausted", maxAttempts),
+		})
+		return
+	}
+	if rs.running || rs.exhausted || now.Before(rs.nextAt) {
+		m.mu.Unlock()
+		return
+	}
+	rs.attempts++
+	rs.running = true
+	attempt := rs.attempts
+	// 在 mu 内、确认运行中时登记：Stop 置 running=false 后才等待 runWG，不会漏等本协程
+	m.runWG.Add(1)
+	m.mu.Unlock()
+
+	m.addEvent(types.Event{
+		Timestamp: now,
+		Type:      "restart_attempt",
+		PID:       pid,
+		Name:      target.Name,
+		Message:   i18n.T("event.restart_attempt", attempt, maxAttempts),
+	})
+	logger.Infof("MONITOR", "Restarting target %s (PID %d), attempt %d/%d: %s",
+		target.Name, pid, attempt, maxAttempts, target.RestartCommand)
+
+	go func() {
+		defer m.runWG.Done()
+		newPID, err := m.runRestart(pid, target, stopCh)
+		if errors.Is(err, errRestartStopped) {
+			// 监控已停止：不再记录事件，下次启动后按原状态继续
+			m.mu.Lock()
+			rs.running = false
+			m.mu.Unlock()
+			return
+		}
+		now := m.clock.Now()
+
+		// 下次尝试前的等待按尝试次数翻倍；重启成功后很快再次退出同样计入尝试次数
+		delay := backoff << minInt(attempt-1, 16)
+		if delay > maxRestartBackoff {
+			delay = maxRestartBackoff
+		}
+		m.mu.Lock()
+		rs.running = false
+		rs.nextAt = now.Add(delay)
+		rs.exhausted = attempt >= maxAttempts
+		if err == nil {
+			rs.succeededAt = now
+		}
+		m.mu.Unlock()
+
+		if err != nil {
+			logger.Warnf("MONITOR", "Restart target %s (PID %d) failed: %v", target.Name, pid, err)
+			m.addEvent(types.Event{
+				Timestamp: now,
+				Type:      "restart_failed",
+				PID:       pid,
+				Name:      target.Name,
+				Message:   i18n.T("event.restart_failed", attempt, maxAttempts, err.Error()),
+			})
+			return
+		}
+		logger.Infof("MONITOR", "Target %s restarted: PID %d -> %d", target.Name, pid, newPID)
+		m.addEvent(types.Event{
+			Timestamp: now,
+			Type:      "restart_success",
+			PID:       newPID,
+			Name:      target.Name,
+			Message:   i18n.T("event.restart_success", pid, newPID),
+		})
+	}()
+}
+
+// runRestart 执行重启命令并等待同名新进程出现，找到后改为监控新 PID；
+// 等待期间 stopCh 关闭时返回 errRestartStopped，不再修改目标
+func (m *MultiMonitor) runRestart(oldPID int32, target types.MonitorTarget, stopCh <-chan struct{}) (int32, error) {
+	started := m.clock.Now()
+	cmd := restartCommand(target.RestartCommand)
+	if err := cmd.Start(); err != nil {
+		return 0, fmt.Errorf("start command: %w", err)
+	}
+	spawned := int32(cmd.Process.Pid)
+	// 回收子进程：命令可能就是目标程序本身，会一直运行到目标退出，因此不登记到 runWG
+	// （否则 Stop 要等目标退出）；该协程只向带缓冲的 done 发送，不访问监控器状态
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	deadline := time.NewTimer(restartResolveTimeout)
+	defer deadline.Stop()
+	poll := time.NewTicker(500 * time.Millisecond)
+	defer poll.Stop()
+	for {
+		select {
+		case <-stopCh:
+			return 0, errRestartStopped
+		case err := <-done:
+			// 命令可能直接就是目标程序（退出即失败），也可能是启动后返回的脚本（继续等待新进程）
+			if err != nil {
+				return 0, fmt.Errorf("command exited: %w", err)
+			}
+			done = nil
+		case <-poll.C:
+			if newPID := m.findRestartedPID(target.Name, oldPID, spawned, started); newPID > 0 {
+				return newPID, m.rebindTarget(oldPID, newPID)
+			}
+		case <-deadline.C:
+			return 0, fmt.Errorf("process %s did not start within %v", target.Name, restartResolveTimeout)
+		}
+	}
+}
+
+// findRestartedPID 查找重启后的新实例：优先使用命令直接启动的进程，
+// 否则取重启开始后启动的、尚未被监控的同名进程中最新的一个
+func (m *MultiMonitor) findRestartedPID(name string, oldPID, spawned int32, started time.Time) int32 {
+	pids, err := m.provider.FindAllPIDsByName(name)
+	if err != nil {
+		return 0
+	}
+	candidates := make([]int32, 0, len(pids))
+	m.mu.RLock()
+	for _, pid := range pids {
+		if pid != oldPID && m.targets[pid] == nil {
+			candidates = append(candidates, pid)
+		}
+	}
+	m.mu.RUnlock()
+
+	var best int32
+	var bestTime int64
+	for _, pid := range candidates {
+		if pid == spawned {
+			return pid
+		}
+		key, err := provider.GetProcessCreateTimeKeyed(pid)
+		if err != nil || key.CreateTime < started.Add(-restartStartSlack).UnixMilli() {
+			continue
+		}
+		if key.CreateTime > bestTime {
+			best, bestTime = pid, key.CreateTime
+		}
+	}
+	return best
+}
+
+// rebindTarget 把目标从已退出的 PID 改为重启后的新 PID：保留配置、重启状态和维护窗口，
+// 重新记录程序文件基线和启动快照，指标缓冲重新开始
+func (m *MultiMonitor) rebindTarget(oldPID, newPID int32) error {
+	binary, _ := m.readBinaryInfo(newPID)
+	snapshot := m.captureSnapshot(newPID)
+	now := m.clock.Now()
+
+	m.mu.Lock()
+	state, exists := m.targets[oldPID]
+	if !exists {
+		m.mu.Unlock()
+		return fmt.Errorf("target PID %d was removed during restart", oldPID)
+	}
+	if _, taken := m.targets[newPID]; taken {
+		m.mu.Unlock()
+		return fmt.Errorf("new PID %d is already monitored", newPID)
+	}
+	state.target.PID = newPID
+	state.lastMetric = nil
+	state.exitReported = false
+	state.prioritySeen = false
+	state.binary = binary
+	state.binaryCheckedAt = now
+	state.expectedExe = ""
+	if binary != nil {
+		state.expectedExe = normalizeExe(binary.Path)
+	}
+	state.reexec = nil
+	delete(m.targets, oldPID)
+	delete(m.metricsBuffers, oldPID)
+	m.targets[newPID] = state
+	m.metricsBuffers[newPID] = m.newMetricsBufferLocked(state.target)
+	if snapshot != nil {
+		m.recordSnapshotLocked(state, snapshot)
+	}
+	m.openFiles.Forget(oldPID)
+	if m.impactAnalyzer != nil {
+		m.impactAnalyzer.RemoveTargetEvents(oldPID)
+	}
+	m.notifyTargetChange()
+	m.mu.Unlock()
+	m.wakeScheduler()
+
+	m.maintMu.Lock()
+	if w, ok := m.maintenance[oldPID]; ok {
+		delete(m.maintenance, oldPID)
+		w.PID = newPID
+		m.maintenance[newPID] = w
+		m.saveMaintenanceLocked()
+	}
+	m.maintMu.Unlock()
+
+	if snapshot != nil {
+		logSnapshot(snapshot)
+	}
+	return nil
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
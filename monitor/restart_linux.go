@@ -0,0 +1,15 @@
+//go:build linux
+
+package monitor
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// restartCommand 由 sh -c 执行重启命令，放入新会话，代理退出或收到终端信号时不影响重启后的进程
+func restartCommand(command string) *exec.Cmd {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	return cmd
+}
@@ -0,0 +1,74 @@
+//go:build linux
+
+package monitor
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"monitor-agent/provider"
+	"monitor-agent/types"
+)
+
+func eventCount(m *MultiMonitor, eventType string) int {
+	n := 0
+	for _, e := range m.GetEvents() {
+		if e.Type == eventType {
+			n++
+		}
+	}
+	return n
+}
+
+// 重启命令执行后、等待新进程期间停止监控：Stop 等待重启协程退出，之后不再修改目标或记录事件
+func TestStopDuringRestartWait(t *testing.T) {
+	base := runtime.NumGoroutine()
+
+	prov := provider.NewFake(nil)
+	prov.SetProcesses([]types.ProcessInfo{{PID: 900001, Name: "scada-restart-test", Status: "S"}})
+	m, err := NewMultiMonitor(types.MultiMonitorConfig{SampleInterval: 1, LogDir: t.TempDir(), AllowRestart: true}, prov)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 命令启动的进程不是目标（Fake 中找不到同名新进程），重启协程会一直轮询到超时
+	if err := m.AddTarget(types.MonitorTarget{
+		PID:            900001,
+		Name:           "scada-restart-test",
+		RestartCommand: "sleep 2",
+		RestartPolicy:  &types.RestartPolicy{Mode: types.RestartAlways},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Start(); err != nil {
+		t.Fatal(err)
+	}
+	prov.RemoveProcess(900001)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for eventCount(m, "restart_attempt") == 0 {
+		if time.Now().After(deadline) {
+			m.Stop()
+			t.Fatal("no restart attempt after the target exited")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	runWithTimeout(t, 3*time.Second, m.Stop)
+	time.Sleep(700 * time.Millisecond) // 超过一次轮询间隔
+	if n := eventCount(m, "restart_failed") + eventCount(m, "restart_success"); n != 0 {
+		t.Fatalf("%d restart events recorded after Stop", n)
+	}
+	if _, ok := m.GetTarget(900001); !ok {
+		t.Fatal("target was rebound or removed after Stop")
+	}
+	m.mu.RLock()
+	for key, rs := range m.restarts {
+		if rs.running {
+			t.Errorf("restart %v still marked running after Stop", key)
+		}
+	}
+	m.mu.RUnlock()
+	// 重启命令（sleep 2）退出后回收子进程的协程也退出
+	waitGoroutines(t, base)
+}
@@ -0,0 +1,17 @@
+//go:build windows
+
+package monitor
+
+import (
+	"os/exec"
+	"syscall"
+
+	"golang.org/x/sys/windows"
+)
+
+// restartCommand 由 cmd /C 执行重启命令，放入新进程组，控制台 Ctrl+C 不影响重启后的进程
+func restartCommand(command string) *exec.Cmd {
+	cmd := exec.Command("cmd", "/C", command)
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: windows.CREATE_NEW_PROCESS_GROUP}
+	return cmd
+}
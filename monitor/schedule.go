@@ -0,0 +1,192 @@
+package monitor
+
+import (
+	"container/heap"
+	"fmt"
+	"time"
+
+	"monitor-agent/buffer"
+	"monitor-agent/types"
+)
+
+// 按目标采样间隔调度：实时控制进程需要 1 秒采样，一同监控的辅助服务 10～60 秒采样即可。
+// 采样循环维护各目标下次采样时间的最小堆，只用一个定时器等待最早到期的目标，不为每个目标启动协程；
+// 采样时刻按间隔对齐到同一基准，间隔相同的目标在同一时刻一起采样。
+// 指标缓冲区按时间跨度换算容量：间隔为全局 2 倍的目标缓冲区减半，各目标保留的历史时长大致相同。
+
+const (
+	// MaxTargetInterval 目标采样间隔的上限（秒）
+	MaxTargetInterval = 3600
+
+	// baseTickPID 调度中的全局节拍：按全局采样间隔检查维护窗口到期并触发采样完成回调（指标推送），
+	// 所有目标的采样间隔都较长时也照常进行
+	baseTickPID int32 = 0
+
+	// minMetricsBufferLen 按间隔换算后的指标缓冲区最小容量
+	minMetricsBufferLen = 2
+)
+
+// ValidateTargetInterval 校验目标的采样间隔（秒），0 表示使用全局采样间隔
+func ValidateTargetInterval(t types.MonitorTarget) error {
+	if t.SampleInterval < 0 || t.SampleInterval > MaxTargetInterval {
+		return fmt.Errorf("sample_interval must be between 0 and %d seconds, got %d", MaxTargetInterval, t.SampleInterval)
+	}
+	return nil
+}
+
+// scheduleItem 一个目标的下次采样时间
+type scheduleItem struct {
+	pid      int32
+	due      time.Time
+	interval time.Duration // 排入时的采样间隔
+	seq      uint64        // 排入序号，区分同一目标先后排入的项
+}
+
+// dueTarget 本轮到期的目标及其采样间隔
+type dueTarget struct {
+	pid      int32
+	interval time.Duration
+}
+
+// sampleSchedule 按下次采样时间排序的最小堆（实现 heap.Interface），只在采样循环中使用。
+// 目标的间隔变化（或移除后重新加入）时直接再排入一项，旧项在出堆时因序号与 queued 不符而丢弃
+type sampleSchedule struct {
+	items  []scheduleItem
+	queued map[int32]scheduleItem // 已排入的目标 -> 当前有效项
+	seq    uint64
+	epoch  time.Time // 对齐基准
+}
+
+func newSampleSchedule(epoch time.Time) *sampleSchedule {
+	return &sampleSchedule{queued: make(map[int32]scheduleItem), epoch: epoch}
+}
+
+// queuedInterval 目标当前有效项的采样间隔，未排入时为 0
+func (s *sampleSchedule) queuedInterval(pid int32) time.Duration {
+	return s.queued[pid].interval
+}
+
+func (s *sampleSchedule) Len() int           { return len(s.items) }
+func (s *sampleSchedule) Less(i, j int) bool { return s.items[i].due.Before(s.items[j].due) }
+func (s *sampleSchedule) Swap(i, j int)      { s.items[i], s.items[j] = s.items[j], s.items[i] }
+func (s *sampleSchedule) Push(x any)         { s.items = append(s.items, x.(scheduleItem)) }
+func (s *sampleSchedule) Pop() any {
+	n := len(s.items)
+	item := s.items[n-1]
+	s.items = s.items[:n-1]
+	return item
+}
+
+// alignedDue 晚于 now 的第一个对齐采样时刻（基准加整数倍的间隔）
+func (s *sampleSchedule) alignedDue(interval time.Duration, now time.Time) time.Time {
+	n := now.Sub(s.epoch)/interval + 1
+	return s.epoch.Add(n * interval)
+}
+
+// enqueue 把目标排入 now 之后的第一个对齐时刻
+func (s *sampleSchedule) enqueue(pid int32, interval time.Duration, now time.Time) {
+	s.seq++
+	item := scheduleItem{pid: pid, due: s.alignedDue(interval, now), interval: interval, seq: s.seq}
+	heap.Push(s, item)
+	s.queued[pid] = item
+}
+
+// next 最早的采样时间
+func (s *sampleSchedule) next() time.Time {
+	return s.items[0].due
+}
+
+// popDue 取出到期的项并按各自间隔排入下一次，丢弃已失效的项。
+// 采样耗时超过间隔时跳过错过的时刻，不连续补采
+func (s *sampleSchedule) popDue(now time.Time) []dueTarget {
+	var due []dueTarget
+	for len(s.items) > 0 && !s.items[0].due.After(now) {
+		item := heap.Pop(s).(scheduleItem)
+		if current, ok := s.queued[item.pid]; !ok || current.seq != item.seq {
+			continue
+		}
+		due = append(due, dueTarget{pid: item.pid, interval: item.interval})
+		item.due = item.due.Add(item.interval)
+		if !item.due.After(now) {
+			item.due = s.alignedDue(item.interval, now)
+		}
+		heap.Push(s, item)
+	}
+	return due
+}
+
+// syncSchedule 按当前目标更新调度：排入新目标和间隔变化的目标，移除已不存在的目标
+func (m *MultiMonitor) syncSchedule(s *sampleSchedule, global time.Duration, now time.Time) {
+	if s.queuedInterval(baseTickPID) != global {
+		s.enqueue(baseTickPID, global, now)
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for pid, state := range m.targets {
+		if interval := m.targetIntervalLocked(state.target); s.queuedInterval(pid) != interval {
+			s.enqueue(pid, interval, now)
+		}
+	}
+	for pid := range s.queued {
+		if _, ok := m.targets[pid]; !ok && pid != baseTickPID {
+			delete(s.queued, pid)
+		}
+	}
+}
+
+// wakeScheduler 通知采样循环目标或其采样间隔有变化
+func (m *MultiMonitor) wakeScheduler() {
+	select {
+	case m.scheduleCh <- struct{}{}:
+	default:
+	}
+}
+
+// targetIntervalLocked 目标的采样间隔：设置了 sample_interval 时使用目标的值，否则使用全局采样间隔（调用方需持有 mu）
+func (m *MultiMonitor) targetIntervalLocked(t types.MonitorTarget) time.Duration {
+	if t.SampleInterval > 0 {
+		return time.Duration(t.SampleInterval) * time.Second
+	}
+	return time.Duration(m.config.SampleInterval) * time.Second
+}
+
+// metricsBufferLenLocked 按采样间隔换算的指标缓冲区容量，使各目标保留的历史时长与全局间隔下的 metrics_buffer_len 大致相同，
+// 最多为 metrics_buffer_len 的 10 倍（调用方需持有 mu）
+func (m *MultiMonitor) metricsBufferLenLocked(interval time.Duration) int {
+	global := time.Duration(m.config.SampleInterval) * time.Second
+	n := m.config.MetricsBufferLen
+	if interval > 0 && interval != global {
+		n = int((time.Duration(n)*global + interval - 1) / interval)
+	}
+	if n < minMetricsBufferLen {
+		n = minMetricsBufferLen
+	}
+	if max := m.config.MetricsBufferLen * 10; n > max {
+		n = max
+	}
+	return n
+}
+
+// newMetricsBufferLocked 按目标的采样间隔创建指标缓冲区（调用方需持有 mu）
+func (m *MultiMonitor) newMetricsBufferLocked(t types.MonitorTarget) *buffer.RingBuffer[types.ProcessMetrics] {
+	return buffer.NewRingBuffer[types.ProcessMetrics](m.metricsBufferLenLocked(m.targetIntervalLocked(t)))
+}
+
+// resizeMetricsBufferLocked 采样间隔变化后按新间隔调整目标的指标缓冲区容量，保留最近的指标（调用方需持有 mu）
+func (m *MultiMonitor) resizeMetricsBufferLocked(pid int32) {
+	state, ok := m.targets[pid]
+	old := m.metricsBuffers[pid]
+	if !ok || old == nil {
+		return
+	}
+	size := m.metricsBufferLenLocked(m.targetIntervalLocked(state.target))
+	if old.Cap() == size {
+		return
+	}
+	buf := buffer.NewRingBuffer[types.ProcessMetrics](size)
+	for _, metric := range old.GetRecent(size) {
+		buf.Push(metric)
+	}
+	m.metricsBuffers[pid] = buf
+}
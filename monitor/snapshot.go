@@ -0,0 +1,91 @@
+package monitor
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"monitor-agent/logger"
+	"monitor-agent/types"
+)
+
+// redactedValue 命中 snapshot_env_deny 的环境变量在快照中的值
+const redactedValue = "[redacted]"
+
+// captureSnapshot 读取目标启动快照并按配置过滤环境变量，读取失败时返回 nil
+func (m *MultiMonitor) captureSnapshot(pid int32) *types.LaunchSnapshot {
+	snap, err := m.provider.GetLaunchSnapshot(pid)
+	if err != nil {
+		logger.Warnf("MONITOR", "Capture launch snapshot of PID %d failed: %v", pid, err)
+		return nil
+	}
+	snap.Env = filterEnv(snap.Env, m.config.SnapshotEnvAllow, m.config.SnapshotEnvDeny)
+	return snap
+}
+
+// filterEnv 只保留 allow 匹配的变量（allow 为空时保留全部），deny 匹配的变量保留名称、隐藏值
+func filterEnv(env map[string]string, allow, deny []string) map[string]string {
+	if env == nil {
+		return nil
+	}
+	result := make(map[string]string, len(env))
+	for k, v := range env {
+		if len(allow) > 0 && !matchEnvName(k, allow) {
+			continue
+		}
+		if matchEnvName(k, deny) {
+			v = redactedValue
+		}
+		result[k] = v
+	}
+	return result
+}
+
+// matchEnvName 环境变量名是否匹配任一通配符（不区分大小写）
+func matchEnvName(name string, patterns []string) bool {
+	upper := strings.ToUpper(name)
+	for _, p := range patterns {
+		if ok, _ := path.Match(strings.ToUpper(p), upper); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// snapshotKey 关联同一目标不同实例的快照（进程重启后按名称重新添加时 PID 会变化）
+func snapshotKey(t types.MonitorTarget) string {
+	return t.Name + "\x00" + t.Alias
+}
+
+// recordSnapshotLocked 保存目标快照，并附上同名目标上一个实例的快照用于对比（调用方需持有 mu）
+func (m *MultiMonitor) recordSnapshotLocked(state *targetState, snap *types.LaunchSnapshot) {
+	key := snapshotKey(state.target)
+	if prev, ok := m.lastSnapshots[key]; ok && prev.PID != snap.PID {
+		p := *prev
+		p.Previous = nil
+		snap.Previous = &p
+	}
+	state.snapshot = snap
+	m.lastSnapshots[key] = snap
+}
+
+// logSnapshot 将快照完整写入一条 EVENT 日志，供事后分析
+func logSnapshot(snap *types.LaunchSnapshot) {
+	msg := fmt.Sprintf("launch snapshot captured: ppid=%d cwd=%s env=%d", snap.PPID, snap.Cwd, len(snap.Env))
+	if snap.Previous != nil {
+		msg += fmt.Sprintf(" previous_pid=%d", snap.Previous.PID)
+	}
+	logger.EventWithData("launch_snapshot", snap.PID, snap.Name, msg, snap)
+}
+
+// GetLaunchSnapshot 获取目标启动快照（含同名目标上一个实例的快照）
+func (m *MultiMonitor) GetLaunchSnapshot(pid int32) (*types.LaunchSnapshot, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	state, ok := m.targets[pid]
+	if !ok || state.snapshot == nil {
+		return nil, false
+	}
+	snap := *state.snapshot
+	return &snap, true
+}
@@ -0,0 +1,144 @@
+package monitor
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"monitor-agent/format"
+	"monitor-agent/i18n"
+	"monitor-agent/types"
+)
+
+const (
+	// 指标异常判定：偏离窗口均值超过 anomalyStdDevs 个标准差，且绝对偏差足够大
+	anomalyStdDevs     = 2.0
+	anomalyMinCPUDelta = 10.0 // CPU 至少偏离 10 个百分点
+	anomalyMinRSSRatio = 0.1  // 内存至少偏离均值的 10%
+	anomalyMinSamples  = 5    // 样本过少时不做异常判定
+
+	defaultTimelineLimit = 100
+)
+
+// GetTimeline 获取单个监控目标的时间线
+// 合并 [from, to] 内的指标异常、该 PID 的事件、以该 PID 为目标的影响事件，按时间升序分页返回。
+// from/to 为零值时不限制；cursor 为上一页返回的 NextCursor。
+func (m *MultiMonitor) GetTimeline(pid int32, from, to time.Time, cursor, limit int) types.TimelinePage {
+	inWindow := func(t time.Time) bool {
+		return (from.IsZero() || !t.Before(from)) && (to.IsZero() || !t.After(to))
+	}
+
+	var items []types.TimelineItem
+
+	// 指标异常
+	var samples []types.ProcessMetrics
+	for _, metric := range m.GetMetrics(pid, 0) {
+		if inWindow(metric.Timestamp) {
+			samples = append(samples, metric)
+		}
+	}
+	items = append(items, findMetricAnomalies(samples)...)
+
+	// 事件
+	for _, evt := range m.GetEvents() {
+		if evt.PID != pid || !inWindow(evt.Timestamp) {
+			continue
+		}
+		evt := evt
+		items = append(items, types.TimelineItem{
+			Timestamp: evt.Timestamp,
+			Kind:      "event",
+			Summary:   fmt.Sprintf("[%s] %s", evt.Type, evt.Message),
+			Event:     &evt,
+		})
+	}
+
+	// 影响事件
+	for _, imp := range m.GetRecentImpacts(0, false) {
+		if imp.TargetPID != pid || !inWindow(imp.Timestamp) {
+			continue
+		}
+		imp := imp
+		items = append(items, types.TimelineItem{
+			Timestamp: imp.Timestamp,
+			Kind:      "impact",
+			Summary:   fmt.Sprintf("[%s/%s] %s", imp.ImpactType, imp.Severity, imp.Description),
+			Impact:    &imp,
+		})
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Timestamp.Before(items[j].Timestamp)
+	})
+
+	// 分页
+	if limit <= 0 {
+		limit = defaultTimelineLimit
+	}
+	if cursor < 0 {
+		cursor = 0
+	}
+	page := types.TimelinePage{PID: pid, Total: len(items), Items: []types.TimelineItem{}}
+	if cursor >= len(items) {
+		return page
+	}
+	end := cursor + limit
+	if end < len(items) {
+		page.NextCursor = end
+	} else {
+		end = len(items)
+	}
+	page.Items = items[cursor:end]
+	return page
+}
+
+// findMetricAnomalies 找出 CPU 或内存明显偏离窗口均值的采样点
+func findMetricAnomalies(samples []types.ProcessMetrics) []types.TimelineItem {
+	if len(samples) < anomalyMinSamples {
+		return nil
+	}
+
+	var cpuSum, rssSum float64
+	for _, s := range samples {
+		cpuSum += s.CPUPct
+		rssSum += float64(s.RSSBytes)
+	}
+	n := float64(len(samples))
+	cpuMean, rssMean := cpuSum/n, rssSum/n
+
+	var cpuVar, rssVar float64
+	for _, s := range samples {
+		cpuVar += (s.CPUPct - cpuMean) * (s.CPUPct - cpuMean)
+		rssVar += (float64(s.RSSBytes) - rssMean) * (float64(s.RSSBytes) - rssMean)
+	}
+	cpuStd, rssStd := math.Sqrt(cpuVar/n), math.Sqrt(rssVar/n)
+
+	var items []types.TimelineItem
+	for _, s := range samples {
+		if !s.Alive {
+			continue
+		}
+		cpuDelta := s.CPUPct - cpuMean
+		rssDelta := float64(s.RSSBytes) - rssMean
+
+		var summary string
+		switch {
+		case math.Abs(cpuDelta) > anomalyStdDevs*cpuStd && math.Abs(cpuDelta) >= anomalyMinCPUDelta:
+			summary = i18n.T("timeline.cpu", s.CPUPct, cpuMean)
+		case math.Abs(rssDelta) > anomalyStdDevs*rssStd && math.Abs(rssDelta) >= anomalyMinRSSRatio*rssMean:
+			summary = i18n.T("timeline.memory", format.Bytes(s.RSSBytes), format.Bytes(uint64(rssMean)))
+		default:
+			continue
+		}
+
+		s := s
+		items = append(items, types.TimelineItem{
+			Timestamp: s.Timestamp,
+			Kind:      "anomaly",
+			Summary:   summary,
+			Metric:    &s,
+		})
+	}
+	return items
+}
@@ -0,0 +1,75 @@
+package monitor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"monitor-agent/impact"
+	"monitor-agent/types"
+)
+
+// NormalizeTargetWatches 规范化并校验目标的监控端口、监控文件和监控目录
+// 端口必须在 1-65535 之间，重复的端口只保留一个；文件路径转换为与 FileChecker 一致的绝对路径并去重，
+// 空路径被忽略。上级目录不存在的文件仍会保留（可能稍后创建），但返回警告提示调用方确认路径；
+// 监控目录转换为绝对路径并去重，不存在或不是目录时同样保留并返回警告
+func NormalizeTargetWatches(t *types.MonitorTarget) ([]string, error) {
+	if len(t.WatchPorts) > 0 {
+		ports := make([]int, 0, len(t.WatchPorts))
+		seen := make(map[int]bool, len(t.WatchPorts))
+		for _, port := range t.WatchPorts {
+			if port < 1 || port > 65535 {
+				return nil, fmt.Errorf("invalid watch port %d (must be 1-65535)", port)
+			}
+			if !seen[port] {
+				seen[port] = true
+				ports = append(ports, port)
+			}
+		}
+		t.WatchPorts = ports
+	}
+
+	var warnings []string
+	if len(t.WatchFiles) > 0 {
+		files := make([]string, 0, len(t.WatchFiles))
+		seen := make(map[string]bool, len(t.WatchFiles))
+		for _, file := range t.WatchFiles {
+			file = impact.NormalizePath(strings.TrimSpace(file))
+			if file == "" || seen[file] {
+				continue
+			}
+			seen[file] = true
+			files = append(files, file)
+			dir := filepath.Dir(filepath.FromSlash(file))
+			if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+				warnings = append(warnings, fmt.Sprintf("watch file %s: parent directory does not exist", file))
+			}
+		}
+		t.WatchFiles = files
+	}
+
+	if len(t.WatchDirs) > 0 {
+		dirs := make([]string, 0, len(t.WatchDirs))
+		seen := make(map[string]bool, len(t.WatchDirs))
+		for _, dir := range t.WatchDirs {
+			dir = strings.TrimSpace(dir)
+			if dir == "" {
+				continue
+			}
+			if abs, err := filepath.Abs(dir); err == nil {
+				dir = abs
+			}
+			if seen[dir] {
+				continue
+			}
+			seen[dir] = true
+			dirs = append(dirs, dir)
+			if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+				warnings = append(warnings, fmt.Sprintf("watch dir %s: not an existing directory", dir))
+			}
+		}
+		t.WatchDirs = dirs
+	}
+	return warnings, nil
+}
@@ -0,0 +1,98 @@
+// Package monitoragent 嵌入 API：在其他程序（如自有的守护进程）中运行监控核心，不需要 CLI 和独立的代理进程。
+//
+//	agent, err := monitoragent.NewWithOptions(cfg, monitoragent.Options{Logger: myLogger})
+//	if err != nil { ... }
+//	agent.Start()
+//	defer agent.Stop()
+//	agent.Monitor().AddTarget(types.MonitorTarget{PID: pid, Name: "edpf_hmi"})
+//
+// 是否启动 Web 服务由 cfg.Server.Enabled 决定；未提供 Logger 时按 cfg.Logging 在日志目录写 JSONL 日志文件。
+// 事件消息语言、时区和字节单位等显示设置是进程级的，按 cfg 设置。
+package monitoragent
+
+import (
+	"fmt"
+
+	"monitor-agent/config"
+	"monitor-agent/impact"
+	"monitor-agent/logger"
+	"monitor-agent/monitor"
+	"monitor-agent/selftest"
+	"monitor-agent/service"
+)
+
+// Options 嵌入选项
+type Options struct {
+	ConfigFile string // 目标增删改后保存配置的文件，为空时不保存
+	LogDir     string // 日志和状态文件（可用率、维护窗口等）目录，为空时使用 cfg.Logging.Dir
+
+	// Logger 日志实现，为 nil 时在日志目录写 JSONL 日志文件。
+	// 实现 logger.DataSink 时同时接收每次采样的指标，实现 logger.AuditSink 时接收审计记录
+	Logger logger.Sink
+
+	CaptureStdLog bool // 把标准 log 包的输出转到日志器（只对默认日志器有效）
+	NoConsoleLog  bool // 默认日志器不向终端输出
+}
+
+// Agent 监控代理
+type Agent struct {
+	svc *service.Service
+}
+
+// New 按配置创建监控代理（不保存配置、使用默认日志器），cfg 为 nil 时使用默认配置
+func New(cfg *config.Config) (*Agent, error) {
+	return NewWithOptions(cfg, Options{})
+}
+
+// NewWithOptions 按配置和选项创建监控代理，cfg 为 nil 时使用默认配置
+func NewWithOptions(cfg *config.Config, opts Options) (*Agent, error) {
+	if cfg == nil {
+		cfg = config.DefaultConfig()
+	}
+	logDir := opts.LogDir
+	if logDir == "" {
+		logDir = cfg.Logging.Dir
+	}
+	svc, err := service.NewWithConfig(service.Config{
+		Addr:          cfg.Server.Addr,
+		LogDir:        logDir,
+		ConfigFile:    opts.ConfigFile,
+		NoConsoleLog:  opts.NoConsoleLog,
+		Logger:        opts.Logger,
+		CaptureStdLog: opts.CaptureStdLog,
+	}, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("create monitor agent: %w", err)
+	}
+	return &Agent{svc: svc}, nil
+}
+
+// Start 加载配置中的目标并开始监控（auto_start 关闭时等待手动启动），按配置启动 Web 服务
+func (a *Agent) Start() error {
+	return a.svc.Start()
+}
+
+// Stop 停止监控、Web 服务和后台采样协程，关闭默认日志器
+func (a *Agent) Stop() error {
+	return a.svc.Stop()
+}
+
+// Wait 阻塞到代理停止
+func (a *Agent) Wait() {
+	a.svc.Wait()
+}
+
+// Monitor 监控器：增删目标、查询指标和事件
+func (a *Agent) Monitor() *monitor.MultiMonitor {
+	return a.svc.GetMonitor()
+}
+
+// SelfTest 启动自检结果（Start 之后有效）：各类指标能否读取、日志目录能否写入
+func (a *Agent) SelfTest() selftest.Report {
+	return a.svc.SelfTest()
+}
+
+// ImpactAnalyzer 影响分析器，配置中关闭影响分析时为 nil
+func (a *Agent) ImpactAnalyzer() *impact.ImpactAnalyzer {
+	return a.svc.GetMonitor().GetImpactAnalyzer()
+}
@@ -1,13 +1,24 @@
 package netmon
 
 import (
+	"errors"
+	"fmt"
 	"log"
+	"path"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/shirou/gopsutil/v3/net"
 )
 
+// Mode 进程流量的统计方式：不抓包，按各进程连接数比例分配网卡总流量（估算值）
+const Mode = "conn-share"
+
+// ErrAlreadyRunning 网络监控已在运行时 Start 返回的错误
+var ErrAlreadyRunning = errors.New("network monitor is already running")
+
 // ProcessNetStats 进程网络统计
 type ProcessNetStats struct {
 	RecvBytes uint64
@@ -24,9 +35,53 @@ type SystemNetStats struct {
 	SendRate  float64
 }
 
+// Options 网络监控选项，均为空时统计所有网卡的合计流量
+// 网卡名支持通配符（path.Match 语法），如 "eth0"、"ens*"、"*.100"
+type Options struct {
+	Interfaces        []string // 只统计匹配的网卡（白名单）
+	ExcludeInterfaces []string // 排除匹配的网卡（黑名单，优先于白名单），如 lo、VLAN 子接口
+}
+
+// enabled 是否配置了网卡过滤
+func (o Options) enabled() bool {
+	return len(o.Interfaces) > 0 || len(o.ExcludeInterfaces) > 0
+}
+
+// validate 校验通配符格式
+func (o Options) validate() error {
+	for _, p := range append(append([]string{}, o.Interfaces...), o.ExcludeInterfaces...) {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("invalid interface pattern %q: %w", p, err)
+		}
+	}
+	return nil
+}
+
+// match 网卡是否参与统计
+func (o Options) match(name string) bool {
+	for _, p := range o.ExcludeInterfaces {
+		if ok, _ := path.Match(p, name); ok {
+			return false
+		}
+	}
+	if len(o.Interfaces) == 0 {
+		return true
+	}
+	for _, p := range o.Interfaces {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
 // NetMonitor 网络流量监控器
 type NetMonitor struct {
-	mu sync.RWMutex
+	mu   sync.RWMutex
+	opts Options
+
+	// 当前参与统计的网卡（配置了过滤时），变化时记录日志
+	selected string
 
 	// 进程网络统计
 	stats map[int32]*processNetSample
@@ -46,6 +101,10 @@ type NetMonitor struct {
 	// 运行状态
 	running bool
 	stopCh  chan struct{}
+
+	// 启停：lifecycleMu 串行化 Start/Stop，Stop 等待采集协程退出后返回
+	lifecycleMu sync.Mutex
+	loopWG      sync.WaitGroup
 }
 
 type processNetSample struct {
@@ -63,34 +122,85 @@ type systemNetSample struct {
 }
 
 // New 创建网络监控器
-func New() *NetMonitor {
+func New(opts Options) *NetMonitor {
 	return &NetMonitor{
+		opts:          opts,
 		stats:         make(map[int32]*processNetSample),
 		sysStats:      &systemNetSample{},
 		procConnCount: make(map[int32]int),
-		stopCh:        make(chan struct{}),
 	}
 }
 
-// Start 启动网络监控
+// Start 启动网络监控，已在运行时返回 ErrAlreadyRunning
+// 配置了网卡过滤时校验通配符并检查至少匹配一个网卡，失败返回错误且不启动
 func (m *NetMonitor) Start() error {
+	m.lifecycleMu.Lock()
+	defer m.lifecycleMu.Unlock()
+
+	if m.opts.enabled() {
+		if err := m.opts.validate(); err != nil {
+			return err
+		}
+		counters, err := net.IOCounters(true)
+		if err != nil {
+			return fmt.Errorf("list interfaces: %w", err)
+		}
+		if names := m.selectInterfaces(counters); len(names) == 0 {
+			return fmt.Errorf("no interface matches interfaces=%v exclude_interfaces=%v",
+				m.opts.Interfaces, m.opts.ExcludeInterfaces)
+		}
+	}
+
 	m.mu.Lock()
 	if m.running {
 		m.mu.Unlock()
-		return nil
+		return ErrAlreadyRunning
 	}
 	m.running = true
 	m.stopCh = make(chan struct{})
+	stopCh := m.stopCh
+	m.loopWG.Add(1)
 	m.mu.Unlock()
 
-	go m.collectLoop()
+	go func() {
+		defer m.loopWG.Done()
+		m.collectLoop(stopCh)
+	}()
 
-	log.Printf("[NetMon] 网络监控已启动（gopsutil）")
+	if m.opts.enabled() {
+		log.Printf("[NetMon] 网络监控已启动（gopsutil），统计网卡: %s", m.selected)
+	} else {
+		log.Printf("[NetMon] 网络监控已启动（gopsutil），统计所有网卡")
+	}
 	return nil
 }
 
-// Stop 停止网络监控
+// selectInterfaces 返回参与统计的网卡名（排序），并在选择结果变化时记录
+func (m *NetMonitor) selectInterfaces(counters []net.IOCountersStat) []string {
+	var names []string
+	for _, c := range counters {
+		if m.opts.match(c.Name) {
+			names = append(names, c.Name)
+		}
+	}
+	sort.Strings(names)
+
+	selected := strings.Join(names, ", ")
+	m.mu.Lock()
+	changed := m.selected != "" && m.selected != selected
+	m.selected = selected
+	m.mu.Unlock()
+	if changed {
+		log.Printf("[NetMon] 统计网卡变化: %s", selected)
+	}
+	return names
+}
+
+// Stop 停止网络监控，等待采集协程退出后返回；未运行时直接返回
 func (m *NetMonitor) Stop() {
+	m.lifecycleMu.Lock()
+	defer m.lifecycleMu.Unlock()
+
 	m.mu.Lock()
 	if !m.running {
 		m.mu.Unlock()
@@ -99,6 +209,9 @@ func (m *NetMonitor) Stop() {
 	m.running = false
 	close(m.stopCh)
 	m.mu.Unlock()
+
+	// 采集过程需要 mu，等待时不能持有 mu
+	m.loopWG.Wait()
 }
 
 // GetStats 获取进程网络统计
@@ -167,14 +280,14 @@ func (m *NetMonitor) CleanupPids(alivePids map[int32]bool) {
 	}
 }
 
-// collectLoop 采集循环
-func (m *NetMonitor) collectLoop() {
+// collectLoop 采集循环，stopCh 为本次运行的停止通道（Start 会替换 m.stopCh，不能在循环中读取字段）
+func (m *NetMonitor) collectLoop(stopCh chan struct{}) {
 	ticker := time.NewTicker(time.Second)
 	defer ticker.Stop()
 
 	for {
 		select {
-		case <-m.stopCh:
+		case <-stopCh:
 			return
 		case <-ticker.C:
 			m.collect()
@@ -184,11 +297,24 @@ func (m *NetMonitor) collectLoop() {
 
 // collect 采集一次数据
 func (m *NetMonitor) collect() {
-	// 获取系统网络统计
-	counters, err := net.IOCounters(false)
+	// 获取系统网络统计（配置了网卡过滤时按网卡采集后只累加选中的网卡）
+	counters, err := net.IOCounters(m.opts.enabled())
 	if err != nil || len(counters) == 0 {
 		return
 	}
+	if m.opts.enabled() {
+		selected := make(map[string]bool)
+		for _, name := range m.selectInterfaces(counters) {
+			selected[name] = true
+		}
+		filtered := counters[:0]
+		for _, c := range counters {
+			if selected[c.Name] {
+				filtered = append(filtered, c)
+			}
+		}
+		counters = filtered
+	}
 
 	var totalRecv, totalSend uint64
 	for _, c := range counters {
@@ -0,0 +1,66 @@
+package netmon
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+// 并发反复启停网络监控：需配合 -race 运行，结束后不遗留采集协程、不死锁
+func TestConcurrentStartStop(t *testing.T) {
+	base := runtime.NumGoroutine()
+	m := New(Options{})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		var wg sync.WaitGroup
+		for w := 0; w < 8; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				for i := 0; i < 50; i++ {
+					if (i+w)%2 == 0 {
+						if err := m.Start(); err != nil && err != ErrAlreadyRunning {
+							t.Errorf("Start: %v", err)
+						}
+					} else {
+						m.Stop()
+					}
+					m.IsRunning()
+					m.GetSystemStats()
+				}
+			}(w)
+		}
+		wg.Wait()
+		m.Stop()
+	}()
+	select {
+	case <-done:
+	case <-time.After(30 * time.Second):
+		buf := make([]byte, 1<<20)
+		t.Fatalf("start/stop hung\n%s", buf[:runtime.Stack(buf, true)])
+	}
+
+	if m.IsRunning() {
+		t.Fatal("running after Stop")
+	}
+	if err := m.Start(); err != nil {
+		t.Fatalf("Start after Stop: %v", err)
+	}
+	if err := m.Start(); err != ErrAlreadyRunning {
+		t.Fatalf("second Start = %v, want ErrAlreadyRunning", err)
+	}
+	time.Sleep(1200 * time.Millisecond)
+	m.Stop()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > base {
+		if time.Now().After(deadline) {
+			buf := make([]byte, 1<<20)
+			t.Fatalf("goroutines = %d, want <= %d\n%s", runtime.NumGoroutine(), base, buf[:runtime.Stack(buf, true)])
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,548 @@
+package profile
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"monitor-agent/buildinfo"
+	"monitor-agent/config"
+	"monitor-agent/format"
+	"monitor-agent/impact"
+	"monitor-agent/logger"
+	"monitor-agent/monitor"
+	"monitor-agent/provider"
+	"monitor-agent/timefmt"
+	"monitor-agent/types"
+)
+
+// FormatVersion 配置档案格式版本，结构不兼容变化时递增
+const FormatVersion = 1
+
+// Document 完整的监控配置档案，用于在冗余服务器之间迁移监控配置
+// 目标按进程名记录（不含 PID），导入时按名称匹配本机进程；
+// Web 服务地址、只读等与主机相关的 server 配置不在档案中
+type Document struct {
+	Version      int                   `json:"version"`
+	AgentVersion string                `json:"agent_version"`
+	ExportedAt   time.Time             `json:"exported_at"`
+	Host         string                `json:"host,omitempty"`
+	Targets      []types.MonitorTarget `json:"targets"`
+	Impact       types.ImpactConfig    `json:"impact"`
+	Sampling     config.SamplingConfig `json:"sampling"`
+	Logging      config.LoggingConfig  `json:"logging"`
+	Display      config.DisplayConfig  `json:"display"`
+}
+
+// Change 导入时的一项变更
+type Change struct {
+	Action string `json:"action"` // add/update/remove/skip/set
+	Target string `json:"target,omitempty"`
+	PID    int32  `json:"pid,omitempty"`
+	Detail string `json:"detail"`
+}
+
+// Plan 导入计划，由 NewPlan 根据档案与当前运行状态计算
+type Plan struct {
+	Changes  []Change `json:"changes"`
+	Warnings []string `json:"warnings,omitempty"`
+
+	doc     *Document
+	adds    []types.MonitorTarget
+	updates []targetUpdate
+	removes []types.MonitorTarget
+}
+
+type targetUpdate struct {
+	old, new types.MonitorTarget
+}
+
+// Export 导出当前运行中的监控配置
+func Export(cfg *config.Config, mm *monitor.MultiMonitor) Document {
+	host, _ := os.Hostname()
+	doc := Document{
+		Version:      FormatVersion,
+		AgentVersion: buildinfo.Version,
+		ExportedAt:   time.Now(),
+		Host:         host,
+		Targets:      []types.MonitorTarget{},
+		Impact:       cfg.Impact,
+		Sampling:     cfg.Sampling,
+		Logging:      cfg.Logging,
+		Display:      cfg.Display,
+	}
+	for _, t := range mm.GetTargets() {
+		t.PID = 0
+		t.Cmdline = ""
+		t.RestartCommand, t.RestartPolicy = "", nil
+		doc.Targets = append(doc.Targets, t)
+	}
+	return doc
+}
+
+// Parse 解析配置档案
+// 档案中缺少的配置项沿用 cfg 中的当前值；targets 与 impact.profiles 以档案为准
+func Parse(data []byte, cfg *config.Config) (*Document, error) {
+	doc := &Document{
+		Impact:   cfg.Impact,
+		Sampling: cfg.Sampling,
+		Logging:  cfg.Logging,
+		Display:  cfg.Display,
+	}
+	doc.Impact.Profiles = nil
+	if err := json.Unmarshal(data, doc); err != nil {
+		return nil, fmt.Errorf("parse profile: %w", err)
+	}
+	if doc.Version == 0 {
+		return nil, fmt.Errorf("not a monitor profile: missing version")
+	}
+	if doc.Version > FormatVersion {
+		return nil, fmt.Errorf("profile version %d is newer than supported version %d", doc.Version, FormatVersion)
+	}
+	return doc, nil
+}
+
+// Validate 校验档案内容，返回不影响导入的警告
+func (doc *Document) Validate() (warnings []string, err error) {
+	s := doc.Sampling
+	if s.Interval < 1 {
+		return nil, fmt.Errorf("sampling.interval must be at least 1")
+	}
+	if s.MetricsBufferLen <= 0 || s.EventsBufferLen <= 0 {
+		return nil, fmt.Errorf("sampling buffer lengths must be positive")
+	}
+	if s.EventDedupWindow < 0 || s.EventRateLimit < 0 {
+		return nil, fmt.Errorf("sampling.event_dedup_window and event_rate_limit must not be negative")
+	}
+	if s.CPUStyle != "" && s.CPUStyle != provider.CPUStyleIrix && s.CPUStyle != provider.CPUStyleSolaris {
+		return nil, fmt.Errorf("sampling.cpu_style: unknown style %q", s.CPUStyle)
+	}
+	if s.CollectWorkers < 0 || s.CollectWorkers > provider.MaxCollectWorkers {
+		return nil, fmt.Errorf("sampling.collect_workers must be between 0 and %d", provider.MaxCollectWorkers)
+	}
+	if s.SampleJitterMS < 0 {
+		return nil, fmt.Errorf("sampling.sample_jitter_ms must not be negative")
+	}
+
+	switch strings.ToLower(doc.Logging.Level) {
+	case "", "debug", "info", "warn", "error":
+	default:
+		return nil, fmt.Errorf("logging.level: unknown level %q", doc.Logging.Level)
+	}
+	if doc.Logging.TimeZone != "" {
+		if _, err := time.LoadLocation(doc.Logging.TimeZone); err != nil {
+			return nil, fmt.Errorf("logging.time_zone: %v", err)
+		}
+	}
+	if sl := doc.Logging.Syslog; sl.Enabled {
+		if err := logger.ValidateSyslog(sl.Options()); err != nil {
+			return nil, fmt.Errorf("logging.syslog: %v", err)
+		}
+	}
+
+	if err := validateImpact(doc.Impact); err != nil {
+		return nil, err
+	}
+	if warnings, err = impact.ValidateProfiles(doc.Impact.Profiles); err != nil {
+		return nil, fmt.Errorf("impact.profiles: %w", err)
+	}
+
+	d := doc.Display
+	if d.TopHighlightWarn < 0 || d.TopHighlightWarn > d.TopHighlightCrit || d.TopHighlightMemMB < 0 {
+		return nil, fmt.Errorf("display: top_highlight_warn must be between 0 and top_highlight_crit")
+	}
+	switch d.ByteUnits {
+	case format.UnitsDefault, format.UnitsBinary, format.UnitsDecimal:
+	default:
+		return nil, fmt.Errorf("display.byte_units: must be binary or decimal, got %q", d.ByteUnits)
+	}
+	if d.Precision != nil && (*d.Precision < 0 || *d.Precision > config.MaxPrecision) {
+		return nil, fmt.Errorf("display.precision must be between 0 and %d", config.MaxPrecision)
+	}
+	for _, name := range d.TableColumns {
+		if !config.IsTableColumn(name) {
+			warnings = append(warnings, fmt.Sprintf("display.table_columns: unknown column %q ignored", name))
+		}
+	}
+
+	if err := validateTargets(doc.Targets); err != nil {
+		return nil, err
+	}
+	return warnings, nil
+}
+
+// validateTargets 校验目标列表：名称必填，监控端口有效，自定义阈值不能为负，处置说明和运行手册链接有效；
+// 去掉目标中的重启命令和重启策略
+func validateTargets(targets []types.MonitorTarget) error {
+	for i, t := range targets {
+		if t.Name == "" {
+			return fmt.Errorf("targets[%d]: name is required", i)
+		}
+		for _, port := range t.WatchPorts {
+			if port < 1 || port > 65535 {
+				return fmt.Errorf("target %q: invalid watch port %d", t.Name, port)
+			}
+		}
+		if t.Thresholds != nil {
+			if err := validateOverrides(*t.Thresholds); err != nil {
+				return fmt.Errorf("target %q: %w", t.Name, err)
+			}
+		}
+		if err := monitor.NormalizeTargetNotes(&targets[i]); err != nil {
+			return fmt.Errorf("target %q: %w", t.Name, err)
+		}
+		// 重启命令与主机相关且会被代理执行，不随档案导入
+		targets[i].RestartCommand, targets[i].RestartPolicy = "", nil
+	}
+	return nil
+}
+
+func validateImpact(c types.ImpactConfig) error {
+	if c.CPUThreshold <= 0 || c.MemoryThreshold <= 0 || c.DiskIOThreshold <= 0 || c.NetworkThreshold <= 0 {
+		return fmt.Errorf("impact: system thresholds must be positive")
+	}
+	if c.AnalysisInterval <= 0 || c.TopNProcesses <= 0 || c.HistoryLen <= 0 {
+		return fmt.Errorf("impact: analysis_interval, top_n_processes and history_len must be positive")
+	}
+	if c.AnalysisWorkers < 0 || c.AnalysisWorkers > impact.MaxAnalysisWorkers || c.SkipWarnCycles < 0 {
+		return fmt.Errorf("impact: analysis_workers must be between 0 and %d, skip_warn_cycles must not be negative", impact.MaxAnalysisWorkers)
+	}
+	if c.ScoreWeightCritical <= 0 || c.ScoreWeightHigh <= 0 || c.ScoreWeightMedium <= 0 || c.ScoreWeightLow <= 0 {
+		return fmt.Errorf("impact: score weights must be positive")
+	}
+	if c.StealThreshold < 0 || c.StealThreshold > 100 {
+		return fmt.Errorf("impact: steal_threshold must be between 0 and 100")
+	}
+	if c.VirtRunWaitThreshold < 0 {
+		return fmt.Errorf("impact: virt_run_wait_threshold must not be negative")
+	}
+	if c.ChurnThreshold < 0 {
+		return fmt.Errorf("impact: churn_threshold must not be negative")
+	}
+	if c.ZombieThreshold < 0 {
+		return fmt.Errorf("impact: zombie_threshold must not be negative")
+	}
+	if c.TrendWindowSeconds < 0 || c.TrendHorizonMinutes < 0 {
+		return fmt.Errorf("impact: trend_window_seconds and trend_horizon_minutes must not be negative")
+	}
+	if c.TrendLimitPercent < 0 || c.TrendLimitPercent > 100 {
+		return fmt.Errorf("impact: trend_limit_percent must be between 0 and 100")
+	}
+	if err := impact.ValidateDurations(c); err != nil {
+		return fmt.Errorf("impact: %w", err)
+	}
+	o := types.ThresholdOverrides{
+		CPUCoreThreshold:       &c.CPUCoreThreshold,
+		ProcCPUThreshold:       &c.ProcCPUThreshold,
+		ProcMemoryThreshold:    &c.ProcMemoryThreshold,
+		ProcMemGrowthThreshold: &c.ProcMemGrowthThreshold,
+		ProcVMSThreshold:       &c.ProcVMSThreshold,
+		ProcFDsThreshold:       &c.ProcFDsThreshold,
+		ProcFDGrowthThreshold:  &c.ProcFDGrowthThreshold,
+		ProcThreadsThreshold:   &c.ProcThreadsThreshold,
+		ProcOpenFilesThreshold: &c.ProcOpenFilesThreshold,
+		ProcDiskReadThreshold:  &c.ProcDiskReadThreshold,
+		ProcDiskWriteThreshold: &c.ProcDiskWriteThreshold,
+		ProcNetRecvThreshold:   &c.ProcNetRecvThreshold,
+		ProcNetSendThreshold:   &c.ProcNetSendThreshold,
+		DirGrowthThreshold:     &c.DirGrowthThreshold,
+		DirMinFreeMB:           &c.DirMinFreeMB,
+	}
+	if err := validateOverrides(o); err != nil {
+		return fmt.Errorf("impact: %w", err)
+	}
+	return nil
+}
+
+// validateOverrides 系统级阈值设置时必须大于 0，其余阈值不能为负（0 表示禁用）
+func validateOverrides(o types.ThresholdOverrides) error {
+	for _, v := range []*float64{o.CPUThreshold, o.MemoryThreshold, o.DiskIOThreshold, o.NetworkThreshold} {
+		if v != nil && *v <= 0 {
+			return fmt.Errorf("system thresholds must be positive")
+		}
+	}
+	for _, v := range []*float64{o.CPUCoreThreshold, o.ProcCPUThreshold, o.ProcMemoryThreshold, o.ProcMemGrowthThreshold,
+		o.ProcVMSThreshold, o.ProcFDGrowthThreshold, o.ProcDiskReadThreshold, o.ProcDiskWriteThreshold, o.ProcNetRecvThreshold, o.ProcNetSendThreshold,
+		o.DirGrowthThreshold, o.DirMinFreeMB} {
+		if v != nil && *v < 0 {
+			return fmt.Errorf("thresholds must not be negative")
+		}
+	}
+	for _, v := range []*int{o.ProcFDsThreshold, o.ProcThreadsThreshold, o.ProcOpenFilesThreshold} {
+		if v != nil && *v < 0 {
+			return fmt.Errorf("thresholds must not be negative")
+		}
+	}
+	return nil
+}
+
+// NewPlan 校验档案并计算导入计划，不修改任何状态
+// 档案中的目标按名称依次匹配：同名目标已在监控则更新，否则添加本机同名进程（按 PID 顺序），
+// 本机未运行的跳过；当前监控中但档案里没有的目标将被移除
+func NewPlan(doc *Document, cfg *config.Config, mm *monitor.MultiMonitor) (*Plan, error) {
+	warnings, err := doc.Validate()
+	if err != nil {
+		return nil, err
+	}
+	plan := &Plan{Changes: []Change{}, Warnings: warnings, doc: doc}
+
+	processes, err := mm.ListAllProcesses()
+	if err != nil {
+		return nil, fmt.Errorf("list processes: %w", err)
+	}
+	sort.Slice(processes, func(i, j int) bool { return processes[i].PID < processes[j].PID })
+
+	current := mm.GetTargets()
+	monitored := make(map[int32]bool, len(current))
+	currentByName := make(map[string][]types.MonitorTarget)
+	for _, t := range current {
+		monitored[t.PID] = true
+		currentByName[t.Name] = append(currentByName[t.Name], t)
+	}
+	procsByName := make(map[string][]types.ProcessInfo)
+	for _, p := range processes {
+		if !monitored[p.PID] {
+			procsByName[p.Name] = append(procsByName[p.Name], p)
+		}
+	}
+
+	matched := make(map[int32]bool)
+	for _, t := range doc.Targets {
+		if list := currentByName[t.Name]; len(list) > 0 {
+			old := list[0]
+			currentByName[t.Name] = list[1:]
+			matched[old.PID] = true
+
+			t.PID, t.Cmdline = old.PID, old.Cmdline
+			t.RestartCommand, t.RestartPolicy = old.RestartCommand, old.RestartPolicy
+			if fields := targetDiff(old, t); len(fields) > 0 {
+				plan.updates = append(plan.updates, targetUpdate{old: old, new: t})
+				plan.Changes = append(plan.Changes, Change{Action: "update", Target: t.Name, PID: t.PID,
+					Detail: "修改 " + strings.Join(fields, ", ")})
+			}
+			continue
+		}
+
+		if list := procsByName[t.Name]; len(list) > 0 {
+			proc := list[0]
+			procsByName[t.Name] = list[1:]
+			t.PID, t.Cmdline = proc.PID, proc.Cmdline
+			plan.adds = append(plan.adds, t)
+			plan.Changes = append(plan.Changes, Change{Action: "add", Target: t.Name, PID: t.PID, Detail: "添加监控目标"})
+			continue
+		}
+
+		plan.Changes = append(plan.Changes, Change{Action: "skip", Target: t.Name, Detail: "本机未运行该进程，跳过"})
+		plan.Warnings = append(plan.Warnings, fmt.Sprintf("process %q is not running, target skipped", t.Name))
+	}
+
+	for _, t := range current {
+		if !matched[t.PID] {
+			plan.removes = append(plan.removes, t)
+			plan.Changes = append(plan.Changes, Change{Action: "remove", Target: t.Name, PID: t.PID, Detail: "档案中无此目标，移除"})
+		}
+	}
+
+	for _, d := range sectionDiff("impact", cfg.Impact, doc.Impact) {
+		plan.Changes = append(plan.Changes, Change{Action: "set", Detail: d})
+	}
+	for _, d := range sectionDiff("sampling", cfg.Sampling, doc.Sampling) {
+		plan.Changes = append(plan.Changes, Change{Action: "set", Detail: d})
+	}
+	for _, d := range sectionDiff("logging", cfg.Logging, doc.Logging) {
+		plan.Changes = append(plan.Changes, Change{Action: "set", Detail: d})
+	}
+	for _, d := range sectionDiff("display", cfg.Display, doc.Display) {
+		plan.Changes = append(plan.Changes, Change{Action: "set", Detail: d})
+	}
+
+	// 启动时才读取的配置项
+	restart := func(changed bool, name string) {
+		if changed {
+			plan.Warnings = append(plan.Warnings, name+" takes effect after restart")
+		}
+	}
+	restart(cfg.Sampling.MetricsBufferLen != doc.Sampling.MetricsBufferLen ||
+		cfg.Sampling.EventsBufferLen != doc.Sampling.EventsBufferLen, "sampling buffer length")
+	restart(cfg.Sampling.EventDedupWindow != doc.Sampling.EventDedupWindow ||
+		cfg.Sampling.EventRateLimit != doc.Sampling.EventRateLimit, "sampling event dedup/rate limit")
+	restart(cfg.Sampling.StripExeSuffix != doc.Sampling.StripExeSuffix, "sampling.strip_exe_suffix")
+	restart(cfg.Sampling.CollectWorkers != doc.Sampling.CollectWorkers, "sampling.collect_workers")
+	restart(cfg.Sampling.SampleJitterMS != doc.Sampling.SampleJitterMS ||
+		cfg.Sampling.SampleJitterEachTick != doc.Sampling.SampleJitterEachTick, "sampling sample jitter")
+	restart(cfg.Sampling.BinaryCheckInterval != doc.Sampling.BinaryCheckInterval ||
+		cfg.Sampling.BinaryHash != doc.Sampling.BinaryHash, "sampling binary check")
+	restart(cfg.Logging.Dir != doc.Logging.Dir || cfg.Logging.Level != doc.Logging.Level ||
+		cfg.Logging.FileOutput != doc.Logging.FileOutput || cfg.Logging.EventsToConsole != doc.Logging.EventsToConsole, "logging")
+	restart(!jsonEqual(cfg.Logging.Syslog, doc.Logging.Syslog), "logging.syslog")
+	restart(doc.Impact.Enabled && mm.GetImpactAnalyzer() == nil, "impact.enabled")
+
+	return plan, nil
+}
+
+// Apply 执行导入计划
+// 目标变更逐项执行，任一步失败时撤销已执行的变更并返回错误，配置不做修改；
+// 全部成功后更新 cfg 并使采样间隔、CPU 口径、影响分析配置和进程显示名称立即生效（保存文件由调用方负责）
+func (plan *Plan) Apply(cfg *config.Config, mm *monitor.MultiMonitor) error {
+	var undo []func()
+	rollback := func() {
+		for i := len(undo) - 1; i >= 0; i-- {
+			undo[i]()
+		}
+	}
+
+	// 添加最可能失败（进程在计划后退出），放在最前面。
+	// 计划已按进程名与现有目标一一对应，多出的同名目标是档案中有意的多个实例
+	for _, t := range plan.adds {
+		if err := mm.ForceAddTarget(t); err != nil {
+			rollback()
+			return fmt.Errorf("add target %s (PID %d): %w", t.Name, t.PID, err)
+		}
+		pid := t.PID
+		undo = append(undo, func() { mm.RemoveTarget(pid) })
+	}
+	for _, u := range plan.updates {
+		if err := mm.UpdateTarget(u.new); err != nil {
+			rollback()
+			return fmt.Errorf("update target %s (PID %d): %w", u.new.Name, u.new.PID, err)
+		}
+		old := u.old
+		undo = append(undo, func() {
+			if err := mm.UpdateTarget(old); err != nil {
+				logger.Warnf("PROFILE", "Rollback update of PID %d failed: %v", old.PID, err)
+			}
+		})
+	}
+
+	doc := plan.doc
+	if oldStyle := mm.GetCPUStyle(); doc.Sampling.CPUStyle != "" && doc.Sampling.CPUStyle != oldStyle {
+		if err := mm.SetCPUStyle(doc.Sampling.CPUStyle); err != nil {
+			rollback()
+			return fmt.Errorf("set cpu style: %w", err)
+		}
+		undo = append(undo, func() { mm.SetCPUStyle(oldStyle) })
+	}
+	if doc.Sampling.Interval != cfg.Sampling.Interval {
+		if err := mm.SetSampleInterval(doc.Sampling.Interval); err != nil {
+			rollback()
+			return fmt.Errorf("set sample interval: %w", err)
+		}
+	}
+
+	// 移除不可能失败，放在最后
+	for _, t := range plan.removes {
+		mm.RemoveTarget(t.PID)
+	}
+
+	cfg.Impact = doc.Impact
+	cfg.Sampling = doc.Sampling
+	cfg.Logging = doc.Logging
+	cfg.Display = doc.Display
+	cfg.Targets = mm.GetTargets()
+	if analyzer := mm.GetImpactAnalyzer(); analyzer != nil {
+		analyzer.UpdateConfig(cfg.Impact)
+	}
+	mm.SetNameAliases(cfg.Display.NameAliases)
+	logger.SetConsoleOutput(cfg.Logging.ConsoleOutput)
+	timefmt.Configure(cfg.Logging.TimeZone, cfg.Logging.TimeFormat)
+	format.SetByteUnits(cfg.Display.ByteUnits)
+
+	logger.Infof("PROFILE", "Profile imported: %d added, %d updated, %d removed",
+		len(plan.adds), len(plan.updates), len(plan.removes))
+	return nil
+}
+
+// targetDiff 返回两个目标配置中不同的字段
+func targetDiff(a, b types.MonitorTarget) []string {
+	var fields []string
+	if a.Alias != b.Alias {
+		fields = append(fields, "alias")
+	}
+	if a.Group != b.Group {
+		fields = append(fields, "group")
+	}
+	if !jsonEqual(a.WatchPorts, b.WatchPorts) {
+		fields = append(fields, "watch_ports")
+	}
+	if !jsonEqual(a.WatchFiles, b.WatchFiles) {
+		fields = append(fields, "watch_files")
+	}
+	if !jsonEqual(a.WatchDirs, b.WatchDirs) {
+		fields = append(fields, "watch_dirs")
+	}
+	if a.SampleInterval != b.SampleInterval {
+		fields = append(fields, "sample_interval")
+	}
+	if !jsonEqual(a.Thresholds, b.Thresholds) {
+		fields = append(fields, "thresholds")
+	}
+	if !jsonEqual(a.ExpectedPriority, b.ExpectedPriority) {
+		fields = append(fields, "expected_priority")
+	}
+	if a.Notes != b.Notes {
+		fields = append(fields, "notes")
+	}
+	if a.RunbookURL != b.RunbookURL {
+		fields = append(fields, "runbook_url")
+	}
+	return fields
+}
+
+// jsonEqual 按 JSON 序列化结果比较（nil 与空切片视为相同）
+func jsonEqual(a, b interface{}) bool {
+	x, _ := json.Marshal(a)
+	y, _ := json.Marshal(b)
+	norm := func(s []byte) []byte {
+		if string(s) == "[]" {
+			return []byte("null")
+		}
+		return s
+	}
+	return bytes.Equal(norm(x), norm(y))
+}
+
+// sectionDiff 逐个 JSON 字段比较配置段，返回 "段.字段: 旧值 → 新值" 形式的描述
+func sectionDiff(section string, old, new interface{}) []string {
+	var a, b map[string]json.RawMessage
+	x, _ := json.Marshal(old)
+	y, _ := json.Marshal(new)
+	json.Unmarshal(x, &a)
+	json.Unmarshal(y, &b)
+
+	keys := make(map[string]bool)
+	for k := range a {
+		keys[k] = true
+	}
+	for k := range b {
+		keys[k] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	var diffs []string
+	for _, k := range sorted {
+		if jsonEqual(a[k], b[k]) {
+			continue
+		}
+		from, to := string(a[k]), string(b[k])
+		if from == "" {
+			from = "null"
+		}
+		if to == "" {
+			to = "null"
+		}
+		if len(from) > 40 || len(to) > 40 {
+			diffs = append(diffs, fmt.Sprintf("%s.%s: 已修改", section, k))
+			continue
+		}
+		diffs = append(diffs, fmt.Sprintf("%s.%s: %s → %s", section, k, from, to))
+	}
+	return diffs
+}
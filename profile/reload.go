@@ -0,0 +1,31 @@
+package profile
+
+import (
+	"fmt"
+
+	"monitor-agent/config"
+	"monitor-agent/format"
+	"monitor-agent/monitor"
+)
+
+// ApplyConfig 用 src（通常是从配置文件重新加载的配置）替换运行中的配置 dst，并使可立即生效的设置生效：
+// 采样间隔、影响分析阈值、进程显示名称、字节单位和远程探测。dst 原地更新，CLI、Web 服务和服务本身
+// 共用同一份配置，都能看到新值；监控目标、Web 地址、并发采集等仍需重启或通过对应命令生效。
+// 返回未能生效的设置的警告
+func ApplyConfig(dst, src *config.Config, mm *monitor.MultiMonitor) []string {
+	*dst = *src
+
+	var warnings []string
+	if err := mm.SetSampleInterval(dst.Sampling.Interval); err != nil {
+		warnings = append(warnings, fmt.Sprintf("采样间隔未更新: %v", err))
+	}
+	if analyzer := mm.GetImpactAnalyzer(); analyzer != nil {
+		analyzer.UpdateConfig(dst.Impact)
+	}
+	mm.SetNameAliases(dst.Display.NameAliases)
+	format.SetByteUnits(dst.Display.ByteUnits)
+	if err := mm.SetProbes(dst.Probes); err != nil {
+		warnings = append(warnings, fmt.Sprintf("远程探测未更新: %v", err))
+	}
+	return warnings
+}
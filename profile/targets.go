@@ -0,0 +1,134 @@
+package profile
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"monitor-agent/logger"
+	"monitor-agent/monitor"
+	"monitor-agent/types"
+)
+
+// TargetList 批量导出/导入的监控目标列表，目标按进程名记录（不含 PID）
+// 与完整配置档案不同，导入时只添加目标，不修改或移除已有目标，也不涉及其他配置
+type TargetList struct {
+	Version    int                   `json:"version"`
+	ExportedAt time.Time             `json:"exported_at"`
+	Host       string                `json:"host,omitempty"`
+	Targets    []types.MonitorTarget `json:"targets"`
+}
+
+// TargetImportResult 批量导入结果
+type TargetImportResult struct {
+	Added      int      `json:"added"`
+	Skipped    int      `json:"skipped"`    // 同名目标已在监控
+	Unresolved int      `json:"unresolved"` // 本机没有可用的同名进程
+	Failed     int      `json:"failed"`     // 添加失败（如进程刚退出）
+	Changes    []Change `json:"changes"`
+}
+
+// ExportTargets 导出当前监控目标
+func ExportTargets(mm *monitor.MultiMonitor) TargetList {
+	host, _ := os.Hostname()
+	list := TargetList{
+		Version:    FormatVersion,
+		ExportedAt: time.Now(),
+		Host:       host,
+		Targets:    []types.MonitorTarget{},
+	}
+	for _, t := range mm.GetTargets() {
+		t.PID = 0
+		t.Cmdline = ""
+		t.RestartCommand, t.RestartPolicy = "", nil
+		list.Targets = append(list.Targets, t)
+	}
+	return list
+}
+
+// ParseTargets 解析并校验批量目标文件
+// 支持 target export 导出的文件、config export 的完整档案（只取 targets）和目标数组
+func ParseTargets(data []byte) ([]types.MonitorTarget, error) {
+	var targets []types.MonitorTarget
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &targets); err != nil {
+			return nil, fmt.Errorf("parse targets: %w", err)
+		}
+	} else {
+		var list TargetList
+		if err := json.Unmarshal(data, &list); err != nil {
+			return nil, fmt.Errorf("parse targets: %w", err)
+		}
+		if list.Version == 0 {
+			return nil, fmt.Errorf("not a target list: missing version")
+		}
+		if list.Version > FormatVersion {
+			return nil, fmt.Errorf("target list version %d is newer than supported version %d", list.Version, FormatVersion)
+		}
+		targets = list.Targets
+	}
+	if err := validateTargets(targets); err != nil {
+		return nil, err
+	}
+	return targets, nil
+}
+
+// ImportTargets 按进程名添加目标
+// 每个条目先与同名的已监控目标配对，配对上视为重复跳过；否则添加本机同名进程（按 PID 顺序），
+// 本机未运行的记为无法解析。某个目标添加失败不影响其余目标
+func ImportTargets(targets []types.MonitorTarget, mm *monitor.MultiMonitor) (*TargetImportResult, error) {
+	processes, err := mm.ListAllProcesses()
+	if err != nil {
+		return nil, fmt.Errorf("list processes: %w", err)
+	}
+	sort.Slice(processes, func(i, j int) bool { return processes[i].PID < processes[j].PID })
+
+	monitored := make(map[int32]bool)
+	currentByName := make(map[string]int)
+	for _, t := range mm.GetTargets() {
+		monitored[t.PID] = true
+		currentByName[t.Name]++
+	}
+	procsByName := make(map[string][]types.ProcessInfo)
+	for _, p := range processes {
+		if !monitored[p.PID] {
+			procsByName[p.Name] = append(procsByName[p.Name], p)
+		}
+	}
+
+	result := &TargetImportResult{Changes: []Change{}}
+	for _, t := range targets {
+		if currentByName[t.Name] > 0 {
+			currentByName[t.Name]--
+			result.Skipped++
+			result.Changes = append(result.Changes, Change{Action: "skip", Target: t.Name, Detail: "同名目标已在监控，跳过"})
+			continue
+		}
+
+		list := procsByName[t.Name]
+		if len(list) == 0 {
+			result.Unresolved++
+			result.Changes = append(result.Changes, Change{Action: "unresolved", Target: t.Name, Detail: "本机没有可用的同名进程"})
+			continue
+		}
+		proc := list[0]
+		procsByName[t.Name] = list[1:]
+
+		t.PID, t.Cmdline = proc.PID, proc.Cmdline
+		// 已在监控的同名目标已按数量跳过，剩下的是文件中有意的多个实例
+		if err := mm.ForceAddTarget(t); err != nil {
+			result.Failed++
+			result.Changes = append(result.Changes, Change{Action: "failed", Target: t.Name, PID: t.PID, Detail: err.Error()})
+			continue
+		}
+		result.Added++
+		result.Changes = append(result.Changes, Change{Action: "add", Target: t.Name, PID: t.PID, Detail: "添加监控目标"})
+	}
+
+	logger.Infof("PROFILE", "Targets imported: %d added, %d skipped, %d unresolved, %d failed",
+		result.Added, result.Skipped, result.Unresolved, result.Failed)
+	return result, nil
+}
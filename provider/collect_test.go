@@ -0,0 +1,55 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// newCollectProvider 不启动网络监控的 provider，collectWorkers 为并发采集的协程数
+func newCollectProvider(tb testing.TB, workers int) *commonProvider {
+	p := newCommonProvider(newPlatformOptions())
+	p.collectWorkers = workers
+	tb.Cleanup(p.Close)
+	return p
+}
+
+func hostProcesses(tb testing.TB) []*process.Process {
+	procs, err := process.Processes()
+	if err != nil {
+		tb.Skipf("list processes: %v", err)
+	}
+	return procs
+}
+
+func TestCollectProcessesKeepsOrder(t *testing.T) {
+	procs := hostProcesses(t)
+	for _, workers := range []int{0, 1, 4, MaxCollectWorkers} {
+		p := newCollectProvider(t, workers)
+		result := p.collectProcesses(procs, nil)
+		if len(result) != len(procs) {
+			t.Fatalf("workers=%d: %d results for %d processes", workers, len(result), len(procs))
+		}
+		for i, info := range result {
+			if info.PID != procs[i].Pid {
+				t.Fatalf("workers=%d: result %d is PID %d, want %d", workers, i, info.PID, procs[i].Pid)
+			}
+		}
+	}
+}
+
+// BenchmarkCollectProcesses 顺序采集（workers=1）与并发采集本机进程列表的耗时对比
+func BenchmarkCollectProcesses(b *testing.B) {
+	procs := hostProcesses(b)
+	for _, workers := range []int{1, 4, 8, MaxCollectWorkers} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			p := newCollectProvider(b, workers)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				p.collectProcesses(procs, nil)
+			}
+			b.ReportMetric(float64(len(procs)), "procs")
+		})
+	}
+}
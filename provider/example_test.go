@@ -0,0 +1,114 @@
+package provider_test
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"monitor-agent/clock"
+	"monitor-agent/impact"
+	"monitor-agent/monitor"
+	"monitor-agent/provider"
+	"monitor-agent/types"
+)
+
+// 用快照描述进程表，再用 RemoveProcess/ReusePID 模拟进程退出和 PID 复用
+func ExampleFake() {
+	f := provider.NewFake(nil)
+	f.SetProcesses([]types.ProcessInfo{
+		{PID: 1001, Name: "scada", CPUPct: 12.5},
+		{PID: 1002, Name: "nginx"},
+		{PID: 1003, Name: "nginx"},
+	})
+
+	pids, _ := f.FindAllPIDsByName("nginx")
+	fmt.Println("nginx:", pids)
+	m, _ := f.GetMetrics(1001)
+	fmt.Println("scada cpu:", m.CPUPct, "alive:", m.Alive)
+
+	f.RemoveProcess(1001)
+	fmt.Println("after exit alive:", f.IsAlive(1001))
+
+	f.ReusePID(types.ProcessInfo{PID: 1001, Name: "bash"})
+	pid, _ := f.FindPIDByName("bash")
+	fmt.Println("reused pid:", pid)
+	// Output:
+	// nginx: [1002 1003]
+	// scada cpu: 12.5 alive: true
+	// after exit alive: false
+	// reused pid: 1001
+}
+
+// Fake 作为监控器的数据来源：添加目标、读取进程列表和系统指标都不访问真实的进程表
+func ExampleFake_monitor() {
+	f := provider.NewFake(nil)
+	f.SetProcesses([]types.ProcessInfo{
+		{PID: 1001, Name: "scada"},
+		{PID: 1002, Name: "historian"},
+	})
+	f.SetSystemMetrics(types.SystemMetrics{CPUPercent: 35, MemoryPercent: 60})
+
+	logDir, cleanup := tempDir()
+	defer cleanup()
+	m, _ := monitor.NewMultiMonitor(types.MultiMonitorConfig{LogDir: logDir}, f)
+	if err := m.AddTarget(types.MonitorTarget{PID: 1001, Name: "scada"}); err != nil {
+		fmt.Println("add target:", err)
+	}
+	if err := m.AddTarget(types.MonitorTarget{PID: 4242, Name: "missing"}); err != nil {
+		fmt.Println("add missing target failed")
+	}
+
+	procs, _ := m.ListAllProcesses()
+	sys, _ := m.GetSystemMetrics()
+	fmt.Println("targets:", len(m.GetTargets()), "processes:", len(procs), "cpu:", sys.CPUPercent)
+	// Output:
+	// add missing target failed
+	// targets: 1 processes: 2 cpu: 35
+}
+
+// Fake 配合 clock.Fake 驱动影响分析器：占用 CPU 的进程超过进程级阈值时产生影响事件
+func ExampleFake_impactAnalyzer() {
+	c := clock.NewFake(time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC))
+	f := provider.NewFake(c)
+	f.SetSystemMetrics(types.SystemMetrics{CPUPercent: 30, CPUPerCore: []float64{30, 30}, MemoryPercent: 40})
+	f.SetProcesses([]types.ProcessInfo{
+		{PID: 1001, Name: "scada", CPUPct: 5},
+		{PID: 2001, Name: "compiler", CPUPct: 95},
+	})
+
+	targets := func() []types.MonitorTarget {
+		return []types.MonitorTarget{{PID: 1001, Name: "scada"}}
+	}
+	a := impact.NewImpactAnalyzer(types.ImpactConfig{
+		Enabled:           true,
+		AnalysisInterval:  1,
+		ProcCPUThreshold:  50,
+		FileCheckInterval: 3600,
+		PortCheckInterval: 3600,
+	}, f, targets, f.ListAllProcesses)
+	a.SetClock(c)
+	a.SetConnectionSource(f.Connections)
+
+	impacts, cancel := a.SubscribeImpacts(8)
+	defer cancel()
+	a.Start()
+	defer a.Stop()
+
+	select {
+	case e := <-impacts:
+		fmt.Printf("%s impact on %s from %s (PID %d)\n", e.ImpactType, e.TargetName, e.SourceName, e.SourcePID)
+	case <-time.After(10 * time.Second):
+		fmt.Println("no impact")
+	}
+	// Output:
+	// cpu impact on scada from compiler (PID 2001)
+}
+
+// tempDir 示例用的临时目录（监控器的可用率、维护窗口等状态文件），cleanup 删除该目录
+func tempDir() (string, func()) {
+	dir, err := os.MkdirTemp("", "fake-example")
+	if err != nil {
+		panic(err)
+	}
+	return dir, func() { os.RemoveAll(dir) }
+}
@@ -0,0 +1,369 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/net"
+
+	"monitor-agent/clock"
+	"monitor-agent/types"
+)
+
+// Fake 按脚本返回进程和系统快照的 provider，不读取真实进程表。
+// 用于测试影响分析器和监控器：SetProcesses/SetSystemMetrics 设置下一次采集看到的快照，
+// 配合 clock.Fake 推进时间即可模拟一段时间内的进程变化。
+// 进程按名称精确匹配，返回的数据都是快照的副本。
+//
+// 常见场景：RemoveProcess 模拟进程退出，ReusePID 模拟 PID 被新进程复用，
+// SetMetricsError/SetSystemMetricsError 模拟权限不足或读取失败
+type Fake struct {
+	mu       sync.Mutex
+	clock    clock.Clock
+	procs    []types.ProcessInfo
+	system   types.SystemMetrics
+	cpuStyle string
+	aliases  map[string]string
+	exec     map[int32][2]string // PID -> 可执行文件路径、工作目录
+	conns    []net.ConnectionStat
+	errs     map[int32]error // PID -> GetMetrics 等按 PID 读取的错误
+	sysErr   error           // GetSystemMetrics 的错误
+}
+
+var _ ProcProvider = (*Fake)(nil)
+
+// NewFake 创建没有进程的 Fake，c 为 nil 时使用系统时钟
+func NewFake(c clock.Clock) *Fake {
+	if c == nil {
+		c = clock.Real
+	}
+	return &Fake{
+		clock:    c,
+		cpuStyle: CPUStyleSolaris,
+		exec:     make(map[int32][2]string),
+		errs:     make(map[int32]error),
+	}
+}
+
+// SetProcesses 替换进程快照
+func (f *Fake) SetProcesses(procs []types.ProcessInfo) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.procs = append([]types.ProcessInfo(nil), procs...)
+}
+
+// UpdateProcess 修改快照中某个进程，进程不存在时返回 false
+func (f *Fake) UpdateProcess(pid int32, update func(p *types.ProcessInfo)) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := range f.procs {
+		if f.procs[i].PID == pid {
+			update(&f.procs[i])
+			return true
+		}
+	}
+	return false
+}
+
+// AddProcess 向快照中添加进程，已有相同 PID 的进程时替换
+func (f *Fake) AddProcess(p types.ProcessInfo) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := range f.procs {
+		if f.procs[i].PID == p.PID {
+			f.procs[i] = p
+			return
+		}
+	}
+	f.procs = append(f.procs, p)
+}
+
+// ReusePID 模拟 PID 复用：原进程退出后新进程 p 获得同一 PID。
+// 新进程的运行时间从 0 开始，并清除原进程的可执行文件路径和注入的错误
+func (f *Fake) ReusePID(p types.ProcessInfo) {
+	p.Uptime = 0
+	f.mu.Lock()
+	delete(f.exec, p.PID)
+	delete(f.errs, p.PID)
+	f.mu.Unlock()
+	f.AddProcess(p)
+}
+
+// SetMetricsError 使按 PID 读取进程数据（GetMetrics、GetMemoryBreakdown、GetLaunchSnapshot、
+// GetCPUAffinity）返回 err，进程仍在快照中（IsAlive 为 true），用于模拟权限不足等读取失败；err 为 nil 时恢复
+func (f *Fake) SetMetricsError(pid int32, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err == nil {
+		delete(f.errs, pid)
+		return
+	}
+	f.errs[pid] = err
+}
+
+// SetSystemMetricsError 使 GetSystemMetrics 返回 err，err 为 nil 时恢复
+func (f *Fake) SetSystemMetricsError(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sysErr = err
+}
+
+// RemoveProcess 从快照中移除进程（模拟进程退出）
+func (f *Fake) RemoveProcess(pid int32) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	kept := f.procs[:0]
+	for _, p := range f.procs {
+		if p.PID != pid {
+			kept = append(kept, p)
+		}
+	}
+	f.procs = kept
+}
+
+// SetSystemMetrics 替换系统指标快照
+func (f *Fake) SetSystemMetrics(m types.SystemMetrics) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.system = m
+}
+
+// SetExecPaths 设置进程的可执行文件路径和工作目录，未设置时 GetExecPaths 返回错误
+func (f *Fake) SetExecPaths(pid int32, exe, cwd string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.exec[pid] = [2]string{exe, cwd}
+}
+
+// SetConnections 替换网络连接表快照，供影响分析器的端口冲突检测使用（见 impact.ImpactAnalyzer.SetConnectionSource）
+func (f *Fake) SetConnections(conns []net.ConnectionStat) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.conns = append([]net.ConnectionStat(nil), conns...)
+}
+
+// Connections 返回网络连接表快照的副本
+func (f *Fake) Connections() ([]net.ConnectionStat, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]net.ConnectionStat(nil), f.conns...), nil
+}
+
+// readLocked 按 PID 读取进程数据：注入了错误时返回该错误，进程不存在时返回未找到（调用方需持有 mu）
+func (f *Fake) readLocked(pid int32) (types.ProcessInfo, error) {
+	if err := f.errs[pid]; err != nil {
+		return types.ProcessInfo{}, err
+	}
+	p, ok := f.findLocked(pid)
+	if !ok {
+		return p, fmt.Errorf("process not found")
+	}
+	return p, nil
+}
+
+// findLocked 按 PID 查找进程（调用方需持有 mu）
+func (f *Fake) findLocked(pid int32) (types.ProcessInfo, bool) {
+	for _, p := range f.procs {
+		if p.PID == pid {
+			return p, true
+		}
+	}
+	return types.ProcessInfo{}, false
+}
+
+// FindAllPIDsByName 根据进程名查找所有匹配的 PID
+func (f *Fake) FindAllPIDsByName(name string) ([]int32, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var pids []int32
+	for _, p := range f.procs {
+		if p.Name == name {
+			pids = append(pids, p.PID)
+		}
+	}
+	return pids, nil
+}
+
+// FindPIDByName 根据进程名查找 PID，没有或有多个同名进程时返回错误
+func (f *Fake) FindPIDByName(name string) (int32, error) {
+	pids, _ := f.FindAllPIDsByName(name)
+	if len(pids) == 0 {
+		return 0, fmt.Errorf("process %s not found", name)
+	}
+	if len(pids) > 1 {
+		return 0, fmt.Errorf("multiple processes found with name %s: %v, please use -pid to specify", name, pids)
+	}
+	return pids[0], nil
+}
+
+// GetMetrics 获取进程指标
+func (f *Fake) GetMetrics(pid int32) (*types.ProcessMetrics, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	p, err := f.readLocked(pid)
+	if err != nil {
+		return nil, err
+	}
+	return &types.ProcessMetrics{
+		Timestamp: f.clock.Now(),
+		PID:       p.PID,
+		Name:      p.Name,
+		CPUPct:    p.CPUPct,
+		RSSBytes:  p.RSSBytes,
+		Priority:  p.Priority,
+		Nice:      p.Nice,
+		Alive:     true,
+	}, nil
+}
+
+// IsAlive 进程是否在快照中
+func (f *Fake) IsAlive(pid int32) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, ok := f.findLocked(pid)
+	return ok
+}
+
+// ListAllProcesses 返回进程快照的副本，按名称映射填写显示名称
+func (f *Fake) ListAllProcesses() ([]types.ProcessInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	result := make([]types.ProcessInfo, len(f.procs))
+	for i, p := range f.procs {
+		p.ListenPorts = append([]int(nil), p.ListenPorts...)
+		p.CPUAffinity = append([]int(nil), p.CPUAffinity...)
+		if alias, ok := f.aliases[p.Name]; ok {
+			p.DisplayName = alias
+		}
+		result[i] = p
+	}
+	return result, nil
+}
+
+// GetExecPaths 获取 SetExecPaths 设置的路径
+func (f *Fake) GetExecPaths(pid int32) (string, string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	paths, ok := f.exec[pid]
+	if !ok {
+		return "", "", fmt.Errorf("process %d: exec path not set", pid)
+	}
+	return paths[0], paths[1], nil
+}
+
+// GetMemoryBreakdown 只返回快照中的 RSS 和虚拟内存，没有映射区明细
+func (f *Fake) GetMemoryBreakdown(pid int32) (*types.MemoryBreakdown, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	p, err := f.readLocked(pid)
+	if err != nil {
+		return nil, err
+	}
+	return &types.MemoryBreakdown{
+		PID:         p.PID,
+		Name:        p.Name,
+		Timestamp:   f.clock.Now(),
+		RSSBytes:    p.RSSBytes,
+		VMSBytes:    p.VMS,
+		DetailError: "not available from fake provider",
+	}, nil
+}
+
+// GetLaunchSnapshot 由进程快照生成启动信息（没有环境变量）
+func (f *Fake) GetLaunchSnapshot(pid int32) (*types.LaunchSnapshot, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	p, err := f.readLocked(pid)
+	if err != nil {
+		return nil, err
+	}
+	now := f.clock.Now()
+	snap := &types.LaunchSnapshot{
+		PID:        p.PID,
+		Name:       p.Name,
+		CapturedAt: now,
+		Cmdline:    p.Cmdline,
+		Cwd:        f.exec[pid][1],
+		PPID:       p.PPID,
+		Env:        map[string]string{},
+	}
+	if p.Uptime > 0 {
+		snap.StartTime = now.Add(-time.Duration(p.Uptime) * time.Second)
+	}
+	return snap, nil
+}
+
+// GetProcessHistory 不做按需采样，返回一条当前指标
+func (f *Fake) GetProcessHistory(ctx context.Context, pid int32, seconds int) ([]types.ProcessMetrics, error) {
+	m, err := f.GetMetrics(pid)
+	if err != nil {
+		return nil, err
+	}
+	return []types.ProcessMetrics{*m}, nil
+}
+
+// Close Fake 没有后台协程，什么也不做
+func (f *Fake) Close() {}
+
+// GetCPUAffinity 返回快照中的 CPU 亲和性
+func (f *Fake) GetCPUAffinity(pid int32) ([]int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	p, err := f.readLocked(pid)
+	if err != nil || len(p.CPUAffinity) == 0 {
+		return nil, fmt.Errorf("无法读取进程 %d 的 CPU 亲和性", pid)
+	}
+	return append([]int(nil), p.CPUAffinity...), nil
+}
+
+// GetAnonVMS 返回快照中的匿名虚拟内存，未设置 AnonVMS 时返回错误
+func (f *Fake) GetAnonVMS(pid int32) (uint64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	p, err := f.readLocked(pid)
+	if err != nil || p.AnonVMS == 0 {
+		return 0, fmt.Errorf("无法读取进程 %d 的匿名虚拟内存", pid)
+	}
+	return p.AnonVMS, nil
+}
+
+// GetCPUStyle 获取进程 CPU 口径（默认 solaris，只记录，不换算快照中的 CPUPct）
+func (f *Fake) GetCPUStyle() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.cpuStyle
+}
+
+// SetCPUStyle 修改进程 CPU 口径
+func (f *Fake) SetCPUStyle(style string) error {
+	if style != CPUStyleIrix && style != CPUStyleSolaris {
+		return fmt.Errorf("未知的 CPU 口径: %s（可选 %s/%s）", style, CPUStyleIrix, CPUStyleSolaris)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.cpuStyle = style
+	return nil
+}
+
+// SetNameAliases 替换进程名到显示名称的映射
+func (f *Fake) SetNameAliases(aliases map[string]string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.aliases = make(map[string]string, len(aliases))
+	for k, v := range aliases {
+		f.aliases[k] = v
+	}
+}
+
+// GetSystemMetrics 返回系统指标快照
+func (f *Fake) GetSystemMetrics() (*types.SystemMetrics, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.sysErr != nil {
+		return nil, f.sysErr
+	}
+	m := f.system
+	return &m, nil
+}
@@ -0,0 +1,186 @@
+package provider
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+
+	"monitor-agent/types"
+)
+
+const (
+	// HistoryMaxSeconds 按需采样单次查询的最长时间（秒），也是每个进程保留的样本数
+	HistoryMaxSeconds = 120
+	// historyTTL 最后一次查询后继续采样的时间，期间重复查询直接复用已有样本
+	historyTTL = time.Minute
+)
+
+// historySampler 单个进程的按需采样器，每秒采样一次，与监控目标的指标缓冲区无关
+type historySampler struct {
+	mu       sync.Mutex
+	started  time.Time
+	lastUsed time.Time
+	samples  []types.ProcessMetrics
+	updated  chan struct{} // 每次采样后关闭并重建，用于唤醒等待的查询
+	done     bool          // 进程已退出或采样器已过期
+}
+
+// GetProcessHistory 获取任意进程最近 seconds 秒的每秒指标
+// 首次查询时启动采样并等待采样满 seconds 秒（或进程退出、ctx 取消）后返回；
+// 采样器在最后一次查询后保留 historyTTL，期间的查询复用已有样本，已采满时立即返回
+func (p *commonProvider) GetProcessHistory(ctx context.Context, pid int32, seconds int) ([]types.ProcessMetrics, error) {
+	if seconds < 1 {
+		seconds = 1
+	}
+	if seconds > HistoryMaxSeconds {
+		seconds = HistoryMaxSeconds
+	}
+
+	s, err := p.historySamplerFor(pid)
+	if err != nil {
+		return nil, err
+	}
+
+	window := time.Duration(seconds) * time.Second
+	for {
+		s.mu.Lock()
+		s.lastUsed = time.Now()
+		ready := s.done || time.Since(s.started) >= window
+		updated := s.updated
+		s.mu.Unlock()
+
+		if ready {
+			break
+		}
+		select {
+		case <-updated:
+		case <-ctx.Done():
+			return s.recent(window), ctx.Err()
+		}
+	}
+	return s.recent(window), nil
+}
+
+// historySamplerFor 获取进程的采样器，不存在或已结束时新建并启动
+func (p *commonProvider) historySamplerFor(pid int32) (*historySampler, error) {
+	p.historyMu.Lock()
+	defer p.historyMu.Unlock()
+
+	if s, ok := p.history[pid]; ok {
+		s.mu.Lock()
+		done := s.done
+		s.mu.Unlock()
+		if !done {
+			return s, nil
+		}
+	}
+
+	select {
+	case <-p.stopCh:
+		return nil, ErrClosed
+	default:
+	}
+
+	proc, err := process.NewProcess(pid)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	s := &historySampler{
+		started:  now,
+		lastUsed: now,
+		updated:  make(chan struct{}),
+	}
+	p.history[pid] = s
+	p.bgWG.Add(1)
+	go func() {
+		defer p.bgWG.Done()
+		p.runHistorySampler(proc, s)
+	}()
+	return s, nil
+}
+
+// runHistorySampler 每秒采样一次，进程退出、超过 historyTTL 无人查询或 provider 关闭时停止
+// CPU 使用率用自己的增量基准计算，不影响监控采样的 CPU 基准
+func (p *commonProvider) runHistorySampler(proc *process.Process, s *historySampler) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	name, _ := proc.Name()
+	name = p.displayName(name)
+	var prevCPUTime float64
+	var prevTime time.Time
+
+	for {
+		now := time.Now()
+		sample := types.ProcessMetrics{Timestamp: now, PID: proc.Pid, Name: name}
+		running, _ := proc.IsRunning()
+		if running {
+			sample.Alive = true
+			if times, err := proc.Times(); err == nil {
+				cpuTime := times.User + times.System
+				if !prevTime.IsZero() {
+					sample.CPUPct = (cpuTime - prevCPUTime) / now.Sub(prevTime).Seconds() * 100
+					p.cpuSamplesMu.RLock()
+					if p.divideByNumCPU {
+						sample.CPUPct /= float64(p.numCPU)
+					}
+					p.cpuSamplesMu.RUnlock()
+				}
+				prevCPUTime, prevTime = cpuTime, now
+			}
+			if mem, err := proc.MemoryInfo(); err == nil {
+				sample.RSSBytes = mem.RSS
+			}
+			sample.Priority, sample.Nice = p.readPriority(proc)
+		}
+
+		s.mu.Lock()
+		s.samples = append(s.samples, sample)
+		if len(s.samples) > HistoryMaxSeconds {
+			s.samples = s.samples[len(s.samples)-HistoryMaxSeconds:]
+		}
+		expired := now.Sub(s.lastUsed) > historyTTL
+		s.done = !running || expired
+		close(s.updated)
+		s.updated = make(chan struct{})
+		done := s.done
+		s.mu.Unlock()
+
+		if !done {
+			select {
+			case <-ticker.C:
+				continue
+			case <-p.stopCh:
+				// 唤醒等待中的查询，返回已有样本
+				s.mu.Lock()
+				s.done = true
+				close(s.updated)
+				s.updated = make(chan struct{})
+				s.mu.Unlock()
+			}
+		}
+		p.historyMu.Lock()
+		if p.history[proc.Pid] == s {
+			delete(p.history, proc.Pid)
+		}
+		p.historyMu.Unlock()
+		return
+	}
+}
+
+// recent 返回最近 window 内的样本副本
+func (s *historySampler) recent(window time.Duration) []types.ProcessMetrics {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cutoff := time.Now().Add(-window)
+	result := make([]types.ProcessMetrics, 0, len(s.samples))
+	for _, m := range s.samples {
+		if !m.Timestamp.Before(cutoff) {
+			result = append(result, m)
+		}
+	}
+	return result
+}
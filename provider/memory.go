@@ -0,0 +1,65 @@
+package provider
+
+import (
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+
+	"monitor-agent/types"
+)
+
+// memDetailTTL 内存明细缓存时间（Linux 读取 smaps 开销较大，避免频繁刷新页面时重复读取）
+const memDetailTTL = 5 * time.Second
+
+// memDetailEntry 内存明细缓存项
+type memDetailEntry struct {
+	at time.Time
+	mb types.MemoryBreakdown
+}
+
+// GetMemoryBreakdown 获取进程内存构成明细
+// 平台明细读取失败（通常是权限不足）时只返回基础内存信息，不视为错误
+func (p *commonProvider) GetMemoryBreakdown(pid int32) (*types.MemoryBreakdown, error) {
+	now := time.Now()
+	p.memDetailMu.Lock()
+	for k, e := range p.memDetail {
+		if now.Sub(e.at) >= memDetailTTL {
+			delete(p.memDetail, k)
+		}
+	}
+	if e, ok := p.memDetail[pid]; ok {
+		mb := e.mb
+		p.memDetailMu.Unlock()
+		return &mb, nil
+	}
+	p.memDetailMu.Unlock()
+
+	proc, err := process.NewProcess(pid)
+	if err != nil {
+		return nil, err
+	}
+	memInfo, err := proc.MemoryInfo()
+	if err != nil {
+		return nil, err
+	}
+	name, _ := proc.Name()
+
+	mb := types.MemoryBreakdown{
+		PID:       pid,
+		Name:      p.displayName(name),
+		Timestamp: now,
+		RSSBytes:  memInfo.RSS,
+		VMSBytes:  memInfo.VMS,
+		SwapBytes: memInfo.Swap,
+	}
+	if err := readMemoryDetail(pid, &mb); err != nil {
+		mb.DetailError = err.Error()
+	} else {
+		mb.Detailed = true
+	}
+
+	p.memDetailMu.Lock()
+	p.memDetail[pid] = &memDetailEntry{at: now, mb: mb}
+	p.memDetailMu.Unlock()
+	return &mb, nil
+}
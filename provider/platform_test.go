@@ -0,0 +1,69 @@
+package provider
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// 各平台的 newPlatformOptions 提供的钩子：Linux 由 gopsutil 读取句柄数和优先级，Windows 使用系统 API
+func TestPlatformOptions(t *testing.T) {
+	opts := newPlatformOptions()
+	type hooks struct {
+		handleCount, priority, fileDescription, schedDelay, anonVMS bool
+		cpuStyle                                                    string
+	}
+	want := map[string]hooks{
+		"linux":   {schedDelay: true, anonVMS: true, cpuStyle: CPUStyleIrix},
+		"windows": {handleCount: true, priority: true, fileDescription: true, cpuStyle: CPUStyleSolaris},
+	}
+	w, ok := want[runtime.GOOS]
+	if !ok {
+		t.Skipf("no platform options on %s", runtime.GOOS)
+	}
+	got := hooks{
+		handleCount:     opts.GetHandleCount != nil,
+		priority:        opts.GetPriority != nil,
+		fileDescription: opts.GetFileDescription != nil,
+		schedDelay:      opts.ReadSchedDelay != nil,
+		anonVMS:         opts.ReadAnonVMS != nil,
+		cpuStyle:        opts.CPUStyle,
+	}
+	if got != w {
+		t.Fatalf("%s platform hooks = %+v, want %+v", runtime.GOOS, got, w)
+	}
+	if opts.MatchProcessName == nil || opts.FormatCmdline == nil || opts.GetCPUAffinity == nil {
+		t.Fatal("required platform hooks are nil")
+	}
+
+	p := newCommonProvider(opts)
+	defer p.Close()
+	if (p.getHandleCount != nil) != got.handleCount || (p.getPriority != nil) != got.priority ||
+		(p.readSchedDelay != nil) != got.schedDelay || (p.readAnonVMS != nil) != got.anonVMS ||
+		p.divideByNumCPU != (got.cpuStyle == CPUStyleSolaris) {
+		t.Fatal("newCommonProvider did not keep the platform hooks")
+	}
+}
+
+// 平台相关的 provider 文件只在对应平台编译，各平台都需编译通过（发布前的交叉编译检查）
+func TestCrossPlatformBuild(t *testing.T) {
+	if testing.Short() {
+		t.Skip("cross-platform build skipped in short mode")
+	}
+	goTool := filepath.Join(runtime.GOROOT(), "bin", "go")
+	if _, err := os.Stat(goTool); err != nil {
+		if goTool, err = exec.LookPath("go"); err != nil {
+			t.Skip("go tool not found")
+		}
+	}
+	for _, goos := range []string{"linux", "windows"} {
+		cmd := exec.Command(goTool, "build", "./...")
+		cmd.Dir = ".."
+		cmd.Env = append(os.Environ(), "GOOS="+goos, "GOARCH=amd64", "CGO_ENABLED=0")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Errorf("GOOS=%s go build ./...: %v\n%s", goos, err, out)
+		}
+	}
+}
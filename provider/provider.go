@@ -1,6 +1,79 @@
 package provider
 
-import "monitor-agent/types"
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"monitor-agent/netmon"
+	"monitor-agent/types"
+)
+
+// 进程 CPU 使用率口径
+const (
+	// CPUStyleIrix 单核口径：单核满载为 100%，多核进程可超过 100%
+	CPUStyleIrix = "irix"
+	// CPUStyleSolaris 整机口径：除以逻辑核心数，最大 100%
+	CPUStyleSolaris = "solaris"
+)
+
+// MaxCollectWorkers collect_workers 的上限
+const MaxCollectWorkers = 16
+
+// ErrClosed provider 已关闭（Close 之后不再启动按需采样）
+var ErrClosed = errors.New("provider closed")
+
+// Options provider 选项
+type Options struct {
+	// CPUStyle 进程 CPU 口径（irix/solaris），为空时使用平台默认值
+	CPUStyle string
+
+	// StripExeSuffix 采集时去掉进程名的 .exe 后缀（Windows），使名称与 Linux 一致
+	StripExeSuffix bool
+
+	// NameAliases 进程名到显示名称的映射，写入 ProcessInfo.DisplayName，不影响按名称匹配
+	NameAliases map[string]string
+
+	// NetMon 网络监控选项（网卡过滤），为空时统计所有网卡
+	NetMon netmon.Options
+
+	// CollectWorkers 采集进程列表时并发读取各进程信息的协程数，0 或 1 表示顺序采集，超过 MaxCollectWorkers 时按上限
+	CollectWorkers int
+}
+
+// New 使用默认选项创建 provider
+func New() ProcProvider {
+	return NewWithOptions(Options{})
+}
+
+// NewWithOptions 使用指定选项创建 provider
+func NewWithOptions(opts Options) ProcProvider {
+	p := newCommonProvider(newPlatformOptions())
+	p.stripExeSuffix = opts.StripExeSuffix
+	p.collectWorkers = opts.CollectWorkers
+	if p.collectWorkers > MaxCollectWorkers {
+		p.collectWorkers = MaxCollectWorkers
+	}
+	p.SetNameAliases(opts.NameAliases)
+	if opts.CPUStyle != "" {
+		if err := p.SetCPUStyle(opts.CPUStyle); err != nil {
+			fmt.Printf("[Provider] %v，使用默认口径 %s\n", err, p.GetCPUStyle())
+		}
+	}
+	p.startNetMonitor(opts.NetMon)
+	return p
+}
+
+// Type provider 类型（数据来源/平台），用于版本信息中区分部署
+func Type() string {
+	return providerType
+}
+
+// IsElevated 代理是否以 root/管理员权限运行
+// 权限不足时无法读取其他用户进程的磁盘 IO、可执行文件路径和句柄数，这些指标显示为 0
+func IsElevated() bool {
+	return isElevated()
+}
 
 // ProcProvider 进程信息提供者接口，封装平台差异
 type ProcProvider interface {
@@ -14,6 +87,26 @@ type ProcProvider interface {
 	IsAlive(pid int32) bool
 	// ListAllProcesses 列出系统所有进程
 	ListAllProcesses() ([]types.ProcessInfo, error)
+	// GetExecPaths 获取进程可执行文件路径和工作目录（工作目录读取失败时为空）
+	GetExecPaths(pid int32) (exe string, cwd string, err error)
+	// GetMemoryBreakdown 获取进程内存构成明细（短时间内重复查询返回缓存）
+	GetMemoryBreakdown(pid int32) (*types.MemoryBreakdown, error)
+	// GetLaunchSnapshot 获取进程启动信息（命令行、工作目录、父进程、启动时间、环境变量）
+	GetLaunchSnapshot(pid int32) (*types.LaunchSnapshot, error)
+	// GetProcessHistory 按需每秒采样任意进程，返回最近 seconds 秒的指标（首次查询会等待采样完成）
+	GetProcessHistory(ctx context.Context, pid int32, seconds int) ([]types.ProcessMetrics, error)
+	// GetCPUAffinity 获取进程 CPU 亲和性（允许运行的核心列表）
+	GetCPUAffinity(pid int32) ([]int, error)
+	// GetAnonVMS 获取进程匿名映射的虚拟内存（不含文件映射和共享内存），平台不支持或读取失败时返回错误
+	GetAnonVMS(pid int32) (uint64, error)
+	// GetCPUStyle 获取当前进程 CPU 口径（irix/solaris）
+	GetCPUStyle() string
+	// SetCPUStyle 修改进程 CPU 口径，并重置 CPU 采样基准
+	SetCPUStyle(style string) error
+	// SetNameAliases 替换进程名到显示名称的映射
+	SetNameAliases(aliases map[string]string)
 	// GetSystemMetrics 获取系统指标
 	GetSystemMetrics() (*types.SystemMetrics, error)
+	// Close 停止后台采样协程（系统指标、进程网络监控、按需采样），重复调用无副作用
+	Close()
 }
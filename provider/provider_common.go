@@ -1,7 +1,10 @@
 package provider
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"strings"
 	"sync"
 	"time"
 
@@ -37,6 +40,17 @@ type rssSample struct {
 	growthRate float64
 }
 
+// 句柄数采样状态（用于计算增长速率）
+// 句柄数随请求频繁起伏，按至少 fdGrowthWindow 的间隔计算，避免瞬时波动被当作泄漏
+type fdSample struct {
+	count      int32
+	sampleTime time.Time
+	growthRate float64 // 个/分钟
+}
+
+// fdGrowthWindow 计算句柄数增长速率的最小间隔
+const fdGrowthWindow = time.Minute
+
 // 进程 CPU 采样状态
 type cpuSample struct {
 	cpuTime    float64 // 累计 CPU 时间（秒）
@@ -62,8 +76,13 @@ type systemSample struct {
 	cpuSystemPct float64
 	cpuIdlePct   float64
 	cpuIowaitPct float64
+	cpuStealPct  float64
 	cpuTotalPct  float64
 
+	// 各核心 CPU 采样（按核心索引）
+	coreTimes []cpu.TimesStat
+	corePct   []float64
+
 	// Swap 采样
 	swapIn      uint64
 	swapOut     uint64
@@ -80,6 +99,10 @@ type systemSample struct {
 	diskReadOps    float64
 	diskWriteOps   float64
 
+	// 最近一次采样是否读取失败，失败时保留上次的结果并在系统指标中标记为降级
+	cpuFailed  bool
+	diskFailed bool
+
 	sampleTime time.Time
 }
 
@@ -97,9 +120,15 @@ type commonProvider struct {
 	ioSamples    map[int32]*ioSample
 	rssSamplesMu sync.RWMutex
 	rssSamples   map[int32]*rssSample
+	fdSamplesMu  sync.Mutex
+	fdSamples    map[int32]*fdSample
 	cpuSamplesMu sync.RWMutex
 	cpuSamples   map[int32]*cpuSample
 
+	// 调度等待采样（仅监控目标，GetMetrics 时更新）
+	schedSamplesMu sync.Mutex
+	schedSamples   map[int32]*schedSample
+
 	// 系统级采样缓存
 	sysSampleMu sync.RWMutex
 	sysSample   *systemSample
@@ -113,33 +142,78 @@ type commonProvider struct {
 	listenPorts      map[int32][]int
 	listenPortsTime  time.Time
 
+	// 内存明细缓存
+	memDetailMu sync.Mutex
+	memDetail   map[int32]*memDetailEntry
+
+	// 按需采样器（任意进程的短期历史，与监控目标无关）
+	historyMu sync.Mutex
+	history   map[int32]*historySampler
+
 	// 进程网络监控
 	netMonitor *netmon.NetMonitor
 
+	// 后台协程（系统指标采样、按需采样器）：Close 关闭 stopCh 后等待 bgWG，
+	// 按需采样器在 historyMu 内登记，关闭后不再新建
+	stopCh    chan struct{}
+	closeOnce sync.Once
+	bgWG      sync.WaitGroup
+
 	// CPU 核心数（用于计算进程 CPU 百分比）
 	numCPU int
 
-	// 是否将进程 CPU 除以核心数（Windows 风格 = true，Linux 风格 = false）
+	// 是否将进程 CPU 除以核心数（solaris 口径 = true，irix 口径 = false），受 cpuSamplesMu 保护
 	divideByNumCPU bool
 
+	// 是否去掉进程名的 .exe 后缀（仅影响显示名称，匹配仍由 matchProcessName 负责）
+	stripExeSuffix bool
+
+	// 采集进程信息的并发协程数，0 或 1 表示顺序采集
+	collectWorkers int
+
+	// 进程名到显示名称的映射（写入 ProcessInfo.DisplayName）
+	aliasMu     sync.RWMutex
+	nameAliases map[string]string
+
 	// 平台特定函数
 	matchProcessName   func(procName, targetName string) bool
 	formatCmdline      func(exe string) string
 	getHandleCount     func(pid int32) int32
 	getPriority        func(pid int32) int32
 	getFileDescription func(exePath string) string
+	getCPUAffinity     func(pid int32) []int
+	readSchedDelay     func(pid int32) (SchedDelay, bool)
+	readAnonVMS        func(pid int32) (uint64, error)
+}
+
+// platformOptions 平台相关的采集方式，由各平台的 newPlatformOptions 提供
+// 函数为 nil 时使用 gopsutil 的默认实现（或不采集该项）
+type platformOptions struct {
+	// MatchProcessName 进程名是否匹配目标名（Windows 需兼容 .exe 后缀）
+	MatchProcessName func(procName, targetName string) bool
+	// FormatCmdline 无法读取命令行时由可执行文件路径构造的显示值
+	FormatCmdline func(exe string) string
+	// GetHandleCount 句柄数，nil 时使用 NumFDs
+	GetHandleCount func(pid int32) int32
+	// GetPriority 优先级，nil 时由 Nice 换算（优先级 = 20 - nice）
+	GetPriority func(pid int32) int32
+	// GetFileDescription 可执行文件的描述信息，nil 时不采集
+	GetFileDescription func(exePath string) string
+	// GetCPUAffinity 进程允许运行的核心，nil 时不采集
+	GetCPUAffinity func(pid int32) []int
+	// ReadSchedDelay 进程累计的调度等待时间，nil 时不采集（ProcessMetrics 的 RunWaitPct/BlockedPct 为 nil）
+	ReadSchedDelay func(pid int32) (SchedDelay, bool)
+	// ReadAnonVMS 进程匿名映射的虚拟内存，nil 时不支持（风险分析按完整 VMS 比较）
+	ReadAnonVMS func(pid int32) (uint64, error)
+	// CPUStyle 默认进程 CPU 口径（irix/solaris），可由 Options.CPUStyle 覆盖
+	CPUStyle string
 }
 
+// 各平台的 newPlatformOptions 共用同一个 commonProvider，发布前以 GOOS=linux/windows 分别编译检查
+var _ ProcProvider = (*commonProvider)(nil)
+
 // newCommonProvider 创建通用 provider
-// divideByNumCPU: Windows 风格设为 true（进程CPU最大100%），Linux 风格设为 false（单核100%，可超过100%）
-func newCommonProvider(
-	matchName func(procName, targetName string) bool,
-	fmtCmdline func(exe string) string,
-	getHandles func(pid int32) int32,
-	getPrio func(pid int32) int32,
-	getFileDesc func(exePath string) string,
-	divideByNumCPU bool,
-) *commonProvider {
+func newCommonProvider(opts platformOptions) *commonProvider {
 	numCPU, _ := cpu.Counts(true)
 	if numCPU == 0 {
 		numCPU = 1
@@ -148,31 +222,67 @@ func newCommonProvider(
 	p := &commonProvider{
 		ioSamples:          make(map[int32]*ioSample),
 		rssSamples:         make(map[int32]*rssSample),
+		fdSamples:          make(map[int32]*fdSample),
 		cpuSamples:         make(map[int32]*cpuSample),
+		schedSamples:       make(map[int32]*schedSample),
 		sysSample:          &systemSample{sampleTime: time.Now()},
 		procCache:          &processListCache{cacheTTL: 500 * time.Millisecond}, // 500ms 缓存
 		listenPorts:        make(map[int32][]int),
+		memDetail:          make(map[int32]*memDetailEntry),
+		history:            make(map[int32]*historySampler),
+		stopCh:             make(chan struct{}),
 		numCPU:             numCPU,
-		divideByNumCPU:     divideByNumCPU,
-		matchProcessName:   matchName,
-		formatCmdline:      fmtCmdline,
-		getHandleCount:     getHandles,
-		getPriority:        getPrio,
-		getFileDescription: getFileDesc,
-		netMonitor:         netmon.New(),
+		divideByNumCPU:     opts.CPUStyle == CPUStyleSolaris,
+		matchProcessName:   opts.MatchProcessName,
+		formatCmdline:      opts.FormatCmdline,
+		getHandleCount:     opts.GetHandleCount,
+		getPriority:        opts.GetPriority,
+		getFileDescription: opts.GetFileDescription,
+		getCPUAffinity:     opts.GetCPUAffinity,
+		readSchedDelay:     opts.ReadSchedDelay,
+		readAnonVMS:        opts.ReadAnonVMS,
 	}
 
 	// 初始化系统 CPU 采样
 	p.initSystemCPUSample()
 
-	go p.sampleSystemMetrics()
+	p.bgWG.Add(1)
+	go func() {
+		defer p.bgWG.Done()
+		p.sampleSystemMetrics()
+	}()
+
+	return p
+}
+
+// Close 停止后台采样：系统指标采样、按需采样器和进程网络监控，等待协程退出后返回。
+// 重复调用只生效一次，关闭后系统指标不再更新，GetProcessHistory 返回 ErrClosed
+func (p *commonProvider) Close() {
+	p.closeOnce.Do(func() {
+		p.historyMu.Lock()
+		close(p.stopCh)
+		p.historyMu.Unlock()
+
+		if p.netMonitor != nil {
+			p.netMonitor.Stop()
+		}
+		p.bgWG.Wait()
+	})
+}
 
-	// 启动进程网络监控
-	if err := p.netMonitor.Start(); err != nil {
+// startNetMonitor 启动进程网络监控
+// 网卡过滤配置无效（通配符错误或没有匹配的网卡）时回退为统计所有网卡
+func (p *commonProvider) startNetMonitor(opts netmon.Options) {
+	p.netMonitor = netmon.New(opts)
+	err := p.netMonitor.Start()
+	if err != nil && (len(opts.Interfaces) > 0 || len(opts.ExcludeInterfaces) > 0) {
+		fmt.Printf("[Provider] 网卡过滤配置无效: %v，改为统计所有网卡\n", err)
+		p.netMonitor = netmon.New(netmon.Options{})
+		err = p.netMonitor.Start()
+	}
+	if err != nil {
 		fmt.Printf("[Provider] 进程网络监控启动失败: %v\n", err)
 	}
-
-	return p
 }
 
 // initSystemCPUSample 初始化系统 CPU 采样基准值
@@ -195,6 +305,17 @@ func (p *commonProvider) initSystemCPUSample() {
 	p.sysSample.cpuTotal = t.User + t.System + t.Idle + t.Nice + t.Iowait + t.Irq + t.Softirq + t.Steal
 	p.sysSample.sampleTime = time.Now()
 	p.sysSampleMu.Unlock()
+
+	if coreTimes, err := cpu.Times(true); err == nil {
+		p.sysSampleMu.Lock()
+		p.sysSample.coreTimes = coreTimes
+		p.sysSampleMu.Unlock()
+	}
+}
+
+// cpuTimesTotal 计算 CPU 累计总时间
+func cpuTimesTotal(t cpu.TimesStat) float64 {
+	return t.User + t.System + t.Idle + t.Nice + t.Iowait + t.Irq + t.Softirq + t.Steal
 }
 
 // sampleSystemMetrics 后台定时采集系统指标
@@ -202,8 +323,13 @@ func (p *commonProvider) sampleSystemMetrics() {
 	ticker := time.NewTicker(time.Second)
 	defer ticker.Stop()
 
-	for range ticker.C {
-		p.collectSystemSample()
+	for {
+		select {
+		case <-p.stopCh:
+			return
+		case <-ticker.C:
+			p.collectSystemSample()
+		}
 	}
 }
 
@@ -212,7 +338,8 @@ func (p *commonProvider) collectSystemSample() {
 	now := time.Now()
 
 	// CPU 时间采样
-	cpuTimes, _ := cpu.Times(false)
+	cpuTimes, cpuErr := cpu.Times(false)
+	coreTimes, _ := cpu.Times(true)
 
 	// Swap 指标
 	swapInfo, _ := mem.SwapMemory()
@@ -223,7 +350,7 @@ func (p *commonProvider) collectSystemSample() {
 	}
 
 	// 系统磁盘 IO
-	diskStats, _ := disk.IOCounters()
+	diskStats, diskErr := disk.IOCounters()
 	var diskReadBytes, diskWriteBytes, diskReadCount, diskWriteCount uint64
 	for _, stat := range diskStats {
 		diskReadBytes += stat.ReadBytes
@@ -236,6 +363,17 @@ func (p *commonProvider) collectSystemSample() {
 	p.sysSampleMu.Lock()
 	defer p.sysSampleMu.Unlock()
 
+	p.sysSample.cpuFailed = cpuErr != nil || len(cpuTimes) == 0
+	p.sysSample.diskFailed = diskErr != nil
+	if swapInfo == nil {
+		swapIn, swapOut = p.sysSample.swapIn, p.sysSample.swapOut
+	}
+	if p.sysSample.diskFailed {
+		// 沿用上次的累计值，避免读取失败的 0 与上次相减得到巨大的速率
+		diskReadBytes, diskWriteBytes = p.sysSample.diskReadBytes, p.sysSample.diskWriteBytes
+		diskReadCount, diskWriteCount = p.sysSample.diskReadCount, p.sysSample.diskWriteCount
+	}
+
 	deltaTime := now.Sub(p.sysSample.sampleTime).Seconds()
 	if deltaTime > 0.1 {
 		// CPU 增量计算
@@ -249,11 +387,13 @@ func (p *commonProvider) collectSystemSample() {
 				deltaSystem := t.System - p.sysSample.cpuSystem
 				deltaIdle := t.Idle - p.sysSample.cpuIdle
 				deltaIowait := t.Iowait - p.sysSample.cpuIowait
+				deltaSteal := t.Steal - p.sysSample.cpuSteal
 
 				p.sysSample.cpuUserPct = deltaUser / deltaTotal * 100
 				p.sysSample.cpuSystemPct = deltaSystem / deltaTotal * 100
 				p.sysSample.cpuIdlePct = deltaIdle / deltaTotal * 100
 				p.sysSample.cpuIowaitPct = deltaIowait / deltaTotal * 100
+				p.sysSample.cpuStealPct = deltaSteal / deltaTotal * 100
 				p.sysSample.cpuTotalPct = 100 - p.sysSample.cpuIdlePct
 			}
 
@@ -269,6 +409,22 @@ func (p *commonProvider) collectSystemSample() {
 			p.sysSample.cpuTotal = currentTotal
 		}
 
+		// 各核心 CPU 增量计算（核心数变化时重新建立基准）
+		if len(coreTimes) > 0 {
+			if len(coreTimes) == len(p.sysSample.coreTimes) {
+				corePct := make([]float64, len(coreTimes))
+				for i, t := range coreTimes {
+					prev := p.sysSample.coreTimes[i]
+					deltaTotal := cpuTimesTotal(t) - cpuTimesTotal(prev)
+					if deltaTotal > 0 {
+						corePct[i] = 100 - (t.Idle-prev.Idle)/deltaTotal*100
+					}
+				}
+				p.sysSample.corePct = corePct
+			}
+			p.sysSample.coreTimes = coreTimes
+		}
+
 		// Swap 速率
 		p.sysSample.swapInRate = float64(swapIn-p.sysSample.swapIn) / deltaTime
 		p.sysSample.swapOutRate = float64(swapOut-p.sysSample.swapOut) / deltaTime
@@ -290,6 +446,45 @@ func (p *commonProvider) collectSystemSample() {
 	p.sysSample.sampleTime = now
 }
 
+// displayName 根据配置规范化进程显示名称
+func (p *commonProvider) displayName(name string) string {
+	if p.stripExeSuffix && strings.HasSuffix(strings.ToLower(name), ".exe") {
+		return name[:len(name)-4]
+	}
+	return name
+}
+
+// nameAlias 查找进程的显示名称，依次按规范化后的名称、原始名称和去掉 .exe 后缀的名称查找，未配置时返回空
+func (p *commonProvider) nameAlias(raw, name string) string {
+	p.aliasMu.RLock()
+	defer p.aliasMu.RUnlock()
+	if alias, ok := p.nameAliases[name]; ok {
+		return alias
+	}
+	if alias, ok := p.nameAliases[raw]; ok {
+		return alias
+	}
+	if strings.HasSuffix(strings.ToLower(raw), ".exe") {
+		return p.nameAliases[raw[:len(raw)-4]]
+	}
+	return ""
+}
+
+// SetNameAliases 替换进程名到显示名称的映射，并清空进程列表缓存使其立即生效
+func (p *commonProvider) SetNameAliases(aliases map[string]string) {
+	copied := make(map[string]string, len(aliases))
+	for name, alias := range aliases {
+		copied[name] = alias
+	}
+	p.aliasMu.Lock()
+	p.nameAliases = copied
+	p.aliasMu.Unlock()
+
+	p.procCacheMu.Lock()
+	p.procCache.cacheTime = time.Time{}
+	p.procCacheMu.Unlock()
+}
+
 func (p *commonProvider) FindAllPIDsByName(name string) ([]int32, error) {
 	procs, err := process.Processes()
 	if err != nil {
@@ -327,19 +522,106 @@ func (p *commonProvider) GetMetrics(pid int32) (*types.ProcessMetrics, error) {
 	cpuPct := p.calcProcessCPU(pid, proc)
 	memInfo, _ := proc.MemoryInfo()
 	name, _ := proc.Name()
+	name = p.displayName(name)
+
+	priority, nice := p.readPriority(proc)
 
 	var rss uint64
 	if memInfo != nil {
 		rss = memInfo.RSS
 	}
 
-	return &types.ProcessMetrics{
+	m := &types.ProcessMetrics{
 		PID:      pid,
 		Name:     name,
 		CPUPct:   cpuPct,
 		RSSBytes: rss,
+		Priority: priority,
+		Nice:     nice,
 		Alive:    true,
-	}, nil
+	}
+	p.fillSchedWait(pid, m)
+	return m, nil
+}
+
+// GetExecPaths 获取进程可执行文件路径和工作目录
+func (p *commonProvider) GetExecPaths(pid int32) (string, string, error) {
+	proc, err := process.NewProcess(pid)
+	if err != nil {
+		return "", "", err
+	}
+	exe, err := proc.Exe()
+	if err != nil {
+		return "", "", err
+	}
+	cwd, _ := proc.Cwd()
+	return exe, cwd, nil
+}
+
+// GetLaunchSnapshot 读取进程启动信息：命令行、工作目录、父进程、启动时间和全部环境变量（未过滤）
+// 环境变量读取失败（通常是权限不足）时记录在 EnvError 中，不视为错误
+func (p *commonProvider) GetLaunchSnapshot(pid int32) (*types.LaunchSnapshot, error) {
+	proc, err := process.NewProcess(pid)
+	if err != nil {
+		return nil, err
+	}
+	name, _ := proc.Name()
+	snap := &types.LaunchSnapshot{
+		PID:        pid,
+		Name:       p.displayName(name),
+		CapturedAt: time.Now(),
+	}
+	snap.Cmdline, _ = proc.Cmdline()
+	snap.Cwd, _ = proc.Cwd()
+	snap.PPID, _ = proc.Ppid()
+	if ms, err := proc.CreateTime(); err == nil {
+		snap.StartTime = time.UnixMilli(ms)
+	}
+
+	env, err := proc.Environ()
+	if err != nil {
+		snap.EnvError = err.Error()
+		return snap, nil
+	}
+	snap.Env = make(map[string]string, len(env))
+	for _, kv := range env {
+		// 跳过 Windows 中以 = 开头的隐藏变量（如 "=C:"）
+		if i := strings.Index(kv, "="); i > 0 {
+			snap.Env[kv[:i]] = kv[i+1:]
+		}
+	}
+	return snap, nil
+}
+
+// ProcessKey 进程实例标识：PID 被复用或进程重启后启动时间不同，可据此区分同一 PID 上的不同进程
+type ProcessKey struct {
+	PID        int32
+	CreateTime int64 // 启动时间（Unix 毫秒）
+}
+
+// GetProcessCreateTimeKeyed 读取进程启动时间，返回 PID 加启动时间的实例标识（两个平台共用）
+func GetProcessCreateTimeKeyed(pid int32) (ProcessKey, error) {
+	key := ProcessKey{PID: pid}
+	proc, err := process.NewProcess(pid)
+	if err != nil {
+		return key, err
+	}
+	key.CreateTime, err = proc.CreateTime()
+	return key, err
+}
+
+// readPriority 读取进程优先级和 Nice 值
+// Windows 使用优先级类对应的基础优先级；Linux 优先级为 20 - nice
+func (p *commonProvider) readPriority(proc *process.Process) (priority, nice int32) {
+	if p.getPriority != nil {
+		return p.getPriority(proc.Pid), 0
+	}
+	// gopsutil 在 Linux 上返回 getpriority 系统调用的原始值，即 20 - nice（1~40）
+	raw, err := proc.Nice()
+	if err != nil {
+		return 0, 0
+	}
+	return raw, 20 - raw
 }
 
 func (p *commonProvider) IsAlive(pid int32) bool {
@@ -351,6 +633,26 @@ func (p *commonProvider) IsAlive(pid int32) bool {
 	return running
 }
 
+// GetCPUAffinity 获取进程允许运行的 CPU 核心列表
+func (p *commonProvider) GetCPUAffinity(pid int32) ([]int, error) {
+	if p.getCPUAffinity == nil {
+		return nil, fmt.Errorf("当前平台不支持读取 CPU 亲和性")
+	}
+	cores := p.getCPUAffinity(pid)
+	if len(cores) == 0 {
+		return nil, fmt.Errorf("无法读取进程 %d 的 CPU 亲和性", pid)
+	}
+	return cores, nil
+}
+
+// GetAnonVMS 获取进程匿名映射的虚拟内存
+func (p *commonProvider) GetAnonVMS(pid int32) (uint64, error) {
+	if p.readAnonVMS == nil {
+		return 0, fmt.Errorf("当前平台不支持读取匿名虚拟内存")
+	}
+	return p.readAnonVMS(pid)
+}
+
 // calcDiskIO 计算进程磁盘 IO 速率
 func (p *commonProvider) calcDiskIO(pid int32, readBytes, writeBytes, readCount, writeCount uint64) (readRate, writeRate, readOps, writeOps float64) {
 	now := time.Now()
@@ -424,6 +726,74 @@ func (p *commonProvider) calcRSSGrowth(pid int32, rss uint64) float64 {
 	return growthRate
 }
 
+// calcFDGrowth 计算句柄数增长速率（个/分钟），间隔不足 fdGrowthWindow 时返回上次的结果
+func (p *commonProvider) calcFDGrowth(pid int32, count int32) float64 {
+	now := time.Now()
+
+	p.fdSamplesMu.Lock()
+	defer p.fdSamplesMu.Unlock()
+
+	sample, exists := p.fdSamples[pid]
+	if !exists {
+		p.fdSamples[pid] = &fdSample{
+			count:      count,
+			sampleTime: now,
+		}
+		return 0
+	}
+
+	deltaTime := now.Sub(sample.sampleTime)
+	if deltaTime < fdGrowthWindow {
+		return sample.growthRate
+	}
+
+	// 计算增长速率（可能为负数表示句柄释放）
+	sample.growthRate = float64(count-sample.count) / deltaTime.Minutes()
+	sample.count = count
+	sample.sampleTime = now
+
+	return sample.growthRate
+}
+
+// GetCPUStyle 获取当前进程 CPU 口径
+func (p *commonProvider) GetCPUStyle() string {
+	p.cpuSamplesMu.RLock()
+	defer p.cpuSamplesMu.RUnlock()
+	if p.divideByNumCPU {
+		return CPUStyleSolaris
+	}
+	return CPUStyleIrix
+}
+
+// SetCPUStyle 修改进程 CPU 口径
+// 口径变化时清空 CPU 采样和进程列表缓存，避免新旧口径混用产生一次错误读数
+func (p *commonProvider) SetCPUStyle(style string) error {
+	var divide bool
+	switch style {
+	case CPUStyleSolaris:
+		divide = true
+	case CPUStyleIrix:
+		divide = false
+	default:
+		return fmt.Errorf("未知的 CPU 口径: %s（可选 %s/%s）", style, CPUStyleIrix, CPUStyleSolaris)
+	}
+
+	p.cpuSamplesMu.Lock()
+	changed := p.divideByNumCPU != divide
+	if changed {
+		p.divideByNumCPU = divide
+		p.cpuSamples = make(map[int32]*cpuSample)
+	}
+	p.cpuSamplesMu.Unlock()
+
+	if changed {
+		p.procCacheMu.Lock()
+		p.procCache.cacheTime = time.Time{}
+		p.procCacheMu.Unlock()
+	}
+	return nil
+}
+
 // calcProcessCPU 计算进程 CPU 使用率（增量方式）
 func (p *commonProvider) calcProcessCPU(pid int32, proc *process.Process) float64 {
 	now := time.Now()
@@ -457,8 +827,8 @@ func (p *commonProvider) calcProcessCPU(pid int32, proc *process.Process) float6
 	deltaCPU := currentCPUTime - sample.cpuTime
 	cpuPct := (deltaCPU / deltaTime) * 100
 
-	// Windows 风格：除以核心数，最大 100%
-	// Linux 风格：不除，单核 100%，多核可超过 100%
+	// solaris 口径：除以核心数，最大 100%
+	// irix 口径：不除，单核 100%，多核可超过 100%
 	if p.divideByNumCPU && p.numCPU > 0 {
 		cpuPct = cpuPct / float64(p.numCPU)
 	}
@@ -508,139 +878,11 @@ func (p *commonProvider) collectAllProcesses() ([]types.ProcessInfo, error) {
 	// 获取所有网络连接，用于统计每个进程的监听端口
 	listenPorts := p.getProcessListenPorts()
 
-	alivePids := make(map[int32]bool)
-	var result []types.ProcessInfo
-
+	alivePids := make(map[int32]bool, len(procs))
 	for _, proc := range procs {
 		alivePids[proc.Pid] = true
-
-		name, _ := proc.Name()
-		memInfo, _ := proc.MemoryInfo()
-		status, _ := proc.Status()
-		username, _ := proc.Username()
-		cmdline, _ := proc.Cmdline()
-		ioCounters, _ := proc.IOCounters()
-		createTime, _ := proc.CreateTime()
-
-		// 使用增量方式计算进程 CPU
-		cpuPct := p.calcProcessCPU(proc.Pid, proc)
-
-		// 获取句柄数/文件描述符数
-		var numFDs int32
-		if p.getHandleCount != nil {
-			numFDs = p.getHandleCount(proc.Pid)
-		} else {
-			numFDs, _ = proc.NumFDs()
-		}
-
-		// 获取线程数
-		numThreads, _ := proc.NumThreads()
-
-		// 获取优先级和 Nice 值
-		var priority int32
-		var nice int32
-		if p.getPriority != nil {
-			priority = p.getPriority(proc.Pid)
-		} else {
-			niceVal, err := proc.Nice()
-			if err == nil {
-				nice = niceVal
-				// Linux: 将 nice 值转换为优先级 (20 - nice)
-				priority = 20 - niceVal
-			}
-		}
-
-		// 获取可执行文件路径
-		exePath, _ := proc.Exe()
-
-		// 如果 cmdline 为空，尝试获取可执行文件路径
-		if cmdline == "" {
-			if exePath != "" {
-				cmdline = p.formatCmdline(exePath)
-			}
-		}
-
-		// 获取文件描述信息
-		var description string
-		if p.getFileDescription != nil && exePath != "" {
-			description = p.getFileDescription(exePath)
-		}
-
-		var rss, vms uint64
-		if memInfo != nil {
-			rss = memInfo.RSS
-			vms = memInfo.VMS
-		}
-
-		statusStr := ""
-		if len(status) > 0 {
-			statusStr = status[0]
-		}
-
-		// 计算磁盘 IO 速率
-		var diskIO, diskReadRate, diskWriteRate, diskReadOps, diskWriteOps float64
-		if ioCounters != nil {
-			diskReadRate, diskWriteRate, diskReadOps, diskWriteOps = p.calcDiskIO(
-				proc.Pid,
-				ioCounters.ReadBytes, ioCounters.WriteBytes,
-				ioCounters.ReadCount, ioCounters.WriteCount,
-			)
-			diskIO = diskReadRate + diskWriteRate
-		}
-
-		// 计算 RSS 增长速率
-		rssGrowthRate := p.calcRSSGrowth(proc.Pid, rss)
-
-		// 计算已运行时间（秒）
-		var uptime int64
-		if createTime > 0 {
-			uptime = (time.Now().UnixMilli() - createTime) / 1000
-		}
-
-		// 获取进程网络流量
-		var netRecvRate, netSendRate float64
-		if p.netMonitor != nil {
-			netStats := p.netMonitor.GetStats(proc.Pid)
-			netRecvRate = netStats.RecvRate
-			netSendRate = netStats.SendRate
-		}
-
-		// 获取进程打开的文件数（使用 NumFDs 作为代理）
-		openFiles := int(numFDs)
-
-		// 获取进程监听的端口
-		var ports []int
-		if p, ok := listenPorts[proc.Pid]; ok {
-			ports = p
-		}
-
-		result = append(result, types.ProcessInfo{
-			PID:           proc.Pid,
-			Name:          name,
-			CPUPct:        cpuPct,
-			RSSBytes:      rss,
-			RSSGrowthRate: rssGrowthRate,
-			VMS:           vms,
-			Status:        statusStr,
-			Username:      username,
-			NumFDs:        numFDs,
-			NumThreads:    numThreads,
-			Priority:      priority,
-			Nice:          nice,
-			DiskIO:        diskIO,
-			DiskReadRate:  diskReadRate,
-			DiskWriteRate: diskWriteRate,
-			DiskReadOps:   diskReadOps,
-			DiskWriteOps:  diskWriteOps,
-			NetRecvRate:   netRecvRate,
-			NetSendRate:   netSendRate,
-			Uptime:        uptime,
-			Cmdline:       cmdline,
-			Description:   description,
-			OpenFiles:     openFiles,
-			ListenPorts:   ports,
-		})
 	}
+	result := p.collectProcesses(procs, listenPorts)
 
 	// 清理已退出进程的采样数据
 	p.ioSamplesMu.Lock()
@@ -659,6 +901,14 @@ func (p *commonProvider) collectAllProcesses() ([]types.ProcessInfo, error) {
 	}
 	p.rssSamplesMu.Unlock()
 
+	p.fdSamplesMu.Lock()
+	for pid := range p.fdSamples {
+		if !alivePids[pid] {
+			delete(p.fdSamples, pid)
+		}
+	}
+	p.fdSamplesMu.Unlock()
+
 	p.cpuSamplesMu.Lock()
 	for pid := range p.cpuSamples {
 		if !alivePids[pid] {
@@ -667,6 +917,14 @@ func (p *commonProvider) collectAllProcesses() ([]types.ProcessInfo, error) {
 	}
 	p.cpuSamplesMu.Unlock()
 
+	p.schedSamplesMu.Lock()
+	for pid := range p.schedSamples {
+		if !alivePids[pid] {
+			delete(p.schedSamples, pid)
+		}
+	}
+	p.schedSamplesMu.Unlock()
+
 	// 清理 netmon 中的进程统计
 	if p.netMonitor != nil {
 		p.netMonitor.CleanupPids(alivePids)
@@ -675,6 +933,196 @@ func (p *commonProvider) collectAllProcesses() ([]types.ProcessInfo, error) {
 	return result, nil
 }
 
+// collectProcesses 采集各进程的信息，collect_workers 大于 1 时由相应数量的协程并发采集。
+// 各进程的增量采样（CPU、磁盘 IO、RSS、句柄数）按 PID 分别加锁更新，并发采集不影响计算结果；
+// 结果按进程列表的顺序返回
+func (p *commonProvider) collectProcesses(procs []*process.Process, listenPorts map[int32][]int) []types.ProcessInfo {
+	result := make([]types.ProcessInfo, len(procs))
+	workers := p.collectWorkers
+	if workers > len(procs) {
+		workers = len(procs)
+	}
+	if workers <= 1 {
+		for i, proc := range procs {
+			result[i] = p.collectProcess(proc, listenPorts)
+		}
+		return result
+	}
+
+	next := make(chan int, len(procs))
+	for i := range procs {
+		next <- i
+	}
+	close(next)
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				result[i] = p.collectProcess(procs[i], listenPorts)
+			}
+		}()
+	}
+	wg.Wait()
+	return result
+}
+
+// collectProcess 采集单个进程的信息
+func (p *commonProvider) collectProcess(proc *process.Process, listenPorts map[int32][]int) types.ProcessInfo {
+	rawName, _ := proc.Name()
+	name := p.displayName(rawName)
+	ppid, _ := proc.Ppid()
+	memInfo, _ := proc.MemoryInfo()
+	status, _ := proc.Status()
+	username, _ := proc.Username()
+	// 记录因权限不足读取失败的字段，避免运维人员把 0 误认为真实值
+	var restricted []string
+	checkPerm := func(field string, err error) {
+		if isPermissionError(err) {
+			restricted = append(restricted, field)
+		}
+	}
+
+	cmdline, err := proc.Cmdline()
+	checkPerm("cmdline", err)
+	ioCounters, err := proc.IOCounters()
+	checkPerm("disk_io", err)
+	createTime, _ := proc.CreateTime()
+
+	// 使用增量方式计算进程 CPU
+	cpuPct := p.calcProcessCPU(proc.Pid, proc)
+
+	// 获取句柄数/文件描述符数
+	var numFDs int32
+	if p.getHandleCount != nil {
+		numFDs = p.getHandleCount(proc.Pid)
+	} else {
+		numFDs, err = proc.NumFDs()
+		checkPerm("fds", err)
+	}
+
+	// 获取线程数
+	numThreads, _ := proc.NumThreads()
+
+	// 获取优先级和 Nice 值
+	priority, nice := p.readPriority(proc)
+
+	// 获取可执行文件路径
+	exePath, err := proc.Exe()
+	checkPerm("exe", err)
+
+	// 如果 cmdline 为空，尝试获取可执行文件路径
+	if cmdline == "" {
+		if exePath != "" {
+			cmdline = p.formatCmdline(exePath)
+		}
+	}
+
+	// 获取文件描述信息
+	var description string
+	if p.getFileDescription != nil && exePath != "" {
+		description = p.getFileDescription(exePath)
+	}
+
+	var rss, vms uint64
+	if memInfo != nil {
+		rss = memInfo.RSS
+		vms = memInfo.VMS
+	}
+
+	statusStr := ""
+	if len(status) > 0 {
+		statusStr = status[0]
+	}
+
+	// 计算磁盘 IO 速率
+	var diskIO, diskReadRate, diskWriteRate, diskReadOps, diskWriteOps float64
+	if ioCounters != nil {
+		diskReadRate, diskWriteRate, diskReadOps, diskWriteOps = p.calcDiskIO(
+			proc.Pid,
+			ioCounters.ReadBytes, ioCounters.WriteBytes,
+			ioCounters.ReadCount, ioCounters.WriteCount,
+		)
+		diskIO = diskReadRate + diskWriteRate
+	}
+
+	// 计算 RSS 增长速率
+	rssGrowthRate := p.calcRSSGrowth(proc.Pid, rss)
+
+	// 计算句柄数增长速率
+	fdGrowthRate := p.calcFDGrowth(proc.Pid, numFDs)
+
+	// 计算已运行时间（秒）
+	var uptime int64
+	if createTime > 0 {
+		uptime = (time.Now().UnixMilli() - createTime) / 1000
+	}
+
+	// 获取进程网络流量
+	var netRecvRate, netSendRate float64
+	if p.netMonitor != nil {
+		netStats := p.netMonitor.GetStats(proc.Pid)
+		netRecvRate = netStats.RecvRate
+		netSendRate = netStats.SendRate
+	}
+
+	// 获取进程打开的文件数（使用 NumFDs 作为代理）
+	openFiles := int(numFDs)
+
+	// 获取 CPU 亲和性（允许运行的核心）
+	var affinity []int
+	if p.getCPUAffinity != nil {
+		affinity = p.getCPUAffinity(proc.Pid)
+	}
+
+	// 获取进程监听的端口
+	var ports []int
+	if p, ok := listenPorts[proc.Pid]; ok {
+		ports = p
+	}
+
+	return types.ProcessInfo{
+		PID:           proc.Pid,
+		PPID:          ppid,
+		Name:          name,
+		DisplayName:   p.nameAlias(rawName, name),
+		CPUPct:        cpuPct,
+		RSSBytes:      rss,
+		RSSGrowthRate: rssGrowthRate,
+		FDGrowthRate:  fdGrowthRate,
+		VMS:           vms,
+		Status:        statusStr,
+		Username:      username,
+		NumFDs:        numFDs,
+		NumThreads:    numThreads,
+		Priority:      priority,
+		Nice:          nice,
+		DiskIO:        diskIO,
+		DiskReadRate:  diskReadRate,
+		DiskWriteRate: diskWriteRate,
+		DiskReadOps:   diskReadOps,
+		DiskWriteOps:  diskWriteOps,
+		NetRecvRate:   netRecvRate,
+		NetSendRate:   netSendRate,
+		Uptime:        uptime,
+		Cmdline:       cmdline,
+		Description:   description,
+		OpenFiles:     openFiles,
+		ListenPorts:   ports,
+		CPUAffinity:   affinity,
+
+		Restricted:       len(restricted) > 0,
+		RestrictedFields: restricted,
+	}
+}
+
+// isPermissionError 判断是否为权限不足（EPERM/EACCES，Windows 上为 ERROR_ACCESS_DENIED）
+func isPermissionError(err error) bool {
+	return err != nil && errors.Is(err, os.ErrPermission)
+}
+
 // getProcessListenPorts 获取所有进程的监听端口（带缓存，3秒更新一次）
 func (p *commonProvider) getProcessListenPorts() map[int32][]int {
 	p.listenPortsMu.RLock()
@@ -720,9 +1168,19 @@ func (p *commonProvider) getProcessListenPorts() map[int32][]int {
 }
 
 func (p *commonProvider) GetSystemMetrics() (*types.SystemMetrics, error) {
+	// 某个子系统读取失败时仍返回其余指标，失败的子系统记入 Degraded，避免把读取失败显示成 0%
+	var degraded []string
+
 	// 内存指标
-	memInfo, _ := mem.VirtualMemory()
-	swapInfo, _ := mem.SwapMemory()
+	memInfo, err := mem.VirtualMemory()
+	if err != nil || memInfo == nil {
+		memInfo = &mem.VirtualMemoryStat{}
+		degraded = append(degraded, types.SubsystemMemory)
+	}
+	swapInfo, err := mem.SwapMemory()
+	if err != nil {
+		swapInfo = nil
+	}
 
 	// 系统负载 (Linux)
 	var loadAvg1, loadAvg5, loadAvg15 float64
@@ -730,6 +1188,8 @@ func (p *commonProvider) GetSystemMetrics() (*types.SystemMetrics, error) {
 		loadAvg1 = loadStat.Load1
 		loadAvg5 = loadStat.Load5
 		loadAvg15 = loadStat.Load15
+	} else {
+		degraded = append(degraded, types.SubsystemLoad)
 	}
 
 	// 获取缓存的系统采样
@@ -738,6 +1198,7 @@ func (p *commonProvider) GetSystemMetrics() (*types.SystemMetrics, error) {
 	cpuUser := p.sysSample.cpuUserPct
 	cpuSystem := p.sysSample.cpuSystemPct
 	cpuIowait := p.sysSample.cpuIowaitPct
+	cpuSteal := p.sysSample.cpuStealPct
 	cpuIdle := p.sysSample.cpuIdlePct
 	swapInRate := p.sysSample.swapInRate
 	swapOutRate := p.sysSample.swapOutRate
@@ -745,17 +1206,30 @@ func (p *commonProvider) GetSystemMetrics() (*types.SystemMetrics, error) {
 	diskWriteRate := p.sysSample.diskWriteRate
 	diskReadOps := p.sysSample.diskReadOps
 	diskWriteOps := p.sysSample.diskWriteOps
+	if p.sysSample.cpuFailed {
+		degraded = append(degraded, types.SubsystemCPU)
+	}
+	if p.sysSample.diskFailed {
+		degraded = append(degraded, types.SubsystemDisk)
+	}
+	var cpuPerCore []float64
+	if len(p.sysSample.corePct) > 0 {
+		cpuPerCore = make([]float64, len(p.sysSample.corePct))
+		copy(cpuPerCore, p.sysSample.corePct)
+	}
 	p.sysSampleMu.RUnlock()
 
 	// 网络流量
 	var netRecv, netSent uint64
 	var netRecvRate, netSendRate float64
-	if p.netMonitor != nil {
+	if p.netMonitor != nil && p.netMonitor.IsRunning() {
 		sysStats := p.netMonitor.GetSystemStats()
 		netRecv = sysStats.RecvBytes
 		netSent = sysStats.SendBytes
 		netRecvRate = sysStats.RecvRate
 		netSendRate = sysStats.SendRate
+	} else {
+		degraded = append(degraded, types.SubsystemNetwork)
 	}
 
 	// Swap 指标
@@ -765,6 +1239,8 @@ func (p *commonProvider) GetSystemMetrics() (*types.SystemMetrics, error) {
 		swapTotal = swapInfo.Total
 		swapUsed = swapInfo.Used
 		swapPercent = swapInfo.UsedPercent
+	} else {
+		degraded = append(degraded, types.SubsystemSwap)
 	}
 
 	return &types.SystemMetrics{
@@ -773,7 +1249,9 @@ func (p *commonProvider) GetSystemMetrics() (*types.SystemMetrics, error) {
 		CPUUser:    cpuUser,
 		CPUSystem:  cpuSystem,
 		CPUIowait:  cpuIowait,
+		CPUSteal:   cpuSteal,
 		CPUIdle:    cpuIdle,
+		CPUPerCore: cpuPerCore,
 
 		// 负载 (Linux)
 		LoadAvg1:  loadAvg1,
@@ -804,5 +1282,7 @@ func (p *commonProvider) GetSystemMetrics() (*types.SystemMetrics, error) {
 		DiskWriteRate: diskWriteRate,
 		DiskReadOps:   diskReadOps,
 		DiskWriteOps:  diskWriteOps,
+
+		Degraded: degraded,
 	}, nil
 }
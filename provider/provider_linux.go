@@ -2,23 +2,252 @@
 
 package provider
 
-func New() ProcProvider {
-	return newCommonProvider(
-		// matchProcessName: Linux 直接匹配
-		func(procName, targetName string) bool {
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+
+	"monitor-agent/types"
+)
+
+// providerType Linux 下通过 gopsutil 读取 /proc
+const providerType = "gopsutil/procfs"
+
+// memTopMappings 内存明细中列出的最大映射区数量
+const memTopMappings = 10
+
+// isElevated 当前进程是否以 root 运行
+func isElevated() bool {
+	return os.Geteuid() == 0
+}
+
+// getCPUAffinity 通过 sched_getaffinity 获取进程允许运行的核心
+func getCPUAffinity(pid int32) []int {
+	var set unix.CPUSet
+	if err := unix.SchedGetaffinity(int(pid), &set); err != nil {
+		return nil
+	}
+	var cores []int
+	for i := 0; i < len(set)*64; i++ {
+		if set.IsSet(i) {
+			cores = append(cores, i)
+		}
+	}
+	return cores
+}
+
+// readMemoryDetail 读取 Linux 内存明细
+// 匿名/文件/共享内存取自 /proc/<pid>/status，最大映射区取自 /proc/<pid>/smaps（需要与目标同用户或 root）
+func readMemoryDetail(pid int32, mb *types.MemoryBreakdown) error {
+	status, err := readProcStatusKB(pid, "RssAnon", "RssFile", "RssShmem")
+	if err != nil {
+		return err
+	}
+	mb.AnonBytes = status["RssAnon"]
+	mb.FileBytes = status["RssFile"]
+	mb.ShmemBytes = status["RssShmem"]
+	mb.SharedBytes = mb.FileBytes + mb.ShmemBytes
+
+	mappings, pss, err := readSmaps(pid)
+	if err != nil {
+		return err
+	}
+	mb.PSSBytes = pss
+	sort.SliceStable(mappings, func(i, j int) bool {
+		return mappings[i].RSSBytes > mappings[j].RSSBytes
+	})
+	if len(mappings) > memTopMappings {
+		mappings = mappings[:memTopMappings]
+	}
+	mb.TopMappings = mappings
+	return nil
+}
+
+// readProcStatusKB 读取 /proc/<pid>/status 中以 kB 为单位的字段，返回字节数
+func readProcStatusKB(pid int32, keys ...string) (map[string]uint64, error) {
+	f, err := os.Open(fmt.Sprintf("/proc/%d/status", pid))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	want := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		want[k] = true
+	}
+	result := make(map[string]uint64, len(keys))
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		key := strings.TrimSuffix(fields[0], ":")
+		if !want[key] {
+			continue
+		}
+		if kb, err := strconv.ParseUint(fields[1], 10, 64); err == nil {
+			result[key] = kb * 1024
+		}
+	}
+	return result, scanner.Err()
+}
+
+// readSmaps 解析 /proc/<pid>/smaps，返回 RSS 非零的映射区和 PSS 合计
+func readSmaps(pid int32) ([]types.MemoryMapping, uint64, error) {
+	f, err := os.Open(fmt.Sprintf("/proc/%d/smaps", pid))
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+
+	var (
+		mappings []types.MemoryMapping
+		cur      *types.MemoryMapping
+		pss      uint64
+	)
+	flush := func() {
+		if cur != nil && cur.RSSBytes > 0 {
+			mappings = append(mappings, *cur)
+		}
+		cur = nil
+	}
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		// 字段行形如 "Rss:  120 kB"，其余为映射区头部 "地址 权限 偏移 设备 inode [路径]"
+		if !strings.HasSuffix(fields[0], ":") {
+			flush()
+			if len(fields) < 5 {
+				continue
+			}
+			path := ""
+			if len(fields) > 5 {
+				path = strings.Join(fields[5:], " ")
+			}
+			cur = &types.MemoryMapping{Address: fields[0], Perms: fields[1], Path: path, Kind: mappingKind(path)}
+			continue
+		}
+		if cur == nil || len(fields) < 2 {
+			continue
+		}
+		kb, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		switch fields[0] {
+		case "Size:":
+			cur.SizeBytes = kb * 1024
+		case "Rss:":
+			cur.RSSBytes = kb * 1024
+		case "Pss:":
+			pss += kb * 1024
+		case "Swap:":
+			cur.SwapBytes = kb * 1024
+		}
+	}
+	flush()
+	if err := scanner.Err(); err != nil {
+		return nil, 0, err
+	}
+	// 权限不足时内核返回空内容而不是错误
+	if len(mappings) == 0 && pss == 0 {
+		return nil, 0, fmt.Errorf("smaps of pid %d is empty (permission denied?)", pid)
+	}
+	return mappings, pss, nil
+}
+
+// readAnonVMS 汇总 /proc/<pid>/maps 中匿名映射区（堆、栈、匿名 mmap，含只保留未提交的地址空间）的大小。
+// 映射区的地址范围和路径与 smaps 的头部相同，但 maps 不统计各区的 RSS/PSS，读取开销小得多，适合每个分析周期读取
+func readAnonVMS(pid int32) (uint64, error) {
+	f, err := os.Open(fmt.Sprintf("/proc/%d/maps", pid))
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	var total uint64
+	regions := 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// "起始-结束 权限 偏移 设备 inode [路径]"
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 {
+			continue
+		}
+		regions++
+		path := ""
+		if len(fields) > 5 {
+			path = strings.Join(fields[5:], " ")
+		}
+		if mappingKind(path) != "anon" {
+			continue
+		}
+		start, end, ok := strings.Cut(fields[0], "-")
+		if !ok {
+			continue
+		}
+		lo, err1 := strconv.ParseUint(start, 16, 64)
+		hi, err2 := strconv.ParseUint(end, 16, 64)
+		if err1 == nil && err2 == nil && hi > lo {
+			total += hi - lo
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	// 内核线程没有映射区；权限不足时内核返回空内容而不是错误
+	if regions == 0 {
+		return 0, fmt.Errorf("maps of pid %d is empty (permission denied?)", pid)
+	}
+	return total, nil
+}
+
+// mappingKind 按映射路径区分匿名内存、共享内存和文件映射
+func mappingKind(path string) string {
+	switch {
+	case path == "" || strings.HasPrefix(path, "["):
+		return "anon"
+	case strings.HasPrefix(path, "/dev/shm/"), strings.HasPrefix(path, "/SYSV"), strings.HasPrefix(path, "/memfd:"):
+		return "shmem"
+	default:
+		return "file"
+	}
+}
+
+// newPlatformOptions Linux 的采集方式
+func newPlatformOptions() platformOptions {
+	return platformOptions{
+		// 进程名直接匹配
+		MatchProcessName: func(procName, targetName string) bool {
 			return procName == targetName
 		},
-		// formatCmdline: Linux 直接返回
-		func(exe string) string {
+		// 命令行直接使用可执行文件路径
+		FormatCmdline: func(exe string) string {
 			return exe
 		},
-		// getHandleCount: Linux 使用 gopsutil 的 NumFDs (返回 nil 使用默认实现)
-		nil,
-		// getPriority: Linux 使用 gopsutil 的 Nice (返回 nil 使用默认实现)
-		nil,
-		// getFileDescription: Linux 没有类似 Windows 的文件描述
-		nil,
-		// divideByNumCPU: 进程 CPU 最大 100%
-		true,
-	)
+		// 句柄数使用 gopsutil 的 NumFDs
+		GetHandleCount: nil,
+		// 优先级由 Nice 换算
+		GetPriority: nil,
+		// Linux 没有类似 Windows 的文件描述
+		GetFileDescription: nil,
+		// 使用 sched_getaffinity
+		GetCPUAffinity: getCPUAffinity,
+		// 汇总各线程的 schedstat 和 delayacct
+		ReadSchedDelay: readSchedDelay,
+		// 汇总 /proc/<pid>/maps 中的匿名映射区
+		ReadAnonVMS: readAnonVMS,
+		// 与 top 一致：单核 100%，多核进程可超过 100%
+		CPUStyle: CPUStyleIrix,
+	}
 }
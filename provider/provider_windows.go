@@ -9,8 +9,13 @@ import (
 	"unsafe"
 
 	"golang.org/x/sys/windows"
+
+	"monitor-agent/types"
 )
 
+// providerType Windows 下通过 gopsutil 和 Win32 API 采集
+const providerType = "gopsutil/win32"
+
 var (
 	modkernel32                 = syscall.NewLazyDLL("kernel32.dll")
 	modpsapi                    = syscall.NewLazyDLL("psapi.dll")
@@ -20,6 +25,7 @@ var (
 	procCloseHandle             = modkernel32.NewProc("CloseHandle")
 	procGetProcessMemoryInfo    = modpsapi.NewProc("GetProcessMemoryInfo")
 	procGetPriorityClass        = modkernel32.NewProc("GetPriorityClass")
+	procGetProcessAffinityMask  = modkernel32.NewProc("GetProcessAffinityMask")
 	procGetFileVersionInfoW     = modversion.NewProc("GetFileVersionInfoW")
 	procGetFileVersionInfoSizeW = modversion.NewProc("GetFileVersionInfoSizeW")
 	procVerQueryValueW          = modversion.NewProc("VerQueryValueW")
@@ -69,6 +75,39 @@ func getProcessHandleCount(pid int32) int32 {
 	return int32(count)
 }
 
+// readMemoryDetail 通过 GetProcessMemoryInfo 读取 Windows 内存明细（工作集、私有字节、页面文件用量）
+func readMemoryDetail(pid int32, mb *types.MemoryBreakdown) error {
+	handle, _, err := procOpenProcess.Call(
+		uintptr(PROCESS_QUERY_INFORMATION|PROCESS_VM_READ),
+		0,
+		uintptr(pid),
+	)
+	if handle == 0 {
+		return fmt.Errorf("OpenProcess: %v", err)
+	}
+	defer procCloseHandle.Call(handle)
+
+	var counters processMemoryCountersEx
+	counters.CB = uint32(unsafe.Sizeof(counters))
+	ret, _, err := procGetProcessMemoryInfo.Call(
+		handle,
+		uintptr(unsafe.Pointer(&counters)),
+		uintptr(counters.CB),
+	)
+	if ret == 0 {
+		return fmt.Errorf("GetProcessMemoryInfo: %v", err)
+	}
+
+	mb.WorkingSetBytes = uint64(counters.WorkingSetSize)
+	mb.PeakWorkingSetBytes = uint64(counters.PeakWorkingSetSize)
+	mb.PrivateBytes = uint64(counters.PrivateUsage)
+	mb.PagefileBytes = uint64(counters.PagefileUsage)
+	mb.PeakPagefileBytes = uint64(counters.PeakPagefileUsage)
+	mb.PagedPoolBytes = uint64(counters.QuotaPagedPoolUsage)
+	mb.NonPagedPoolBytes = uint64(counters.QuotaNonPagedPoolUsage)
+	return nil
+}
+
 // getProcessPriority 获取进程优先级
 func getProcessPriority(pid int32) int32 {
 	handle, _, _ := procOpenProcess.Call(
@@ -107,6 +146,37 @@ func getProcessPriority(pid int32) int32 {
 	}
 }
 
+// getCPUAffinity 获取进程亲和性掩码并转换为核心列表
+func getCPUAffinity(pid int32) []int {
+	handle, _, _ := procOpenProcess.Call(
+		uintptr(PROCESS_QUERY_INFORMATION),
+		0,
+		uintptr(pid),
+	)
+	if handle == 0 {
+		return nil
+	}
+	defer procCloseHandle.Call(handle)
+
+	var processMask, systemMask uintptr
+	ret, _, _ := procGetProcessAffinityMask.Call(
+		handle,
+		uintptr(unsafe.Pointer(&processMask)),
+		uintptr(unsafe.Pointer(&systemMask)),
+	)
+	if ret == 0 {
+		return nil
+	}
+
+	var cores []int
+	for i := 0; i < int(unsafe.Sizeof(processMask))*8; i++ {
+		if processMask&(1<<uint(i)) != 0 {
+			cores = append(cores, i)
+		}
+	}
+	return cores
+}
+
 // getFileDescription 获取可执行文件的描述信息（带缓存）
 func getFileDescription(exePath string) string {
 	if exePath == "" {
@@ -210,23 +280,35 @@ func tryGetDescription(data []byte, langCP uint32) string {
 	return windows.UTF16PtrToString(valuePtr)
 }
 
-func New() ProcProvider {
-	return newCommonProvider(
-		// matchProcessName: Windows 需要匹配 .exe 后缀
-		func(procName, targetName string) bool {
+// isElevated 当前进程是否以管理员身份运行（UAC 提升）
+func isElevated() bool {
+	return windows.GetCurrentProcessToken().IsElevated()
+}
+
+// newPlatformOptions Windows 的采集方式
+func newPlatformOptions() platformOptions {
+	return platformOptions{
+		// 进程名需要兼容 .exe 后缀
+		MatchProcessName: func(procName, targetName string) bool {
 			return procName == targetName || procName == targetName+".exe"
 		},
-		// formatCmdline: Windows 给路径加引号
-		func(exe string) string {
+		// 路径加引号
+		FormatCmdline: func(exe string) string {
 			return fmt.Sprintf("\"%s\"", exe)
 		},
-		// getHandleCount: Windows 使用 GetProcessHandleCount API
-		getProcessHandleCount,
-		// getPriority: Windows 使用 GetPriorityClass API
-		getProcessPriority,
-		// getFileDescription: Windows 使用版本信息 API 获取文件描述
-		getFileDescription,
-		// divideByNumCPU: Windows 风格，进程 CPU 最大 100%
-		true,
-	)
+		// 使用 GetProcessHandleCount API
+		GetHandleCount: getProcessHandleCount,
+		// 使用 GetPriorityClass API
+		GetPriority: getProcessPriority,
+		// 使用版本信息 API 获取文件描述
+		GetFileDescription: getFileDescription,
+		// 使用 GetProcessAffinityMask API
+		GetCPUAffinity: getCPUAffinity,
+		// Windows 不提供按进程的调度等待统计
+		ReadSchedDelay: nil,
+		// Windows 的 VMS 为提交的私有内存（页面文件用量），本身不含文件映射
+		ReadAnonVMS: nil,
+		// 与任务管理器一致：整机口径，进程 CPU 最大 100%
+		CPUStyle: CPUStyleSolaris,
+	}
 }
@@ -0,0 +1,94 @@
+//go:build linux
+
+package provider
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// userHZ /proc/<pid>/stat 中时钟节拍的频率（USER_HZ，Linux 各架构均为 100）
+const userHZ = 100
+
+// delayacctEnabled 内核是否开启 delay accounting。
+// 5.14 起默认关闭，由 sysctl kernel.task_delayacct 控制；更早的内核没有该开关，默认开启
+func delayacctEnabled() bool {
+	data, err := os.ReadFile("/proc/sys/kernel/task_delayacct")
+	if err != nil {
+		return os.IsNotExist(err)
+	}
+	return strings.TrimSpace(string(data)) != "0"
+}
+
+// readSchedDelay 汇总进程各线程的调度等待时间：
+// /proc/<pid>/task/<tid>/schedstat 第二项为可运行等待 CPU 的时间（纳秒，需内核开启 schedstat），
+// /proc/<pid>/task/<tid>/stat 第 42 项 delayacct_blkio_ticks 为等待块设备 IO 的时间（节拍，需开启 delay accounting）
+func readSchedDelay(pid int32) (SchedDelay, bool) {
+	taskDir := filepath.Join("/proc", strconv.Itoa(int(pid)), "task")
+	tids, err := os.ReadDir(taskDir)
+	if err != nil {
+		return SchedDelay{}, false
+	}
+
+	d := SchedDelay{RunOK: true, BlkioOK: delayacctEnabled()}
+	var runNs, blkioTicks uint64
+	for _, tid := range tids {
+		dir := filepath.Join(taskDir, tid.Name())
+		if d.RunOK {
+			if ns, ok := readRunDelay(filepath.Join(dir, "schedstat")); ok {
+				runNs += ns
+			} else if _, err := os.Stat(dir); err == nil {
+				d.RunOK = false // 线程仍在但没有 schedstat：内核未开启
+			}
+		}
+		if d.BlkioOK {
+			if ticks, ok := readBlkioTicks(filepath.Join(dir, "stat")); ok {
+				blkioTicks += ticks
+			}
+		}
+	}
+	if !d.RunOK && !d.BlkioOK {
+		return SchedDelay{}, false
+	}
+	d.RunDelay = time.Duration(runNs)
+	d.BlkioDelay = time.Duration(blkioTicks) * time.Second / userHZ
+	return d, true
+}
+
+// readRunDelay 读取 schedstat 的第二项（run_delay，纳秒）
+func readRunDelay(path string) (uint64, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) < 2 {
+		return 0, false
+	}
+	ns, err := strconv.ParseUint(fields[1], 10, 64)
+	return ns, err == nil
+}
+
+// readBlkioTicks 读取 stat 的第 42 项（delayacct_blkio_ticks）。
+// 进程名可能包含空格和括号，从最后一个 ')' 之后开始按空白分隔（其后第一项为第 3 项 state）
+func readBlkioTicks(path string) (uint64, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+	s := string(data)
+	i := strings.LastIndexByte(s, ')')
+	if i < 0 {
+		return 0, false
+	}
+	fields := strings.Fields(s[i+1:])
+	const idx = 42 - 3
+	if len(fields) <= idx {
+		return 0, false
+	}
+	ticks, err := strconv.ParseUint(fields[idx], 10, 64)
+	return ticks, err == nil
+}
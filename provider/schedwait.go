@@ -0,0 +1,73 @@
+package provider
+
+import (
+	"time"
+
+	"monitor-agent/types"
+)
+
+// 调度等待：虚拟机上宿主机抢占 CPU 时，目标自身 CPU% 不高却运行缓慢，因为线程可运行但拿不到 CPU。
+// 平台提供累计的等待时间（Linux 为各线程 schedstat 的 run_delay 和 delayacct 的 blkio 等待），
+// 这里按两次采样的增量换算为时间占比。内核未开启 schedstat 或 delay accounting 时对应字段为 nil
+
+// SchedDelay 进程累计的调度等待时间（各线程之和），OK 为 false 表示平台不提供该项
+type SchedDelay struct {
+	RunDelay   time.Duration // 可运行但在等待 CPU
+	RunOK      bool
+	BlkioDelay time.Duration // 等待块设备 IO
+	BlkioOK    bool
+}
+
+// schedSample 进程调度等待采样状态
+type schedSample struct {
+	delay      SchedDelay
+	sampleTime time.Time
+	runPct     *float64
+	blockedPct *float64
+}
+
+// fillSchedWait 计算进程的调度等待占比并写入指标，平台不提供时保持为 nil。
+// 首次采样只记录基准值；与上次采样间隔不足 100ms 时沿用上次的结果
+func (p *commonProvider) fillSchedWait(pid int32, m *types.ProcessMetrics) {
+	if p.readSchedDelay == nil {
+		return
+	}
+	delay, ok := p.readSchedDelay(pid)
+	if !ok {
+		return
+	}
+	now := time.Now()
+
+	p.schedSamplesMu.Lock()
+	defer p.schedSamplesMu.Unlock()
+
+	sample, exists := p.schedSamples[pid]
+	if !exists {
+		p.schedSamples[pid] = &schedSample{delay: delay, sampleTime: now}
+		return
+	}
+	elapsed := now.Sub(sample.sampleTime)
+	if elapsed < 100*time.Millisecond {
+		m.RunWaitPct, m.BlockedPct = sample.runPct, sample.blockedPct
+		return
+	}
+
+	sample.runPct = delayPct(delay.RunOK && sample.delay.RunOK, delay.RunDelay-sample.delay.RunDelay, elapsed)
+	sample.blockedPct = delayPct(delay.BlkioOK && sample.delay.BlkioOK, delay.BlkioDelay-sample.delay.BlkioDelay, elapsed)
+	sample.delay = delay
+	sample.sampleTime = now
+	m.RunWaitPct, m.BlockedPct = sample.runPct, sample.blockedPct
+}
+
+// delayPct 等待时间增量占采样间隔的百分比，不可用时返回 nil。
+// 线程退出会使各线程之和减少，增量为负时按 0 计
+func delayPct(ok bool, delta, elapsed time.Duration) *float64 {
+	if !ok {
+		return nil
+	}
+	if delta < 0 {
+		delta = 0
+	}
+	pct := float64(delta) / float64(elapsed) * 100
+	return &pct
+}
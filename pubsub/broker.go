@@ -0,0 +1,74 @@
+package pubsub
+
+import "sync"
+
+// Broker 泛型发布订阅器
+// 发布使用非阻塞发送，订阅者缓冲区满（消费过慢）时会被移除并关闭其通道
+type Broker[T any] struct {
+	mu     sync.Mutex
+	subs   map[chan T]struct{}
+	closed bool
+}
+
+func NewBroker[T any]() *Broker[T] {
+	return &Broker[T]{
+		subs: make(map[chan T]struct{}),
+	}
+}
+
+// Subscribe 注册订阅者，返回消息通道和取消订阅函数
+// 通道被关闭表示订阅已结束（取消订阅或因消费过慢被移除）
+func (b *Broker[T]) Subscribe(bufSize int) (<-chan T, func()) {
+	ch := make(chan T, bufSize)
+
+	b.mu.Lock()
+	if b.closed {
+		close(ch)
+	} else {
+		b.subs[ch] = struct{}{}
+	}
+	b.mu.Unlock()
+
+	unsubscribe := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subs[ch]; ok {
+			delete(b.subs, ch)
+			close(ch)
+		}
+	}
+	return ch, unsubscribe
+}
+
+// Publish 向所有订阅者发布消息
+func (b *Broker[T]) Publish(msg T) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subs {
+		select {
+		case ch <- msg:
+		default:
+			// 订阅者消费过慢，移除
+			delete(b.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// Len 当前订阅者数量
+func (b *Broker[T]) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subs)
+}
+
+// Close 关闭所有订阅
+func (b *Broker[T]) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subs {
+		close(ch)
+	}
+	b.subs = make(map[chan T]struct{})
+	b.closed = true
+}
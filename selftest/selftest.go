@@ -0,0 +1,231 @@
+// Package selftest 启动自检：探测代理在当前部署下能否读取所需的各类信息（进程列表、进程 IO、打开文件、
+// 网络连接、网卡计数器）以及日志目录能否写入，输出 PASS/WARN/FAIL 矩阵。
+// 权限或内核配置不足时相关指标只会静默显示为 0，自检让这类部署问题在首次启动时就显现出来
+package selftest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	psnet "github.com/shirou/gopsutil/v3/net"
+	"github.com/shirou/gopsutil/v3/process"
+
+	"monitor-agent/buildinfo"
+	"monitor-agent/provider"
+)
+
+// 检查结果
+const (
+	StatusPass = "PASS"
+	StatusWarn = "WARN" // 可以运行，但部分指标缺失或不准确
+	StatusFail = "FAIL" // 对应指标完全不可用
+)
+
+// maxSampledProcs 读取进程 IO 和打开文件时最多尝试的其他进程数（打开文件在句柄多的进程上读取较慢）
+const maxSampledProcs = 20
+
+// Check 一项检查的结果
+type Check struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+}
+
+// Report 自检报告
+type Report struct {
+	Time     time.Time `json:"time"`
+	Platform string    `json:"platform"`
+	Elevated bool      `json:"elevated"` // 是否以 root/管理员运行
+	Status   string    `json:"status"`   // 各项中最差的结果
+	Checks   []Check   `json:"checks"`
+}
+
+// Count 返回结果为 status 的检查项数
+func (r Report) Count(status string) int {
+	n := 0
+	for _, c := range r.Checks {
+		if c.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// Summary 一行摘要，如 "5 PASS, 2 WARN, 0 FAIL"
+func (r Report) Summary() string {
+	return fmt.Sprintf("%d PASS, %d WARN, %d FAIL", r.Count(StatusPass), r.Count(StatusWarn), r.Count(StatusFail))
+}
+
+// Print 按行输出检查矩阵
+func (r Report) Print(w io.Writer) {
+	for _, c := range r.Checks {
+		fmt.Fprintf(w, "  %-5s %-14s %s\n", c.Status, c.Name, c.Detail)
+	}
+}
+
+// Run 执行全部检查，logDir 为日志和状态文件目录
+func Run(logDir string) Report {
+	r := Report{
+		Time:     time.Now(),
+		Platform: buildinfo.Get().Platform,
+		Elevated: provider.IsElevated(),
+	}
+	r.Checks = append(r.Checks, checkPrivileges(r.Elevated))
+
+	pids, check := checkProcessList()
+	r.Checks = append(r.Checks, check)
+	others := sampleOtherPIDs(pids)
+	r.Checks = append(r.Checks,
+		checkPerProcess("process_io", "IO counters", others, func(p *process.Process) error {
+			_, err := p.IOCounters()
+			return err
+		}),
+		checkPerProcess("open_files", "open files", others, func(p *process.Process) error {
+			_, err := p.OpenFiles()
+			return err
+		}),
+		checkConnections(r.Elevated),
+		checkNetCounters(),
+		checkLogDir(logDir),
+	)
+
+	r.Status = StatusPass
+	for _, c := range r.Checks {
+		if c.Status == StatusFail {
+			r.Status = StatusFail
+			break
+		}
+		if c.Status == StatusWarn {
+			r.Status = StatusWarn
+		}
+	}
+	return r
+}
+
+func checkPrivileges(elevated bool) Check {
+	if elevated {
+		return Check{"privileges", StatusPass, "running as root/administrator"}
+	}
+	return Check{"privileges", StatusWarn, "not running as root/administrator: disk IO, exe path and FD counts " +
+		"of other users' processes cannot be read"}
+}
+
+func checkProcessList() ([]int32, Check) {
+	pids, err := process.Pids()
+	if err != nil {
+		return nil, Check{"process_list", StatusFail, fmt.Sprintf("cannot enumerate processes: %v", err)}
+	}
+	if len(pids) == 0 {
+		return nil, Check{"process_list", StatusFail, "process enumeration returned no processes"}
+	}
+	return pids, Check{"process_list", StatusPass, fmt.Sprintf("%d processes", len(pids))}
+}
+
+// sampleOtherPIDs 取最多 maxSampledProcs 个其他进程（PID 从小到大，通常包含其他用户的系统服务）
+func sampleOtherPIDs(pids []int32) []int32 {
+	self := int32(os.Getpid())
+	var out []int32
+	for _, pid := range pids {
+		if pid <= 0 || pid == self {
+			continue
+		}
+		out = append(out, pid)
+		if len(out) >= maxSampledProcs {
+			break
+		}
+	}
+	return out
+}
+
+// checkPerProcess 先读取代理自身进程（失败为 FAIL：平台或内核不支持），再尝试其他进程（部分失败为 WARN：通常是权限不足）。
+// 检查期间已退出的进程不计入
+func checkPerProcess(name, what string, others []int32, read func(*process.Process) error) Check {
+	self, err := process.NewProcess(int32(os.Getpid()))
+	if err == nil {
+		err = read(self)
+	}
+	if err != nil {
+		return Check{name, StatusFail, fmt.Sprintf("cannot read %s of the agent itself: %v", what, err)}
+	}
+
+	tried, failed := 0, 0
+	var firstErr error
+	for _, pid := range others {
+		p, err := process.NewProcess(pid)
+		if err != nil {
+			continue
+		}
+		if err := read(p); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+			failed++
+		}
+		tried++
+	}
+	if failed > 0 {
+		return Check{name, StatusWarn, fmt.Sprintf("%s of %d/%d other processes unreadable (%v)", what, failed, tried, firstErr)}
+	}
+	return Check{name, StatusPass, fmt.Sprintf("%s readable for the agent and %d other processes", what, tried)}
+}
+
+// checkConnections 枚举网络连接。非特权运行时 Linux 上其他用户进程的连接没有 PID，端口检测和进程连接数不完整
+func checkConnections(elevated bool) Check {
+	conns, err := psnet.Connections("all")
+	if err != nil {
+		return Check{"connections", StatusFail, fmt.Sprintf("cannot enumerate connections: %v", err)}
+	}
+	noPID := 0
+	for _, c := range conns {
+		if c.Pid == 0 {
+			noPID++
+		}
+	}
+	if noPID > 0 && !elevated {
+		return Check{"connections", StatusWarn, fmt.Sprintf("%d connections, %d without owning process "+
+			"(port checks and per-process traffic are incomplete)", len(conns), noPID)}
+	}
+	return Check{"connections", StatusPass, fmt.Sprintf("%d connections", len(conns))}
+}
+
+// checkNetCounters 读取网卡计数器。代理不抓包（无需 pcap），进程流量按连接数比例分配网卡总流量
+func checkNetCounters() Check {
+	counters, err := psnet.IOCounters(true)
+	if err != nil {
+		return Check{"net_counters", StatusFail, fmt.Sprintf("cannot read interface counters: %v", err)}
+	}
+	if len(counters) == 0 {
+		return Check{"net_counters", StatusWarn, "no network interfaces found"}
+	}
+	return Check{"net_counters", StatusPass, fmt.Sprintf("%d interfaces (no packet capture needed, per-process traffic is estimated)",
+		len(counters))}
+}
+
+// checkLogDir 在日志目录中创建并删除一个临时文件
+func checkLogDir(dir string) Check {
+	if dir == "" {
+		dir = "."
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return Check{"log_dir", StatusFail, fmt.Sprintf("cannot create %s: %v", dir, err)}
+	}
+	f, err := os.CreateTemp(dir, ".selftest-*")
+	if err != nil {
+		return Check{"log_dir", StatusFail, fmt.Sprintf("%s is not writable: %v", dir, err)}
+	}
+	_, err = f.WriteString("selftest\n")
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	os.Remove(f.Name())
+	if err != nil {
+		return Check{"log_dir", StatusFail, fmt.Sprintf("cannot write to %s: %v", dir, err)}
+	}
+	return Check{"log_dir", StatusPass, fmt.Sprintf("%s is writable", dir)}
+}
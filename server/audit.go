@@ -0,0 +1,94 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strconv"
+
+	"monitor-agent/logger"
+)
+
+const (
+	// auditDefaultLimit 审计日志默认返回条数
+	auditDefaultLimit = 100
+	// auditMaxLimit 审计日志最多返回条数
+	auditMaxLimit = 10000
+)
+
+// audit 记录一次 Web 修改操作：登录用户、来源 IP、操作和内容，err 非 nil 时记录失败原因
+func (s *WebServer) audit(r *http.Request, action string, detail interface{}, err error) {
+	entry := logger.AuditEntry{
+		Source:   "web",
+		RemoteIP: r.RemoteAddr,
+		Action:   action,
+		Detail:   detail,
+	}
+	if host, _, splitErr := net.SplitHostPort(r.RemoteAddr); splitErr == nil {
+		entry.RemoteIP = host
+	}
+	entry.User = s.requestUser(r)
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	logger.Audit(entry)
+}
+
+// requestUser 请求的登录用户，未登录时返回空字符串
+func (s *WebServer) requestUser(r *http.Request) string {
+	if cookie, err := r.Cookie("session_token"); err == nil {
+		return s.authManager.SessionUser(cookie.Value)
+	}
+	return ""
+}
+
+// changedFields 比较两个对象的 JSON 字段，返回有变化的字段及新旧值，用于审计配置修改
+func changedFields(before, after interface{}) map[string]interface{} {
+	var old, cur map[string]json.RawMessage
+	if data, err := json.Marshal(before); err == nil {
+		json.Unmarshal(data, &old)
+	}
+	if data, err := json.Marshal(after); err == nil {
+		json.Unmarshal(data, &cur)
+	}
+	changes := make(map[string]interface{})
+	for key, value := range cur {
+		if prev, ok := old[key]; !ok || !bytes.Equal(prev, value) {
+			changes[key] = map[string]json.RawMessage{"from": old[key], "to": value}
+		}
+	}
+	for key, prev := range old {
+		if _, ok := cur[key]; !ok {
+			changes[key] = map[string]json.RawMessage{"from": prev}
+		}
+	}
+	return changes
+}
+
+// GET /api/audit?n=100 - 最近 n 条审计记录（所有修改状态的操作），按时间从早到晚排列；n 默认 100、最大 10000
+// 只读接口，需要登录，只读模式下同样可用
+func (s *WebServer) handleAudit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		s.errorResponse(w, 405, "method not allowed")
+		return
+	}
+	n := auditDefaultLimit
+	if v := r.URL.Query().Get("n"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed <= 0 {
+			s.errorResponse(w, http.StatusBadRequest, "invalid n")
+			return
+		}
+		if parsed > auditMaxLimit {
+			parsed = auditMaxLimit
+		}
+		n = parsed
+	}
+	entries, err := logger.ReadAudit(s.logDir(), n)
+	if err != nil {
+		s.errorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.jsonResponse(w, entries)
+}
@@ -28,6 +28,9 @@ type AuthManager struct {
 	config   AuthConfig
 	sessions map[string]*Session
 	mu       sync.RWMutex
+
+	stopCh    chan struct{}
+	closeOnce sync.Once
 }
 
 // NewAuthManager 创建认证管理器
@@ -45,6 +48,7 @@ func NewAuthManager(cfg AuthConfig) *AuthManager {
 	am := &AuthManager{
 		config:   cfg,
 		sessions: make(map[string]*Session),
+		stopCh:   make(chan struct{}),
 	}
 
 	// 启动过期会话清理
@@ -96,6 +100,17 @@ func (am *AuthManager) ValidateToken(token string) bool {
 	return true
 }
 
+// SessionUser 返回 token 对应的登录用户，token 无效或已过期时返回空字符串
+func (am *AuthManager) SessionUser(token string) string {
+	am.mu.RLock()
+	defer am.mu.RUnlock()
+	session, exists := am.sessions[token]
+	if !exists || time.Now().After(session.ExpiresAt) {
+		return ""
+	}
+	return session.Username
+}
+
 // Logout 登出
 func (am *AuthManager) Logout(token string) {
 	am.mu.Lock()
@@ -103,10 +118,21 @@ func (am *AuthManager) Logout(token string) {
 	am.mu.Unlock()
 }
 
-// cleanupExpiredSessions 清理过期会话
+// Close 停止过期会话清理协程，重复调用无副作用
+func (am *AuthManager) Close() {
+	am.closeOnce.Do(func() { close(am.stopCh) })
+}
+
+// cleanupExpiredSessions 清理过期会话，Close 后退出
 func (am *AuthManager) cleanupExpiredSessions() {
 	ticker := time.NewTicker(10 * time.Minute)
-	for range ticker.C {
+	defer ticker.Stop()
+	for {
+		select {
+		case <-am.stopCh:
+			return
+		case <-ticker.C:
+		}
 		am.mu.Lock()
 		now := time.Now()
 		for token, session := range am.sessions {
@@ -0,0 +1,53 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"monitor-agent/types"
+)
+
+// commentRequest 添加备注的请求体
+type commentRequest struct {
+	ID   string `json:"id"`
+	Text string `json:"text"`
+}
+
+// POST /api/events/comment - 为事件添加值班备注 {"id": "...", "text": "..."}，备注人为当前登录用户
+func (s *WebServer) handleEventComment(w http.ResponseWriter, r *http.Request) {
+	s.handleComment(w, r, "event.comment",
+		func(id string) bool { _, ok := s.multiMonitor.GetEvent(id); return ok },
+		s.multiMonitor.AddEventComment)
+}
+
+// POST /api/impacts/comment - 为影响事件（活跃或最近解除）添加值班备注 {"id": "...", "text": "..."}
+func (s *WebServer) handleImpactComment(w http.ResponseWriter, r *http.Request) {
+	s.handleComment(w, r, "impact.comment",
+		func(id string) bool { _, ok := s.multiMonitor.GetImpact(id); return ok },
+		s.multiMonitor.AddImpactComment)
+}
+
+// handleComment 校验请求并添加备注：事件不存在时返回 404，备注为空、过长或数量达到上限时返回 400
+func (s *WebServer) handleComment(w http.ResponseWriter, r *http.Request, action string,
+	exists func(id string) bool, add func(id, user, text string) (types.Comment, error)) {
+	if r.Method != "POST" {
+		s.errorResponse(w, 405, "method not allowed")
+		return
+	}
+	var req commentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ID == "" {
+		s.errorResponse(w, 400, "invalid request body, expected {\"id\": ..., \"text\": ...}")
+		return
+	}
+	if !exists(req.ID) {
+		s.errorResponse(w, 404, "not found: "+req.ID)
+		return
+	}
+	comment, err := add(req.ID, s.requestUser(r), req.Text)
+	s.audit(r, action, req, err)
+	if err != nil {
+		s.errorResponse(w, 400, err.Error())
+		return
+	}
+	s.jsonResponse(w, comment)
+}
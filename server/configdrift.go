@@ -0,0 +1,85 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"monitor-agent/config"
+	"monitor-agent/profile"
+)
+
+// configDrift 检查配置文件是否在运行中被修改（未指定配置文件时没有漂移）
+func (s *WebServer) configDrift() config.Drift {
+	s.configMu.RLock()
+	defer s.configMu.RUnlock()
+	if s.appConfig == nil {
+		return config.Drift{}
+	}
+	return config.CheckDrift(s.configFile, s.appConfig)
+}
+
+// POST /api/config/apply-file - 配置文件在运行中被修改后，按文件更新运行中的配置，返回应用的差异
+func (s *WebServer) handleConfigApplyFile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		s.errorResponse(w, 405, "method not allowed")
+		return
+	}
+	if s.configFile == "" {
+		s.errorResponse(w, 400, "no config file")
+		return
+	}
+
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+	if s.appConfig == nil {
+		s.appConfig = config.DefaultConfig()
+	}
+	drift := config.CheckDrift(s.configFile, s.appConfig)
+	if drift.Error != "" {
+		s.audit(r, "config.apply_file", map[string]string{"file": s.configFile}, errors.New(drift.Error))
+		s.errorResponse(w, 400, drift.Error)
+		return
+	}
+	if !drift.Drift {
+		s.jsonResponse(w, map[string]any{"status": "ok", "diff": drift.Diff})
+		return
+	}
+
+	cfg, err := config.LoadConfig(s.configFile)
+	s.audit(r, "config.apply_file", map[string]any{"file": s.configFile, "diff": drift.Diff}, err)
+	if err != nil {
+		s.errorResponse(w, 500, err.Error())
+		return
+	}
+	warnings := profile.ApplyConfig(s.appConfig, cfg, s.multiMonitor)
+	s.jsonResponse(w, map[string]any{
+		"status":   "ok",
+		"diff":     drift.Diff,
+		"warnings": warnings,
+	})
+}
+
+// POST /api/config/save - 以运行中的配置覆盖配置文件（放弃文件中的手工修改）
+func (s *WebServer) handleConfigSave(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		s.errorResponse(w, 405, "method not allowed")
+		return
+	}
+	if s.configFile == "" {
+		s.errorResponse(w, 400, "no config file")
+		return
+	}
+
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+	if s.appConfig == nil {
+		s.appConfig = config.DefaultConfig()
+	}
+	err := config.SaveConfig(s.configFile, s.appConfig)
+	s.audit(r, "config.save", map[string]string{"file": s.configFile}, err)
+	if err != nil {
+		s.errorResponse(w, 500, err.Error())
+		return
+	}
+	s.jsonResponse(w, map[string]string{"status": "ok"})
+}
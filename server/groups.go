@@ -0,0 +1,16 @@
+package server
+
+import (
+	"net/http"
+
+	"monitor-agent/types"
+)
+
+// GET /api/monitor/groups - 按分组汇总监控目标（分组状态、运行成员数、CPU 和内存之和）
+func (s *WebServer) handleTargetGroups(w http.ResponseWriter, r *http.Request) {
+	groups := s.multiMonitor.GetTargetGroups()
+	if groups == nil {
+		groups = []types.TargetGroup{}
+	}
+	s.jsonResponse(w, groups)
+}
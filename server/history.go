@@ -0,0 +1,78 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"monitor-agent/logquery"
+)
+
+const (
+	// historyDefaultLimit 按日浏览每页默认条数
+	historyDefaultLimit = 100
+	// historyMaxLimit 按日浏览每页最多条数
+	historyMaxLimit = 1000
+)
+
+// GET /api/history/days - 列出有事件或影响日志的日期（从新到旧）及各日事件数、按严重级别的影响数
+// 首次调用时为升级前的日志文件补建按日索引，之后只读取索引
+func (s *WebServer) handleHistoryDays(w http.ResponseWriter, r *http.Request) {
+	s.beginStream(r) // 补建索引可能较慢
+	days, err := logquery.Days(s.logDir())
+	s.extendWriteDeadline(r, 0)
+	if err != nil {
+		s.errorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.jsonResponse(w, days)
+}
+
+// GET /api/history/day?date=2024-05-12&category=&offset=&limit= - 某天的事件和影响日志，按时间从早到晚分页返回
+// category 为 event 或 impact 时只返回该类；limit 默认 100、最大 1000
+func (s *WebServer) handleHistoryDay(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	query := logquery.DayQuery{
+		Date:  q.Get("date"),
+		Limit: historyDefaultLimit,
+	}
+	if _, err := logquery.ParseDay(query.Date); err != nil {
+		s.errorResponse(w, http.StatusBadRequest, "invalid date: "+err.Error())
+		return
+	}
+	switch category := strings.ToUpper(q.Get("category")); category {
+	case "", "EVENT", "IMPACT":
+		query.Category = category
+	default:
+		s.errorResponse(w, http.StatusBadRequest, "category must be event or impact")
+		return
+	}
+	if v := q.Get("offset"); v != "" {
+		offset, err := strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			s.errorResponse(w, http.StatusBadRequest, "invalid offset")
+			return
+		}
+		query.Offset = offset
+	}
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			s.errorResponse(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+		if limit > historyMaxLimit {
+			limit = historyMaxLimit
+		}
+		query.Limit = limit
+	}
+
+	s.beginStream(r)
+	result, err := logquery.Day(s.logDir(), query)
+	s.extendWriteDeadline(r, 0)
+	if err != nil {
+		s.errorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.jsonResponse(w, result)
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"monitor-agent/logger"
+	"monitor-agent/logquery"
+)
+
+const (
+	// logSearchMaxLimit 单次检索最多返回的日志条数
+	logSearchMaxLimit = 1000
+	// logSearchMaxBytes 单次检索最多读取的日志字节数，避免大范围检索长时间占用磁盘
+	logSearchMaxBytes = 256 << 20
+)
+
+// GET /api/logs/search?category=&level=&pid=&q=&from=&to=&limit= - 检索日志目录中的历史日志
+// 从最新的文件开始按时间从新到旧流式返回：{"entries":[...],"files_scanned":..,"bytes_scanned":..,"limit_reached":..,"truncated":..}
+// from/to 支持 RFC3339 或 Unix 秒；limit 默认 100、最大 1000；读取量超过上限时停止，truncated 为 true
+func (s *WebServer) handleLogSearch(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	query := logquery.Query{
+		Category: q.Get("category"),
+		Level:    q.Get("level"),
+		Text:     q.Get("q"),
+		MaxBytes: logSearchMaxBytes,
+	}
+	if v := q.Get("pid"); v != "" {
+		pid, err := strconv.ParseInt(v, 10, 32)
+		if err != nil || pid <= 0 {
+			s.errorResponse(w, http.StatusBadRequest, "invalid pid")
+			return
+		}
+		query.PID = int32(pid)
+	}
+	var err error
+	if query.From, err = parseTimeParam(q.Get("from")); err != nil {
+		s.errorResponse(w, http.StatusBadRequest, "invalid from: "+err.Error())
+		return
+	}
+	if query.To, err = parseTimeParam(q.Get("to")); err != nil {
+		s.errorResponse(w, http.StatusBadRequest, "invalid to: "+err.Error())
+		return
+	}
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			s.errorResponse(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+		if limit > logSearchMaxLimit {
+			limit = logSearchMaxLimit
+		}
+		query.Limit = limit
+	}
+
+	// 检索耗时不计入读写超时，每次写入前延长写期限
+	s.beginStream(r)
+
+	// 第一条结果前才写响应头，检索失败（如日志目录不可读）时仍可返回错误
+	flusher, _ := w.(http.Flusher)
+	enc := json.NewEncoder(w)
+	started := false
+	begin := func() {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"entries":[`))
+		started = true
+	}
+	stats, err := logquery.Search(s.logDir(), query, func(e logquery.Entry) bool {
+		s.extendWriteDeadline(r, 0)
+		if !started {
+			begin()
+		} else {
+			w.Write([]byte(","))
+		}
+		enc.Encode(e)
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return r.Context().Err() == nil // 客户端断开后停止检索
+	})
+	s.extendWriteDeadline(r, 0)
+	if err != nil {
+		s.errorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !started {
+		begin()
+	}
+	// 统计字段与 entries 并列输出在同一对象中
+	tail, _ := json.Marshal(stats)
+	w.Write([]byte("],"))
+	w.Write(tail[1:])
+}
+
+// logDir 当前日志目录
+func (s *WebServer) logDir() string {
+	if l := logger.Default(); l != nil {
+		return l.GetLogDir()
+	}
+	s.configMu.RLock()
+	defer s.configMu.RUnlock()
+	if s.appConfig != nil && s.appConfig.Logging.Dir != "" {
+		return s.appConfig.Logging.Dir
+	}
+	return "logs"
+}
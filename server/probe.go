@@ -0,0 +1,46 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+
+	"monitor-agent/types"
+)
+
+// GET /api/monitor/probe?name=xxx&n=60 - 远程探测目标的状态和最近 n 次探测结果（n 默认全部），按时间从早到晚排列
+func (s *WebServer) handleProbe(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		s.errorResponse(w, 405, "method not allowed")
+		return
+	}
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		s.errorResponse(w, http.StatusBadRequest, "name is required")
+		return
+	}
+	n := 0
+	if v := r.URL.Query().Get("n"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed <= 0 {
+			s.errorResponse(w, http.StatusBadRequest, "invalid n")
+			return
+		}
+		n = parsed
+	}
+	results, ok := s.multiMonitor.GetProbeResults(name, n)
+	if !ok {
+		s.errorResponse(w, http.StatusNotFound, "probe not found: "+name)
+		return
+	}
+	var status types.ProbeStatus
+	for _, p := range s.multiMonitor.GetProbes() {
+		if p.Name == name {
+			status = p
+			break
+		}
+	}
+	s.jsonResponse(w, map[string]interface{}{
+		"probe":   status,
+		"results": results,
+	})
+}
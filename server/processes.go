@@ -0,0 +1,183 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"monitor-agent/types"
+)
+
+// processSortKeys /api/processes 的 sort 参数可选的排序指标
+var processSortKeys = map[string]func(p *types.ProcessInfo) float64{
+	"cpu":     func(p *types.ProcessInfo) float64 { return p.CPUPct },
+	"rss":     func(p *types.ProcessInfo) float64 { return float64(p.RSSBytes) },
+	"disk":    func(p *types.ProcessInfo) float64 { return p.DiskReadRate + p.DiskWriteRate },
+	"net":     func(p *types.ProcessInfo) float64 { return p.NetRecvRate + p.NetSendRate },
+	"fds":     func(p *types.ProcessInfo) float64 { return float64(p.NumFDs) },
+	"threads": func(p *types.ProcessInfo) float64 { return float64(p.NumThreads) },
+}
+
+// processFields ProcessInfo 的 JSON 字段名，fields 参数只能从中选择
+var processFields = jsonFieldNames(reflect.TypeOf(types.ProcessInfo{}))
+
+// jsonFieldNames 结构体各字段的 JSON 名称
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	names := make(map[string]bool, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+// processQuery /api/processes 的查询参数，零值表示返回全部进程（与不带参数时相同）
+type processQuery struct {
+	name, user string // 进程名（含显示名称）、用户名，不区分大小写的子串匹配
+	sortKey    string // 为空时保持 provider 的原顺序
+	asc        bool
+	offset     int
+	limit      int      // 0 表示不限制
+	fields     []string // 为空时返回完整字段
+}
+
+// parseProcessQuery 解析并校验查询参数
+func parseProcessQuery(r *http.Request) (processQuery, string) {
+	q := r.URL.Query()
+	query := processQuery{
+		name: strings.ToLower(q.Get("name")),
+		user: strings.ToLower(q.Get("user")),
+	}
+	if v := strings.ToLower(q.Get("sort")); v != "" {
+		if _, ok := processSortKeys[v]; !ok {
+			return query, "sort must be one of cpu, rss, disk, net, fds, threads"
+		}
+		query.sortKey = v
+	}
+	switch strings.ToLower(q.Get("order")) {
+	case "", "desc":
+	case "asc":
+		query.asc = true
+	default:
+		return query, "order must be asc or desc"
+	}
+	if v := q.Get("offset"); v != "" {
+		offset, err := strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			return query, "invalid offset"
+		}
+		query.offset = offset
+	}
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 0 {
+			return query, "invalid limit"
+		}
+		query.limit = limit
+	}
+	if v := q.Get("fields"); v != "" {
+		for _, f := range strings.Split(v, ",") {
+			f = strings.TrimSpace(f)
+			if f == "" {
+				continue
+			}
+			if !processFields[f] {
+				return query, "unknown field: " + f
+			}
+			query.fields = append(query.fields, f)
+		}
+	}
+	return query, ""
+}
+
+// apply 按条件过滤、排序并分页，返回本页进程和过滤后的总数
+func (q processQuery) apply(procs []types.ProcessInfo) ([]types.ProcessInfo, int) {
+	if q.name != "" || q.user != "" {
+		matched := make([]types.ProcessInfo, 0, len(procs))
+		for _, p := range procs {
+			if q.name != "" && !strings.Contains(strings.ToLower(p.Name), q.name) &&
+				!strings.Contains(strings.ToLower(p.DisplayName), q.name) {
+				continue
+			}
+			if q.user != "" && !strings.Contains(strings.ToLower(p.Username), q.user) {
+				continue
+			}
+			matched = append(matched, p)
+		}
+		procs = matched
+	}
+	if value, ok := processSortKeys[q.sortKey]; ok {
+		sort.SliceStable(procs, func(i, j int) bool {
+			if q.asc {
+				return value(&procs[i]) < value(&procs[j])
+			}
+			return value(&procs[i]) > value(&procs[j])
+		})
+	}
+
+	total := len(procs)
+	if q.offset >= total {
+		return []types.ProcessInfo{}, total
+	}
+	procs = procs[q.offset:]
+	if q.limit > 0 && q.limit < len(procs) {
+		procs = procs[:q.limit]
+	}
+	return procs, total
+}
+
+// sparse 只保留 fields 中的字段
+func (q processQuery) sparse(procs []types.ProcessInfo) ([]map[string]json.RawMessage, error) {
+	result := make([]map[string]json.RawMessage, 0, len(procs))
+	for i := range procs {
+		data, err := json.Marshal(&procs[i])
+		if err != nil {
+			return nil, err
+		}
+		var all map[string]json.RawMessage
+		if err := json.Unmarshal(data, &all); err != nil {
+			return nil, err
+		}
+		item := make(map[string]json.RawMessage, len(q.fields))
+		for _, f := range q.fields {
+			if v, ok := all[f]; ok {
+				item[f] = v
+			}
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+// GET /api/processes?sort=cpu&order=desc&offset=0&limit=50&fields=pid,name,cpu_pct&name=java&user=root - 列出系统进程
+// 不带参数时返回全部进程（provider 缓存的列表）；过滤后、分页前的总数在 X-Total-Count 响应头中
+func (s *WebServer) handleListProcesses(w http.ResponseWriter, r *http.Request) {
+	query, msg := parseProcessQuery(r)
+	if msg != "" {
+		s.errorResponse(w, http.StatusBadRequest, msg)
+		return
+	}
+	procs, err := s.multiMonitor.ListAllProcesses()
+	if err != nil {
+		s.errorResponse(w, 500, err.Error())
+		return
+	}
+	page, total := query.apply(procs)
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
+	w.Header().Set("Access-Control-Expose-Headers", "X-Total-Count")
+	if len(query.fields) == 0 {
+		s.jsonResponse(w, page)
+		return
+	}
+	items, err := query.sparse(page)
+	if err != nil {
+		s.errorResponse(w, 500, err.Error())
+		return
+	}
+	s.jsonResponse(w, items)
+}
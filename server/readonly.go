@@ -0,0 +1,45 @@
+package server
+
+import (
+	"net/http"
+)
+
+// mutatingPaths 修改状态的接口，只读模式下拒绝非 GET/HEAD 请求
+var mutatingPaths = map[string]bool{
+	"/api/monitor/add":          true,
+	"/api/monitor/targets/bulk": true,
+	"/api/monitor/remove":       true,
+	"/api/monitor/removeAll":    true,
+	"/api/monitor/update":       true,
+	"/api/monitor/start":        true,
+	"/api/monitor/stop":         true,
+	"/api/monitor/maintenance":  true,
+	"/api/impacts/clear":        true,
+	"/api/impacts/pause":        true,
+	"/api/impacts/comment":      true,
+	"/api/events/comment":       true,
+	"/api/config/impact":        true,
+	"/api/config/import":        true,
+	"/api/config/apply-file":    true,
+	"/api/config/save":          true,
+}
+
+// readOnlyMiddleware 只读模式中间件
+// server.read_only 为 true 时，对修改状态的接口返回 403，查询接口不受影响
+func (s *WebServer) readOnlyMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead &&
+			mutatingPaths[r.URL.Path] && s.readOnlyEnabled() {
+			s.errorResponse(w, http.StatusForbidden, "server is in read-only mode")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// readOnlyEnabled 是否处于只读模式
+func (s *WebServer) readOnlyEnabled() bool {
+	s.configMu.RLock()
+	defer s.configMu.RUnlock()
+	return s.appConfig != nil && s.appConfig.Server.ReadOnly
+}
@@ -0,0 +1,16 @@
+package server
+
+import (
+	"net/http"
+
+	"monitor-agent/selftest"
+)
+
+// GET /api/selftest - 重新执行自检（权限、进程 IO、打开文件、网络连接、网卡计数器、日志目录），返回 PASS/WARN/FAIL 矩阵
+func (s *WebServer) handleSelfTest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		s.errorResponse(w, 405, "method not allowed")
+		return
+	}
+	s.jsonResponse(w, selftest.Run(s.logDir()))
+}
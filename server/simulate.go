@@ -0,0 +1,81 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"monitor-agent/config"
+	"monitor-agent/impact"
+)
+
+// simulateRequest 阈值模拟请求
+type simulateRequest struct {
+	Config json.RawMessage `json:"config"` // 候选阈值，格式同 /api/config/impact，只覆盖其中出现的字段
+	Window string          `json:"window"` // 回放窗口（如 "30m"），为空时回放全部缓冲
+}
+
+// POST /api/impacts/simulate - 按候选阈值回放最近的分析周期，返回会产生的影响事件统计（不产生任何事件）
+// 请求体 {"config":{...},"window":"30m"}；响应含候选阈值 candidate 和当前阈值 current 的统计作为对照
+func (s *WebServer) handleImpactsSimulate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		s.errorResponse(w, 405, "method not allowed")
+		return
+	}
+	analyzer := s.multiMonitor.GetImpactAnalyzer()
+	if analyzer == nil {
+		s.errorResponse(w, http.StatusServiceUnavailable, "impact analysis is not enabled")
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		s.errorResponse(w, 400, "read request body: "+err.Error())
+		return
+	}
+	var req simulateRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		s.errorResponse(w, 400, "invalid request body: "+err.Error())
+		return
+	}
+	var window time.Duration
+	if req.Window != "" {
+		if window, err = parseWindowParam(req.Window); err != nil {
+			s.errorResponse(w, 400, "invalid window: "+err.Error())
+			return
+		}
+	}
+
+	// 与保存配置相同：解码到当前配置的副本上，profiles 出现时整体替换
+	s.configMu.RLock()
+	base := config.DefaultConfig().Impact
+	if s.appConfig != nil {
+		base = s.appConfig.Impact
+	}
+	s.configMu.RUnlock()
+	candidate := base
+	if len(req.Config) > 0 {
+		candidate.Profiles = nil
+		if err := json.Unmarshal(req.Config, &candidate); err != nil {
+			s.errorResponse(w, 400, "invalid config: "+err.Error())
+			return
+		}
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(req.Config, &fields); err == nil {
+			if _, ok := fields["profiles"]; !ok {
+				candidate.Profiles = base.Profiles
+			}
+		}
+	}
+	if _, err := impact.ValidateProfiles(candidate.Profiles); err != nil {
+		s.errorResponse(w, 400, "invalid profiles: "+err.Error())
+		return
+	}
+	if err := impact.ValidateDurations(candidate); err != nil {
+		s.errorResponse(w, 400, "invalid durations: "+err.Error())
+		return
+	}
+
+	s.jsonResponse(w, analyzer.Simulate(candidate, window))
+}
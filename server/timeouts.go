@@ -0,0 +1,56 @@
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+)
+
+// 连接超时：http.Server 的 ReadTimeout/WriteTimeout 针对整个请求，会切断 SSE、长轮询等长连接，
+// 且 ReadTimeout 到期后服务端的后台读会取消请求上下文。流式接口通过 ConnContext 保存的连接
+// 取消读期限，并在每次写入前单独延长写期限；死连接由写期限和 TCP keepalive 发现。
+
+// connContextKey 请求上下文中保存底层连接的键
+type connContextKey struct{}
+
+// ConnContext 供 http.Server.ConnContext 使用，把底层连接保存到请求上下文
+func ConnContext(ctx context.Context, c net.Conn) context.Context {
+	return context.WithValue(ctx, connContextKey{}, c)
+}
+
+// requestConn 请求的底层连接，未设置 ConnContext 时返回 nil
+func requestConn(r *http.Request) net.Conn {
+	c, _ := r.Context().Value(connContextKey{}).(net.Conn)
+	return c
+}
+
+// writeTimeout 配置的写超时，0 表示不限制
+func (s *WebServer) writeTimeout() time.Duration {
+	s.configMu.RLock()
+	defer s.configMu.RUnlock()
+	if s.appConfig == nil {
+		return 0
+	}
+	return time.Duration(s.appConfig.Server.Timeouts.WriteSeconds) * time.Second
+}
+
+// beginStream 流式接口开始时调用：取消读期限，避免请求上下文在读超时后被取消
+func (s *WebServer) beginStream(r *http.Request) {
+	if c := requestConn(r); c != nil {
+		c.SetReadDeadline(time.Time{})
+	}
+}
+
+// extendWriteDeadline 把写期限延长到 extra 加写超时之后（写超时为 0 时不限制），流式接口在每次写入前调用
+func (s *WebServer) extendWriteDeadline(r *http.Request, extra time.Duration) {
+	c := requestConn(r)
+	if c == nil {
+		return
+	}
+	deadline := time.Time{}
+	if timeout := s.writeTimeout(); timeout > 0 {
+		deadline = time.Now().Add(extra + timeout)
+	}
+	c.SetWriteDeadline(deadline)
+}
@@ -3,19 +3,38 @@ package server
 import (
 	"embed"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"io/fs"
 	"net/http"
 	"strconv"
+	"strings"
 	"sync"
+	"time"
 
+	"monitor-agent/buildinfo"
 	"monitor-agent/config"
+	"monitor-agent/impact"
 	"monitor-agent/monitor"
+	"monitor-agent/netmon"
+	"monitor-agent/profile"
+	"monitor-agent/provider"
 	"monitor-agent/types"
 )
 
 //go:embed static/*
 var staticFiles embed.FS
 
+const (
+	// longPollTimeout 长轮询最长等待时间
+	longPollTimeout = 25 * time.Second
+	// sseHeartbeatInterval SSE 心跳间隔（防止代理断开空闲连接）
+	sseHeartbeatInterval = 15 * time.Second
+	// sseBufferSize SSE 订阅缓冲区大小，超出视为慢速客户端并断开
+	sseBufferSize = 64
+)
+
 // WebServer Web 服务器（带界面）
 type WebServer struct {
 	multiMonitor *monitor.MultiMonitor
@@ -53,34 +72,71 @@ func NewWebServerWithAuth(mm *monitor.MultiMonitor, authCfg AuthConfig, appCfg *
 
 	// API 路由
 	s.mux.HandleFunc("/api/processes", s.handleListProcesses)
+	s.mux.HandleFunc("/api/process/history", s.handleProcessHistory)
 	s.mux.HandleFunc("/api/monitor/targets", s.handleTargets)
+	s.mux.HandleFunc("/api/monitor/targets/bulk", s.handleTargetsBulk)
+	s.mux.HandleFunc("/api/monitor/groups", s.handleTargetGroups)
 	s.mux.HandleFunc("/api/monitor/add", s.handleAddTarget)
 	s.mux.HandleFunc("/api/monitor/remove", s.handleRemoveTarget)
 	s.mux.HandleFunc("/api/monitor/removeAll", s.handleRemoveAllTargets)
 	s.mux.HandleFunc("/api/monitor/update", s.handleUpdateTarget)
 	s.mux.HandleFunc("/api/monitor/start", s.handleStart)
 	s.mux.HandleFunc("/api/monitor/stop", s.handleStop)
+	s.mux.HandleFunc("/api/monitor/availability", s.handleAvailability)
+	s.mux.HandleFunc("/api/monitor/maintenance", s.handleMaintenance)
+	s.mux.HandleFunc("/api/monitor/meminfo", s.handleMemInfo)
+	s.mux.HandleFunc("/api/monitor/openfiles", s.handleOpenFiles)
+	s.mux.HandleFunc("/api/monitor/target/snapshot", s.handleTargetSnapshot)
+	s.mux.HandleFunc("/api/monitor/probe", s.handleProbe)
 	s.mux.HandleFunc("/api/metrics", s.handleMetrics)
 	s.mux.HandleFunc("/api/metrics/latest", s.handleLatestMetrics)
 	s.mux.HandleFunc("/api/events", s.handleEvents)
+	s.mux.HandleFunc("/api/events/longpoll", s.handleEventsLongPoll)
+	s.mux.HandleFunc("/api/events/comment", s.handleEventComment)
+	s.mux.HandleFunc("/api/events/stream", s.handleEventsStream)
 	s.mux.HandleFunc("/api/process-changes", s.handleProcessChanges)
+	s.mux.HandleFunc("/api/timeline", s.handleTimeline)
+	s.mux.HandleFunc("/api/logs/search", s.handleLogSearch)
+	s.mux.HandleFunc("/api/history/days", s.handleHistoryDays)
+	s.mux.HandleFunc("/api/history/day", s.handleHistoryDay)
 	s.mux.HandleFunc("/api/status", s.handleStatus)
+	s.mux.HandleFunc("/api/version", s.handleVersion)
+	s.mux.HandleFunc("/api/selftest", s.handleSelfTest)
+	s.mux.HandleFunc("/api/dashboard", s.handleDashboard)
 	s.mux.HandleFunc("/api/system", s.handleSystem)
 	s.mux.HandleFunc("/api/impacts", s.handleImpacts)
+	s.mux.HandleFunc("/api/impacts/get", s.handleImpactGet)
+	s.mux.HandleFunc("/api/impacts/comment", s.handleImpactComment)
 	s.mux.HandleFunc("/api/impacts/summary", s.handleImpactsSummary)
+	s.mux.HandleFunc("/api/impacts/score", s.handleImpactsScore)
+	s.mux.HandleFunc("/api/impacts/offenders", s.handleImpactsOffenders)
+	s.mux.HandleFunc("/api/impacts/diagnostics", s.handleImpactsDiagnostics)
 	s.mux.HandleFunc("/api/impacts/clear", s.handleImpactsClear)
+	s.mux.HandleFunc("/api/impacts/pause", s.handleImpactsPause)
+	s.mux.HandleFunc("/api/impacts/simulate", s.handleImpactsSimulate)
+	s.mux.HandleFunc("/api/impacts/stream", s.handleImpactsStream)
 	s.mux.HandleFunc("/api/config/impact", s.handleImpactConfig)
+	s.mux.HandleFunc("/api/config/export", s.handleConfigExport)
+	s.mux.HandleFunc("/api/config/import", s.handleConfigImport)
+	s.mux.HandleFunc("/api/config/apply-file", s.handleConfigApplyFile)
+	s.mux.HandleFunc("/api/config/save", s.handleConfigSave)
+	s.mux.HandleFunc("/api/audit", s.handleAudit)
 
 	// 静态文件
 	staticFS, _ := fs.Sub(staticFiles, "static")
 	s.mux.Handle("/", http.FileServer(http.FS(staticFS)))
 
-	// 应用认证中间件
-	s.handler = s.authManager.AuthMiddleware(s.mux)
+	// 应用认证和只读模式中间件
+	s.handler = s.authManager.AuthMiddleware(s.readOnlyMiddleware(s.mux))
 
 	return s
 }
 
+// Close 停止 Web 服务器的后台协程（过期会话清理），在 HTTP 服务器关闭后调用
+func (s *WebServer) Close() {
+	s.authManager.Close()
+}
+
 func (s *WebServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// CORS
 	w.Header().Set("Access-Control-Allow-Origin", "*")
@@ -103,43 +159,90 @@ func (s *WebServer) errorResponse(w http.ResponseWriter, code int, msg string) {
 	json.NewEncoder(w).Encode(map[string]string{"error": msg})
 }
 
-// GET /api/processes - 列出系统所有进程
-func (s *WebServer) handleListProcesses(w http.ResponseWriter, r *http.Request) {
-	procs, err := s.multiMonitor.ListAllProcesses()
-	if err != nil {
-		s.errorResponse(w, 500, err.Error())
-		return
-	}
-	s.jsonResponse(w, procs)
+// GET /api/monitor/targets - 获取监控目标列表，含远程探测目标，以 kind 区分（"process" 或 "probe"）
+func (s *WebServer) handleTargets(w http.ResponseWriter, r *http.Request) {
+	s.jsonResponse(w, s.multiMonitor.GetTargetList())
 }
 
-// GET /api/monitor/targets - 获取监控目标列表
-func (s *WebServer) handleTargets(w http.ResponseWriter, r *http.Request) {
-	targets := s.multiMonitor.GetTargets()
-	if targets == nil {
-		targets = []types.MonitorTarget{}
+// GET/POST /api/monitor/targets/bulk - 导出监控目标列表，或按进程名批量添加目标
+func (s *WebServer) handleTargetsBulk(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		list := profile.ExportTargets(s.multiMonitor)
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=monitor-targets-%s.json", list.ExportedAt.Format("20060102-150405")))
+		s.jsonResponse(w, list)
+	case "POST":
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			s.errorResponse(w, 400, "read request body: "+err.Error())
+			return
+		}
+		targets, err := profile.ParseTargets(body)
+		if err != nil {
+			s.errorResponse(w, 400, err.Error())
+			return
+		}
+		result, err := profile.ImportTargets(targets, s.multiMonitor)
+		s.audit(r, "target.import", result, err)
+		if err != nil {
+			s.errorResponse(w, 500, err.Error())
+			return
+		}
+		// 添加后自动启动监控（可通过 server.auto_start 关闭，已在运行时 Start 返回的错误忽略）
+		if result.Added > 0 && s.autoStartEnabled() {
+			s.multiMonitor.Start()
+		}
+		s.jsonResponse(w, result)
+	default:
+		s.errorResponse(w, 405, "method not allowed")
 	}
-	s.jsonResponse(w, targets)
 }
 
 // POST /api/monitor/add - 添加监控目标
+// 已有同名同命令行的目标时返回 409（body 含已有目标的 existing_pid），确需重复监控时带 "force": true 重新提交
 func (s *WebServer) handleAddTarget(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
 		s.errorResponse(w, 405, "method not allowed")
 		return
 	}
-	var target types.MonitorTarget
-	if err := json.NewDecoder(r.Body).Decode(&target); err != nil {
+	var req struct {
+		types.MonitorTarget
+		Force bool `json:"force"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		s.errorResponse(w, 400, "invalid request body")
 		return
 	}
-	if err := s.multiMonitor.AddTarget(target); err != nil {
+	target := req.MonitorTarget
+	if target.RestartCommand != "" || target.RestartPolicy != nil {
+		s.errorResponse(w, http.StatusForbidden, "restart_command and restart_policy can only be set in the config file or CLI")
+		return
+	}
+	warnings, err := monitor.NormalizeTargetWatches(&target)
+	if err == nil {
+		if req.Force {
+			err = s.multiMonitor.ForceAddTarget(target)
+		} else {
+			err = s.multiMonitor.AddTarget(target)
+		}
+	}
+	s.audit(r, "target.add", target, err)
+	var dup *monitor.DuplicateTargetError
+	if errors.As(err, &dup) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]any{"error": err.Error(), "existing_pid": dup.PID})
+		return
+	}
+	if err != nil {
 		s.errorResponse(w, 400, err.Error())
 		return
 	}
-	// 添加后自动启动监控
-	s.multiMonitor.Start()
-	s.jsonResponse(w, map[string]string{"status": "ok"})
+	// 添加后自动启动监控（可通过 server.auto_start 关闭，已在运行时 Start 返回的错误忽略）
+	if s.autoStartEnabled() {
+		s.multiMonitor.Start()
+	}
+	s.targetResponse(w, warnings)
 }
 
 // POST /api/monitor/remove - 移除监控目标
@@ -156,6 +259,7 @@ func (s *WebServer) handleRemoveTarget(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	s.multiMonitor.RemoveTarget(req.PID)
+	s.audit(r, "target.remove", req, nil)
 	s.jsonResponse(w, map[string]string{"status": "ok"})
 }
 
@@ -165,7 +269,9 @@ func (s *WebServer) handleRemoveAllTargets(w http.ResponseWriter, r *http.Reques
 		s.errorResponse(w, 405, "method not allowed")
 		return
 	}
+	removed := len(s.multiMonitor.GetTargets())
 	s.multiMonitor.RemoveAllTargets()
+	s.audit(r, "target.remove_all", map[string]int{"removed": removed}, nil)
 	s.jsonResponse(w, map[string]string{"status": "ok"})
 }
 
@@ -180,11 +286,29 @@ func (s *WebServer) handleUpdateTarget(w http.ResponseWriter, r *http.Request) {
 		s.errorResponse(w, 400, "invalid request body")
 		return
 	}
-	if err := s.multiMonitor.UpdateTarget(target); err != nil {
+	// 重启命令会被代理执行，Web API 不能修改，沿用当前配置
+	if current, ok := s.multiMonitor.GetTarget(target.PID); ok {
+		target.RestartCommand, target.RestartPolicy = current.RestartCommand, current.RestartPolicy
+	}
+	warnings, err := monitor.NormalizeTargetWatches(&target)
+	if err == nil {
+		err = s.multiMonitor.UpdateTarget(target)
+	}
+	s.audit(r, "target.update", target, err)
+	if err != nil {
 		s.errorResponse(w, 400, err.Error())
 		return
 	}
-	s.jsonResponse(w, map[string]string{"status": "ok"})
+	s.targetResponse(w, warnings)
+}
+
+// targetResponse 添加/更新目标成功的响应，监控文件路径可疑时附带 warnings
+func (s *WebServer) targetResponse(w http.ResponseWriter, warnings []string) {
+	if len(warnings) == 0 {
+		s.jsonResponse(w, map[string]string{"status": "ok"})
+		return
+	}
+	s.jsonResponse(w, map[string]any{"status": "ok", "warnings": warnings})
 }
 
 // POST /api/monitor/start - 启动监控
@@ -193,7 +317,12 @@ func (s *WebServer) handleStart(w http.ResponseWriter, r *http.Request) {
 		s.errorResponse(w, 405, "method not allowed")
 		return
 	}
-	s.multiMonitor.Start()
+	err := s.multiMonitor.Start()
+	s.audit(r, "monitor.start", nil, err)
+	if err != nil {
+		s.errorResponse(w, http.StatusConflict, err.Error())
+		return
+	}
 	s.jsonResponse(w, map[string]string{"status": "ok"})
 }
 
@@ -204,6 +333,7 @@ func (s *WebServer) handleStop(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	s.multiMonitor.Stop()
+	s.audit(r, "monitor.stop", nil, nil)
 	s.jsonResponse(w, map[string]string{"status": "ok"})
 }
 
@@ -222,6 +352,32 @@ func (s *WebServer) handleMetrics(w http.ResponseWriter, r *http.Request) {
 	s.jsonResponse(w, metrics)
 }
 
+// GET /api/process/history?pid=&seconds=30 - 按需采样任意进程的短期历史
+// 首次查询会阻塞到采样满 seconds 秒（最长 provider.HistoryMaxSeconds），之后一段时间内的查询复用已有样本
+func (s *WebServer) handleProcessHistory(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	pid, err := strconv.ParseInt(q.Get("pid"), 10, 32)
+	if err != nil || pid <= 0 {
+		s.errorResponse(w, http.StatusBadRequest, "invalid pid")
+		return
+	}
+	seconds := 30
+	if v := q.Get("seconds"); v != "" {
+		seconds, err = strconv.Atoi(v)
+		if err != nil || seconds <= 0 {
+			s.errorResponse(w, http.StatusBadRequest, "invalid seconds")
+			return
+		}
+	}
+
+	samples, err := s.multiMonitor.GetProcessHistory(r.Context(), int32(pid), seconds)
+	if err != nil && samples == nil {
+		s.errorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+	s.jsonResponse(w, samples)
+}
+
 // GET /api/metrics/latest - 获取所有监控目标的最新指标
 func (s *WebServer) handleLatestMetrics(w http.ResponseWriter, r *http.Request) {
 	metrics := s.multiMonitor.GetAllLatestMetrics()
@@ -241,6 +397,342 @@ func (s *WebServer) handleEvents(w http.ResponseWriter, r *http.Request) {
 	s.jsonResponse(w, events)
 }
 
+// GET /api/events/longpoll?since=<seq> - 长轮询获取新事件（最长等待 25 秒）
+func (s *WebServer) handleEventsLongPoll(w http.ResponseWriter, r *http.Request) {
+	since, _ := strconv.ParseInt(r.URL.Query().Get("since"), 10, 64)
+	// 等待时间不计入读写超时
+	s.beginStream(r)
+	s.extendWriteDeadline(r, longPollTimeout)
+	events, seq := s.multiMonitor.WaitEventsSince(r.Context(), since, longPollTimeout)
+	s.jsonResponse(w, map[string]interface{}{
+		"seq":    seq,
+		"events": events,
+	})
+}
+
+// GET /api/events/stream - SSE 实时推送新事件、进程变化和影响事件
+func (s *WebServer) handleEventsStream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		s.errorResponse(w, 500, "streaming not supported")
+		return
+	}
+
+	events, unsubscribe := s.multiMonitor.Subscribe(sseBufferSize)
+	defer unsubscribe()
+	impacts, unsubscribeImpacts := s.multiMonitor.SubscribeImpacts(sseBufferSize)
+	defer unsubscribeImpacts()
+
+	// 长连接不受整体读写超时限制，每次等待前延长写期限
+	s.beginStream(r)
+	s.extendWriteDeadline(r, 0)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // 禁止 nginx 缓冲
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		s.extendWriteDeadline(r, sseHeartbeatInterval)
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := w.Write([]byte(": heartbeat\n\n")); err != nil {
+				return
+			}
+		case msg, ok := <-events:
+			if !ok {
+				return // 消费过慢被移除，客户端会自动重连
+			}
+			if err := writeSSE(w, msg.Type, msg.Data); err != nil {
+				return
+			}
+		case imp, ok := <-impacts:
+			if !ok {
+				return
+			}
+			if err := writeSSE(w, "impact", imp); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+// GET /api/impacts/stream?minSeverity=high - SSE 只推送影响事件：新影响为 event: impact，
+// 影响解除为 event: impact_resolved（data 为解除前的影响事件，按 id 对应）。供大屏等只关心影响的客户端使用，
+// minSeverity 只推送不低于该级别的影响及其解除
+func (s *WebServer) handleImpactsStream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		s.errorResponse(w, 405, "method not allowed")
+		return
+	}
+	minSeverity := strings.ToLower(r.URL.Query().Get("minSeverity"))
+	if minSeverity != "" && !impact.IsSeverity(minSeverity) {
+		s.errorResponse(w, http.StatusBadRequest, "invalid minSeverity, expected low/medium/high/critical")
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		s.errorResponse(w, 500, "streaming not supported")
+		return
+	}
+
+	impacts, unsubscribe := s.multiMonitor.SubscribeImpacts(sseBufferSize)
+	defer unsubscribe()
+	resolved, unsubscribeResolved := s.multiMonitor.SubscribeResolvedImpacts(sseBufferSize)
+	defer unsubscribeResolved()
+
+	// 长连接不受整体读写超时限制，每次等待前延长写期限
+	s.beginStream(r)
+	s.extendWriteDeadline(r, 0)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // 禁止 nginx 缓冲
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		s.extendWriteDeadline(r, sseHeartbeatInterval)
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := w.Write([]byte(": heartbeat\n\n")); err != nil {
+				return
+			}
+		case imp, ok := <-impacts:
+			if !ok {
+				return // 消费过慢被移除，客户端会自动重连
+			}
+			if !impact.AtLeastSeverity(imp.Severity, minSeverity) {
+				continue
+			}
+			if err := writeSSE(w, "impact", imp); err != nil {
+				return
+			}
+		case imp, ok := <-resolved:
+			if !ok {
+				return
+			}
+			if !impact.AtLeastSeverity(imp.Severity, minSeverity) {
+				continue
+			}
+			if err := writeSSE(w, "impact_resolved", imp); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+// writeSSE 写入一条 SSE 消息
+func writeSSE(w http.ResponseWriter, event string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload)
+	return err
+}
+
+// GET /api/timeline?pid=xxx&from=&to=&cursor=&limit= - 获取监控目标的时间线
+// from/to 支持 RFC3339 或 Unix 秒
+func (s *WebServer) handleTimeline(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	pid, err := strconv.ParseInt(q.Get("pid"), 10, 32)
+	if err != nil || pid <= 0 {
+		s.errorResponse(w, http.StatusBadRequest, "invalid pid")
+		return
+	}
+	from, err := parseTimeParam(q.Get("from"))
+	if err != nil {
+		s.errorResponse(w, http.StatusBadRequest, "invalid from: "+err.Error())
+		return
+	}
+	to, err := parseTimeParam(q.Get("to"))
+	if err != nil {
+		s.errorResponse(w, http.StatusBadRequest, "invalid to: "+err.Error())
+		return
+	}
+	cursor, _ := strconv.Atoi(q.Get("cursor"))
+	limit, _ := strconv.Atoi(q.Get("limit"))
+
+	s.jsonResponse(w, s.multiMonitor.GetTimeline(int32(pid), from, to, cursor, limit))
+}
+
+// GET/POST /api/monitor/maintenance - 查询或设置维护模式
+// POST body: {"pid":1234,"duration":"2h","reason":"打补丁"}，pid 省略或为 0 表示全局；{"pid":1234,"end":true} 提前结束
+func (s *WebServer) handleMaintenance(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "GET" {
+		s.jsonResponse(w, s.multiMonitor.GetMaintenance())
+		return
+	}
+	if r.Method != "POST" {
+		s.errorResponse(w, 405, "method not allowed")
+		return
+	}
+
+	var req struct {
+		PID      int32  `json:"pid"`
+		Duration string `json:"duration"`
+		Reason   string `json:"reason"`
+		End      bool   `json:"end"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.errorResponse(w, 400, "invalid request body")
+		return
+	}
+
+	if req.End {
+		err := s.multiMonitor.EndMaintenance(req.PID)
+		s.audit(r, "target.maintenance_end", map[string]int32{"pid": req.PID}, err)
+		if err != nil {
+			s.errorResponse(w, 404, err.Error())
+			return
+		}
+		s.jsonResponse(w, map[string]string{"status": "ok"})
+		return
+	}
+
+	duration, err := time.ParseDuration(req.Duration)
+	if err != nil || duration <= 0 {
+		s.errorResponse(w, 400, "invalid duration, expected e.g. \"2h\" or \"30m\"")
+		return
+	}
+	win, err := s.multiMonitor.StartMaintenance(req.PID, duration, req.Reason)
+	s.audit(r, "target.maintenance_start", req, err)
+	if err != nil {
+		s.errorResponse(w, 400, err.Error())
+		return
+	}
+	s.jsonResponse(w, win)
+}
+
+// GET /api/monitor/availability?pid=&window=7d - 获取目标可用率（不指定 pid 时返回所有目标）
+func (s *WebServer) handleAvailability(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	windowStr := q.Get("window")
+	if windowStr == "" {
+		windowStr = "7d"
+	}
+	window, err := parseWindowParam(windowStr)
+	if err != nil {
+		s.errorResponse(w, http.StatusBadRequest, "invalid window: "+err.Error())
+		return
+	}
+
+	if q.Get("pid") == "" {
+		reports := s.multiMonitor.GetAllAvailability(window)
+		for i := range reports {
+			reports[i].Window = windowStr
+		}
+		s.jsonResponse(w, reports)
+		return
+	}
+
+	pid, err := strconv.ParseInt(q.Get("pid"), 10, 32)
+	if err != nil || pid <= 0 {
+		s.errorResponse(w, http.StatusBadRequest, "invalid pid")
+		return
+	}
+	report, ok := s.multiMonitor.GetAvailability(int32(pid), window)
+	if !ok {
+		s.errorResponse(w, http.StatusNotFound, "target not found")
+		return
+	}
+	report.Window = windowStr
+	s.jsonResponse(w, report)
+}
+
+// handleMemInfo 目标内存构成明细
+func (s *WebServer) handleMemInfo(w http.ResponseWriter, r *http.Request) {
+	pid, err := strconv.ParseInt(r.URL.Query().Get("pid"), 10, 32)
+	if err != nil || pid <= 0 {
+		s.errorResponse(w, http.StatusBadRequest, "invalid pid")
+		return
+	}
+	mb, err := s.multiMonitor.GetMemoryBreakdown(int32(pid))
+	if err != nil {
+		s.errorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+	s.jsonResponse(w, mb)
+}
+
+// handleOpenFiles 目标打开的文件、套接字和管道（按目录分组计数），附带与上次查询的差异
+func (s *WebServer) handleOpenFiles(w http.ResponseWriter, r *http.Request) {
+	pid, err := strconv.ParseInt(r.URL.Query().Get("pid"), 10, 32)
+	if err != nil || pid <= 0 {
+		s.errorResponse(w, http.StatusBadRequest, "invalid pid")
+		return
+	}
+	report, err := s.multiMonitor.GetOpenFiles(int32(pid))
+	if err != nil {
+		s.errorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+	s.jsonResponse(w, report)
+}
+
+// handleTargetSnapshot 目标启动快照（添加目标时记录的命令行、工作目录和环境变量）
+func (s *WebServer) handleTargetSnapshot(w http.ResponseWriter, r *http.Request) {
+	pid, err := strconv.ParseInt(r.URL.Query().Get("pid"), 10, 32)
+	if err != nil || pid <= 0 {
+		s.errorResponse(w, http.StatusBadRequest, "invalid pid")
+		return
+	}
+	snap, ok := s.multiMonitor.GetLaunchSnapshot(int32(pid))
+	if !ok {
+		s.errorResponse(w, http.StatusNotFound, "snapshot not found")
+		return
+	}
+	s.jsonResponse(w, snap)
+}
+
+// parseWindowParam 解析时间窗口参数，支持 "7d" 形式的天数或 Go duration（如 "12h"）
+func parseWindowParam(v string) (time.Duration, error) {
+	var d time.Duration
+	if strings.HasSuffix(v, "d") {
+		n, err := strconv.Atoi(strings.TrimSuffix(v, "d"))
+		if err != nil {
+			return 0, err
+		}
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		if d, err = time.ParseDuration(v); err != nil {
+			return 0, err
+		}
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("window must be positive")
+	}
+	return d, nil
+}
+
+// parseTimeParam 解析时间参数（RFC3339 或 Unix 秒），空字符串返回零值
+func parseTimeParam(v string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if sec, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return time.Unix(sec, 0), nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
+
 // GET /api/process-changes?n=50 - 获取最近进程变化
 func (s *WebServer) handleProcessChanges(w http.ResponseWriter, r *http.Request) {
 	n, _ := strconv.Atoi(r.URL.Query().Get("n"))
@@ -256,12 +748,78 @@ func (s *WebServer) handleProcessChanges(w http.ResponseWriter, r *http.Request)
 
 // GET /api/status - 获取监控状态
 func (s *WebServer) handleStatus(w http.ResponseWriter, r *http.Request) {
+	status := map[string]any{
+		"running":     s.multiMonitor.IsRunning(),
+		"auto_start":  s.autoStartEnabled(),
+		"read_only":   s.readOnlyEnabled(),
+		"cpu_style":   s.multiMonitor.GetCPUStyle(),
+		"targets":     len(s.multiMonitor.GetTargets()),
+		"maintenance": s.multiMonitor.GetMaintenance(),
+	}
+	// 配置文件在运行中被修改时附带与运行中配置的差异
+	drift := s.configDrift()
+	status["config_drift"] = drift.Drift
+	if drift.Drift {
+		status["config_diff"] = drift.Diff
+		if drift.Error != "" {
+			status["config_drift_error"] = drift.Error
+		}
+	}
+	s.jsonResponse(w, status)
+}
+
+// versionInfo /api/version 的返回内容：构建信息加上运行时的采集方式
+type versionInfo struct {
+	buildinfo.Info
+	Provider   string `json:"provider"`    // 进程信息来源
+	NetmonMode string `json:"netmon_mode"` // 进程流量统计方式
+}
+
+// GET /api/version - 获取版本与构建信息（含 provider 类型和网络监控方式），用于核对各主机部署的版本
+func (s *WebServer) handleVersion(w http.ResponseWriter, r *http.Request) {
+	s.jsonResponse(w, versionInfo{
+		Info:       buildinfo.Get(),
+		Provider:   provider.Type(),
+		NetmonMode: netmon.Mode,
+	})
+}
+
+// GET /api/dashboard - 获取首页所需的全部数据（系统指标、目标状态、最近事件、影响摘要）
+func (s *WebServer) handleDashboard(w http.ResponseWriter, r *http.Request) {
+	targets, running := s.multiMonitor.GetTargetStatuses()
+
+	var system *types.SystemMetrics
+	if metrics, err := s.multiMonitor.GetSystemMetrics(); err == nil {
+		system = metrics
+	}
+
+	events := s.multiMonitor.GetRecentEvents(10)
+	if events == nil {
+		events = []types.Event{}
+	}
+
 	s.jsonResponse(w, map[string]any{
-		"running": s.multiMonitor.IsRunning(),
-		"targets": len(s.multiMonitor.GetTargets()),
+		"generated_at":   time.Now(),
+		"running":        running,
+		"auto_start":     s.autoStartEnabled(),
+		"cpu_style":      s.multiMonitor.GetCPUStyle(),
+		"system":         system,
+		"targets":        targets,
+		"events":         events,
+		"impact_summary": s.multiMonitor.GetImpactSummary(false),
 	})
 }
 
+// autoStartEnabled 添加目标时是否自动启动监控（无配置时保持自动启动）
+func (s *WebServer) autoStartEnabled() bool {
+	s.configMu.RLock()
+	defer s.configMu.RUnlock()
+	if s.appConfig == nil {
+		return true
+	}
+	return s.appConfig.Server.AutoStart
+}
+
 // GET /api/system - 获取系统指标
 func (s *WebServer) handleSystem(w http.ResponseWriter, r *http.Request) {
 	metrics, err := s.multiMonitor.GetSystemMetrics()
@@ -272,25 +830,71 @@ func (s *WebServer) handleSystem(w http.ResponseWriter, r *http.Request) {
 	s.jsonResponse(w, metrics)
 }
 
-// GET /api/impacts?n=50 - 获取最近影响事件
+// GET /api/impacts?n=50&group=true&minSeverity=high - 获取最近影响事件，group=true 时合并同一目标、同一类型的多个影响源，
+// minSeverity 只返回不低于该级别的事件（low/medium/high/critical）
 func (s *WebServer) handleImpacts(w http.ResponseWriter, r *http.Request) {
 	n, _ := strconv.Atoi(r.URL.Query().Get("n"))
 	if n <= 0 {
 		n = 50
 	}
-	impacts := s.multiMonitor.GetRecentImpacts(n)
+	grouped, _ := strconv.ParseBool(r.URL.Query().Get("group"))
+	minSeverity := strings.ToLower(r.URL.Query().Get("minSeverity"))
+	if minSeverity != "" && !impact.IsSeverity(minSeverity) {
+		s.errorResponse(w, http.StatusBadRequest, "invalid minSeverity, expected low/medium/high/critical")
+		return
+	}
+	impacts := s.multiMonitor.GetRecentImpactsMin(n, grouped, minSeverity)
 	if impacts == nil {
 		impacts = []types.ImpactEvent{}
 	}
 	s.jsonResponse(w, impacts)
 }
 
-// GET /api/impacts/summary - 获取影响统计摘要
+// GET /api/impacts/get?id=xxx - 按 ID 获取单个影响事件（含检测时的指标历史），已解除的近期事件也可查询
+func (s *WebServer) handleImpactGet(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		s.errorResponse(w, http.StatusBadRequest, "missing id")
+		return
+	}
+	imp, ok := s.multiMonitor.GetImpact(id)
+	if !ok {
+		s.errorResponse(w, http.StatusNotFound, "impact not found")
+		return
+	}
+	s.jsonResponse(w, imp)
+}
+
+// GET /api/impacts/summary?group=true - 获取影响统计摘要，group=true 时按合并后的条目统计
 func (s *WebServer) handleImpactsSummary(w http.ResponseWriter, r *http.Request) {
-	summary := s.multiMonitor.GetImpactSummary()
+	grouped, _ := strconv.ParseBool(r.URL.Query().Get("group"))
+	summary := s.multiMonitor.GetImpactSummary(grouped)
 	s.jsonResponse(w, summary)
 }
 
+// GET /api/impacts/score - 获取健康评分和等级
+func (s *WebServer) handleImpactsScore(w http.ResponseWriter, r *http.Request) {
+	score, grade := s.multiMonitor.GetHealthScore()
+	s.jsonResponse(w, map[string]any{
+		"score": score,
+		"grade": grade,
+	})
+}
+
+// GET /api/impacts/offenders?n=10 - 最近 7 天影响目标最多的进程排行
+func (s *WebServer) handleImpactsOffenders(w http.ResponseWriter, r *http.Request) {
+	n, _ := strconv.Atoi(r.URL.Query().Get("n"))
+	if n <= 0 {
+		n = 10
+	}
+	s.jsonResponse(w, s.multiMonitor.GetImpactOffenders(n))
+}
+
+// GET /api/impacts/diagnostics - 影响分析器的运行诊断（最近周期耗时、文件/端口检测缓存、各项检测最近的错误）
+func (s *WebServer) handleImpactsDiagnostics(w http.ResponseWriter, r *http.Request) {
+	s.jsonResponse(w, s.multiMonitor.GetImpactDiagnostics())
+}
+
 // POST /api/impacts/clear - 清除所有影响事件
 func (s *WebServer) handleImpactsClear(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
@@ -298,9 +902,69 @@ func (s *WebServer) handleImpactsClear(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	s.multiMonitor.ClearImpactEvents()
+	s.audit(r, "impact.clear", nil, nil)
 	s.jsonResponse(w, map[string]string{"status": "ok"})
 }
 
+// GET/POST /api/impacts/pause - 查询或设置风险告警暂停（即全局维护模式）
+// POST body: {"duration":"1h","reason":"数据库备份"}；{"end":true} 提前恢复
+// GET 返回 {"paused":true,"window":{...},"scheduled":"夜间备份"}，scheduled 为当前所在的计划维护时段
+func (s *WebServer) handleImpactsPause(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "GET" {
+		resp := map[string]any{"paused": false}
+		for _, win := range s.multiMonitor.GetMaintenance() {
+			if win.PID == 0 {
+				resp["paused"], resp["window"] = true, win
+			}
+		}
+		if analyzer := s.multiMonitor.GetImpactAnalyzer(); analyzer != nil {
+			if name := analyzer.ActiveMaintenanceWindow(); name != "" {
+				resp["paused"], resp["scheduled"] = true, name
+			}
+		}
+		s.jsonResponse(w, resp)
+		return
+	}
+	if r.Method != "POST" {
+		s.errorResponse(w, 405, "method not allowed")
+		return
+	}
+
+	var req struct {
+		Duration string `json:"duration"`
+		Reason   string `json:"reason"`
+		End      bool   `json:"end"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.errorResponse(w, 400, "invalid request body")
+		return
+	}
+
+	if req.End {
+		err := s.multiMonitor.EndMaintenance(0)
+		s.audit(r, "impact.resume", nil, err)
+		if err != nil {
+			s.errorResponse(w, 404, "impact alerts are not paused")
+			return
+		}
+		s.jsonResponse(w, map[string]string{"status": "ok"})
+		return
+	}
+
+	duration, err := time.ParseDuration(req.Duration)
+	if err != nil || duration <= 0 {
+		s.errorResponse(w, 400, "invalid duration, expected e.g. \"30m\" or \"2h\"")
+		return
+	}
+	win, err := s.multiMonitor.StartMaintenance(0, duration, req.Reason)
+	s.audit(r, "impact.pause", req, err)
+	if err != nil {
+		s.errorResponse(w, 400, err.Error())
+		return
+	}
+	s.jsonResponse(w, win)
+}
+
 // GET/POST /api/config/impact - 获取或更新影响分析配置
 func (s *WebServer) handleImpactConfig(w http.ResponseWriter, r *http.Request) {
 	if r.Method == "GET" {
@@ -324,19 +988,56 @@ func (s *WebServer) handleImpactConfig(w http.ResponseWriter, r *http.Request) {
 			s.appConfig = config.DefaultConfig()
 		}
 		
-		// 解码到当前配置上（只覆盖 JSON 中存在的字段）
-		if err := json.NewDecoder(r.Body).Decode(&s.appConfig.Impact); err != nil {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			s.errorResponse(w, 400, "read request body: "+err.Error())
+			return
+		}
+
+		// 解码到当前配置的副本上（只覆盖 JSON 中存在的字段），
+		// profiles 和 maintenance_windows 整体替换，避免与旧列表按下标合并
+		impactCfg := s.appConfig.Impact
+		impactCfg.Profiles = nil
+		impactCfg.MaintenanceWindows = nil
+		if err := json.Unmarshal(body, &impactCfg); err != nil {
 			s.errorResponse(w, 400, "invalid request body: "+err.Error())
 			return
 		}
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(body, &fields); err == nil {
+			if _, ok := fields["profiles"]; !ok {
+				impactCfg.Profiles = s.appConfig.Impact.Profiles
+			}
+			if _, ok := fields["maintenance_windows"]; !ok {
+				impactCfg.MaintenanceWindows = s.appConfig.Impact.MaintenanceWindows
+			}
+		}
+
+		warnings, err := impact.ValidateProfiles(impactCfg.Profiles)
+		if err != nil {
+			s.errorResponse(w, 400, "invalid profiles: "+err.Error())
+			return
+		}
+		if err := impact.ValidateDurations(impactCfg); err != nil {
+			s.errorResponse(w, 400, "invalid durations: "+err.Error())
+			return
+		}
+		if err := impact.ValidateMaintenanceWindows(impactCfg.MaintenanceWindows); err != nil {
+			s.errorResponse(w, 400, "invalid maintenance windows: "+err.Error())
+			return
+		}
+		changes := changedFields(s.appConfig.Impact, impactCfg)
+		s.appConfig.Impact = impactCfg
 		
 		// 保存到文件
 		if s.configFile != "" {
 			if err := config.SaveConfig(s.configFile, s.appConfig); err != nil {
+				s.audit(r, "config.impact", changes, err)
 				s.errorResponse(w, 500, "save config failed: "+err.Error())
 				return
 			}
 		}
+		s.audit(r, "config.impact", changes, nil)
 		
 		// 更新影响分析器配置
 		analyzer := s.multiMonitor.GetImpactAnalyzer()
@@ -344,9 +1045,86 @@ func (s *WebServer) handleImpactConfig(w http.ResponseWriter, r *http.Request) {
 			analyzer.UpdateConfig(s.appConfig.Impact)
 		}
 		
-		s.jsonResponse(w, map[string]string{"status": "ok"})
+		resp := map[string]any{"status": "ok"}
+		if len(warnings) > 0 {
+			resp["warnings"] = warnings
+		}
+		s.jsonResponse(w, resp)
 		return
 	}
 	
 	s.errorResponse(w, 405, "method not allowed")
 }
+
+// GET /api/config/export - 导出完整监控配置档案
+func (s *WebServer) handleConfigExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		s.errorResponse(w, 405, "method not allowed")
+		return
+	}
+	s.configMu.RLock()
+	cfg := s.appConfig
+	if cfg == nil {
+		cfg = config.DefaultConfig()
+	}
+	doc := profile.Export(cfg, s.multiMonitor)
+	s.configMu.RUnlock()
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=monitor-profile-%s.json", doc.ExportedAt.Format("20060102-150405")))
+	s.jsonResponse(w, doc)
+}
+
+// POST /api/config/import?dry_run=true - 导入监控配置档案，dry_run 时只返回将要进行的变更
+func (s *WebServer) handleConfigImport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		s.errorResponse(w, 405, "method not allowed")
+		return
+	}
+	dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dry_run"))
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		s.errorResponse(w, 400, "read request body: "+err.Error())
+		return
+	}
+
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+	if s.appConfig == nil {
+		s.appConfig = config.DefaultConfig()
+	}
+
+	doc, err := profile.Parse(body, s.appConfig)
+	if err != nil {
+		s.errorResponse(w, 400, err.Error())
+		return
+	}
+	plan, err := profile.NewPlan(doc, s.appConfig, s.multiMonitor)
+	if err != nil {
+		s.errorResponse(w, 400, err.Error())
+		return
+	}
+
+	if !dryRun && len(plan.Changes) > 0 {
+		if err := plan.Apply(s.appConfig, s.multiMonitor); err != nil {
+			s.audit(r, "config.import", plan.Changes, err)
+			s.errorResponse(w, 500, "import failed, rolled back: "+err.Error())
+			return
+		}
+		if s.configFile != "" {
+			if err := config.SaveConfig(s.configFile, s.appConfig); err != nil {
+				s.audit(r, "config.import", plan.Changes, err)
+				s.errorResponse(w, 500, "save config failed: "+err.Error())
+				return
+			}
+		}
+		s.audit(r, "config.import", plan.Changes, nil)
+	}
+
+	s.jsonResponse(w, map[string]any{
+		"status":   "ok",
+		"dry_run":  dryRun,
+		"changes":  plan.Changes,
+		"warnings": plan.Warnings,
+	})
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"strings"
+	"time"
+
+	"monitor-agent/config"
+	"monitor-agent/i18n"
+	"monitor-agent/logger"
+)
+
+// configDriftInterval 检查配置文件是否在运行中被修改的间隔
+const configDriftInterval = time.Minute
+
+// runConfigDriftCheck 每分钟检查配置文件是否在运行中被手工修改（配置漂移），
+// 出现漂移时记录 WARN 日志和 config_drift 事件，漂移消除（按文件应用或保存配置）后记录 config_drift_resolved 事件
+func (s *Service) runConfigDriftCheck() {
+	ticker := time.NewTicker(configDriftInterval)
+	defer ticker.Stop()
+	drifted := false
+	for {
+		select {
+		case <-ticker.C:
+		case <-s.ctx.Done():
+			return
+		}
+
+		drift := config.CheckDrift(s.config.ConfigFile, s.appConfig)
+		if drift.Drift == drifted {
+			continue
+		}
+		drifted = drift.Drift
+		name := i18n.T("config.drift.name")
+		if !drifted {
+			logger.Infof("SERVICE", "Config file %s matches the running config again", s.config.ConfigFile)
+			s.mm.AddImpactEvent("config_drift_resolved", 0, name, i18n.T("config.drift.resolved", s.config.ConfigFile))
+			continue
+		}
+
+		detail := drift.Error
+		if detail == "" {
+			fields := make([]string, len(drift.Diff))
+			for i, d := range drift.Diff {
+				fields[i] = d.Field
+			}
+			detail = strings.Join(fields, ", ")
+		}
+		logger.Warnf("SERVICE", "Config file %s was modified while running and differs from the running config: %s "+
+			"(use 'config apply-file' or 'config save' to reconcile)", s.config.ConfigFile, detail)
+		s.mm.AddImpactEvent("config_drift", 0, name, i18n.T("config.drift.detected", s.config.ConfigFile, detail))
+	}
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"strings"
+	"time"
+
+	"monitor-agent/logger"
+)
+
+// logJanitorInterval 日志清理间隔
+const logJanitorInterval = time.Hour
+
+// logRetentionPolicy 配置中的日志保留策略
+func (s *Service) logRetentionPolicy() logger.RetentionPolicy {
+	return logger.RetentionPolicy{
+		MaxAge:        time.Duration(s.appConfig.Logging.MaxAgeDays) * 24 * time.Hour,
+		MaxTotalBytes: int64(s.appConfig.Logging.MaxTotalMB) * 1024 * 1024,
+	}
+}
+
+// runLogJanitor 启动时及之后每小时按保留策略清理日志目录，服务停止时退出
+func (s *Service) runLogJanitor() {
+	policy := s.logRetentionPolicy()
+	if policy.MaxAge <= 0 && policy.MaxTotalBytes <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(logJanitorInterval)
+	defer ticker.Stop()
+	for {
+		s.cleanupLogs(policy)
+		select {
+		case <-ticker.C:
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+// cleanupLogs 执行一次清理并记录结果
+func (s *Service) cleanupLogs(policy logger.RetentionPolicy) {
+	result, err := logger.CleanupLogs(s.config.LogDir, policy, time.Now())
+	if err != nil {
+		logger.Warnf("SERVICE", "Log cleanup failed: %v", err)
+		return
+	}
+	if len(result.Removed) > 0 {
+		logger.Infof("SERVICE", "Log cleanup removed %d files (%d bytes): %s",
+			len(result.Removed), result.Freed, strings.Join(result.Removed, ", "))
+	}
+	if len(result.Failed) > 0 {
+		logger.Warnf("SERVICE", "Log cleanup could not remove %d files (in use?), will retry: %s",
+			len(result.Failed), strings.Join(result.Failed, ", "))
+	}
+}
@@ -4,25 +4,40 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"path/filepath"
 	"time"
 
+	"monitor-agent/buildinfo"
 	"monitor-agent/config"
+	"monitor-agent/format"
+	"monitor-agent/i18n"
 	"monitor-agent/impact"
+	"monitor-agent/influx"
 	"monitor-agent/logger"
 	"monitor-agent/monitor"
+	"monitor-agent/netmon"
 	"monitor-agent/provider"
+	"monitor-agent/selftest"
 	"monitor-agent/server"
+	"monitor-agent/timefmt"
 	"monitor-agent/types"
 )
 
 // Config 服务配置
 type Config struct {
-	Addr       string
-	LogDir     string
-	ConfigFile string
+	Addr         string
+	LogDir       string
+	ConfigFile   string
+	NoConsoleLog bool // 不向终端输出日志（CLI JSON 模式下 stdout 只输出 JSON）
+
+	// Logger 日志实现，为 nil 时按配置在日志目录写 JSONL 日志文件；
+	// 嵌入到其他程序时可使用宿主的日志，此时不创建日志目录，也不按保留策略清理日志目录
+	Logger logger.Sink
+	// CaptureStdLog 把标准 log 包的输出转到日志器（独立运行时使用，嵌入时保留宿主的设置）
+	CaptureStdLog bool
 }
 
 // Service 监控服务
@@ -30,9 +45,13 @@ type Service struct {
 	config     Config
 	appConfig  *config.Config
 	mm         *monitor.MultiMonitor
+	prov       provider.ProcProvider
+	webServer  *server.WebServer
 	httpServer *http.Server
 	ctx        context.Context
 	cancel     context.CancelFunc
+
+	selfTest selftest.Report // 启动自检结果
 }
 
 // New 创建服务实例（使用默认配置）
@@ -42,34 +61,83 @@ func New(cfg Config) (*Service, error) {
 
 // NewWithConfig 创建服务实例（使用指定配置）
 func NewWithConfig(cfg Config, appCfg *config.Config) (*Service, error) {
-	// 确保日志目录存在
 	if cfg.LogDir == "" {
 		exe, _ := os.Executable()
 		cfg.LogDir = filepath.Join(filepath.Dir(exe), "logs")
 	}
-	os.MkdirAll(cfg.LogDir, 0755)
 
-	// 初始化统一日志器
-	if err := logger.Init(cfg.LogDir, appCfg.Logging.FileOutput, appCfg.Logging.ConsoleOutput); err != nil {
+	// 初始化统一日志器（创建日志目录），或使用调用方的日志实现
+	if cfg.Logger != nil {
+		logger.SetSink(cfg.Logger)
+	} else if err := logger.Init(cfg.LogDir, appCfg.Logging.FileOutput, appCfg.Logging.ConsoleOutput && !cfg.NoConsoleLog); err != nil {
 		return nil, fmt.Errorf("init logger: %w", err)
+	} else if err := logger.SetMetricFormat(appCfg.Logging.MetricFormat); err != nil {
+		logger.Warnf("SERVICE", "%v, metrics are logged as json", err)
 	}
 
 	// 设置标准log输出到统一日志器（兼容老代码）
-	if logger.Default() != nil {
+	if cfg.CaptureStdLog && logger.Default() != nil {
 		log.SetOutput(logger.Default().GetWriter())
 		log.SetFlags(0) // 不使用标准log的时间戳前缀
 	}
 
+	// 时区和时间格式，时区无效时回退为本地时区
+	if err := timefmt.Configure(appCfg.Logging.TimeZone, appCfg.Logging.TimeFormat); err != nil {
+		logger.Warnf("SERVICE", "%v", err)
+	}
+
+	// 字节数的单位口径，无效时保持旧版显示
+	if err := format.SetByteUnits(appCfg.Display.ByteUnits); err != nil {
+		logger.Warnf("SERVICE", "%v", err)
+	}
+
+	// 事件消息和影响描述的语言，不支持时回退为中文
+	if err := i18n.SetLanguage(appCfg.Language); err != nil {
+		logger.Warnf("SERVICE", "%v", err)
+	}
+
+	// 非 root/管理员运行时无法读取其他用户进程的部分指标，启动时给出明确提示
+	if !provider.IsElevated() {
+		logger.Warn("SERVICE", "Agent is not running as root/administrator: disk IO, exe path and FD counts "+
+			"of other users' processes cannot be read and show as 0 (marked restricted)")
+	}
+
 	monitorCfg := types.MultiMonitorConfig{
 		SampleInterval:   appCfg.Sampling.Interval,
 		MetricsBufferLen: appCfg.Sampling.MetricsBufferLen,
 		EventsBufferLen:  appCfg.Sampling.EventsBufferLen,
+		EventDedupWindow: appCfg.Sampling.EventDedupWindow,
+		EventRateLimit:   appCfg.Sampling.EventRateLimit,
 		LogDir:           cfg.LogDir,
+
+		SampleJitterMS:       appCfg.Sampling.SampleJitterMS,
+		SampleJitterEachTick: appCfg.Sampling.SampleJitterEachTick,
+
+		BinaryCheckInterval: appCfg.Sampling.BinaryCheckInterval,
+		BinaryHash:          appCfg.Sampling.BinaryHash,
+
+		SnapshotEnvAllow: appCfg.Sampling.SnapshotEnvAllow,
+		SnapshotEnvDeny:  appCfg.Sampling.SnapshotEnvDeny,
+
+		AllowRestart: appCfg.Watchdog.AllowRestart,
+	}
+	if monitorCfg.AllowRestart {
+		logger.Warn("SERVICE", "Watchdog enabled: targets with a restart_policy will be restarted by running their restart_command")
 	}
 
-	prov := provider.New()
+	prov := provider.NewWithOptions(provider.Options{
+		StripExeSuffix: appCfg.Sampling.StripExeSuffix,
+		NameAliases:    appCfg.Display.NameAliases,
+		CPUStyle:       appCfg.Sampling.CPUStyle,
+		CollectWorkers: appCfg.Sampling.CollectWorkers,
+		NetMon: netmon.Options{
+			Interfaces:        appCfg.NetMon.Interfaces,
+			ExcludeInterfaces: appCfg.NetMon.ExcludeInterfaces,
+		},
+	})
 	mm, err := monitor.NewMultiMonitor(monitorCfg, prov)
 	if err != nil {
+		prov.Close()
 		return nil, fmt.Errorf("create multi monitor: %w", err)
 	}
 
@@ -85,6 +153,8 @@ func NewWithConfig(cfg Config, appCfg *config.Config) (*Service, error) {
 		analyzer.SetEventCallback(func(eventType string, pid int32, name string, message string) {
 			mm.AddImpactEvent(eventType, pid, name, message)
 		})
+		// 影响源排行保存在日志目录，重启后继续累计
+		analyzer.SetOffenderTracker(impact.NewOffenderTracker(cfg.LogDir))
 		mm.SetImpactAnalyzer(analyzer)
 		logger.Infof("SERVICE", "Impact analyzer enabled (interval=%ds)", appCfg.Impact.AnalysisInterval)
 	}
@@ -95,6 +165,7 @@ func NewWithConfig(cfg Config, appCfg *config.Config) (*Service, error) {
 		config:    cfg,
 		appConfig: appCfg,
 		mm:        mm,
+		prov:      prov,
 		ctx:       ctx,
 		cancel:    cancel,
 	}
@@ -107,10 +178,28 @@ func NewWithConfig(cfg Config, appCfg *config.Config) (*Service, error) {
 // Start 启动服务
 func (s *Service) Start() error {
 	logger.Info("SERVICE", "Starting monitor service...")
+	logger.Infof("SERVICE", "%s", buildinfo.Full())
+	logger.Infof("SERVICE", "Provider: %s, netmon mode: %s", provider.Type(), netmon.Mode)
 	logger.Infof("SERVICE", "Log directory: %s", s.config.LogDir)
+	s.runSelfTest()
 
-	// 启动监控
-	s.mm.Start()
+	// 日志转发到 syslog（配置无效时只记录错误，不影响监控）
+	if sl := s.appConfig.Logging.Syslog; sl.Enabled {
+		fwd, err := logger.NewSyslogForwarder(sl.Options())
+		if err != nil {
+			logger.Errorf("SERVICE", "Syslog forwarding disabled: %v", err)
+		} else {
+			logger.SetSyslogForwarder(fwd)
+			logger.Infof("SERVICE", "Forwarding logs to syslog (%s %s)", sl.Network, sl.Addr)
+		}
+	}
+
+	// 启动监控（auto_start 关闭时等待手动启动）
+	if s.appConfig.Server.AutoStart {
+		s.mm.Start()
+	} else {
+		logger.Info("SERVICE", "Auto start disabled, monitoring waits for explicit start")
+	}
 
 	// 临时禁用目标变化回调（避免加载时触发保存）
 	s.mm.SetTargetChangeCallback(nil)
@@ -120,6 +209,11 @@ func (s *Service) Start() error {
 		logger.Errorf("SERVICE", "Load targets from config failed: %v", err)
 	}
 
+	// 远程探测目标
+	if err := s.mm.SetProbes(s.appConfig.Probes); err != nil {
+		logger.Errorf("SERVICE", "Set probes from config failed: %v", err)
+	}
+
 	// 恢复目标变化回调
 	s.mm.SetTargetChangeCallback(func(targets []types.MonitorTarget) {
 		s.saveTargetsToConfig(targets)
@@ -128,14 +222,32 @@ func (s *Service) Start() error {
 	// 启动 HTTP 服务器（如果启用）
 	if s.appConfig.Server.Enabled {
 		webSrv := server.NewWebServerWithConfig(s.mm, server.AuthConfig{}, s.appConfig, s.config.ConfigFile)
+		s.webServer = webSrv
+		timeouts := s.appConfig.Server.Timeouts
 		s.httpServer = &http.Server{
-			Addr:    s.config.Addr,
-			Handler: webSrv,
+			Addr:              s.config.Addr,
+			Handler:           webSrv,
+			ReadHeaderTimeout: seconds(timeouts.ReadHeaderSeconds),
+			ReadTimeout:       seconds(timeouts.ReadSeconds),
+			WriteTimeout:      seconds(timeouts.WriteSeconds),
+			IdleTimeout:       seconds(timeouts.IdleSeconds),
+			ConnContext:       server.ConnContext, // 流式接口据此单独设置读写期限
 		}
 
 		go func() {
+			// TCP keepalive 发现网络半关闭后残留的死连接，0 时使用系统默认间隔
+			lc := net.ListenConfig{KeepAlive: seconds(timeouts.KeepAliveSeconds)}
+			addr := s.config.Addr
+			if addr == "" {
+				addr = ":http"
+			}
+			ln, err := lc.Listen(s.ctx, "tcp", addr)
+			if err != nil {
+				logger.Errorf("SERVICE", "HTTP server error: %v", err)
+				return
+			}
 			logger.Infof("SERVICE", "HTTP server listening on %s", s.config.Addr)
-			if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			if err := s.httpServer.Serve(ln); err != nil && err != http.ErrServerClosed {
 				logger.Errorf("SERVICE", "HTTP server error: %v", err)
 			}
 		}()
@@ -143,6 +255,34 @@ func (s *Service) Start() error {
 		logger.Info("SERVICE", "HTTP server disabled")
 	}
 
+	// 指标推送（配置无效时只记录错误，不影响监控）
+	if in := s.appConfig.Influx; in.Enabled {
+		exp, err := influx.New(influx.Options{
+			URL:         in.URL,
+			Token:       in.Token,
+			Interval:    in.Interval,
+			Measurement: in.Measurement,
+			Tags:        in.Tags,
+			Backlog:     in.Backlog,
+		}, s.mm.GetSystemMetrics)
+		if err != nil {
+			logger.Errorf("SERVICE", "Influx exporter disabled: %v", err)
+		} else {
+			s.mm.SetCollectCallback(exp.Observe)
+			go exp.Run(s.ctx)
+		}
+	}
+
+	// 按保留策略定期清理日志目录（日志由调用方的日志实现输出时不清理）
+	if s.config.Logger == nil {
+		go s.runLogJanitor()
+	}
+
+	// 配置文件在运行中被手工修改时告警（不保存配置时不检查）
+	if s.config.ConfigFile != "" {
+		go s.runConfigDriftCheck()
+	}
+
 	logger.Info("SERVICE", "Service started successfully")
 	return nil
 }
@@ -151,20 +291,25 @@ func (s *Service) Start() error {
 func (s *Service) Stop() error {
 	logger.Info("SERVICE", "Stopping monitor service...")
 
-	// 停止监控
-	s.mm.Stop()
-
-	// 关闭 HTTP 服务器
+	// 先关闭 HTTP 服务器：进行中的请求仍会调用监控器和 provider（如 /api/monitor/start 重新启动监控），
+	// 请求结束后再停止监控和 provider 的后台采样（系统指标、网络监控），反复创建和停止服务时不遗留协程
 	if s.httpServer != nil {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 		if err := s.httpServer.Shutdown(ctx); err != nil {
 			logger.Errorf("SERVICE", "HTTP server shutdown error: %v", err)
 		}
+		s.webServer.Close()
 	}
 
+	s.mm.Stop()
+	s.prov.Close()
+
 	s.cancel()
 	logger.Info("SERVICE", "Service stopped")
+	if fwd := logger.SetSyslogForwarder(nil); fwd != nil {
+		fwd.Close()
+	}
 	logger.Close() // 关闭日志器
 	return nil
 }
@@ -201,46 +346,143 @@ func (s *Service) loadTargetsFromConfig() error {
 		nameToProcs[p.Name] = append(nameToProcs[p.Name], *p)
 	}
 
-	// 添加监控目标
+	// 先把配置项解析到进程再添加：按名称解析时优先使用尚未被其他配置项占用的同名进程，
+	// 多个配置项指向同一进程时合并为一个目标，避免同一进程产生重复的影响事件
+	claimed := make(map[int32]int) // PID -> resolved 中的下标
+	var resolved []types.MonitorTarget
 	for _, target := range s.appConfig.Targets {
-		// 如果指定了 PID，直接使用
-		if target.PID > 0 {
-			if err := s.mm.AddTarget(target); err != nil {
-				logger.Errorf("SERVICE", "Add target PID %d failed: %v", target.PID, err)
-			} else {
-				logger.Infof("SERVICE", "Added target: %s (PID %d)", target.Name, target.PID)
+		if target.PID <= 0 {
+			if target.Name == "" {
+				logger.Warn("SERVICE", "Skip target: no PID or name specified")
+				continue
 			}
-			continue
+			procs := nameToProcs[target.Name]
+			if len(procs) == 0 {
+				logger.Warnf("SERVICE", "Process '%s' not found", target.Name)
+				continue
+			}
+			proc := procs[0]
+			for _, p := range procs {
+				if _, used := claimed[p.PID]; !used {
+					proc = p
+					break
+				}
+			}
+			if len(procs) > 1 {
+				logger.Infof("SERVICE", "Multiple processes found for '%s', using PID %d", target.Name, proc.PID)
+			}
+			target.PID = proc.PID
+			target.Cmdline = proc.Cmdline
 		}
 
-		// 按进程名查找
-		if target.Name == "" {
-			logger.Warn("SERVICE", "Skip target: no PID or name specified")
+		if i, dup := claimed[target.PID]; dup {
+			logger.Warnf("SERVICE", "Targets '%s' and '%s' resolve to the same process (PID %d), merged",
+				targetLabel(resolved[i]), targetLabel(target), target.PID)
+			resolved[i] = mergeTargets(resolved[i], target)
 			continue
 		}
+		claimed[target.PID] = len(resolved)
+		resolved = append(resolved, target)
+	}
 
-		procs, found := nameToProcs[target.Name]
-		if !found || len(procs) == 0 {
-			logger.Warnf("SERVICE", "Process '%s' not found", target.Name)
-			continue
+	// 配置中剩下的同名目标指向不同进程，是有意监控的多个实例
+	for _, target := range resolved {
+		if err := s.mm.ForceAddTarget(target); err != nil {
+			logger.Errorf("SERVICE", "Add target '%s' (PID %d) failed: %v", target.Name, target.PID, err)
+		} else {
+			logger.Infof("SERVICE", "Added target: %s (PID %d)", target.Name, target.PID)
 		}
+	}
 
-		if len(procs) > 1 {
-			logger.Infof("SERVICE", "Multiple processes found for '%s', using first one (PID %d)",
-				target.Name, procs[0].PID)
+	return nil
+}
+
+// targetLabel 日志中的目标名称（有别名时附带别名）
+func targetLabel(t types.MonitorTarget) string {
+	if t.Alias != "" {
+		return t.Name + " (" + t.Alias + ")"
+	}
+	return t.Name
+}
+
+// mergeTargets 合并指向同一进程的两个配置项：以先出现的 a 为准，合并监控端口、文件和目录，a 未设置的项取 b 的值
+func mergeTargets(a, b types.MonitorTarget) types.MonitorTarget {
+	seenPort := make(map[int]bool)
+	for _, p := range a.WatchPorts {
+		seenPort[p] = true
+	}
+	for _, p := range b.WatchPorts {
+		if !seenPort[p] {
+			seenPort[p] = true
+			a.WatchPorts = append(a.WatchPorts, p)
+		}
+	}
+	seenFile := make(map[string]bool)
+	for _, f := range a.WatchFiles {
+		seenFile[f] = true
+	}
+	for _, f := range b.WatchFiles {
+		if !seenFile[f] {
+			seenFile[f] = true
+			a.WatchFiles = append(a.WatchFiles, f)
 		}
+	}
+	seenDir := make(map[string]bool)
+	for _, d := range a.WatchDirs {
+		seenDir[d] = true
+	}
+	for _, d := range b.WatchDirs {
+		if !seenDir[d] {
+			seenDir[d] = true
+			a.WatchDirs = append(a.WatchDirs, d)
+		}
+	}
+	if a.Alias == "" {
+		a.Alias = b.Alias
+	}
+	if a.Group == "" {
+		a.Group = b.Group
+	}
+	if a.SampleInterval == 0 {
+		a.SampleInterval = b.SampleInterval
+	}
+	if a.Thresholds == nil {
+		a.Thresholds = b.Thresholds
+	}
+	if a.ExpectedPriority == nil {
+		a.ExpectedPriority = b.ExpectedPriority
+	}
+	if a.Notes == "" {
+		a.Notes = b.Notes
+	}
+	if a.RunbookURL == "" {
+		a.RunbookURL = b.RunbookURL
+	}
+	if a.RestartCommand == "" && a.RestartPolicy == nil {
+		a.RestartCommand, a.RestartPolicy = b.RestartCommand, b.RestartPolicy
+	}
+	return a
+}
 
-		// 使用找到的第一个进程
-		target.PID = procs[0].PID
-		target.Cmdline = procs[0].Cmdline
-		if err := s.mm.AddTarget(target); err != nil {
-			logger.Errorf("SERVICE", "Add target '%s' failed: %v", target.Name, err)
-		} else {
-			logger.Infof("SERVICE", "Added target: %s (PID %d)", target.Name, target.PID)
+// runSelfTest 启动自检，逐项记录日志：WARN 项记为警告，FAIL 项记为错误
+func (s *Service) runSelfTest() {
+	s.selfTest = selftest.Run(s.config.LogDir)
+	for _, c := range s.selfTest.Checks {
+		switch c.Status {
+		case selftest.StatusFail:
+			logger.Errorf("SELFTEST", "%-5s %s: %s", c.Status, c.Name, c.Detail)
+		case selftest.StatusWarn:
+			logger.Warnf("SELFTEST", "%-5s %s: %s", c.Status, c.Name, c.Detail)
+		default:
+			logger.Infof("SELFTEST", "%-5s %s: %s", c.Status, c.Name, c.Detail)
 		}
 	}
+	logger.Infof("SELFTEST", "Self-test finished: %s", s.selfTest.Summary())
+}
 
-	return nil
+// SelfTest 返回启动自检结果
+func (s *Service) SelfTest() selftest.Report {
+	return s.selfTest
 }
 
 // saveTargetsToConfig 保存监控目标到配置文件
@@ -259,3 +501,8 @@ func (s *Service) saveTargetsToConfig(targets []types.MonitorTarget) {
 		logger.Infof("SERVICE", "Saved %d targets to config", len(targets))
 	}
 }
+
+// seconds 秒数转换为时长，0 表示不限制
+func seconds(n int) time.Duration {
+	return time.Duration(n) * time.Second
+}
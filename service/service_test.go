@@ -0,0 +1,51 @@
+package service
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"monitor-agent/config"
+)
+
+// nopSink 丢弃所有日志
+type nopSink struct{}
+
+func (nopSink) Log(level, category, message string, data interface{}) {}
+
+// 反复创建、启动和停止服务：Stop 之后的协程数回到第一次启动之前的水平
+// （provider 的系统指标采样和网络监控、监控和分析协程、Web 会话清理都已退出）
+func TestStartStopDoesNotLeakGoroutines(t *testing.T) {
+	dir := t.TempDir()
+	cycle := func() {
+		cfg := config.DefaultConfig()
+		cfg.Server.Enabled = true
+		cfg.Server.AutoStart = true
+		s, err := NewWithConfig(Config{Addr: "127.0.0.1:0", LogDir: dir, Logger: nopSink{}}, cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Start(); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(50 * time.Millisecond)
+		if err := s.Stop(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	base := runtime.NumGoroutine()
+	for i := 0; i < 10; i++ {
+		cycle()
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > base {
+		if time.Now().After(deadline) {
+			buf := make([]byte, 1<<20)
+			t.Fatalf("goroutines = %d after Stop, want <= %d before Start\n%s",
+				runtime.NumGoroutine(), base, buf[:runtime.Stack(buf, true)])
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
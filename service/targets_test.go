@@ -0,0 +1,69 @@
+package service
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"monitor-agent/config"
+	"monitor-agent/monitor"
+	"monitor-agent/provider"
+	"monitor-agent/types"
+)
+
+// 启动时按名称解析的配置项优先使用尚未被占用的同名进程，指向同一进程的配置项合并为一个目标
+func TestLoadTargetsMergesEntriesForSameProcess(t *testing.T) {
+	prov := provider.NewFake(nil)
+	prov.SetProcesses([]types.ProcessInfo{
+		{PID: 100, Name: "nginx", Cmdline: "nginx: worker process"},
+		{PID: 101, Name: "nginx", Cmdline: "nginx: worker process"},
+		{PID: 200, Name: "scada", Cmdline: "/opt/scada/bin/scada"},
+	})
+	mm, err := monitor.NewMultiMonitor(types.MultiMonitorConfig{SampleInterval: 1, LogDir: t.TempDir()}, prov)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.DefaultConfig()
+	cfg.Targets = []types.MonitorTarget{
+		{Name: "nginx"},
+		{Name: "nginx", Alias: "worker-2"},
+		{Name: "scada", WatchPorts: []int{8080}},
+		{PID: 200, Name: "scada", Alias: "main", WatchPorts: []int{8080, 502}},
+		{Name: "historian"}, // 进程不存在
+	}
+	s := &Service{appConfig: cfg, mm: mm}
+	if err := s.loadTargetsFromConfig(); err != nil {
+		t.Fatal(err)
+	}
+
+	targets := mm.GetTargets()
+	sort.Slice(targets, func(i, j int) bool { return targets[i].PID < targets[j].PID })
+	var pids []int32
+	for _, target := range targets {
+		pids = append(pids, target.PID)
+	}
+	if want := []int32{100, 101, 200}; !reflect.DeepEqual(pids, want) {
+		t.Fatalf("target PIDs = %v, want %v", pids, want)
+	}
+	if targets[1].Alias != "worker-2" {
+		t.Fatalf("second nginx entry = %+v, want alias worker-2 on PID 101", targets[1])
+	}
+	scada := targets[2]
+	if scada.Alias != "main" || !reflect.DeepEqual(scada.WatchPorts, []int{8080, 502}) {
+		t.Fatalf("merged scada target = %+v, want alias main and ports 8080, 502", scada)
+	}
+}
+
+func TestMergeTargets(t *testing.T) {
+	a := types.MonitorTarget{PID: 1, Name: "scada", WatchPorts: []int{80}, WatchFiles: []string{"/a"}, Notes: "first"}
+	b := types.MonitorTarget{PID: 1, Name: "scada", Alias: "main", WatchPorts: []int{80, 443}, WatchFiles: []string{"/a", "/b"}, Notes: "second", RunbookURL: "http://wiki/scada"}
+	got := mergeTargets(a, b)
+	want := types.MonitorTarget{
+		PID: 1, Name: "scada", Alias: "main",
+		WatchPorts: []int{80, 443}, WatchFiles: []string{"/a", "/b"},
+		Notes: "first", RunbookURL: "http://wiki/scada",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("mergeTargets = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,74 @@
+// Package timefmt 统一日志、报告和事件显示使用的时区与时间格式
+package timefmt
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	mu     sync.RWMutex
+	loc    = time.Local
+	zone   string // 配置的时区名，空表示本地时区
+	layout string // 配置的完整时间格式，空表示各处使用自己的默认格式
+)
+
+// Configure 设置时区（IANA 名称，如 "Asia/Shanghai"）和完整时间格式（Go 时间格式，如 "2006-01-02 15:04:05 MST"）
+// 时区名无效时回退为本地时区并返回错误，时间格式仍然生效
+func Configure(timeZone, timeFormat string) error {
+	var err error
+	l := time.Local
+	if timeZone != "" {
+		if l, err = time.LoadLocation(timeZone); err != nil {
+			l = time.Local
+			err = fmt.Errorf("invalid time zone %q, using local time: %w", timeZone, err)
+			timeZone = ""
+		}
+	}
+
+	mu.Lock()
+	loc = l
+	zone = timeZone
+	layout = timeFormat
+	mu.Unlock()
+	return err
+}
+
+// Location 当前使用的时区
+func Location() *time.Location {
+	mu.RLock()
+	defer mu.RUnlock()
+	return loc
+}
+
+// ZoneName 配置的时区名，使用本地时区时返回 "Local"
+func ZoneName() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	if zone == "" {
+		return "Local"
+	}
+	return zone
+}
+
+// Now 当前时区的当前时间
+func Now() time.Time {
+	return time.Now().In(Location())
+}
+
+// In 转换到当前时区
+func In(t time.Time) time.Time {
+	return t.In(Location())
+}
+
+// Format 按当前时区格式化完整时间，配置了时间格式时使用配置，否则使用 fallback
+func Format(t time.Time, fallback string) string {
+	mu.RLock()
+	l, f := loc, layout
+	mu.RUnlock()
+	if f == "" {
+		f = fallback
+	}
+	return t.In(l).Format(f)
+}
@@ -9,16 +9,253 @@ type ProcessMetrics struct {
 	Name      string    `json:"name"`
 	CPUPct    float64   `json:"cpu_pct"`
 	RSSBytes  uint64    `json:"rss_bytes"`
+	Priority  int32     `json:"priority"` // 进程优先级（Linux 为 20-nice）
+	Nice      int32     `json:"nice"`     // Nice 值 (Linux)
 	Alive     bool      `json:"alive"`
+
+	// IntervalMS 本次采样的间隔（毫秒）：目标的采样间隔、全局采样间隔或聚焦采样间隔，绘图时按此换算时间轴
+	IntervalMS int64 `json:"interval_ms,omitempty"`
+
+	// 调度等待（Linux，各线程之和，单核 100%，多线程可超过 100%），内核不提供时为 nil：
+	// RunWaitPct 可运行但在等待 CPU 的时间占比（schedstat），BlockedPct 等待块设备 IO 的时间占比（delayacct）
+	RunWaitPct *float64 `json:"run_wait_pct,omitempty"`
+	BlockedPct *float64 `json:"blocked_pct,omitempty"`
 }
 
 // Event 事件记录
 type Event struct {
+	ID        string    `json:"id"`  // 事件标识，合并重复事件时保持不变，用于关联值班备注
+	Seq       int64     `json:"seq"` // 单调递增序号（合并重复事件时更新为最新序号）
 	Timestamp time.Time `json:"timestamp"`
 	Type      string    `json:"type"` // "exit", "start", "new_process", "process_gone"
 	PID       int32     `json:"pid"`
 	Name      string    `json:"name"`
 	Message   string    `json:"message"`
+	Count     int       `json:"count,omitempty"` // 去重合并的发生次数（>1 表示多次重复）
+	LastSeen  time.Time `json:"last_seen"`       // 最后一次发生时间
+
+	Comments []Comment `json:"comments,omitempty"` // 值班备注
+}
+
+// Comment 值班人员对事件或影响事件的备注（如"已确认，厂家工单 #4411"）
+type Comment struct {
+	Time time.Time `json:"time"`
+	User string    `json:"user,omitempty"` // 登录用户
+	Text string    `json:"text"`
+}
+
+// TargetStatus 监控目标及其最新状态（用于总览）
+type TargetStatus struct {
+	MonitorTarget
+	Latest        *ProcessMetrics `json:"latest,omitempty"` // 最新指标
+	ActiveImpacts int             `json:"active_impacts"`   // 活跃影响事件数
+
+	Binary          *BinaryInfo `json:"binary,omitempty"`            // 可执行文件基线
+	BinaryCheckedAt *time.Time  `json:"binary_checked_at,omitempty"` // 上次完整性校验时间
+	Reexec          *ExecChange `json:"reexec,omitempty"`            // 最近一次 exec 为其他程序（PID 不变）
+	Dirs            []DirUsage  `json:"dirs,omitempty"`              // WatchDirs 的最近一次统计结果
+}
+
+// 分组状态
+const (
+	GroupUp       = "up"       // 成员全部运行
+	GroupDegraded = "degraded" // 部分成员停止
+	GroupDown     = "down"     // 成员全部停止
+)
+
+// TargetGroup 按 MonitorTarget.Group 汇总的一组监控目标
+type TargetGroup struct {
+	Name          string         `json:"name"`   // 分组名称，未分组的目标归入名称为空的组
+	Status        string         `json:"status"` // up、degraded、down
+	Total         int            `json:"total"`
+	Running       int            `json:"running"`
+	CPUPct        float64        `json:"cpu_pct"`        // 运行中成员的 CPU% 之和
+	RSSBytes      uint64         `json:"rss_bytes"`      // 运行中成员的内存之和
+	ActiveImpacts int            `json:"active_impacts"` // 成员的活跃影响事件数之和
+	Targets       []TargetStatus `json:"targets"`
+}
+
+// DirUsage 目标监控目录的一次统计结果
+type DirUsage struct {
+	Path       string    `json:"path"`
+	SizeBytes  uint64    `json:"size_bytes"`  // 目录下所有文件的大小之和
+	Files      int       `json:"files"`       // 文件数
+	FreeBytes  uint64    `json:"free_bytes"`  // 所在文件系统的剩余空间
+	TotalBytes uint64    `json:"total_bytes"` // 所在文件系统的总空间
+	ScannedAt  time.Time `json:"scanned_at"`
+	ScanMillis int64     `json:"scan_ms"` // 本次遍历耗时（含限速暂停）
+
+	// GrowthMBPerHour 按最近一小时的统计计算的增长速度（MB/小时），统计跨度不足 5 分钟时为 nil
+	GrowthMBPerHour *float64 `json:"growth_mb_per_hour,omitempty"`
+
+	Truncated bool   `json:"truncated,omitempty"` // 条目过多，只统计了一部分
+	Error     string `json:"error,omitempty"`     // 统计失败的原因（如目录不存在）
+}
+
+// ExecChange 目标进程 exec 为其他程序：PID 不变，可执行文件路径变化
+type ExecChange struct {
+	From string    `json:"from"` // 变化前的可执行文件路径
+	To   string    `json:"to"`
+	At   time.Time `json:"at"` // 发现时间
+}
+
+// BinaryInfo 目标可执行文件信息，用于检测程序文件被替换（原地升级或篡改）
+type BinaryInfo struct {
+	Path    string    `json:"path"`
+	Cwd     string    `json:"cwd,omitempty"` // 工作目录
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+	SHA256  string    `json:"sha256,omitempty"`  // 仅在开启 sampling.binary_hash 时计算
+	Missing bool      `json:"missing,omitempty"` // 文件已不存在
+}
+
+// LaunchSnapshot 目标启动环境快照，添加目标时记录，用于事后分析实例是如何启动的
+type LaunchSnapshot struct {
+	PID        int32             `json:"pid"`
+	Name       string            `json:"name"`
+	CapturedAt time.Time         `json:"captured_at"`
+	Cmdline    string            `json:"cmdline"`
+	Cwd        string            `json:"cwd"`
+	PPID       int32             `json:"ppid"`
+	StartTime  time.Time         `json:"start_time"`
+	Env        map[string]string `json:"env"`                 // 按 snapshot_env_allow/deny 过滤，命中 deny 的值替换为 [redacted]
+	EnvError   string            `json:"env_error,omitempty"` // 环境变量读取失败的原因（通常是权限不足）
+
+	// 同名目标上一个实例的快照（重新添加目标时保留，用于对比；不再向前嵌套）
+	Previous *LaunchSnapshot `json:"previous,omitempty"`
+}
+
+// TimelineItem 时间线条目（指标异常、事件、影响事件按时间合并）
+type TimelineItem struct {
+	Timestamp time.Time       `json:"timestamp"`
+	Kind      string          `json:"kind"`    // "anomaly" / "event" / "impact"
+	Summary   string          `json:"summary"` // 简要描述
+	Metric    *ProcessMetrics `json:"metric,omitempty"`
+	Event     *Event          `json:"event,omitempty"`
+	Impact    *ImpactEvent    `json:"impact,omitempty"`
+}
+
+// TimelinePage 时间线分页结果
+type TimelinePage struct {
+	PID        int32          `json:"pid"`
+	Total      int            `json:"total"`                 // 时间窗口内条目总数
+	Items      []TimelineItem `json:"items"`                 // 当前页条目（按时间升序）
+	NextCursor int            `json:"next_cursor,omitempty"` // 下一页游标，0 表示没有更多
+}
+
+// OutageInterval 停运区间
+type OutageInterval struct {
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`     // 进行中的停运为查询时刻
+	Ongoing  bool      `json:"ongoing"` // 是否仍在停运
+	Duration float64   `json:"duration_seconds"`
+}
+
+// AvailabilityReport 目标可用率统计
+// 代理自身未运行的时间计为 unknown，不计入停运时间，可用率只基于已知时间计算
+type AvailabilityReport struct {
+	PID            int32            `json:"pid"`
+	Name           string           `json:"name"`
+	Window         string           `json:"window"`
+	From           time.Time        `json:"from"`
+	To             time.Time        `json:"to"`
+	UptimePct      *float64         `json:"uptime_pct"` // 无已知数据时为 null
+	UpSeconds      float64          `json:"up_seconds"`
+	DownSeconds    float64          `json:"down_seconds"`
+	UnknownSeconds float64          `json:"unknown_seconds"`
+	Outages        []OutageInterval `json:"outages"`
+}
+
+// MaintenanceWindow 维护窗口，期间影响分析不对目标告警（指标照常采集）
+type MaintenanceWindow struct {
+	PID       int32     `json:"pid"` // 0 表示全局维护
+	Reason    string    `json:"reason"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Remaining float64   `json:"remaining_seconds,omitempty"` // 剩余秒数（仅查询结果）
+}
+
+// StreamMessage 实时推送消息（SSE）
+type StreamMessage struct {
+	Type string `json:"type"` // "event" / "process_change" / "impact"
+	Data any    `json:"data"`
+}
+
+// MemoryBreakdown 进程内存构成明细（用于排查内存增长的类型）
+// Linux 附带 /proc 统计的匿名/文件映射/共享内存和最大的映射区；Windows 附带工作集、私有字节和页面文件用量。
+// 权限不足无法读取明细时 Detailed 为 false，只返回基础内存信息，原因见 DetailError
+type MemoryBreakdown struct {
+	PID         int32     `json:"pid"`
+	Name        string    `json:"name"`
+	Timestamp   time.Time `json:"timestamp"` // 明细读取时间（短时间内重复查询返回缓存）
+	RSSBytes    uint64    `json:"rss_bytes"`
+	VMSBytes    uint64    `json:"vms_bytes"`
+	SwapBytes   uint64    `json:"swap_bytes"`
+	SharedBytes uint64    `json:"shared_bytes"`
+	Detailed    bool      `json:"detailed"`
+	DetailError string    `json:"detail_error,omitempty"`
+
+	// Linux
+	AnonBytes   uint64          `json:"anon_bytes,omitempty"`  // 匿名内存（堆、栈等）
+	FileBytes   uint64          `json:"file_bytes,omitempty"`  // 文件映射（程序、动态库、mmap 文件）
+	ShmemBytes  uint64          `json:"shmem_bytes,omitempty"` // 共享内存（tmpfs、SysV/POSIX 共享内存）
+	PSSBytes    uint64          `json:"pss_bytes,omitempty"`   // 按共享进程数均摊后的驻留内存
+	TopMappings []MemoryMapping `json:"top_mappings,omitempty"`
+
+	// Windows
+	WorkingSetBytes     uint64 `json:"working_set_bytes,omitempty"`
+	PeakWorkingSetBytes uint64 `json:"peak_working_set_bytes,omitempty"`
+	PrivateBytes        uint64 `json:"private_bytes,omitempty"`
+	PagefileBytes       uint64 `json:"pagefile_bytes,omitempty"`
+	PeakPagefileBytes   uint64 `json:"peak_pagefile_bytes,omitempty"`
+	PagedPoolBytes      uint64 `json:"paged_pool_bytes,omitempty"`
+	NonPagedPoolBytes   uint64 `json:"non_paged_pool_bytes,omitempty"`
+}
+
+// OpenFilesReport 监控目标当前打开的文件、套接字和管道（用于排查句柄泄漏），附带与上次查询的差异。
+// 权限不足等原因无法完整读取时 Complete 为 false，原因见 Note
+type OpenFilesReport struct {
+	PID       int32           `json:"pid"`
+	Name      string          `json:"name"`
+	Timestamp time.Time       `json:"timestamp"`
+	Total     int             `json:"total"`     // 读取到的句柄数
+	ByType    map[string]int  `json:"by_type"`   // 类型 -> 句柄数
+	Dirs      []OpenFilesDir  `json:"dirs"`      // 按目录分组计数（套接字、管道等按类型分组），按句柄数降序
+	Files     []OpenFileEntry `json:"files"`     // 按路径去重，按句柄数降序
+	Truncated bool            `json:"truncated"` // dirs/files 超过上限已截断
+	Complete  bool            `json:"complete"`
+	Note      string          `json:"note,omitempty"`
+
+	// 与上次查询的差异，首次查询（或目标重启后）Previous 为空
+	Previous      *time.Time      `json:"previous,omitempty"`
+	Added         []OpenFileEntry `json:"added,omitempty"`          // 新打开的路径
+	Removed       []OpenFileEntry `json:"removed,omitempty"`        // 已关闭的路径
+	DiffTruncated bool            `json:"diff_truncated,omitempty"` // added/removed 超过上限已截断
+}
+
+// OpenFileEntry 进程打开的一个路径（同一路径可能被打开多次）
+type OpenFileEntry struct {
+	Path  string `json:"path"` // 套接字、管道为内核标识，如 socket:[12345]
+	Type  string `json:"type"` // file / socket / pipe / device / other
+	Count int    `json:"count"`
+}
+
+// OpenFilesDir 打开文件按目录分组的计数
+type OpenFilesDir struct {
+	Dir   string `json:"dir"`
+	Type  string `json:"type"`
+	Count int    `json:"count"`
+}
+
+// MemoryMapping 进程内存映射区（Linux smaps）
+type MemoryMapping struct {
+	Address   string `json:"address"` // 起止地址，如 7f0c2a000000-7f0c2a021000
+	Perms     string `json:"perms"`
+	Path      string `json:"path"` // 映射文件或 [heap]、[stack]，匿名映射为空
+	Kind      string `json:"kind"` // anon / file / shmem
+	SizeBytes uint64 `json:"size_bytes"`
+	RSSBytes  uint64 `json:"rss_bytes"`
+	SwapBytes uint64 `json:"swap_bytes"`
 }
 
 // ProcessChange 进程变化记录
@@ -33,10 +270,13 @@ type ProcessChange struct {
 // ProcessInfo 系统进程信息（用于列表展示）
 type ProcessInfo struct {
 	PID           int32   `json:"pid"`
-	Name          string  `json:"name"`
+	PPID          int32   `json:"ppid"`                   // 父进程 PID
+	Name          string  `json:"name"`                   // 进程名，用于按名称匹配
+	DisplayName   string  `json:"display_name,omitempty"` // 显示名称（display.name_aliases 中配置），未配置时为空
 	CPUPct        float64 `json:"cpu_pct"`
 	RSSBytes      uint64  `json:"rss_bytes"`
 	RSSGrowthRate float64 `json:"rss_growth_rate"` // RSS 增长速率 (B/s)
+	FDGrowthRate  float64 `json:"fd_growth_rate"`  // 句柄数/文件描述符数增长速率（个/分钟），按至少 1 分钟的间隔计算
 	VMS           uint64  `json:"vms"`             // 虚拟内存大小
 	Status        string  `json:"status"`
 	Username      string  `json:"username"`        // 发布者/用户
@@ -56,6 +296,15 @@ type ProcessInfo struct {
 	Description   string  `json:"description"`     // 文件描述（来自可执行文件版本信息）
 	OpenFiles     int     `json:"open_files"`      // 打开的文件数
 	ListenPorts   []int   `json:"listen_ports"`    // 监听的端口列表
+	CPUAffinity   []int   `json:"cpu_affinity"`    // 允许运行的 CPU 核心，平台不支持或读取失败时为空
+
+	// 权限不足导致读取失败的字段（如非 root 运行时读取其他用户进程的 disk_io/exe/fds），这些字段显示为 0
+	Restricted       bool     `json:"restricted,omitempty"`
+	RestrictedFields []string `json:"restricted_fields,omitempty"`
+
+	// 匿名映射的虚拟内存（不含文件映射和共享内存），只在风险分析启用 exclude_mapped_vms 时
+	// 为虚拟内存超过阈值的进程读取，0 表示未读取
+	AnonVMS uint64 `json:"anon_vms,omitempty"`
 }
 
 // MonitorTarget 监控目标
@@ -63,9 +312,78 @@ type MonitorTarget struct {
 	PID        int32    `json:"pid"`
 	Name       string   `json:"name"`            // 进程名
 	Alias      string   `json:"alias,omitempty"` // 备注名称（如：电力监控主进程）
+	Group      string   `json:"group,omitempty"` // 所属分组（如：SCADA），同一系统的多个进程在总览中合并显示
 	Cmdline    string   `json:"cmdline,omitempty"`
 	WatchFiles []string `json:"watch_files,omitempty"` // 需要监控的关键文件路径
 	WatchPorts []int    `json:"watch_ports,omitempty"` // 需要监控的端口列表
+	WatchDirs  []string `json:"watch_dirs,omitempty"`  // 需要统计占用的目录（如目标自己的数据、日志目录）
+
+	// SampleInterval 目标的采样间隔（秒），0 表示使用全局 sampling.interval
+	SampleInterval int `json:"sample_interval,omitempty"`
+
+	// Thresholds 目标自定义阈值，未设置的字段使用全局影响分析配置
+	Thresholds *ThresholdOverrides `json:"thresholds,omitempty"`
+
+	// ExpectedPriority 期望的进程优先级（与 ProcessInfo.Priority 同口径），实际值偏离时产生 priority 影响事件
+	ExpectedPriority *int32 `json:"expected_priority,omitempty"`
+
+	// Notes 处置说明（该目标异常时值班人员应如何处理），RunbookURL 运行手册链接，
+	// 两者会附加到该目标的影响事件处理建议中
+	Notes      string `json:"notes,omitempty"`
+	RunbookURL string `json:"runbook_url,omitempty"`
+
+	// RestartCommand 目标退出后用于重新启动的命令（Linux 由 sh -c、Windows 由 cmd /C 执行），
+	// RestartPolicy 自动重启策略；仅在全局 watchdog.allow_restart 开启时生效
+	RestartCommand string         `json:"restart_command,omitempty"`
+	RestartPolicy  *RestartPolicy `json:"restart_policy,omitempty"`
+}
+
+// 自动重启策略
+const (
+	RestartNever     = "never"      // 不重启（默认）
+	RestartOnFailure = "on-failure" // 非计划退出时重启，维护窗口内的退出不重启
+	RestartAlways    = "always"     // 任何退出都重启，包括维护窗口内
+)
+
+// RestartPolicy 目标退出后的自动重启策略
+type RestartPolicy struct {
+	Mode        string `json:"mode"`                   // never、on-failure、always
+	MaxAttempts int    `json:"max_attempts,omitempty"` // 连续重启的最多次数，0 表示默认 3 次
+	BackoffSec  int    `json:"backoff_sec,omitempty"`  // 第二次尝试前的等待（秒），之后每次翻倍，0 表示默认 10 秒
+}
+
+// ProcessTargetEntry 目标列表中的进程目标（kind 为 "process"）
+type ProcessTargetEntry struct {
+	Kind string `json:"kind"`
+	MonitorTarget
+}
+
+// ProbeConfig 远程探测目标：无法安装本程序的设备（如嵌入式控制器），定期 TCP 连接指定端口判断存活
+type ProbeConfig struct {
+	Name          string `json:"name"`
+	Host          string `json:"host"`
+	Port          int    `json:"port"`
+	Interval      int    `json:"interval,omitempty"`       // 探测间隔（秒），默认10
+	TimeoutMs     int    `json:"timeout_ms,omitempty"`     // 连接超时（毫秒），默认2000
+	FailThreshold int    `json:"fail_threshold,omitempty"` // 连续失败该次数判定为不可达，默认3
+}
+
+// ProbeResult 一次探测结果
+type ProbeResult struct {
+	Timestamp time.Time `json:"timestamp"`
+	Up        bool      `json:"up"`              // 本次是否连通
+	RTTMs     float64   `json:"rtt_ms"`          // 建立连接耗时（毫秒），失败时为 0
+	Error     string    `json:"error,omitempty"` // 失败原因
+}
+
+// ProbeStatus 远程探测目标及其状态（目标列表中 kind 为 "probe"）
+type ProbeStatus struct {
+	Kind string `json:"kind"`
+	ProbeConfig
+	State    string       `json:"state"`           // unknown（尚未判定）、up、down
+	Since    *time.Time   `json:"since,omitempty"` // 进入当前状态的时间
+	Failures int          `json:"failures"`        // 连续失败次数
+	Last     *ProbeResult `json:"last,omitempty"`  // 最近一次探测结果
 }
 
 // MultiMonitorConfig 多进程监控配置
@@ -74,7 +392,20 @@ type MultiMonitorConfig struct {
 	SampleInterval   int             `json:"sample_interval"` // 采样间隔（秒）
 	MetricsBufferLen int             `json:"metrics_buffer_len"`
 	EventsBufferLen  int             `json:"events_buffer_len"`
+	EventDedupWindow int             `json:"event_dedup_window"` // 事件去重窗口（秒），0 表示不去重
+	EventRateLimit   int             `json:"event_rate_limit"`   // 每分钟最多记录事件数，0 表示不限制
 	LogDir           string          `json:"log_dir"`
+
+	SampleJitterMS       int  `json:"sample_jitter_ms"`        // 采样时刻随机偏移的上限（毫秒），0 表示不偏移
+	SampleJitterEachTick bool `json:"sample_jitter_each_tick"` // 每轮采样都偏移，false 时只偏移首次采样
+
+	BinaryCheckInterval int  `json:"binary_check_interval"` // 可执行文件完整性校验间隔（秒）
+	BinaryHash          bool `json:"binary_hash"`           // 校验时计算 SHA256（大文件较耗时）
+
+	SnapshotEnvAllow []string `json:"snapshot_env_allow"` // 启动快照记录的环境变量（通配符），为空表示全部
+	SnapshotEnvDeny  []string `json:"snapshot_env_deny"`  // 启动快照中隐藏值的环境变量（通配符，优先于 allow）
+
+	AllowRestart bool `json:"allow_restart"` // 是否允许按目标的 restart_policy 自动重启退出的目标
 }
 
 // SystemMetrics 系统指标
@@ -84,8 +415,11 @@ type SystemMetrics struct {
 	CPUUser    float64 `json:"cpu_user"`   // 用户态 CPU%
 	CPUSystem  float64 `json:"cpu_system"` // 内核态 CPU%
 	CPUIowait  float64 `json:"cpu_iowait"` // IO 等待 CPU%
+	CPUSteal   float64 `json:"cpu_steal"`  // 被宿主机抢占的 CPU%（Linux 虚拟机，物理机和 Windows 为 0）
 	CPUIdle    float64 `json:"cpu_idle"`   // 空闲 CPU%
 
+	CPUPerCore []float64 `json:"cpu_per_core,omitempty"` // 各逻辑核心 CPU%
+
 	// 负载指标 (Linux)
 	LoadAvg1  float64 `json:"load_avg_1"`  // 1 分钟负载
 	LoadAvg5  float64 `json:"load_avg_5"`  // 5 分钟负载
@@ -119,20 +453,153 @@ type SystemMetrics struct {
 	// 系统统计
 	ProcessCount int `json:"process_count"` // 进程总数
 	ThreadCount  int `json:"thread_count"`  // 线程总数
+
+	// 进程启停频率（基于进程列表采样，采样间隔内启动又退出的进程无法统计）
+	ProcessChurnRate int    `json:"process_churn_rate"`          // 最近一分钟新建+退出进程数
+	ProcessChurnTop  string `json:"process_churn_top,omitempty"` // 最近一分钟新建次数最多的进程名
+
+	// 读取失败的子系统（cpu、memory、swap、load、network、disk），对应字段为 0 且不可信，显示为 N/A
+	Degraded []string `json:"degraded,omitempty"`
+}
+
+// 系统指标的子系统名称，用于 SystemMetrics.Degraded
+const (
+	SubsystemCPU     = "cpu"
+	SubsystemMemory  = "memory"
+	SubsystemSwap    = "swap"
+	SubsystemLoad    = "load"
+	SubsystemNetwork = "network"
+	SubsystemDisk    = "disk"
+)
+
+// IsDegraded 判断子系统的指标本次是否读取失败
+func (m *SystemMetrics) IsDegraded(subsystem string) bool {
+	for _, s := range m.Degraded {
+		if s == subsystem {
+			return true
+		}
+	}
+	return false
+}
+
+// ProcessChurn 最近一分钟的进程启停统计
+type ProcessChurn struct {
+	PerMinute int    // 新建+退出进程数
+	TopName   string // 新建次数最多的进程名
+	TopCount  int    // 该进程名的新建次数
 }
 
 // ImpactEvent 影响事件
 type ImpactEvent struct {
+	ID          string        `json:"id"`         // 事件标识，同一活跃影响的后续检测保持不变
+	FirstSeen   time.Time     `json:"first_seen"` // 首次成为正式影响的时间
 	Timestamp   time.Time     `json:"timestamp"`
-	TargetPID   int32         `json:"target_pid"`  // 被影响的监控目标 PID
-	TargetName  string        `json:"target_name"` // 被影响的监控目标名称
-	ImpactType  string        `json:"impact_type"` // cpu/memory/disk_io/network/file/port
-	Severity    string        `json:"severity"`    // low/medium/high/critical
-	SourcePID   int32         `json:"source_pid"`  // 影响源进程 PID
-	SourceName  string        `json:"source_name"` // 影响源进程名
-	Description string        `json:"description"` // 影响描述
-	Metrics     ImpactMetrics `json:"metrics"`     // 相关指标
-	Suggestion  string        `json:"suggestion"`  // 处理建议
+	TargetPID   int32         `json:"target_pid"`             // 被影响的监控目标 PID
+	TargetName  string        `json:"target_name"`            // 被影响的监控目标名称
+	ImpactType  string        `json:"impact_type"`            // cpu/memory/disk_io/network/file/port
+	Severity    string        `json:"severity"`               // low/medium/high/critical
+	SourcePID   int32         `json:"source_pid"`             // 影响源进程 PID
+	SourceName  string        `json:"source_name"`            // 影响源进程名
+	Description string        `json:"description"`            // 影响描述
+	Metrics     ImpactMetrics `json:"metrics"`                // 相关指标
+	Suggestion  string        `json:"suggestion"`             // 处理建议
+	Suppressed  bool          `json:"suppressed,omitempty"`   // 目标处于维护模式，不告警、不计入健康评分
+	TargetNotes string        `json:"target_notes,omitempty"` // 目标的处置说明
+	RunbookURL  string        `json:"runbook_url,omitempty"`  // 目标的运行手册链接
+
+	// 分组模式下同一目标、同一类型的多个影响源合并为一条时填写
+	Count        int                 `json:"count,omitempty"`        // 合并的影响事件数
+	Contributors []ImpactContributor `json:"contributors,omitempty"` // 影响最大的几个来源
+
+	// 检测时目标进程的指标历史，仅按 ID 查询单个事件时返回
+	History []ProcessMetrics `json:"history,omitempty"`
+
+	Comments []Comment `json:"comments,omitempty"` // 值班备注
+}
+
+// ImpactContributor 分组影响事件中的单个影响源
+type ImpactContributor struct {
+	ID          string `json:"id"`
+	SourcePID   int32  `json:"source_pid"`
+	SourceName  string `json:"source_name"`
+	Severity    string `json:"severity"`
+	Description string `json:"description"`
+}
+
+// ImpactOffender 影响源进程排行项，按进程名累计（进程重启后合并统计）
+type ImpactOffender struct {
+	Rank       int            `json:"rank"`
+	Name       string         `json:"name"`
+	Total      int            `json:"total"`
+	BySeverity map[string]int `json:"by_severity"` // critical/high/medium/low -> 次数
+	ByType     map[string]int `json:"by_type"`     // 影响类型 -> 次数
+	TopType    string         `json:"top_type"`    // 最常见的影响类型
+	LastSeen   time.Time      `json:"last_seen"`
+}
+
+// ImpactDiagnostics 影响分析器的运行诊断：最近一个分析周期的耗时、文件/端口检测的缓存状态和各项检测最近的错误，
+// 用于排查「冲突检测没有效果」是检测没有运行、缓存过期还是确实没有发现冲突
+type ImpactDiagnostics struct {
+	Enabled          bool       `json:"enabled"`
+	Running          bool       `json:"running"`
+	Cycles           int64      `json:"cycles"`             // 启动以来完成的分析周期数
+	AnalysisInterval int        `json:"analysis_interval"`  // 秒
+	LastRun          *time.Time `json:"last_run,omitempty"` // 最近一个分析周期的开始时间
+	LastDurationMs   float64    `json:"last_duration_ms"`
+	Processes        int        `json:"processes"`         // 最近一个周期扫描的进程数
+	AnalysisWorkers  int        `json:"analysis_workers"`  // 并发分析的协程数
+	SkippedCycles    int64      `json:"skipped_cycles"`    // 因上一周期未结束而跳过的周期数（启动以来）
+	ConsecutiveSkips int        `json:"consecutive_skips"` // 当前连续跳过的周期数
+	SlowCycles       int64      `json:"slow_cycles"`       // 耗时超过分析间隔的周期数
+
+	PortCheckInterval  int        `json:"port_check_interval"`
+	LastPortCheck      *time.Time `json:"last_port_check,omitempty"`
+	PortsRefreshedAt   *time.Time `json:"ports_refreshed_at,omitempty"` // 目标监听端口缓存的刷新时间（每 60 秒）
+	ConnectionsScanned int        `json:"connections_scanned"`          // 最近一次端口检测扫描的连接数
+
+	FileCheckInterval   int        `json:"file_check_interval"`
+	LastFileCheck       *time.Time `json:"last_file_check,omitempty"`
+	FilesRefreshedAt    *time.Time `json:"files_refreshed_at,omitempty"` // 目标打开文件缓存的刷新时间（每 60 秒）
+	OpenFilesIndexed    int        `json:"open_files_indexed"`           // 最近一次文件检测索引的文件路径数
+	OpenFilesUnreadable int        `json:"open_files_unreadable"`        // 无法读取打开文件的进程数（通常是权限不足）
+
+	Targets []ImpactTargetDiagnostics `json:"targets"`
+	// Errors 各项检测最近一次的错误（system/processes/port/file），该检测再次成功后清除
+	Errors map[string]ImpactCheckError `json:"errors"`
+}
+
+// ImpactTargetDiagnostics 单个目标的文件/端口检测缓存
+type ImpactTargetDiagnostics struct {
+	PID         int32  `json:"pid"`
+	Name        string `json:"name"`
+	ListenPorts int    `json:"listen_ports"` // 缓存的监听地址数（自动发现）
+	WatchPorts  int    `json:"watch_ports"`  // 配置的监控端口数
+	OpenFiles   int    `json:"open_files"`   // 缓存的打开文件数（自动发现）
+	WatchFiles  int    `json:"watch_files"`  // 配置的监控文件数
+}
+
+// ImpactCheckError 一项检测的错误
+type ImpactCheckError struct {
+	Error string    `json:"error"`
+	Time  time.Time `json:"time"`
+}
+
+// ImpactSimulation 阈值模拟结果：回放最近的分析周期，分别统计候选阈值和当前阈值会产生的影响事件
+type ImpactSimulation struct {
+	From      time.Time              `json:"from"`
+	To        time.Time              `json:"to"`
+	Cycles    int                    `json:"cycles"` // 回放的分析周期数
+	Candidate ImpactSimulationCounts `json:"candidate"`
+	Current   ImpactSimulationCounts `json:"current"`
+	Excluded  []string               `json:"excluded"` // 不参与回放的影响类型（依赖实时状态）
+}
+
+// ImpactSimulationCounts 一组阈值在回放区间内会产生的影响事件（按持续时间要求判定，同一影响持续期间只计一次）
+type ImpactSimulationCounts struct {
+	Total      int                       `json:"total"`
+	BySeverity map[string]int            `json:"by_severity"`
+	ByType     map[string]map[string]int `json:"by_type"`  // 影响类型 -> 严重级别 -> 次数
+	Examples   []ImpactEvent             `json:"examples"` // 最早产生的若干事件
 }
 
 // ImpactMetrics 影响相关指标
@@ -147,20 +614,77 @@ type ImpactMetrics struct {
 	SourceNetIO  float64 `json:"source_net_io"`           // 影响源网络IO
 	ConflictFile string  `json:"conflict_file,omitempty"` // 冲突文件路径
 	ConflictPort int     `json:"conflict_port,omitempty"` // 冲突端口
+
+	// 目标进程当时的 IO 和资源占用，用于事后判断影响是否确实波及目标（旧版本记录的事件中为 0）
+	TargetDiskIO     float64 `json:"target_disk_io"`     // 目标进程磁盘IO（读+写，B/s）
+	TargetNetIO      float64 `json:"target_net_io"`      // 目标进程网络IO（收+发，B/s）
+	TargetNumThreads int32   `json:"target_num_threads"` // 目标进程线程数
+	TargetNumFDs     int32   `json:"target_num_fds"`     // 目标进程句柄数/文件描述符数
+}
+
+// ThresholdProfile 阈值时段配置（如夜间批处理窗口放宽磁盘IO阈值）
+type ThresholdProfile struct {
+	Name string `json:"name"`
+	// Window 生效窗口，格式 "HH:MM-HH:MM [星期]"，星期字段与 cron 相同（0/7=周日，支持 * , -），
+	// 结束时间早于开始时间表示跨零点，此时星期按开始当天匹配。
+	// 例："01:00-04:00"、"20:00-08:00 1-5"、"00:00-24:00 0,6"
+	Window    string             `json:"window"`
+	Overrides ThresholdOverrides `json:"overrides"`
+}
+
+// MaintenanceSchedule 周期性的计划维护时段（如每晚备份、批处理），窗口格式与阈值时段相同
+type MaintenanceSchedule struct {
+	Name   string `json:"name"`
+	Window string `json:"window"`           // "HH:MM-HH:MM [星期]"
+	Reason string `json:"reason,omitempty"` // 记录在维护开始事件中
+}
+
+// ThresholdOverrides 时段内覆盖的阈值，未设置（null）的字段沿用基础配置
+type ThresholdOverrides struct {
+	CPUThreshold     *float64 `json:"cpu_threshold,omitempty"`
+	MemoryThreshold  *float64 `json:"memory_threshold,omitempty"`
+	DiskIOThreshold  *float64 `json:"disk_io_threshold,omitempty"`
+	NetworkThreshold *float64 `json:"network_threshold,omitempty"`
+	CPUCoreThreshold *float64 `json:"cpu_core_threshold,omitempty"`
+
+	ProcCPUThreshold       *float64 `json:"proc_cpu_threshold,omitempty"`
+	ProcMemoryThreshold    *float64 `json:"proc_memory_threshold,omitempty"`
+	ProcMemGrowthThreshold *float64 `json:"proc_mem_growth_threshold,omitempty"`
+	ProcVMSThreshold       *float64 `json:"proc_vms_threshold,omitempty"`
+	ProcFDsThreshold       *int     `json:"proc_fds_threshold,omitempty"`
+	ProcFDGrowthThreshold  *float64 `json:"proc_fd_growth_threshold,omitempty"`
+	ProcThreadsThreshold   *int     `json:"proc_threads_threshold,omitempty"`
+	ProcOpenFilesThreshold *int     `json:"proc_open_files_threshold,omitempty"`
+	ProcDiskReadThreshold  *float64 `json:"proc_disk_read_threshold,omitempty"`
+	ProcDiskWriteThreshold *float64 `json:"proc_disk_write_threshold,omitempty"`
+	ProcNetRecvThreshold   *float64 `json:"proc_net_recv_threshold,omitempty"`
+	ProcNetSendThreshold   *float64 `json:"proc_net_send_threshold,omitempty"`
+
+	DirGrowthThreshold *float64 `json:"dir_growth_threshold,omitempty"`
+	DirMinFreeMB       *float64 `json:"dir_min_free_mb,omitempty"`
 }
 
 // ImpactConfig 影响分析配置
 type ImpactConfig struct {
 	Enabled          bool `json:"enabled"`           // 是否启用
 	AnalysisInterval int  `json:"analysis_interval"` // 分析间隔（秒），默认5
-	TopNProcesses    int  `json:"top_n_processes"`   // 分析 Top N 进程，默认10
+	TopNProcesses    int  `json:"top_n_processes"`   // 系统级阈值触发时归因的 Top N 进程，默认10
 	HistoryLen       int  `json:"history_len"`       // 影响记录保留数量，默认100
 
+	// 分析周期负载控制：上一周期未结束时跳过本次，连续跳过超过 SkipWarnCycles 个周期时记录 WARN
+	AnalysisWorkers int `json:"analysis_workers"` // 并发执行各项分析的协程数，0 或 1 表示顺序执行，默认1
+	SkipWarnCycles  int `json:"skip_warn_cycles"` // 连续跳过多少个周期后告警，默认3
+
 	// 系统级别阈值
-	CPUThreshold     float64 `json:"cpu_threshold"`     // 系统 CPU 竞争阈值（%），默认80
-	MemoryThreshold  float64 `json:"memory_threshold"`  // 系统内存压力阈值（%），默认85
-	DiskIOThreshold  float64 `json:"disk_io_threshold"` // 系统磁盘IO阈值（MB/s），默认100
-	NetworkThreshold float64 `json:"network_threshold"` // 系统网络IO阈值（MB/s），默认100
+	CPUThreshold     float64 `json:"cpu_threshold"`      // 系统 CPU 竞争阈值（%），默认80
+	MemoryThreshold  float64 `json:"memory_threshold"`   // 系统内存压力阈值（%），默认85
+	DiskIOThreshold  float64 `json:"disk_io_threshold"`  // 系统磁盘IO阈值（MB/s），默认100
+	NetworkThreshold float64 `json:"network_threshold"`  // 系统网络IO阈值（MB/s），默认100
+	CPUCoreThreshold float64 `json:"cpu_core_threshold"` // 单核饱和阈值（%），默认90，0 表示不检测核心争用
+	ChurnThreshold   int     `json:"churn_threshold"`    // 进程启停频率阈值（每分钟新建+退出进程数），默认120，0 表示不检测
+	StealThreshold   float64 `json:"steal_threshold"`    // CPU 被宿主机抢占阈值（%，Linux 虚拟机），默认10，0 表示不检测
+	// 虚拟化资源争用：宿主机抢占达到 steal_threshold 且目标可运行等待 CPU 的时间占比达到该阈值（%），默认20，0 表示不检测
+	VirtRunWaitThreshold float64 `json:"virt_run_wait_threshold"`
 
 	// 进程级别阈值（单个进程超过即触发检测）
 	// 0 表示不检测该指标
@@ -169,6 +693,7 @@ type ImpactConfig struct {
 	ProcMemGrowthThreshold float64 `json:"proc_mem_growth_threshold"` // 进程内存增速阈值（MB/s），默认10
 	ProcVMSThreshold       float64 `json:"proc_vms_threshold"`        // 进程虚拟内存阈值（MB），默认0（不检测）
 	ProcFDsThreshold       int     `json:"proc_fds_threshold"`        // 进程句柄数阈值，默认1000
+	ProcFDGrowthThreshold  float64 `json:"proc_fd_growth_threshold"`  // 进程句柄数增速阈值（个/分钟），默认100
 	ProcThreadsThreshold   int     `json:"proc_threads_threshold"`    // 进程线程数阈值，默认500
 	ProcOpenFilesThreshold int     `json:"proc_open_files_threshold"` // 进程打开文件数阈值，默认500
 	ProcDiskReadThreshold  float64 `json:"proc_disk_read_threshold"`  // 进程磁盘读阈值（MB/s），默认50
@@ -176,10 +701,54 @@ type ImpactConfig struct {
 	ProcNetRecvThreshold   float64 `json:"proc_net_recv_threshold"`   // 进程网络收阈值（MB/s），默认50
 	ProcNetSendThreshold   float64 `json:"proc_net_send_threshold"`   // 进程网络发阈值（MB/s），默认50
 
+	// 目标僵尸子进程数阈值（目标未回收的已退出子进程，仅 Linux 等类 Unix 系统），默认5，0 表示不检测
+	ZombieThreshold int `json:"zombie_threshold"`
+
+	// 趋势预测：按最近一段时间的线性斜率预测系统内存、Swap 和磁盘使用率到达耗尽线的时间，早于预警时长时产生 trend 影响
+	TrendWindowSeconds  int      `json:"trend_window_seconds"`       // 斜率计算窗口（秒），默认600，0 表示不检测
+	TrendHorizonMinutes int      `json:"trend_horizon_minutes"`      // 预计在该时间内耗尽时告警（分钟），默认30
+	TrendLimitPercent   float64  `json:"trend_limit_percent"`        // 视为耗尽的使用率（%），默认95
+	TrendDiskPaths      []string `json:"trend_disk_paths,omitempty"` // 检测填满趋势的磁盘挂载点，为空表示系统盘
+
+	// 目标目录占用：按 FileCheckInterval 在后台统计目标 WatchDirs 的大小和所在文件系统的剩余空间
+	DirGrowthThreshold float64 `json:"dir_growth_threshold"` // 目录增长速度阈值（MB/小时），默认1024，0 表示不检测
+	DirMinFreeMB       float64 `json:"dir_min_free_mb"`      // 目录所在文件系统剩余空间下限（MB），默认1024，0 表示不检测
+
 	// 资源冲突检测间隔
 	FileCheckInterval int `json:"file_check_interval"` // 文件检测间隔（秒），默认30
 	PortCheckInterval int `json:"port_check_interval"` // 端口检测间隔（秒），默认30
 
+	// 端口冲突检测选项
+	IgnoreLoopbackPorts bool `json:"ignore_loopback_ports"` // 忽略仅监听回环地址的端口
+
+	// 不把本程序及其子进程作为影响来源（本程序自身的开销不计入对目标的影响）
+	ExcludeSelf bool `json:"exclude_self"` // 默认true
+
+	// 虚拟内存检测不计文件映射：按匿名映射的虚拟内存（ProcessInfo.AnonVMS）比较 proc_vms_threshold，
+	// 避免大量映射数据文件的程序误报；平台不支持或读取失败时仍按完整 VMS 比较
+	ExcludeMappedVMS bool `json:"exclude_mapped_vms"`
+
+	// 持续时间要求：突破阈值持续该时长才产生影响事件，不再突破持续该时长才解除，避免瞬时尖峰反复告警
+	// 实际判定粒度为分析间隔；0 表示立即产生/解除
+	MinDurationSeconds   int            `json:"min_duration_seconds"`             // 全局持续时间（秒）
+	MinDurationOverrides map[string]int `json:"min_duration_overrides,omitempty"` // 按影响类型覆盖（如 "cpu": 30）
+	ClearDurationSeconds int            `json:"clear_duration_seconds"`           // 解除前需持续未突破的时间（秒）
+
+	// 日志冷却：同一影响在该时间内再次产生只在界面显示，不重复写日志；0 表示每次产生都记录
+	LogCooldownSeconds int `json:"log_cooldown_seconds"`
+
+	// 阈值时段配置：在各自时间窗口内覆盖上面的阈值，多个同时生效时以列表中最后一个为准
+	Profiles []ThresholdProfile `json:"profiles,omitempty"`
+
+	// 计划维护时段：时段内照常分析，但影响事件标记为已抑制（不告警、不计入健康评分），多个匹配时取第一个
+	MaintenanceWindows []MaintenanceSchedule `json:"maintenance_windows,omitempty"`
+
+	// 健康评分权重（每个活跃影响事件按严重级别扣分，满分100）
+	ScoreWeightCritical float64 `json:"score_weight_critical"` // 严重事件扣分，默认40
+	ScoreWeightHigh     float64 `json:"score_weight_high"`     // 高级事件扣分，默认15
+	ScoreWeightMedium   float64 `json:"score_weight_medium"`   // 中级事件扣分，默认5
+	ScoreWeightLow      float64 `json:"score_weight_low"`      // 低级事件扣分，默认1
+
 	// 兼容旧字段（已废弃，使用新字段）
 	ProcessCPUThreshold     float64 `json:"process_cpu_threshold,omitempty"`
 	ProcessMemoryThreshold  float64 `json:"process_memory_threshold,omitempty"`